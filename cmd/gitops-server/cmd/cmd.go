@@ -18,18 +18,26 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/weaveworks/weave-gitops/api/v1alpha1"
 	"github.com/weaveworks/weave-gitops/cmd/gitops/cmderrors"
+	"github.com/weaveworks/weave-gitops/core/clustersmngr"
 	"github.com/weaveworks/weave-gitops/core/logger"
 	core "github.com/weaveworks/weave-gitops/core/server"
+	"github.com/weaveworks/weave-gitops/pkg/featureflags"
+	"github.com/weaveworks/weave-gitops/pkg/helm"
 	"github.com/weaveworks/weave-gitops/pkg/helm/watcher"
 	"github.com/weaveworks/weave-gitops/pkg/helm/watcher/cache"
 	"github.com/weaveworks/weave-gitops/pkg/kube"
 	"github.com/weaveworks/weave-gitops/pkg/server"
 	"github.com/weaveworks/weave-gitops/pkg/server/auth"
+	serverconfig "github.com/weaveworks/weave-gitops/pkg/server/config"
+	"github.com/weaveworks/weave-gitops/pkg/server/ratelimit"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 const (
@@ -43,18 +51,27 @@ type Options struct {
 	Host                          string
 	HelmRepoNamespace             string
 	HelmRepoName                  string
+	HelmRepositories              []string
 	ProfileCacheLocation          string
+	ProfileCacheBackend           string
+	ProfileCacheNamespace         string
 	WatcherMetricsBindAddress     string
 	WatcherHealthzBindAddress     string
 	WatcherPort                   int
+	ProfileUpgradePolicy          string
 	Path                          string
 	LogLevel                      string
 	OIDC                          auth.OIDCConfig
 	NotificationControllerAddress string
 	TLSCertFile                   string
 	TLSKeyFile                    string
+	TLSClientCAFile               string
 	Insecure                      bool
 	MTLS                          bool
+	ConfigFile                    string
+	FeatureFlagsConfigMap         string
+	RateLimits                    ratelimit.Config
+	GitHostTypes                  map[string]string
 }
 
 var options Options
@@ -67,22 +84,41 @@ func NewCommand() *cobra.Command {
 
 	options = Options{}
 
+	cmd.Flags().StringVar(&options.ConfigFile, "config-file", "", "path to a configuration file, flags given on the command line take precedence over it")
 	cmd.Flags().StringVar(&options.LogLevel, "log-level", logger.DefaultLogLevel, "log level")
 	cmd.Flags().StringVar(&options.Host, "host", server.DefaultHost, "UI host")
 	cmd.Flags().StringVar(&options.Port, "port", server.DefaultPort, "UI port")
 	cmd.Flags().StringVar(&options.Path, "path", "", "Path url")
 	cmd.Flags().StringVar(&options.HelmRepoNamespace, "helm-repo-namespace", "default", "the namespace of the Helm Repository resource to scan for profiles")
 	cmd.Flags().StringVar(&options.HelmRepoName, "helm-repo-name", "weaveworks-charts", "the name of the Helm Repository resource to scan for profiles")
+	cmd.Flags().StringSliceVar(&options.HelmRepositories, "helm-repositories", nil, "the Helm Repository resources to scan for profiles as namespace/name, or \"*\" for all the ones the user can read, overrides --helm-repo-name and --helm-repo-namespace")
 	cmd.Flags().StringVar(&options.ProfileCacheLocation, "profile-cache-location", "/tmp/helm-cache", "the location where the cache Profile data lives")
+	cmd.Flags().StringVar(&options.ProfileCacheBackend, "profile-cache-backend", cache.BackendFilesystem, "where to cache Profile data: \"filesystem\" under --profile-cache-location, \"memory\", or \"configmap\" to share it between replicas through ConfigMaps, a single replica scanning the HelmRepositories")
+	cmd.Flags().StringVar(&options.ProfileCacheNamespace, "profile-cache-namespace", v1alpha1.DefaultNamespace, "the namespace of the ConfigMaps holding Profile data when --profile-cache-backend is \"configmap\"")
 	cmd.Flags().StringVar(&options.WatcherHealthzBindAddress, "watcher-healthz-bind-address", ":9981", "bind address for the healthz service of the watcher")
 	cmd.Flags().StringVar(&options.WatcherMetricsBindAddress, "watcher-metrics-bind-address", ":9980", "bind address for the metrics service of the watcher")
 	cmd.Flags().StringVar(&options.NotificationControllerAddress, "notification-controller-address", "", "the address of the notification-controller running in the cluster")
 	cmd.Flags().IntVar(&options.WatcherPort, "watcher-port", 9443, "the port on which the watcher is running")
+	cmd.Flags().StringVar(&options.ProfileUpgradePolicy, "profile-upgrade-policy", string(helm.UpgradePolicyMajor), "the upgrades of installed profiles the watcher notifies about: \"patch\", \"minor\" or \"major\", HelmReleases can override it with the weave.works/upgrade-policy annotation")
+	cmd.Flags().StringToStringVar(&options.GitHostTypes, "git-host-types", map[string]string{}, "the self-hosted git providers by host, e.g. git.example.com=gitea (bitbucket-server, gitea or gitlab), provider tokens are only validated against these hosts")
+	cmd.Flags().StringVar(&options.FeatureFlagsConfigMap, "feature-flags-configmap", featureflags.DefaultConfigMapName, "the name of the ConfigMap in the flux-system namespace holding feature flags, empty to disable")
+
+	options.RateLimits = ratelimit.Config{
+		Global:       ratelimit.Limit{Interval: time.Second},
+		PerPrincipal: ratelimit.Limit{Interval: time.Second},
+		FanOut:       ratelimit.Limit{Interval: time.Second},
+		FanOutRoutes: ratelimit.DefaultFanOutRoutes,
+	}
+
+	cmd.Flags().Uint64Var(&options.RateLimits.Global.Requests, "api-global-rate-limit", 500, "API requests per second allowed for all users together, 0 to disable")
+	cmd.Flags().Uint64Var(&options.RateLimits.PerPrincipal.Requests, "api-rate-limit", 50, "API requests per second allowed for each user, or each client address when not authenticated, 0 to disable")
+	cmd.Flags().Uint64Var(&options.RateLimits.FanOut.Requests, "api-fanout-rate-limit", 10, "API requests per second allowed for each user to routes listing resources in every cluster, 0 to disable")
 
 	cmd.Flags().StringVar(&options.TLSCertFile, "tls-cert-file", "", "filename for the TLS certificate, in-memory generated if omitted")
 	cmd.Flags().StringVar(&options.TLSKeyFile, "tls-private-key-file", "", "filename for the TLS key, in-memory generated if omitted")
+	cmd.Flags().StringVar(&options.TLSClientCAFile, "tls-client-ca-file", "", "filename of the CA bundle used to verify client certificates, clients presenting a valid certificate are authenticated by it")
 	cmd.Flags().BoolVar(&options.Insecure, "insecure", false, "do not attempt to read TLS certificates")
-	cmd.Flags().BoolVar(&options.MTLS, "mtls", false, "disable enforce mTLS")
+	cmd.Flags().BoolVar(&options.MTLS, "mtls", false, "require a verified client certificate for every connection")
 
 	cmd.Flags().StringVar(&options.OIDC.IssuerURL, "oidc-issuer-url", "", "The URL of the OpenID Connect issuer")
 	cmd.Flags().StringVar(&options.OIDC.ClientID, "oidc-client-id", "", "The client ID for the OpenID Connect client")
@@ -99,6 +135,26 @@ func runCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	var cfgWatcher *serverconfig.Watcher
+
+	if options.ConfigFile != "" {
+		cfgWatcher, err = serverconfig.NewWatcher(log, options.ConfigFile)
+		if err != nil {
+			return err
+		}
+
+		applyConfigFile(cmd.Flags(), cfgWatcher.Config())
+
+		// The file may change the log level.
+		log, err = logger.New(options.LogLevel, options.Insecure)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	mux := http.NewServeMux()
 
 	mux.Handle("/health/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -126,7 +182,7 @@ func runCmd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("could not create kube http client: %w", err)
 	}
 
-	profileCache, err := cache.NewCache(options.ProfileCacheLocation)
+	profileCache, err := cache.NewBackend(options.ProfileCacheBackend, options.ProfileCacheLocation, rawClient, options.ProfileCacheNamespace)
 	if err != nil {
 		return fmt.Errorf("failed to create cacher: %w", err)
 	}
@@ -136,14 +192,39 @@ func runCmd(cmd *cobra.Command, args []string) error {
 		options.NotificationControllerAddress = fmt.Sprintf("http://notification-controller.%s.svc.cluster.local./", namespace)
 	}
 
-	profileWatcher, err := watcher.NewWatcher(watcher.Options{
+	upgradePolicy, err := helm.ParseUpgradePolicy(options.ProfileUpgradePolicy)
+	if err != nil {
+		return err
+	}
+
+	var clustersFetcher *clustersmngr.StaticClusterFetcher
+
+	if cfgWatcher != nil {
+		clustersFetcher, err = clustersmngr.NewStaticClusterFetcher(rest, rawClient, v1alpha1.DefaultNamespace, toClusters(cfgWatcher.Config().Clusters))
+		if err != nil {
+			return fmt.Errorf("failed creating clusters fetcher: %w", err)
+		}
+	}
+
+	watcherOptions := watcher.Options{
 		KubeClient:                    rawClient,
 		Cache:                         profileCache,
 		MetricsBindAddress:            options.WatcherMetricsBindAddress,
 		HealthzBindAddress:            options.WatcherHealthzBindAddress,
 		NotificationControllerAddress: options.NotificationControllerAddress,
 		WatcherPort:                   options.WatcherPort,
-	})
+		UpgradePolicy:                 upgradePolicy,
+		// every replica would rescan the repositories and overwrite the data of the others otherwise
+		LeaderElection:          options.ProfileCacheBackend == cache.BackendConfigMap,
+		LeaderElectionNamespace: options.ProfileCacheNamespace,
+	}
+
+	if clustersFetcher != nil {
+		// upgrades are notified for the HelmReleases of the leaf clusters too
+		watcherOptions.ClustersFetcher = clustersFetcher
+	}
+
+	profileWatcher, err := watcher.NewWatcher(watcherOptions)
 	if err != nil {
 		return fmt.Errorf("failed to start the watcher: %w", err)
 	}
@@ -194,25 +275,65 @@ func runCmd(cmd *cobra.Command, args []string) error {
 	}
 
 	coreConfig := core.NewCoreConfig(log, rest, clusterName)
+	flags := featureflags.NewStore()
+	checker := newReloadableChecker(nil)
+
+	var fileConfig *serverconfig.Config
+
+	if cfgWatcher != nil {
+		fileConfig = cfgWatcher.Config()
+
+		flags.SetDefaults(fileConfig.FeatureFlags)
+		checker.setRules(fileConfig.AccessRules)
+	}
+
+	coreConfig.NSAccess = checker
+
+	if options.FeatureFlagsConfigMap != "" {
+		clientset, err := kubernetes.NewForConfig(rest)
+		if err != nil {
+			return fmt.Errorf("could not create kubernetes clientset: %w", err)
+		}
+
+		go featureflags.WatchConfigMap(ctx, log, clientset, v1alpha1.DefaultNamespace, options.FeatureFlagsConfigMap, flags)
+	}
 
 	appConfig, err := server.DefaultApplicationsConfig(log)
 	if err != nil {
 		return fmt.Errorf("could not create http client: %w", err)
 	}
 
+	appConfig.GitHostTypes = options.GitHostTypes
+	// ParseRepoURL detects the providers of the hosts like the CLI
+	viper.Set("git-host-types", options.GitHostTypes)
+
 	profilesConfig := server.NewProfilesConfig(kube.ClusterConfig{
 		DefaultConfig: rest,
 		ClusterName:   clusterName,
-	}, profileCache, options.HelmRepoNamespace, options.HelmRepoName)
-
-	appAndProfilesHandlers, err := server.NewHandlers(context.Background(), log,
-		&server.Config{
-			AppConfig:        appConfig,
-			ProfilesConfig:   profilesConfig,
-			CoreServerConfig: coreConfig,
-			AuthServer:       authServer,
-		},
-	)
+	}, profileCache, options.HelmRepoNamespace, options.HelmRepoName).WithHelmRepositories(options.HelmRepositories)
+
+	// The profile watcher serves this registry on its metrics address.
+	rateLimiter, err := ratelimit.New(log, rateLimitConfig(cmd.Flags(), fileConfig), metrics.Registry)
+	if err != nil {
+		return fmt.Errorf("could not create API rate limiter: %w", err)
+	}
+
+	defer rateLimiter.Close()
+
+	handlersConfig := &server.Config{
+		AppConfig:        appConfig,
+		AppOptions:       []server.ApplicationsOption{server.WithFeatureFlags(flags)},
+		ProfilesConfig:   profilesConfig,
+		CoreServerConfig: coreConfig,
+		AuthServer:       authServer,
+		RateLimiter:      rateLimiter,
+	}
+
+	if clustersFetcher != nil {
+		handlersConfig.ClustersFetcher = clustersFetcher
+	}
+
+	appAndProfilesHandlers, err := server.NewHandlers(context.Background(), log, handlersConfig)
 	if err != nil {
 		return fmt.Errorf("could not create handler: %w", err)
 	}
@@ -239,10 +360,64 @@ func runCmd(cmd *cobra.Command, args []string) error {
 		Handler: mux,
 	}
 
+	var certs *certReloader
+
+	if !options.Insecure && options.TLSCertFile != "" && options.TLSKeyFile != "" {
+		certs, err = newCertReloader(ctx, log, options.TLSCertFile, options.TLSKeyFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	var cas *clientCAs
+
+	if !options.Insecure && options.TLSClientCAFile != "" {
+		cas, err = newClientCAs(log, options.TLSClientCAFile)
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			if err := cas.Start(ctx); err != nil {
+				log.Error(err, "failed watching client CA file, changes will not be picked up")
+			}
+		}()
+	}
+
+	if cfgWatcher != nil {
+		cfgWatcher.OnChange(func(old, new *serverconfig.Config) {
+			flags.SetDefaults(new.FeatureFlags)
+			checker.setRules(new.AccessRules)
+			clustersFetcher.SetClusters(toClusters(new.Clusters))
+
+			if err := rateLimiter.SetConfig(rateLimitConfig(cmd.Flags(), new)); err != nil {
+				log.Error(err, "failed to update API rate limits, keeping the previous ones")
+			}
+
+			if certs != nil && new.TLS.CertFile != "" && !cmd.Flags().Changed("tls-cert-file") && !cmd.Flags().Changed("tls-private-key-file") {
+				if err := certs.update(ctx, new.TLS.CertFile, new.TLS.KeyFile); err != nil {
+					log.Error(err, "failed to switch TLS certificate, keeping the previous one")
+				}
+			}
+
+			if sections := restartRequired(old, new); len(sections) > 0 {
+				log.Info("config file changes need a restart to take effect", "sections", sections)
+			}
+
+			log.Info("Reloaded config file", "path", options.ConfigFile)
+		})
+
+		go func() {
+			if err := cfgWatcher.Start(ctx); err != nil {
+				log.Error(err, "failed watching config file, changes will not be picked up")
+			}
+		}()
+	}
+
 	go func() {
 		log.Info("Starting server", "address", addr)
 
-		if err := listenAndServe(log, srv, options); err != nil {
+		if err := listenAndServe(log, srv, options, certs, cas); err != nil {
 			log.Error(err, "server exited")
 			os.Exit(1)
 		}
@@ -253,20 +428,20 @@ func runCmd(cmd *cobra.Command, args []string) error {
 	signal.Notify(quit, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
 
 	defer func() {
-		cancel()
+		shutdownCancel()
 	}()
 
-	if err := srv.Shutdown(ctx); err != nil {
+	if err := srv.Shutdown(shutdownCtx); err != nil {
 		return fmt.Errorf("Server Shutdown Failed: %w", err)
 	}
 
 	return nil
 }
 
-func listenAndServe(log logr.Logger, srv *http.Server, options Options) error {
+func listenAndServe(log logr.Logger, srv *http.Server, options Options, certs *certReloader, cas *clientCAs) error {
 	if options.Insecure {
 		log.Info("TLS connections disabled")
 		return srv.ListenAndServe()
@@ -276,7 +451,27 @@ func listenAndServe(log logr.Logger, srv *http.Server, options Options) error {
 		return cmderrors.ErrNoTLSCertOrKey
 	}
 
-	if options.MTLS {
+	// The certificate is served by the reloader so that rotated files are
+	// picked up without a restart.
+	srv.TLSConfig = &tls.Config{
+		GetCertificate: certs.GetCertificate,
+	}
+
+	switch {
+	case cas != nil:
+		// Client certificates are optional unless mTLS is enforced, so
+		// browsers can still log in while machine clients use certificates.
+		srv.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		if options.MTLS {
+			srv.TLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+
+		srv.TLSConfig.GetConfigForClient = cas.configForClient(srv.TLSConfig)
+
+		log.Info("Verifying client certificates", "ca", options.TLSClientCAFile, "required", options.MTLS)
+	case options.MTLS:
+		log.Info("--mtls without --tls-client-ca-file trusts clients signed by the server certificate, set --tls-client-ca-file instead")
+
 		caCert, err := ioutil.ReadFile(options.TLSCertFile)
 		if err != nil {
 			return fmt.Errorf("failed reading cert file %s. %s", options.TLSCertFile, err)
@@ -285,17 +480,15 @@ func listenAndServe(log logr.Logger, srv *http.Server, options Options) error {
 		caCertPool := x509.NewCertPool()
 		caCertPool.AppendCertsFromPEM(caCert)
 
-		srv.TLSConfig = &tls.Config{
-			ClientCAs:  caCertPool,
-			ClientAuth: tls.RequireAndVerifyClientCert,
-		}
-	} else {
+		srv.TLSConfig.ClientCAs = caCertPool
+		srv.TLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
 		log.Info("Using TLS from %q and %q", options.TLSCertFile, options.TLSKeyFile)
 	}
 
 	// if tlsCert and tlsKey are both empty (""), ListenAndServeTLS will ignore
 	// and happily use the TLSConfig supplied above
-	return srv.ListenAndServeTLS(options.TLSCertFile, options.TLSKeyFile)
+	return srv.ListenAndServeTLS("", "")
 }
 
 //go:embed dist/*
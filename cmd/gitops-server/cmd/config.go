@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/weaveworks/weave-gitops/core/clustersmngr"
+	"github.com/weaveworks/weave-gitops/core/nsaccess"
+	"github.com/weaveworks/weave-gitops/pkg/server"
+	serverconfig "github.com/weaveworks/weave-gitops/pkg/server/config"
+	"github.com/weaveworks/weave-gitops/pkg/server/ratelimit"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/rest"
+)
+
+// applyConfigFile copies the values set in the configuration file into
+// options. Flags given explicitly on the command line take precedence.
+func applyConfigFile(flags *pflag.FlagSet, cfg *serverconfig.Config) {
+	setString := func(name string, dst *string, value string) {
+		if value != "" && !flags.Changed(name) {
+			*dst = value
+		}
+	}
+
+	setString("log-level", &options.LogLevel, cfg.Server.LogLevel)
+	setString("host", &options.Host, cfg.Server.Host)
+	setString("port", &options.Port, cfg.Server.Port)
+	setString("path", &options.Path, cfg.Server.Path)
+	setString("notification-controller-address", &options.NotificationControllerAddress, cfg.Server.NotificationControllerAddress)
+
+	setString("tls-cert-file", &options.TLSCertFile, cfg.TLS.CertFile)
+	setString("tls-private-key-file", &options.TLSKeyFile, cfg.TLS.KeyFile)
+	setString("tls-client-ca-file", &options.TLSClientCAFile, cfg.TLS.ClientCAFile)
+
+	if cfg.TLS.Insecure && !flags.Changed("insecure") {
+		options.Insecure = true
+	}
+
+	if cfg.TLS.MTLS && !flags.Changed("mtls") {
+		options.MTLS = true
+	}
+
+	setString("oidc-issuer-url", &options.OIDC.IssuerURL, cfg.Auth.OIDC.IssuerURL)
+	setString("oidc-client-id", &options.OIDC.ClientID, cfg.Auth.OIDC.ClientID)
+	setString("oidc-client-secret", &options.OIDC.ClientSecret, cfg.Auth.OIDC.ClientSecret)
+	setString("oidc-redirect-url", &options.OIDC.RedirectURL, cfg.Auth.OIDC.RedirectURL)
+
+	if cfg.Auth.OIDC.TokenDuration.Duration != 0 && !flags.Changed("oidc-token-duration") {
+		options.OIDC.TokenDuration = cfg.Auth.OIDC.TokenDuration.Duration
+	}
+
+	// The environment variable wins when it is set, as it always has.
+	if cfg.Auth.Enabled && os.Getenv(server.AuthEnabledFeatureFlag) == "" {
+		os.Setenv(server.AuthEnabledFeatureFlag, "true")
+	}
+
+	setString("helm-repo-name", &options.HelmRepoName, cfg.Profiles.HelmRepository.Name)
+	setString("helm-repo-namespace", &options.HelmRepoNamespace, cfg.Profiles.HelmRepository.Namespace)
+	setString("profile-cache-location", &options.ProfileCacheLocation, cfg.Profiles.CacheLocation)
+	setString("profile-cache-backend", &options.ProfileCacheBackend, cfg.Profiles.CacheBackend)
+	setString("profile-cache-namespace", &options.ProfileCacheNamespace, cfg.Profiles.CacheNamespace)
+	setString("watcher-metrics-bind-address", &options.WatcherMetricsBindAddress, cfg.Profiles.Watcher.MetricsBindAddress)
+	setString("watcher-healthz-bind-address", &options.WatcherHealthzBindAddress, cfg.Profiles.Watcher.HealthzBindAddress)
+	setString("profile-upgrade-policy", &options.ProfileUpgradePolicy, cfg.Profiles.Watcher.UpgradePolicy)
+	setString("feature-flags-configmap", &options.FeatureFlagsConfigMap, cfg.FeatureFlagsConfigMap)
+
+	if len(cfg.Profiles.HelmRepositories) > 0 && !flags.Changed("helm-repositories") {
+		options.HelmRepositories = cfg.Profiles.HelmRepositories
+	}
+
+	if cfg.Profiles.Watcher.Port != 0 && !flags.Changed("watcher-port") {
+		options.WatcherPort = cfg.Profiles.Watcher.Port
+	}
+}
+
+// restartRequired returns the sections of the configuration that changed
+// but are only read at start up.
+func restartRequired(old, new *serverconfig.Config) []string {
+	var sections []string
+
+	if !reflect.DeepEqual(old.Server, new.Server) {
+		sections = append(sections, "server")
+	}
+
+	if old.TLS.Insecure != new.TLS.Insecure || old.TLS.MTLS != new.TLS.MTLS || old.TLS.ClientCAFile != new.TLS.ClientCAFile {
+		sections = append(sections, "tls")
+	}
+
+	if !reflect.DeepEqual(old.Auth, new.Auth) {
+		sections = append(sections, "auth")
+	}
+
+	if !reflect.DeepEqual(old.Profiles, new.Profiles) {
+		sections = append(sections, "profiles")
+	}
+
+	if old.FeatureFlagsConfigMap != new.FeatureFlagsConfigMap {
+		sections = append(sections, "featureFlagsConfigMap")
+	}
+
+	return sections
+}
+
+// rateLimitConfig merges the rate limits of the configuration file, which may
+// be nil, into the ones given by flags. Flags given explicitly on the command
+// line take precedence.
+func rateLimitConfig(flags *pflag.FlagSet, cfg *serverconfig.Config) ratelimit.Config {
+	result := options.RateLimits
+
+	if cfg == nil {
+		return result
+	}
+
+	setLimit := func(name string, dst *ratelimit.Limit, value serverconfig.RateLimit) {
+		if value.Requests == 0 || flags.Changed(name) {
+			return
+		}
+
+		dst.Requests = value.Requests
+		dst.Interval = time.Second
+
+		if value.Interval.Duration != 0 {
+			dst.Interval = value.Interval.Duration
+		}
+	}
+
+	setLimit("api-global-rate-limit", &result.Global, cfg.RateLimits.Global)
+	setLimit("api-rate-limit", &result.PerPrincipal, cfg.RateLimits.PerPrincipal)
+	setLimit("api-fanout-rate-limit", &result.FanOut, cfg.RateLimits.FanOut)
+
+	if len(cfg.RateLimits.FanOutRoutes) > 0 {
+		result.FanOutRoutes = cfg.RateLimits.FanOutRoutes
+	}
+
+	return result
+}
+
+func toClusters(clusters []serverconfig.Cluster) []clustersmngr.Cluster {
+	result := make([]clustersmngr.Cluster, 0, len(clusters))
+
+	for _, c := range clusters {
+		result = append(result, clustersmngr.Cluster{
+			Name:      c.Name,
+			Server:    c.Server,
+			SecretRef: c.SecretRef,
+		})
+	}
+
+	return result
+}
+
+// reloadableChecker is a nsaccess.Checker whose rules can be replaced.
+type reloadableChecker struct {
+	mu      sync.RWMutex
+	checker nsaccess.Checker
+}
+
+func newReloadableChecker(rules []rbacv1.PolicyRule) *reloadableChecker {
+	c := &reloadableChecker{}
+	c.setRules(rules)
+
+	return c
+}
+
+func (c *reloadableChecker) setRules(rules []rbacv1.PolicyRule) {
+	if len(rules) == 0 {
+		rules = nsaccess.DefautltWegoAppRules
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checker = nsaccess.NewChecker(rules)
+}
+
+func (c *reloadableChecker) FilterAccessibleNamespaces(ctx context.Context, cfg *rest.Config, namespaces []corev1.Namespace) ([]corev1.Namespace, error) {
+	c.mu.RLock()
+	checker := c.checker
+	c.mu.RUnlock()
+
+	return checker.FilterAccessibleNamespaces(ctx, cfg, namespaces)
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+)
+
+// certReloader serves the certificate of a certwatcher, which reloads it when
+// the files are rotated. Pointing it at different files starts a new watcher.
+type certReloader struct {
+	log logr.Logger
+
+	mu       sync.RWMutex
+	watcher  *certwatcher.CertWatcher
+	certFile string
+	keyFile  string
+	cancel   context.CancelFunc
+}
+
+func newCertReloader(ctx context.Context, log logr.Logger, certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{log: log}
+
+	if err := r.update(ctx, certFile, keyFile); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *certReloader) update(ctx context.Context, certFile, keyFile string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.watcher != nil && r.certFile == certFile && r.keyFile == keyFile {
+		return nil
+	}
+
+	watcher, err := certwatcher.New(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		if err := watcher.Start(watchCtx); err != nil {
+			r.log.Error(err, "failed watching TLS certificate", "cert", certFile, "key", keyFile)
+		}
+	}()
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	r.watcher, r.certFile, r.keyFile, r.cancel = watcher, certFile, keyFile, cancel
+
+	return nil
+}
+
+func (r *certReloader) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.watcher.GetCertificate(hello)
+}
+
+// clientCAs holds the bundle used to verify client certificates and reloads
+// it when the file changes, so CAs can be rotated without a restart.
+type clientCAs struct {
+	log  logr.Logger
+	path string
+
+	mu   sync.RWMutex
+	pool *x509.CertPool
+}
+
+func newClientCAs(log logr.Logger, path string) (*clientCAs, error) {
+	c := &clientCAs{log: log, path: path}
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *clientCAs) load() error {
+	pem, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return fmt.Errorf("failed reading client CA file %s: %w", c.path, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no certificates found in client CA file %s", c.path)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pool = pool
+
+	return nil
+}
+
+// Start reloads the bundle on changes until ctx is done. The directory is
+// watched so that updates of mounted Secrets are noticed too.
+func (c *clientCAs) Start(ctx context.Context) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer fsw.Close()
+
+	if err := fsw.Add(filepath.Dir(c.path)); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", c.path, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+				continue
+			}
+
+			if err := c.load(); err != nil {
+				c.log.Error(err, "failed to reload client CA file, keeping the previous bundle")
+			}
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+
+			c.log.Error(err, "error watching client CA file")
+		}
+	}
+}
+
+// configForClient returns a tls.Config.GetConfigForClient function that
+// serves base with the current bundle.
+func (c *clientCAs) configForClient(base *tls.Config) func(*tls.ClientHelloInfo) (*tls.Config, error) {
+	return func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+
+		cfg := base.Clone()
+		cfg.GetConfigForClient = nil
+		cfg.ClientCAs = c.pool
+
+		return cfg, nil
+	}
+}
@@ -21,7 +21,11 @@ import (
 	"k8s.io/client-go/util/homedir"
 )
 
-var opts profiles.Options
+var (
+	opts        profiles.Options
+	valuesFlags internal.ValuesFlags
+	mergeFlags  internal.MergeFlags
+)
 
 // AddCommand provides support for adding a profile to a cluster.
 func AddCommand() *cobra.Command {
@@ -33,6 +37,9 @@ func AddCommand() *cobra.Command {
 		Example: `
 		# Add a profile to a cluster
 		gitops add profile --name=podinfo --cluster=prod --version=1.0.0 --config-repo=ssh://git@github.com/owner/config-repo.git
+
+		# Squash the pull request once its checks passed and wait for Flux to install the profile
+		gitops add profile --name=podinfo --cluster=prod --version=1.0.0 --config-repo=ssh://git@github.com/owner/config-repo.git --wait --merge-method=squash
 		`,
 		RunE: addProfileCmdRunE(),
 	}
@@ -43,6 +50,8 @@ func AddCommand() *cobra.Command {
 	cmd.Flags().StringVar(&opts.Cluster, "cluster", "", "Name of the cluster to add the profile to")
 	cmd.Flags().StringVar(&opts.ProfilesPort, "profiles-port", server.DefaultPort, "Port the Profiles API is running on")
 	cmd.Flags().BoolVar(&opts.AutoMerge, "auto-merge", false, "If set, 'gitops add profile' will merge automatically into the repository's branch")
+	internal.AddMergeFlags(cmd, &mergeFlags)
+	internal.AddValuesFlags(cmd, &valuesFlags)
 	cmd.Flags().StringVar(&opts.Kubeconfig, "kubeconfig", filepath.Join(homedir.HomeDir(), ".kube", "config"), "Absolute path to the kubeconfig file")
 	internal.AddPRFlags(cmd, &opts.HeadBranch, &opts.BaseBranch, &opts.Description, &opts.Message, &opts.Title)
 
@@ -68,6 +77,21 @@ func addProfileCmdRunE() func(*cobra.Command, []string) error {
 		}
 
 		var err error
+		if opts.Values, err = valuesFlags.Values(); err != nil {
+			return err
+		}
+
+		if opts.ValuesFrom, err = valuesFlags.References(); err != nil {
+			return err
+		}
+
+		if opts.MergeMethod, err = mergeFlags.MergeMethod(); err != nil {
+			return err
+		}
+
+		opts.Wait = mergeFlags.Wait
+		opts.ChecksGracePeriod = mergeFlags.ChecksGracePeriod
+
 		if opts.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
 			return err
 		}
@@ -97,7 +121,13 @@ func addProfileCmdRunE() func(*cobra.Command, []string) error {
 			return fmt.Errorf("failed to get git clients: %w", err)
 		}
 
-		return profiles.NewService(clientSet, log).Add(context.Background(), gitProvider, opts)
+		ctx, cancel := mergeFlags.Context(context.Background())
+		defer cancel()
+
+		svc := profiles.NewService(clientSet, log)
+		svc.KubeClient = kubeClient.Raw()
+
+		return svc.Add(ctx, gitProvider, opts)
 	}
 }
 
@@ -1,6 +1,9 @@
 package profiles_test
 
 import (
+	"os"
+	"path/filepath"
+
 	"github.com/go-resty/resty/v2"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -26,6 +29,9 @@ var _ = Describe("Add a Profile", func() {
 				"--namespace", "test-namespace",
 				"--config-repo", "https://ssh@github:test/test.git",
 				"--auto-merge", "true",
+				"--merge-method", "squash",
+				"--wait",
+				"--wait-timeout", "10m",
 			})
 
 			err := cmd.Execute()
@@ -68,6 +74,41 @@ var _ = Describe("Add a Profile", func() {
 		})
 	})
 
+	When("the values file is not valid", func() {
+		It("fails", func() {
+			valuesFile := filepath.Join(GinkgoT().TempDir(), "values.yaml")
+			Expect(os.WriteFile(valuesFile, []byte("- not a map"), 0600)).To(Succeed())
+
+			cmd.SetArgs([]string{
+				"add", "profile",
+				"--name", "podinfo",
+				"--config-repo", "ssh://git@github.com/owner/config-repo.git",
+				"--cluster", "prod",
+				"--values", valuesFile,
+			})
+
+			err := cmd.Execute()
+			Expect(err).To(MatchError(ContainSubstring("failed to parse --values file " + valuesFile)))
+		})
+	})
+
+	When("a values reference is not valid", func() {
+		It("fails", func() {
+			cmd.SetArgs([]string{
+				"add", "profile",
+				"--name", "podinfo",
+				"--config-repo", "ssh://git@github.com/owner/config-repo.git",
+				"--cluster", "prod",
+				"--version", "0.0.1",
+				"--set", "replicaCount=2",
+				"--values-from", "Pod/podinfo",
+			})
+
+			err := cmd.Execute()
+			Expect(err).To(MatchError(ContainSubstring("failed to parse --values-from: invalid values reference \"Pod/podinfo\"")))
+		})
+	})
+
 	When("a flag is unknown", func() {
 		It("fails", func() {
 			cmd.SetArgs([]string{
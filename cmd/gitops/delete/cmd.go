@@ -4,6 +4,8 @@ import (
 	"github.com/go-resty/resty/v2"
 	"github.com/spf13/cobra"
 	"github.com/weaveworks/weave-gitops/cmd/gitops/delete/clusters"
+	"github.com/weaveworks/weave-gitops/cmd/gitops/delete/deploykeys"
+	"github.com/weaveworks/weave-gitops/cmd/gitops/delete/profiles"
 )
 
 func DeleteCommand(endpoint *string, client *resty.Client) *cobra.Command {
@@ -12,10 +14,18 @@ func DeleteCommand(endpoint *string, client *resty.Client) *cobra.Command {
 		Short: "Delete one or many Weave GitOps resources",
 		Example: `
 # Delete a CAPI cluster given its name
-gitops delete cluster <cluster-name>`,
+gitops delete cluster <cluster-name>
+
+# Delete a profile that is installed on a cluster
+gitops delete profile --name=podinfo --cluster=prod --config-repo=ssh://git@github.com/owner/config-repo.git
+
+# Delete the deploy keys of a repository that Flux doesn't use
+gitops delete deploy-keys --config-repo=ssh://git@github.com/owner/config-repo.git`,
 	}
 
 	cmd.AddCommand(clusters.ClusterCommand(endpoint, client))
+	cmd.AddCommand(profiles.DeleteCommand())
+	cmd.AddCommand(deploykeys.DeleteCommand())
 
 	return cmd
 }
@@ -0,0 +1,91 @@
+package deploykeys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/cmd/internal"
+	"github.com/weaveworks/weave-gitops/pkg/services/auth"
+)
+
+var (
+	configRepo string
+	ids        []string
+	dryRun     bool
+)
+
+// DeleteCommand deletes the deploy keys of the config repository that Flux no longer uses.
+func DeleteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "deploy-key",
+		Aliases: []string{"deploy-keys"},
+		Short:   "Delete the orphaned deploy keys of a repository",
+		Long: `Delete the deploy keys of a repository replaced by rotating the key used by Flux, e.g. left behind by
+a failed rotation. The Secret of the deploy key used by Flux records the keys it replaced, the command
+fails when it isn't in --namespace.
+
+The keys of the other clusters using the repository are only deleted when their IDs are given with --id,
+e.g. for a deleted cluster. "gitops get deploy-keys" lists their IDs.`,
+		Example: `
+# List the deploy keys of the config repository that Flux no longer uses
+gitops delete deploy-keys --config-repo=ssh://git@github.com/owner/config-repo.git --dry-run
+
+# Delete them
+gitops delete deploy-keys --config-repo=ssh://git@github.com/owner/config-repo.git
+
+# Delete the deploy key of a deleted cluster too
+gitops delete deploy-keys --config-repo=ssh://git@github.com/owner/config-repo.git --id=64528731
+`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          deleteDeployKeysCmdRunE,
+	}
+
+	cmd.Flags().StringVar(&configRepo, "config-repo", "", "URL of the external repository that contains the automation manifests")
+	cmd.Flags().StringSliceVar(&ids, "id", nil, "IDs of other deploy keys to delete, e.g. those of deleted clusters")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the deploy keys that would be deleted without deleting them")
+	cobra.CheckErr(cmd.MarkFlagRequired("config-repo"))
+
+	return cmd
+}
+
+func deleteDeployKeysCmdRunE(cmd *cobra.Command, args []string) error {
+	namespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		return err
+	}
+
+	authSvc, repoUrl, err := internal.NewDeployKeyService(configRepo)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := authSvc.DeleteOrphanedDeployKeys(context.Background(), namespace, repoUrl, auth.DeleteDeployKeysOptions{
+		IDs:    ids,
+		DryRun: dryRun,
+	})
+
+	log := internal.NewCLILogger(os.Stdout)
+	for _, k := range deleted {
+		if dryRun {
+			log.Println("Would delete deploy key %s (%s)", k.Name, k.ID)
+		} else {
+			log.Successf("Deleted deploy key %s", k.Name)
+		}
+	}
+
+	if errors.Is(err, auth.ErrNoDeployKeySecret) {
+		return fmt.Errorf("%w, set --namespace to the namespace of Flux, or --id to delete keys by their IDs", err)
+	} else if err != nil {
+		return fmt.Errorf("failed to delete deploy keys: %w", err)
+	}
+
+	if len(deleted) == 0 {
+		log.Println("No orphaned deploy key found")
+	}
+
+	return nil
+}
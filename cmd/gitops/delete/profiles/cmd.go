@@ -0,0 +1,97 @@
+package profiles
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/cmd/internal"
+	"github.com/weaveworks/weave-gitops/pkg/flux"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/runner"
+	"github.com/weaveworks/weave-gitops/pkg/services"
+	"github.com/weaveworks/weave-gitops/pkg/services/profiles"
+)
+
+var (
+	opts       profiles.Options
+	mergeFlags internal.MergeFlags
+)
+
+// DeleteCommand provides support for removing a profile from a cluster.
+func DeleteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "profile",
+		Short:         "Delete a profile from a cluster",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Example: `
+	# Delete a profile that is installed on a cluster
+	gitops delete profile --name=podinfo --cluster=prod --config-repo=ssh://git@github.com/owner/config-repo.git
+		`,
+		RunE: deleteProfileCmdRunE(),
+	}
+
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Name of the profile")
+	cmd.Flags().StringVar(&opts.ConfigRepo, "config-repo", "", "URL of the external repository that contains the automation manifests")
+	cmd.Flags().StringVar(&opts.Cluster, "cluster", "", "Name of the cluster where the profile is installed")
+	cmd.Flags().BoolVar(&opts.AutoMerge, "auto-merge", false, "If set, 'gitops delete profile' will merge automatically into the repository's branch")
+	internal.AddMergeFlags(cmd, &mergeFlags)
+	internal.AddPRFlags(cmd, &opts.HeadBranch, &opts.BaseBranch, &opts.Description, &opts.Message, &opts.Title)
+
+	requiredFlags := []string{"name", "config-repo", "cluster"}
+	for _, f := range requiredFlags {
+		if err := cobra.MarkFlagRequired(cmd.Flags(), f); err != nil {
+			panic(fmt.Errorf("unexpected error: %w", err))
+		}
+	}
+
+	return cmd
+}
+
+func deleteProfileCmdRunE() func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		log := internal.NewCLILogger(os.Stdout)
+		fluxClient := flux.New(&runner.CLIRunner{})
+		factory := services.NewFactory(fluxClient, log)
+		providerClient := internal.NewGitProviderClient(os.Stdout, os.LookupEnv, log)
+
+		var err error
+		if opts.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+			return err
+		}
+
+		if opts.MergeMethod, err = mergeFlags.MergeMethod(); err != nil {
+			return err
+		}
+
+		opts.Wait = mergeFlags.Wait
+		opts.ChecksGracePeriod = mergeFlags.ChecksGracePeriod
+
+		kubeClient, _, err := kube.NewKubeHTTPClient()
+		if err != nil {
+			return fmt.Errorf("failed to create kube client: %w", err)
+		}
+
+		_, gitProvider, err := factory.GetGitClients(context.Background(), kubeClient, providerClient, services.GitConfigParams{
+			ConfigRepo:       opts.ConfigRepo,
+			Namespace:        opts.Namespace,
+			IsHelmRepository: true,
+			DryRun:           false,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get git clients: %w", err)
+		}
+
+		ctx, cancel := mergeFlags.Context(context.Background())
+		defer cancel()
+
+		// Deleting a profile only changes the config repository, so the
+		// profiles API is not needed.
+		svc := profiles.NewService(nil, log)
+		svc.KubeClient = kubeClient.Raw()
+
+		return svc.Delete(ctx, gitProvider, opts)
+	}
+}
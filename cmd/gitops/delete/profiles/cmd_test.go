@@ -0,0 +1,72 @@
+package profiles_test
+
+import (
+	"github.com/go-resty/resty/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/cmd/gitops/root"
+)
+
+var _ = Describe("Delete a Profile", func() {
+	var cmd *cobra.Command
+
+	BeforeEach(func() {
+		cmd = root.RootCmd(resty.New())
+	})
+
+	When("the flags are valid", func() {
+		It("accepts all known flags for deleting a profile", func() {
+			cmd.SetArgs([]string{
+				"delete", "profile",
+				"--name", "podinfo",
+				"--cluster", "prod",
+				"--namespace", "test-namespace",
+				"--config-repo", "https://ssh@github:test/test.git",
+				"--auto-merge", "true",
+				"--merge-method", "squash",
+				"--wait",
+				"--wait-timeout", "10m",
+			})
+
+			err := cmd.Execute()
+			Expect(err.Error()).NotTo(ContainSubstring("unknown flag"))
+		})
+	})
+
+	When("flags are not valid", func() {
+		It("fails if --name, --cluster, or --config-repo are not provided", func() {
+			cmd.SetArgs([]string{
+				"delete", "profile",
+			})
+
+			err := cmd.Execute()
+			Expect(err).To(MatchError("required flag(s) \"cluster\", \"config-repo\", \"name\" not set"))
+		})
+
+		It("fails with an unknown merge method", func() {
+			cmd.SetArgs([]string{
+				"delete", "profile",
+				"--name", "podinfo",
+				"--cluster", "prod",
+				"--config-repo", "https://github.com/owner/config-repo.git",
+				"--merge-method", "fast-forward",
+			})
+
+			err := cmd.Execute()
+			Expect(err).To(MatchError(`failed to parse --merge-method: unknown merge method "fast-forward", must be one of merge, squash or rebase`))
+		})
+	})
+
+	When("a flag is unknown", func() {
+		It("fails", func() {
+			cmd.SetArgs([]string{
+				"delete", "profile",
+				"--unknown", "param",
+			})
+
+			err := cmd.Execute()
+			Expect(err).To(MatchError("unknown flag: --unknown"))
+		})
+	})
+})
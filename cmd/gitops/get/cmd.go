@@ -5,6 +5,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/weaveworks/weave-gitops/cmd/gitops/get/clusters"
 	"github.com/weaveworks/weave-gitops/cmd/gitops/get/credentials"
+	"github.com/weaveworks/weave-gitops/cmd/gitops/get/deploykeys"
 	"github.com/weaveworks/weave-gitops/cmd/gitops/get/profiles"
 	"github.com/weaveworks/weave-gitops/cmd/gitops/get/templates"
 )
@@ -21,13 +22,17 @@ gitops get templates
 gitops get credentials
 
 # Get all CAPI clusters
-gitops get clusters`,
+gitops get clusters
+
+# Get the deploy keys of a repository
+gitops get deploy-keys --config-repo=ssh://git@github.com/owner/config-repo.git`,
 	}
 
 	cmd.AddCommand(templates.TemplateCommand(endpoint, client))
 	cmd.AddCommand(credentials.CredentialCommand(endpoint, client))
 	cmd.AddCommand(clusters.ClusterCommand(endpoint, client))
 	cmd.AddCommand(profiles.Cmd)
+	cmd.AddCommand(deploykeys.DeployKeysCommand())
 
 	return cmd
 }
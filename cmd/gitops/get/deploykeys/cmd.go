@@ -0,0 +1,68 @@
+package deploykeys
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/cmd/internal"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+var configRepo string
+
+// DeployKeysCommand lists the deploy keys gitops uploaded to the config repository.
+func DeployKeysCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "deploy-key",
+		Aliases: []string{"deploy-keys"},
+		Short:   "Show the deploy keys gitops uploaded to a repository",
+		Example: `
+# Get the deploy keys of the config repository, and which one Flux uses
+gitops get deploy-keys --config-repo=ssh://git@github.com/owner/config-repo.git
+`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          getDeployKeysCmdRunE,
+	}
+
+	cmd.Flags().StringVar(&configRepo, "config-repo", "", "URL of the external repository that contains the automation manifests")
+	cobra.CheckErr(cmd.MarkFlagRequired("config-repo"))
+
+	return cmd
+}
+
+func getDeployKeysCmdRunE(cmd *cobra.Command, args []string) error {
+	namespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		return err
+	}
+
+	authSvc, repoUrl, err := internal.NewDeployKeyService(configRepo)
+	if err != nil {
+		return err
+	}
+
+	keys, err := authSvc.ListDeployKeys(context.Background(), namespace, repoUrl)
+	if err != nil {
+		return fmt.Errorf("failed to list deploy keys: %w", err)
+	}
+
+	w := printers.GetNewTabWriter(os.Stdout)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "NAME\tID\tCREATED\tIN USE\tREPLACED")
+
+	for _, k := range keys {
+		created := "-"
+		if !k.CreatedAt.IsZero() {
+			created = k.CreatedAt.Local().Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%t\n", k.Name, k.ID, created, k.InUse, k.Replaced)
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package update
 
 import (
+	"github.com/weaveworks/weave-gitops/cmd/gitops/update/deploykeys"
 	"github.com/weaveworks/weave-gitops/cmd/gitops/update/profiles"
 
 	"github.com/go-resty/resty/v2"
@@ -14,10 +15,18 @@ func UpdateCommand(endpoint *string, client *resty.Client) *cobra.Command {
 		Example: `
 	# Update a profile that is installed on a cluster
 	gitops update profile --name=podinfo --cluster=prod --config-repo=ssh://git@github.com/owner/config-repo.git  --version=1.0.0
+
+	# Open pull requests upgrading the profiles installed on a cluster
+	gitops update profile-upgrades --cluster=prod --config-repo=ssh://git@github.com/owner/config-repo.git
+
+	# Rotate the deploy key of a repository if it is older than 90 days
+	gitops update deploy-key --config-repo=ssh://git@github.com/owner/config-repo.git --max-age=90d
 		`,
 	}
 
 	cmd.AddCommand(profiles.UpdateCommand())
+	cmd.AddCommand(profiles.UpgradesCommand())
+	cmd.AddCommand(deploykeys.RotateCommand())
 
 	return cmd
 }
@@ -0,0 +1,69 @@
+package deploykeys
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/cmd/internal"
+)
+
+var (
+	configRepo string
+	maxAge     string
+)
+
+// RotateCommand rotates the deploy key of the config repository.
+func RotateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deploy-key",
+		Short: "Rotate the deploy key of a repository",
+		Long: `Generate a new deploy key, upload it to the git provider and check that it grants access to the
+repository, then update the Secret used by Flux and delete the old key.`,
+		Example: `
+# Rotate the deploy key of the config repository
+gitops update deploy-key --config-repo=ssh://git@github.com/owner/config-repo.git
+
+# Rotate it only if it is older than 90 days, e.g. in a scheduled job
+gitops update deploy-key --config-repo=ssh://git@github.com/owner/config-repo.git --max-age=90d
+`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          rotateDeployKeyCmdRunE,
+	}
+
+	cmd.Flags().StringVar(&configRepo, "config-repo", "", "URL of the external repository that contains the automation manifests")
+	cmd.Flags().StringVar(&maxAge, "max-age", "0", "Rotate the key only if it is older than this, in days like 90d or as a duration like 12h")
+	cobra.CheckErr(cmd.MarkFlagRequired("config-repo"))
+
+	return cmd
+}
+
+func rotateDeployKeyCmdRunE(cmd *cobra.Command, args []string) error {
+	age, err := internal.ParseMaxAge(maxAge)
+	if err != nil {
+		return err
+	}
+
+	namespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		return err
+	}
+
+	authSvc, repoUrl, err := internal.NewDeployKeyService(configRepo)
+	if err != nil {
+		return err
+	}
+
+	rotated, err := authSvc.RotateDeployKey(context.Background(), namespace, repoUrl, age)
+	if err != nil {
+		return fmt.Errorf("failed to rotate deploy key: %w", err)
+	}
+
+	if !rotated {
+		internal.NewCLILogger(os.Stdout).Println("The deploy key of %s is younger than %s, it wasn't rotated", repoUrl, maxAge)
+	}
+
+	return nil
+}
@@ -20,7 +20,11 @@ import (
 	"k8s.io/client-go/util/homedir"
 )
 
-var opts profiles.Options
+var (
+	opts        profiles.Options
+	valuesFlags internal.ValuesFlags
+	mergeFlags  internal.MergeFlags
+)
 
 // UpdateCommand provides support for updating a profile that is installed on a cluster.
 func UpdateCommand() *cobra.Command {
@@ -42,7 +46,9 @@ func UpdateCommand() *cobra.Command {
 	cmd.Flags().StringVar(&opts.Cluster, "cluster", "", "Name of the cluster where the profile is installed")
 	cmd.Flags().StringVar(&opts.ProfilesPort, "profiles-port", server.DefaultPort, "Port the Profiles API is running on")
 	cmd.Flags().BoolVar(&opts.AutoMerge, "auto-merge", false, "If set, 'gitops update profile' will merge automatically into the repository's branch")
+	internal.AddMergeFlags(cmd, &mergeFlags)
 	cmd.Flags().StringVar(&opts.Kubeconfig, "kubeconfig", filepath.Join(homedir.HomeDir(), ".kube", "config"), "Absolute path to the kubeconfig file")
+	internal.AddValuesFlags(cmd, &valuesFlags)
 	internal.AddPRFlags(cmd, &opts.HeadBranch, &opts.BaseBranch, &opts.Description, &opts.Message, &opts.Title)
 
 	requiredFlags := []string{"name", "config-repo", "cluster", "version"}
@@ -69,6 +75,21 @@ func updateProfileCmdRunE() func(*cobra.Command, []string) error {
 		}
 
 		var err error
+		if opts.Values, err = valuesFlags.Values(); err != nil {
+			return err
+		}
+
+		if opts.MergeMethod, err = mergeFlags.MergeMethod(); err != nil {
+			return err
+		}
+
+		opts.Wait = mergeFlags.Wait
+		opts.ChecksGracePeriod = mergeFlags.ChecksGracePeriod
+
+		if opts.ValuesFrom, err = valuesFlags.References(); err != nil {
+			return err
+		}
+
 		if opts.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
 			return err
 		}
@@ -98,6 +119,12 @@ func updateProfileCmdRunE() func(*cobra.Command, []string) error {
 			return fmt.Errorf("failed to get git clients: %w", err)
 		}
 
-		return profiles.NewService(clientSet, log).Update(context.Background(), gitProvider, opts)
+		ctx, cancel := mergeFlags.Context(context.Background())
+		defer cancel()
+
+		svc := profiles.NewService(clientSet, log)
+		svc.KubeClient = kubeClient.Raw()
+
+		return svc.Update(ctx, gitProvider, opts)
 	}
 }
@@ -26,6 +26,9 @@ var _ = Describe("Update Profile(s)", func() {
 				"--namespace", "test-namespace",
 				"--config-repo", "https://ssh@github:test/test.git",
 				"--auto-merge", "true",
+				"--merge-method", "squash",
+				"--wait",
+				"--wait-timeout", "10m",
 			})
 
 			err := cmd.Execute()
@@ -57,6 +60,23 @@ var _ = Describe("Update Profile(s)", func() {
 		})
 	})
 
+	When("a values reference is not valid", func() {
+		It("fails", func() {
+			cmd.SetArgs([]string{
+				"update", "profile",
+				"--name", "podinfo",
+				"--config-repo", "ssh://git@github.com/owner/config-repo.git",
+				"--cluster", "prod",
+				"--version", "0.0.1",
+				"--set", "replicaCount=2",
+				"--values-from", "Pod/podinfo",
+			})
+
+			err := cmd.Execute()
+			Expect(err).To(MatchError(ContainSubstring("failed to parse --values-from: invalid values reference \"Pod/podinfo\"")))
+		})
+	})
+
 	When("a flag is unknown", func() {
 		It("fails", func() {
 			cmd.SetArgs([]string{
@@ -0,0 +1,143 @@
+package profiles
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/cmd/internal"
+	"github.com/weaveworks/weave-gitops/pkg/flux"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/runner"
+	"github.com/weaveworks/weave-gitops/pkg/server"
+	"github.com/weaveworks/weave-gitops/pkg/services"
+	"github.com/weaveworks/weave-gitops/pkg/services/profiles"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/homedir"
+)
+
+var (
+	upgradeOpts       profiles.UpgradeOptions
+	upgradeKubeconfig string
+	upgradeInterval   time.Duration
+	upgradeMergeFlags internal.MergeFlags
+)
+
+// UpgradesCommand provides support for opening pull requests that upgrade the profiles installed on a cluster.
+func UpgradesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "profile-upgrades",
+		Short:         "Open pull requests upgrading the profiles installed on a cluster",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Example: `
+	# Open a pull request for each profile installed on a cluster that has a newer version
+	gitops update profile-upgrades --cluster=prod --config-repo=ssh://git@github.com/owner/config-repo.git
+
+	# Open a single pull request upgrading the profiles to their latest 6.x version
+	gitops update profile-upgrades --cluster=prod --config-repo=ssh://git@github.com/owner/config-repo.git --constraint="^6.0" --batch
+
+	# Keep checking for upgrades every hour
+	gitops update profile-upgrades --cluster=prod --config-repo=ssh://git@github.com/owner/config-repo.git --interval=1h
+		`,
+		RunE: upgradeProfilesCmdRunE(),
+	}
+
+	cmd.Flags().StringVar(&upgradeOpts.ConfigRepo, "config-repo", "", "URL of the external repository that contains the automation manifests")
+	cmd.Flags().StringVar(&upgradeOpts.Cluster, "cluster", "", "Name of the cluster where the profiles are installed")
+	cmd.Flags().StringVar(&upgradeOpts.Constraint, "constraint", "", "Semver constraint the new versions must satisfy (e.g.: ~6.0), any newer version when empty")
+	cmd.Flags().BoolVar(&upgradeOpts.Batch, "batch", false, "If set, a single pull request upgrades every profile instead of one pull request per profile")
+	cmd.Flags().StringVar(&upgradeOpts.BaseBranch, "base", "", "The base branch of the remote repository")
+	cmd.Flags().StringVar(&upgradeOpts.ProfilesPort, "profiles-port", server.DefaultPort, "Port the Profiles API is running on")
+	cmd.Flags().BoolVar(&upgradeOpts.AutoMerge, "auto-merge", false, "If set, 'gitops update profile-upgrades' will merge automatically into the repository's branch")
+	internal.AddMergeFlags(cmd, &upgradeMergeFlags)
+	cmd.Flags().DurationVar(&upgradeInterval, "interval", 0, "If set, keep checking for upgrades at this interval instead of exiting")
+	cmd.Flags().StringVar(&upgradeKubeconfig, "kubeconfig", filepath.Join(homedir.HomeDir(), ".kube", "config"), "Absolute path to the kubeconfig file")
+
+	requiredFlags := []string{"config-repo", "cluster"}
+	for _, f := range requiredFlags {
+		if err := cobra.MarkFlagRequired(cmd.Flags(), f); err != nil {
+			panic(fmt.Errorf("unexpected error: %w", err))
+		}
+	}
+
+	return cmd
+}
+
+func upgradeProfilesCmdRunE() func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		log := internal.NewCLILogger(os.Stdout)
+		fluxClient := flux.New(&runner.CLIRunner{})
+		factory := services.NewFactory(fluxClient, log)
+		providerClient := internal.NewGitProviderClient(os.Stdout, os.LookupEnv, log)
+
+		if upgradeOpts.Constraint != "" {
+			if _, err := semver.NewConstraint(upgradeOpts.Constraint); err != nil {
+				return fmt.Errorf("error parsing --constraint=%s: %w", upgradeOpts.Constraint, err)
+			}
+		}
+
+		if upgradeInterval < 0 {
+			return fmt.Errorf("--interval cannot be negative")
+		}
+
+		var err error
+		if upgradeOpts.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+			return err
+		}
+
+		if upgradeOpts.MergeMethod, err = upgradeMergeFlags.MergeMethod(); err != nil {
+			return err
+		}
+
+		upgradeOpts.Wait = upgradeMergeFlags.Wait
+		upgradeOpts.ChecksGracePeriod = upgradeMergeFlags.ChecksGracePeriod
+
+		config, err := clientcmd.BuildConfigFromFlags("", upgradeKubeconfig)
+		if err != nil {
+			return fmt.Errorf("error initializing kubernetes config: %w", err)
+		}
+
+		clientSet, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return fmt.Errorf("error initializing kubernetes client: %w", err)
+		}
+
+		kubeClient, _, err := kube.NewKubeHTTPClient()
+		if err != nil {
+			return fmt.Errorf("failed to create kube client: %w", err)
+		}
+
+		_, gitProvider, err := factory.GetGitClients(context.Background(), kubeClient, providerClient, services.GitConfigParams{
+			ConfigRepo:       upgradeOpts.ConfigRepo,
+			Namespace:        upgradeOpts.Namespace,
+			IsHelmRepository: true,
+			DryRun:           false,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get git clients: %w", err)
+		}
+
+		svc := profiles.NewService(clientSet, log)
+		svc.KubeClient = kubeClient.Raw()
+
+		if upgradeInterval == 0 {
+			ctx, cancel := upgradeMergeFlags.Context(context.Background())
+			defer cancel()
+
+			return svc.Upgrade(ctx, gitProvider, upgradeOpts)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return svc.WatchUpgrades(ctx, gitProvider, upgradeOpts, upgradeInterval)
+	}
+}
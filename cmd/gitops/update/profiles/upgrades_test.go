@@ -0,0 +1,75 @@
+package profiles_test
+
+import (
+	"github.com/weaveworks/weave-gitops/cmd/gitops/root"
+
+	"github.com/go-resty/resty/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+var _ = Describe("Update Profile Upgrades", func() {
+	var cmd *cobra.Command
+
+	BeforeEach(func() {
+		cmd = root.RootCmd(resty.New())
+	})
+
+	When("the flags are valid", func() {
+		It("accepts all known flags for upgrading profiles", func() {
+			cmd.SetArgs([]string{
+				"update", "profile-upgrades",
+				"--cluster", "prod",
+				"--namespace", "test-namespace",
+				"--config-repo", "https://ssh@github:test/test.git",
+				"--constraint", "~6.0",
+				"--batch",
+				"--base", "main",
+				"--interval", "1h",
+				"--auto-merge", "true",
+				"--merge-method", "squash",
+				"--wait",
+				"--wait-timeout", "10m",
+			})
+
+			err := cmd.Execute()
+			Expect(err.Error()).NotTo(ContainSubstring("unknown flag"))
+		})
+	})
+
+	When("flags are not valid", func() {
+		It("fails if --cluster or --config-repo are not provided", func() {
+			cmd.SetArgs([]string{
+				"update", "profile-upgrades",
+			})
+
+			err := cmd.Execute()
+			Expect(err).To(MatchError("required flag(s) \"cluster\", \"config-repo\" not set"))
+		})
+
+		It("fails if the constraint is not valid", func() {
+			cmd.SetArgs([]string{
+				"update", "profile-upgrades",
+				"--config-repo", "ssh://git@github.com/owner/config-repo.git",
+				"--cluster", "prod",
+				"--constraint", "&%*/v",
+			})
+
+			err := cmd.Execute()
+			Expect(err).To(MatchError(ContainSubstring("error parsing --constraint=&%*/v")))
+		})
+
+		It("fails if the interval is negative", func() {
+			cmd.SetArgs([]string{
+				"update", "profile-upgrades",
+				"--config-repo", "ssh://git@github.com/owner/config-repo.git",
+				"--cluster", "prod",
+				"--interval", "-1m",
+			})
+
+			err := cmd.Execute()
+			Expect(err).To(MatchError("--interval cannot be negative"))
+		})
+	})
+})
@@ -70,10 +70,16 @@ func upgradeCmdRunE() func(*cobra.Command, []string) error {
 
 		providerClient := internal.NewGitProviderClient(os.Stdout, os.LookupEnv, log)
 
+		gitOpts, err := internal.GitOptionsFromEnv(os.LookupEnv)
+		if err != nil {
+			return err
+		}
+
 		gitClient, gitProvider, err := factory.GetGitClients(ctx, kubeClient, providerClient, services.GitConfigParams{
 			ConfigRepo: upgradeCmdFlags.ConfigRepo,
 			Namespace:  upgradeCmdFlags.Namespace,
 			DryRun:     upgradeCmdFlags.DryRun,
+			GitOptions: gitOpts,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to get git clients: %w", err)
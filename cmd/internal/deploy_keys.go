@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/weaveworks/weave-gitops/pkg/flux"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/runner"
+	"github.com/weaveworks/weave-gitops/pkg/services"
+	"github.com/weaveworks/weave-gitops/pkg/services/auth"
+)
+
+// NewDeployKeyService returns the service managing the deploy keys of the config repository.
+func NewDeployKeyService(configRepo string) (auth.AuthService, gitproviders.RepoURL, error) {
+	repoUrl, err := gitproviders.NewRepoURL(configRepo)
+	if err != nil {
+		return nil, gitproviders.RepoURL{}, fmt.Errorf("error normalizing config url: %w", err)
+	}
+
+	if repoUrl.Protocol() == gitproviders.RepositoryURLProtocolHTTPS {
+		return nil, gitproviders.RepoURL{}, fmt.Errorf("%s is accessed with the token of the git provider, it has no deploy key", configRepo)
+	}
+
+	kubeClient, _, err := kube.NewKubeHTTPClient()
+	if err != nil {
+		return nil, gitproviders.RepoURL{}, fmt.Errorf("failed to create kube client: %w", err)
+	}
+
+	log := NewCLILogger(os.Stdout)
+	factory := services.NewFactory(flux.New(&runner.CLIRunner{}), log)
+
+	authSvc, err := factory.GetAuthService(kubeClient, NewGitProviderClient(os.Stdout, os.LookupEnv, log), services.GitConfigParams{
+		ConfigRepo: configRepo,
+	})
+	if err != nil {
+		return nil, gitproviders.RepoURL{}, fmt.Errorf("error getting auth service: %w", err)
+	}
+
+	return authSvc, repoUrl, nil
+}
+
+// ParseMaxAge parses the max age of deploy keys, a duration or a number of days like 90d.
+func ParseMaxAge(s string) (time.Duration, error) {
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid max age %q, must be a number of days like 90d or a duration like 12h", s)
+		}
+
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid max age %q, must be a number of days like 90d or a duration like 12h", s)
+	}
+
+	return d, nil
+}
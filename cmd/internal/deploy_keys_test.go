@@ -0,0 +1,27 @@
+package internal_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/cmd/internal"
+)
+
+var _ = Describe("ParseMaxAge", func() {
+	It("parses days and durations", func() {
+		d, err := internal.ParseMaxAge("90d")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(Equal(90 * 24 * time.Hour))
+
+		d, err = internal.ParseMaxAge("12h")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(Equal(12 * time.Hour))
+
+		_, err = internal.ParseMaxAge("ninety days")
+		Expect(err).To(MatchError(`invalid max age "ninety days", must be a number of days like 90d or a duration like 12h`))
+
+		_, err = internal.ParseMaxAge("-1d")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/weaveworks/weave-gitops/pkg/git"
+)
+
+// GitOptionsFromEnv returns the options of the git client configured with the env vars. Commits are
+// authored by GITOPS_GIT_AUTHOR_NAME and GITOPS_GIT_AUTHOR_EMAIL, committed by GITOPS_GIT_COMMITTER_NAME
+// and GITOPS_GIT_COMMITTER_EMAIL, and signed with the key at the path of GITOPS_GIT_SIGNING_KEY, in the
+// GITOPS_GIT_SIGNING_FORMAT, openpgp (default) or ssh, decrypted with GITOPS_GIT_SIGNING_KEY_PASSPHRASE.
+// Repositories are cloned with the last GITOPS_GIT_CLONE_DEPTH commits only, or kept in GITOPS_GIT_CACHE_DIR
+// to fetch the new commits only on the next clones.
+func GitOptionsFromEnv(lookupEnvFunc func(key string) (string, bool)) ([]git.Option, error) {
+	getEnv := func(key string) string {
+		v, _ := lookupEnvFunc(key)
+		return v
+	}
+
+	opts := []git.Option{}
+
+	if author := (git.Author{Name: getEnv("GITOPS_GIT_AUTHOR_NAME"), Email: getEnv("GITOPS_GIT_AUTHOR_EMAIL")}); author != (git.Author{}) {
+		if author.Name == "" || author.Email == "" {
+			return nil, fmt.Errorf("GITOPS_GIT_AUTHOR_NAME and GITOPS_GIT_AUTHOR_EMAIL need to be set together")
+		}
+
+		opts = append(opts, git.WithAuthor(author))
+	}
+
+	if committer := (git.Author{Name: getEnv("GITOPS_GIT_COMMITTER_NAME"), Email: getEnv("GITOPS_GIT_COMMITTER_EMAIL")}); committer != (git.Author{}) {
+		if committer.Name == "" || committer.Email == "" {
+			return nil, fmt.Errorf("GITOPS_GIT_COMMITTER_NAME and GITOPS_GIT_COMMITTER_EMAIL need to be set together")
+		}
+
+		opts = append(opts, git.WithCommitter(committer))
+	}
+
+	if keyPath := getEnv("GITOPS_GIT_SIGNING_KEY"); keyPath != "" {
+		key, err := ioutil.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading GITOPS_GIT_SIGNING_KEY: %w", err)
+		}
+
+		signer, err := git.NewSigner(getEnv("GITOPS_GIT_SIGNING_FORMAT"), key, getEnv("GITOPS_GIT_SIGNING_KEY_PASSPHRASE"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid GITOPS_GIT_SIGNING_KEY: %w", err)
+		}
+
+		opts = append(opts, git.WithSigner(signer))
+	}
+
+	if depth := getEnv("GITOPS_GIT_CLONE_DEPTH"); depth != "" {
+		n, err := strconv.Atoi(depth)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid GITOPS_GIT_CLONE_DEPTH %q, must be a positive number", depth)
+		}
+
+		opts = append(opts, git.WithDepth(n))
+	}
+
+	if cacheDir := getEnv("GITOPS_GIT_CACHE_DIR"); cacheDir != "" {
+		opts = append(opts, git.WithCache(cacheDir))
+	}
+
+	return opts, nil
+}
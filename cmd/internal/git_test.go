@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/git/wrapper"
+)
+
+var _ = Describe("GitOptionsFromEnv", func() {
+	var (
+		dir string
+		env map[string]string
+	)
+
+	lookupEnv := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	headCommit := func(opts []git.Option) (*gogit.Repository, string) {
+		client := git.New(nil, wrapper.NewGoGit(), opts...)
+
+		_, err := client.Init(dir, "https://example.com/owner/repo.git", "main")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Write("test.txt", []byte("testing"))).To(Succeed())
+
+		hash, err := client.Commit(git.Commit{Message: "test commit"})
+		Expect(err).NotTo(HaveOccurred())
+
+		repo, err := gogit.PlainOpen(dir)
+		Expect(err).NotTo(HaveOccurred())
+
+		return repo, hash
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		dir, err = ioutil.TempDir("", "git-options-")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+
+		env = map[string]string{}
+	})
+
+	It("configures nothing by default", func() {
+		opts, err := GitOptionsFromEnv(lookupEnv)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts).To(BeEmpty())
+	})
+
+	It("configures the author and committer", func() {
+		env["GITOPS_GIT_AUTHOR_NAME"] = "author"
+		env["GITOPS_GIT_AUTHOR_EMAIL"] = "author@example.com"
+		env["GITOPS_GIT_COMMITTER_NAME"] = "committer"
+		env["GITOPS_GIT_COMMITTER_EMAIL"] = "committer@example.com"
+
+		opts, err := GitOptionsFromEnv(lookupEnv)
+		Expect(err).NotTo(HaveOccurred())
+
+		repo, hash := headCommit(opts)
+		commit, err := repo.CommitObject(plumbing.NewHash(hash))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commit.Author.Name).To(Equal("author"))
+		Expect(commit.Author.Email).To(Equal("author@example.com"))
+		Expect(commit.Committer.Name).To(Equal("committer"))
+		Expect(commit.Committer.Email).To(Equal("committer@example.com"))
+	})
+
+	It("requires both the name and email", func() {
+		env["GITOPS_GIT_AUTHOR_NAME"] = "author"
+
+		_, err := GitOptionsFromEnv(lookupEnv)
+		Expect(err).To(MatchError("GITOPS_GIT_AUTHOR_NAME and GITOPS_GIT_AUTHOR_EMAIL need to be set together"))
+	})
+
+	It("signs the commits with the signing key", func() {
+		entity, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		var privateKey, publicKey bytes.Buffer
+
+		w, err := armor.Encode(&privateKey, openpgp.PrivateKeyType, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entity.SerializePrivate(w, nil)).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+
+		w, err = armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entity.Serialize(w)).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+
+		keyDir, err := ioutil.TempDir("", "signing-key-")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, keyDir)
+
+		keyPath := filepath.Join(keyDir, "key.asc")
+		Expect(ioutil.WriteFile(keyPath, privateKey.Bytes(), 0600)).To(Succeed())
+
+		env["GITOPS_GIT_SIGNING_KEY"] = keyPath
+
+		opts, err := GitOptionsFromEnv(lookupEnv)
+		Expect(err).NotTo(HaveOccurred())
+
+		repo, hash := headCommit(opts)
+		commit, err := repo.CommitObject(plumbing.NewHash(hash))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = commit.Verify(publicKey.String())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects invalid signing keys", func() {
+		env["GITOPS_GIT_SIGNING_KEY"] = filepath.Join(dir, "missing")
+
+		_, err := GitOptionsFromEnv(lookupEnv)
+		Expect(err).To(MatchError(ContainSubstring("error reading GITOPS_GIT_SIGNING_KEY")))
+
+		Expect(ioutil.WriteFile(filepath.Join(dir, "key"), []byte("not a key"), 0600)).To(Succeed())
+		env["GITOPS_GIT_SIGNING_KEY"] = filepath.Join(dir, "key")
+		env["GITOPS_GIT_SIGNING_FORMAT"] = "ssh"
+
+		_, err = GitOptionsFromEnv(lookupEnv)
+		Expect(err).To(MatchError(ContainSubstring("invalid GITOPS_GIT_SIGNING_KEY")))
+	})
+
+	It("configures the clone depth and cache", func() {
+		env["GITOPS_GIT_CLONE_DEPTH"] = "1"
+		env["GITOPS_GIT_CACHE_DIR"] = filepath.Join(dir, "cache")
+
+		opts, err := GitOptionsFromEnv(lookupEnv)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts).To(HaveLen(2))
+	})
+
+	It("rejects invalid clone depths", func() {
+		env["GITOPS_GIT_CLONE_DEPTH"] = "-1"
+
+		_, err := GitOptionsFromEnv(lookupEnv)
+		Expect(err).To(MatchError(`invalid GITOPS_GIT_CLONE_DEPTH "-1", must be a positive number`))
+	})
+})
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+)
+
+// MergeFlags tell what happens to a pull request once opened.
+type MergeFlags struct {
+	Method  string
+	Wait    bool
+	Timeout time.Duration
+	// ChecksGracePeriod is how long the checks of the pull request may take to be reported.
+	ChecksGracePeriod time.Duration
+}
+
+func AddMergeFlags(cmd *cobra.Command, flags *MergeFlags) {
+	cmd.Flags().StringVar(&flags.Method, "merge-method", "merge", "How the pull request is merged with --auto-merge or --wait: merge, squash or rebase")
+	cmd.Flags().BoolVar(&flags.Wait, "wait", false, "If set, wait for the checks of the pull request to pass, merge it and wait for Flux to apply the merged commit on the cluster")
+	cmd.Flags().DurationVar(&flags.Timeout, "wait-timeout", 30*time.Minute, "How long to wait for the pull request to be merged and applied with --wait")
+	cmd.Flags().DurationVar(&flags.ChecksGracePeriod, "checks-grace-period", 2*time.Minute, "How long to wait for the checks of the pull request to be reported with --wait, before merging it without checks")
+}
+
+// MergeMethod returns the merge method of the --merge-method flag.
+func (f MergeFlags) MergeMethod() (gitprovider.MergeMethod, error) {
+	method, err := gitproviders.ParseMergeMethod(f.Method)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse --merge-method: %w", err)
+	}
+
+	return method, nil
+}
+
+// Context returns a context that ends after the --wait-timeout when waiting.
+func (f MergeFlags) Context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if !f.Wait || f.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, f.Timeout)
+}
@@ -0,0 +1,36 @@
+package internal_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/cmd/internal"
+)
+
+var _ = Describe("MergeFlags", func() {
+	It("parses the merge method", func() {
+		method, err := internal.MergeFlags{Method: "squash"}.MergeMethod()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(method).To(Equal(gitprovider.MergeMethodSquash))
+
+		_, err = internal.MergeFlags{Method: "ff"}.MergeMethod()
+		Expect(err).To(MatchError(`failed to parse --merge-method: unknown merge method "ff", must be one of merge, squash or rebase`))
+	})
+
+	It("times out when waiting", func() {
+		ctx, cancel := internal.MergeFlags{Wait: true, Timeout: time.Minute}.Context(context.Background())
+		defer cancel()
+
+		_, ok := ctx.Deadline()
+		Expect(ok).To(BeTrue())
+
+		ctx, cancel = internal.MergeFlags{Timeout: time.Minute}.Context(context.Background())
+		defer cancel()
+
+		_, ok = ctx.Deadline()
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -1,19 +1,34 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 	"github.com/weaveworks/weave-gitops/pkg/logger"
 )
 
-const missingTokenErr = "the %q environment variable needs to be set to a valid token"
+const (
+	missingTokenErr = "the %q environment variable needs to be set to a valid token"
+
+	// providerCacheTTL is how long the responses of the provider APIs are cached during a command.
+	providerCacheTTL = 5 * time.Minute
+	// rateLimitMaxWait is how long a command waits for the quota of a provider API to be renewed.
+	rateLimitMaxWait = 2 * time.Minute
+)
 
 type gitProviderClient struct {
 	stdout        *os.File
 	lookupEnvFunc func(key string) (string, bool)
 	log           logger.Logger
+	// githubApps caches the installation tokens of the GitHub App per host
+	githubApps  map[string]*gitproviders.GitHubAppTokenSource
+	rateLimiter *gitproviders.RateLimiter
+	// providers are the cached providers per repository owner
+	providers map[string]gitproviders.GitProvider
 }
 
 func NewGitProviderClient(stdout *os.File, lookupEnvFunc func(key string) (string, bool), log logger.Logger) gitproviders.Client {
@@ -21,25 +36,131 @@ func NewGitProviderClient(stdout *os.File, lookupEnvFunc func(key string) (strin
 		stdout:        stdout,
 		lookupEnvFunc: lookupEnvFunc,
 		log:           log,
+		githubApps:    map[string]*gitproviders.GitHubAppTokenSource{},
+		rateLimiter: gitproviders.NewRateLimiter(rateLimitMaxWait, func(quota gitproviders.RateLimit) {
+			log.Warningf("%d of the %d requests allowed by the %s API are left until %s\n",
+				quota.Remaining, quota.Limit, quota.Host, quota.Reset.Format(time.Kitchen))
+		}),
+		providers: map[string]gitproviders.GitProvider{},
 	}
 }
 
-// GetProvider returns a GitProvider containing the token stored in the <git provider>_TOKEN
+// GetProvider returns a GitProvider containing the token stored in the <git provider>_TOKEN,
+// or authenticating as the GitHub App configured with the GITHUB_APP_* env vars.
 func (c *gitProviderClient) GetProvider(repoUrl gitproviders.RepoURL, getAccountType gitproviders.AccountTypeGetter) (gitproviders.GitProvider, error) {
-	token, err := GetToken(repoUrl, c.lookupEnvFunc)
+	if repoUrl.Provider() == gitproviders.GitProviderGit {
+		return c.getGitProvider(repoUrl)
+	}
+
+	githubApp, err := c.getGitHubApp(repoUrl)
 	if err != nil {
 		return nil, err
 	}
 
+	var token string
+
+	if githubApp == nil {
+		if token, err = GetToken(repoUrl, c.lookupEnvFunc); err != nil {
+			return nil, err
+		}
+	}
+
+	key := fmt.Sprintf("%s/%s/%s", repoUrl.Provider(), repoUrl.URL().Host, repoUrl.Owner())
+	if provider, ok := c.providers[key]; ok {
+		return provider, nil
+	}
+
 	provider, err := gitproviders.New(gitproviders.Config{
-		Provider: repoUrl.Provider(),
-		Token:    token,
-		Hostname: repoUrl.URL().Host,
+		Provider:    repoUrl.Provider(),
+		Token:       token,
+		Hostname:    repoUrl.URL().Host,
+		GitHubApp:   githubApp,
+		RateLimiter: c.rateLimiter,
 	}, repoUrl.Owner(), getAccountType)
 	if err != nil {
 		return nil, fmt.Errorf("error creating git provider client: %w", err)
 	}
 
+	provider = gitproviders.NewCachedProvider(provider, providerCacheTTL)
+	c.providers[key] = provider
+
+	return provider, nil
+}
+
+// GetToken returns the token stored in the <git provider>_TOKEN env var, or an installation token
+// of the GitHub App configured with the GITHUB_APP_* env vars.
+func (c *gitProviderClient) GetToken(repoUrl gitproviders.RepoURL) (string, error) {
+	githubApp, err := c.getGitHubApp(repoUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if githubApp != nil {
+		return githubApp.Token(context.Background())
+	}
+
+	return GetToken(repoUrl, c.lookupEnvFunc)
+}
+
+// getGitHubApp returns the token source of the GitHub App for GitHub repositories, if one is configured.
+func (c *gitProviderClient) getGitHubApp(repoUrl gitproviders.RepoURL) (*gitproviders.GitHubAppTokenSource, error) {
+	if repoUrl.Provider() != gitproviders.GitProviderGitHub {
+		return nil, nil
+	}
+
+	host := repoUrl.URL().Host
+	if source, ok := c.githubApps[host]; ok {
+		return source, nil
+	}
+
+	app, err := gitproviders.GitHubAppFromEnv(c.lookupEnvFunc)
+	if err != nil || app == nil {
+		return nil, err
+	}
+
+	source, err := gitproviders.NewGitHubAppTokenSource(*app, host)
+	if err != nil {
+		return nil, err
+	}
+
+	c.githubApps[host] = source
+
+	return source, nil
+}
+
+// getGitProvider returns the provider of plain git servers, which needs no token. It is configured
+// with the GITOPS_GIT_DEFAULT_BRANCH, GITOPS_GIT_COMPARE_URL and GITOPS_GIT_DIRECT_COMMIT env vars,
+// and commits like the git clients of GitOptionsFromEnv.
+func (c *gitProviderClient) getGitProvider(repoUrl gitproviders.RepoURL) (gitproviders.GitProvider, error) {
+	opts := gitproviders.GitOptions{}
+	opts.DefaultBranch, _ = c.lookupEnvFunc("GITOPS_GIT_DEFAULT_BRANCH")
+	opts.CompareURL, _ = c.lookupEnvFunc("GITOPS_GIT_COMPARE_URL")
+
+	if directCommit, ok := c.lookupEnvFunc("GITOPS_GIT_DIRECT_COMMIT"); ok && directCommit != "" {
+		v, err := strconv.ParseBool(directCommit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for GITOPS_GIT_DIRECT_COMMIT: %w", directCommit, err)
+		}
+
+		opts.DirectCommit = v
+	}
+
+	clientOpts, err := GitOptionsFromEnv(c.lookupEnvFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.ClientOptions = clientOpts
+
+	provider, err := gitproviders.New(gitproviders.Config{
+		Provider: gitproviders.GitProviderGit,
+		Hostname: repoUrl.URL().Host,
+		Git:      opts,
+	}, repoUrl.Owner(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating git provider client: %w", err)
+	}
+
 	return provider, nil
 }
 
@@ -49,6 +170,10 @@ func getTokenVarName(providerName gitproviders.GitProviderName) (string, error)
 		return "GITHUB_TOKEN", nil
 	case gitproviders.GitProviderGitLab:
 		return "GITLAB_TOKEN", nil
+	case gitproviders.GitProviderBitbucketServer:
+		return "BITBUCKET_SERVER_TOKEN", nil
+	case gitproviders.GitProviderGitea:
+		return "GITEA_TOKEN", nil
 	default:
 		return "", fmt.Errorf("unknown git provider: %q", providerName)
 	}
@@ -1,6 +1,11 @@
 package internal
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"os"
@@ -8,6 +13,7 @@ import (
 	"github.com/fluxcd/go-git-providers/gitprovider"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 	"github.com/weaveworks/weave-gitops/pkg/logger/loggerfakes"
 )
@@ -15,6 +21,7 @@ import (
 const (
 	githubToken = "github-token-123"
 	gitlabToken = "gitlab-token-abc"
+	giteaToken  = "gitea-token-xyz"
 )
 
 func fakeAccountGetterError(_ gitprovider.Client, _ string, _ string) (gitproviders.ProviderAccountType, error) {
@@ -30,6 +37,8 @@ func fakeEnvLookupExists(key string) (string, bool) {
 		return githubToken, true
 	} else if key == "GITLAB_TOKEN" {
 		return gitlabToken, true
+	} else if key == "GITEA_TOKEN" {
+		return giteaToken, true
 	} else {
 		return "", false
 	}
@@ -73,10 +82,20 @@ var _ = Describe("Get git provider", func() {
 				provider, err := client.GetProvider(repoUrl, fakeAccountGetterSuccess)
 
 				Expect(err).To(BeNil())
-				expectedProvider, _ := gitproviders.New(gitproviders.Config{Provider: repoUrl.Provider(), Token: githubToken}, repoUrl.Owner(), fakeAccountGetterSuccess)
-				Expect(provider).To(Equal(expectedProvider))
+				Expect(provider.GetProviderDomain()).To(Equal("github.com"))
 				Expect(fakeLogger.WarningfCallCount()).To(Equal(0), "we should not write out a warning message to the user if a token is set")
 			})
+
+			It("reuses the provider of the owner", func() {
+				provider, err := client.GetProvider(repoUrl, fakeAccountGetterSuccess)
+				Expect(err).NotTo(HaveOccurred())
+
+				otherRepo, _ := gitproviders.NewRepoURL("ssh://git@github.com/weaveworks/profiles.git")
+
+				other, err := client.GetProvider(otherRepo, fakeAccountGetterError)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(other).To(BeIdenticalTo(provider))
+			})
 		})
 
 		Describe("gitlab token", func() {
@@ -100,5 +119,117 @@ var _ = Describe("Get git provider", func() {
 				Expect(provider.GetProviderDomain()).To(Equal("gitlab.com"))
 			})
 		})
+
+		Describe("gitea token", func() {
+			BeforeEach(func() {
+				viper.Set("git-host-types", "gitea.example.com=gitea")
+				fakeLogger = &loggerfakes.FakeLogger{}
+				client = NewGitProviderClient(os.Stdout, fakeEnvLookupExists, fakeLogger)
+				repoUrl, _ = gitproviders.NewRepoURL("ssh://git@gitea.example.com/weaveworks/weave-gitops.git")
+			})
+
+			AfterEach(func() {
+				viper.Set("git-host-types", "")
+			})
+
+			It("success", func() {
+				provider, err := client.GetProvider(repoUrl, fakeAccountGetterError)
+
+				Expect(err).To(BeNil())
+				Expect(provider.GetProviderDomain()).To(Equal("gitea.example.com"))
+			})
+		})
+	})
+
+	Describe("GitHub App", func() {
+		It("is used instead of the token", func() {
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).NotTo(HaveOccurred())
+
+			env := map[string]string{
+				"GITHUB_APP_ID":              "12",
+				"GITHUB_APP_INSTALLATION_ID": "34",
+				"GITHUB_APP_PRIVATE_KEY":     string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+			}
+			client = NewGitProviderClient(os.Stdout, func(key string) (string, bool) {
+				v, ok := env[key]
+				return v, ok
+			}, &loggerfakes.FakeLogger{})
+			repoUrl, _ = gitproviders.NewRepoURL("ssh://git@github.com/weaveworks/weave-gitops.git")
+
+			provider, err := client.GetProvider(repoUrl, fakeAccountGetterSuccess)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(provider.GetProviderDomain()).To(Equal("github.com"))
+		})
+
+		It("fails on an incomplete configuration", func() {
+			client = NewGitProviderClient(os.Stdout, func(key string) (string, bool) {
+				if key == "GITHUB_APP_ID" {
+					return "12", true
+				}
+
+				return fakeEnvLookupExists(key)
+			}, &loggerfakes.FakeLogger{})
+			repoUrl, _ = gitproviders.NewRepoURL("ssh://git@github.com/weaveworks/weave-gitops.git")
+
+			_, err := client.GetProvider(repoUrl, fakeAccountGetterSuccess)
+			Expect(err).To(MatchError(ContainSubstring("invalid GITHUB_APP_INSTALLATION_ID")))
+		})
+	})
+
+	Describe("plain git remote", func() {
+		BeforeEach(func() {
+			viper.Set("git-host-types", "git.example.com=git")
+			repoUrl, _ = gitproviders.NewRepoURL("ssh://git@git.example.com/weaveworks/weave-gitops.git")
+		})
+
+		AfterEach(func() {
+			viper.Set("git-host-types", "")
+		})
+
+		It("needs no token", func() {
+			client = NewGitProviderClient(os.Stdout, fakeEnvLookupExists, &loggerfakes.FakeLogger{})
+
+			provider, err := client.GetProvider(repoUrl, fakeAccountGetterError)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(provider.GetProviderDomain()).To(Equal("git.example.com"))
+
+			branch, err := provider.GetDefaultBranch(context.Background(), repoUrl)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(branch).To(Equal("main"))
+		})
+
+		It("is configured with env variables", func() {
+			env := map[string]string{"GITOPS_GIT_DEFAULT_BRANCH": "trunk", "GITOPS_GIT_DIRECT_COMMIT": "yes"}
+			client = NewGitProviderClient(os.Stdout, func(key string) (string, bool) {
+				v, ok := env[key]
+				return v, ok
+			}, &loggerfakes.FakeLogger{})
+
+			_, err := client.GetProvider(repoUrl, fakeAccountGetterSuccess)
+			Expect(err).To(MatchError(ContainSubstring(`invalid value "yes" for GITOPS_GIT_DIRECT_COMMIT`)))
+
+			env["GITOPS_GIT_DIRECT_COMMIT"] = "true"
+			provider, err := client.GetProvider(repoUrl, fakeAccountGetterSuccess)
+			Expect(err).NotTo(HaveOccurred())
+
+			branch, err := provider.GetDefaultBranch(context.Background(), repoUrl)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(branch).To(Equal("trunk"))
+			Expect(provider.MergePullRequest(context.Background(), repoUrl, 0, gitprovider.MergeMethodMerge, "merge")).To(Succeed())
+		})
+	})
+
+	Describe("token missing in env variable", func() {
+		It("names the env variable of the provider", func() {
+			viper.Set("git-host-types", "bitbucket.example.com=bitbucket-server")
+			defer viper.Set("git-host-types", "")
+
+			client = NewGitProviderClient(os.Stdout, fakeEnvLookupExists, &loggerfakes.FakeLogger{})
+			repoUrl, _ = gitproviders.NewRepoURL("ssh://git@bitbucket.example.com:7999/proj/weave-gitops.git")
+
+			_, err := client.GetProvider(repoUrl, fakeAccountGetterSuccess)
+			Expect(err).To(MatchError(`the "BITBUCKET_SERVER_TOKEN" environment variable needs to be set to a valid token`))
+		})
 	})
 })
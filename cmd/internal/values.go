@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/pkg/helm"
+	"helm.sh/helm/v3/pkg/strvals"
+	"sigs.k8s.io/yaml"
+)
+
+// ValuesFlags holds the values of a profile given on the command line.
+type ValuesFlags struct {
+	Files      []string
+	Set        []string
+	ValuesFrom []string
+}
+
+func AddValuesFlags(cmd *cobra.Command, flags *ValuesFlags) {
+	cmd.Flags().StringArrayVar(&flags.Files, "values", nil, "Path to a YAML file with values for the profile, checked against the values schema of the profile (can specify multiple, later files override earlier ones)")
+	cmd.Flags().StringArrayVar(&flags.Set, "set", nil, "Set values for the profile, applied after the --values files (can specify multiple or separate values with commas: key1=val1,key2=val2)")
+	cmd.Flags().StringArrayVar(&flags.ValuesFrom, "values-from", nil, "Reference to a ConfigMap or Secret holding values for the profile, as ConfigMap/<name>[:<key>] or Secret/<name>[:<key>] (can specify multiple)")
+}
+
+// Values returns the values of the --values files merged in order, with the
+// --set values applied on top.
+func (f ValuesFlags) Values() (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	for _, path := range f.Files {
+		fileValues, err := readValuesFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		values = helm.MergeValues(values, fileValues)
+	}
+
+	for _, s := range f.Set {
+		if err := strvals.ParseInto(s, values); err != nil {
+			return nil, fmt.Errorf("failed to parse --set %s: %w", s, err)
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	return values, nil
+}
+
+// References returns the ConfigMaps and Secrets of the --values-from flags.
+func (f ValuesFlags) References() ([]helmv2beta1.ValuesReference, error) {
+	var refs []helmv2beta1.ValuesReference
+
+	for _, s := range f.ValuesFrom {
+		ref, err := helm.ParseValuesReference(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse --values-from: %w", err)
+		}
+
+		refs = append(refs, ref)
+	}
+
+	return refs, nil
+}
+
+func readValuesFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --values file: %w", err)
+	}
+
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse --values file %s: %w", path, err)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,61 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/cmd/internal"
+)
+
+var _ = Describe("ValuesFlags", func() {
+	writeFile := func(content string) string {
+		path := filepath.Join(GinkgoT().TempDir(), "values.yaml")
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+
+		return path
+	}
+
+	It("merges the files in order and applies --set on top", func() {
+		flags := internal.ValuesFlags{
+			Files: []string{
+				writeFile("replicaCount: 1\nimage:\n  repository: podinfo\n  tag: 6.0.0\n"),
+				writeFile("image:\n  tag: 6.0.1\n"),
+			},
+			Set: []string{"replicaCount=3,ingress.enabled=true"},
+		}
+
+		values, err := flags.Values()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(Equal(map[string]interface{}{
+			"replicaCount": int64(3),
+			"image":        map[string]interface{}{"repository": "podinfo", "tag": "6.0.1"},
+			"ingress":      map[string]interface{}{"enabled": true},
+		}))
+	})
+
+	It("returns no values when none are given", func() {
+		values, err := internal.ValuesFlags{}.Values()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(BeNil())
+	})
+
+	It("fails on an invalid --set", func() {
+		_, err := internal.ValuesFlags{Set: []string{"a.=b"}}.Values()
+		Expect(err).To(MatchError(ContainSubstring("failed to parse --set a.=b")))
+	})
+
+	It("parses the --values-from references", func() {
+		refs, err := internal.ValuesFlags{ValuesFrom: []string{"ConfigMap/common", "Secret/credentials:prod.yaml"}}.References()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(refs).To(Equal([]helmv2beta1.ValuesReference{
+			{Kind: "ConfigMap", Name: "common"},
+			{Kind: "Secret", Name: "credentials", ValuesKey: "prod.yaml"},
+		}))
+
+		_, err = internal.ValuesFlags{ValuesFrom: []string{"common"}}.References()
+		Expect(err).To(MatchError(ContainSubstring("failed to parse --values-from")))
+	})
+})
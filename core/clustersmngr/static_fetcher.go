@@ -0,0 +1,84 @@
+package clustersmngr
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TokenSecretKey is the key of the bearer token in a leaf cluster Secret.
+	TokenSecretKey = "token"
+	// CASecretKey is the key of the optional CA bundle in a leaf cluster Secret.
+	CASecretKey = "ca.crt"
+)
+
+// StaticClusterFetcher returns the cluster the server is running in and a
+// configured list of leaf clusters. The credentials of the leaf clusters are
+// read from their SecretRef on every Fetch, so rotated tokens are picked up.
+type StaticClusterFetcher struct {
+	local     ClusterFetcher
+	client    client.Client
+	namespace string
+
+	mu       sync.RWMutex
+	clusters []Cluster
+}
+
+// NewStaticClusterFetcher creates a StaticClusterFetcher. Secrets are looked up
+// in namespace with the given client.
+func NewStaticClusterFetcher(config *rest.Config, cl client.Client, namespace string, clusters []Cluster) (*StaticClusterFetcher, error) {
+	local, err := NewSingleClusterFetcher(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StaticClusterFetcher{
+		local:     local,
+		client:    cl,
+		namespace: namespace,
+		clusters:  clusters,
+	}, nil
+}
+
+// SetClusters replaces the list of leaf clusters.
+func (cf *StaticClusterFetcher) SetClusters(clusters []Cluster) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	cf.clusters = clusters
+}
+
+func (cf *StaticClusterFetcher) Fetch(ctx context.Context) ([]Cluster, error) {
+	result, err := cf.local.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.mu.RLock()
+	leafs := append([]Cluster{}, cf.clusters...)
+	cf.mu.RUnlock()
+
+	for _, c := range leafs {
+		var secret corev1.Secret
+		if err := cf.client.Get(ctx, client.ObjectKey{Namespace: cf.namespace, Name: c.SecretRef}, &secret); err != nil {
+			return nil, fmt.Errorf("failed getting secret %s for cluster %s: %w", c.SecretRef, c.Name, err)
+		}
+
+		token, ok := secret.Data[TokenSecretKey]
+		if !ok {
+			return nil, fmt.Errorf("secret %s for cluster %s has no %q key", c.SecretRef, c.Name, TokenSecretKey)
+		}
+
+		c.BearerToken = string(token)
+		c.TLSConfig = rest.TLSClientConfig{CAData: secret.Data[CASecretKey]}
+
+		result = append(result, c)
+	}
+
+	return result, nil
+}
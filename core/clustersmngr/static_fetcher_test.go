@@ -0,0 +1,45 @@
+package clustersmngr_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/core/clustersmngr"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStaticFetcher(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "leaf-creds", Namespace: "flux-system"},
+		Data: map[string][]byte{
+			"token":  []byte("leaf-token"),
+			"ca.crt": []byte("leaf-ca"),
+		},
+	}
+	cl := fake.NewClientBuilder().WithScheme(kube.CreateScheme()).WithObjects(secret).Build()
+
+	fetcher, err := clustersmngr.NewStaticClusterFetcher(&rest.Config{Host: "my-host"}, cl, "flux-system", []clustersmngr.Cluster{
+		{Name: "leaf", Server: "https://leaf:6443", SecretRef: "leaf-creds"},
+	})
+	g.Expect(err).To(BeNil())
+
+	clusters, err := fetcher.Fetch(context.TODO())
+	g.Expect(err).To(BeNil())
+	g.Expect(clusters).To(HaveLen(2))
+	g.Expect(clusters[0].Name).To(Equal(clustersmngr.DefaultCluster))
+	g.Expect(clusters[1].Name).To(Equal("leaf"))
+	g.Expect(clusters[1].BearerToken).To(Equal("leaf-token"))
+	g.Expect(clusters[1].TLSConfig.CAData).To(Equal([]byte("leaf-ca")))
+
+	fetcher.SetClusters([]clustersmngr.Cluster{{Name: "other", Server: "https://other:6443", SecretRef: "missing"}})
+
+	_, err = fetcher.Fetch(context.TODO())
+	g.Expect(err).To(MatchError(ContainSubstring("failed getting secret missing for cluster other")))
+}
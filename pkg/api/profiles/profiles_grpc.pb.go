@@ -23,6 +23,12 @@ type ProfilesClient interface {
 	GetProfiles(ctx context.Context, in *GetProfilesRequest, opts ...grpc.CallOption) (*GetProfilesResponse, error)
 	// GetProfileValues returns a list of values for a given version of a profile from the cluster.
 	GetProfileValues(ctx context.Context, in *GetProfileValuesRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error)
+	// GetProfileValuesSchema returns the JSON schema of the values of a given version of a profile.
+	GetProfileValuesSchema(ctx context.Context, in *GetProfileValuesSchemaRequest, opts ...grpc.CallOption) (*GetProfileValuesSchemaResponse, error)
+	// ValidateProfileValues checks values for a given version of a profile against its values schema.
+	ValidateProfileValues(ctx context.Context, in *ValidateProfileValuesRequest, opts ...grpc.CallOption) (*ValidateProfileValuesResponse, error)
+	// ListProfileUpgrades lists the HelmReleases installed from a profile that can be upgraded to a newer version.
+	ListProfileUpgrades(ctx context.Context, in *ListProfileUpgradesRequest, opts ...grpc.CallOption) (*ListProfileUpgradesResponse, error)
 }
 
 type profilesClient struct {
@@ -51,6 +57,33 @@ func (c *profilesClient) GetProfileValues(ctx context.Context, in *GetProfileVal
 	return out, nil
 }
 
+func (c *profilesClient) GetProfileValuesSchema(ctx context.Context, in *GetProfileValuesSchemaRequest, opts ...grpc.CallOption) (*GetProfileValuesSchemaResponse, error) {
+	out := new(GetProfileValuesSchemaResponse)
+	err := c.cc.Invoke(ctx, "/wego_profiles.v1.Profiles/GetProfileValuesSchema", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *profilesClient) ValidateProfileValues(ctx context.Context, in *ValidateProfileValuesRequest, opts ...grpc.CallOption) (*ValidateProfileValuesResponse, error) {
+	out := new(ValidateProfileValuesResponse)
+	err := c.cc.Invoke(ctx, "/wego_profiles.v1.Profiles/ValidateProfileValues", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *profilesClient) ListProfileUpgrades(ctx context.Context, in *ListProfileUpgradesRequest, opts ...grpc.CallOption) (*ListProfileUpgradesResponse, error) {
+	out := new(ListProfileUpgradesResponse)
+	err := c.cc.Invoke(ctx, "/wego_profiles.v1.Profiles/ListProfileUpgrades", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ProfilesServer is the server API for Profiles service.
 // All implementations must embed UnimplementedProfilesServer
 // for forward compatibility
@@ -59,6 +92,12 @@ type ProfilesServer interface {
 	GetProfiles(context.Context, *GetProfilesRequest) (*GetProfilesResponse, error)
 	// GetProfileValues returns a list of values for a given version of a profile from the cluster.
 	GetProfileValues(context.Context, *GetProfileValuesRequest) (*httpbody.HttpBody, error)
+	// GetProfileValuesSchema returns the JSON schema of the values of a given version of a profile.
+	GetProfileValuesSchema(context.Context, *GetProfileValuesSchemaRequest) (*GetProfileValuesSchemaResponse, error)
+	// ValidateProfileValues checks values for a given version of a profile against its values schema.
+	ValidateProfileValues(context.Context, *ValidateProfileValuesRequest) (*ValidateProfileValuesResponse, error)
+	// ListProfileUpgrades lists the HelmReleases installed from a profile that can be upgraded to a newer version.
+	ListProfileUpgrades(context.Context, *ListProfileUpgradesRequest) (*ListProfileUpgradesResponse, error)
 	mustEmbedUnimplementedProfilesServer()
 }
 
@@ -72,6 +111,15 @@ func (UnimplementedProfilesServer) GetProfiles(context.Context, *GetProfilesRequ
 func (UnimplementedProfilesServer) GetProfileValues(context.Context, *GetProfileValuesRequest) (*httpbody.HttpBody, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetProfileValues not implemented")
 }
+func (UnimplementedProfilesServer) GetProfileValuesSchema(context.Context, *GetProfileValuesSchemaRequest) (*GetProfileValuesSchemaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProfileValuesSchema not implemented")
+}
+func (UnimplementedProfilesServer) ValidateProfileValues(context.Context, *ValidateProfileValuesRequest) (*ValidateProfileValuesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateProfileValues not implemented")
+}
+func (UnimplementedProfilesServer) ListProfileUpgrades(context.Context, *ListProfileUpgradesRequest) (*ListProfileUpgradesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProfileUpgrades not implemented")
+}
 func (UnimplementedProfilesServer) mustEmbedUnimplementedProfilesServer() {}
 
 // UnsafeProfilesServer may be embedded to opt out of forward compatibility for this service.
@@ -121,6 +169,60 @@ func _Profiles_GetProfileValues_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Profiles_GetProfileValuesSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProfileValuesSchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProfilesServer).GetProfileValuesSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wego_profiles.v1.Profiles/GetProfileValuesSchema",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProfilesServer).GetProfileValuesSchema(ctx, req.(*GetProfileValuesSchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Profiles_ValidateProfileValues_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateProfileValuesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProfilesServer).ValidateProfileValues(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wego_profiles.v1.Profiles/ValidateProfileValues",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProfilesServer).ValidateProfileValues(ctx, req.(*ValidateProfileValuesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Profiles_ListProfileUpgrades_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProfileUpgradesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProfilesServer).ListProfileUpgrades(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wego_profiles.v1.Profiles/ListProfileUpgrades",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProfilesServer).ListProfileUpgrades(ctx, req.(*ListProfileUpgradesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Profiles_ServiceDesc is the grpc.ServiceDesc for Profiles service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -136,6 +238,18 @@ var Profiles_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetProfileValues",
 			Handler:    _Profiles_GetProfileValues_Handler,
 		},
+		{
+			MethodName: "GetProfileValuesSchema",
+			Handler:    _Profiles_GetProfileValuesSchema_Handler,
+		},
+		{
+			MethodName: "ValidateProfileValues",
+			Handler:    _Profiles_ValidateProfileValues_Handler,
+		},
+		{
+			MethodName: "ListProfileUpgrades",
+			Handler:    _Profiles_ListProfileUpgrades_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/profiles/profiles.proto",
@@ -0,0 +1,687 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package apiclientfakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/weaveworks/weave-gitops/pkg/api/applications"
+	"github.com/weaveworks/weave-gitops/pkg/apiclient"
+)
+
+type FakeApplicationsClient struct {
+	AuthenticateStub        func(context.Context, *applications.AuthenticateRequest) (*applications.AuthenticateResponse, error)
+	authenticateMutex       sync.RWMutex
+	authenticateArgsForCall []struct {
+		arg1 context.Context
+		arg2 *applications.AuthenticateRequest
+	}
+	authenticateReturns struct {
+		result1 *applications.AuthenticateResponse
+		result2 error
+	}
+	authenticateReturnsOnCall map[int]struct {
+		result1 *applications.AuthenticateResponse
+		result2 error
+	}
+	AuthorizeGitlabStub        func(context.Context, *applications.AuthorizeGitlabRequest) (*applications.AuthorizeGitlabResponse, error)
+	authorizeGitlabMutex       sync.RWMutex
+	authorizeGitlabArgsForCall []struct {
+		arg1 context.Context
+		arg2 *applications.AuthorizeGitlabRequest
+	}
+	authorizeGitlabReturns struct {
+		result1 *applications.AuthorizeGitlabResponse
+		result2 error
+	}
+	authorizeGitlabReturnsOnCall map[int]struct {
+		result1 *applications.AuthorizeGitlabResponse
+		result2 error
+	}
+	GetFeatureFlagsStub        func(context.Context, *applications.GetFeatureFlagsRequest) (*applications.GetFeatureFlagsResponse, error)
+	getFeatureFlagsMutex       sync.RWMutex
+	getFeatureFlagsArgsForCall []struct {
+		arg1 context.Context
+		arg2 *applications.GetFeatureFlagsRequest
+	}
+	getFeatureFlagsReturns struct {
+		result1 *applications.GetFeatureFlagsResponse
+		result2 error
+	}
+	getFeatureFlagsReturnsOnCall map[int]struct {
+		result1 *applications.GetFeatureFlagsResponse
+		result2 error
+	}
+	GetGithubAuthStatusStub        func(context.Context, *applications.GetGithubAuthStatusRequest) (*applications.GetGithubAuthStatusResponse, error)
+	getGithubAuthStatusMutex       sync.RWMutex
+	getGithubAuthStatusArgsForCall []struct {
+		arg1 context.Context
+		arg2 *applications.GetGithubAuthStatusRequest
+	}
+	getGithubAuthStatusReturns struct {
+		result1 *applications.GetGithubAuthStatusResponse
+		result2 error
+	}
+	getGithubAuthStatusReturnsOnCall map[int]struct {
+		result1 *applications.GetGithubAuthStatusResponse
+		result2 error
+	}
+	GetGithubDeviceCodeStub        func(context.Context, *applications.GetGithubDeviceCodeRequest) (*applications.GetGithubDeviceCodeResponse, error)
+	getGithubDeviceCodeMutex       sync.RWMutex
+	getGithubDeviceCodeArgsForCall []struct {
+		arg1 context.Context
+		arg2 *applications.GetGithubDeviceCodeRequest
+	}
+	getGithubDeviceCodeReturns struct {
+		result1 *applications.GetGithubDeviceCodeResponse
+		result2 error
+	}
+	getGithubDeviceCodeReturnsOnCall map[int]struct {
+		result1 *applications.GetGithubDeviceCodeResponse
+		result2 error
+	}
+	GetGitlabAuthURLStub        func(context.Context, *applications.GetGitlabAuthURLRequest) (*applications.GetGitlabAuthURLResponse, error)
+	getGitlabAuthURLMutex       sync.RWMutex
+	getGitlabAuthURLArgsForCall []struct {
+		arg1 context.Context
+		arg2 *applications.GetGitlabAuthURLRequest
+	}
+	getGitlabAuthURLReturns struct {
+		result1 *applications.GetGitlabAuthURLResponse
+		result2 error
+	}
+	getGitlabAuthURLReturnsOnCall map[int]struct {
+		result1 *applications.GetGitlabAuthURLResponse
+		result2 error
+	}
+	ParseRepoURLStub        func(context.Context, *applications.ParseRepoURLRequest) (*applications.ParseRepoURLResponse, error)
+	parseRepoURLMutex       sync.RWMutex
+	parseRepoURLArgsForCall []struct {
+		arg1 context.Context
+		arg2 *applications.ParseRepoURLRequest
+	}
+	parseRepoURLReturns struct {
+		result1 *applications.ParseRepoURLResponse
+		result2 error
+	}
+	parseRepoURLReturnsOnCall map[int]struct {
+		result1 *applications.ParseRepoURLResponse
+		result2 error
+	}
+	ValidateProviderTokenStub        func(context.Context, *applications.ValidateProviderTokenRequest) (*applications.ValidateProviderTokenResponse, error)
+	validateProviderTokenMutex       sync.RWMutex
+	validateProviderTokenArgsForCall []struct {
+		arg1 context.Context
+		arg2 *applications.ValidateProviderTokenRequest
+	}
+	validateProviderTokenReturns struct {
+		result1 *applications.ValidateProviderTokenResponse
+		result2 error
+	}
+	validateProviderTokenReturnsOnCall map[int]struct {
+		result1 *applications.ValidateProviderTokenResponse
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeApplicationsClient) Authenticate(arg1 context.Context, arg2 *applications.AuthenticateRequest) (*applications.AuthenticateResponse, error) {
+	fake.authenticateMutex.Lock()
+	ret, specificReturn := fake.authenticateReturnsOnCall[len(fake.authenticateArgsForCall)]
+	fake.authenticateArgsForCall = append(fake.authenticateArgsForCall, struct {
+		arg1 context.Context
+		arg2 *applications.AuthenticateRequest
+	}{arg1, arg2})
+	stub := fake.AuthenticateStub
+	fakeReturns := fake.authenticateReturns
+	fake.recordInvocation("Authenticate", []interface{}{arg1, arg2})
+	fake.authenticateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeApplicationsClient) AuthenticateCallCount() int {
+	fake.authenticateMutex.RLock()
+	defer fake.authenticateMutex.RUnlock()
+	return len(fake.authenticateArgsForCall)
+}
+
+func (fake *FakeApplicationsClient) AuthenticateCalls(stub func(context.Context, *applications.AuthenticateRequest) (*applications.AuthenticateResponse, error)) {
+	fake.authenticateMutex.Lock()
+	defer fake.authenticateMutex.Unlock()
+	fake.AuthenticateStub = stub
+}
+
+func (fake *FakeApplicationsClient) AuthenticateArgsForCall(i int) (context.Context, *applications.AuthenticateRequest) {
+	fake.authenticateMutex.RLock()
+	defer fake.authenticateMutex.RUnlock()
+	argsForCall := fake.authenticateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeApplicationsClient) AuthenticateReturns(result1 *applications.AuthenticateResponse, result2 error) {
+	fake.authenticateMutex.Lock()
+	defer fake.authenticateMutex.Unlock()
+	fake.AuthenticateStub = nil
+	fake.authenticateReturns = struct {
+		result1 *applications.AuthenticateResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) AuthenticateReturnsOnCall(i int, result1 *applications.AuthenticateResponse, result2 error) {
+	fake.authenticateMutex.Lock()
+	defer fake.authenticateMutex.Unlock()
+	fake.AuthenticateStub = nil
+	if fake.authenticateReturnsOnCall == nil {
+		fake.authenticateReturnsOnCall = make(map[int]struct {
+			result1 *applications.AuthenticateResponse
+			result2 error
+		})
+	}
+	fake.authenticateReturnsOnCall[i] = struct {
+		result1 *applications.AuthenticateResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) AuthorizeGitlab(arg1 context.Context, arg2 *applications.AuthorizeGitlabRequest) (*applications.AuthorizeGitlabResponse, error) {
+	fake.authorizeGitlabMutex.Lock()
+	ret, specificReturn := fake.authorizeGitlabReturnsOnCall[len(fake.authorizeGitlabArgsForCall)]
+	fake.authorizeGitlabArgsForCall = append(fake.authorizeGitlabArgsForCall, struct {
+		arg1 context.Context
+		arg2 *applications.AuthorizeGitlabRequest
+	}{arg1, arg2})
+	stub := fake.AuthorizeGitlabStub
+	fakeReturns := fake.authorizeGitlabReturns
+	fake.recordInvocation("AuthorizeGitlab", []interface{}{arg1, arg2})
+	fake.authorizeGitlabMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeApplicationsClient) AuthorizeGitlabCallCount() int {
+	fake.authorizeGitlabMutex.RLock()
+	defer fake.authorizeGitlabMutex.RUnlock()
+	return len(fake.authorizeGitlabArgsForCall)
+}
+
+func (fake *FakeApplicationsClient) AuthorizeGitlabCalls(stub func(context.Context, *applications.AuthorizeGitlabRequest) (*applications.AuthorizeGitlabResponse, error)) {
+	fake.authorizeGitlabMutex.Lock()
+	defer fake.authorizeGitlabMutex.Unlock()
+	fake.AuthorizeGitlabStub = stub
+}
+
+func (fake *FakeApplicationsClient) AuthorizeGitlabArgsForCall(i int) (context.Context, *applications.AuthorizeGitlabRequest) {
+	fake.authorizeGitlabMutex.RLock()
+	defer fake.authorizeGitlabMutex.RUnlock()
+	argsForCall := fake.authorizeGitlabArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeApplicationsClient) AuthorizeGitlabReturns(result1 *applications.AuthorizeGitlabResponse, result2 error) {
+	fake.authorizeGitlabMutex.Lock()
+	defer fake.authorizeGitlabMutex.Unlock()
+	fake.AuthorizeGitlabStub = nil
+	fake.authorizeGitlabReturns = struct {
+		result1 *applications.AuthorizeGitlabResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) AuthorizeGitlabReturnsOnCall(i int, result1 *applications.AuthorizeGitlabResponse, result2 error) {
+	fake.authorizeGitlabMutex.Lock()
+	defer fake.authorizeGitlabMutex.Unlock()
+	fake.AuthorizeGitlabStub = nil
+	if fake.authorizeGitlabReturnsOnCall == nil {
+		fake.authorizeGitlabReturnsOnCall = make(map[int]struct {
+			result1 *applications.AuthorizeGitlabResponse
+			result2 error
+		})
+	}
+	fake.authorizeGitlabReturnsOnCall[i] = struct {
+		result1 *applications.AuthorizeGitlabResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) GetFeatureFlags(arg1 context.Context, arg2 *applications.GetFeatureFlagsRequest) (*applications.GetFeatureFlagsResponse, error) {
+	fake.getFeatureFlagsMutex.Lock()
+	ret, specificReturn := fake.getFeatureFlagsReturnsOnCall[len(fake.getFeatureFlagsArgsForCall)]
+	fake.getFeatureFlagsArgsForCall = append(fake.getFeatureFlagsArgsForCall, struct {
+		arg1 context.Context
+		arg2 *applications.GetFeatureFlagsRequest
+	}{arg1, arg2})
+	stub := fake.GetFeatureFlagsStub
+	fakeReturns := fake.getFeatureFlagsReturns
+	fake.recordInvocation("GetFeatureFlags", []interface{}{arg1, arg2})
+	fake.getFeatureFlagsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeApplicationsClient) GetFeatureFlagsCallCount() int {
+	fake.getFeatureFlagsMutex.RLock()
+	defer fake.getFeatureFlagsMutex.RUnlock()
+	return len(fake.getFeatureFlagsArgsForCall)
+}
+
+func (fake *FakeApplicationsClient) GetFeatureFlagsCalls(stub func(context.Context, *applications.GetFeatureFlagsRequest) (*applications.GetFeatureFlagsResponse, error)) {
+	fake.getFeatureFlagsMutex.Lock()
+	defer fake.getFeatureFlagsMutex.Unlock()
+	fake.GetFeatureFlagsStub = stub
+}
+
+func (fake *FakeApplicationsClient) GetFeatureFlagsArgsForCall(i int) (context.Context, *applications.GetFeatureFlagsRequest) {
+	fake.getFeatureFlagsMutex.RLock()
+	defer fake.getFeatureFlagsMutex.RUnlock()
+	argsForCall := fake.getFeatureFlagsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeApplicationsClient) GetFeatureFlagsReturns(result1 *applications.GetFeatureFlagsResponse, result2 error) {
+	fake.getFeatureFlagsMutex.Lock()
+	defer fake.getFeatureFlagsMutex.Unlock()
+	fake.GetFeatureFlagsStub = nil
+	fake.getFeatureFlagsReturns = struct {
+		result1 *applications.GetFeatureFlagsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) GetFeatureFlagsReturnsOnCall(i int, result1 *applications.GetFeatureFlagsResponse, result2 error) {
+	fake.getFeatureFlagsMutex.Lock()
+	defer fake.getFeatureFlagsMutex.Unlock()
+	fake.GetFeatureFlagsStub = nil
+	if fake.getFeatureFlagsReturnsOnCall == nil {
+		fake.getFeatureFlagsReturnsOnCall = make(map[int]struct {
+			result1 *applications.GetFeatureFlagsResponse
+			result2 error
+		})
+	}
+	fake.getFeatureFlagsReturnsOnCall[i] = struct {
+		result1 *applications.GetFeatureFlagsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) GetGithubAuthStatus(arg1 context.Context, arg2 *applications.GetGithubAuthStatusRequest) (*applications.GetGithubAuthStatusResponse, error) {
+	fake.getGithubAuthStatusMutex.Lock()
+	ret, specificReturn := fake.getGithubAuthStatusReturnsOnCall[len(fake.getGithubAuthStatusArgsForCall)]
+	fake.getGithubAuthStatusArgsForCall = append(fake.getGithubAuthStatusArgsForCall, struct {
+		arg1 context.Context
+		arg2 *applications.GetGithubAuthStatusRequest
+	}{arg1, arg2})
+	stub := fake.GetGithubAuthStatusStub
+	fakeReturns := fake.getGithubAuthStatusReturns
+	fake.recordInvocation("GetGithubAuthStatus", []interface{}{arg1, arg2})
+	fake.getGithubAuthStatusMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeApplicationsClient) GetGithubAuthStatusCallCount() int {
+	fake.getGithubAuthStatusMutex.RLock()
+	defer fake.getGithubAuthStatusMutex.RUnlock()
+	return len(fake.getGithubAuthStatusArgsForCall)
+}
+
+func (fake *FakeApplicationsClient) GetGithubAuthStatusCalls(stub func(context.Context, *applications.GetGithubAuthStatusRequest) (*applications.GetGithubAuthStatusResponse, error)) {
+	fake.getGithubAuthStatusMutex.Lock()
+	defer fake.getGithubAuthStatusMutex.Unlock()
+	fake.GetGithubAuthStatusStub = stub
+}
+
+func (fake *FakeApplicationsClient) GetGithubAuthStatusArgsForCall(i int) (context.Context, *applications.GetGithubAuthStatusRequest) {
+	fake.getGithubAuthStatusMutex.RLock()
+	defer fake.getGithubAuthStatusMutex.RUnlock()
+	argsForCall := fake.getGithubAuthStatusArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeApplicationsClient) GetGithubAuthStatusReturns(result1 *applications.GetGithubAuthStatusResponse, result2 error) {
+	fake.getGithubAuthStatusMutex.Lock()
+	defer fake.getGithubAuthStatusMutex.Unlock()
+	fake.GetGithubAuthStatusStub = nil
+	fake.getGithubAuthStatusReturns = struct {
+		result1 *applications.GetGithubAuthStatusResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) GetGithubAuthStatusReturnsOnCall(i int, result1 *applications.GetGithubAuthStatusResponse, result2 error) {
+	fake.getGithubAuthStatusMutex.Lock()
+	defer fake.getGithubAuthStatusMutex.Unlock()
+	fake.GetGithubAuthStatusStub = nil
+	if fake.getGithubAuthStatusReturnsOnCall == nil {
+		fake.getGithubAuthStatusReturnsOnCall = make(map[int]struct {
+			result1 *applications.GetGithubAuthStatusResponse
+			result2 error
+		})
+	}
+	fake.getGithubAuthStatusReturnsOnCall[i] = struct {
+		result1 *applications.GetGithubAuthStatusResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) GetGithubDeviceCode(arg1 context.Context, arg2 *applications.GetGithubDeviceCodeRequest) (*applications.GetGithubDeviceCodeResponse, error) {
+	fake.getGithubDeviceCodeMutex.Lock()
+	ret, specificReturn := fake.getGithubDeviceCodeReturnsOnCall[len(fake.getGithubDeviceCodeArgsForCall)]
+	fake.getGithubDeviceCodeArgsForCall = append(fake.getGithubDeviceCodeArgsForCall, struct {
+		arg1 context.Context
+		arg2 *applications.GetGithubDeviceCodeRequest
+	}{arg1, arg2})
+	stub := fake.GetGithubDeviceCodeStub
+	fakeReturns := fake.getGithubDeviceCodeReturns
+	fake.recordInvocation("GetGithubDeviceCode", []interface{}{arg1, arg2})
+	fake.getGithubDeviceCodeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeApplicationsClient) GetGithubDeviceCodeCallCount() int {
+	fake.getGithubDeviceCodeMutex.RLock()
+	defer fake.getGithubDeviceCodeMutex.RUnlock()
+	return len(fake.getGithubDeviceCodeArgsForCall)
+}
+
+func (fake *FakeApplicationsClient) GetGithubDeviceCodeCalls(stub func(context.Context, *applications.GetGithubDeviceCodeRequest) (*applications.GetGithubDeviceCodeResponse, error)) {
+	fake.getGithubDeviceCodeMutex.Lock()
+	defer fake.getGithubDeviceCodeMutex.Unlock()
+	fake.GetGithubDeviceCodeStub = stub
+}
+
+func (fake *FakeApplicationsClient) GetGithubDeviceCodeArgsForCall(i int) (context.Context, *applications.GetGithubDeviceCodeRequest) {
+	fake.getGithubDeviceCodeMutex.RLock()
+	defer fake.getGithubDeviceCodeMutex.RUnlock()
+	argsForCall := fake.getGithubDeviceCodeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeApplicationsClient) GetGithubDeviceCodeReturns(result1 *applications.GetGithubDeviceCodeResponse, result2 error) {
+	fake.getGithubDeviceCodeMutex.Lock()
+	defer fake.getGithubDeviceCodeMutex.Unlock()
+	fake.GetGithubDeviceCodeStub = nil
+	fake.getGithubDeviceCodeReturns = struct {
+		result1 *applications.GetGithubDeviceCodeResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) GetGithubDeviceCodeReturnsOnCall(i int, result1 *applications.GetGithubDeviceCodeResponse, result2 error) {
+	fake.getGithubDeviceCodeMutex.Lock()
+	defer fake.getGithubDeviceCodeMutex.Unlock()
+	fake.GetGithubDeviceCodeStub = nil
+	if fake.getGithubDeviceCodeReturnsOnCall == nil {
+		fake.getGithubDeviceCodeReturnsOnCall = make(map[int]struct {
+			result1 *applications.GetGithubDeviceCodeResponse
+			result2 error
+		})
+	}
+	fake.getGithubDeviceCodeReturnsOnCall[i] = struct {
+		result1 *applications.GetGithubDeviceCodeResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) GetGitlabAuthURL(arg1 context.Context, arg2 *applications.GetGitlabAuthURLRequest) (*applications.GetGitlabAuthURLResponse, error) {
+	fake.getGitlabAuthURLMutex.Lock()
+	ret, specificReturn := fake.getGitlabAuthURLReturnsOnCall[len(fake.getGitlabAuthURLArgsForCall)]
+	fake.getGitlabAuthURLArgsForCall = append(fake.getGitlabAuthURLArgsForCall, struct {
+		arg1 context.Context
+		arg2 *applications.GetGitlabAuthURLRequest
+	}{arg1, arg2})
+	stub := fake.GetGitlabAuthURLStub
+	fakeReturns := fake.getGitlabAuthURLReturns
+	fake.recordInvocation("GetGitlabAuthURL", []interface{}{arg1, arg2})
+	fake.getGitlabAuthURLMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeApplicationsClient) GetGitlabAuthURLCallCount() int {
+	fake.getGitlabAuthURLMutex.RLock()
+	defer fake.getGitlabAuthURLMutex.RUnlock()
+	return len(fake.getGitlabAuthURLArgsForCall)
+}
+
+func (fake *FakeApplicationsClient) GetGitlabAuthURLCalls(stub func(context.Context, *applications.GetGitlabAuthURLRequest) (*applications.GetGitlabAuthURLResponse, error)) {
+	fake.getGitlabAuthURLMutex.Lock()
+	defer fake.getGitlabAuthURLMutex.Unlock()
+	fake.GetGitlabAuthURLStub = stub
+}
+
+func (fake *FakeApplicationsClient) GetGitlabAuthURLArgsForCall(i int) (context.Context, *applications.GetGitlabAuthURLRequest) {
+	fake.getGitlabAuthURLMutex.RLock()
+	defer fake.getGitlabAuthURLMutex.RUnlock()
+	argsForCall := fake.getGitlabAuthURLArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeApplicationsClient) GetGitlabAuthURLReturns(result1 *applications.GetGitlabAuthURLResponse, result2 error) {
+	fake.getGitlabAuthURLMutex.Lock()
+	defer fake.getGitlabAuthURLMutex.Unlock()
+	fake.GetGitlabAuthURLStub = nil
+	fake.getGitlabAuthURLReturns = struct {
+		result1 *applications.GetGitlabAuthURLResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) GetGitlabAuthURLReturnsOnCall(i int, result1 *applications.GetGitlabAuthURLResponse, result2 error) {
+	fake.getGitlabAuthURLMutex.Lock()
+	defer fake.getGitlabAuthURLMutex.Unlock()
+	fake.GetGitlabAuthURLStub = nil
+	if fake.getGitlabAuthURLReturnsOnCall == nil {
+		fake.getGitlabAuthURLReturnsOnCall = make(map[int]struct {
+			result1 *applications.GetGitlabAuthURLResponse
+			result2 error
+		})
+	}
+	fake.getGitlabAuthURLReturnsOnCall[i] = struct {
+		result1 *applications.GetGitlabAuthURLResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) ParseRepoURL(arg1 context.Context, arg2 *applications.ParseRepoURLRequest) (*applications.ParseRepoURLResponse, error) {
+	fake.parseRepoURLMutex.Lock()
+	ret, specificReturn := fake.parseRepoURLReturnsOnCall[len(fake.parseRepoURLArgsForCall)]
+	fake.parseRepoURLArgsForCall = append(fake.parseRepoURLArgsForCall, struct {
+		arg1 context.Context
+		arg2 *applications.ParseRepoURLRequest
+	}{arg1, arg2})
+	stub := fake.ParseRepoURLStub
+	fakeReturns := fake.parseRepoURLReturns
+	fake.recordInvocation("ParseRepoURL", []interface{}{arg1, arg2})
+	fake.parseRepoURLMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeApplicationsClient) ParseRepoURLCallCount() int {
+	fake.parseRepoURLMutex.RLock()
+	defer fake.parseRepoURLMutex.RUnlock()
+	return len(fake.parseRepoURLArgsForCall)
+}
+
+func (fake *FakeApplicationsClient) ParseRepoURLCalls(stub func(context.Context, *applications.ParseRepoURLRequest) (*applications.ParseRepoURLResponse, error)) {
+	fake.parseRepoURLMutex.Lock()
+	defer fake.parseRepoURLMutex.Unlock()
+	fake.ParseRepoURLStub = stub
+}
+
+func (fake *FakeApplicationsClient) ParseRepoURLArgsForCall(i int) (context.Context, *applications.ParseRepoURLRequest) {
+	fake.parseRepoURLMutex.RLock()
+	defer fake.parseRepoURLMutex.RUnlock()
+	argsForCall := fake.parseRepoURLArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeApplicationsClient) ParseRepoURLReturns(result1 *applications.ParseRepoURLResponse, result2 error) {
+	fake.parseRepoURLMutex.Lock()
+	defer fake.parseRepoURLMutex.Unlock()
+	fake.ParseRepoURLStub = nil
+	fake.parseRepoURLReturns = struct {
+		result1 *applications.ParseRepoURLResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) ParseRepoURLReturnsOnCall(i int, result1 *applications.ParseRepoURLResponse, result2 error) {
+	fake.parseRepoURLMutex.Lock()
+	defer fake.parseRepoURLMutex.Unlock()
+	fake.ParseRepoURLStub = nil
+	if fake.parseRepoURLReturnsOnCall == nil {
+		fake.parseRepoURLReturnsOnCall = make(map[int]struct {
+			result1 *applications.ParseRepoURLResponse
+			result2 error
+		})
+	}
+	fake.parseRepoURLReturnsOnCall[i] = struct {
+		result1 *applications.ParseRepoURLResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) ValidateProviderToken(arg1 context.Context, arg2 *applications.ValidateProviderTokenRequest) (*applications.ValidateProviderTokenResponse, error) {
+	fake.validateProviderTokenMutex.Lock()
+	ret, specificReturn := fake.validateProviderTokenReturnsOnCall[len(fake.validateProviderTokenArgsForCall)]
+	fake.validateProviderTokenArgsForCall = append(fake.validateProviderTokenArgsForCall, struct {
+		arg1 context.Context
+		arg2 *applications.ValidateProviderTokenRequest
+	}{arg1, arg2})
+	stub := fake.ValidateProviderTokenStub
+	fakeReturns := fake.validateProviderTokenReturns
+	fake.recordInvocation("ValidateProviderToken", []interface{}{arg1, arg2})
+	fake.validateProviderTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeApplicationsClient) ValidateProviderTokenCallCount() int {
+	fake.validateProviderTokenMutex.RLock()
+	defer fake.validateProviderTokenMutex.RUnlock()
+	return len(fake.validateProviderTokenArgsForCall)
+}
+
+func (fake *FakeApplicationsClient) ValidateProviderTokenCalls(stub func(context.Context, *applications.ValidateProviderTokenRequest) (*applications.ValidateProviderTokenResponse, error)) {
+	fake.validateProviderTokenMutex.Lock()
+	defer fake.validateProviderTokenMutex.Unlock()
+	fake.ValidateProviderTokenStub = stub
+}
+
+func (fake *FakeApplicationsClient) ValidateProviderTokenArgsForCall(i int) (context.Context, *applications.ValidateProviderTokenRequest) {
+	fake.validateProviderTokenMutex.RLock()
+	defer fake.validateProviderTokenMutex.RUnlock()
+	argsForCall := fake.validateProviderTokenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeApplicationsClient) ValidateProviderTokenReturns(result1 *applications.ValidateProviderTokenResponse, result2 error) {
+	fake.validateProviderTokenMutex.Lock()
+	defer fake.validateProviderTokenMutex.Unlock()
+	fake.ValidateProviderTokenStub = nil
+	fake.validateProviderTokenReturns = struct {
+		result1 *applications.ValidateProviderTokenResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) ValidateProviderTokenReturnsOnCall(i int, result1 *applications.ValidateProviderTokenResponse, result2 error) {
+	fake.validateProviderTokenMutex.Lock()
+	defer fake.validateProviderTokenMutex.Unlock()
+	fake.ValidateProviderTokenStub = nil
+	if fake.validateProviderTokenReturnsOnCall == nil {
+		fake.validateProviderTokenReturnsOnCall = make(map[int]struct {
+			result1 *applications.ValidateProviderTokenResponse
+			result2 error
+		})
+	}
+	fake.validateProviderTokenReturnsOnCall[i] = struct {
+		result1 *applications.ValidateProviderTokenResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeApplicationsClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.authenticateMutex.RLock()
+	defer fake.authenticateMutex.RUnlock()
+	fake.authorizeGitlabMutex.RLock()
+	defer fake.authorizeGitlabMutex.RUnlock()
+	fake.getFeatureFlagsMutex.RLock()
+	defer fake.getFeatureFlagsMutex.RUnlock()
+	fake.getGithubAuthStatusMutex.RLock()
+	defer fake.getGithubAuthStatusMutex.RUnlock()
+	fake.getGithubDeviceCodeMutex.RLock()
+	defer fake.getGithubDeviceCodeMutex.RUnlock()
+	fake.getGitlabAuthURLMutex.RLock()
+	defer fake.getGitlabAuthURLMutex.RUnlock()
+	fake.parseRepoURLMutex.RLock()
+	defer fake.parseRepoURLMutex.RUnlock()
+	fake.validateProviderTokenMutex.RLock()
+	defer fake.validateProviderTokenMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeApplicationsClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ apiclient.ApplicationsClient = new(FakeApplicationsClient)
@@ -0,0 +1,1173 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package apiclientfakes
+
+import (
+	"context"
+	"sync"
+
+	api "github.com/weaveworks/weave-gitops/pkg/api/core"
+	"github.com/weaveworks/weave-gitops/pkg/apiclient"
+)
+
+type FakeCoreClient struct {
+	GetChildObjectsStub        func(context.Context, *api.GetChildObjectsRequest) (*api.GetChildObjectsResponse, error)
+	getChildObjectsMutex       sync.RWMutex
+	getChildObjectsArgsForCall []struct {
+		arg1 context.Context
+		arg2 *api.GetChildObjectsRequest
+	}
+	getChildObjectsReturns struct {
+		result1 *api.GetChildObjectsResponse
+		result2 error
+	}
+	getChildObjectsReturnsOnCall map[int]struct {
+		result1 *api.GetChildObjectsResponse
+		result2 error
+	}
+	GetFluxNamespaceStub        func(context.Context, *api.GetFluxNamespaceRequest) (*api.GetFluxNamespaceResponse, error)
+	getFluxNamespaceMutex       sync.RWMutex
+	getFluxNamespaceArgsForCall []struct {
+		arg1 context.Context
+		arg2 *api.GetFluxNamespaceRequest
+	}
+	getFluxNamespaceReturns struct {
+		result1 *api.GetFluxNamespaceResponse
+		result2 error
+	}
+	getFluxNamespaceReturnsOnCall map[int]struct {
+		result1 *api.GetFluxNamespaceResponse
+		result2 error
+	}
+	GetHelmReleaseStub        func(context.Context, *api.GetHelmReleaseRequest) (*api.GetHelmReleaseResponse, error)
+	getHelmReleaseMutex       sync.RWMutex
+	getHelmReleaseArgsForCall []struct {
+		arg1 context.Context
+		arg2 *api.GetHelmReleaseRequest
+	}
+	getHelmReleaseReturns struct {
+		result1 *api.GetHelmReleaseResponse
+		result2 error
+	}
+	getHelmReleaseReturnsOnCall map[int]struct {
+		result1 *api.GetHelmReleaseResponse
+		result2 error
+	}
+	GetKustomizationStub        func(context.Context, *api.GetKustomizationRequest) (*api.GetKustomizationResponse, error)
+	getKustomizationMutex       sync.RWMutex
+	getKustomizationArgsForCall []struct {
+		arg1 context.Context
+		arg2 *api.GetKustomizationRequest
+	}
+	getKustomizationReturns struct {
+		result1 *api.GetKustomizationResponse
+		result2 error
+	}
+	getKustomizationReturnsOnCall map[int]struct {
+		result1 *api.GetKustomizationResponse
+		result2 error
+	}
+	GetReconciledObjectsStub        func(context.Context, *api.GetReconciledObjectsRequest) (*api.GetReconciledObjectsResponse, error)
+	getReconciledObjectsMutex       sync.RWMutex
+	getReconciledObjectsArgsForCall []struct {
+		arg1 context.Context
+		arg2 *api.GetReconciledObjectsRequest
+	}
+	getReconciledObjectsReturns struct {
+		result1 *api.GetReconciledObjectsResponse
+		result2 error
+	}
+	getReconciledObjectsReturnsOnCall map[int]struct {
+		result1 *api.GetReconciledObjectsResponse
+		result2 error
+	}
+	ListBucketsStub        func(context.Context, *api.ListBucketRequest) (*api.ListBucketsResponse, error)
+	listBucketsMutex       sync.RWMutex
+	listBucketsArgsForCall []struct {
+		arg1 context.Context
+		arg2 *api.ListBucketRequest
+	}
+	listBucketsReturns struct {
+		result1 *api.ListBucketsResponse
+		result2 error
+	}
+	listBucketsReturnsOnCall map[int]struct {
+		result1 *api.ListBucketsResponse
+		result2 error
+	}
+	ListFluxEventsStub        func(context.Context, *api.ListFluxEventsRequest) (*api.ListFluxEventsResponse, error)
+	listFluxEventsMutex       sync.RWMutex
+	listFluxEventsArgsForCall []struct {
+		arg1 context.Context
+		arg2 *api.ListFluxEventsRequest
+	}
+	listFluxEventsReturns struct {
+		result1 *api.ListFluxEventsResponse
+		result2 error
+	}
+	listFluxEventsReturnsOnCall map[int]struct {
+		result1 *api.ListFluxEventsResponse
+		result2 error
+	}
+	ListFluxRuntimeObjectsStub        func(context.Context, *api.ListFluxRuntimeObjectsRequest) (*api.ListFluxRuntimeObjectsResponse, error)
+	listFluxRuntimeObjectsMutex       sync.RWMutex
+	listFluxRuntimeObjectsArgsForCall []struct {
+		arg1 context.Context
+		arg2 *api.ListFluxRuntimeObjectsRequest
+	}
+	listFluxRuntimeObjectsReturns struct {
+		result1 *api.ListFluxRuntimeObjectsResponse
+		result2 error
+	}
+	listFluxRuntimeObjectsReturnsOnCall map[int]struct {
+		result1 *api.ListFluxRuntimeObjectsResponse
+		result2 error
+	}
+	ListGitRepositoriesStub        func(context.Context, *api.ListGitRepositoriesRequest) (*api.ListGitRepositoriesResponse, error)
+	listGitRepositoriesMutex       sync.RWMutex
+	listGitRepositoriesArgsForCall []struct {
+		arg1 context.Context
+		arg2 *api.ListGitRepositoriesRequest
+	}
+	listGitRepositoriesReturns struct {
+		result1 *api.ListGitRepositoriesResponse
+		result2 error
+	}
+	listGitRepositoriesReturnsOnCall map[int]struct {
+		result1 *api.ListGitRepositoriesResponse
+		result2 error
+	}
+	ListHelmChartsStub        func(context.Context, *api.ListHelmChartsRequest) (*api.ListHelmChartsResponse, error)
+	listHelmChartsMutex       sync.RWMutex
+	listHelmChartsArgsForCall []struct {
+		arg1 context.Context
+		arg2 *api.ListHelmChartsRequest
+	}
+	listHelmChartsReturns struct {
+		result1 *api.ListHelmChartsResponse
+		result2 error
+	}
+	listHelmChartsReturnsOnCall map[int]struct {
+		result1 *api.ListHelmChartsResponse
+		result2 error
+	}
+	ListHelmReleasesStub        func(context.Context, *api.ListHelmReleasesRequest) (*api.ListHelmReleasesResponse, error)
+	listHelmReleasesMutex       sync.RWMutex
+	listHelmReleasesArgsForCall []struct {
+		arg1 context.Context
+		arg2 *api.ListHelmReleasesRequest
+	}
+	listHelmReleasesReturns struct {
+		result1 *api.ListHelmReleasesResponse
+		result2 error
+	}
+	listHelmReleasesReturnsOnCall map[int]struct {
+		result1 *api.ListHelmReleasesResponse
+		result2 error
+	}
+	ListHelmRepositoriesStub        func(context.Context, *api.ListHelmRepositoriesRequest) (*api.ListHelmRepositoriesResponse, error)
+	listHelmRepositoriesMutex       sync.RWMutex
+	listHelmRepositoriesArgsForCall []struct {
+		arg1 context.Context
+		arg2 *api.ListHelmRepositoriesRequest
+	}
+	listHelmRepositoriesReturns struct {
+		result1 *api.ListHelmRepositoriesResponse
+		result2 error
+	}
+	listHelmRepositoriesReturnsOnCall map[int]struct {
+		result1 *api.ListHelmRepositoriesResponse
+		result2 error
+	}
+	ListKustomizationsStub        func(context.Context, *api.ListKustomizationsRequest) (*api.ListKustomizationsResponse, error)
+	listKustomizationsMutex       sync.RWMutex
+	listKustomizationsArgsForCall []struct {
+		arg1 context.Context
+		arg2 *api.ListKustomizationsRequest
+	}
+	listKustomizationsReturns struct {
+		result1 *api.ListKustomizationsResponse
+		result2 error
+	}
+	listKustomizationsReturnsOnCall map[int]struct {
+		result1 *api.ListKustomizationsResponse
+		result2 error
+	}
+	ListNamespacesStub        func(context.Context, *api.ListNamespacesRequest) (*api.ListNamespacesResponse, error)
+	listNamespacesMutex       sync.RWMutex
+	listNamespacesArgsForCall []struct {
+		arg1 context.Context
+		arg2 *api.ListNamespacesRequest
+	}
+	listNamespacesReturns struct {
+		result1 *api.ListNamespacesResponse
+		result2 error
+	}
+	listNamespacesReturnsOnCall map[int]struct {
+		result1 *api.ListNamespacesResponse
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCoreClient) GetChildObjects(arg1 context.Context, arg2 *api.GetChildObjectsRequest) (*api.GetChildObjectsResponse, error) {
+	fake.getChildObjectsMutex.Lock()
+	ret, specificReturn := fake.getChildObjectsReturnsOnCall[len(fake.getChildObjectsArgsForCall)]
+	fake.getChildObjectsArgsForCall = append(fake.getChildObjectsArgsForCall, struct {
+		arg1 context.Context
+		arg2 *api.GetChildObjectsRequest
+	}{arg1, arg2})
+	stub := fake.GetChildObjectsStub
+	fakeReturns := fake.getChildObjectsReturns
+	fake.recordInvocation("GetChildObjects", []interface{}{arg1, arg2})
+	fake.getChildObjectsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCoreClient) GetChildObjectsCallCount() int {
+	fake.getChildObjectsMutex.RLock()
+	defer fake.getChildObjectsMutex.RUnlock()
+	return len(fake.getChildObjectsArgsForCall)
+}
+
+func (fake *FakeCoreClient) GetChildObjectsCalls(stub func(context.Context, *api.GetChildObjectsRequest) (*api.GetChildObjectsResponse, error)) {
+	fake.getChildObjectsMutex.Lock()
+	defer fake.getChildObjectsMutex.Unlock()
+	fake.GetChildObjectsStub = stub
+}
+
+func (fake *FakeCoreClient) GetChildObjectsArgsForCall(i int) (context.Context, *api.GetChildObjectsRequest) {
+	fake.getChildObjectsMutex.RLock()
+	defer fake.getChildObjectsMutex.RUnlock()
+	argsForCall := fake.getChildObjectsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCoreClient) GetChildObjectsReturns(result1 *api.GetChildObjectsResponse, result2 error) {
+	fake.getChildObjectsMutex.Lock()
+	defer fake.getChildObjectsMutex.Unlock()
+	fake.GetChildObjectsStub = nil
+	fake.getChildObjectsReturns = struct {
+		result1 *api.GetChildObjectsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) GetChildObjectsReturnsOnCall(i int, result1 *api.GetChildObjectsResponse, result2 error) {
+	fake.getChildObjectsMutex.Lock()
+	defer fake.getChildObjectsMutex.Unlock()
+	fake.GetChildObjectsStub = nil
+	if fake.getChildObjectsReturnsOnCall == nil {
+		fake.getChildObjectsReturnsOnCall = make(map[int]struct {
+			result1 *api.GetChildObjectsResponse
+			result2 error
+		})
+	}
+	fake.getChildObjectsReturnsOnCall[i] = struct {
+		result1 *api.GetChildObjectsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) GetFluxNamespace(arg1 context.Context, arg2 *api.GetFluxNamespaceRequest) (*api.GetFluxNamespaceResponse, error) {
+	fake.getFluxNamespaceMutex.Lock()
+	ret, specificReturn := fake.getFluxNamespaceReturnsOnCall[len(fake.getFluxNamespaceArgsForCall)]
+	fake.getFluxNamespaceArgsForCall = append(fake.getFluxNamespaceArgsForCall, struct {
+		arg1 context.Context
+		arg2 *api.GetFluxNamespaceRequest
+	}{arg1, arg2})
+	stub := fake.GetFluxNamespaceStub
+	fakeReturns := fake.getFluxNamespaceReturns
+	fake.recordInvocation("GetFluxNamespace", []interface{}{arg1, arg2})
+	fake.getFluxNamespaceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCoreClient) GetFluxNamespaceCallCount() int {
+	fake.getFluxNamespaceMutex.RLock()
+	defer fake.getFluxNamespaceMutex.RUnlock()
+	return len(fake.getFluxNamespaceArgsForCall)
+}
+
+func (fake *FakeCoreClient) GetFluxNamespaceCalls(stub func(context.Context, *api.GetFluxNamespaceRequest) (*api.GetFluxNamespaceResponse, error)) {
+	fake.getFluxNamespaceMutex.Lock()
+	defer fake.getFluxNamespaceMutex.Unlock()
+	fake.GetFluxNamespaceStub = stub
+}
+
+func (fake *FakeCoreClient) GetFluxNamespaceArgsForCall(i int) (context.Context, *api.GetFluxNamespaceRequest) {
+	fake.getFluxNamespaceMutex.RLock()
+	defer fake.getFluxNamespaceMutex.RUnlock()
+	argsForCall := fake.getFluxNamespaceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCoreClient) GetFluxNamespaceReturns(result1 *api.GetFluxNamespaceResponse, result2 error) {
+	fake.getFluxNamespaceMutex.Lock()
+	defer fake.getFluxNamespaceMutex.Unlock()
+	fake.GetFluxNamespaceStub = nil
+	fake.getFluxNamespaceReturns = struct {
+		result1 *api.GetFluxNamespaceResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) GetFluxNamespaceReturnsOnCall(i int, result1 *api.GetFluxNamespaceResponse, result2 error) {
+	fake.getFluxNamespaceMutex.Lock()
+	defer fake.getFluxNamespaceMutex.Unlock()
+	fake.GetFluxNamespaceStub = nil
+	if fake.getFluxNamespaceReturnsOnCall == nil {
+		fake.getFluxNamespaceReturnsOnCall = make(map[int]struct {
+			result1 *api.GetFluxNamespaceResponse
+			result2 error
+		})
+	}
+	fake.getFluxNamespaceReturnsOnCall[i] = struct {
+		result1 *api.GetFluxNamespaceResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) GetHelmRelease(arg1 context.Context, arg2 *api.GetHelmReleaseRequest) (*api.GetHelmReleaseResponse, error) {
+	fake.getHelmReleaseMutex.Lock()
+	ret, specificReturn := fake.getHelmReleaseReturnsOnCall[len(fake.getHelmReleaseArgsForCall)]
+	fake.getHelmReleaseArgsForCall = append(fake.getHelmReleaseArgsForCall, struct {
+		arg1 context.Context
+		arg2 *api.GetHelmReleaseRequest
+	}{arg1, arg2})
+	stub := fake.GetHelmReleaseStub
+	fakeReturns := fake.getHelmReleaseReturns
+	fake.recordInvocation("GetHelmRelease", []interface{}{arg1, arg2})
+	fake.getHelmReleaseMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCoreClient) GetHelmReleaseCallCount() int {
+	fake.getHelmReleaseMutex.RLock()
+	defer fake.getHelmReleaseMutex.RUnlock()
+	return len(fake.getHelmReleaseArgsForCall)
+}
+
+func (fake *FakeCoreClient) GetHelmReleaseCalls(stub func(context.Context, *api.GetHelmReleaseRequest) (*api.GetHelmReleaseResponse, error)) {
+	fake.getHelmReleaseMutex.Lock()
+	defer fake.getHelmReleaseMutex.Unlock()
+	fake.GetHelmReleaseStub = stub
+}
+
+func (fake *FakeCoreClient) GetHelmReleaseArgsForCall(i int) (context.Context, *api.GetHelmReleaseRequest) {
+	fake.getHelmReleaseMutex.RLock()
+	defer fake.getHelmReleaseMutex.RUnlock()
+	argsForCall := fake.getHelmReleaseArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCoreClient) GetHelmReleaseReturns(result1 *api.GetHelmReleaseResponse, result2 error) {
+	fake.getHelmReleaseMutex.Lock()
+	defer fake.getHelmReleaseMutex.Unlock()
+	fake.GetHelmReleaseStub = nil
+	fake.getHelmReleaseReturns = struct {
+		result1 *api.GetHelmReleaseResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) GetHelmReleaseReturnsOnCall(i int, result1 *api.GetHelmReleaseResponse, result2 error) {
+	fake.getHelmReleaseMutex.Lock()
+	defer fake.getHelmReleaseMutex.Unlock()
+	fake.GetHelmReleaseStub = nil
+	if fake.getHelmReleaseReturnsOnCall == nil {
+		fake.getHelmReleaseReturnsOnCall = make(map[int]struct {
+			result1 *api.GetHelmReleaseResponse
+			result2 error
+		})
+	}
+	fake.getHelmReleaseReturnsOnCall[i] = struct {
+		result1 *api.GetHelmReleaseResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) GetKustomization(arg1 context.Context, arg2 *api.GetKustomizationRequest) (*api.GetKustomizationResponse, error) {
+	fake.getKustomizationMutex.Lock()
+	ret, specificReturn := fake.getKustomizationReturnsOnCall[len(fake.getKustomizationArgsForCall)]
+	fake.getKustomizationArgsForCall = append(fake.getKustomizationArgsForCall, struct {
+		arg1 context.Context
+		arg2 *api.GetKustomizationRequest
+	}{arg1, arg2})
+	stub := fake.GetKustomizationStub
+	fakeReturns := fake.getKustomizationReturns
+	fake.recordInvocation("GetKustomization", []interface{}{arg1, arg2})
+	fake.getKustomizationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCoreClient) GetKustomizationCallCount() int {
+	fake.getKustomizationMutex.RLock()
+	defer fake.getKustomizationMutex.RUnlock()
+	return len(fake.getKustomizationArgsForCall)
+}
+
+func (fake *FakeCoreClient) GetKustomizationCalls(stub func(context.Context, *api.GetKustomizationRequest) (*api.GetKustomizationResponse, error)) {
+	fake.getKustomizationMutex.Lock()
+	defer fake.getKustomizationMutex.Unlock()
+	fake.GetKustomizationStub = stub
+}
+
+func (fake *FakeCoreClient) GetKustomizationArgsForCall(i int) (context.Context, *api.GetKustomizationRequest) {
+	fake.getKustomizationMutex.RLock()
+	defer fake.getKustomizationMutex.RUnlock()
+	argsForCall := fake.getKustomizationArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCoreClient) GetKustomizationReturns(result1 *api.GetKustomizationResponse, result2 error) {
+	fake.getKustomizationMutex.Lock()
+	defer fake.getKustomizationMutex.Unlock()
+	fake.GetKustomizationStub = nil
+	fake.getKustomizationReturns = struct {
+		result1 *api.GetKustomizationResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) GetKustomizationReturnsOnCall(i int, result1 *api.GetKustomizationResponse, result2 error) {
+	fake.getKustomizationMutex.Lock()
+	defer fake.getKustomizationMutex.Unlock()
+	fake.GetKustomizationStub = nil
+	if fake.getKustomizationReturnsOnCall == nil {
+		fake.getKustomizationReturnsOnCall = make(map[int]struct {
+			result1 *api.GetKustomizationResponse
+			result2 error
+		})
+	}
+	fake.getKustomizationReturnsOnCall[i] = struct {
+		result1 *api.GetKustomizationResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) GetReconciledObjects(arg1 context.Context, arg2 *api.GetReconciledObjectsRequest) (*api.GetReconciledObjectsResponse, error) {
+	fake.getReconciledObjectsMutex.Lock()
+	ret, specificReturn := fake.getReconciledObjectsReturnsOnCall[len(fake.getReconciledObjectsArgsForCall)]
+	fake.getReconciledObjectsArgsForCall = append(fake.getReconciledObjectsArgsForCall, struct {
+		arg1 context.Context
+		arg2 *api.GetReconciledObjectsRequest
+	}{arg1, arg2})
+	stub := fake.GetReconciledObjectsStub
+	fakeReturns := fake.getReconciledObjectsReturns
+	fake.recordInvocation("GetReconciledObjects", []interface{}{arg1, arg2})
+	fake.getReconciledObjectsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCoreClient) GetReconciledObjectsCallCount() int {
+	fake.getReconciledObjectsMutex.RLock()
+	defer fake.getReconciledObjectsMutex.RUnlock()
+	return len(fake.getReconciledObjectsArgsForCall)
+}
+
+func (fake *FakeCoreClient) GetReconciledObjectsCalls(stub func(context.Context, *api.GetReconciledObjectsRequest) (*api.GetReconciledObjectsResponse, error)) {
+	fake.getReconciledObjectsMutex.Lock()
+	defer fake.getReconciledObjectsMutex.Unlock()
+	fake.GetReconciledObjectsStub = stub
+}
+
+func (fake *FakeCoreClient) GetReconciledObjectsArgsForCall(i int) (context.Context, *api.GetReconciledObjectsRequest) {
+	fake.getReconciledObjectsMutex.RLock()
+	defer fake.getReconciledObjectsMutex.RUnlock()
+	argsForCall := fake.getReconciledObjectsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCoreClient) GetReconciledObjectsReturns(result1 *api.GetReconciledObjectsResponse, result2 error) {
+	fake.getReconciledObjectsMutex.Lock()
+	defer fake.getReconciledObjectsMutex.Unlock()
+	fake.GetReconciledObjectsStub = nil
+	fake.getReconciledObjectsReturns = struct {
+		result1 *api.GetReconciledObjectsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) GetReconciledObjectsReturnsOnCall(i int, result1 *api.GetReconciledObjectsResponse, result2 error) {
+	fake.getReconciledObjectsMutex.Lock()
+	defer fake.getReconciledObjectsMutex.Unlock()
+	fake.GetReconciledObjectsStub = nil
+	if fake.getReconciledObjectsReturnsOnCall == nil {
+		fake.getReconciledObjectsReturnsOnCall = make(map[int]struct {
+			result1 *api.GetReconciledObjectsResponse
+			result2 error
+		})
+	}
+	fake.getReconciledObjectsReturnsOnCall[i] = struct {
+		result1 *api.GetReconciledObjectsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListBuckets(arg1 context.Context, arg2 *api.ListBucketRequest) (*api.ListBucketsResponse, error) {
+	fake.listBucketsMutex.Lock()
+	ret, specificReturn := fake.listBucketsReturnsOnCall[len(fake.listBucketsArgsForCall)]
+	fake.listBucketsArgsForCall = append(fake.listBucketsArgsForCall, struct {
+		arg1 context.Context
+		arg2 *api.ListBucketRequest
+	}{arg1, arg2})
+	stub := fake.ListBucketsStub
+	fakeReturns := fake.listBucketsReturns
+	fake.recordInvocation("ListBuckets", []interface{}{arg1, arg2})
+	fake.listBucketsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCoreClient) ListBucketsCallCount() int {
+	fake.listBucketsMutex.RLock()
+	defer fake.listBucketsMutex.RUnlock()
+	return len(fake.listBucketsArgsForCall)
+}
+
+func (fake *FakeCoreClient) ListBucketsCalls(stub func(context.Context, *api.ListBucketRequest) (*api.ListBucketsResponse, error)) {
+	fake.listBucketsMutex.Lock()
+	defer fake.listBucketsMutex.Unlock()
+	fake.ListBucketsStub = stub
+}
+
+func (fake *FakeCoreClient) ListBucketsArgsForCall(i int) (context.Context, *api.ListBucketRequest) {
+	fake.listBucketsMutex.RLock()
+	defer fake.listBucketsMutex.RUnlock()
+	argsForCall := fake.listBucketsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCoreClient) ListBucketsReturns(result1 *api.ListBucketsResponse, result2 error) {
+	fake.listBucketsMutex.Lock()
+	defer fake.listBucketsMutex.Unlock()
+	fake.ListBucketsStub = nil
+	fake.listBucketsReturns = struct {
+		result1 *api.ListBucketsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListBucketsReturnsOnCall(i int, result1 *api.ListBucketsResponse, result2 error) {
+	fake.listBucketsMutex.Lock()
+	defer fake.listBucketsMutex.Unlock()
+	fake.ListBucketsStub = nil
+	if fake.listBucketsReturnsOnCall == nil {
+		fake.listBucketsReturnsOnCall = make(map[int]struct {
+			result1 *api.ListBucketsResponse
+			result2 error
+		})
+	}
+	fake.listBucketsReturnsOnCall[i] = struct {
+		result1 *api.ListBucketsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListFluxEvents(arg1 context.Context, arg2 *api.ListFluxEventsRequest) (*api.ListFluxEventsResponse, error) {
+	fake.listFluxEventsMutex.Lock()
+	ret, specificReturn := fake.listFluxEventsReturnsOnCall[len(fake.listFluxEventsArgsForCall)]
+	fake.listFluxEventsArgsForCall = append(fake.listFluxEventsArgsForCall, struct {
+		arg1 context.Context
+		arg2 *api.ListFluxEventsRequest
+	}{arg1, arg2})
+	stub := fake.ListFluxEventsStub
+	fakeReturns := fake.listFluxEventsReturns
+	fake.recordInvocation("ListFluxEvents", []interface{}{arg1, arg2})
+	fake.listFluxEventsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCoreClient) ListFluxEventsCallCount() int {
+	fake.listFluxEventsMutex.RLock()
+	defer fake.listFluxEventsMutex.RUnlock()
+	return len(fake.listFluxEventsArgsForCall)
+}
+
+func (fake *FakeCoreClient) ListFluxEventsCalls(stub func(context.Context, *api.ListFluxEventsRequest) (*api.ListFluxEventsResponse, error)) {
+	fake.listFluxEventsMutex.Lock()
+	defer fake.listFluxEventsMutex.Unlock()
+	fake.ListFluxEventsStub = stub
+}
+
+func (fake *FakeCoreClient) ListFluxEventsArgsForCall(i int) (context.Context, *api.ListFluxEventsRequest) {
+	fake.listFluxEventsMutex.RLock()
+	defer fake.listFluxEventsMutex.RUnlock()
+	argsForCall := fake.listFluxEventsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCoreClient) ListFluxEventsReturns(result1 *api.ListFluxEventsResponse, result2 error) {
+	fake.listFluxEventsMutex.Lock()
+	defer fake.listFluxEventsMutex.Unlock()
+	fake.ListFluxEventsStub = nil
+	fake.listFluxEventsReturns = struct {
+		result1 *api.ListFluxEventsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListFluxEventsReturnsOnCall(i int, result1 *api.ListFluxEventsResponse, result2 error) {
+	fake.listFluxEventsMutex.Lock()
+	defer fake.listFluxEventsMutex.Unlock()
+	fake.ListFluxEventsStub = nil
+	if fake.listFluxEventsReturnsOnCall == nil {
+		fake.listFluxEventsReturnsOnCall = make(map[int]struct {
+			result1 *api.ListFluxEventsResponse
+			result2 error
+		})
+	}
+	fake.listFluxEventsReturnsOnCall[i] = struct {
+		result1 *api.ListFluxEventsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListFluxRuntimeObjects(arg1 context.Context, arg2 *api.ListFluxRuntimeObjectsRequest) (*api.ListFluxRuntimeObjectsResponse, error) {
+	fake.listFluxRuntimeObjectsMutex.Lock()
+	ret, specificReturn := fake.listFluxRuntimeObjectsReturnsOnCall[len(fake.listFluxRuntimeObjectsArgsForCall)]
+	fake.listFluxRuntimeObjectsArgsForCall = append(fake.listFluxRuntimeObjectsArgsForCall, struct {
+		arg1 context.Context
+		arg2 *api.ListFluxRuntimeObjectsRequest
+	}{arg1, arg2})
+	stub := fake.ListFluxRuntimeObjectsStub
+	fakeReturns := fake.listFluxRuntimeObjectsReturns
+	fake.recordInvocation("ListFluxRuntimeObjects", []interface{}{arg1, arg2})
+	fake.listFluxRuntimeObjectsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCoreClient) ListFluxRuntimeObjectsCallCount() int {
+	fake.listFluxRuntimeObjectsMutex.RLock()
+	defer fake.listFluxRuntimeObjectsMutex.RUnlock()
+	return len(fake.listFluxRuntimeObjectsArgsForCall)
+}
+
+func (fake *FakeCoreClient) ListFluxRuntimeObjectsCalls(stub func(context.Context, *api.ListFluxRuntimeObjectsRequest) (*api.ListFluxRuntimeObjectsResponse, error)) {
+	fake.listFluxRuntimeObjectsMutex.Lock()
+	defer fake.listFluxRuntimeObjectsMutex.Unlock()
+	fake.ListFluxRuntimeObjectsStub = stub
+}
+
+func (fake *FakeCoreClient) ListFluxRuntimeObjectsArgsForCall(i int) (context.Context, *api.ListFluxRuntimeObjectsRequest) {
+	fake.listFluxRuntimeObjectsMutex.RLock()
+	defer fake.listFluxRuntimeObjectsMutex.RUnlock()
+	argsForCall := fake.listFluxRuntimeObjectsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCoreClient) ListFluxRuntimeObjectsReturns(result1 *api.ListFluxRuntimeObjectsResponse, result2 error) {
+	fake.listFluxRuntimeObjectsMutex.Lock()
+	defer fake.listFluxRuntimeObjectsMutex.Unlock()
+	fake.ListFluxRuntimeObjectsStub = nil
+	fake.listFluxRuntimeObjectsReturns = struct {
+		result1 *api.ListFluxRuntimeObjectsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListFluxRuntimeObjectsReturnsOnCall(i int, result1 *api.ListFluxRuntimeObjectsResponse, result2 error) {
+	fake.listFluxRuntimeObjectsMutex.Lock()
+	defer fake.listFluxRuntimeObjectsMutex.Unlock()
+	fake.ListFluxRuntimeObjectsStub = nil
+	if fake.listFluxRuntimeObjectsReturnsOnCall == nil {
+		fake.listFluxRuntimeObjectsReturnsOnCall = make(map[int]struct {
+			result1 *api.ListFluxRuntimeObjectsResponse
+			result2 error
+		})
+	}
+	fake.listFluxRuntimeObjectsReturnsOnCall[i] = struct {
+		result1 *api.ListFluxRuntimeObjectsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListGitRepositories(arg1 context.Context, arg2 *api.ListGitRepositoriesRequest) (*api.ListGitRepositoriesResponse, error) {
+	fake.listGitRepositoriesMutex.Lock()
+	ret, specificReturn := fake.listGitRepositoriesReturnsOnCall[len(fake.listGitRepositoriesArgsForCall)]
+	fake.listGitRepositoriesArgsForCall = append(fake.listGitRepositoriesArgsForCall, struct {
+		arg1 context.Context
+		arg2 *api.ListGitRepositoriesRequest
+	}{arg1, arg2})
+	stub := fake.ListGitRepositoriesStub
+	fakeReturns := fake.listGitRepositoriesReturns
+	fake.recordInvocation("ListGitRepositories", []interface{}{arg1, arg2})
+	fake.listGitRepositoriesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCoreClient) ListGitRepositoriesCallCount() int {
+	fake.listGitRepositoriesMutex.RLock()
+	defer fake.listGitRepositoriesMutex.RUnlock()
+	return len(fake.listGitRepositoriesArgsForCall)
+}
+
+func (fake *FakeCoreClient) ListGitRepositoriesCalls(stub func(context.Context, *api.ListGitRepositoriesRequest) (*api.ListGitRepositoriesResponse, error)) {
+	fake.listGitRepositoriesMutex.Lock()
+	defer fake.listGitRepositoriesMutex.Unlock()
+	fake.ListGitRepositoriesStub = stub
+}
+
+func (fake *FakeCoreClient) ListGitRepositoriesArgsForCall(i int) (context.Context, *api.ListGitRepositoriesRequest) {
+	fake.listGitRepositoriesMutex.RLock()
+	defer fake.listGitRepositoriesMutex.RUnlock()
+	argsForCall := fake.listGitRepositoriesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCoreClient) ListGitRepositoriesReturns(result1 *api.ListGitRepositoriesResponse, result2 error) {
+	fake.listGitRepositoriesMutex.Lock()
+	defer fake.listGitRepositoriesMutex.Unlock()
+	fake.ListGitRepositoriesStub = nil
+	fake.listGitRepositoriesReturns = struct {
+		result1 *api.ListGitRepositoriesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListGitRepositoriesReturnsOnCall(i int, result1 *api.ListGitRepositoriesResponse, result2 error) {
+	fake.listGitRepositoriesMutex.Lock()
+	defer fake.listGitRepositoriesMutex.Unlock()
+	fake.ListGitRepositoriesStub = nil
+	if fake.listGitRepositoriesReturnsOnCall == nil {
+		fake.listGitRepositoriesReturnsOnCall = make(map[int]struct {
+			result1 *api.ListGitRepositoriesResponse
+			result2 error
+		})
+	}
+	fake.listGitRepositoriesReturnsOnCall[i] = struct {
+		result1 *api.ListGitRepositoriesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListHelmCharts(arg1 context.Context, arg2 *api.ListHelmChartsRequest) (*api.ListHelmChartsResponse, error) {
+	fake.listHelmChartsMutex.Lock()
+	ret, specificReturn := fake.listHelmChartsReturnsOnCall[len(fake.listHelmChartsArgsForCall)]
+	fake.listHelmChartsArgsForCall = append(fake.listHelmChartsArgsForCall, struct {
+		arg1 context.Context
+		arg2 *api.ListHelmChartsRequest
+	}{arg1, arg2})
+	stub := fake.ListHelmChartsStub
+	fakeReturns := fake.listHelmChartsReturns
+	fake.recordInvocation("ListHelmCharts", []interface{}{arg1, arg2})
+	fake.listHelmChartsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCoreClient) ListHelmChartsCallCount() int {
+	fake.listHelmChartsMutex.RLock()
+	defer fake.listHelmChartsMutex.RUnlock()
+	return len(fake.listHelmChartsArgsForCall)
+}
+
+func (fake *FakeCoreClient) ListHelmChartsCalls(stub func(context.Context, *api.ListHelmChartsRequest) (*api.ListHelmChartsResponse, error)) {
+	fake.listHelmChartsMutex.Lock()
+	defer fake.listHelmChartsMutex.Unlock()
+	fake.ListHelmChartsStub = stub
+}
+
+func (fake *FakeCoreClient) ListHelmChartsArgsForCall(i int) (context.Context, *api.ListHelmChartsRequest) {
+	fake.listHelmChartsMutex.RLock()
+	defer fake.listHelmChartsMutex.RUnlock()
+	argsForCall := fake.listHelmChartsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCoreClient) ListHelmChartsReturns(result1 *api.ListHelmChartsResponse, result2 error) {
+	fake.listHelmChartsMutex.Lock()
+	defer fake.listHelmChartsMutex.Unlock()
+	fake.ListHelmChartsStub = nil
+	fake.listHelmChartsReturns = struct {
+		result1 *api.ListHelmChartsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListHelmChartsReturnsOnCall(i int, result1 *api.ListHelmChartsResponse, result2 error) {
+	fake.listHelmChartsMutex.Lock()
+	defer fake.listHelmChartsMutex.Unlock()
+	fake.ListHelmChartsStub = nil
+	if fake.listHelmChartsReturnsOnCall == nil {
+		fake.listHelmChartsReturnsOnCall = make(map[int]struct {
+			result1 *api.ListHelmChartsResponse
+			result2 error
+		})
+	}
+	fake.listHelmChartsReturnsOnCall[i] = struct {
+		result1 *api.ListHelmChartsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListHelmReleases(arg1 context.Context, arg2 *api.ListHelmReleasesRequest) (*api.ListHelmReleasesResponse, error) {
+	fake.listHelmReleasesMutex.Lock()
+	ret, specificReturn := fake.listHelmReleasesReturnsOnCall[len(fake.listHelmReleasesArgsForCall)]
+	fake.listHelmReleasesArgsForCall = append(fake.listHelmReleasesArgsForCall, struct {
+		arg1 context.Context
+		arg2 *api.ListHelmReleasesRequest
+	}{arg1, arg2})
+	stub := fake.ListHelmReleasesStub
+	fakeReturns := fake.listHelmReleasesReturns
+	fake.recordInvocation("ListHelmReleases", []interface{}{arg1, arg2})
+	fake.listHelmReleasesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCoreClient) ListHelmReleasesCallCount() int {
+	fake.listHelmReleasesMutex.RLock()
+	defer fake.listHelmReleasesMutex.RUnlock()
+	return len(fake.listHelmReleasesArgsForCall)
+}
+
+func (fake *FakeCoreClient) ListHelmReleasesCalls(stub func(context.Context, *api.ListHelmReleasesRequest) (*api.ListHelmReleasesResponse, error)) {
+	fake.listHelmReleasesMutex.Lock()
+	defer fake.listHelmReleasesMutex.Unlock()
+	fake.ListHelmReleasesStub = stub
+}
+
+func (fake *FakeCoreClient) ListHelmReleasesArgsForCall(i int) (context.Context, *api.ListHelmReleasesRequest) {
+	fake.listHelmReleasesMutex.RLock()
+	defer fake.listHelmReleasesMutex.RUnlock()
+	argsForCall := fake.listHelmReleasesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCoreClient) ListHelmReleasesReturns(result1 *api.ListHelmReleasesResponse, result2 error) {
+	fake.listHelmReleasesMutex.Lock()
+	defer fake.listHelmReleasesMutex.Unlock()
+	fake.ListHelmReleasesStub = nil
+	fake.listHelmReleasesReturns = struct {
+		result1 *api.ListHelmReleasesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListHelmReleasesReturnsOnCall(i int, result1 *api.ListHelmReleasesResponse, result2 error) {
+	fake.listHelmReleasesMutex.Lock()
+	defer fake.listHelmReleasesMutex.Unlock()
+	fake.ListHelmReleasesStub = nil
+	if fake.listHelmReleasesReturnsOnCall == nil {
+		fake.listHelmReleasesReturnsOnCall = make(map[int]struct {
+			result1 *api.ListHelmReleasesResponse
+			result2 error
+		})
+	}
+	fake.listHelmReleasesReturnsOnCall[i] = struct {
+		result1 *api.ListHelmReleasesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListHelmRepositories(arg1 context.Context, arg2 *api.ListHelmRepositoriesRequest) (*api.ListHelmRepositoriesResponse, error) {
+	fake.listHelmRepositoriesMutex.Lock()
+	ret, specificReturn := fake.listHelmRepositoriesReturnsOnCall[len(fake.listHelmRepositoriesArgsForCall)]
+	fake.listHelmRepositoriesArgsForCall = append(fake.listHelmRepositoriesArgsForCall, struct {
+		arg1 context.Context
+		arg2 *api.ListHelmRepositoriesRequest
+	}{arg1, arg2})
+	stub := fake.ListHelmRepositoriesStub
+	fakeReturns := fake.listHelmRepositoriesReturns
+	fake.recordInvocation("ListHelmRepositories", []interface{}{arg1, arg2})
+	fake.listHelmRepositoriesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCoreClient) ListHelmRepositoriesCallCount() int {
+	fake.listHelmRepositoriesMutex.RLock()
+	defer fake.listHelmRepositoriesMutex.RUnlock()
+	return len(fake.listHelmRepositoriesArgsForCall)
+}
+
+func (fake *FakeCoreClient) ListHelmRepositoriesCalls(stub func(context.Context, *api.ListHelmRepositoriesRequest) (*api.ListHelmRepositoriesResponse, error)) {
+	fake.listHelmRepositoriesMutex.Lock()
+	defer fake.listHelmRepositoriesMutex.Unlock()
+	fake.ListHelmRepositoriesStub = stub
+}
+
+func (fake *FakeCoreClient) ListHelmRepositoriesArgsForCall(i int) (context.Context, *api.ListHelmRepositoriesRequest) {
+	fake.listHelmRepositoriesMutex.RLock()
+	defer fake.listHelmRepositoriesMutex.RUnlock()
+	argsForCall := fake.listHelmRepositoriesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCoreClient) ListHelmRepositoriesReturns(result1 *api.ListHelmRepositoriesResponse, result2 error) {
+	fake.listHelmRepositoriesMutex.Lock()
+	defer fake.listHelmRepositoriesMutex.Unlock()
+	fake.ListHelmRepositoriesStub = nil
+	fake.listHelmRepositoriesReturns = struct {
+		result1 *api.ListHelmRepositoriesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListHelmRepositoriesReturnsOnCall(i int, result1 *api.ListHelmRepositoriesResponse, result2 error) {
+	fake.listHelmRepositoriesMutex.Lock()
+	defer fake.listHelmRepositoriesMutex.Unlock()
+	fake.ListHelmRepositoriesStub = nil
+	if fake.listHelmRepositoriesReturnsOnCall == nil {
+		fake.listHelmRepositoriesReturnsOnCall = make(map[int]struct {
+			result1 *api.ListHelmRepositoriesResponse
+			result2 error
+		})
+	}
+	fake.listHelmRepositoriesReturnsOnCall[i] = struct {
+		result1 *api.ListHelmRepositoriesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListKustomizations(arg1 context.Context, arg2 *api.ListKustomizationsRequest) (*api.ListKustomizationsResponse, error) {
+	fake.listKustomizationsMutex.Lock()
+	ret, specificReturn := fake.listKustomizationsReturnsOnCall[len(fake.listKustomizationsArgsForCall)]
+	fake.listKustomizationsArgsForCall = append(fake.listKustomizationsArgsForCall, struct {
+		arg1 context.Context
+		arg2 *api.ListKustomizationsRequest
+	}{arg1, arg2})
+	stub := fake.ListKustomizationsStub
+	fakeReturns := fake.listKustomizationsReturns
+	fake.recordInvocation("ListKustomizations", []interface{}{arg1, arg2})
+	fake.listKustomizationsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCoreClient) ListKustomizationsCallCount() int {
+	fake.listKustomizationsMutex.RLock()
+	defer fake.listKustomizationsMutex.RUnlock()
+	return len(fake.listKustomizationsArgsForCall)
+}
+
+func (fake *FakeCoreClient) ListKustomizationsCalls(stub func(context.Context, *api.ListKustomizationsRequest) (*api.ListKustomizationsResponse, error)) {
+	fake.listKustomizationsMutex.Lock()
+	defer fake.listKustomizationsMutex.Unlock()
+	fake.ListKustomizationsStub = stub
+}
+
+func (fake *FakeCoreClient) ListKustomizationsArgsForCall(i int) (context.Context, *api.ListKustomizationsRequest) {
+	fake.listKustomizationsMutex.RLock()
+	defer fake.listKustomizationsMutex.RUnlock()
+	argsForCall := fake.listKustomizationsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCoreClient) ListKustomizationsReturns(result1 *api.ListKustomizationsResponse, result2 error) {
+	fake.listKustomizationsMutex.Lock()
+	defer fake.listKustomizationsMutex.Unlock()
+	fake.ListKustomizationsStub = nil
+	fake.listKustomizationsReturns = struct {
+		result1 *api.ListKustomizationsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListKustomizationsReturnsOnCall(i int, result1 *api.ListKustomizationsResponse, result2 error) {
+	fake.listKustomizationsMutex.Lock()
+	defer fake.listKustomizationsMutex.Unlock()
+	fake.ListKustomizationsStub = nil
+	if fake.listKustomizationsReturnsOnCall == nil {
+		fake.listKustomizationsReturnsOnCall = make(map[int]struct {
+			result1 *api.ListKustomizationsResponse
+			result2 error
+		})
+	}
+	fake.listKustomizationsReturnsOnCall[i] = struct {
+		result1 *api.ListKustomizationsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListNamespaces(arg1 context.Context, arg2 *api.ListNamespacesRequest) (*api.ListNamespacesResponse, error) {
+	fake.listNamespacesMutex.Lock()
+	ret, specificReturn := fake.listNamespacesReturnsOnCall[len(fake.listNamespacesArgsForCall)]
+	fake.listNamespacesArgsForCall = append(fake.listNamespacesArgsForCall, struct {
+		arg1 context.Context
+		arg2 *api.ListNamespacesRequest
+	}{arg1, arg2})
+	stub := fake.ListNamespacesStub
+	fakeReturns := fake.listNamespacesReturns
+	fake.recordInvocation("ListNamespaces", []interface{}{arg1, arg2})
+	fake.listNamespacesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCoreClient) ListNamespacesCallCount() int {
+	fake.listNamespacesMutex.RLock()
+	defer fake.listNamespacesMutex.RUnlock()
+	return len(fake.listNamespacesArgsForCall)
+}
+
+func (fake *FakeCoreClient) ListNamespacesCalls(stub func(context.Context, *api.ListNamespacesRequest) (*api.ListNamespacesResponse, error)) {
+	fake.listNamespacesMutex.Lock()
+	defer fake.listNamespacesMutex.Unlock()
+	fake.ListNamespacesStub = stub
+}
+
+func (fake *FakeCoreClient) ListNamespacesArgsForCall(i int) (context.Context, *api.ListNamespacesRequest) {
+	fake.listNamespacesMutex.RLock()
+	defer fake.listNamespacesMutex.RUnlock()
+	argsForCall := fake.listNamespacesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeCoreClient) ListNamespacesReturns(result1 *api.ListNamespacesResponse, result2 error) {
+	fake.listNamespacesMutex.Lock()
+	defer fake.listNamespacesMutex.Unlock()
+	fake.ListNamespacesStub = nil
+	fake.listNamespacesReturns = struct {
+		result1 *api.ListNamespacesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) ListNamespacesReturnsOnCall(i int, result1 *api.ListNamespacesResponse, result2 error) {
+	fake.listNamespacesMutex.Lock()
+	defer fake.listNamespacesMutex.Unlock()
+	fake.ListNamespacesStub = nil
+	if fake.listNamespacesReturnsOnCall == nil {
+		fake.listNamespacesReturnsOnCall = make(map[int]struct {
+			result1 *api.ListNamespacesResponse
+			result2 error
+		})
+	}
+	fake.listNamespacesReturnsOnCall[i] = struct {
+		result1 *api.ListNamespacesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCoreClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getChildObjectsMutex.RLock()
+	defer fake.getChildObjectsMutex.RUnlock()
+	fake.getFluxNamespaceMutex.RLock()
+	defer fake.getFluxNamespaceMutex.RUnlock()
+	fake.getHelmReleaseMutex.RLock()
+	defer fake.getHelmReleaseMutex.RUnlock()
+	fake.getKustomizationMutex.RLock()
+	defer fake.getKustomizationMutex.RUnlock()
+	fake.getReconciledObjectsMutex.RLock()
+	defer fake.getReconciledObjectsMutex.RUnlock()
+	fake.listBucketsMutex.RLock()
+	defer fake.listBucketsMutex.RUnlock()
+	fake.listFluxEventsMutex.RLock()
+	defer fake.listFluxEventsMutex.RUnlock()
+	fake.listFluxRuntimeObjectsMutex.RLock()
+	defer fake.listFluxRuntimeObjectsMutex.RUnlock()
+	fake.listGitRepositoriesMutex.RLock()
+	defer fake.listGitRepositoriesMutex.RUnlock()
+	fake.listHelmChartsMutex.RLock()
+	defer fake.listHelmChartsMutex.RUnlock()
+	fake.listHelmReleasesMutex.RLock()
+	defer fake.listHelmReleasesMutex.RUnlock()
+	fake.listHelmRepositoriesMutex.RLock()
+	defer fake.listHelmRepositoriesMutex.RUnlock()
+	fake.listKustomizationsMutex.RLock()
+	defer fake.listKustomizationsMutex.RUnlock()
+	fake.listNamespacesMutex.RLock()
+	defer fake.listNamespacesMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeCoreClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ apiclient.CoreClient = new(FakeCoreClient)
@@ -0,0 +1,445 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package apiclientfakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/weaveworks/weave-gitops/pkg/api/profiles"
+	"github.com/weaveworks/weave-gitops/pkg/apiclient"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+)
+
+type FakeProfilesClient struct {
+	GetProfileValuesStub        func(context.Context, *profiles.GetProfileValuesRequest) (*httpbody.HttpBody, error)
+	getProfileValuesMutex       sync.RWMutex
+	getProfileValuesArgsForCall []struct {
+		arg1 context.Context
+		arg2 *profiles.GetProfileValuesRequest
+	}
+	getProfileValuesReturns struct {
+		result1 *httpbody.HttpBody
+		result2 error
+	}
+	getProfileValuesReturnsOnCall map[int]struct {
+		result1 *httpbody.HttpBody
+		result2 error
+	}
+	GetProfileValuesSchemaStub        func(context.Context, *profiles.GetProfileValuesSchemaRequest) (*profiles.GetProfileValuesSchemaResponse, error)
+	getProfileValuesSchemaMutex       sync.RWMutex
+	getProfileValuesSchemaArgsForCall []struct {
+		arg1 context.Context
+		arg2 *profiles.GetProfileValuesSchemaRequest
+	}
+	getProfileValuesSchemaReturns struct {
+		result1 *profiles.GetProfileValuesSchemaResponse
+		result2 error
+	}
+	getProfileValuesSchemaReturnsOnCall map[int]struct {
+		result1 *profiles.GetProfileValuesSchemaResponse
+		result2 error
+	}
+	GetProfilesStub        func(context.Context, *profiles.GetProfilesRequest) (*profiles.GetProfilesResponse, error)
+	getProfilesMutex       sync.RWMutex
+	getProfilesArgsForCall []struct {
+		arg1 context.Context
+		arg2 *profiles.GetProfilesRequest
+	}
+	getProfilesReturns struct {
+		result1 *profiles.GetProfilesResponse
+		result2 error
+	}
+	getProfilesReturnsOnCall map[int]struct {
+		result1 *profiles.GetProfilesResponse
+		result2 error
+	}
+	ListProfileUpgradesStub        func(context.Context, *profiles.ListProfileUpgradesRequest) (*profiles.ListProfileUpgradesResponse, error)
+	listProfileUpgradesMutex       sync.RWMutex
+	listProfileUpgradesArgsForCall []struct {
+		arg1 context.Context
+		arg2 *profiles.ListProfileUpgradesRequest
+	}
+	listProfileUpgradesReturns struct {
+		result1 *profiles.ListProfileUpgradesResponse
+		result2 error
+	}
+	listProfileUpgradesReturnsOnCall map[int]struct {
+		result1 *profiles.ListProfileUpgradesResponse
+		result2 error
+	}
+	ValidateProfileValuesStub        func(context.Context, *profiles.ValidateProfileValuesRequest) (*profiles.ValidateProfileValuesResponse, error)
+	validateProfileValuesMutex       sync.RWMutex
+	validateProfileValuesArgsForCall []struct {
+		arg1 context.Context
+		arg2 *profiles.ValidateProfileValuesRequest
+	}
+	validateProfileValuesReturns struct {
+		result1 *profiles.ValidateProfileValuesResponse
+		result2 error
+	}
+	validateProfileValuesReturnsOnCall map[int]struct {
+		result1 *profiles.ValidateProfileValuesResponse
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeProfilesClient) GetProfileValues(arg1 context.Context, arg2 *profiles.GetProfileValuesRequest) (*httpbody.HttpBody, error) {
+	fake.getProfileValuesMutex.Lock()
+	ret, specificReturn := fake.getProfileValuesReturnsOnCall[len(fake.getProfileValuesArgsForCall)]
+	fake.getProfileValuesArgsForCall = append(fake.getProfileValuesArgsForCall, struct {
+		arg1 context.Context
+		arg2 *profiles.GetProfileValuesRequest
+	}{arg1, arg2})
+	stub := fake.GetProfileValuesStub
+	fakeReturns := fake.getProfileValuesReturns
+	fake.recordInvocation("GetProfileValues", []interface{}{arg1, arg2})
+	fake.getProfileValuesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProfilesClient) GetProfileValuesCallCount() int {
+	fake.getProfileValuesMutex.RLock()
+	defer fake.getProfileValuesMutex.RUnlock()
+	return len(fake.getProfileValuesArgsForCall)
+}
+
+func (fake *FakeProfilesClient) GetProfileValuesCalls(stub func(context.Context, *profiles.GetProfileValuesRequest) (*httpbody.HttpBody, error)) {
+	fake.getProfileValuesMutex.Lock()
+	defer fake.getProfileValuesMutex.Unlock()
+	fake.GetProfileValuesStub = stub
+}
+
+func (fake *FakeProfilesClient) GetProfileValuesArgsForCall(i int) (context.Context, *profiles.GetProfileValuesRequest) {
+	fake.getProfileValuesMutex.RLock()
+	defer fake.getProfileValuesMutex.RUnlock()
+	argsForCall := fake.getProfileValuesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProfilesClient) GetProfileValuesReturns(result1 *httpbody.HttpBody, result2 error) {
+	fake.getProfileValuesMutex.Lock()
+	defer fake.getProfileValuesMutex.Unlock()
+	fake.GetProfileValuesStub = nil
+	fake.getProfileValuesReturns = struct {
+		result1 *httpbody.HttpBody
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProfilesClient) GetProfileValuesReturnsOnCall(i int, result1 *httpbody.HttpBody, result2 error) {
+	fake.getProfileValuesMutex.Lock()
+	defer fake.getProfileValuesMutex.Unlock()
+	fake.GetProfileValuesStub = nil
+	if fake.getProfileValuesReturnsOnCall == nil {
+		fake.getProfileValuesReturnsOnCall = make(map[int]struct {
+			result1 *httpbody.HttpBody
+			result2 error
+		})
+	}
+	fake.getProfileValuesReturnsOnCall[i] = struct {
+		result1 *httpbody.HttpBody
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProfilesClient) GetProfileValuesSchema(arg1 context.Context, arg2 *profiles.GetProfileValuesSchemaRequest) (*profiles.GetProfileValuesSchemaResponse, error) {
+	fake.getProfileValuesSchemaMutex.Lock()
+	ret, specificReturn := fake.getProfileValuesSchemaReturnsOnCall[len(fake.getProfileValuesSchemaArgsForCall)]
+	fake.getProfileValuesSchemaArgsForCall = append(fake.getProfileValuesSchemaArgsForCall, struct {
+		arg1 context.Context
+		arg2 *profiles.GetProfileValuesSchemaRequest
+	}{arg1, arg2})
+	stub := fake.GetProfileValuesSchemaStub
+	fakeReturns := fake.getProfileValuesSchemaReturns
+	fake.recordInvocation("GetProfileValuesSchema", []interface{}{arg1, arg2})
+	fake.getProfileValuesSchemaMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProfilesClient) GetProfileValuesSchemaCallCount() int {
+	fake.getProfileValuesSchemaMutex.RLock()
+	defer fake.getProfileValuesSchemaMutex.RUnlock()
+	return len(fake.getProfileValuesSchemaArgsForCall)
+}
+
+func (fake *FakeProfilesClient) GetProfileValuesSchemaCalls(stub func(context.Context, *profiles.GetProfileValuesSchemaRequest) (*profiles.GetProfileValuesSchemaResponse, error)) {
+	fake.getProfileValuesSchemaMutex.Lock()
+	defer fake.getProfileValuesSchemaMutex.Unlock()
+	fake.GetProfileValuesSchemaStub = stub
+}
+
+func (fake *FakeProfilesClient) GetProfileValuesSchemaArgsForCall(i int) (context.Context, *profiles.GetProfileValuesSchemaRequest) {
+	fake.getProfileValuesSchemaMutex.RLock()
+	defer fake.getProfileValuesSchemaMutex.RUnlock()
+	argsForCall := fake.getProfileValuesSchemaArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProfilesClient) GetProfileValuesSchemaReturns(result1 *profiles.GetProfileValuesSchemaResponse, result2 error) {
+	fake.getProfileValuesSchemaMutex.Lock()
+	defer fake.getProfileValuesSchemaMutex.Unlock()
+	fake.GetProfileValuesSchemaStub = nil
+	fake.getProfileValuesSchemaReturns = struct {
+		result1 *profiles.GetProfileValuesSchemaResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProfilesClient) GetProfileValuesSchemaReturnsOnCall(i int, result1 *profiles.GetProfileValuesSchemaResponse, result2 error) {
+	fake.getProfileValuesSchemaMutex.Lock()
+	defer fake.getProfileValuesSchemaMutex.Unlock()
+	fake.GetProfileValuesSchemaStub = nil
+	if fake.getProfileValuesSchemaReturnsOnCall == nil {
+		fake.getProfileValuesSchemaReturnsOnCall = make(map[int]struct {
+			result1 *profiles.GetProfileValuesSchemaResponse
+			result2 error
+		})
+	}
+	fake.getProfileValuesSchemaReturnsOnCall[i] = struct {
+		result1 *profiles.GetProfileValuesSchemaResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProfilesClient) GetProfiles(arg1 context.Context, arg2 *profiles.GetProfilesRequest) (*profiles.GetProfilesResponse, error) {
+	fake.getProfilesMutex.Lock()
+	ret, specificReturn := fake.getProfilesReturnsOnCall[len(fake.getProfilesArgsForCall)]
+	fake.getProfilesArgsForCall = append(fake.getProfilesArgsForCall, struct {
+		arg1 context.Context
+		arg2 *profiles.GetProfilesRequest
+	}{arg1, arg2})
+	stub := fake.GetProfilesStub
+	fakeReturns := fake.getProfilesReturns
+	fake.recordInvocation("GetProfiles", []interface{}{arg1, arg2})
+	fake.getProfilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProfilesClient) GetProfilesCallCount() int {
+	fake.getProfilesMutex.RLock()
+	defer fake.getProfilesMutex.RUnlock()
+	return len(fake.getProfilesArgsForCall)
+}
+
+func (fake *FakeProfilesClient) GetProfilesCalls(stub func(context.Context, *profiles.GetProfilesRequest) (*profiles.GetProfilesResponse, error)) {
+	fake.getProfilesMutex.Lock()
+	defer fake.getProfilesMutex.Unlock()
+	fake.GetProfilesStub = stub
+}
+
+func (fake *FakeProfilesClient) GetProfilesArgsForCall(i int) (context.Context, *profiles.GetProfilesRequest) {
+	fake.getProfilesMutex.RLock()
+	defer fake.getProfilesMutex.RUnlock()
+	argsForCall := fake.getProfilesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProfilesClient) GetProfilesReturns(result1 *profiles.GetProfilesResponse, result2 error) {
+	fake.getProfilesMutex.Lock()
+	defer fake.getProfilesMutex.Unlock()
+	fake.GetProfilesStub = nil
+	fake.getProfilesReturns = struct {
+		result1 *profiles.GetProfilesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProfilesClient) GetProfilesReturnsOnCall(i int, result1 *profiles.GetProfilesResponse, result2 error) {
+	fake.getProfilesMutex.Lock()
+	defer fake.getProfilesMutex.Unlock()
+	fake.GetProfilesStub = nil
+	if fake.getProfilesReturnsOnCall == nil {
+		fake.getProfilesReturnsOnCall = make(map[int]struct {
+			result1 *profiles.GetProfilesResponse
+			result2 error
+		})
+	}
+	fake.getProfilesReturnsOnCall[i] = struct {
+		result1 *profiles.GetProfilesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProfilesClient) ListProfileUpgrades(arg1 context.Context, arg2 *profiles.ListProfileUpgradesRequest) (*profiles.ListProfileUpgradesResponse, error) {
+	fake.listProfileUpgradesMutex.Lock()
+	ret, specificReturn := fake.listProfileUpgradesReturnsOnCall[len(fake.listProfileUpgradesArgsForCall)]
+	fake.listProfileUpgradesArgsForCall = append(fake.listProfileUpgradesArgsForCall, struct {
+		arg1 context.Context
+		arg2 *profiles.ListProfileUpgradesRequest
+	}{arg1, arg2})
+	stub := fake.ListProfileUpgradesStub
+	fakeReturns := fake.listProfileUpgradesReturns
+	fake.recordInvocation("ListProfileUpgrades", []interface{}{arg1, arg2})
+	fake.listProfileUpgradesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProfilesClient) ListProfileUpgradesCallCount() int {
+	fake.listProfileUpgradesMutex.RLock()
+	defer fake.listProfileUpgradesMutex.RUnlock()
+	return len(fake.listProfileUpgradesArgsForCall)
+}
+
+func (fake *FakeProfilesClient) ListProfileUpgradesCalls(stub func(context.Context, *profiles.ListProfileUpgradesRequest) (*profiles.ListProfileUpgradesResponse, error)) {
+	fake.listProfileUpgradesMutex.Lock()
+	defer fake.listProfileUpgradesMutex.Unlock()
+	fake.ListProfileUpgradesStub = stub
+}
+
+func (fake *FakeProfilesClient) ListProfileUpgradesArgsForCall(i int) (context.Context, *profiles.ListProfileUpgradesRequest) {
+	fake.listProfileUpgradesMutex.RLock()
+	defer fake.listProfileUpgradesMutex.RUnlock()
+	argsForCall := fake.listProfileUpgradesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProfilesClient) ListProfileUpgradesReturns(result1 *profiles.ListProfileUpgradesResponse, result2 error) {
+	fake.listProfileUpgradesMutex.Lock()
+	defer fake.listProfileUpgradesMutex.Unlock()
+	fake.ListProfileUpgradesStub = nil
+	fake.listProfileUpgradesReturns = struct {
+		result1 *profiles.ListProfileUpgradesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProfilesClient) ListProfileUpgradesReturnsOnCall(i int, result1 *profiles.ListProfileUpgradesResponse, result2 error) {
+	fake.listProfileUpgradesMutex.Lock()
+	defer fake.listProfileUpgradesMutex.Unlock()
+	fake.ListProfileUpgradesStub = nil
+	if fake.listProfileUpgradesReturnsOnCall == nil {
+		fake.listProfileUpgradesReturnsOnCall = make(map[int]struct {
+			result1 *profiles.ListProfileUpgradesResponse
+			result2 error
+		})
+	}
+	fake.listProfileUpgradesReturnsOnCall[i] = struct {
+		result1 *profiles.ListProfileUpgradesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProfilesClient) ValidateProfileValues(arg1 context.Context, arg2 *profiles.ValidateProfileValuesRequest) (*profiles.ValidateProfileValuesResponse, error) {
+	fake.validateProfileValuesMutex.Lock()
+	ret, specificReturn := fake.validateProfileValuesReturnsOnCall[len(fake.validateProfileValuesArgsForCall)]
+	fake.validateProfileValuesArgsForCall = append(fake.validateProfileValuesArgsForCall, struct {
+		arg1 context.Context
+		arg2 *profiles.ValidateProfileValuesRequest
+	}{arg1, arg2})
+	stub := fake.ValidateProfileValuesStub
+	fakeReturns := fake.validateProfileValuesReturns
+	fake.recordInvocation("ValidateProfileValues", []interface{}{arg1, arg2})
+	fake.validateProfileValuesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProfilesClient) ValidateProfileValuesCallCount() int {
+	fake.validateProfileValuesMutex.RLock()
+	defer fake.validateProfileValuesMutex.RUnlock()
+	return len(fake.validateProfileValuesArgsForCall)
+}
+
+func (fake *FakeProfilesClient) ValidateProfileValuesCalls(stub func(context.Context, *profiles.ValidateProfileValuesRequest) (*profiles.ValidateProfileValuesResponse, error)) {
+	fake.validateProfileValuesMutex.Lock()
+	defer fake.validateProfileValuesMutex.Unlock()
+	fake.ValidateProfileValuesStub = stub
+}
+
+func (fake *FakeProfilesClient) ValidateProfileValuesArgsForCall(i int) (context.Context, *profiles.ValidateProfileValuesRequest) {
+	fake.validateProfileValuesMutex.RLock()
+	defer fake.validateProfileValuesMutex.RUnlock()
+	argsForCall := fake.validateProfileValuesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProfilesClient) ValidateProfileValuesReturns(result1 *profiles.ValidateProfileValuesResponse, result2 error) {
+	fake.validateProfileValuesMutex.Lock()
+	defer fake.validateProfileValuesMutex.Unlock()
+	fake.ValidateProfileValuesStub = nil
+	fake.validateProfileValuesReturns = struct {
+		result1 *profiles.ValidateProfileValuesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProfilesClient) ValidateProfileValuesReturnsOnCall(i int, result1 *profiles.ValidateProfileValuesResponse, result2 error) {
+	fake.validateProfileValuesMutex.Lock()
+	defer fake.validateProfileValuesMutex.Unlock()
+	fake.ValidateProfileValuesStub = nil
+	if fake.validateProfileValuesReturnsOnCall == nil {
+		fake.validateProfileValuesReturnsOnCall = make(map[int]struct {
+			result1 *profiles.ValidateProfileValuesResponse
+			result2 error
+		})
+	}
+	fake.validateProfileValuesReturnsOnCall[i] = struct {
+		result1 *profiles.ValidateProfileValuesResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProfilesClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getProfileValuesMutex.RLock()
+	defer fake.getProfileValuesMutex.RUnlock()
+	fake.getProfileValuesSchemaMutex.RLock()
+	defer fake.getProfileValuesSchemaMutex.RUnlock()
+	fake.getProfilesMutex.RLock()
+	defer fake.getProfilesMutex.RUnlock()
+	fake.listProfileUpgradesMutex.RLock()
+	defer fake.listProfileUpgradesMutex.RUnlock()
+	fake.validateProfileValuesMutex.RLock()
+	defer fake.validateProfileValuesMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeProfilesClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ apiclient.ProfilesClient = new(FakeProfilesClient)
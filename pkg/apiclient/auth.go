@@ -0,0 +1,81 @@
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/weaveworks/weave-gitops/pkg/server/auth"
+)
+
+const signInPath = "/oauth2/sign_in"
+
+// authenticator adds credentials to an outgoing request.
+type authenticator interface {
+	apply(req *http.Request)
+}
+
+type bearerToken string
+
+func (t bearerToken) apply(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+string(t))
+}
+
+type idTokenCookie string
+
+func (t idTokenCookie) apply(req *http.Request) {
+	req.AddCookie(&http.Cookie{Name: auth.IDTokenCookieName, Value: string(t)})
+}
+
+// Login signs in as the cluster user (the admin account configured in the
+// cluster-user-auth Secret) and authenticates all subsequent requests with
+// the session cookie returned by the server.
+func (c *Client) Login(ctx context.Context, username, password string) error {
+	payload, err := json.Marshal(auth.LoginRequest{Username: username, Password: password})
+	if err != nil {
+		return fmt.Errorf("failed to encode login request: %w", err)
+	}
+
+	target := strings.TrimSuffix(c.t.base.String(), "/") + signInPath
+
+	res, err := c.t.retry.do(ctx, http.MethodPost, func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Content-Type", jsonContentType)
+		req.Header.Set("User-Agent", c.t.userAgent)
+
+		return c.t.httpClient.Do(req)
+	})
+	if err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		data, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("login failed: %w", newAPIError(res, data))
+	}
+
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == auth.IDTokenCookieName && cookie.Value != "" {
+			c.t.setAuth(idTokenCookie(cookie.Value))
+			return nil
+		}
+	}
+
+	return fmt.Errorf("login failed: no %s cookie in the response", auth.IDTokenCookieName)
+}
+
+// SetBearerToken replaces the credentials used by the client with an OIDC ID
+// token, e.g. after refreshing it.
+func (c *Client) SetBearerToken(token string) {
+	c.t.setAuth(bearerToken(token))
+}
@@ -0,0 +1,243 @@
+// Package apiclient provides a typed Go client for the gitops-server HTTP
+// API. The Core, Applications and Profiles service clients are generated from
+// the protobuf definitions in api/ and talk to the grpc-gateway routes served
+// under /v1/.
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+//go:generate go run ./internal/gen -out zz_generated.client.go
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+const (
+	jsonContentType = "application/json"
+	userAgent       = "weave-gitops-apiclient"
+)
+
+// Client gives access to every gitops-server API. The service clients are
+// interfaces so tests can swap them for the fakes in apiclientfakes.
+type Client struct {
+	Core         CoreClient
+	Applications ApplicationsClient
+	Profiles     ProfilesClient
+
+	t *transport
+}
+
+// New creates a Client for the gitops-server listening at endpoint, which must
+// be an absolute HTTP(S) URL, e.g. https://gitops.example.com.
+func New(endpoint string, opts ...Option) (*Client, error) {
+	u, err := url.ParseRequestURI(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid endpoint %q: scheme must be http or https", endpoint)
+	}
+
+	o := options{
+		retry:     DefaultRetryPolicy(),
+		userAgent: userAgent,
+	}
+
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+
+	httpClient := o.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()}
+	}
+
+	if o.tlsOptions != nil {
+		if err := applyTLSOptions(httpClient, *o.tlsOptions); err != nil {
+			return nil, err
+		}
+	}
+
+	t := &transport{
+		base:       u,
+		httpClient: httpClient,
+		retry:      o.retry,
+		userAgent:  o.userAgent,
+		auth:       o.auth,
+	}
+
+	return &Client{
+		Core:         coreClient{t: t},
+		Applications: applicationsClient{t: t},
+		Profiles:     profilesClient{t: t},
+		t:            t,
+	}, nil
+}
+
+// Endpoint returns the base URL of the gitops-server.
+func (c *Client) Endpoint() string {
+	return c.t.base.String()
+}
+
+// transport performs the HTTP round trips for all the service clients and
+// holds the shared authentication state.
+type transport struct {
+	base       *url.URL
+	httpClient *http.Client
+	retry      RetryPolicy
+	userAgent  string
+
+	mu   sync.RWMutex
+	auth authenticator
+}
+
+func (t *transport) setAuth(a authenticator) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.auth = a
+}
+
+func (t *transport) authenticate(req *http.Request) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.auth != nil {
+		t.auth.apply(req)
+	}
+}
+
+// do sends in to the route described by pattern and body, and decodes the
+// JSON response into out.
+func (t *transport) do(ctx context.Context, method, pattern, body string, in, out proto.Message) error {
+	res, err := t.send(ctx, method, pattern, body, in, jsonContentType, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(res, out); err != nil {
+		return fmt.Errorf("failed to decode response from %s %s: %w", method, pattern, err)
+	}
+
+	return nil
+}
+
+// doRaw is like do for routes that answer with an arbitrary body rather than
+// a JSON encoded message.
+func (t *transport) doRaw(ctx context.Context, method, pattern, body string, in proto.Message) (*httpbody.HttpBody, error) {
+	var contentType string
+
+	res, err := t.send(ctx, method, pattern, body, in, "*/*", func(h http.Header) {
+		contentType = h.Get("Content-Type")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &httpbody.HttpBody{ContentType: contentType, Data: res}, nil
+}
+
+// send performs the request, retrying according to the retry policy, and
+// returns the body of a successful response. headers, if set, is called with
+// the headers of that response.
+func (t *transport) send(ctx context.Context, method, pattern, body string, in proto.Message, accept string, headers func(http.Header)) ([]byte, error) {
+	path, consumed, err := expandPath(pattern, in)
+	if err != nil {
+		return nil, err
+	}
+
+	target := strings.TrimSuffix(t.base.String(), "/") + path
+
+	var payload []byte
+
+	switch body {
+	case "":
+		if q := encodeQuery(in, consumed).Encode(); q != "" {
+			target += "?" + q
+		}
+	case "*":
+		if payload, err = protojson.Marshal(in); err != nil {
+			return nil, fmt.Errorf("failed to encode request for %s %s: %w", method, pattern, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported body mapping %q for %s %s", body, method, pattern)
+	}
+
+	res, err := t.retry.do(ctx, method, func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Accept", accept)
+		req.Header.Set("User-Agent", t.userAgent)
+
+		if payload != nil {
+			req.Header.Set("Content-Type", jsonContentType)
+		}
+
+		t.authenticate(req)
+
+		return t.httpClient.Do(req)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response from %s %s: %w", method, path, err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, newAPIError(res, data)
+	}
+
+	if headers != nil {
+		headers(res.Header)
+	}
+
+	return data, nil
+}
+
+// drain discards what is left of a response body so the connection can be
+// reused before retrying.
+func drain(res *http.Response) {
+	if res == nil || res.Body == nil {
+		return
+	}
+
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+}
+
+// IsUnauthorized returns true if err is an APIError for a 401 response.
+func IsUnauthorized(err error) bool {
+	var apiErr *APIError
+
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+// IsNotFound returns true if err is an APIError for a 404 response.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
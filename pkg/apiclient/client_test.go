@@ -0,0 +1,246 @@
+package apiclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	pbcore "github.com/weaveworks/weave-gitops/pkg/api/core"
+	pbprofiles "github.com/weaveworks/weave-gitops/pkg/api/profiles"
+	"github.com/weaveworks/weave-gitops/pkg/apiclient"
+	"github.com/weaveworks/weave-gitops/pkg/apiclient/apiclientfakes"
+	"github.com/weaveworks/weave-gitops/pkg/server/auth"
+)
+
+func newClient(t *testing.T, h http.HandlerFunc, opts ...apiclient.Option) *apiclient.Client {
+	t.Helper()
+
+	s := httptest.NewServer(h)
+	t.Cleanup(s.Close)
+
+	c, err := apiclient.New(s.URL, opts...)
+	if err != nil {
+		t.Fatalf("failed creating client: %v", err)
+	}
+
+	return c
+}
+
+func TestNewRejectsInvalidEndpoint(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	_, err := apiclient.New("localhost:9001")
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = apiclient.New("ftp://localhost:9001")
+	g.Expect(err).To(MatchError(ContainSubstring("scheme must be http or https")))
+}
+
+func TestPathAndQueryEncoding(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var gotPath, gotQuery string
+
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotQuery = r.URL.RawQuery
+
+		_, _ = w.Write([]byte(`{"kustomization":{"name":"flux system","namespace":"ns"},"unknownField":true}`))
+	})
+
+	res, err := c.Core.GetKustomization(context.Background(), &pbcore.GetKustomizationRequest{
+		Name:        "flux system",
+		Namespace:   "ns",
+		ClusterName: "Default",
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res.Kustomization.Name).To(Equal("flux system"))
+	g.Expect(gotPath).To(Equal("/v1/kustomizations/flux%20system"))
+	g.Expect(gotQuery).To(Equal("clusterName=Default&namespace=ns"))
+}
+
+func TestMissingPathVariable(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request should be sent")
+	})
+
+	_, err := c.Core.GetKustomization(context.Background(), &pbcore.GetKustomizationRequest{})
+	g.Expect(err).To(MatchError(ContainSubstring(`field "name" is required`)))
+}
+
+func TestRawResponse(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.URL.Path).To(Equal("/v1/profiles/podinfo/6.0.1/values"))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write([]byte("replicaCount: 1\n"))
+	})
+
+	res, err := c.Profiles.GetProfileValues(context.Background(), &pbprofiles.GetProfileValuesRequest{
+		ProfileName:    "podinfo",
+		ProfileVersion: "6.0.1",
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res.ContentType).To(Equal("application/octet-stream"))
+	g.Expect(string(res.Data)).To(Equal("replicaCount: 1\n"))
+}
+
+func TestBearerToken(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var gotAuth string
+
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{}`))
+	}, apiclient.WithBearerToken("abc"))
+
+	_, err := c.Core.ListNamespaces(context.Background(), &pbcore.ListNamespacesRequest{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(gotAuth).To(Equal("Bearer abc"))
+
+	c.SetBearerToken("def")
+
+	_, err = c.Core.ListNamespaces(context.Background(), &pbcore.ListNamespacesRequest{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(gotAuth).To(Equal("Bearer def"))
+}
+
+func TestLogin(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/sign_in" {
+			var req auth.LoginRequest
+
+			g.Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+
+			if req.Password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			http.SetCookie(w, &http.Cookie{Name: auth.IDTokenCookieName, Value: "session"})
+
+			return
+		}
+
+		cookie, err := r.Cookie(auth.IDTokenCookieName)
+		if err != nil || cookie.Value != "session" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":16,"message":"unauthenticated"}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	_, err := c.Core.ListNamespaces(context.Background(), &pbcore.ListNamespacesRequest{})
+	g.Expect(apiclient.IsUnauthorized(err)).To(BeTrue())
+
+	g.Expect(c.Login(context.Background(), "admin", "wrong")).NotTo(Succeed())
+	g.Expect(c.Login(context.Background(), "admin", "secret")).To(Succeed())
+
+	_, err = c.Core.ListNamespaces(context.Background(), &pbcore.ListNamespacesRequest{})
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestAPIError(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":5,"message":"kustomization not found"}`))
+	})
+
+	_, err := c.Core.GetKustomization(context.Background(), &pbcore.GetKustomizationRequest{Name: "missing"})
+	g.Expect(apiclient.IsNotFound(err)).To(BeTrue())
+
+	var apiErr *apiclient.APIError
+
+	g.Expect(errors.As(err, &apiErr)).To(BeTrue())
+	g.Expect(apiErr.Code).To(Equal(5))
+	g.Expect(apiErr.Message).To(Equal("kustomization not found"))
+}
+
+func TestRetry(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	policy := apiclient.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	attempts := 0
+
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	}, apiclient.WithRetryPolicy(policy))
+
+	_, err := c.Core.ListNamespaces(context.Background(), &pbcore.ListNamespacesRequest{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(attempts).To(Equal(3))
+}
+
+func TestRetrySkipsNonIdempotentRequests(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	policy := apiclient.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	attempts := 0
+
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, apiclient.WithRetryPolicy(policy))
+
+	g.Expect(c.Login(context.Background(), "admin", "secret")).NotTo(Succeed())
+	g.Expect(attempts).To(Equal(1))
+}
+
+func TestForEachNamespace(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	core := &apiclientfakes.FakeCoreClient{}
+	core.ListNamespacesReturns(&pbcore.ListNamespacesResponse{
+		Namespaces: []*pbcore.Namespace{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+	}, nil)
+	core.ListKustomizationsStub = func(_ context.Context, req *pbcore.ListKustomizationsRequest) (*pbcore.ListKustomizationsResponse, error) {
+		return &pbcore.ListKustomizationsResponse{
+			Kustomizations: []*pbcore.Kustomization{{Name: "k", Namespace: req.Namespace}},
+		}, nil
+	}
+
+	all, err := apiclient.ListAllKustomizations(context.Background(), core)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(all).To(HaveLen(3))
+	g.Expect(all[2].Namespace).To(Equal("c"))
+
+	var visited []string
+
+	err = apiclient.ForEachNamespace(context.Background(), core, func(_ context.Context, ns string) error {
+		visited = append(visited, ns)
+
+		if ns == "b" {
+			return apiclient.ErrStopPaging
+		}
+
+		return nil
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(visited).To(Equal([]string{"a", "b"}))
+}
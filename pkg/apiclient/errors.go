@@ -0,0 +1,44 @@
+package apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned when the server answers with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	// Code is the gRPC status code reported by grpc-gateway, if any.
+	Code    int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("server responded with %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+
+	return fmt.Sprintf("server responded with %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+func newAPIError(res *http.Response, body []byte) error {
+	apiErr := &APIError{StatusCode: res.StatusCode}
+
+	// Both grpc-gateway errors and the auth handlers use a JSON object with a
+	// "message" field, anything else is reported verbatim.
+	var payload struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+
+	if err := json.Unmarshal(body, &payload); err == nil {
+		apiErr.Code = payload.Code
+		apiErr.Message = payload.Message
+	} else {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+
+	return apiErr
+}
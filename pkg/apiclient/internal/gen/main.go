@@ -0,0 +1,195 @@
+// gen generates the typed service clients in pkg/apiclient from the
+// registered protobuf descriptors and their google.api.http annotations.
+//
+// It is run through `go generate ./pkg/apiclient/...` and should be re-run
+// whenever the API definitions in api/ change.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"strings"
+	"text/template"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pbapp "github.com/weaveworks/weave-gitops/pkg/api/applications"
+	pbcore "github.com/weaveworks/weave-gitops/pkg/api/core"
+	pbprofiles "github.com/weaveworks/weave-gitops/pkg/api/profiles"
+)
+
+const httpBodyMessage = "google.api.HttpBody"
+
+type service struct {
+	Name    string
+	Impl    string
+	Methods []method
+}
+
+type method struct {
+	Name     string
+	Request  string
+	Response string
+	Verb     string
+	Pattern  string
+	Body     string
+	RawBody  bool
+}
+
+var sources = []struct {
+	alias string
+	file  protoreflect.FileDescriptor
+}{
+	{"pbcore", pbcore.File_api_core_core_proto},
+	{"pbapp", pbapp.File_api_applications_applications_proto},
+	{"pbprofiles", pbprofiles.File_api_profiles_profiles_proto},
+}
+
+func main() {
+	out := flag.String("out", "zz_generated.client.go", "the file to write the generated clients to")
+	flag.Parse()
+
+	var services []service
+
+	for _, src := range sources {
+		svcs, err := collectServices(src.alias, src.file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		services = append(services, svcs...)
+	}
+
+	var buf bytes.Buffer
+	if err := clientTemplate.Execute(&buf, services); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to render clients: %s\n", err)
+		os.Exit(1)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to format generated clients: %s\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %s\n", *out, err)
+		os.Exit(1)
+	}
+}
+
+func collectServices(alias string, file protoreflect.FileDescriptor) ([]service, error) {
+	var services []service
+
+	for i := 0; i < file.Services().Len(); i++ {
+		sd := file.Services().Get(i)
+		name := string(sd.Name())
+		svc := service{Name: name, Impl: strings.ToLower(name[:1]) + name[1:] + "Client"}
+
+		for j := 0; j < sd.Methods().Len(); j++ {
+			md := sd.Methods().Get(j)
+
+			rule, ok := proto.GetExtension(md.Options(), annotations.E_Http).(*annotations.HttpRule)
+			if !ok || rule == nil {
+				return nil, fmt.Errorf("method %s has no google.api.http annotation", md.FullName())
+			}
+
+			verb, pattern, err := httpRule(rule)
+			if err != nil {
+				return nil, fmt.Errorf("method %s: %w", md.FullName(), err)
+			}
+
+			svc.Methods = append(svc.Methods, method{
+				Name:     string(md.Name()),
+				Request:  goType(alias, md.Input()),
+				Response: goType(alias, md.Output()),
+				Verb:     verb,
+				Pattern:  pattern,
+				Body:     rule.GetBody(),
+				RawBody:  md.Output().FullName() == httpBodyMessage,
+			})
+		}
+
+		services = append(services, svc)
+	}
+
+	return services, nil
+}
+
+func httpRule(rule *annotations.HttpRule) (string, string, error) {
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return "http.MethodGet", p.Get, nil
+	case *annotations.HttpRule_Post:
+		return "http.MethodPost", p.Post, nil
+	case *annotations.HttpRule_Put:
+		return "http.MethodPut", p.Put, nil
+	case *annotations.HttpRule_Patch:
+		return "http.MethodPatch", p.Patch, nil
+	case *annotations.HttpRule_Delete:
+		return "http.MethodDelete", p.Delete, nil
+	}
+
+	return "", "", fmt.Errorf("unsupported http rule %v", rule)
+}
+
+func goType(alias string, md protoreflect.MessageDescriptor) string {
+	if md.FullName() == httpBodyMessage {
+		return "httpbody.HttpBody"
+	}
+
+	return alias + "." + strings.ReplaceAll(string(md.Name()), ".", "_")
+}
+
+var clientTemplate = template.Must(template.New("clients").Parse(`// Code generated by pkg/apiclient/internal/gen. DO NOT EDIT.
+
+package apiclient
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+
+	pbapp "github.com/weaveworks/weave-gitops/pkg/api/applications"
+	pbcore "github.com/weaveworks/weave-gitops/pkg/api/core"
+	pbprofiles "github.com/weaveworks/weave-gitops/pkg/api/profiles"
+)
+
+{{ range $svc := . }}
+// {{ $svc.Name }}Client is a typed HTTP client for the {{ $svc.Name }} API.
+//counterfeiter:generate . {{ $svc.Name }}Client
+type {{ $svc.Name }}Client interface {
+{{- range $svc.Methods }}
+	{{ .Name }}(ctx context.Context, in *{{ .Request }}) (*{{ .Response }}, error)
+{{- end }}
+}
+
+type {{ $svc.Impl }} struct {
+	t *transport
+}
+
+var _ {{ $svc.Name }}Client = {{ $svc.Impl }}{}
+{{ range $svc.Methods }}
+// {{ .Name }} calls {{ $svc.Name }}.{{ .Name }} ({{ .Pattern }}).
+func (c {{ $svc.Impl }}) {{ .Name }}(ctx context.Context, in *{{ .Request }}) (*{{ .Response }}, error) {
+{{- if .RawBody }}
+	return c.t.doRaw(ctx, {{ .Verb }}, "{{ .Pattern }}", "{{ .Body }}", in)
+{{- else }}
+	out := &{{ .Response }}{}
+	if err := c.t.do(ctx, {{ .Verb }}, "{{ .Pattern }}", "{{ .Body }}", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+{{- end }}
+}
+{{ end }}
+{{- end }}
+`))
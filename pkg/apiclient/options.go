@@ -0,0 +1,79 @@
+package apiclient
+
+import (
+	"errors"
+	"net/http"
+)
+
+type options struct {
+	httpClient *http.Client
+	tlsOptions *TLSOptions
+	retry      RetryPolicy
+	userAgent  string
+	auth       authenticator
+}
+
+// Option configures a Client.
+type Option func(*options) error
+
+// WithHTTPClient sets the http.Client used to talk to the server. TLS options,
+// if any, are applied to its transport.
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *options) error {
+		if c == nil {
+			return errors.New("http client cannot be nil")
+		}
+
+		o.httpClient = c
+
+		return nil
+	}
+}
+
+// WithTLS configures how the client verifies the server and, for mTLS, which
+// client certificate it presents.
+func WithTLS(tlsOptions TLSOptions) Option {
+	return func(o *options) error {
+		o.tlsOptions = &tlsOptions
+		return nil
+	}
+}
+
+// WithRetryPolicy overrides the DefaultRetryPolicy.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *options) error {
+		if p.MaxAttempts < 1 {
+			return errors.New("retry policy must allow at least one attempt")
+		}
+
+		o.retry = p
+
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(o *options) error {
+		o.userAgent = ua
+		return nil
+	}
+}
+
+// WithBearerToken authenticates every request with an OIDC ID token passed in
+// the Authorization header.
+func WithBearerToken(token string) Option {
+	return func(o *options) error {
+		o.auth = bearerToken(token)
+		return nil
+	}
+}
+
+// WithIDTokenCookie authenticates every request with the id_token cookie, as
+// issued by the server to the UI after an OIDC or admin login.
+func WithIDTokenCookie(token string) Option {
+	return func(o *options) error {
+		o.auth = idTokenCookie(token)
+		return nil
+	}
+}
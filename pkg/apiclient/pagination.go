@@ -0,0 +1,82 @@
+package apiclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pbcore "github.com/weaveworks/weave-gitops/pkg/api/core"
+)
+
+// The list RPCs of the Core API take a namespace and return every matching
+// object at once; there are no server-side page tokens. These helpers walk the
+// namespaces the caller can see, so large clusters can be processed one
+// namespace (one "page") at a time.
+
+// NamespacePageFunc is called once per namespace. Returning ErrStopPaging ends
+// the iteration without an error.
+type NamespacePageFunc func(ctx context.Context, namespace string) error
+
+// ErrStopPaging can be returned from a NamespacePageFunc to stop iterating.
+var ErrStopPaging = errors.New("stop paging")
+
+// ForEachNamespace lists the namespaces visible to the caller and calls fn for
+// each of them in order.
+func ForEachNamespace(ctx context.Context, c CoreClient, fn NamespacePageFunc) error {
+	res, err := c.ListNamespaces(ctx, &pbcore.ListNamespacesRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	for _, ns := range res.Namespaces {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := fn(ctx, ns.Name); err != nil {
+			if err == ErrStopPaging {
+				return nil
+			}
+
+			return fmt.Errorf("namespace %s: %w", ns.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ListAllKustomizations collects the Kustomizations of every namespace.
+func ListAllKustomizations(ctx context.Context, c CoreClient) ([]*pbcore.Kustomization, error) {
+	var all []*pbcore.Kustomization
+
+	err := ForEachNamespace(ctx, c, func(ctx context.Context, namespace string) error {
+		res, err := c.ListKustomizations(ctx, &pbcore.ListKustomizationsRequest{Namespace: namespace})
+		if err != nil {
+			return err
+		}
+
+		all = append(all, res.Kustomizations...)
+
+		return nil
+	})
+
+	return all, err
+}
+
+// ListAllHelmReleases collects the HelmReleases of every namespace.
+func ListAllHelmReleases(ctx context.Context, c CoreClient) ([]*pbcore.HelmRelease, error) {
+	var all []*pbcore.HelmRelease
+
+	err := ForEachNamespace(ctx, c, func(ctx context.Context, namespace string) error {
+		res, err := c.ListHelmReleases(ctx, &pbcore.ListHelmReleasesRequest{Namespace: namespace})
+		if err != nil {
+			return err
+		}
+
+		all = append(all, res.HelmReleases...)
+
+		return nil
+	})
+
+	return all, err
+}
@@ -0,0 +1,111 @@
+package apiclient
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// expandPath replaces the {field} variables of a google.api.http path template
+// with the matching fields of msg. It returns the expanded path and the names
+// of the fields that were used, so they are not repeated in the query string.
+func expandPath(pattern string, msg proto.Message) (string, map[string]bool, error) {
+	consumed := map[string]bool{}
+	fields := msg.ProtoReflect().Descriptor().Fields()
+
+	var sb strings.Builder
+
+	for {
+		start := strings.Index(pattern, "{")
+		if start < 0 {
+			sb.WriteString(pattern)
+			break
+		}
+
+		end := strings.Index(pattern[start:], "}")
+		if end < 0 {
+			return "", nil, fmt.Errorf("unterminated variable in path %q", pattern)
+		}
+
+		end += start
+		name := pattern[start+1 : end]
+
+		// Variables may carry a sub-pattern, e.g. {name=projects/*}.
+		if i := strings.Index(name, "="); i >= 0 {
+			name = name[:i]
+		}
+
+		fd := fields.ByName(protoreflect.Name(name))
+		if fd == nil {
+			return "", nil, fmt.Errorf("path variable %q is not a field of %s", name, msg.ProtoReflect().Descriptor().FullName())
+		}
+
+		value := msg.ProtoReflect().Get(fd).String()
+		if value == "" {
+			return "", nil, fmt.Errorf("field %q is required to build the request path", name)
+		}
+
+		sb.WriteString(pattern[:start])
+		sb.WriteString(url.PathEscape(value))
+
+		consumed[name] = true
+		pattern = pattern[end+1:]
+	}
+
+	return sb.String(), consumed, nil
+}
+
+// encodeQuery encodes the populated fields of msg that are not part of the
+// path as query parameters, the way grpc-gateway expects them. Nested messages
+// are flattened into dotted parameter names.
+func encodeQuery(msg proto.Message, skip map[string]bool) url.Values {
+	values := url.Values{}
+
+	addQueryValues(values, "", msg.ProtoReflect(), skip)
+
+	return values
+}
+
+func addQueryValues(values url.Values, prefix string, m protoreflect.Message, skip map[string]bool) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if prefix == "" && skip[string(fd.Name())] {
+			return true
+		}
+
+		key := prefix + fd.JSONName()
+
+		switch {
+		case fd.IsMap():
+			// Maps cannot be expressed as grpc-gateway query parameters.
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				values.Add(key, scalarString(fd, list.Get(i)))
+			}
+		case fd.Kind() == protoreflect.MessageKind:
+			addQueryValues(values, key+".", v.Message(), nil)
+		default:
+			values.Add(key, scalarString(fd, v))
+		}
+
+		return true
+	})
+}
+
+func scalarString(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	if fd.Kind() == protoreflect.EnumKind {
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+	}
+
+	if fd.Kind() == protoreflect.BytesKind {
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	}
+
+	return v.String()
+}
@@ -0,0 +1,95 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. Requests are retried
+// on transport errors and on 429, 502, 503 and 504 responses.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry. It doubles on each
+	// subsequent retry, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// RetryNonIdempotent allows POST requests to be retried too.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy retries idempotent requests up to three times.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+	}
+}
+
+// NoRetry disables retries.
+func NoRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// do runs attempt until it succeeds, returns a response that should not be
+// retried, or the policy is exhausted. The last response or error is returned.
+func (p RetryPolicy) do(ctx context.Context, method string, attempt func() (*http.Response, error)) (*http.Response, error) {
+	backoff := p.InitialBackoff
+	retryable := p.RetryNonIdempotent || method == http.MethodGet
+
+	for n := 1; ; n++ {
+		res, err := attempt()
+
+		if n >= p.MaxAttempts || !retryable || !shouldRetry(res, err) {
+			return res, err
+		}
+
+		wait := backoff
+		if d, ok := retryAfter(res); ok {
+			wait = d
+		}
+
+		if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+			wait = p.MaxBackoff
+		}
+
+		drain(res)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+	}
+}
+
+func shouldRetry(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+
+	seconds, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
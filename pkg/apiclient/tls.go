@@ -0,0 +1,82 @@
+package apiclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// TLSOptions configures the TLS settings of the client.
+type TLSOptions struct {
+	// CAFile is a PEM bundle used instead of the system roots to verify the
+	// server certificate.
+	CAFile string
+	// CertFile and KeyFile hold a client certificate presented to servers
+	// running with --mtls.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name used to verify the server certificate.
+	ServerName string
+	// InsecureSkipVerify disables verification of the server certificate.
+	InsecureSkipVerify bool
+}
+
+func (o TLSOptions) config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.CAFile != "" {
+		pem, err := ioutil.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading CA file %s: %w", o.CAFile, err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", o.CAFile)
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if (o.CertFile == "") != (o.KeyFile == "") {
+		return nil, errors.New("both a client certificate and key are required for mTLS")
+	}
+
+	if o.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed loading client certificate: %w", err)
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+func applyTLSOptions(c *http.Client, o TLSOptions) error {
+	cfg, err := o.config()
+	if err != nil {
+		return err
+	}
+
+	if c.Transport == nil {
+		c.Transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	t, ok := c.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("cannot apply TLS options to transport of type %T", c.Transport)
+	}
+
+	t.TLSClientConfig = cfg
+
+	return nil
+}
@@ -0,0 +1,342 @@
+// Code generated by pkg/apiclient/internal/gen. DO NOT EDIT.
+
+package apiclient
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+
+	pbapp "github.com/weaveworks/weave-gitops/pkg/api/applications"
+	pbcore "github.com/weaveworks/weave-gitops/pkg/api/core"
+	pbprofiles "github.com/weaveworks/weave-gitops/pkg/api/profiles"
+)
+
+// CoreClient is a typed HTTP client for the Core API.
+//
+//counterfeiter:generate . CoreClient
+type CoreClient interface {
+	ListKustomizations(ctx context.Context, in *pbcore.ListKustomizationsRequest) (*pbcore.ListKustomizationsResponse, error)
+	GetKustomization(ctx context.Context, in *pbcore.GetKustomizationRequest) (*pbcore.GetKustomizationResponse, error)
+	ListHelmReleases(ctx context.Context, in *pbcore.ListHelmReleasesRequest) (*pbcore.ListHelmReleasesResponse, error)
+	GetHelmRelease(ctx context.Context, in *pbcore.GetHelmReleaseRequest) (*pbcore.GetHelmReleaseResponse, error)
+	ListGitRepositories(ctx context.Context, in *pbcore.ListGitRepositoriesRequest) (*pbcore.ListGitRepositoriesResponse, error)
+	ListHelmCharts(ctx context.Context, in *pbcore.ListHelmChartsRequest) (*pbcore.ListHelmChartsResponse, error)
+	ListHelmRepositories(ctx context.Context, in *pbcore.ListHelmRepositoriesRequest) (*pbcore.ListHelmRepositoriesResponse, error)
+	ListBuckets(ctx context.Context, in *pbcore.ListBucketRequest) (*pbcore.ListBucketsResponse, error)
+	ListFluxRuntimeObjects(ctx context.Context, in *pbcore.ListFluxRuntimeObjectsRequest) (*pbcore.ListFluxRuntimeObjectsResponse, error)
+	GetReconciledObjects(ctx context.Context, in *pbcore.GetReconciledObjectsRequest) (*pbcore.GetReconciledObjectsResponse, error)
+	GetChildObjects(ctx context.Context, in *pbcore.GetChildObjectsRequest) (*pbcore.GetChildObjectsResponse, error)
+	GetFluxNamespace(ctx context.Context, in *pbcore.GetFluxNamespaceRequest) (*pbcore.GetFluxNamespaceResponse, error)
+	ListNamespaces(ctx context.Context, in *pbcore.ListNamespacesRequest) (*pbcore.ListNamespacesResponse, error)
+	ListFluxEvents(ctx context.Context, in *pbcore.ListFluxEventsRequest) (*pbcore.ListFluxEventsResponse, error)
+}
+
+type coreClient struct {
+	t *transport
+}
+
+var _ CoreClient = coreClient{}
+
+// ListKustomizations calls Core.ListKustomizations (/v1/kustomizations).
+func (c coreClient) ListKustomizations(ctx context.Context, in *pbcore.ListKustomizationsRequest) (*pbcore.ListKustomizationsResponse, error) {
+	out := &pbcore.ListKustomizationsResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/kustomizations", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GetKustomization calls Core.GetKustomization (/v1/kustomizations/{name}).
+func (c coreClient) GetKustomization(ctx context.Context, in *pbcore.GetKustomizationRequest) (*pbcore.GetKustomizationResponse, error) {
+	out := &pbcore.GetKustomizationResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/kustomizations/{name}", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ListHelmReleases calls Core.ListHelmReleases (/v1/helmreleases).
+func (c coreClient) ListHelmReleases(ctx context.Context, in *pbcore.ListHelmReleasesRequest) (*pbcore.ListHelmReleasesResponse, error) {
+	out := &pbcore.ListHelmReleasesResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/helmreleases", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GetHelmRelease calls Core.GetHelmRelease (/v1/helmrelease/{name}).
+func (c coreClient) GetHelmRelease(ctx context.Context, in *pbcore.GetHelmReleaseRequest) (*pbcore.GetHelmReleaseResponse, error) {
+	out := &pbcore.GetHelmReleaseResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/helmrelease/{name}", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ListGitRepositories calls Core.ListGitRepositories (/v1/gitrepositories).
+func (c coreClient) ListGitRepositories(ctx context.Context, in *pbcore.ListGitRepositoriesRequest) (*pbcore.ListGitRepositoriesResponse, error) {
+	out := &pbcore.ListGitRepositoriesResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/gitrepositories", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ListHelmCharts calls Core.ListHelmCharts (/v1/helmcharts).
+func (c coreClient) ListHelmCharts(ctx context.Context, in *pbcore.ListHelmChartsRequest) (*pbcore.ListHelmChartsResponse, error) {
+	out := &pbcore.ListHelmChartsResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/helmcharts", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ListHelmRepositories calls Core.ListHelmRepositories (/v1/helmrepositories).
+func (c coreClient) ListHelmRepositories(ctx context.Context, in *pbcore.ListHelmRepositoriesRequest) (*pbcore.ListHelmRepositoriesResponse, error) {
+	out := &pbcore.ListHelmRepositoriesResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/helmrepositories", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ListBuckets calls Core.ListBuckets (/v1/buckets).
+func (c coreClient) ListBuckets(ctx context.Context, in *pbcore.ListBucketRequest) (*pbcore.ListBucketsResponse, error) {
+	out := &pbcore.ListBucketsResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/buckets", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ListFluxRuntimeObjects calls Core.ListFluxRuntimeObjects (/v1/flux_runtime_objects).
+func (c coreClient) ListFluxRuntimeObjects(ctx context.Context, in *pbcore.ListFluxRuntimeObjectsRequest) (*pbcore.ListFluxRuntimeObjectsResponse, error) {
+	out := &pbcore.ListFluxRuntimeObjectsResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/flux_runtime_objects", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GetReconciledObjects calls Core.GetReconciledObjects (/v1/reconciled_objects).
+func (c coreClient) GetReconciledObjects(ctx context.Context, in *pbcore.GetReconciledObjectsRequest) (*pbcore.GetReconciledObjectsResponse, error) {
+	out := &pbcore.GetReconciledObjectsResponse{}
+	if err := c.t.do(ctx, http.MethodPost, "/v1/reconciled_objects", "*", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GetChildObjects calls Core.GetChildObjects (/v1/child_objects).
+func (c coreClient) GetChildObjects(ctx context.Context, in *pbcore.GetChildObjectsRequest) (*pbcore.GetChildObjectsResponse, error) {
+	out := &pbcore.GetChildObjectsResponse{}
+	if err := c.t.do(ctx, http.MethodPost, "/v1/child_objects", "*", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GetFluxNamespace calls Core.GetFluxNamespace (/v1/namespace/flux).
+func (c coreClient) GetFluxNamespace(ctx context.Context, in *pbcore.GetFluxNamespaceRequest) (*pbcore.GetFluxNamespaceResponse, error) {
+	out := &pbcore.GetFluxNamespaceResponse{}
+	if err := c.t.do(ctx, http.MethodPost, "/v1/namespace/flux", "*", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ListNamespaces calls Core.ListNamespaces (/v1/namespaces).
+func (c coreClient) ListNamespaces(ctx context.Context, in *pbcore.ListNamespacesRequest) (*pbcore.ListNamespacesResponse, error) {
+	out := &pbcore.ListNamespacesResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/namespaces", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ListFluxEvents calls Core.ListFluxEvents (/v1/events).
+func (c coreClient) ListFluxEvents(ctx context.Context, in *pbcore.ListFluxEventsRequest) (*pbcore.ListFluxEventsResponse, error) {
+	out := &pbcore.ListFluxEventsResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/events", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ApplicationsClient is a typed HTTP client for the Applications API.
+//
+//counterfeiter:generate . ApplicationsClient
+type ApplicationsClient interface {
+	Authenticate(ctx context.Context, in *pbapp.AuthenticateRequest) (*pbapp.AuthenticateResponse, error)
+	GetGithubDeviceCode(ctx context.Context, in *pbapp.GetGithubDeviceCodeRequest) (*pbapp.GetGithubDeviceCodeResponse, error)
+	GetGithubAuthStatus(ctx context.Context, in *pbapp.GetGithubAuthStatusRequest) (*pbapp.GetGithubAuthStatusResponse, error)
+	GetGitlabAuthURL(ctx context.Context, in *pbapp.GetGitlabAuthURLRequest) (*pbapp.GetGitlabAuthURLResponse, error)
+	AuthorizeGitlab(ctx context.Context, in *pbapp.AuthorizeGitlabRequest) (*pbapp.AuthorizeGitlabResponse, error)
+	ParseRepoURL(ctx context.Context, in *pbapp.ParseRepoURLRequest) (*pbapp.ParseRepoURLResponse, error)
+	ValidateProviderToken(ctx context.Context, in *pbapp.ValidateProviderTokenRequest) (*pbapp.ValidateProviderTokenResponse, error)
+	GetFeatureFlags(ctx context.Context, in *pbapp.GetFeatureFlagsRequest) (*pbapp.GetFeatureFlagsResponse, error)
+}
+
+type applicationsClient struct {
+	t *transport
+}
+
+var _ ApplicationsClient = applicationsClient{}
+
+// Authenticate calls Applications.Authenticate (/v1/authenticate/{provider_name}).
+func (c applicationsClient) Authenticate(ctx context.Context, in *pbapp.AuthenticateRequest) (*pbapp.AuthenticateResponse, error) {
+	out := &pbapp.AuthenticateResponse{}
+	if err := c.t.do(ctx, http.MethodPost, "/v1/authenticate/{provider_name}", "*", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GetGithubDeviceCode calls Applications.GetGithubDeviceCode (/v1/applications/auth_providers/github).
+func (c applicationsClient) GetGithubDeviceCode(ctx context.Context, in *pbapp.GetGithubDeviceCodeRequest) (*pbapp.GetGithubDeviceCodeResponse, error) {
+	out := &pbapp.GetGithubDeviceCodeResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/applications/auth_providers/github", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GetGithubAuthStatus calls Applications.GetGithubAuthStatus (/v1/applications/auth_providers/github/status).
+func (c applicationsClient) GetGithubAuthStatus(ctx context.Context, in *pbapp.GetGithubAuthStatusRequest) (*pbapp.GetGithubAuthStatusResponse, error) {
+	out := &pbapp.GetGithubAuthStatusResponse{}
+	if err := c.t.do(ctx, http.MethodPost, "/v1/applications/auth_providers/github/status", "*", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GetGitlabAuthURL calls Applications.GetGitlabAuthURL (/v1/applications/auth_providers/gitlab).
+func (c applicationsClient) GetGitlabAuthURL(ctx context.Context, in *pbapp.GetGitlabAuthURLRequest) (*pbapp.GetGitlabAuthURLResponse, error) {
+	out := &pbapp.GetGitlabAuthURLResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/applications/auth_providers/gitlab", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// AuthorizeGitlab calls Applications.AuthorizeGitlab (/v1/applications/auth_providers/gitlab/authorize).
+func (c applicationsClient) AuthorizeGitlab(ctx context.Context, in *pbapp.AuthorizeGitlabRequest) (*pbapp.AuthorizeGitlabResponse, error) {
+	out := &pbapp.AuthorizeGitlabResponse{}
+	if err := c.t.do(ctx, http.MethodPost, "/v1/applications/auth_providers/gitlab/authorize", "*", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ParseRepoURL calls Applications.ParseRepoURL (/v1/applications/parse_repo_url).
+func (c applicationsClient) ParseRepoURL(ctx context.Context, in *pbapp.ParseRepoURLRequest) (*pbapp.ParseRepoURLResponse, error) {
+	out := &pbapp.ParseRepoURLResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/applications/parse_repo_url", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ValidateProviderToken calls Applications.ValidateProviderToken (/v1/applications/validate_token).
+func (c applicationsClient) ValidateProviderToken(ctx context.Context, in *pbapp.ValidateProviderTokenRequest) (*pbapp.ValidateProviderTokenResponse, error) {
+	out := &pbapp.ValidateProviderTokenResponse{}
+	if err := c.t.do(ctx, http.MethodPost, "/v1/applications/validate_token", "*", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GetFeatureFlags calls Applications.GetFeatureFlags (/v1/featureflags).
+func (c applicationsClient) GetFeatureFlags(ctx context.Context, in *pbapp.GetFeatureFlagsRequest) (*pbapp.GetFeatureFlagsResponse, error) {
+	out := &pbapp.GetFeatureFlagsResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/featureflags", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ProfilesClient is a typed HTTP client for the Profiles API.
+//
+//counterfeiter:generate . ProfilesClient
+type ProfilesClient interface {
+	GetProfiles(ctx context.Context, in *pbprofiles.GetProfilesRequest) (*pbprofiles.GetProfilesResponse, error)
+	GetProfileValues(ctx context.Context, in *pbprofiles.GetProfileValuesRequest) (*httpbody.HttpBody, error)
+	GetProfileValuesSchema(ctx context.Context, in *pbprofiles.GetProfileValuesSchemaRequest) (*pbprofiles.GetProfileValuesSchemaResponse, error)
+	ValidateProfileValues(ctx context.Context, in *pbprofiles.ValidateProfileValuesRequest) (*pbprofiles.ValidateProfileValuesResponse, error)
+	ListProfileUpgrades(ctx context.Context, in *pbprofiles.ListProfileUpgradesRequest) (*pbprofiles.ListProfileUpgradesResponse, error)
+}
+
+type profilesClient struct {
+	t *transport
+}
+
+var _ ProfilesClient = profilesClient{}
+
+// GetProfiles calls Profiles.GetProfiles (/v1/profiles).
+func (c profilesClient) GetProfiles(ctx context.Context, in *pbprofiles.GetProfilesRequest) (*pbprofiles.GetProfilesResponse, error) {
+	out := &pbprofiles.GetProfilesResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/profiles", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GetProfileValues calls Profiles.GetProfileValues (/v1/profiles/{profile_name}/{profile_version}/values).
+func (c profilesClient) GetProfileValues(ctx context.Context, in *pbprofiles.GetProfileValuesRequest) (*httpbody.HttpBody, error) {
+	return c.t.doRaw(ctx, http.MethodGet, "/v1/profiles/{profile_name}/{profile_version}/values", "", in)
+}
+
+// GetProfileValuesSchema calls Profiles.GetProfileValuesSchema (/v1/profiles/{profile_name}/{profile_version}/values/schema).
+func (c profilesClient) GetProfileValuesSchema(ctx context.Context, in *pbprofiles.GetProfileValuesSchemaRequest) (*pbprofiles.GetProfileValuesSchemaResponse, error) {
+	out := &pbprofiles.GetProfileValuesSchemaResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/profiles/{profile_name}/{profile_version}/values/schema", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ValidateProfileValues calls Profiles.ValidateProfileValues (/v1/profiles/{profile_name}/{profile_version}/values/validate).
+func (c profilesClient) ValidateProfileValues(ctx context.Context, in *pbprofiles.ValidateProfileValuesRequest) (*pbprofiles.ValidateProfileValuesResponse, error) {
+	out := &pbprofiles.ValidateProfileValuesResponse{}
+	if err := c.t.do(ctx, http.MethodPost, "/v1/profiles/{profile_name}/{profile_version}/values/validate", "*", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ListProfileUpgrades calls Profiles.ListProfileUpgrades (/v1/profiles/upgrades).
+func (c profilesClient) ListProfileUpgrades(ctx context.Context, in *pbprofiles.ListProfileUpgradesRequest) (*pbprofiles.ListProfileUpgradesResponse, error) {
+	out := &pbprofiles.ListProfileUpgradesResponse{}
+	if err := c.t.do(ctx, http.MethodGet, "/v1/profiles/upgrades", "", in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
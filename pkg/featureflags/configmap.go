@@ -0,0 +1,65 @@
+package featureflags
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+// DefaultConfigMapName is the ConfigMap read by the gitops-server.
+const DefaultConfigMapName = "weave-gitops-feature-flags"
+
+// WatchConfigMap keeps the flags of store in sync with a ConfigMap until ctx
+// is done. A missing ConfigMap means there are no targeted flags, an invalid
+// one is logged and the flags in effect are kept.
+func WatchConfigMap(ctx context.Context, log logr.Logger, cs kubernetes.Interface, namespace, name string, store *Store) {
+	log = log.WithValues("configmap", namespace+"/"+name)
+	selector := fields.OneTermEqualSelector("metadata.name", name).String()
+
+	lw := &toolscache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.FieldSelector = selector
+			return cs.CoreV1().ConfigMaps(namespace).List(ctx, opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.FieldSelector = selector
+			return cs.CoreV1().ConfigMaps(namespace).Watch(ctx, opts)
+		},
+	}
+
+	update := func(obj interface{}) {
+		cm, ok := obj.(*corev1.ConfigMap)
+		if !ok || cm.Name != name {
+			return
+		}
+
+		flags, err := Parse(cm.Data)
+		if err != nil {
+			log.Error(err, "ignoring invalid feature flags")
+			return
+		}
+
+		store.SetFlags(flags)
+		log.Info("Loaded feature flags", "count", len(flags))
+	}
+
+	_, informer := toolscache.NewInformer(lw, &corev1.ConfigMap{}, 0, toolscache.ResourceEventHandlerFuncs{
+		AddFunc: update,
+		UpdateFunc: func(_, obj interface{}) {
+			update(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			store.SetFlags(nil)
+			log.Info("Feature flags ConfigMap deleted")
+		},
+	})
+
+	informer.Run(ctx.Done())
+}
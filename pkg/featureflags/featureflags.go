@@ -0,0 +1,100 @@
+// Package featureflags evaluates the feature flags returned to the UI and
+// checked by server-side code paths. Flags are defined in a ConfigMap and can
+// be targeted at specific users or groups, so new capabilities can be rolled
+// out gradually.
+package featureflags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/weaveworks/weave-gitops/pkg/server/auth"
+	"sigs.k8s.io/yaml"
+)
+
+// EnabledValue is the value of a flag that is switched on.
+const EnabledValue = "true"
+
+// Flag is a single feature flag. Targets are evaluated in order, the first
+// one matching the principal decides the value.
+type Flag struct {
+	// Value applies to everyone not matched by a target.
+	Value   string   `json:"value"`
+	Targets []Target `json:"targets,omitempty"`
+}
+
+// Target sets the value of a flag for some users or members of some groups.
+type Target struct {
+	Users  []string `json:"users,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+	Value  string   `json:"value"`
+}
+
+// Evaluate returns the value of the flag for p, which may be nil for
+// anonymous requests.
+func (f Flag) Evaluate(p *auth.UserPrincipal) string {
+	if p != nil {
+		for _, t := range f.Targets {
+			if t.matches(p) {
+				return t.Value
+			}
+		}
+	}
+
+	return f.Value
+}
+
+func (t Target) matches(p *auth.UserPrincipal) bool {
+	for _, u := range t.Users {
+		if u == p.ID {
+			return true
+		}
+	}
+
+	for _, g := range t.Groups {
+		for _, pg := range p.Groups {
+			if g == pg {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Set is a collection of flags by name.
+type Set map[string]Flag
+
+// Parse reads flags from the data of a ConfigMap. Each key is a flag name and
+// its value either a plain string that applies to everyone, or a YAML object
+// with a default value and targets:
+//
+//	WEAVE_GITOPS_FEATURE_TENANCY: |
+//	  value: "false"
+//	  targets:
+//	    - groups: ["weaveworks"]
+//	      value: "true"
+func Parse(data map[string]string) (Set, error) {
+	flags := Set{}
+
+	for name, raw := range data {
+		var probe interface{}
+		if err := yaml.Unmarshal([]byte(raw), &probe); err != nil {
+			return nil, fmt.Errorf("invalid feature flag %s: %w", name, err)
+		}
+
+		if _, ok := probe.(map[string]interface{}); !ok {
+			flags[name] = Flag{Value: strings.TrimSpace(raw)}
+			continue
+		}
+
+		var f Flag
+		if err := yaml.UnmarshalStrict([]byte(raw), &f); err != nil {
+			return nil, fmt.Errorf("invalid feature flag %s: %w", name, err)
+		}
+
+		flags[name] = f
+	}
+
+	return flags, nil
+}
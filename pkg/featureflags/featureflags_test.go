@@ -0,0 +1,118 @@
+package featureflags_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/featureflags"
+	"github.com/weaveworks/weave-gitops/pkg/server/auth"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const tenancyFlag = `
+value: "false"
+targets:
+  - users: ["alice@example.com"]
+    value: "true"
+  - groups: ["weaveworks"]
+    value: "beta"
+`
+
+func TestParse(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	flags, err := featureflags.Parse(map[string]string{
+		"WEAVE_GITOPS_FEATURE_CLUSTER": "true",
+		"WEAVE_GITOPS_FEATURE_TENANCY": tenancyFlag,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(flags["WEAVE_GITOPS_FEATURE_CLUSTER"]).To(Equal(featureflags.Flag{Value: "true"}))
+	g.Expect(flags["WEAVE_GITOPS_FEATURE_TENANCY"].Targets).To(HaveLen(2))
+
+	_, err = featureflags.Parse(map[string]string{"WEAVE_GITOPS_FEATURE_TENANCY": "value: true\ntargts: []\n"})
+	g.Expect(err).To(MatchError(ContainSubstring("invalid feature flag WEAVE_GITOPS_FEATURE_TENANCY")))
+}
+
+func TestEvaluate(t *testing.T) {
+	flags, err := featureflags.Parse(map[string]string{"TENANCY": tenancyFlag})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		principal *auth.UserPrincipal
+		want      string
+	}{
+		{"anonymous", nil, "false"},
+		{"other user", &auth.UserPrincipal{ID: "bob@example.com", Groups: []string{"devs"}}, "false"},
+		{"targeted user", &auth.UserPrincipal{ID: "alice@example.com", Groups: []string{"weaveworks"}}, "true"},
+		{"targeted group", &auth.UserPrincipal{ID: "carol@example.com", Groups: []string{"devs", "weaveworks"}}, "beta"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(flags["TENANCY"].Evaluate(tt.principal)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestStore(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	store := featureflags.NewStore()
+	store.SetDefaults(map[string]string{"CLUSTER": "true", "TENANCY": "true"})
+
+	flags, err := featureflags.Parse(map[string]string{"TENANCY": tenancyFlag})
+	g.Expect(err).NotTo(HaveOccurred())
+	store.SetFlags(flags)
+
+	alice := &auth.UserPrincipal{ID: "alice@example.com"}
+
+	g.Expect(store.Flags(nil)).To(Equal(map[string]string{"CLUSTER": "true", "TENANCY": "false"}))
+	g.Expect(store.Flags(alice)).To(Equal(map[string]string{"CLUSTER": "true", "TENANCY": "true"}))
+
+	g.Expect(store.Enabled(context.Background(), "CLUSTER")).To(BeTrue())
+	g.Expect(store.Enabled(context.Background(), "TENANCY")).To(BeFalse())
+	g.Expect(store.Enabled(auth.WithPrincipal(context.Background(), alice), "TENANCY")).To(BeTrue())
+	g.Expect(store.Enabled(context.Background(), "UNKNOWN")).To(BeFalse())
+}
+
+func TestWatchConfigMap(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: featureflags.DefaultConfigMapName, Namespace: "flux-system"},
+		Data:       map[string]string{"CLUSTER": "true"},
+	}
+	cs := fake.NewSimpleClientset(cm)
+	store := featureflags.NewStore()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go featureflags.WatchConfigMap(ctx, logr.Discard(), cs, "flux-system", featureflags.DefaultConfigMapName, store)
+
+	g.Eventually(func() map[string]string { return store.Flags(nil) }, 5*time.Second).Should(HaveKeyWithValue("CLUSTER", "true"))
+
+	cm.Data = map[string]string{"CLUSTER": "false"}
+	_, err := cs.CoreV1().ConfigMaps("flux-system").Update(ctx, cm, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Eventually(func() map[string]string { return store.Flags(nil) }, 5*time.Second).Should(HaveKeyWithValue("CLUSTER", "false"))
+
+	// Invalid data keeps the flags in effect.
+	cm.Data = map[string]string{"CLUSTER": "value: [\n"}
+	_, err = cs.CoreV1().ConfigMaps("flux-system").Update(ctx, cm, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Consistently(func() map[string]string { return store.Flags(nil) }, 300*time.Millisecond).Should(HaveKeyWithValue("CLUSTER", "false"))
+
+	g.Expect(cs.CoreV1().ConfigMaps("flux-system").Delete(ctx, cm.Name, metav1.DeleteOptions{})).To(Succeed())
+	g.Eventually(func() map[string]string { return store.Flags(nil) }, 5*time.Second).Should(BeEmpty())
+}
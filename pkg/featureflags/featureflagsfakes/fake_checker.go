@@ -0,0 +1,189 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package featureflagsfakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/weaveworks/weave-gitops/pkg/featureflags"
+	"github.com/weaveworks/weave-gitops/pkg/server/auth"
+)
+
+type FakeChecker struct {
+	EnabledStub        func(context.Context, string) bool
+	enabledMutex       sync.RWMutex
+	enabledArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	enabledReturns struct {
+		result1 bool
+	}
+	enabledReturnsOnCall map[int]struct {
+		result1 bool
+	}
+	FlagsStub        func(*auth.UserPrincipal) map[string]string
+	flagsMutex       sync.RWMutex
+	flagsArgsForCall []struct {
+		arg1 *auth.UserPrincipal
+	}
+	flagsReturns struct {
+		result1 map[string]string
+	}
+	flagsReturnsOnCall map[int]struct {
+		result1 map[string]string
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeChecker) Enabled(arg1 context.Context, arg2 string) bool {
+	fake.enabledMutex.Lock()
+	ret, specificReturn := fake.enabledReturnsOnCall[len(fake.enabledArgsForCall)]
+	fake.enabledArgsForCall = append(fake.enabledArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.EnabledStub
+	fakeReturns := fake.enabledReturns
+	fake.recordInvocation("Enabled", []interface{}{arg1, arg2})
+	fake.enabledMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeChecker) EnabledCallCount() int {
+	fake.enabledMutex.RLock()
+	defer fake.enabledMutex.RUnlock()
+	return len(fake.enabledArgsForCall)
+}
+
+func (fake *FakeChecker) EnabledCalls(stub func(context.Context, string) bool) {
+	fake.enabledMutex.Lock()
+	defer fake.enabledMutex.Unlock()
+	fake.EnabledStub = stub
+}
+
+func (fake *FakeChecker) EnabledArgsForCall(i int) (context.Context, string) {
+	fake.enabledMutex.RLock()
+	defer fake.enabledMutex.RUnlock()
+	argsForCall := fake.enabledArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeChecker) EnabledReturns(result1 bool) {
+	fake.enabledMutex.Lock()
+	defer fake.enabledMutex.Unlock()
+	fake.EnabledStub = nil
+	fake.enabledReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeChecker) EnabledReturnsOnCall(i int, result1 bool) {
+	fake.enabledMutex.Lock()
+	defer fake.enabledMutex.Unlock()
+	fake.EnabledStub = nil
+	if fake.enabledReturnsOnCall == nil {
+		fake.enabledReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.enabledReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeChecker) Flags(arg1 *auth.UserPrincipal) map[string]string {
+	fake.flagsMutex.Lock()
+	ret, specificReturn := fake.flagsReturnsOnCall[len(fake.flagsArgsForCall)]
+	fake.flagsArgsForCall = append(fake.flagsArgsForCall, struct {
+		arg1 *auth.UserPrincipal
+	}{arg1})
+	stub := fake.FlagsStub
+	fakeReturns := fake.flagsReturns
+	fake.recordInvocation("Flags", []interface{}{arg1})
+	fake.flagsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeChecker) FlagsCallCount() int {
+	fake.flagsMutex.RLock()
+	defer fake.flagsMutex.RUnlock()
+	return len(fake.flagsArgsForCall)
+}
+
+func (fake *FakeChecker) FlagsCalls(stub func(*auth.UserPrincipal) map[string]string) {
+	fake.flagsMutex.Lock()
+	defer fake.flagsMutex.Unlock()
+	fake.FlagsStub = stub
+}
+
+func (fake *FakeChecker) FlagsArgsForCall(i int) *auth.UserPrincipal {
+	fake.flagsMutex.RLock()
+	defer fake.flagsMutex.RUnlock()
+	argsForCall := fake.flagsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeChecker) FlagsReturns(result1 map[string]string) {
+	fake.flagsMutex.Lock()
+	defer fake.flagsMutex.Unlock()
+	fake.FlagsStub = nil
+	fake.flagsReturns = struct {
+		result1 map[string]string
+	}{result1}
+}
+
+func (fake *FakeChecker) FlagsReturnsOnCall(i int, result1 map[string]string) {
+	fake.flagsMutex.Lock()
+	defer fake.flagsMutex.Unlock()
+	fake.FlagsStub = nil
+	if fake.flagsReturnsOnCall == nil {
+		fake.flagsReturnsOnCall = make(map[int]struct {
+			result1 map[string]string
+		})
+	}
+	fake.flagsReturnsOnCall[i] = struct {
+		result1 map[string]string
+	}{result1}
+}
+
+func (fake *FakeChecker) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.enabledMutex.RLock()
+	defer fake.enabledMutex.RUnlock()
+	fake.flagsMutex.RLock()
+	defer fake.flagsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeChecker) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ featureflags.Checker = new(FakeChecker)
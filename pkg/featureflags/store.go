@@ -0,0 +1,78 @@
+package featureflags
+
+import (
+	"context"
+	"sync"
+
+	"github.com/weaveworks/weave-gitops/pkg/server/auth"
+)
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+// Checker gives access to the flags in effect for a principal.
+//counterfeiter:generate . Checker
+type Checker interface {
+	// Flags returns the value of every flag for p, which may be nil.
+	Flags(p *auth.UserPrincipal) map[string]string
+	// Enabled reports whether a flag is switched on for the principal of ctx.
+	Enabled(ctx context.Context, name string) bool
+}
+
+// Store holds the flags in effect. Flags loaded from a ConfigMap take
+// precedence over the defaults, e.g. those of the server configuration file.
+type Store struct {
+	mu       sync.RWMutex
+	defaults map[string]string
+	flags    Set
+}
+
+// NewStore creates an empty Store.
+func NewStore() *Store {
+	return &Store{}
+}
+
+// SetDefaults replaces the flags that apply to everyone unless overridden.
+func (s *Store) SetDefaults(defaults map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.defaults = defaults
+}
+
+// SetFlags replaces the targeted flags.
+func (s *Store) SetFlags(flags Set) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.flags = flags
+}
+
+func (s *Store) Flags(p *auth.UserPrincipal) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make(map[string]string, len(s.defaults)+len(s.flags))
+
+	for name, value := range s.defaults {
+		result[name] = value
+	}
+
+	for name, f := range s.flags {
+		result[name] = f.Evaluate(p)
+	}
+
+	return result
+}
+
+func (s *Store) Enabled(ctx context.Context, name string) bool {
+	p := auth.Principal(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if f, ok := s.flags[name]; ok {
+		return f.Evaluate(p) == EnabledValue
+	}
+
+	return s.defaults[name] == EnabledValue
+}
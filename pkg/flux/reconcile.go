@@ -0,0 +1,146 @@
+package flux
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	kustomizev2 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	"github.com/fluxcd/pkg/apis/meta"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultSourceBranch is the branch checked out by GitRepositories without a branch.
+const defaultSourceBranch = "master"
+
+// Reconciliation is a Kustomization that applied a revision of a repository.
+type Reconciliation struct {
+	Kustomization types.NamespacedName
+	// Revision is the revision of the source, <branch>/<sha> for GitRepositories.
+	Revision string
+}
+
+// WaitForReconciliation polls the cluster until the GitRepositories tracking a branch of a repository fetched
+// a commit of the branch and every Kustomization of these sources applied it. It fails when a Kustomization
+// failed to apply the commit or the cluster has no source for the branch.
+func WaitForReconciliation(ctx context.Context, c client.Client, repoUrl gitproviders.RepoURL, branch, sha string, interval time.Duration) ([]Reconciliation, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		reconciliations, done, err := reconciled(ctx, c, repoUrl, branch, sha)
+		if err != nil || done {
+			return reconciliations, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("commit %s of %s still not applied: %w", sha, repoUrl, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// reconciled returns the Kustomizations that applied the commit, and whether all of them did.
+func reconciled(ctx context.Context, c client.Client, repoUrl gitproviders.RepoURL, branch, sha string) ([]Reconciliation, bool, error) {
+	sources := &sourcev1.GitRepositoryList{}
+	if err := c.List(ctx, sources); err != nil {
+		return nil, false, fmt.Errorf("failed to list GitRepositories: %w", err)
+	}
+
+	// the revisions of the sources of the branch by namespaced name
+	revisions := map[types.NamespacedName]string{}
+
+	for _, source := range sources.Items {
+		// sources of other hosts can't be parsed without their type, they aren't the repository anyway
+		sourceUrl, err := gitproviders.NewRepoURL(source.Spec.URL)
+		if err != nil || !sourceUrl.Equal(repoUrl) {
+			continue
+		}
+
+		// sources of other branches or pinned to a tag or commit never fetch the commit
+		if !tracksBranch(source, branch) {
+			continue
+		}
+
+		revision := ""
+		if source.Status.Artifact != nil {
+			revision = source.Status.Artifact.Revision
+		}
+
+		revisions[types.NamespacedName{Namespace: source.Namespace, Name: source.Name}] = revision
+	}
+
+	if len(revisions) == 0 {
+		return nil, false, fmt.Errorf("no GitRepository of branch %s of %s found on the cluster", branch, repoUrl)
+	}
+
+	for _, revision := range revisions {
+		if !strings.HasSuffix(revision, sha) {
+			return nil, false, nil
+		}
+	}
+
+	kustomizations := &kustomizev2.KustomizationList{}
+	if err := c.List(ctx, kustomizations); err != nil {
+		return nil, false, fmt.Errorf("failed to list Kustomizations: %w", err)
+	}
+
+	reconciliations := []Reconciliation{}
+	done := true
+
+	for _, kustomization := range kustomizations.Items {
+		ref := kustomization.Spec.SourceRef
+		if ref.Kind != sourcev1.GitRepositoryKind {
+			continue
+		}
+
+		source := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+		if source.Namespace == "" {
+			source.Namespace = kustomization.Namespace
+		}
+
+		revision, ok := revisions[source]
+		if !ok {
+			continue
+		}
+
+		name := types.NamespacedName{Namespace: kustomization.Namespace, Name: kustomization.Name}
+		ready := apimeta.FindStatusCondition(kustomization.Status.Conditions, meta.ReadyCondition)
+
+		switch {
+		case kustomization.Status.LastAppliedRevision == revision && ready != nil && ready.Status == metav1.ConditionTrue:
+			reconciliations = append(reconciliations, Reconciliation{Kustomization: name, Revision: revision})
+		case kustomization.Status.LastAttemptedRevision == revision && ready != nil && ready.Status == metav1.ConditionFalse:
+			return nil, false, fmt.Errorf("Kustomization %s failed to apply revision %s: %s", name, revision, ready.Message)
+		default:
+			done = false
+		}
+	}
+
+	return reconciliations, done, nil
+}
+
+// tracksBranch tells whether a GitRepository follows the head of a branch.
+func tracksBranch(source sourcev1.GitRepository, branch string) bool {
+	ref := source.Spec.Reference
+	if ref == nil {
+		return branch == defaultSourceBranch
+	}
+
+	if ref.Commit != "" || ref.SemVer != "" || ref.Tag != "" {
+		return false
+	}
+
+	if ref.Branch == "" {
+		return branch == defaultSourceBranch
+	}
+
+	return ref.Branch == branch
+}
@@ -0,0 +1,139 @@
+package flux_test
+
+import (
+	"context"
+	"time"
+
+	kustomizev2 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	"github.com/fluxcd/pkg/apis/meta"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/flux"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("WaitForReconciliation", func() {
+	const sha = "4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f70"
+
+	var (
+		ctx     context.Context
+		repoUrl gitproviders.RepoURL
+		source  *sourcev1.GitRepository
+		apps    *kustomizev2.Kustomization
+	)
+
+	newKustomization := func(name, revision string, status metav1.ConditionStatus, message string) *kustomizev2.Kustomization {
+		return &kustomizev2.Kustomization{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "flux-system"},
+			Spec: kustomizev2.KustomizationSpec{
+				SourceRef: kustomizev2.CrossNamespaceSourceReference{Kind: sourcev1.GitRepositoryKind, Name: "config-repo"},
+			},
+			Status: kustomizev2.KustomizationStatus{
+				LastAppliedRevision:   revision,
+				LastAttemptedRevision: revision,
+				Conditions:            []metav1.Condition{{Type: meta.ReadyCondition, Status: status, Message: message}},
+			},
+		}
+	}
+
+	wait := func(objects ...client.Object) ([]flux.Reconciliation, error) {
+		c := fake.NewClientBuilder().WithScheme(kube.CreateScheme()).WithObjects(objects...).Build()
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		return flux.WaitForReconciliation(ctx, c, repoUrl, "main", sha, time.Millisecond)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		repoUrl, err = gitproviders.NewRepoURL("https://github.com/owner/config-repo")
+		Expect(err).NotTo(HaveOccurred())
+
+		source = &sourcev1.GitRepository{
+			ObjectMeta: metav1.ObjectMeta{Name: "config-repo", Namespace: "flux-system"},
+			Spec: sourcev1.GitRepositorySpec{
+				URL:       "ssh://git@github.com/owner/config-repo.git",
+				Reference: &sourcev1.GitRepositoryRef{Branch: "main"},
+			},
+			Status: sourcev1.GitRepositoryStatus{Artifact: &sourcev1.Artifact{Revision: "main/" + sha}},
+		}
+
+		apps = newKustomization("apps", "main/"+sha, metav1.ConditionTrue, "Applied revision: main/"+sha)
+	})
+
+	It("returns the Kustomizations that applied the commit", func() {
+		other := newKustomization("other", "main/1234567", metav1.ConditionTrue, "")
+		other.Spec.SourceRef.Name = "other-repo"
+
+		reconciliations, err := wait(source, apps, other)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciliations).To(Equal([]flux.Reconciliation{{
+			Kustomization: types.NamespacedName{Namespace: "flux-system", Name: "apps"},
+			Revision:      "main/" + sha,
+		}}))
+	})
+
+	It("ignores the sources of other branches and pinned sources", func() {
+		staging := source.DeepCopy()
+		staging.Name = "staging"
+		staging.Spec.Reference = &sourcev1.GitRepositoryRef{Branch: "staging"}
+		staging.Status.Artifact.Revision = "staging/1234567"
+
+		pinned := source.DeepCopy()
+		pinned.Name = "pinned"
+		pinned.Spec.Reference = &sourcev1.GitRepositoryRef{Branch: "main", Tag: "v1.0.0"}
+		pinned.Status.Artifact.Revision = "v1.0.0/1234567"
+
+		defaultBranch := source.DeepCopy()
+		defaultBranch.Name = "default-branch"
+		defaultBranch.Spec.Reference = nil
+		defaultBranch.Status.Artifact.Revision = "master/1234567"
+
+		reconciliations, err := wait(source, apps, staging, pinned, defaultBranch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciliations).To(HaveLen(1))
+	})
+
+	It("waits for the source to fetch the commit", func() {
+		source.Status.Artifact.Revision = "main/1234567"
+
+		_, err := wait(source, apps)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+
+	It("waits for every Kustomization of the source to apply the commit", func() {
+		_, err := wait(source, apps, newKustomization("infra", "main/1234567", metav1.ConditionTrue, ""))
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+
+	It("fails when a Kustomization failed to apply the commit", func() {
+		failed := newKustomization("infra", "main/1234567", metav1.ConditionFalse, "dry-run failed")
+		failed.Status.LastAttemptedRevision = "main/" + sha
+
+		_, err := wait(source, apps, failed)
+		Expect(err).To(MatchError(ContainSubstring("Kustomization flux-system/infra failed to apply revision main/" + sha + ": dry-run failed")))
+	})
+
+	It("fails without a source for the repository", func() {
+		source.Spec.URL = "ssh://git@github.com/owner/other-repo.git"
+
+		_, err := wait(source, apps)
+		Expect(err).To(MatchError("no GitRepository of branch main of https://github.com/owner/config-repo.git found on the cluster"))
+	})
+
+	It("fails without a source for the branch", func() {
+		source.Spec.Reference.Branch = "staging"
+
+		_, err := wait(source, apps)
+		Expect(err).To(MatchError("no GitRepository of branch main of https://github.com/owner/config-repo.git found on the cluster"))
+	})
+})
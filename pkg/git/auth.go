@@ -0,0 +1,16 @@
+package git
+
+import (
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// NewHTTPSAuth returns the credentials of HTTPS remotes for a token: the basic auth of the username
+// and token, or the token as bearer when there is no username.
+func NewHTTPSAuth(username, token string) transport.AuthMethod {
+	if username == "" {
+		return &http.TokenAuth{Token: token}
+	}
+
+	return &http.BasicAuth{Username: username, Password: token}
+}
@@ -0,0 +1,18 @@
+package git_test
+
+import (
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+)
+
+var _ = Describe("NewHTTPSAuth", func() {
+	It("sends the token as password of the username", func() {
+		Expect(git.NewHTTPSAuth("oauth2", "token")).To(Equal(&http.BasicAuth{Username: "oauth2", Password: "token"}))
+	})
+
+	It("sends the token as bearer without a username", func() {
+		Expect(git.NewHTTPSAuth("", "token")).To(Equal(&http.TokenAuth{Token: "token"}))
+	})
+})
@@ -0,0 +1,333 @@
+package git
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/gofrs/flock"
+)
+
+// cacheLockTimeout is how long a clone waits for other processes cloning the same repository.
+const cacheLockTimeout = 5 * time.Minute
+
+// cloneFromCache fetches the branch into the bare repository cached for the URL, then initializes a
+// repository at path reading the objects of the cache through its alternates, so that nothing is copied.
+// The cached repository is locked meanwhile so that concurrent clones don't fetch into it at the same time.
+func (g *GoGit) cloneFromCache(ctx context.Context, path, url, branch string) (*gogit.Repository, error) {
+	if err := os.MkdirAll(g.cacheDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create git cache dir: %w", err)
+	}
+
+	cachePath := filepath.Join(g.cacheDir, cacheKey(url))
+	lock := flock.New(cachePath + ".lock")
+
+	lockCtx, cancel := context.WithTimeout(ctx, cacheLockTimeout)
+	defer cancel()
+
+	if ok, err := lock.TryLockContext(lockCtx, 250*time.Millisecond); !ok {
+		return nil, fmt.Errorf("unable to lock the git cache of %s: %w", url, err)
+	}
+
+	defer func() {
+		_ = lock.Unlock()
+	}()
+
+	hash, err := g.fetchToCache(ctx, cachePath, url, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := g.git.PlainInit(path, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := shareObjects(path, cachePath); err != nil {
+		return nil, fmt.Errorf("failed to share the git cache of %s: %w", url, err)
+	}
+
+	if _, err := r.CreateRemote(&config.RemoteConfig{
+		Name: gogit.DefaultRemoteName,
+		URLs: []string{url},
+	}); err != nil {
+		return nil, err
+	}
+
+	cfg, err := r.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	branchRef := plumbing.NewBranchReferenceName(branch)
+
+	cfg.Branches[branch] = &config.Branch{
+		Name:   branch,
+		Remote: gogit.DefaultRemoteName,
+		Merge:  branchRef,
+	}
+
+	if err := r.Storer.SetConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	remoteRef := plumbing.NewRemoteReferenceName(gogit.DefaultRemoteName, branch)
+	if err := r.Storer.SetReference(plumbing.NewHashReference(remoteRef, hash)); err != nil {
+		return nil, err
+	}
+
+	// only the cloned branch is a local branch, so that pushes don't push the others fetched in the cache
+	if err := r.Storer.SetReference(plumbing.NewHashReference(branchRef, hash)); err != nil {
+		return nil, err
+	}
+
+	if err := r.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branchRef)); err != nil {
+		return nil, err
+	}
+
+	if len(g.sparsePaths) > 0 {
+		return r, nil
+	}
+
+	wt, err := r.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := wt.Reset(&gogit.ResetOptions{Commit: hash, Mode: gogit.HardReset}); err != nil {
+		return nil, fmt.Errorf("failed to check out %s: %w", branch, err)
+	}
+
+	return r, nil
+}
+
+// fetchToCache fetches the branch in the bare repository at cachePath, cloning it when it doesn't
+// exist yet, and returns the commit at its head. The whole history is fetched whatever the depth of
+// the client, as go-git fails to fetch into shallow repositories.
+func (g *GoGit) fetchToCache(ctx context.Context, cachePath, url, branch string) (plumbing.Hash, error) {
+	created := false
+
+	r, err := g.git.PlainOpen(cachePath)
+	if errors.Is(err, gogit.ErrRepositoryNotExists) {
+		if r, err = g.initCache(cachePath, url); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to create the git cache of %s: %w", url, err)
+		}
+
+		created = true
+	} else if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to open the git cache of %s: %w", url, err)
+	}
+
+	remoteRef := plumbing.NewRemoteReferenceName(gogit.DefaultRemoteName, branch)
+
+	err = r.FetchContext(ctx, &gogit.FetchOptions{
+		RemoteName: gogit.DefaultRemoteName,
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branch), remoteRef))},
+		Auth:       g.auth,
+		Tags:       gogit.NoTags,
+	})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		// an empty cache would make the next clone a full one
+		if created {
+			_ = os.RemoveAll(cachePath)
+		}
+
+		return plumbing.ZeroHash, err
+	}
+
+	ref, err := r.Reference(remoteRef, true)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read %s in the git cache of %s: %w", remoteRef, url, err)
+	}
+
+	return ref.Hash(), nil
+}
+
+func (g *GoGit) initCache(cachePath, url string) (*gogit.Repository, error) {
+	r, err := g.git.PlainInit(cachePath, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.CreateRemote(&config.RemoteConfig{
+		Name: gogit.DefaultRemoteName,
+		URLs: []string{url},
+	}); err != nil {
+		_ = os.RemoveAll(cachePath)
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// checkoutSparse resets the index to the commit and checks out the files under the sparse paths only.
+func (g *GoGit) checkoutSparse(hash plumbing.Hash) error {
+	wt, err := g.repository.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open the worktree: %w", err)
+	}
+
+	if err := wt.Reset(&gogit.ResetOptions{Commit: hash, Mode: gogit.MixedReset}); err != nil {
+		return err
+	}
+
+	// files removed since the previous checkout must not be left behind
+	for _, p := range g.sparsePaths {
+		if err := os.RemoveAll(filepath.Join(g.path, p)); err != nil {
+			return err
+		}
+	}
+
+	commit, err := g.repository.CommitObject(hash)
+	if err != nil {
+		return err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+
+	return tree.Files().ForEach(func(f *object.File) error {
+		if !g.inSparsePaths(f.Name) {
+			return nil
+		}
+
+		return checkoutFile(filepath.Join(g.path, filepath.FromSlash(f.Name)), f)
+	})
+}
+
+// checkoutSparseBranch switches to a branch, creating it at HEAD when it doesn't exist, without
+// checking out the files out of the sparse paths.
+func (g *GoGit) checkoutSparseBranch(branch string) error {
+	branchRef := plumbing.NewBranchReferenceName(branch)
+
+	ref, err := g.repository.Reference(branchRef, true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		head, err := g.repository.Head()
+		if err != nil {
+			return err
+		}
+
+		// the new branch is at the same commit, the worktree stays as it is
+		if err := g.repository.Storer.SetReference(plumbing.NewHashReference(branchRef, head.Hash())); err != nil {
+			return err
+		}
+
+		return g.repository.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branchRef))
+	} else if err != nil {
+		return err
+	}
+
+	if err := g.repository.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branchRef)); err != nil {
+		return err
+	}
+
+	return g.checkoutSparse(ref.Hash())
+}
+
+// inSparsePaths returns whether a file of the repository is checked out.
+func (g *GoGit) inSparsePaths(file string) bool {
+	if len(g.sparsePaths) == 0 {
+		return true
+	}
+
+	for _, p := range g.sparsePaths {
+		if file == p || strings.HasPrefix(file, p+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkoutFile writes a file of a commit at target.
+func checkoutFile(target string, f *object.File) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	if f.Mode == filemode.Symlink {
+		link, err := f.Contents()
+		if err != nil {
+			return err
+		}
+
+		return os.Symlink(link, target)
+	}
+
+	mode, err := f.Mode.ToOSFileMode()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	r, err := f.Reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	_, err = io.Copy(out, r)
+
+	return err
+}
+
+// cleanPaths returns the paths relative to the root of the repository, with slashes.
+func cleanPaths(paths []string) []string {
+	cleaned := []string{}
+
+	for _, p := range paths {
+		p = path.Clean(filepath.ToSlash(p))
+		p = strings.TrimPrefix(p, "/")
+
+		if p == "." || p == "" {
+			// the whole repository
+			return nil
+		}
+
+		cleaned = append(cleaned, p)
+	}
+
+	return cleaned
+}
+
+// cacheKey returns the name of the cached repository of a URL.
+func cacheKey(url string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(url)))[:32]
+}
+
+// shareObjects makes the repository at path read the objects missing from its object database in the
+// one of the bare repository at cachePath. The path is absolute as relative alternates are resolved
+// from the objects dir of the repository.
+func shareObjects(path, cachePath string) error {
+	objects, err := filepath.Abs(filepath.Join(cachePath, "objects"))
+	if err != nil {
+		return err
+	}
+
+	info := filepath.Join(path, gogit.GitDirName, "objects", "info")
+	if err := os.MkdirAll(info, 0700); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(info, "alternates"), []byte(objects+"\n"), 0600)
+}
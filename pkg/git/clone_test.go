@@ -0,0 +1,133 @@
+package git_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/git/wrapper"
+)
+
+var _ = Describe("Clone options", func() {
+	var (
+		ctx       context.Context
+		remoteDir string
+		workDir   string
+		remoteUrl string
+	)
+
+	runGit := func(workingDir string, args ...string) string {
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@test.com"}, args...)
+		return strings.TrimSpace(string(executeCommand(workingDir, "git", args...)))
+	}
+
+	pushFile := func(path, content string) {
+		Expect(os.MkdirAll(filepath.Join(workDir, filepath.Dir(path)), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(workDir, path), []byte(content), 0644)).To(Succeed())
+		runGit(workDir, "add", path)
+		runGit(workDir, "commit", "-m", "update "+path)
+		runGit(workDir, "push", "origin", "main")
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		remoteDir = filepath.Join(dir, "remote.git")
+		workDir = filepath.Join(dir, "work")
+		remoteUrl = "file://" + remoteDir
+
+		runGit(dir, "init", "--bare", remoteDir)
+		runGit(dir, "init", workDir)
+		runGit(workDir, "checkout", "-b", "main")
+		runGit(workDir, "remote", "add", "origin", remoteUrl)
+
+		pushFile("clusters/prod/profiles.yaml", "podinfo: 6.0.0\n")
+		pushFile("clusters/dev/profiles.yaml", "podinfo: 6.1.0\n")
+	})
+
+	clone := func(path string, opts ...git.Option) git.Git {
+		client := git.New(nil, wrapper.NewGoGit(), opts...)
+
+		_, err := client.Clone(ctx, path, remoteUrl, "main")
+		Expect(err).NotTo(HaveOccurred())
+
+		return client
+	}
+
+	It("clones the last commits only", func() {
+		path := filepath.Join(dir, "clone")
+		clone(path, git.WithDepth(1))
+
+		Expect(runGit(path, "rev-list", "--count", "HEAD")).To(Equal("1"))
+	})
+
+	It("fetches the new commits into the cache", func() {
+		cacheDir := filepath.Join(dir, "cache")
+
+		clone(filepath.Join(dir, "first"), git.WithCache(cacheDir))
+
+		pushFile("clusters/prod/profiles.yaml", "podinfo: 6.2.0\n")
+
+		path := filepath.Join(dir, "second")
+		client := clone(path, git.WithCache(cacheDir))
+
+		content, err := client.Read("clusters/prod/profiles.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(Equal("podinfo: 6.2.0\n"))
+		Expect(runGit(path, "rev-list", "--count", "HEAD")).To(Equal("3"))
+
+		entries, err := ioutil.ReadDir(cacheDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(2), "a repository and its lock file")
+
+		packs, err := ioutil.ReadDir(filepath.Join(path, ".git", "objects", "pack"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(packs).To(BeEmpty(), "the objects are read from the cache")
+
+		Expect(client.Checkout("update-prod")).To(Succeed())
+		Expect(client.Write("clusters/prod/profiles.yaml", []byte("podinfo: 6.3.0\n"))).To(Succeed())
+		_, err = client.Commit(git.Commit{Message: "update prod"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Push(ctx)).To(Succeed())
+
+		Expect(runGit(remoteDir, "show", "update-prod:clusters/prod/profiles.yaml")).To(Equal("podinfo: 6.3.0"))
+	})
+
+	It("initializes the repository when the branch doesn't exist", func() {
+		client := git.New(nil, wrapper.NewGoGit(), git.WithCache(filepath.Join(dir, "cache")))
+
+		_, err := client.Clone(ctx, filepath.Join(dir, "clone"), remoteUrl, "new-branch")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("checks out and commits the sparse paths only", func() {
+		path := filepath.Join(dir, "clone")
+		client := git.New(nil, wrapper.NewGoGit())
+
+		_, err := client.Clone(ctx, path, remoteUrl, "main", "clusters/prod")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = os.Stat(filepath.Join(path, "clusters/prod/profiles.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = os.Stat(filepath.Join(path, "clusters/dev"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+
+		clean, err := client.Status()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clean).To(BeTrue())
+
+		Expect(client.Checkout("update-prod")).To(Succeed())
+		Expect(client.Write("clusters/prod/profiles.yaml", []byte("podinfo: 6.3.0\n"))).To(Succeed())
+		_, err = client.Commit(git.Commit{Message: "update prod"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Push(ctx)).To(Succeed())
+
+		Expect(runGit(remoteDir, "show", "update-prod:clusters/prod/profiles.yaml")).To(Equal("podinfo: 6.3.0"))
+		Expect(runGit(remoteDir, "show", "update-prod:clusters/dev/profiles.yaml")).To(Equal("podinfo: 6.1.0"))
+	})
+})
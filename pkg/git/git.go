@@ -36,10 +36,15 @@ type Author struct {
 	Email string
 }
 
+// DefaultAuthor authors the commits of clients configured with no other author.
+var DefaultAuthor = Author{Name: "Weave Gitops", Email: "weave-gitops@weave.works"}
+
 type Commit struct {
 	Author
-	Hash    string
-	Message string
+	// Committer defaults to the committer of the client, then to the author.
+	Committer Author
+	Hash      string
+	Message   string
 }
 
 // WegoRoot is the default root directory for the GitOps repo
@@ -86,7 +91,7 @@ func GetProfilesPath(clusterName, profilesManifestPath string) string {
 type Git interface {
 	Open(path string) (*gogit.Repository, error)
 	Init(path, url, branch string) (bool, error)
-	Clone(ctx context.Context, path, url, branch string) (bool, error)
+	Clone(ctx context.Context, path, url, branch string, sparsePaths ...string) (bool, error)
 	Checkout(newBranch string) error
 	Read(path string) ([]byte, error)
 	Write(path string, content []byte) error
@@ -25,6 +25,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/weaveworks/weave-gitops/pkg/git/wrapper"
@@ -41,18 +42,74 @@ type GoGit struct {
 	auth       transport.AuthMethod
 	repository *gogit.Repository
 	git        wrapper.Git
+	author     Author
+	committer  Author
+	signer     Signer
+	depth      int
+	cacheDir   string
+	// sparsePaths are the only paths checked out and committed by the client, all of them when empty.
+	sparsePaths []string
 }
 
-func New(auth transport.AuthMethod, wrapper wrapper.Git) Git {
-	return &GoGit{
-		auth: auth,
-		git:  wrapper,
+// Option configures the commits of a GoGit client.
+type Option func(*GoGit)
+
+// WithAuthor sets the author of the commits which don't have one, instead of DefaultAuthor.
+func WithAuthor(author Author) Option {
+	return func(g *GoGit) {
+		g.author = author
+	}
+}
+
+// WithCommitter sets the committer of the commits, which is otherwise their author.
+func WithCommitter(committer Author) Option {
+	return func(g *GoGit) {
+		g.committer = committer
+	}
+}
+
+// WithSigner signs the commits with signer.
+func WithSigner(signer Signer) Option {
+	return func(g *GoGit) {
+		g.signer = signer
+	}
+}
+
+// WithDepth clones only the last depth commits of the branches, instead of their whole history.
+func WithDepth(depth int) Option {
+	return func(g *GoGit) {
+		g.depth = depth
 	}
 }
 
+// WithCache keeps a copy of the cloned repositories in dir. Later clones of a repository only
+// fetch the commits pushed since the previous one. The copies have the whole history, the depth
+// of the client doesn't apply to them. The clones read their objects from the copies, which must be
+// kept as long as the clones are used.
+func WithCache(dir string) Option {
+	return func(g *GoGit) {
+		g.cacheDir = dir
+	}
+}
+
+func New(auth transport.AuthMethod, wrapper wrapper.Git, opts ...Option) Git {
+	g := &GoGit{
+		auth:   auth,
+		git:    wrapper,
+		author: DefaultAuthor,
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
 // Open opens a git repository in the provided path, and returns a repository.
 func (g *GoGit) Open(path string) (*gogit.Repository, error) {
 	g.path = path
+	g.sparsePaths = nil
 	repo, err := g.git.PlainOpen(path)
 
 	if err != nil {
@@ -110,17 +167,32 @@ func (g *GoGit) Init(path, url, branch string) (bool, error) {
 }
 
 // Clone clones a starting repository URL to a path, and checks out the provided
-// branch name.
+// branch name. When sparsePaths are given, only the files under them are checked
+// out, and later commits leave the other files as they are.
 //
 // If the directory is successfully initialised, it returns true, otherwise it
 // returns false.
-func (g *GoGit) Clone(ctx context.Context, path, url, branch string) (bool, error) {
+func (g *GoGit) Clone(ctx context.Context, path, url, branch string, sparsePaths ...string) (bool, error) {
 	g.path = path
+	g.sparsePaths = cleanPaths(sparsePaths)
+
+	var (
+		r   *gogit.Repository
+		err error
+	)
+
+	if g.cacheDir != "" {
+		r, err = g.cloneFromCache(ctx, path, url, branch)
+	} else {
+		r, err = g.clone(ctx, path, url, branch, g.depth, len(g.sparsePaths) > 0)
+	}
 
-	r, err := g.clone(ctx, path, url, branch, 0)
 	if err != nil {
 		if errors.Is(err, transport.ErrEmptyRemoteRepository) ||
 			errors.Is(err, gogit.NoMatchingRefSpecError{}) {
+			// there are no files to leave out of a new branch
+			g.sparsePaths = nil
+
 			return g.Init(path, url, branch)
 		}
 
@@ -129,10 +201,21 @@ func (g *GoGit) Clone(ctx context.Context, path, url, branch string) (bool, erro
 
 	g.repository = r
 
+	if len(g.sparsePaths) > 0 {
+		head, err := r.Head()
+		if err != nil {
+			return false, fmt.Errorf("failed getting repository HEAD %w", err)
+		}
+
+		if err := g.checkoutSparse(head.Hash()); err != nil {
+			return false, fmt.Errorf("failed to check out %s: %w", strings.Join(g.sparsePaths, ", "), err)
+		}
+	}
+
 	return true, nil
 }
 
-func (g *GoGit) clone(ctx context.Context, path, url, branch string, depth int) (*gogit.Repository, error) {
+func (g *GoGit) clone(ctx context.Context, path, url, branch string, depth int, noCheckout bool) (*gogit.Repository, error) {
 	branchRef := plumbing.NewBranchReferenceName(branch)
 	r, err := g.git.PlainCloneContext(ctx, path, false, &gogit.CloneOptions{
 		URL:           url,
@@ -140,7 +223,7 @@ func (g *GoGit) clone(ctx context.Context, path, url, branch string, depth int)
 		RemoteName:    gogit.DefaultRemoteName,
 		ReferenceName: branchRef,
 		SingleBranch:  true,
-		NoCheckout:    false,
+		NoCheckout:    noCheckout,
 		Progress:      nil,
 		Depth:         depth,
 		Tags:          gogit.NoTags,
@@ -239,6 +322,11 @@ func (g *GoGit) Commit(message Commit, filters ...func(string) bool) (string, er
 	var changed bool
 
 	for file, stat := range status {
+		// the files out of the sparse paths aren't checked out, they look deleted
+		if !g.inSparsePaths(file) {
+			continue
+		}
+
 		if stat.Worktree == gogit.Deleted {
 			_, _ = wt.Add(file)
 			changed = true
@@ -286,20 +374,95 @@ func (g *GoGit) Commit(message Commit, filters ...func(string) bool) (string, er
 		return head.Hash().String(), ErrNoStagedFiles
 	}
 
+	author := message.Author
+	if author == (Author{}) {
+		author = g.author
+	}
+
+	committer := message.Committer
+	if committer == (Author{}) {
+		committer = g.committer
+	}
+
+	if committer == (Author{}) {
+		committer = author
+	}
+
+	when := time.Now()
+
 	commit, err := wt.Commit(message.Message, &gogit.CommitOptions{
 		Author: &object.Signature{
-			Name:  message.Name,
-			Email: message.Email,
-			When:  time.Now(),
+			Name:  author.Name,
+			Email: author.Email,
+			When:  when,
+		},
+		Committer: &object.Signature{
+			Name:  committer.Name,
+			Email: committer.Email,
+			When:  when,
 		},
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to commit changes: %w", err)
 	}
 
+	if g.signer != nil {
+		if commit, err = g.sign(commit); err != nil {
+			return "", fmt.Errorf("failed to sign commit: %w", err)
+		}
+	}
+
 	return commit.String(), nil
 }
 
+// sign replaces the commit at HEAD with a signed copy. go-git only signs with
+// OpenPGP entities itself, so this lets other signers, e.g. SSH keys, be used.
+func (g *GoGit) sign(hash plumbing.Hash) (plumbing.Hash, error) {
+	commit, err := g.repository.CommitObject(hash)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	unsigned := g.repository.Storer.NewEncodedObject()
+	if err := commit.EncodeWithoutSignature(unsigned); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	r, err := unsigned.Reader()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	defer r.Close()
+
+	signature, err := g.signer.Sign(r)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	commit.PGPSignature = string(signature)
+
+	signed := g.repository.Storer.NewEncodedObject()
+	if err := commit.Encode(signed); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	signedHash, err := g.repository.Storer.SetEncodedObject(signed)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	head, err := g.repository.Head()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	if err := g.repository.Storer.SetReference(plumbing.NewHashReference(head.Name(), signedHash)); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return signedHash, nil
+}
+
 func (g *GoGit) Push(ctx context.Context) error {
 	if g.repository == nil {
 		return ErrNoGitRepository
@@ -328,7 +491,13 @@ func (g *GoGit) Status() (bool, error) {
 		return false, fmt.Errorf("failed to get the worktree status: %w", err)
 	}
 
-	return status.IsClean(), nil
+	for file, stat := range status {
+		if g.inSparsePaths(file) && (stat.Worktree != gogit.Unmodified || stat.Staging != gogit.Unmodified) {
+			return false, nil
+		}
+	}
+
+	return true, nil
 }
 
 func (g *GoGit) Head() (string, error) {
@@ -372,7 +541,7 @@ func (g *GoGit) ValidateAccess(ctx context.Context, url string, branch string) e
 
 	defer os.RemoveAll(path)
 
-	_, err = g.clone(ctx, path, url, branch, 1)
+	_, err = g.clone(ctx, path, url, branch, 1, false)
 	if err != nil && !errors.Is(err, transport.ErrEmptyRemoteRepository) {
 		return fmt.Errorf("error validating git repo access %w", err)
 	}
@@ -381,6 +550,14 @@ func (g *GoGit) ValidateAccess(ctx context.Context, url string, branch string) e
 }
 
 func (g *GoGit) Checkout(newBranch string) error {
+	if len(g.sparsePaths) > 0 {
+		if err := g.checkoutSparseBranch(newBranch); err != nil {
+			return fmt.Errorf("failed checking out branch %w", err)
+		}
+
+		return nil
+	}
+
 	wt, err := g.repository.Worktree()
 	if err != nil {
 		return fmt.Errorf("failed getting repository work-tree %w", err)
@@ -316,6 +316,46 @@ var _ = Describe("Commit", func() {
 		})
 		Expect(err).Should(MatchError("no staged files"))
 	})
+
+	It("commits as the default author of the client", func() {
+		_, err = gitClient.Init(dir, "https://github.com/github/gitignore", "master")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(gitClient.Write("test.txt", []byte("testing"))).To(Succeed())
+
+		_, err = gitClient.Commit(git.Commit{Message: "test commit"})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		out := executeCommand(dir, "git", "log", "-1", "--pretty=%an <%ae>|%cn <%ce>")
+		Expect(string(out)).To(Equal("Weave Gitops <weave-gitops@weave.works>|Weave Gitops <weave-gitops@weave.works>\n"))
+	})
+
+	It("commits with the author and committer configured on the client", func() {
+		client := git.New(nil, wrapper.NewGoGit(),
+			git.WithAuthor(git.Author{Name: "author", Email: "author@example.com"}),
+			git.WithCommitter(git.Author{Name: "committer", Email: "committer@example.com"}))
+
+		_, err = client.Init(dir, "https://github.com/github/gitignore", "master")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(client.Write("test.txt", []byte("testing"))).To(Succeed())
+
+		_, err = client.Commit(git.Commit{Message: "test commit"})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		out := executeCommand(dir, "git", "log", "-1", "--pretty=%an <%ae>|%cn <%ce>")
+		Expect(string(out)).To(Equal("author <author@example.com>|committer <committer@example.com>\n"))
+
+		Expect(client.Write("test.txt", []byte("changed"))).To(Succeed())
+
+		_, err = client.Commit(git.Commit{
+			Author:    git.Author{Name: "test", Email: "test@example.com"},
+			Committer: git.Author{Name: "bot", Email: "bot@example.com"},
+			Message:   "test commit",
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		out = executeCommand(dir, "git", "log", "-1", "--pretty=%an <%ae>|%cn <%ce>")
+		Expect(string(out)).To(Equal("test <test@example.com>|bot <bot@example.com>\n"))
+	})
 })
 
 var _ = Describe("Status", func() {
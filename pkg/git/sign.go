@@ -0,0 +1,169 @@
+package git
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// SigningFormatOpenPGP signs commits with a GPG key.
+	SigningFormatOpenPGP = "openpgp"
+	// SigningFormatSSH signs commits with an SSH key, like git does with gpg.format=ssh.
+	SigningFormatSSH = "ssh"
+)
+
+// Signer signs commits, returning the armored signature of the encoded commit.
+type Signer interface {
+	Sign(message io.Reader) ([]byte, error)
+}
+
+// NewSigner returns the signer of a private key in the given format, openpgp or ssh.
+func NewSigner(format string, privateKey []byte, passphrase string) (Signer, error) {
+	switch format {
+	case SigningFormatOpenPGP, "":
+		return NewOpenPGPSigner(privateKey, passphrase)
+	case SigningFormatSSH:
+		return NewSSHSigner(privateKey, passphrase)
+	default:
+		return nil, fmt.Errorf("unsupported signing format %q, expected %s or %s", format, SigningFormatOpenPGP, SigningFormatSSH)
+	}
+}
+
+type openPGPSigner struct {
+	entity *openpgp.Entity
+}
+
+// NewOpenPGPSigner returns a signer of the first private key of an armored GPG key ring.
+func NewOpenPGPSigner(armoredKeyRing []byte, passphrase string) (Signer, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKeyRing))
+	if err != nil {
+		return nil, fmt.Errorf("could not read GPG key ring: %w", err)
+	}
+
+	for _, entity := range entities {
+		if entity.PrivateKey == nil {
+			continue
+		}
+
+		if entity.PrivateKey.Encrypted {
+			if err := entity.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+				return nil, fmt.Errorf("could not decrypt GPG private key: %w", err)
+			}
+		}
+
+		for _, subkey := range entity.Subkeys {
+			if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+				if err := subkey.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+					return nil, fmt.Errorf("could not decrypt GPG private subkey: %w", err)
+				}
+			}
+		}
+
+		return openPGPSigner{entity: entity}, nil
+	}
+
+	return nil, errors.New("no GPG private key found in key ring")
+}
+
+func (s openPGPSigner) Sign(message io.Reader) ([]byte, error) {
+	var b bytes.Buffer
+
+	if err := openpgp.ArmoredDetachSign(&b, s.entity, message, nil); err != nil {
+		return nil, fmt.Errorf("could not sign with GPG key: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+// sshsigMagic starts the signatures of the SSHSIG format used by git, see
+// https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig
+const sshsigMagic = "SSHSIG"
+
+// sshsigNamespace is the namespace git signs commits in.
+const sshsigNamespace = "git"
+
+type sshsigSignedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+type sshsigBlob struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+type sshSigner struct {
+	signer ssh.Signer
+}
+
+// NewSSHSigner returns a signer of a PEM encoded SSH private key.
+func NewSSHSigner(privateKey []byte, passphrase string) (Signer, error) {
+	var (
+		signer ssh.Signer
+		err    error
+	)
+
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(privateKey, []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(privateKey)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read SSH private key: %w", err)
+	}
+
+	return sshSigner{signer: signer}, nil
+}
+
+func (s sshSigner) Sign(message io.Reader) ([]byte, error) {
+	b, err := ioutil.ReadAll(message)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha512.Sum512(b)
+	signedData := append([]byte(sshsigMagic), ssh.Marshal(sshsigSignedData{
+		Namespace:     sshsigNamespace,
+		HashAlgorithm: "sha512",
+		Hash:          hash[:],
+	})...)
+
+	var sig *ssh.Signature
+
+	// RSA keys sign with SHA-1 by default, which git doesn't accept
+	if signer, ok := s.signer.(ssh.AlgorithmSigner); ok && s.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		sig, err = signer.SignWithAlgorithm(rand.Reader, signedData, ssh.SigAlgoRSASHA2512)
+	} else {
+		sig, err = s.signer.Sign(rand.Reader, signedData)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not sign with SSH key: %w", err)
+	}
+
+	blob := append([]byte(sshsigMagic), ssh.Marshal(sshsigBlob{
+		Version:       1,
+		PublicKey:     s.signer.PublicKey().Marshal(),
+		Namespace:     sshsigNamespace,
+		HashAlgorithm: "sha512",
+		Signature:     ssh.Marshal(sig),
+	})...)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "SSH SIGNATURE", Bytes: blob}), nil
+}
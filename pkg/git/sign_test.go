@@ -0,0 +1,145 @@
+package git_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/git/wrapper"
+)
+
+var _ = Describe("Signing commits", func() {
+	commitWith := func(signer git.Signer) *object.Commit {
+		client := git.New(nil, wrapper.NewGoGit(), git.WithSigner(signer))
+
+		_, err := client.Init(dir, "https://github.com/github/gitignore", "master")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Write("test.txt", []byte("testing"))).To(Succeed())
+
+		hash, err := client.Commit(git.Commit{Message: "signed commit"})
+		Expect(err).NotTo(HaveOccurred())
+
+		repo, err := gogit.PlainOpen(dir)
+		Expect(err).NotTo(HaveOccurred())
+
+		head, err := repo.Head()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(head.Name()).To(Equal(plumbing.NewBranchReferenceName("master")))
+		Expect(head.Hash().String()).To(Equal(hash))
+
+		commit, err := repo.CommitObject(head.Hash())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commit.Message).To(Equal("signed commit"))
+
+		return commit
+	}
+
+	It("signs with GPG keys", func() {
+		entity, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		var privateKey, publicKey bytes.Buffer
+
+		w, err := armor.Encode(&privateKey, openpgp.PrivateKeyType, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entity.SerializePrivate(w, nil)).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+
+		w, err = armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entity.Serialize(w)).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+
+		signer, err := git.NewSigner(git.SigningFormatOpenPGP, privateKey.Bytes(), "")
+		Expect(err).NotTo(HaveOccurred())
+
+		commit := commitWith(signer)
+		Expect(commit.PGPSignature).To(HavePrefix("-----BEGIN PGP SIGNATURE-----"))
+
+		signedBy, err := commit.Verify(publicKey.String())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signedBy.PrimaryKey.KeyId).To(Equal(entity.PrimaryKey.KeyId))
+	})
+
+	It("signs with SSH keys", func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+
+		signer, err := git.NewSigner(git.SigningFormatSSH, pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		}), "")
+		Expect(err).NotTo(HaveOccurred())
+
+		commit := commitWith(signer)
+
+		block, _ := pem.Decode([]byte(commit.PGPSignature))
+		Expect(block).NotTo(BeNil())
+		Expect(block.Type).To(Equal("SSH SIGNATURE"))
+		Expect(string(block.Bytes[:6])).To(Equal("SSHSIG"))
+
+		var blob struct {
+			Version       uint32
+			PublicKey     []byte
+			Namespace     string
+			Reserved      string
+			HashAlgorithm string
+			Signature     []byte
+		}
+		Expect(ssh.Unmarshal(block.Bytes[6:], &blob)).To(Succeed())
+		Expect(blob.Namespace).To(Equal("git"))
+
+		publicKey, err := ssh.NewPublicKey(&key.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blob.PublicKey).To(Equal(publicKey.Marshal()))
+
+		var signature ssh.Signature
+		Expect(ssh.Unmarshal(blob.Signature, &signature)).To(Succeed())
+		Expect(signature.Format).To(Equal(ssh.SigAlgoRSASHA2512))
+
+		unsigned := &plumbing.MemoryObject{}
+		Expect(commit.EncodeWithoutSignature(unsigned)).To(Succeed())
+
+		r, err := unsigned.Reader()
+		Expect(err).NotTo(HaveOccurred())
+
+		b, err := ioutil.ReadAll(r)
+		Expect(err).NotTo(HaveOccurred())
+
+		hash := sha512.Sum512(b)
+		signed := append([]byte("SSHSIG"), ssh.Marshal(struct {
+			Namespace     string
+			Reserved      string
+			HashAlgorithm string
+			Hash          []byte
+		}{"git", "", "sha512", hash[:]})...)
+		Expect(publicKey.Verify(signed, &signature)).To(Succeed())
+	})
+
+	It("rejects unknown signing formats", func() {
+		_, err := git.NewSigner("x509", nil, "")
+		Expect(err).To(MatchError(`unsupported signing format "x509", expected openpgp or ssh`))
+	})
+
+	It("rejects invalid keys", func() {
+		_, err := git.NewSigner(git.SigningFormatOpenPGP, []byte("not a key"), "")
+		Expect(err).To(MatchError(ContainSubstring("could not read GPG key ring")))
+
+		_, err = git.NewSigner(git.SigningFormatSSH, []byte("not a key"), "")
+		Expect(err).To(MatchError(ContainSubstring("could not read SSH private key")))
+	})
+})
@@ -0,0 +1,207 @@
+package gitproviders
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+)
+
+// cachedProvider caches the responses of the read-only calls of a GitProvider. The entries of a repository
+// are dropped when a call changes it through the provider.
+type cachedProvider struct {
+	GitProvider
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+var _ GitProvider = &cachedProvider{}
+
+// NewCachedProvider returns a GitProvider caching the default branches, visibilities, directory listings
+// and authenticated user returned by provider for ttl. Errors aren't cached. go-git-providers clients
+// revalidate the other responses with their ETags already.
+func NewCachedProvider(provider GitProvider, ttl time.Duration) GitProvider {
+	return &cachedProvider{
+		GitProvider: provider,
+		ttl:         ttl,
+		now:         time.Now,
+		entries:     map[string]cacheEntry{},
+	}
+}
+
+// cached returns the value of the key, calling get when it isn't cached or has expired.
+func (p *cachedProvider) cached(key string, get func() (interface{}, error)) (interface{}, error) {
+	p.mu.Lock()
+	entry, ok := p.entries[key]
+	p.mu.Unlock()
+
+	if ok && p.now().Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	value, err := get()
+	if err != nil {
+		return nil, err
+	}
+
+	now := p.now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// the directory listings of older commits are never read again
+	for k, e := range p.entries {
+		if !now.Before(e.expires) {
+			delete(p.entries, k)
+		}
+	}
+
+	p.entries[key] = cacheEntry{value: value, expires: now.Add(p.ttl)}
+
+	return value, nil
+}
+
+// invalidate drops the entries of a repository.
+func (p *cachedProvider) invalidate(repoUrl RepoURL) {
+	prefix := repoKey(repoUrl, "")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key := range p.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(p.entries, key)
+		}
+	}
+}
+
+func repoKey(repoUrl RepoURL, call string, args ...interface{}) string {
+	key := repoUrl.String() + "\n" + call
+
+	for _, arg := range args {
+		key += fmt.Sprintf("\n%v", arg)
+	}
+
+	return key
+}
+
+func (p *cachedProvider) GetDefaultBranch(ctx context.Context, repoUrl RepoURL) (string, error) {
+	value, err := p.cached(repoKey(repoUrl, "default-branch"), func() (interface{}, error) {
+		return p.GitProvider.GetDefaultBranch(ctx, repoUrl)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return value.(string), nil
+}
+
+func (p *cachedProvider) GetRepoVisibility(ctx context.Context, repoUrl RepoURL) (*gitprovider.RepositoryVisibility, error) {
+	value, err := p.cached(repoKey(repoUrl, "visibility"), func() (interface{}, error) {
+		return p.GitProvider.GetRepoVisibility(ctx, repoUrl)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	visibility, ok := value.(*gitprovider.RepositoryVisibility)
+	if !ok || visibility == nil {
+		return nil, nil
+	}
+
+	// the callers get a copy they can change
+	v := *visibility
+
+	return &v, nil
+}
+
+// GetRepoDirFiles caches the files by the commit at the head of the branch, which is looked up on each
+// call, so that they are never out of date.
+func (p *cachedProvider) GetRepoDirFiles(ctx context.Context, repoUrl RepoURL, dirPath, targetBranch string) ([]*gitprovider.CommitFile, error) {
+	head, err := headCommit(ctx, p.GitProvider, repoUrl, targetBranch)
+	if err != nil || head == "" {
+		return p.GitProvider.GetRepoDirFiles(ctx, repoUrl, dirPath, targetBranch)
+	}
+
+	value, err := p.cached(repoKey(repoUrl, "dir-files", dirPath, head), func() (interface{}, error) {
+		return p.GitProvider.GetRepoDirFiles(ctx, repoUrl, dirPath, targetBranch)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the callers get copies they can change
+	files := []*gitprovider.CommitFile{}
+
+	for _, file := range value.([]*gitprovider.CommitFile) {
+		file := *file
+		files = append(files, &file)
+	}
+
+	return files, nil
+}
+
+func (p *cachedProvider) GetAuthenticatedUser(ctx context.Context) (git.Author, error) {
+	value, err := p.cached("authenticated-user", func() (interface{}, error) {
+		return p.GitProvider.GetAuthenticatedUser(ctx)
+	})
+	if err != nil {
+		return git.Author{}, err
+	}
+
+	return value.(git.Author), nil
+}
+
+func (p *cachedProvider) UploadDeployKey(ctx context.Context, repoUrl RepoURL, deployKey []byte) error {
+	defer p.invalidate(repoUrl)
+
+	return p.GitProvider.UploadDeployKey(ctx, repoUrl, deployKey)
+}
+
+func (p *cachedProvider) UploadNamedDeployKey(ctx context.Context, repoUrl RepoURL, name string, deployKey []byte) error {
+	defer p.invalidate(repoUrl)
+
+	return p.GitProvider.UploadNamedDeployKey(ctx, repoUrl, name, deployKey)
+}
+
+func (p *cachedProvider) DeleteDeployKey(ctx context.Context, repoUrl RepoURL, id string) error {
+	defer p.invalidate(repoUrl)
+
+	return p.GitProvider.DeleteDeployKey(ctx, repoUrl, id)
+}
+
+func (p *cachedProvider) CreatePullRequest(ctx context.Context, repoUrl RepoURL, prInfo PullRequestInfo) (gitprovider.PullRequest, error) {
+	defer p.invalidate(repoUrl)
+
+	return p.GitProvider.CreatePullRequest(ctx, repoUrl, prInfo)
+}
+
+func (p *cachedProvider) DeleteBranch(ctx context.Context, repoUrl RepoURL, branch string) error {
+	defer p.invalidate(repoUrl)
+
+	return p.GitProvider.DeleteBranch(ctx, repoUrl, branch)
+}
+
+func (p *cachedProvider) CommitFiles(ctx context.Context, repoUrl RepoURL, branch, commitMessage string, files []gitprovider.CommitFile) error {
+	defer p.invalidate(repoUrl)
+
+	return p.GitProvider.CommitFiles(ctx, repoUrl, branch, commitMessage, files)
+}
+
+func (p *cachedProvider) MergePullRequest(ctx context.Context, repoUrl RepoURL, pullRequestNumber int, mergeMethod gitprovider.MergeMethod, commitMesage string) error {
+	defer p.invalidate(repoUrl)
+
+	return p.GitProvider.MergePullRequest(ctx, repoUrl, pullRequestNumber, mergeMethod, commitMesage)
+}
@@ -0,0 +1,102 @@
+package gitproviders
+
+import (
+	"context"
+	"time"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// countingProvider counts the calls to the memoryProvider.
+type countingProvider struct {
+	*memoryProvider
+	calls map[string]int
+}
+
+func (p *countingProvider) GetDefaultBranch(ctx context.Context, repoUrl RepoURL) (string, error) {
+	p.calls["GetDefaultBranch"]++
+	return p.memoryProvider.GetDefaultBranch(ctx, repoUrl)
+}
+
+func (p *countingProvider) GetRepoDirFiles(ctx context.Context, repoUrl RepoURL, dirPath, branch string) ([]*gitprovider.CommitFile, error) {
+	p.calls["GetRepoDirFiles"]++
+	return p.memoryProvider.GetRepoDirFiles(ctx, repoUrl, dirPath, branch)
+}
+
+var _ = Describe("NewCachedProvider", func() {
+	var (
+		ctx      context.Context
+		provider *countingProvider
+		cached   *cachedProvider
+		repoUrl  RepoURL
+		now      time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		provider = &countingProvider{
+			memoryProvider: newMemoryProvider(map[string]string{"clusters/prod/profiles.yaml": "podinfo: 6.0.0\n"}),
+			calls:          map[string]int{},
+		}
+
+		now = time.Now()
+		cached = NewCachedProvider(provider, time.Minute).(*cachedProvider)
+		cached.now = func() time.Time { return now }
+
+		var err error
+		repoUrl, err = NewRepoURL("https://github.com/owner/config-repo")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("caches the default branch until it expires", func() {
+		for i := 0; i < 2; i++ {
+			Expect(cached.GetDefaultBranch(ctx, repoUrl)).To(Equal("main"))
+		}
+
+		Expect(provider.calls["GetDefaultBranch"]).To(Equal(1))
+
+		now = now.Add(2 * time.Minute)
+
+		Expect(cached.GetDefaultBranch(ctx, repoUrl)).To(Equal("main"))
+		Expect(provider.calls["GetDefaultBranch"]).To(Equal(2))
+	})
+
+	It("caches the files of a directory by the head of the branch", func() {
+		for i := 0; i < 2; i++ {
+			files, err := cached.GetRepoDirFiles(ctx, repoUrl, "clusters/prod", "main")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*files[0].Content).To(Equal("podinfo: 6.0.0\n"))
+		}
+
+		Expect(provider.calls["GetRepoDirFiles"]).To(Equal(1))
+
+		provider.commit("main", []gitprovider.CommitFile{{
+			Path:    gitprovider.StringVar("clusters/prod/profiles.yaml"),
+			Content: gitprovider.StringVar("podinfo: 6.1.0\n"),
+		}})
+
+		files, err := cached.GetRepoDirFiles(ctx, repoUrl, "clusters/prod", "main")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*files[0].Content).To(Equal("podinfo: 6.1.0\n"))
+		Expect(provider.calls["GetRepoDirFiles"]).To(Equal(2))
+	})
+
+	It("doesn't cache errors", func() {
+		for i := 0; i < 2; i++ {
+			_, err := cached.GetRepoDirFiles(ctx, repoUrl, "clusters/dev", "main")
+			Expect(err).To(MatchError(gitprovider.ErrNotFound))
+		}
+
+		Expect(provider.calls["GetRepoDirFiles"]).To(Equal(2))
+	})
+
+	It("drops the entries of a repository changed through the provider", func() {
+		Expect(cached.GetDefaultBranch(ctx, repoUrl)).To(Equal("main"))
+		Expect(cached.CommitFiles(ctx, repoUrl, "main", "update", nil)).To(Succeed())
+		Expect(cached.GetDefaultBranch(ctx, repoUrl)).To(Equal("main"))
+
+		Expect(provider.calls["GetDefaultBranch"]).To(Equal(2))
+	})
+})
@@ -0,0 +1,306 @@
+package gitproviders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+)
+
+// ErrChangesetConflict is returned when files edited by a changeset changed on the base branch after
+// they were read.
+var ErrChangesetConflict = errors.New("conflicting changes on the base branch")
+
+// Changeset gathers the edits of several files of a repository into a single pull request.
+//
+// It remembers the commit of the base branch the files were read at. When the branch moved on by the
+// time the changeset is submitted, the edits are rebased on the new commit if none of the files they
+// touch changed, and ErrChangesetConflict is returned otherwise. Submitting a changeset with the branch
+// of an open pull request updates that pull request instead of opening another one. A branch generated by
+// gitops whose pull request was merged or closed is reset to the base branch, other branches without an
+// open pull request must not exist yet.
+type Changeset struct {
+	provider   GitProvider
+	repoUrl    RepoURL
+	baseBranch string
+	baseCommit string
+	// dirs holds the files read from the base branch by directory and path
+	dirs    map[string]map[string]string
+	changes []gitprovider.CommitFile
+}
+
+// NewChangeset returns an empty changeset based on the head of a branch, the default branch of the
+// repository if empty.
+func NewChangeset(ctx context.Context, provider GitProvider, repoUrl RepoURL, baseBranch string) (*Changeset, error) {
+	if baseBranch == "" {
+		branch, err := provider.GetDefaultBranch(ctx, repoUrl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get default branch: %w", err)
+		}
+
+		baseBranch = branch
+	}
+
+	baseCommit, err := headCommit(ctx, provider, repoUrl, baseBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Changeset{
+		provider:   provider,
+		repoUrl:    repoUrl,
+		baseBranch: baseBranch,
+		baseCommit: baseCommit,
+		dirs:       map[string]map[string]string{},
+	}, nil
+}
+
+// BaseBranch returns the branch the pull request of the changeset targets.
+func (c *Changeset) BaseBranch() string {
+	return c.baseBranch
+}
+
+// ReadDir returns the files of a directory of the base branch, like GitProvider.GetRepoDirFiles. Edits of
+// the files of the directories read are checked for conflicts when the changeset is submitted.
+func (c *Changeset) ReadDir(ctx context.Context, dirPath string) ([]*gitprovider.CommitFile, error) {
+	files, err := c.provider.GetRepoDirFiles(ctx, c.repoUrl, dirPath, c.baseBranch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get files in '%s' of repository %q: %w", dirPath, c.repoUrl, err)
+	}
+
+	c.dirs[path.Clean(dirPath)] = contentsByPath(files)
+
+	return files, nil
+}
+
+// Write sets the content of a file, replacing any previous edit of it.
+func (c *Changeset) Write(filePath, content string) {
+	c.edit(gitprovider.CommitFile{Path: &filePath, Content: &content})
+}
+
+// Delete deletes a file, replacing any previous edit of it.
+func (c *Changeset) Delete(filePath string) {
+	c.edit(gitprovider.CommitFile{Path: &filePath})
+}
+
+func (c *Changeset) edit(file gitprovider.CommitFile) {
+	for i, change := range c.changes {
+		if *change.Path == *file.Path {
+			c.changes[i] = file
+			return
+		}
+	}
+
+	c.changes = append(c.changes, file)
+}
+
+// Submit opens a pull request with the edits of the changeset from prInfo.NewBranch to the base branch, or
+// commits them to the branch if it already has an open pull request. It returns the pull request and
+// whether it was opened rather than updated.
+func (c *Changeset) Submit(ctx context.Context, prInfo PullRequestInfo) (gitprovider.PullRequest, bool, error) {
+	if len(c.changes) == 0 {
+		return nil, false, errors.New("no changes to submit")
+	}
+
+	if err := c.rebase(ctx); err != nil {
+		return nil, false, err
+	}
+
+	prInfo.TargetBranch = c.baseBranch
+	prInfo.Files = append([]gitprovider.CommitFile{}, c.changes...)
+
+	pr, err := c.provider.GetOpenPullRequest(ctx, c.repoUrl, prInfo.NewBranch)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get the pull request of %s: %w", prInfo.NewBranch, err)
+	}
+
+	if pr == nil {
+		if err := c.resetBranch(ctx, prInfo); err != nil {
+			return nil, false, err
+		}
+
+		pr, err = c.provider.CreatePullRequest(ctx, c.repoUrl, prInfo)
+		if err != nil {
+			if !prInfo.GeneratedBranch {
+				return nil, false, fmt.Errorf("failed to open a pull request from %s, which must not exist yet as it has no open pull request: %w", prInfo.NewBranch, err)
+			}
+
+			return nil, false, err
+		}
+
+		return pr, true, nil
+	}
+
+	pending, err := c.pendingChanges(ctx, prInfo.NewBranch)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// running the same command again leaves the pull request as it is
+	if len(pending) > 0 {
+		if err := c.provider.CommitFiles(ctx, c.repoUrl, prInfo.NewBranch, prInfo.CommitMessage, pending); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return pr, false, nil
+}
+
+// resetBranch deletes the generated branch of a merged or closed pull request, which is left behind by
+// default, so that the new pull request starts from the base branch. Branches named by the user are never
+// deleted.
+func (c *Changeset) resetBranch(ctx context.Context, prInfo PullRequestInfo) error {
+	if !prInfo.GeneratedBranch {
+		return nil
+	}
+
+	closed, err := c.provider.GetClosedPullRequest(ctx, c.repoUrl, prInfo.NewBranch)
+	if err != nil {
+		return fmt.Errorf("failed to get the pull requests of %s: %w", prInfo.NewBranch, err)
+	}
+
+	if closed == nil {
+		return nil
+	}
+
+	if err := c.provider.DeleteBranch(ctx, c.repoUrl, prInfo.NewBranch); err != nil {
+		return fmt.Errorf("failed to reset %s: %w", prInfo.NewBranch, err)
+	}
+
+	return nil
+}
+
+// rebase moves the changeset to the head of the base branch, provided none of the edited files of the
+// directories read changed since.
+func (c *Changeset) rebase(ctx context.Context) error {
+	head, err := headCommit(ctx, c.provider, c.repoUrl, c.baseBranch)
+	if err != nil {
+		return err
+	}
+
+	if head == c.baseCommit {
+		return nil
+	}
+
+	dirs := map[string]map[string]string{}
+
+	for dir := range c.dirs {
+		files, err := c.readDir(ctx, dir, c.baseBranch)
+		if err != nil {
+			return err
+		}
+
+		dirs[dir] = contentsByPath(files)
+	}
+
+	conflicts := []string{}
+
+	for _, change := range c.changes {
+		filePath := path.Clean(*change.Path)
+
+		read, ok := c.dirs[path.Dir(filePath)]
+		if !ok {
+			continue
+		}
+
+		before, existed := read[filePath]
+		now, exists := dirs[path.Dir(filePath)][filePath]
+
+		if before != now || existed != exists {
+			conflicts = append(conflicts, filePath)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s changed on %s since commit %s, run the command again to apply the changes on the latest commit",
+			ErrChangesetConflict, strings.Join(conflicts, ", "), c.baseBranch, shortSha(c.baseCommit))
+	}
+
+	c.baseCommit = head
+	c.dirs = dirs
+
+	return nil
+}
+
+// pendingChanges returns the edits that aren't on the branch yet.
+func (c *Changeset) pendingChanges(ctx context.Context, branch string) ([]gitprovider.CommitFile, error) {
+	dirs := map[string]map[string]string{}
+	pending := []gitprovider.CommitFile{}
+
+	for _, change := range c.changes {
+		filePath := path.Clean(*change.Path)
+		dir := path.Dir(filePath)
+
+		if _, ok := dirs[dir]; !ok {
+			files, err := c.readDir(ctx, dir, branch)
+			if err != nil {
+				return nil, err
+			}
+
+			dirs[dir] = contentsByPath(files)
+		}
+
+		content, exists := dirs[dir][filePath]
+
+		switch {
+		case change.Content == nil && !exists:
+		case change.Content != nil && exists && *change.Content == content:
+		default:
+			pending = append(pending, change)
+		}
+	}
+
+	return pending, nil
+}
+
+func (c *Changeset) readDir(ctx context.Context, dirPath, branch string) ([]*gitprovider.CommitFile, error) {
+	files, err := c.provider.GetRepoDirFiles(ctx, c.repoUrl, dirPath, branch)
+	if err != nil {
+		// the directory was removed, or is created by the changeset
+		if errors.Is(err, gitprovider.ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to get files in '%s' of repository %q: %w", dirPath, c.repoUrl, err)
+	}
+
+	return files, nil
+}
+
+func headCommit(ctx context.Context, provider GitProvider, repoUrl RepoURL, branch string) (string, error) {
+	commits, err := provider.GetCommits(ctx, repoUrl, branch, 1, 0)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the head of %s: %w", branch, err)
+	}
+
+	if len(commits) == 0 {
+		return "", nil
+	}
+
+	return commits[0].Get().Sha, nil
+}
+
+func contentsByPath(files []*gitprovider.CommitFile) map[string]string {
+	contents := map[string]string{}
+
+	for _, file := range files {
+		if file.Path == nil || file.Content == nil {
+			continue
+		}
+
+		contents[path.Clean(*file.Path)] = *file.Content
+	}
+
+	return contents
+}
+
+func shortSha(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+
+	return sha
+}
@@ -0,0 +1,309 @@
+package gitproviders
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// memoryProvider keeps the files of the branches of a single repository in memory.
+type memoryProvider struct {
+	GitProvider
+	branches  map[string]map[string]string
+	heads     map[string]int
+	prs       map[string]gitprovider.PullRequest
+	closed    map[string]gitprovider.PullRequest
+	created   []PullRequestInfo
+	committed [][]gitprovider.CommitFile
+}
+
+func newMemoryProvider(files map[string]string) *memoryProvider {
+	return &memoryProvider{
+		branches: map[string]map[string]string{"main": files},
+		heads:    map[string]int{"main": 1},
+		prs:      map[string]gitprovider.PullRequest{},
+		closed:   map[string]gitprovider.PullRequest{},
+	}
+}
+
+func (p *memoryProvider) commit(branch string, files []gitprovider.CommitFile) {
+	for _, file := range files {
+		if file.Content == nil {
+			delete(p.branches[branch], *file.Path)
+		} else {
+			p.branches[branch][*file.Path] = *file.Content
+		}
+	}
+
+	p.heads[branch]++
+}
+
+func (p *memoryProvider) GetDefaultBranch(_ context.Context, _ RepoURL) (string, error) {
+	return "main", nil
+}
+
+func (p *memoryProvider) GetCommits(_ context.Context, _ RepoURL, branch string, _, _ int) ([]gitprovider.Commit, error) {
+	return []gitprovider.Commit{commit{info: gitprovider.CommitInfo{Sha: fmt.Sprintf("%s-%08d", branch, p.heads[branch])}}}, nil
+}
+
+func (p *memoryProvider) GetRepoDirFiles(_ context.Context, _ RepoURL, dirPath, branch string) ([]*gitprovider.CommitFile, error) {
+	files := []*gitprovider.CommitFile{}
+
+	for filePath, content := range p.branches[branch] {
+		if path.Dir(filePath) == dirPath {
+			filePath, content := filePath, content
+			files = append(files, &gitprovider.CommitFile{Path: &filePath, Content: &content})
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, gitprovider.ErrNotFound
+	}
+
+	return files, nil
+}
+
+func (p *memoryProvider) GetOpenPullRequest(_ context.Context, _ RepoURL, branch string) (gitprovider.PullRequest, error) {
+	return p.prs[branch], nil
+}
+
+// merge merges the open pull request of a branch into main and keeps the branch.
+func (p *memoryProvider) merge(branch string) {
+	files := []gitprovider.CommitFile{}
+
+	for filePath, content := range p.branches[branch] {
+		filePath, content := filePath, content
+		files = append(files, gitprovider.CommitFile{Path: &filePath, Content: &content})
+	}
+
+	p.commit("main", files)
+
+	p.closed[branch] = p.prs[branch]
+	delete(p.prs, branch)
+}
+
+func (p *memoryProvider) GetClosedPullRequest(_ context.Context, _ RepoURL, branch string) (gitprovider.PullRequest, error) {
+	return p.closed[branch], nil
+}
+
+func (p *memoryProvider) CreatePullRequest(_ context.Context, _ RepoURL, prInfo PullRequestInfo) (gitprovider.PullRequest, error) {
+	if _, ok := p.branches[prInfo.NewBranch]; ok {
+		return nil, fmt.Errorf("error creating branch %s: reference already exists", prInfo.NewBranch)
+	}
+
+	p.created = append(p.created, prInfo)
+
+	p.branches[prInfo.NewBranch] = map[string]string{}
+	for filePath, content := range p.branches[prInfo.TargetBranch] {
+		p.branches[prInfo.NewBranch][filePath] = content
+	}
+
+	p.commit(prInfo.NewBranch, prInfo.Files)
+
+	pr := pullRequest{info: gitprovider.PullRequestInfo{Number: len(p.created)}}
+	p.prs[prInfo.NewBranch] = pr
+
+	return pr, nil
+}
+
+func (p *memoryProvider) DeleteBranch(_ context.Context, _ RepoURL, branch string) error {
+	delete(p.branches, branch)
+	delete(p.heads, branch)
+
+	return nil
+}
+
+func (p *memoryProvider) CommitFiles(_ context.Context, _ RepoURL, branch, _ string, files []gitprovider.CommitFile) error {
+	p.committed = append(p.committed, files)
+	p.commit(branch, files)
+
+	return nil
+}
+
+var _ = Describe("Changeset", func() {
+	var (
+		ctx      context.Context
+		provider *memoryProvider
+		repoUrl  RepoURL
+		prInfo   PullRequestInfo
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		provider = newMemoryProvider(map[string]string{
+			"clusters/prod/profiles.yaml":  "podinfo: 6.0.0\n",
+			"clusters/prod/kustomize.yaml": "kind: Kustomization\n",
+		})
+
+		var err error
+		repoUrl, err = NewRepoURL("https://github.com/owner/config-repo")
+		Expect(err).NotTo(HaveOccurred())
+
+		prInfo = PullRequestInfo{Title: "update podinfo", NewBranch: "update-podinfo", GeneratedBranch: true, CommitMessage: "update podinfo"}
+	})
+
+	newChangeset := func() *Changeset {
+		changeset, err := NewChangeset(ctx, provider, repoUrl, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changeset.BaseBranch()).To(Equal("main"))
+
+		files, err := changeset.ReadDir(ctx, "clusters/prod")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(2))
+
+		return changeset
+	}
+
+	It("opens a pull request with the edits of several files", func() {
+		changeset := newChangeset()
+		changeset.Write("clusters/prod/profiles.yaml", "podinfo: 6.0.1\n")
+		changeset.Write("clusters/prod/profiles.yaml", "podinfo: 6.1.0\n")
+		changeset.Delete("clusters/prod/kustomize.yaml")
+
+		pr, created, err := changeset.Submit(ctx, prInfo)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(BeTrue())
+		Expect(pr.Get().Number).To(Equal(1))
+
+		Expect(provider.created).To(HaveLen(1))
+		Expect(provider.created[0].TargetBranch).To(Equal("main"))
+		Expect(provider.created[0].NewBranch).To(Equal("update-podinfo"))
+		Expect(provider.branches["update-podinfo"]).To(Equal(map[string]string{
+			"clusters/prod/profiles.yaml": "podinfo: 6.1.0\n",
+		}))
+	})
+
+	It("fails without edits", func() {
+		_, _, err := newChangeset().Submit(ctx, prInfo)
+		Expect(err).To(MatchError("no changes to submit"))
+	})
+
+	It("rebases the edits when other files changed on the base branch", func() {
+		changeset := newChangeset()
+		changeset.Write("clusters/prod/profiles.yaml", "podinfo: 6.1.0\n")
+
+		provider.commit("main", []gitprovider.CommitFile{{
+			Path:    gitprovider.StringVar("clusters/prod/kustomize.yaml"),
+			Content: gitprovider.StringVar("kind: Kustomization\nprune: true\n"),
+		}})
+
+		_, created, err := changeset.Submit(ctx, prInfo)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(BeTrue())
+		Expect(provider.branches["update-podinfo"]).To(HaveKeyWithValue("clusters/prod/kustomize.yaml", "kind: Kustomization\nprune: true\n"))
+	})
+
+	It("fails when the edited files changed on the base branch", func() {
+		changeset := newChangeset()
+		changeset.Write("clusters/prod/profiles.yaml", "podinfo: 6.1.0\n")
+
+		provider.commit("main", []gitprovider.CommitFile{{
+			Path:    gitprovider.StringVar("clusters/prod/profiles.yaml"),
+			Content: gitprovider.StringVar("podinfo: 6.0.2\n"),
+		}})
+
+		_, _, err := changeset.Submit(ctx, prInfo)
+		Expect(err).To(MatchError(ErrChangesetConflict))
+		Expect(err.Error()).To(ContainSubstring("clusters/prod/profiles.yaml changed on main since commit main-00"))
+		Expect(provider.created).To(BeEmpty())
+	})
+
+	It("fails when an edited file was created on the base branch", func() {
+		changeset := newChangeset()
+		changeset.Write("clusters/prod/flux.yaml", "kind: GitRepository\n")
+
+		provider.commit("main", []gitprovider.CommitFile{{
+			Path:    gitprovider.StringVar("clusters/prod/flux.yaml"),
+			Content: gitprovider.StringVar("kind: HelmRepository\n"),
+		}})
+
+		_, _, err := changeset.Submit(ctx, prInfo)
+		Expect(err).To(MatchError(ErrChangesetConflict))
+	})
+
+	It("updates the open pull request of the branch", func() {
+		changeset := newChangeset()
+		changeset.Write("clusters/prod/profiles.yaml", "podinfo: 6.1.0\n")
+
+		_, created, err := changeset.Submit(ctx, prInfo)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(BeTrue())
+
+		changeset = newChangeset()
+		changeset.Write("clusters/prod/profiles.yaml", "podinfo: 6.2.0\n")
+
+		pr, created, err := changeset.Submit(ctx, prInfo)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(BeFalse())
+		Expect(pr.Get().Number).To(Equal(1))
+		Expect(provider.created).To(HaveLen(1))
+		Expect(provider.committed).To(HaveLen(1))
+		Expect(provider.branches["update-podinfo"]).To(HaveKeyWithValue("clusters/prod/profiles.yaml", "podinfo: 6.2.0\n"))
+	})
+
+	It("resets the branch of a merged pull request for a new one", func() {
+		changeset := newChangeset()
+		changeset.Write("clusters/prod/profiles.yaml", "podinfo: 6.1.0\n")
+
+		_, _, err := changeset.Submit(ctx, prInfo)
+		Expect(err).NotTo(HaveOccurred())
+
+		provider.merge("update-podinfo")
+
+		changeset = newChangeset()
+		changeset.Write("clusters/prod/profiles.yaml", "podinfo: 6.2.0\n")
+
+		pr, created, err := changeset.Submit(ctx, prInfo)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(BeTrue())
+		Expect(pr.Get().Number).To(Equal(2))
+		Expect(provider.branches["update-podinfo"]).To(Equal(map[string]string{
+			"clusters/prod/profiles.yaml":  "podinfo: 6.2.0\n",
+			"clusters/prod/kustomize.yaml": "kind: Kustomization\n",
+		}))
+	})
+
+	It("fails without deleting a branch it didn't generate that has no pull request", func() {
+		prInfo.NewBranch = "my-branch"
+		prInfo.GeneratedBranch = false
+
+		provider.branches["my-branch"] = map[string]string{"clusters/prod/profiles.yaml": "podinfo: 6.0.5\n"}
+
+		changeset := newChangeset()
+		changeset.Write("clusters/prod/profiles.yaml", "podinfo: 6.1.0\n")
+
+		_, _, err := changeset.Submit(ctx, prInfo)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("failed to open a pull request from my-branch"))
+		Expect(provider.created).To(BeEmpty())
+		Expect(provider.branches["my-branch"]).To(Equal(map[string]string{"clusters/prod/profiles.yaml": "podinfo: 6.0.5\n"}))
+	})
+
+	It("fails without deleting a generated branch that has no pull request", func() {
+		provider.branches["update-podinfo"] = map[string]string{"clusters/prod/profiles.yaml": "podinfo: 6.0.5\n"}
+
+		changeset := newChangeset()
+		changeset.Write("clusters/prod/profiles.yaml", "podinfo: 6.1.0\n")
+
+		_, _, err := changeset.Submit(ctx, prInfo)
+		Expect(err).To(HaveOccurred())
+		Expect(provider.branches["update-podinfo"]).To(HaveKeyWithValue("clusters/prod/profiles.yaml", "podinfo: 6.0.5\n"))
+	})
+
+	It("leaves the open pull request as it is when it has the edits already", func() {
+		for i := 0; i < 2; i++ {
+			changeset := newChangeset()
+			changeset.Write("clusters/prod/profiles.yaml", "podinfo: 6.1.0\n")
+
+			_, _, err := changeset.Submit(ctx, prInfo)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(provider.created).To(HaveLen(1))
+		Expect(provider.committed).To(BeEmpty())
+	})
+})
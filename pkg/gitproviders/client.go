@@ -4,4 +4,5 @@ package gitproviders
 //counterfeiter:generate . Client
 type Client interface {
 	GetProvider(repoUrl RepoURL, getAccountType AccountTypeGetter) (GitProvider, error)
+	GetToken(repoUrl RepoURL) (string, error)
 }
@@ -0,0 +1,140 @@
+package gitproviders
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	"github.com/google/go-github/v41/github"
+	"github.com/xanzy/go-gitlab"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// DeployKey is a deploy key of a repository.
+type DeployKey struct {
+	// ID identifies the key on the git provider.
+	ID       string
+	Name     string
+	Key      []byte
+	ReadOnly bool
+	// CreatedAt is zero when the git provider doesn't return it.
+	CreatedAt time.Time
+}
+
+// RotatedDeployKeyName returns the name of a deploy key replacing another one at t. The names are
+// unique, as some providers refuse keys named like existing ones.
+func RotatedDeployKeyName(t time.Time) string {
+	return DeployKeyName + "-" + t.UTC().Format("20060102-150405")
+}
+
+// IsManagedDeployKey returns whether a deploy key was uploaded by gitops, given its name.
+func IsManagedDeployKey(name string) bool {
+	return name == DeployKeyName || strings.HasPrefix(name, DeployKeyName+"-")
+}
+
+// SameDeployKey returns whether two public keys are the same, ignoring their comments, which the
+// providers drop.
+func SameDeployKey(a, b []byte) bool {
+	fa, fb := bytes.Fields(a), bytes.Fields(b)
+	if len(fa) < 2 || len(fb) < 2 {
+		return false
+	}
+
+	return bytes.Equal(fa[0], fb[0]) && bytes.Equal(fa[1], fb[1])
+}
+
+// DeployKeyFingerprint returns the SHA256 fingerprint of a public key, or an empty string when it can't
+// be parsed.
+func DeployKeyFingerprint(key []byte) string {
+	publicKey, _, _, _, err := gossh.ParseAuthorizedKey(key)
+	if err != nil {
+		return ""
+	}
+
+	return gossh.FingerprintSHA256(publicKey)
+}
+
+// listDeployKeys returns the deploy keys of a repository uploaded by gitops.
+func listDeployKeys(ctx context.Context, repo gitprovider.UserRepository) ([]DeployKey, error) {
+	keys, err := repo.DeployKeys().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing deploy keys: %w", err)
+	}
+
+	deployKeys := []DeployKey{}
+
+	for _, k := range keys {
+		info := k.Get()
+		if !IsManagedDeployKey(info.Name) {
+			continue
+		}
+
+		deployKeys = append(deployKeys, newDeployKey(k))
+	}
+
+	return deployKeys, nil
+}
+
+func newDeployKey(k gitprovider.DeployKey) DeployKey {
+	info := k.Get()
+	key := DeployKey{
+		Name:     info.Name,
+		Key:      info.Key,
+		ReadOnly: info.ReadOnly != nil && *info.ReadOnly,
+	}
+
+	switch obj := k.APIObject().(type) {
+	case *github.Key:
+		key.ID = strconv.FormatInt(obj.GetID(), 10)
+		key.CreatedAt = obj.GetCreatedAt().Time
+	case *gitlab.DeployKey:
+		key.ID = strconv.Itoa(obj.ID)
+
+		if obj.CreatedAt != nil {
+			key.CreatedAt = *obj.CreatedAt
+		}
+	}
+
+	return key
+}
+
+// deleteDeployKey deletes a deploy key of a repository uploaded by gitops.
+func deleteDeployKey(ctx context.Context, repo gitprovider.UserRepository, id string) error {
+	keys, err := repo.DeployKeys().List(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing deploy keys: %w", err)
+	}
+
+	for _, k := range keys {
+		if !IsManagedDeployKey(k.Get().Name) || newDeployKey(k).ID != id {
+			continue
+		}
+
+		if err := k.Delete(ctx); err != nil {
+			return fmt.Errorf("error deleting deploy key %s: %w", id, err)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("error deleting deploy key %s: %w", id, gitprovider.ErrNotFound)
+}
+
+// managedDeployKeyExists returns whether a deploy key uploaded by gitops exists, whatever its name.
+func managedDeployKeyExists(ctx context.Context, repo gitprovider.UserRepository) (bool, error) {
+	keys, err := listDeployKeys(ctx, repo)
+	if err != nil {
+		if errors.Is(err, gitprovider.ErrNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return len(keys) > 0, nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/fluxcd/go-git-providers/gitprovider"
+
+	"github.com/weaveworks/weave-gitops/pkg/git"
 )
 
 type dryrunProvider struct {
@@ -46,14 +48,46 @@ func (p *dryrunProvider) UploadDeployKey(_ context.Context, repoUrl RepoURL, dep
 	return nil
 }
 
+func (p *dryrunProvider) UploadNamedDeployKey(_ context.Context, repoUrl RepoURL, name string, deployKey []byte) error {
+	return nil
+}
+
+func (p *dryrunProvider) ListDeployKeys(_ context.Context, repoUrl RepoURL) ([]DeployKey, error) {
+	return []DeployKey{}, nil
+}
+
+func (p *dryrunProvider) DeleteDeployKey(_ context.Context, repoUrl RepoURL, id string) error {
+	return nil
+}
+
 func (p *dryrunProvider) CreatePullRequest(_ context.Context, repoUrl RepoURL, prInfo PullRequestInfo) (gitprovider.PullRequest, error) {
 	return nil, nil
 }
 
+func (p *dryrunProvider) GetOpenPullRequest(_ context.Context, repoUrl RepoURL, branch string) (gitprovider.PullRequest, error) {
+	return nil, nil
+}
+
+func (p *dryrunProvider) GetClosedPullRequest(_ context.Context, repoUrl RepoURL, branch string) (gitprovider.PullRequest, error) {
+	return nil, nil
+}
+
+func (p *dryrunProvider) DeleteBranch(_ context.Context, repoUrl RepoURL, branch string) error {
+	return nil
+}
+
+func (p *dryrunProvider) CommitFiles(_ context.Context, repoUrl RepoURL, branch, commitMessage string, files []gitprovider.CommitFile) error {
+	return nil
+}
+
 func (p *dryrunProvider) GetCommits(_ context.Context, repoUrl RepoURL, targetBranch string, pageSize int, pageToken int) ([]gitprovider.Commit, error) {
 	return []gitprovider.Commit{}, nil
 }
 
+func (p *dryrunProvider) GetAuthenticatedUser(_ context.Context) (git.Author, error) {
+	return git.DefaultAuthor, nil
+}
+
 func (p *dryrunProvider) GetProviderDomain() string {
 	return p.provider.GetProviderDomain()
 }
@@ -62,6 +96,10 @@ func (p *dryrunProvider) GetRepoDirFiles(_ context.Context, repoUrl RepoURL, dir
 	return nil, nil
 }
 
-func (p *dryrunProvider) MergePullRequest(ctx context.Context, repoUrl RepoURL, pullRequestNumber int, commitMesage string) error {
+func (p *dryrunProvider) GetPullRequestStatus(ctx context.Context, repoUrl RepoURL, pullRequestNumber int) (PullRequestStatus, error) {
+	return PullRequestStatus{Number: pullRequestNumber, State: PullRequestMerged, Checks: ChecksPassed}, nil
+}
+
+func (p *dryrunProvider) MergePullRequest(ctx context.Context, repoUrl RepoURL, pullRequestNumber int, mergeMethod gitprovider.MergeMethod, commitMesage string) error {
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/fluxcd/go-git-providers/gitprovider"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/git"
 	"github.com/weaveworks/weave-gitops/pkg/vendorfakes/fakegitprovider"
 )
 
@@ -89,6 +90,14 @@ var _ = Describe("DryRun", func() {
 		})
 	})
 
+	Describe("GetAuthenticatedUser", func() {
+		It("returns the default author", func() {
+			user, err := dryRunProvider.GetAuthenticatedUser(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(user).To(Equal(git.DefaultAuthor))
+		})
+	})
+
 	Describe("GetProviderDomain", func() {
 		It("returns github provider", func() {
 			Expect(dryRunProvider.GetProviderDomain()).To(Equal("github.com"))
@@ -2,6 +2,7 @@ package gitproviders
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/fluxcd/go-git-providers/github"
 	"github.com/fluxcd/go-git-providers/gitlab"
@@ -12,9 +13,12 @@ import (
 type GitProviderName string
 
 const (
-	GitProviderGitHub GitProviderName = "github"
-	GitProviderGitLab GitProviderName = "gitlab"
-	tokenTypeOauth    string          = "oauth2"
+	GitProviderGitHub          GitProviderName = "github"
+	GitProviderGitLab          GitProviderName = "gitlab"
+	GitProviderBitbucketServer GitProviderName = "bitbucket-server"
+	GitProviderGitea           GitProviderName = "gitea"
+	GitProviderGit             GitProviderName = "git"
+	tokenTypeOauth             string          = "oauth2"
 )
 
 // Config defines the configuration for connecting to a GitProvider.
@@ -29,17 +33,58 @@ type Config struct {
 	// Token contains the token used to authenticate with the
 	// Provider.
 	Token string
+
+	// Git configures the provider of plain git servers, see GitProviderGit.
+	Git GitOptions
+
+	// GitHubApp authenticates with the installation tokens of a GitHub App instead of Token.
+	GitHubApp *GitHubAppTokenSource
+
+	// RateLimiter, if set, backs off the API requests when the rate limit of the provider is reached.
+	RateLimiter *RateLimiter
+}
+
+// httpClient returns the client of the REST APIs of the providers, going through the rate limiter if any.
+func (c Config) httpClient() *http.Client {
+	if c.RateLimiter == nil {
+		return http.DefaultClient
+	}
+
+	return &http.Client{Transport: c.RateLimiter.Transport(nil)}
+}
+
+// HTTPSUsername returns the username sent along with the token of a provider when cloning over
+// HTTPS, an empty one meaning the token is sent as bearer.
+func HTTPSUsername(provider GitProviderName) string {
+	switch provider {
+	case GitProviderGitHub:
+		return "x-access-token"
+	case GitProviderGitLab, GitProviderGitea:
+		return tokenTypeOauth
+	default:
+		return ""
+	}
 }
 
 func buildGitProvider(config Config) (gitprovider.Client, string, error) {
-	if config.Token == "" {
+	if config.Token == "" && (config.GitHubApp == nil || config.Provider != GitProviderGitHub) {
 		return nil, "", fmt.Errorf("no git provider token present")
 	}
 
+	// the cached responses are revalidated with their ETags, which doesn't count against the rate limit
+	opts := []gitprovider.ClientOption{gitprovider.WithConditionalRequests(true)}
+
+	if config.RateLimiter != nil {
+		opts = append(opts, gitprovider.WithPostChainTransportHook(config.RateLimiter.Transport))
+	}
+
 	switch config.Provider {
 	case GitProviderGitHub:
-		opts := []gitprovider.ClientOption{
-			gitprovider.WithOAuth2Token(config.Token),
+
+		if config.GitHubApp != nil {
+			opts = append(opts, gitprovider.WithPreChainTransportHook(config.GitHubApp.transport))
+		} else {
+			opts = append(opts, gitprovider.WithOAuth2Token(config.Token))
 		}
 
 		// Quirk of ggp, if using github.com or gitlab.com and you prepend
@@ -57,10 +102,7 @@ func buildGitProvider(config Config) (gitprovider.Client, string, error) {
 			return client, hostname, nil
 		}
 	case GitProviderGitLab:
-		opts := []gitprovider.ClientOption{
-			gitprovider.WithOAuth2Token(config.Token),
-			gitprovider.WithConditionalRequests(true),
-		}
+		opts = append(opts, gitprovider.WithOAuth2Token(config.Token))
 
 		// Quirk, see above
 		hostname := gitlab.DefaultDomain
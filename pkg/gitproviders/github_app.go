@@ -0,0 +1,172 @@
+package gitproviders
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fluxcd/go-git-providers/github"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// githubAppJWTLifetime is below the 10 minutes allowed by GitHub to leave room for clock drift.
+	githubAppJWTLifetime = 9 * time.Minute
+	// githubAppTokenRefreshMargin is how long before their expiry installation tokens are renewed.
+	githubAppTokenRefreshMargin = 5 * time.Minute
+)
+
+// GitHubApp holds the credentials of the installation of a GitHub App.
+type GitHubApp struct {
+	AppID          int64
+	InstallationID int64
+	// PrivateKey is the PEM encoded private key of the app.
+	PrivateKey []byte
+}
+
+// GitHubAppFromEnv returns the GitHub App configured with the GITHUB_APP_ID, GITHUB_APP_INSTALLATION_ID
+// and GITHUB_APP_PRIVATE_KEY or GITHUB_APP_PRIVATE_KEY_PATH env vars, or nil when GITHUB_APP_ID isn't set.
+func GitHubAppFromEnv(lookupEnv func(key string) (string, bool)) (*GitHubApp, error) {
+	appID, ok := lookupEnv("GITHUB_APP_ID")
+	if !ok || appID == "" {
+		return nil, nil
+	}
+
+	app := &GitHubApp{}
+
+	var err error
+
+	if app.AppID, err = strconv.ParseInt(appID, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid GITHUB_APP_ID %q: %w", appID, err)
+	}
+
+	installationID, _ := lookupEnv("GITHUB_APP_INSTALLATION_ID")
+	if app.InstallationID, err = strconv.ParseInt(installationID, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid GITHUB_APP_INSTALLATION_ID %q: %w", installationID, err)
+	}
+
+	if key, ok := lookupEnv("GITHUB_APP_PRIVATE_KEY"); ok && key != "" {
+		app.PrivateKey = []byte(key)
+	} else if path, ok := lookupEnv("GITHUB_APP_PRIVATE_KEY_PATH"); ok && path != "" {
+		if app.PrivateKey, err = ioutil.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("error reading GitHub App private key: %w", err)
+		}
+	} else {
+		return nil, fmt.Errorf("GITHUB_APP_PRIVATE_KEY or GITHUB_APP_PRIVATE_KEY_PATH needs to be set along with GITHUB_APP_ID")
+	}
+
+	return app, nil
+}
+
+// GitHubAppTokenSource exchanges the JWT of a GitHub App for short-lived installation tokens,
+// which are cached and renewed shortly before they expire. It is safe for concurrent use.
+type GitHubAppTokenSource struct {
+	app    GitHubApp
+	key    *rsa.PrivateKey
+	client restClient
+	now    func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewGitHubAppTokenSource returns the token source of a GitHub App installed on github.com, or on the
+// GitHub Enterprise server of hostname.
+func NewGitHubAppTokenSource(app GitHubApp, hostname string) (*GitHubAppTokenSource, error) {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(app.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub App private key: %w", err)
+	}
+
+	baseURL := "https://api.github.com"
+	if hostname != "" && hostname != github.DefaultDomain {
+		baseURL = "https://" + apiHostname(hostname) + "/api/v3"
+	}
+
+	return &GitHubAppTokenSource{
+		app: app,
+		key: key,
+		client: restClient{
+			baseURL: baseURL,
+			http:    http.DefaultClient,
+		},
+		now: time.Now,
+	}, nil
+}
+
+// Token returns an installation token valid for a few minutes at least.
+func (s *GitHubAppTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Add(githubAppTokenRefreshMargin).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	appJWT, err := s.appJWT()
+	if err != nil {
+		return "", err
+	}
+
+	// the app authenticates with its JWT to create installation tokens
+	client := s.client
+	client.authorization = "Bearer " + appJWT
+
+	var res struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	if err := client.post(ctx, fmt.Sprintf("/app/installations/%d/access_tokens", s.app.InstallationID), nil, &res); err != nil {
+		return "", fmt.Errorf("error creating GitHub App installation token: %w", err)
+	}
+
+	s.token, s.expiresAt = res.Token, res.ExpiresAt
+
+	return s.token, nil
+}
+
+func (s *GitHubAppTokenSource) appJWT() (string, error) {
+	now := s.now()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.StandardClaims{
+		// backdated in case the clock of GitHub is behind
+		IssuedAt:  now.Add(-time.Minute).Unix(),
+		ExpiresAt: now.Add(githubAppJWTLifetime).Unix(),
+		Issuer:    strconv.FormatInt(s.app.AppID, 10),
+	})
+
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("error signing GitHub App JWT: %w", err)
+	}
+
+	return signed, nil
+}
+
+// transport returns an http.RoundTripper authenticating the requests with installation tokens.
+func (s *GitHubAppTokenSource) transport(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		token, err := s.Token(req.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "token "+token)
+
+		return next.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
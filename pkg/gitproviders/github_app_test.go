@@ -0,0 +1,180 @@
+package gitproviders
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	"github.com/golang-jwt/jwt/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GitHub App", func() {
+	var (
+		key        *rsa.PrivateKey
+		privateKey []byte
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+
+		privateKey = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	})
+
+	Describe("GitHubAppFromEnv", func() {
+		lookupEnv := func(env map[string]string) func(string) (string, bool) {
+			return func(key string) (string, bool) {
+				v, ok := env[key]
+				return v, ok
+			}
+		}
+
+		It("returns nil without an app ID", func() {
+			app, err := GitHubAppFromEnv(lookupEnv(map[string]string{}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(app).To(BeNil())
+		})
+
+		It("reads the private key from a file", func() {
+			dir, err := ioutil.TempDir("", "github-app-")
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(os.RemoveAll, dir)
+
+			path := filepath.Join(dir, "key.pem")
+			Expect(ioutil.WriteFile(path, privateKey, 0600)).To(Succeed())
+
+			app, err := GitHubAppFromEnv(lookupEnv(map[string]string{
+				"GITHUB_APP_ID":               "12",
+				"GITHUB_APP_INSTALLATION_ID":  "34",
+				"GITHUB_APP_PRIVATE_KEY_PATH": path,
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(app).To(Equal(&GitHubApp{AppID: 12, InstallationID: 34, PrivateKey: privateKey}))
+		})
+
+		It("requires the installation and private key", func() {
+			_, err := GitHubAppFromEnv(lookupEnv(map[string]string{"GITHUB_APP_ID": "12"}))
+			Expect(err).To(MatchError(ContainSubstring(`invalid GITHUB_APP_INSTALLATION_ID ""`)))
+
+			_, err = GitHubAppFromEnv(lookupEnv(map[string]string{"GITHUB_APP_ID": "12", "GITHUB_APP_INSTALLATION_ID": "34"}))
+			Expect(err).To(MatchError("GITHUB_APP_PRIVATE_KEY or GITHUB_APP_PRIVATE_KEY_PATH needs to be set along with GITHUB_APP_ID"))
+		})
+	})
+
+	Describe("GitHubAppTokenSource", func() {
+		var (
+			source   *GitHubAppTokenSource
+			now      time.Time
+			requests []*http.Request
+		)
+
+		BeforeEach(func() {
+			now = time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+			requests = nil
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r)
+
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": %q}`, len(requests), now.Add(time.Hour).Format(time.RFC3339))
+			}))
+			DeferCleanup(server.Close)
+
+			var err error
+
+			source, err = NewGitHubAppTokenSource(GitHubApp{AppID: 12, InstallationID: 34, PrivateKey: privateKey}, "github.com")
+			Expect(err).NotTo(HaveOccurred())
+
+			source.client.baseURL = server.URL
+			source.now = func() time.Time { return now }
+		})
+
+		It("rejects invalid private keys", func() {
+			_, err := NewGitHubAppTokenSource(GitHubApp{PrivateKey: []byte("not a key")}, "github.com")
+			Expect(err).To(MatchError(ContainSubstring("invalid GitHub App private key")))
+		})
+
+		It("uses the API of GitHub Enterprise servers", func() {
+			s, err := NewGitHubAppTokenSource(GitHubApp{PrivateKey: privateKey}, "github.example.com")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.client.baseURL).To(Equal("https://github.example.com/api/v3"))
+		})
+
+		It("exchanges the JWT of the app for an installation token", func() {
+			token, err := source.Token(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("ghs_1"))
+
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Method).To(Equal(http.MethodPost))
+			Expect(requests[0].URL.Path).To(Equal("/app/installations/34/access_tokens"))
+
+			claims := jwt.StandardClaims{}
+			parser := &jwt.Parser{SkipClaimsValidation: true}
+			_, err = parser.ParseWithClaims(strings.TrimPrefix(requests[0].Header.Get("Authorization"), "Bearer "), &claims, func(t *jwt.Token) (interface{}, error) {
+				Expect(t.Method).To(Equal(jwt.SigningMethodRS256))
+				return &key.PublicKey, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(claims.Issuer).To(Equal("12"))
+			Expect(claims.IssuedAt).To(Equal(now.Add(-time.Minute).Unix()))
+			Expect(claims.ExpiresAt).To(Equal(now.Add(9 * time.Minute).Unix()))
+		})
+
+		It("caches the token until shortly before it expires", func() {
+			token, err := source.Token(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("ghs_1"))
+
+			now = now.Add(50 * time.Minute)
+			token, err = source.Token(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("ghs_1"))
+
+			now = now.Add(6 * time.Minute)
+			token, err = source.Token(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("ghs_2"))
+			Expect(requests).To(HaveLen(2))
+		})
+
+		It("authenticates the requests of the GitHub client", func() {
+			var authorization string
+
+			transport := source.transport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				authorization = req.Header.Get("Authorization")
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			}))
+
+			req, err := http.NewRequest(http.MethodGet, "https://api.github.com/user", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = transport.RoundTrip(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authorization).To(Equal("token ghs_1"))
+		})
+
+		It("builds GitHub providers without a token", func() {
+			provider, err := New(Config{Provider: GitProviderGitHub, GitHubApp: source}, "weaveworks", func(_ gitprovider.Client, _ string, _ string) (ProviderAccountType, error) {
+				return AccountTypeOrg, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(provider.GetProviderDomain()).To(Equal("github.com"))
+		})
+	})
+})
@@ -22,6 +22,19 @@ type FakeClient struct {
 		result1 gitproviders.GitProvider
 		result2 error
 	}
+	GetTokenStub        func(gitproviders.RepoURL) (string, error)
+	getTokenMutex       sync.RWMutex
+	getTokenArgsForCall []struct {
+		arg1 gitproviders.RepoURL
+	}
+	getTokenReturns struct {
+		result1 string
+		result2 error
+	}
+	getTokenReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -91,11 +104,77 @@ func (fake *FakeClient) GetProviderReturnsOnCall(i int, result1 gitproviders.Git
 	}{result1, result2}
 }
 
+func (fake *FakeClient) GetToken(arg1 gitproviders.RepoURL) (string, error) {
+	fake.getTokenMutex.Lock()
+	ret, specificReturn := fake.getTokenReturnsOnCall[len(fake.getTokenArgsForCall)]
+	fake.getTokenArgsForCall = append(fake.getTokenArgsForCall, struct {
+		arg1 gitproviders.RepoURL
+	}{arg1})
+	stub := fake.GetTokenStub
+	fakeReturns := fake.getTokenReturns
+	fake.recordInvocation("GetToken", []interface{}{arg1})
+	fake.getTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) GetTokenCallCount() int {
+	fake.getTokenMutex.RLock()
+	defer fake.getTokenMutex.RUnlock()
+	return len(fake.getTokenArgsForCall)
+}
+
+func (fake *FakeClient) GetTokenCalls(stub func(gitproviders.RepoURL) (string, error)) {
+	fake.getTokenMutex.Lock()
+	defer fake.getTokenMutex.Unlock()
+	fake.GetTokenStub = stub
+}
+
+func (fake *FakeClient) GetTokenArgsForCall(i int) gitproviders.RepoURL {
+	fake.getTokenMutex.RLock()
+	defer fake.getTokenMutex.RUnlock()
+	argsForCall := fake.getTokenArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) GetTokenReturns(result1 string, result2 error) {
+	fake.getTokenMutex.Lock()
+	defer fake.getTokenMutex.Unlock()
+	fake.GetTokenStub = nil
+	fake.getTokenReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) GetTokenReturnsOnCall(i int, result1 string, result2 error) {
+	fake.getTokenMutex.Lock()
+	defer fake.getTokenMutex.Unlock()
+	fake.GetTokenStub = nil
+	if fake.getTokenReturnsOnCall == nil {
+		fake.getTokenReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.getTokenReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.getProviderMutex.RLock()
 	defer fake.getProviderMutex.RUnlock()
+	fake.getTokenMutex.RLock()
+	defer fake.getTokenMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -6,10 +6,26 @@ import (
 	"sync"
 
 	"github.com/fluxcd/go-git-providers/gitprovider"
+	"github.com/weaveworks/weave-gitops/pkg/git"
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 )
 
 type FakeGitProvider struct {
+	CommitFilesStub        func(context.Context, gitproviders.RepoURL, string, string, []gitprovider.CommitFile) error
+	commitFilesMutex       sync.RWMutex
+	commitFilesArgsForCall []struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+		arg3 string
+		arg4 string
+		arg5 []gitprovider.CommitFile
+	}
+	commitFilesReturns struct {
+		result1 error
+	}
+	commitFilesReturnsOnCall map[int]struct {
+		result1 error
+	}
 	CreatePullRequestStub        func(context.Context, gitproviders.RepoURL, gitproviders.PullRequestInfo) (gitprovider.PullRequest, error)
 	createPullRequestMutex       sync.RWMutex
 	createPullRequestArgsForCall []struct {
@@ -25,6 +41,32 @@ type FakeGitProvider struct {
 		result1 gitprovider.PullRequest
 		result2 error
 	}
+	DeleteBranchStub        func(context.Context, gitproviders.RepoURL, string) error
+	deleteBranchMutex       sync.RWMutex
+	deleteBranchArgsForCall []struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+		arg3 string
+	}
+	deleteBranchReturns struct {
+		result1 error
+	}
+	deleteBranchReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteDeployKeyStub        func(context.Context, gitproviders.RepoURL, string) error
+	deleteDeployKeyMutex       sync.RWMutex
+	deleteDeployKeyArgsForCall []struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+		arg3 string
+	}
+	deleteDeployKeyReturns struct {
+		result1 error
+	}
+	deleteDeployKeyReturnsOnCall map[int]struct {
+		result1 error
+	}
 	DeployKeyExistsStub        func(context.Context, gitproviders.RepoURL) (bool, error)
 	deployKeyExistsMutex       sync.RWMutex
 	deployKeyExistsArgsForCall []struct {
@@ -39,6 +81,34 @@ type FakeGitProvider struct {
 		result1 bool
 		result2 error
 	}
+	GetAuthenticatedUserStub        func(context.Context) (git.Author, error)
+	getAuthenticatedUserMutex       sync.RWMutex
+	getAuthenticatedUserArgsForCall []struct {
+		arg1 context.Context
+	}
+	getAuthenticatedUserReturns struct {
+		result1 git.Author
+		result2 error
+	}
+	getAuthenticatedUserReturnsOnCall map[int]struct {
+		result1 git.Author
+		result2 error
+	}
+	GetClosedPullRequestStub        func(context.Context, gitproviders.RepoURL, string) (gitprovider.PullRequest, error)
+	getClosedPullRequestMutex       sync.RWMutex
+	getClosedPullRequestArgsForCall []struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+		arg3 string
+	}
+	getClosedPullRequestReturns struct {
+		result1 gitprovider.PullRequest
+		result2 error
+	}
+	getClosedPullRequestReturnsOnCall map[int]struct {
+		result1 gitprovider.PullRequest
+		result2 error
+	}
 	GetCommitsStub        func(context.Context, gitproviders.RepoURL, string, int, int) ([]gitprovider.Commit, error)
 	getCommitsMutex       sync.RWMutex
 	getCommitsArgsForCall []struct {
@@ -70,6 +140,21 @@ type FakeGitProvider struct {
 		result1 string
 		result2 error
 	}
+	GetOpenPullRequestStub        func(context.Context, gitproviders.RepoURL, string) (gitprovider.PullRequest, error)
+	getOpenPullRequestMutex       sync.RWMutex
+	getOpenPullRequestArgsForCall []struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+		arg3 string
+	}
+	getOpenPullRequestReturns struct {
+		result1 gitprovider.PullRequest
+		result2 error
+	}
+	getOpenPullRequestReturnsOnCall map[int]struct {
+		result1 gitprovider.PullRequest
+		result2 error
+	}
 	GetProviderDomainStub        func() string
 	getProviderDomainMutex       sync.RWMutex
 	getProviderDomainArgsForCall []struct {
@@ -80,6 +165,21 @@ type FakeGitProvider struct {
 	getProviderDomainReturnsOnCall map[int]struct {
 		result1 string
 	}
+	GetPullRequestStatusStub        func(context.Context, gitproviders.RepoURL, int) (gitproviders.PullRequestStatus, error)
+	getPullRequestStatusMutex       sync.RWMutex
+	getPullRequestStatusArgsForCall []struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+		arg3 int
+	}
+	getPullRequestStatusReturns struct {
+		result1 gitproviders.PullRequestStatus
+		result2 error
+	}
+	getPullRequestStatusReturnsOnCall map[int]struct {
+		result1 gitproviders.PullRequestStatus
+		result2 error
+	}
 	GetRepoDirFilesStub        func(context.Context, gitproviders.RepoURL, string, string) ([]*gitprovider.CommitFile, error)
 	getRepoDirFilesMutex       sync.RWMutex
 	getRepoDirFilesArgsForCall []struct {
@@ -110,13 +210,28 @@ type FakeGitProvider struct {
 		result1 *gitprovider.RepositoryVisibility
 		result2 error
 	}
-	MergePullRequestStub        func(context.Context, gitproviders.RepoURL, int, string) error
+	ListDeployKeysStub        func(context.Context, gitproviders.RepoURL) ([]gitproviders.DeployKey, error)
+	listDeployKeysMutex       sync.RWMutex
+	listDeployKeysArgsForCall []struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+	}
+	listDeployKeysReturns struct {
+		result1 []gitproviders.DeployKey
+		result2 error
+	}
+	listDeployKeysReturnsOnCall map[int]struct {
+		result1 []gitproviders.DeployKey
+		result2 error
+	}
+	MergePullRequestStub        func(context.Context, gitproviders.RepoURL, int, gitprovider.MergeMethod, string) error
 	mergePullRequestMutex       sync.RWMutex
 	mergePullRequestArgsForCall []struct {
 		arg1 context.Context
 		arg2 gitproviders.RepoURL
 		arg3 int
-		arg4 string
+		arg4 gitprovider.MergeMethod
+		arg5 string
 	}
 	mergePullRequestReturns struct {
 		result1 error
@@ -151,10 +266,94 @@ type FakeGitProvider struct {
 	uploadDeployKeyReturnsOnCall map[int]struct {
 		result1 error
 	}
+	UploadNamedDeployKeyStub        func(context.Context, gitproviders.RepoURL, string, []byte) error
+	uploadNamedDeployKeyMutex       sync.RWMutex
+	uploadNamedDeployKeyArgsForCall []struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+		arg3 string
+		arg4 []byte
+	}
+	uploadNamedDeployKeyReturns struct {
+		result1 error
+	}
+	uploadNamedDeployKeyReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeGitProvider) CommitFiles(arg1 context.Context, arg2 gitproviders.RepoURL, arg3 string, arg4 string, arg5 []gitprovider.CommitFile) error {
+	var arg5Copy []gitprovider.CommitFile
+	if arg5 != nil {
+		arg5Copy = make([]gitprovider.CommitFile, len(arg5))
+		copy(arg5Copy, arg5)
+	}
+	fake.commitFilesMutex.Lock()
+	ret, specificReturn := fake.commitFilesReturnsOnCall[len(fake.commitFilesArgsForCall)]
+	fake.commitFilesArgsForCall = append(fake.commitFilesArgsForCall, struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+		arg3 string
+		arg4 string
+		arg5 []gitprovider.CommitFile
+	}{arg1, arg2, arg3, arg4, arg5Copy})
+	stub := fake.CommitFilesStub
+	fakeReturns := fake.commitFilesReturns
+	fake.recordInvocation("CommitFiles", []interface{}{arg1, arg2, arg3, arg4, arg5Copy})
+	fake.commitFilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeGitProvider) CommitFilesCallCount() int {
+	fake.commitFilesMutex.RLock()
+	defer fake.commitFilesMutex.RUnlock()
+	return len(fake.commitFilesArgsForCall)
+}
+
+func (fake *FakeGitProvider) CommitFilesCalls(stub func(context.Context, gitproviders.RepoURL, string, string, []gitprovider.CommitFile) error) {
+	fake.commitFilesMutex.Lock()
+	defer fake.commitFilesMutex.Unlock()
+	fake.CommitFilesStub = stub
+}
+
+func (fake *FakeGitProvider) CommitFilesArgsForCall(i int) (context.Context, gitproviders.RepoURL, string, string, []gitprovider.CommitFile) {
+	fake.commitFilesMutex.RLock()
+	defer fake.commitFilesMutex.RUnlock()
+	argsForCall := fake.commitFilesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeGitProvider) CommitFilesReturns(result1 error) {
+	fake.commitFilesMutex.Lock()
+	defer fake.commitFilesMutex.Unlock()
+	fake.CommitFilesStub = nil
+	fake.commitFilesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGitProvider) CommitFilesReturnsOnCall(i int, result1 error) {
+	fake.commitFilesMutex.Lock()
+	defer fake.commitFilesMutex.Unlock()
+	fake.CommitFilesStub = nil
+	if fake.commitFilesReturnsOnCall == nil {
+		fake.commitFilesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.commitFilesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeGitProvider) CreatePullRequest(arg1 context.Context, arg2 gitproviders.RepoURL, arg3 gitproviders.PullRequestInfo) (gitprovider.PullRequest, error) {
 	fake.createPullRequestMutex.Lock()
 	ret, specificReturn := fake.createPullRequestReturnsOnCall[len(fake.createPullRequestArgsForCall)]
@@ -221,6 +420,132 @@ func (fake *FakeGitProvider) CreatePullRequestReturnsOnCall(i int, result1 gitpr
 	}{result1, result2}
 }
 
+func (fake *FakeGitProvider) DeleteBranch(arg1 context.Context, arg2 gitproviders.RepoURL, arg3 string) error {
+	fake.deleteBranchMutex.Lock()
+	ret, specificReturn := fake.deleteBranchReturnsOnCall[len(fake.deleteBranchArgsForCall)]
+	fake.deleteBranchArgsForCall = append(fake.deleteBranchArgsForCall, struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.DeleteBranchStub
+	fakeReturns := fake.deleteBranchReturns
+	fake.recordInvocation("DeleteBranch", []interface{}{arg1, arg2, arg3})
+	fake.deleteBranchMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeGitProvider) DeleteBranchCallCount() int {
+	fake.deleteBranchMutex.RLock()
+	defer fake.deleteBranchMutex.RUnlock()
+	return len(fake.deleteBranchArgsForCall)
+}
+
+func (fake *FakeGitProvider) DeleteBranchCalls(stub func(context.Context, gitproviders.RepoURL, string) error) {
+	fake.deleteBranchMutex.Lock()
+	defer fake.deleteBranchMutex.Unlock()
+	fake.DeleteBranchStub = stub
+}
+
+func (fake *FakeGitProvider) DeleteBranchArgsForCall(i int) (context.Context, gitproviders.RepoURL, string) {
+	fake.deleteBranchMutex.RLock()
+	defer fake.deleteBranchMutex.RUnlock()
+	argsForCall := fake.deleteBranchArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeGitProvider) DeleteBranchReturns(result1 error) {
+	fake.deleteBranchMutex.Lock()
+	defer fake.deleteBranchMutex.Unlock()
+	fake.DeleteBranchStub = nil
+	fake.deleteBranchReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGitProvider) DeleteBranchReturnsOnCall(i int, result1 error) {
+	fake.deleteBranchMutex.Lock()
+	defer fake.deleteBranchMutex.Unlock()
+	fake.DeleteBranchStub = nil
+	if fake.deleteBranchReturnsOnCall == nil {
+		fake.deleteBranchReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteBranchReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGitProvider) DeleteDeployKey(arg1 context.Context, arg2 gitproviders.RepoURL, arg3 string) error {
+	fake.deleteDeployKeyMutex.Lock()
+	ret, specificReturn := fake.deleteDeployKeyReturnsOnCall[len(fake.deleteDeployKeyArgsForCall)]
+	fake.deleteDeployKeyArgsForCall = append(fake.deleteDeployKeyArgsForCall, struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.DeleteDeployKeyStub
+	fakeReturns := fake.deleteDeployKeyReturns
+	fake.recordInvocation("DeleteDeployKey", []interface{}{arg1, arg2, arg3})
+	fake.deleteDeployKeyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeGitProvider) DeleteDeployKeyCallCount() int {
+	fake.deleteDeployKeyMutex.RLock()
+	defer fake.deleteDeployKeyMutex.RUnlock()
+	return len(fake.deleteDeployKeyArgsForCall)
+}
+
+func (fake *FakeGitProvider) DeleteDeployKeyCalls(stub func(context.Context, gitproviders.RepoURL, string) error) {
+	fake.deleteDeployKeyMutex.Lock()
+	defer fake.deleteDeployKeyMutex.Unlock()
+	fake.DeleteDeployKeyStub = stub
+}
+
+func (fake *FakeGitProvider) DeleteDeployKeyArgsForCall(i int) (context.Context, gitproviders.RepoURL, string) {
+	fake.deleteDeployKeyMutex.RLock()
+	defer fake.deleteDeployKeyMutex.RUnlock()
+	argsForCall := fake.deleteDeployKeyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeGitProvider) DeleteDeployKeyReturns(result1 error) {
+	fake.deleteDeployKeyMutex.Lock()
+	defer fake.deleteDeployKeyMutex.Unlock()
+	fake.DeleteDeployKeyStub = nil
+	fake.deleteDeployKeyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGitProvider) DeleteDeployKeyReturnsOnCall(i int, result1 error) {
+	fake.deleteDeployKeyMutex.Lock()
+	defer fake.deleteDeployKeyMutex.Unlock()
+	fake.DeleteDeployKeyStub = nil
+	if fake.deleteDeployKeyReturnsOnCall == nil {
+		fake.deleteDeployKeyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteDeployKeyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeGitProvider) DeployKeyExists(arg1 context.Context, arg2 gitproviders.RepoURL) (bool, error) {
 	fake.deployKeyExistsMutex.Lock()
 	ret, specificReturn := fake.deployKeyExistsReturnsOnCall[len(fake.deployKeyExistsArgsForCall)]
@@ -286,6 +611,136 @@ func (fake *FakeGitProvider) DeployKeyExistsReturnsOnCall(i int, result1 bool, r
 	}{result1, result2}
 }
 
+func (fake *FakeGitProvider) GetAuthenticatedUser(arg1 context.Context) (git.Author, error) {
+	fake.getAuthenticatedUserMutex.Lock()
+	ret, specificReturn := fake.getAuthenticatedUserReturnsOnCall[len(fake.getAuthenticatedUserArgsForCall)]
+	fake.getAuthenticatedUserArgsForCall = append(fake.getAuthenticatedUserArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.GetAuthenticatedUserStub
+	fakeReturns := fake.getAuthenticatedUserReturns
+	fake.recordInvocation("GetAuthenticatedUser", []interface{}{arg1})
+	fake.getAuthenticatedUserMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitProvider) GetAuthenticatedUserCallCount() int {
+	fake.getAuthenticatedUserMutex.RLock()
+	defer fake.getAuthenticatedUserMutex.RUnlock()
+	return len(fake.getAuthenticatedUserArgsForCall)
+}
+
+func (fake *FakeGitProvider) GetAuthenticatedUserCalls(stub func(context.Context) (git.Author, error)) {
+	fake.getAuthenticatedUserMutex.Lock()
+	defer fake.getAuthenticatedUserMutex.Unlock()
+	fake.GetAuthenticatedUserStub = stub
+}
+
+func (fake *FakeGitProvider) GetAuthenticatedUserArgsForCall(i int) context.Context {
+	fake.getAuthenticatedUserMutex.RLock()
+	defer fake.getAuthenticatedUserMutex.RUnlock()
+	argsForCall := fake.getAuthenticatedUserArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeGitProvider) GetAuthenticatedUserReturns(result1 git.Author, result2 error) {
+	fake.getAuthenticatedUserMutex.Lock()
+	defer fake.getAuthenticatedUserMutex.Unlock()
+	fake.GetAuthenticatedUserStub = nil
+	fake.getAuthenticatedUserReturns = struct {
+		result1 git.Author
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitProvider) GetAuthenticatedUserReturnsOnCall(i int, result1 git.Author, result2 error) {
+	fake.getAuthenticatedUserMutex.Lock()
+	defer fake.getAuthenticatedUserMutex.Unlock()
+	fake.GetAuthenticatedUserStub = nil
+	if fake.getAuthenticatedUserReturnsOnCall == nil {
+		fake.getAuthenticatedUserReturnsOnCall = make(map[int]struct {
+			result1 git.Author
+			result2 error
+		})
+	}
+	fake.getAuthenticatedUserReturnsOnCall[i] = struct {
+		result1 git.Author
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitProvider) GetClosedPullRequest(arg1 context.Context, arg2 gitproviders.RepoURL, arg3 string) (gitprovider.PullRequest, error) {
+	fake.getClosedPullRequestMutex.Lock()
+	ret, specificReturn := fake.getClosedPullRequestReturnsOnCall[len(fake.getClosedPullRequestArgsForCall)]
+	fake.getClosedPullRequestArgsForCall = append(fake.getClosedPullRequestArgsForCall, struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.GetClosedPullRequestStub
+	fakeReturns := fake.getClosedPullRequestReturns
+	fake.recordInvocation("GetClosedPullRequest", []interface{}{arg1, arg2, arg3})
+	fake.getClosedPullRequestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitProvider) GetClosedPullRequestCallCount() int {
+	fake.getClosedPullRequestMutex.RLock()
+	defer fake.getClosedPullRequestMutex.RUnlock()
+	return len(fake.getClosedPullRequestArgsForCall)
+}
+
+func (fake *FakeGitProvider) GetClosedPullRequestCalls(stub func(context.Context, gitproviders.RepoURL, string) (gitprovider.PullRequest, error)) {
+	fake.getClosedPullRequestMutex.Lock()
+	defer fake.getClosedPullRequestMutex.Unlock()
+	fake.GetClosedPullRequestStub = stub
+}
+
+func (fake *FakeGitProvider) GetClosedPullRequestArgsForCall(i int) (context.Context, gitproviders.RepoURL, string) {
+	fake.getClosedPullRequestMutex.RLock()
+	defer fake.getClosedPullRequestMutex.RUnlock()
+	argsForCall := fake.getClosedPullRequestArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeGitProvider) GetClosedPullRequestReturns(result1 gitprovider.PullRequest, result2 error) {
+	fake.getClosedPullRequestMutex.Lock()
+	defer fake.getClosedPullRequestMutex.Unlock()
+	fake.GetClosedPullRequestStub = nil
+	fake.getClosedPullRequestReturns = struct {
+		result1 gitprovider.PullRequest
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitProvider) GetClosedPullRequestReturnsOnCall(i int, result1 gitprovider.PullRequest, result2 error) {
+	fake.getClosedPullRequestMutex.Lock()
+	defer fake.getClosedPullRequestMutex.Unlock()
+	fake.GetClosedPullRequestStub = nil
+	if fake.getClosedPullRequestReturnsOnCall == nil {
+		fake.getClosedPullRequestReturnsOnCall = make(map[int]struct {
+			result1 gitprovider.PullRequest
+			result2 error
+		})
+	}
+	fake.getClosedPullRequestReturnsOnCall[i] = struct {
+		result1 gitprovider.PullRequest
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeGitProvider) GetCommits(arg1 context.Context, arg2 gitproviders.RepoURL, arg3 string, arg4 int, arg5 int) ([]gitprovider.Commit, error) {
 	fake.getCommitsMutex.Lock()
 	ret, specificReturn := fake.getCommitsReturnsOnCall[len(fake.getCommitsArgsForCall)]
@@ -419,6 +874,72 @@ func (fake *FakeGitProvider) GetDefaultBranchReturnsOnCall(i int, result1 string
 	}{result1, result2}
 }
 
+func (fake *FakeGitProvider) GetOpenPullRequest(arg1 context.Context, arg2 gitproviders.RepoURL, arg3 string) (gitprovider.PullRequest, error) {
+	fake.getOpenPullRequestMutex.Lock()
+	ret, specificReturn := fake.getOpenPullRequestReturnsOnCall[len(fake.getOpenPullRequestArgsForCall)]
+	fake.getOpenPullRequestArgsForCall = append(fake.getOpenPullRequestArgsForCall, struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.GetOpenPullRequestStub
+	fakeReturns := fake.getOpenPullRequestReturns
+	fake.recordInvocation("GetOpenPullRequest", []interface{}{arg1, arg2, arg3})
+	fake.getOpenPullRequestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitProvider) GetOpenPullRequestCallCount() int {
+	fake.getOpenPullRequestMutex.RLock()
+	defer fake.getOpenPullRequestMutex.RUnlock()
+	return len(fake.getOpenPullRequestArgsForCall)
+}
+
+func (fake *FakeGitProvider) GetOpenPullRequestCalls(stub func(context.Context, gitproviders.RepoURL, string) (gitprovider.PullRequest, error)) {
+	fake.getOpenPullRequestMutex.Lock()
+	defer fake.getOpenPullRequestMutex.Unlock()
+	fake.GetOpenPullRequestStub = stub
+}
+
+func (fake *FakeGitProvider) GetOpenPullRequestArgsForCall(i int) (context.Context, gitproviders.RepoURL, string) {
+	fake.getOpenPullRequestMutex.RLock()
+	defer fake.getOpenPullRequestMutex.RUnlock()
+	argsForCall := fake.getOpenPullRequestArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeGitProvider) GetOpenPullRequestReturns(result1 gitprovider.PullRequest, result2 error) {
+	fake.getOpenPullRequestMutex.Lock()
+	defer fake.getOpenPullRequestMutex.Unlock()
+	fake.GetOpenPullRequestStub = nil
+	fake.getOpenPullRequestReturns = struct {
+		result1 gitprovider.PullRequest
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitProvider) GetOpenPullRequestReturnsOnCall(i int, result1 gitprovider.PullRequest, result2 error) {
+	fake.getOpenPullRequestMutex.Lock()
+	defer fake.getOpenPullRequestMutex.Unlock()
+	fake.GetOpenPullRequestStub = nil
+	if fake.getOpenPullRequestReturnsOnCall == nil {
+		fake.getOpenPullRequestReturnsOnCall = make(map[int]struct {
+			result1 gitprovider.PullRequest
+			result2 error
+		})
+	}
+	fake.getOpenPullRequestReturnsOnCall[i] = struct {
+		result1 gitprovider.PullRequest
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeGitProvider) GetProviderDomain() string {
 	fake.getProviderDomainMutex.Lock()
 	ret, specificReturn := fake.getProviderDomainReturnsOnCall[len(fake.getProviderDomainArgsForCall)]
@@ -472,6 +993,72 @@ func (fake *FakeGitProvider) GetProviderDomainReturnsOnCall(i int, result1 strin
 	}{result1}
 }
 
+func (fake *FakeGitProvider) GetPullRequestStatus(arg1 context.Context, arg2 gitproviders.RepoURL, arg3 int) (gitproviders.PullRequestStatus, error) {
+	fake.getPullRequestStatusMutex.Lock()
+	ret, specificReturn := fake.getPullRequestStatusReturnsOnCall[len(fake.getPullRequestStatusArgsForCall)]
+	fake.getPullRequestStatusArgsForCall = append(fake.getPullRequestStatusArgsForCall, struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+		arg3 int
+	}{arg1, arg2, arg3})
+	stub := fake.GetPullRequestStatusStub
+	fakeReturns := fake.getPullRequestStatusReturns
+	fake.recordInvocation("GetPullRequestStatus", []interface{}{arg1, arg2, arg3})
+	fake.getPullRequestStatusMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitProvider) GetPullRequestStatusCallCount() int {
+	fake.getPullRequestStatusMutex.RLock()
+	defer fake.getPullRequestStatusMutex.RUnlock()
+	return len(fake.getPullRequestStatusArgsForCall)
+}
+
+func (fake *FakeGitProvider) GetPullRequestStatusCalls(stub func(context.Context, gitproviders.RepoURL, int) (gitproviders.PullRequestStatus, error)) {
+	fake.getPullRequestStatusMutex.Lock()
+	defer fake.getPullRequestStatusMutex.Unlock()
+	fake.GetPullRequestStatusStub = stub
+}
+
+func (fake *FakeGitProvider) GetPullRequestStatusArgsForCall(i int) (context.Context, gitproviders.RepoURL, int) {
+	fake.getPullRequestStatusMutex.RLock()
+	defer fake.getPullRequestStatusMutex.RUnlock()
+	argsForCall := fake.getPullRequestStatusArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeGitProvider) GetPullRequestStatusReturns(result1 gitproviders.PullRequestStatus, result2 error) {
+	fake.getPullRequestStatusMutex.Lock()
+	defer fake.getPullRequestStatusMutex.Unlock()
+	fake.GetPullRequestStatusStub = nil
+	fake.getPullRequestStatusReturns = struct {
+		result1 gitproviders.PullRequestStatus
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitProvider) GetPullRequestStatusReturnsOnCall(i int, result1 gitproviders.PullRequestStatus, result2 error) {
+	fake.getPullRequestStatusMutex.Lock()
+	defer fake.getPullRequestStatusMutex.Unlock()
+	fake.GetPullRequestStatusStub = nil
+	if fake.getPullRequestStatusReturnsOnCall == nil {
+		fake.getPullRequestStatusReturnsOnCall = make(map[int]struct {
+			result1 gitproviders.PullRequestStatus
+			result2 error
+		})
+	}
+	fake.getPullRequestStatusReturnsOnCall[i] = struct {
+		result1 gitproviders.PullRequestStatus
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeGitProvider) GetRepoDirFiles(arg1 context.Context, arg2 gitproviders.RepoURL, arg3 string, arg4 string) ([]*gitprovider.CommitFile, error) {
 	fake.getRepoDirFilesMutex.Lock()
 	ret, specificReturn := fake.getRepoDirFilesReturnsOnCall[len(fake.getRepoDirFilesArgsForCall)]
@@ -604,21 +1191,87 @@ func (fake *FakeGitProvider) GetRepoVisibilityReturnsOnCall(i int, result1 *gitp
 	}{result1, result2}
 }
 
-func (fake *FakeGitProvider) MergePullRequest(arg1 context.Context, arg2 gitproviders.RepoURL, arg3 int, arg4 string) error {
+func (fake *FakeGitProvider) ListDeployKeys(arg1 context.Context, arg2 gitproviders.RepoURL) ([]gitproviders.DeployKey, error) {
+	fake.listDeployKeysMutex.Lock()
+	ret, specificReturn := fake.listDeployKeysReturnsOnCall[len(fake.listDeployKeysArgsForCall)]
+	fake.listDeployKeysArgsForCall = append(fake.listDeployKeysArgsForCall, struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+	}{arg1, arg2})
+	stub := fake.ListDeployKeysStub
+	fakeReturns := fake.listDeployKeysReturns
+	fake.recordInvocation("ListDeployKeys", []interface{}{arg1, arg2})
+	fake.listDeployKeysMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitProvider) ListDeployKeysCallCount() int {
+	fake.listDeployKeysMutex.RLock()
+	defer fake.listDeployKeysMutex.RUnlock()
+	return len(fake.listDeployKeysArgsForCall)
+}
+
+func (fake *FakeGitProvider) ListDeployKeysCalls(stub func(context.Context, gitproviders.RepoURL) ([]gitproviders.DeployKey, error)) {
+	fake.listDeployKeysMutex.Lock()
+	defer fake.listDeployKeysMutex.Unlock()
+	fake.ListDeployKeysStub = stub
+}
+
+func (fake *FakeGitProvider) ListDeployKeysArgsForCall(i int) (context.Context, gitproviders.RepoURL) {
+	fake.listDeployKeysMutex.RLock()
+	defer fake.listDeployKeysMutex.RUnlock()
+	argsForCall := fake.listDeployKeysArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGitProvider) ListDeployKeysReturns(result1 []gitproviders.DeployKey, result2 error) {
+	fake.listDeployKeysMutex.Lock()
+	defer fake.listDeployKeysMutex.Unlock()
+	fake.ListDeployKeysStub = nil
+	fake.listDeployKeysReturns = struct {
+		result1 []gitproviders.DeployKey
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitProvider) ListDeployKeysReturnsOnCall(i int, result1 []gitproviders.DeployKey, result2 error) {
+	fake.listDeployKeysMutex.Lock()
+	defer fake.listDeployKeysMutex.Unlock()
+	fake.ListDeployKeysStub = nil
+	if fake.listDeployKeysReturnsOnCall == nil {
+		fake.listDeployKeysReturnsOnCall = make(map[int]struct {
+			result1 []gitproviders.DeployKey
+			result2 error
+		})
+	}
+	fake.listDeployKeysReturnsOnCall[i] = struct {
+		result1 []gitproviders.DeployKey
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitProvider) MergePullRequest(arg1 context.Context, arg2 gitproviders.RepoURL, arg3 int, arg4 gitprovider.MergeMethod, arg5 string) error {
 	fake.mergePullRequestMutex.Lock()
 	ret, specificReturn := fake.mergePullRequestReturnsOnCall[len(fake.mergePullRequestArgsForCall)]
 	fake.mergePullRequestArgsForCall = append(fake.mergePullRequestArgsForCall, struct {
 		arg1 context.Context
 		arg2 gitproviders.RepoURL
 		arg3 int
-		arg4 string
-	}{arg1, arg2, arg3, arg4})
+		arg4 gitprovider.MergeMethod
+		arg5 string
+	}{arg1, arg2, arg3, arg4, arg5})
 	stub := fake.MergePullRequestStub
 	fakeReturns := fake.mergePullRequestReturns
-	fake.recordInvocation("MergePullRequest", []interface{}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("MergePullRequest", []interface{}{arg1, arg2, arg3, arg4, arg5})
 	fake.mergePullRequestMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4)
+		return stub(arg1, arg2, arg3, arg4, arg5)
 	}
 	if specificReturn {
 		return ret.result1
@@ -632,17 +1285,17 @@ func (fake *FakeGitProvider) MergePullRequestCallCount() int {
 	return len(fake.mergePullRequestArgsForCall)
 }
 
-func (fake *FakeGitProvider) MergePullRequestCalls(stub func(context.Context, gitproviders.RepoURL, int, string) error) {
+func (fake *FakeGitProvider) MergePullRequestCalls(stub func(context.Context, gitproviders.RepoURL, int, gitprovider.MergeMethod, string) error) {
 	fake.mergePullRequestMutex.Lock()
 	defer fake.mergePullRequestMutex.Unlock()
 	fake.MergePullRequestStub = stub
 }
 
-func (fake *FakeGitProvider) MergePullRequestArgsForCall(i int) (context.Context, gitproviders.RepoURL, int, string) {
+func (fake *FakeGitProvider) MergePullRequestArgsForCall(i int) (context.Context, gitproviders.RepoURL, int, gitprovider.MergeMethod, string) {
 	fake.mergePullRequestMutex.RLock()
 	defer fake.mergePullRequestMutex.RUnlock()
 	argsForCall := fake.mergePullRequestArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
 func (fake *FakeGitProvider) MergePullRequestReturns(result1 error) {
@@ -801,29 +1454,116 @@ func (fake *FakeGitProvider) UploadDeployKeyReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *FakeGitProvider) UploadNamedDeployKey(arg1 context.Context, arg2 gitproviders.RepoURL, arg3 string, arg4 []byte) error {
+	var arg4Copy []byte
+	if arg4 != nil {
+		arg4Copy = make([]byte, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.uploadNamedDeployKeyMutex.Lock()
+	ret, specificReturn := fake.uploadNamedDeployKeyReturnsOnCall[len(fake.uploadNamedDeployKeyArgsForCall)]
+	fake.uploadNamedDeployKeyArgsForCall = append(fake.uploadNamedDeployKeyArgsForCall, struct {
+		arg1 context.Context
+		arg2 gitproviders.RepoURL
+		arg3 string
+		arg4 []byte
+	}{arg1, arg2, arg3, arg4Copy})
+	stub := fake.UploadNamedDeployKeyStub
+	fakeReturns := fake.uploadNamedDeployKeyReturns
+	fake.recordInvocation("UploadNamedDeployKey", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.uploadNamedDeployKeyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeGitProvider) UploadNamedDeployKeyCallCount() int {
+	fake.uploadNamedDeployKeyMutex.RLock()
+	defer fake.uploadNamedDeployKeyMutex.RUnlock()
+	return len(fake.uploadNamedDeployKeyArgsForCall)
+}
+
+func (fake *FakeGitProvider) UploadNamedDeployKeyCalls(stub func(context.Context, gitproviders.RepoURL, string, []byte) error) {
+	fake.uploadNamedDeployKeyMutex.Lock()
+	defer fake.uploadNamedDeployKeyMutex.Unlock()
+	fake.UploadNamedDeployKeyStub = stub
+}
+
+func (fake *FakeGitProvider) UploadNamedDeployKeyArgsForCall(i int) (context.Context, gitproviders.RepoURL, string, []byte) {
+	fake.uploadNamedDeployKeyMutex.RLock()
+	defer fake.uploadNamedDeployKeyMutex.RUnlock()
+	argsForCall := fake.uploadNamedDeployKeyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeGitProvider) UploadNamedDeployKeyReturns(result1 error) {
+	fake.uploadNamedDeployKeyMutex.Lock()
+	defer fake.uploadNamedDeployKeyMutex.Unlock()
+	fake.UploadNamedDeployKeyStub = nil
+	fake.uploadNamedDeployKeyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGitProvider) UploadNamedDeployKeyReturnsOnCall(i int, result1 error) {
+	fake.uploadNamedDeployKeyMutex.Lock()
+	defer fake.uploadNamedDeployKeyMutex.Unlock()
+	fake.UploadNamedDeployKeyStub = nil
+	if fake.uploadNamedDeployKeyReturnsOnCall == nil {
+		fake.uploadNamedDeployKeyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.uploadNamedDeployKeyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeGitProvider) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.commitFilesMutex.RLock()
+	defer fake.commitFilesMutex.RUnlock()
 	fake.createPullRequestMutex.RLock()
 	defer fake.createPullRequestMutex.RUnlock()
+	fake.deleteBranchMutex.RLock()
+	defer fake.deleteBranchMutex.RUnlock()
+	fake.deleteDeployKeyMutex.RLock()
+	defer fake.deleteDeployKeyMutex.RUnlock()
 	fake.deployKeyExistsMutex.RLock()
 	defer fake.deployKeyExistsMutex.RUnlock()
+	fake.getAuthenticatedUserMutex.RLock()
+	defer fake.getAuthenticatedUserMutex.RUnlock()
+	fake.getClosedPullRequestMutex.RLock()
+	defer fake.getClosedPullRequestMutex.RUnlock()
 	fake.getCommitsMutex.RLock()
 	defer fake.getCommitsMutex.RUnlock()
 	fake.getDefaultBranchMutex.RLock()
 	defer fake.getDefaultBranchMutex.RUnlock()
+	fake.getOpenPullRequestMutex.RLock()
+	defer fake.getOpenPullRequestMutex.RUnlock()
 	fake.getProviderDomainMutex.RLock()
 	defer fake.getProviderDomainMutex.RUnlock()
+	fake.getPullRequestStatusMutex.RLock()
+	defer fake.getPullRequestStatusMutex.RUnlock()
 	fake.getRepoDirFilesMutex.RLock()
 	defer fake.getRepoDirFilesMutex.RUnlock()
 	fake.getRepoVisibilityMutex.RLock()
 	defer fake.getRepoVisibilityMutex.RUnlock()
+	fake.listDeployKeysMutex.RLock()
+	defer fake.listDeployKeysMutex.RUnlock()
 	fake.mergePullRequestMutex.RLock()
 	defer fake.mergePullRequestMutex.RUnlock()
 	fake.repositoryExistsMutex.RLock()
 	defer fake.repositoryExistsMutex.RUnlock()
 	fake.uploadDeployKeyMutex.RLock()
 	defer fake.uploadDeployKeyMutex.RUnlock()
+	fake.uploadNamedDeployKeyMutex.RLock()
+	defer fake.uploadNamedDeployKeyMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
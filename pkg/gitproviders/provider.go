@@ -4,13 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/weaveworks/weave-gitops/pkg/git"
 	"github.com/weaveworks/weave-gitops/pkg/utils"
 
 	"github.com/fluxcd/go-git-providers/gitprovider"
+	"github.com/google/go-github/v41/github"
+	"github.com/xanzy/go-gitlab"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
@@ -35,19 +39,35 @@ type GitProvider interface {
 	GetDefaultBranch(ctx context.Context, repoUrl RepoURL) (string, error)
 	GetRepoVisibility(ctx context.Context, repoUrl RepoURL) (*gitprovider.RepositoryVisibility, error)
 	UploadDeployKey(ctx context.Context, repoUrl RepoURL, deployKey []byte) error
+	// UploadNamedDeployKey uploads a deploy key replacing another one, named with RotatedDeployKeyName.
+	UploadNamedDeployKey(ctx context.Context, repoUrl RepoURL, name string, deployKey []byte) error
+	// ListDeployKeys returns the deploy keys of the repository uploaded by gitops.
+	ListDeployKeys(ctx context.Context, repoUrl RepoURL) ([]DeployKey, error)
+	DeleteDeployKey(ctx context.Context, repoUrl RepoURL, id string) error
 	CreatePullRequest(ctx context.Context, repoUrl RepoURL, prInfo PullRequestInfo) (gitprovider.PullRequest, error)
+	GetOpenPullRequest(ctx context.Context, repoUrl RepoURL, branch string) (gitprovider.PullRequest, error)
+	// GetClosedPullRequest returns the latest merged or closed pull request of a branch, or nil if there is none.
+	GetClosedPullRequest(ctx context.Context, repoUrl RepoURL, branch string) (gitprovider.PullRequest, error)
+	// DeleteBranch deletes a branch, it doesn't fail if the branch doesn't exist.
+	DeleteBranch(ctx context.Context, repoUrl RepoURL, branch string) error
+	CommitFiles(ctx context.Context, repoUrl RepoURL, branch, commitMessage string, files []gitprovider.CommitFile) error
 	GetCommits(ctx context.Context, repoUrl RepoURL, targetBranch string, pageSize int, pageToken int) ([]gitprovider.Commit, error)
 	GetProviderDomain() string
 	GetRepoDirFiles(ctx context.Context, repoUrl RepoURL, dirPath, targetBranch string) ([]*gitprovider.CommitFile, error)
-	MergePullRequest(ctx context.Context, repoUrl RepoURL, pullRequestNumber int, commitMesage string) error
+	GetPullRequestStatus(ctx context.Context, repoUrl RepoURL, pullRequestNumber int) (PullRequestStatus, error)
+	MergePullRequest(ctx context.Context, repoUrl RepoURL, pullRequestNumber int, mergeMethod gitprovider.MergeMethod, commitMesage string) error
+	GetAuthenticatedUser(ctx context.Context) (git.Author, error)
 }
 
 type PullRequestInfo struct {
-	Title                     string
-	Description               string
-	CommitMessage             string
-	TargetBranch              string
-	NewBranch                 string
+	Title         string
+	Description   string
+	CommitMessage string
+	TargetBranch  string
+	NewBranch     string
+	// GeneratedBranch tells that NewBranch was named by gitops rather than by the user, so that the branch of
+	// a merged or closed pull request can be reset for a new one.
+	GeneratedBranch           bool
 	SkipAddingFilesOnCreation bool
 	Files                     []gitprovider.CommitFile
 }
@@ -55,6 +75,17 @@ type PullRequestInfo struct {
 type AccountTypeGetter func(provider gitprovider.Client, domain string, owner string) (ProviderAccountType, error)
 
 func New(config Config, owner string, getAccountType AccountTypeGetter) (GitProvider, error) {
+	// go-git-providers has no client for these, their providers talk to the REST API or the git remote
+	// directly and handle user and organization repositories alike.
+	switch config.Provider {
+	case GitProviderBitbucketServer:
+		return newBitbucketServerGitProvider(config)
+	case GitProviderGitea:
+		return newGiteaGitProvider(config)
+	case GitProviderGit:
+		return newGitGitProvider(config), nil
+	}
+
 	provider, domain, err := buildGitProvider(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build git provider: %w", err)
@@ -82,7 +113,8 @@ func deployKeyExists(ctx context.Context, repo gitprovider.UserRepository) (bool
 	_, err := repo.DeployKeys().Get(ctx, DeployKeyName)
 	if err != nil && !strings.Contains(err.Error(), "key is already in use") {
 		if errors.Is(err, gitprovider.ErrNotFound) {
-			return false, nil
+			// the key may have been rotated
+			return managedDeployKeyExists(ctx, repo)
 		} else {
 			return false, fmt.Errorf("error getting deploy key %s: %s", DeployKeyName, err)
 		}
@@ -102,10 +134,10 @@ func uploadDeployKey(ctx context.Context, repo gitprovider.UserRepository, deplo
 	}
 
 	if err = utils.WaitUntil(os.Stdout, time.Second, defaultTimeout, func() error {
-		_, err = repo.DeployKeys().Get(ctx, DeployKeyName)
+		_, err = repo.DeployKeys().Get(ctx, deployKeyInfo.Name)
 		return err
 	}); err != nil {
-		return fmt.Errorf("error verifying deploy key %s: %s", DeployKeyName, err)
+		return fmt.Errorf("error verifying deploy key %s: %s", deployKeyInfo.Name, err)
 	}
 
 	return nil
@@ -154,6 +186,323 @@ func createPullRequest(ctx context.Context, repo gitprovider.UserRepository, prI
 	return pr, nil
 }
 
+// getOpenPullRequest returns the open pull request of a branch, or nil if there is none. go-git-providers
+// doesn't expose the source branch of pull requests, it is read from the objects of the GitHub and GitLab APIs.
+func getOpenPullRequest(ctx context.Context, repo gitprovider.UserRepository, branch string) (gitprovider.PullRequest, error) {
+	prs, err := repo.PullRequests().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pull requests: %w", err)
+	}
+
+	for _, pr := range prs {
+		switch obj := pr.APIObject().(type) {
+		case *github.PullRequest:
+			if obj.GetState() == "open" && obj.GetHead().GetRef() == branch {
+				return pr, nil
+			}
+		case *gitlab.MergeRequest:
+			if obj.State == "opened" && obj.SourceBranch == branch {
+				return pr, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// getClosedPullRequest returns the latest merged or closed pull request of a branch, or nil if there is none.
+// go-git-providers only lists open pull requests on GitHub, they are listed with the clients of the GitHub and
+// GitLab APIs.
+func getClosedPullRequest(ctx context.Context, provider gitprovider.Client, repoUrl RepoURL, branch string) (gitprovider.PullRequest, error) {
+	switch client := provider.Raw().(type) {
+	case *github.Client:
+		prs, _, err := client.PullRequests.List(ctx, repoUrl.Owner(), repoUrl.RepositoryName(), &github.PullRequestListOptions{
+			State: "closed",
+			Head:  repoUrl.Owner() + ":" + branch,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing pull requests: %w", err)
+		}
+
+		for _, pr := range prs {
+			if pr.GetHead().GetRef() == branch {
+				return pullRequest{
+					info:   gitprovider.PullRequestInfo{Merged: pr.GetMerged() || pr.MergedAt != nil, Number: pr.GetNumber(), WebURL: pr.GetHTMLURL()},
+					apiObj: pr,
+				}, nil
+			}
+		}
+	case *gitlab.Client:
+		mrs, _, err := client.MergeRequests.ListProjectMergeRequests(repoUrl.Owner()+"/"+repoUrl.RepositoryName(), &gitlab.ListProjectMergeRequestsOptions{
+			SourceBranch: &branch,
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("error listing merge requests: %w", err)
+		}
+
+		for _, mr := range mrs {
+			if mr.SourceBranch == branch && (mr.State == "merged" || mr.State == "closed") {
+				return pullRequest{
+					info:   gitprovider.PullRequestInfo{Merged: mr.State == "merged", Number: mr.IID, WebURL: mr.WebURL},
+					apiObj: mr,
+				}, nil
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported git provider %q", provider.ProviderID())
+	}
+
+	return nil, nil
+}
+
+// deleteBranch deletes a branch, which go-git-providers doesn't support, with the clients of the GitHub
+// and GitLab APIs.
+func deleteBranch(ctx context.Context, provider gitprovider.Client, repoUrl RepoURL, branch string) error {
+	var (
+		res *http.Response
+		err error
+	)
+
+	switch client := provider.Raw().(type) {
+	case *github.Client:
+		var ghRes *github.Response
+		ghRes, err = client.Git.DeleteRef(ctx, repoUrl.Owner(), repoUrl.RepositoryName(), "heads/"+branch)
+
+		if ghRes != nil {
+			res = ghRes.Response
+		}
+	case *gitlab.Client:
+		var glRes *gitlab.Response
+		glRes, err = client.Branches.DeleteBranch(repoUrl.Owner()+"/"+repoUrl.RepositoryName(), branch, gitlab.WithContext(ctx))
+
+		if glRes != nil {
+			res = glRes.Response
+		}
+	default:
+		return fmt.Errorf("unsupported git provider %q", provider.ProviderID())
+	}
+
+	if err != nil && (res == nil || (res.StatusCode != http.StatusNotFound && !isMissingGitHubRef(err))) {
+		return fmt.Errorf("error deleting branch %s: %w", branch, err)
+	}
+
+	return nil
+}
+
+// isMissingGitHubRef tells whether GitHub refused to delete a reference because it doesn't exist. It answers
+// 422 for these, and for references it refuses to delete, e.g. protected branches.
+func isMissingGitHubRef(err error) bool {
+	var ghErr *github.ErrorResponse
+
+	return errors.As(err, &ghErr) && ghErr.Response != nil && ghErr.Response.StatusCode == http.StatusUnprocessableEntity &&
+		ghErr.Message == "Reference does not exist"
+}
+
+// getPullRequestStatus returns the state, checks and reviews of a pull request, which go-git-providers
+// doesn't expose, from the clients of the GitHub and GitLab APIs.
+func getPullRequestStatus(ctx context.Context, provider gitprovider.Client, repoUrl RepoURL, pullRequestNumber int) (PullRequestStatus, error) {
+	switch client := provider.Raw().(type) {
+	case *github.Client:
+		return getGitHubPullRequestStatus(ctx, client, repoUrl, pullRequestNumber)
+	case *gitlab.Client:
+		return getGitLabMergeRequestStatus(ctx, client, repoUrl, pullRequestNumber)
+	default:
+		return PullRequestStatus{}, fmt.Errorf("unsupported git provider %q", provider.ProviderID())
+	}
+}
+
+func getGitHubPullRequestStatus(ctx context.Context, client *github.Client, repoUrl RepoURL, pullRequestNumber int) (PullRequestStatus, error) {
+	owner, name := repoUrl.Owner(), repoUrl.RepositoryName()
+
+	pr, _, err := client.PullRequests.Get(ctx, owner, name, pullRequestNumber)
+	if err != nil {
+		return PullRequestStatus{}, fmt.Errorf("error getting pull request %d: %w", pullRequestNumber, err)
+	}
+
+	status := PullRequestStatus{
+		Number:  pullRequestNumber,
+		WebURL:  pr.GetHTMLURL(),
+		State:   PullRequestOpen,
+		HeadSha: pr.GetHead().GetSHA(),
+	}
+
+	switch {
+	case pr.GetMerged():
+		status.State = PullRequestMerged
+		status.MergeCommitSha = pr.GetMergeCommitSHA()
+	case pr.GetState() == "closed":
+		status.State = PullRequestClosed
+	}
+
+	// repositories report the result of their CI either as commit statuses or as check runs
+	combined, _, err := client.Repositories.GetCombinedStatus(ctx, owner, name, status.HeadSha, nil)
+	if err != nil {
+		return PullRequestStatus{}, fmt.Errorf("error getting the statuses of commit %s: %w", status.HeadSha, err)
+	}
+
+	checks := []CheckState{}
+	reported := map[string]bool{}
+
+	for _, s := range combined.Statuses {
+		reported[s.GetContext()] = true
+	}
+
+	// the combined state of a commit without statuses is pending
+	if combined.GetTotalCount() > 0 {
+		switch combined.GetState() {
+		case "success":
+			checks = append(checks, ChecksPassed)
+		case "pending":
+			checks = append(checks, ChecksPending)
+		default:
+			checks = append(checks, ChecksFailed)
+		}
+	}
+
+	runs, _, err := client.Checks.ListCheckRunsForRef(ctx, owner, name, status.HeadSha, &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}})
+	if err != nil {
+		return PullRequestStatus{}, fmt.Errorf("error getting the check runs of commit %s: %w", status.HeadSha, err)
+	}
+
+	for _, run := range runs.CheckRuns {
+		reported[run.GetName()] = true
+
+		switch {
+		case run.GetStatus() != "completed":
+			checks = append(checks, ChecksPending)
+		case run.GetConclusion() == "success" || run.GetConclusion() == "neutral" || run.GetConclusion() == "skipped":
+			checks = append(checks, ChecksPassed)
+		default:
+			checks = append(checks, ChecksFailed)
+		}
+	}
+
+	// the checks required by the protection of the base branch are pending until they are reported
+	required, res, err := client.Repositories.GetRequiredStatusChecks(ctx, owner, name, pr.GetBase().GetRef())
+	if err != nil && (res == nil || (res.StatusCode != http.StatusNotFound && res.StatusCode != http.StatusForbidden)) {
+		return PullRequestStatus{}, fmt.Errorf("error getting the required checks of branch %s: %w", pr.GetBase().GetRef(), err)
+	}
+
+	if required != nil {
+		for _, check := range required.Contexts {
+			if !reported[check] {
+				checks = append(checks, ChecksPending)
+			}
+		}
+	}
+
+	status.Checks = combineChecks(checks...)
+
+	reviews, _, err := client.PullRequests.ListReviews(ctx, owner, name, pullRequestNumber, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return PullRequestStatus{}, fmt.Errorf("error getting the reviews of pull request %d: %w", pullRequestNumber, err)
+	}
+
+	// only the latest approval or change request of each reviewer counts
+	latest := map[string]string{}
+
+	for _, review := range reviews {
+		if state := review.GetState(); state != "COMMENTED" {
+			latest[review.GetUser().GetLogin()] = state
+		}
+	}
+
+	for _, state := range latest {
+		switch state {
+		case "APPROVED":
+			status.Approvals++
+		case "CHANGES_REQUESTED":
+			status.ChangesRequested = true
+		}
+	}
+
+	return status, nil
+}
+
+func getGitLabMergeRequestStatus(ctx context.Context, client *gitlab.Client, repoUrl RepoURL, pullRequestNumber int) (PullRequestStatus, error) {
+	pid := repoUrl.Owner() + "/" + repoUrl.RepositoryName()
+
+	mr, _, err := client.MergeRequests.GetMergeRequest(pid, pullRequestNumber, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return PullRequestStatus{}, fmt.Errorf("error getting merge request %d: %w", pullRequestNumber, err)
+	}
+
+	status := PullRequestStatus{
+		Number:  pullRequestNumber,
+		WebURL:  mr.WebURL,
+		State:   PullRequestOpen,
+		HeadSha: mr.SHA,
+		Checks:  ChecksNone,
+	}
+
+	switch mr.State {
+	case "merged":
+		status.State = PullRequestMerged
+
+		// fast-forward merges leave the head commit on the target branch
+		for _, sha := range []string{mr.MergeCommitSHA, mr.SquashCommitSHA, mr.SHA} {
+			if sha != "" {
+				status.MergeCommitSha = sha
+				break
+			}
+		}
+	case "closed", "locked":
+		status.State = PullRequestClosed
+	}
+
+	if mr.HeadPipeline != nil {
+		switch mr.HeadPipeline.Status {
+		case "success", "skipped", "manual":
+			status.Checks = ChecksPassed
+		case "failed", "canceled":
+			status.Checks = ChecksFailed
+		default:
+			status.Checks = ChecksPending
+		}
+	}
+
+	approvals, _, err := client.MergeRequestApprovals.GetConfiguration(pid, pullRequestNumber, gitlab.WithContext(ctx))
+	if err != nil {
+		return PullRequestStatus{}, fmt.Errorf("error getting the approvals of merge request %d: %w", pullRequestNumber, err)
+	}
+
+	status.Approvals = len(approvals.ApprovedBy)
+
+	return status, nil
+}
+
+// mergePullRequest merges a pull request with go-git-providers, or with the GitHub API when rebasing it.
+func mergePullRequest(ctx context.Context, provider gitprovider.Client, repo gitprovider.UserRepository, repoUrl RepoURL, pullRequestNumber int, mergeMethod gitprovider.MergeMethod, commitMessage string) error {
+	if mergeMethod == "" {
+		mergeMethod = gitprovider.MergeMethodMerge
+	}
+
+	if mergeMethod != MergeMethodRebase {
+		return repo.PullRequests().Merge(ctx, pullRequestNumber, mergeMethod, commitMessage)
+	}
+
+	client, ok := provider.Raw().(*github.Client)
+	if !ok {
+		// GitLab rebases merge requests when the project uses fast-forward merges
+		return fmt.Errorf("the rebase merge method isn't supported by %s, set it as the merge method of the project instead", provider.ProviderID())
+	}
+
+	_, _, err := client.PullRequests.Merge(ctx, repoUrl.Owner(), repoUrl.RepositoryName(), pullRequestNumber, commitMessage, &github.PullRequestOptions{MergeMethod: string(MergeMethodRebase)})
+	if err != nil {
+		return fmt.Errorf("error merging pull request %d: %w", pullRequestNumber, err)
+	}
+
+	return nil
+}
+
+func commitFiles(ctx context.Context, repo gitprovider.UserRepository, branch, commitMessage string, files []gitprovider.CommitFile) error {
+	if _, err := repo.Commits().Create(ctx, branch, commitMessage, files); err != nil {
+		return fmt.Errorf("error creating commit %s: %w", branch, err)
+	}
+
+	return nil
+}
+
 func getCommits(ctx context.Context, repo gitprovider.UserRepository, targetBranch string, pageSize int, pageToken int) ([]gitprovider.Commit, error) {
 	// currently locking the commit list at 10. May discuss pagination options later.
 	commits, err := repo.Commits().ListPage(ctx, targetBranch, pageSize, pageToken)
@@ -168,6 +517,48 @@ func getCommits(ctx context.Context, repo gitprovider.UserRepository, targetBran
 	return commits, nil
 }
 
+// getAuthenticatedUser returns the name and email of the owner of the token, which go-git-providers
+// doesn't expose, from the clients of the GitHub and GitLab APIs.
+func getAuthenticatedUser(ctx context.Context, provider gitprovider.Client, domain string) (git.Author, error) {
+	switch client := provider.Raw().(type) {
+	case *github.Client:
+		user, _, err := client.Users.Get(ctx, "")
+		if err != nil {
+			return git.Author{}, fmt.Errorf("error getting the authenticated user: %w", err)
+		}
+
+		author := git.Author{Name: user.GetName(), Email: user.GetEmail()}
+		if author.Name == "" {
+			author.Name = user.GetLogin()
+		}
+
+		// users can keep their email private, GitHub attributes commits of their noreply address to them
+		if author.Email == "" {
+			author.Email = fmt.Sprintf("%d+%s@users.noreply.%s", user.GetID(), user.GetLogin(), domain)
+		}
+
+		return author, nil
+	case *gitlab.Client:
+		user, _, err := client.Users.CurrentUser(gitlab.WithContext(ctx))
+		if err != nil {
+			return git.Author{}, fmt.Errorf("error getting the authenticated user: %w", err)
+		}
+
+		author := git.Author{Name: user.Name, Email: user.Email}
+		if author.Name == "" {
+			author.Name = user.Username
+		}
+
+		if author.Email == "" {
+			author.Email = user.PublicEmail
+		}
+
+		return author, nil
+	default:
+		return git.Author{}, fmt.Errorf("unsupported git provider %q", provider.ProviderID())
+	}
+}
+
 func getProviderDomain(providerID gitprovider.ProviderID) string {
 	return string(GitProviderName(providerID)) + ".com"
 }
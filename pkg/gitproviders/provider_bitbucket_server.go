@@ -0,0 +1,625 @@
+package gitproviders
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+
+	"github.com/weaveworks/weave-gitops/pkg/git"
+)
+
+const bitbucketServerFilesLimit = 1000
+
+// bitbucketServerGitProvider talks to the REST API of Bitbucket Server, where the owner of a repository
+// is either a project key or a user slug prefixed with '~'.
+type bitbucketServerGitProvider struct {
+	domain string
+	client restClient
+}
+
+var _ GitProvider = bitbucketServerGitProvider{}
+
+type bitbucketServerRepository struct {
+	Slug   string `json:"slug"`
+	Public bool   `json:"public"`
+}
+
+type bitbucketServerRef struct {
+	ID           string `json:"id"`
+	DisplayID    string `json:"displayId,omitempty"`
+	LatestCommit string `json:"latestCommit,omitempty"`
+}
+
+type bitbucketServerCommit struct {
+	ID     string `json:"id"`
+	Author struct {
+		Name string `json:"name"`
+	} `json:"author"`
+	AuthorTimestamp int64  `json:"authorTimestamp"`
+	Message         string `json:"message"`
+}
+
+type bitbucketServerPullRequest struct {
+	ID        int                `json:"id"`
+	Version   int                `json:"version"`
+	State     string             `json:"state"`
+	FromRef   bitbucketServerRef `json:"fromRef"`
+	ToRef     bitbucketServerRef `json:"toRef"`
+	Reviewers []struct {
+		User   bitbucketServerUser `json:"user"`
+		Status string              `json:"status"`
+	} `json:"reviewers"`
+	Properties struct {
+		MergeCommit struct {
+			ID string `json:"id"`
+		} `json:"mergeCommit"`
+	} `json:"properties"`
+	Links struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+}
+
+type bitbucketServerBuildStats struct {
+	Successful int `json:"successful"`
+	InProgress int `json:"inProgress"`
+	Failed     int `json:"failed"`
+	Cancelled  int `json:"cancelled"`
+}
+
+type bitbucketServerUser struct {
+	Name         string `json:"name"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+	Slug         string `json:"slug"`
+}
+
+type bitbucketServerSSHKey struct {
+	Key struct {
+		ID    int    `json:"id,omitempty"`
+		Text  string `json:"text"`
+		Label string `json:"label"`
+	} `json:"key"`
+	Permission string `json:"permission"`
+}
+
+func newBitbucketServerGitProvider(config Config) (GitProvider, error) {
+	client, err := newRestClient(config, "Bearer", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return bitbucketServerGitProvider{
+		domain: apiHostname(config.Hostname),
+		client: client,
+	}, nil
+}
+
+func (p bitbucketServerGitProvider) RepositoryExists(ctx context.Context, repoUrl RepoURL) (bool, error) {
+	if _, err := p.getRepo(ctx, repoUrl); err != nil {
+		if errors.Is(err, gitprovider.ErrNotFound) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("could not get verify repository exists  %w", err)
+	}
+
+	return true, nil
+}
+
+func (p bitbucketServerGitProvider) DeployKeyExists(ctx context.Context, repoUrl RepoURL) (bool, error) {
+	var keys struct {
+		Values []bitbucketServerSSHKey `json:"values"`
+	}
+
+	if err := p.client.get(ctx, p.keysPath(repoUrl), nil, &keys); err != nil {
+		return false, fmt.Errorf("error getting deploy key %s: %s", DeployKeyName, err)
+	}
+
+	for _, k := range keys.Values {
+		if IsManagedDeployKey(k.Key.Label) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (p bitbucketServerGitProvider) UploadDeployKey(ctx context.Context, repoUrl RepoURL, deployKey []byte) error {
+	return p.UploadNamedDeployKey(ctx, repoUrl, DeployKeyName, deployKey)
+}
+
+func (p bitbucketServerGitProvider) UploadNamedDeployKey(ctx context.Context, repoUrl RepoURL, name string, deployKey []byte) error {
+	key := bitbucketServerSSHKey{Permission: "REPO_WRITE"}
+	key.Key.Text = strings.TrimSpace(string(deployKey))
+	key.Key.Label = name
+
+	if err := p.client.post(ctx, p.keysPath(repoUrl), key, nil); err != nil {
+		if errors.Is(err, gitprovider.ErrNotFound) {
+			return ErrRepositoryNoPermissionsOrDoesNotExist
+		}
+
+		return fmt.Errorf("error uploading deploy key %s", err)
+	}
+
+	return nil
+}
+
+// ListDeployKeys returns the access keys of the repository uploaded by gitops. Bitbucket Server doesn't
+// return when they were created.
+func (p bitbucketServerGitProvider) ListDeployKeys(ctx context.Context, repoUrl RepoURL) ([]DeployKey, error) {
+	var keys struct {
+		Values []bitbucketServerSSHKey `json:"values"`
+	}
+
+	if err := p.client.get(ctx, p.keysPath(repoUrl), nil, &keys); err != nil {
+		return nil, fmt.Errorf("error listing deploy keys: %w", err)
+	}
+
+	deployKeys := []DeployKey{}
+
+	for _, k := range keys.Values {
+		if !IsManagedDeployKey(k.Key.Label) {
+			continue
+		}
+
+		deployKeys = append(deployKeys, DeployKey{
+			ID:       strconv.Itoa(k.Key.ID),
+			Name:     k.Key.Label,
+			Key:      []byte(k.Key.Text),
+			ReadOnly: k.Permission == "REPO_READ",
+		})
+	}
+
+	return deployKeys, nil
+}
+
+func (p bitbucketServerGitProvider) DeleteDeployKey(ctx context.Context, repoUrl RepoURL, id string) error {
+	if err := p.client.delete(ctx, p.keysPath(repoUrl)+"/"+url.PathEscape(id)); err != nil {
+		return fmt.Errorf("error deleting deploy key %s: %w", id, err)
+	}
+
+	return nil
+}
+
+func (p bitbucketServerGitProvider) GetDefaultBranch(ctx context.Context, repoUrl RepoURL) (string, error) {
+	var ref bitbucketServerRef
+	if err := p.client.get(ctx, p.repoPath(repoUrl)+"/branches/default", nil, &ref); err != nil {
+		return "main", err
+	}
+
+	return ref.DisplayID, nil
+}
+
+func (p bitbucketServerGitProvider) GetRepoVisibility(ctx context.Context, repoUrl RepoURL) (*gitprovider.RepositoryVisibility, error) {
+	repo, err := p.getRepo(ctx, repoUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	visibility := gitprovider.RepositoryVisibilityPrivate
+	if repo.Public {
+		visibility = gitprovider.RepositoryVisibilityPublic
+	}
+
+	return &visibility, nil
+}
+
+func (p bitbucketServerGitProvider) CreatePullRequest(ctx context.Context, repoUrl RepoURL, prInfo PullRequestInfo) (gitprovider.PullRequest, error) {
+	if prInfo.TargetBranch == "" {
+		branch, err := p.GetDefaultBranch(ctx, repoUrl)
+		if err != nil {
+			return nil, fmt.Errorf("error getting default branch: %w", err)
+		}
+
+		prInfo.TargetBranch = branch
+	}
+
+	if !prInfo.SkipAddingFilesOnCreation {
+		commits, err := p.GetCommits(ctx, repoUrl, prInfo.TargetBranch, 1, 0)
+		if err != nil {
+			return nil, fmt.Errorf("error getting commits: %w", err)
+		}
+
+		if len(commits) == 0 {
+			return nil, fmt.Errorf("no commits on the target branch: %s", prInfo.TargetBranch)
+		}
+
+		branch := map[string]string{"name": prInfo.NewBranch, "startPoint": commits[0].Get().Sha}
+		if err := p.client.post(ctx, p.repoPath(repoUrl)+"/branches", branch, nil); err != nil {
+			return nil, fmt.Errorf("error creating branch %s: %w", prInfo.NewBranch, err)
+		}
+
+		if err := p.commitFiles(ctx, repoUrl, prInfo.NewBranch, commits[0].Get().Sha, prInfo.CommitMessage, prInfo.Files); err != nil {
+			return nil, fmt.Errorf("error creating commit %s: %w", prInfo.NewBranch, err)
+		}
+	}
+
+	req := map[string]interface{}{
+		"title":       prInfo.Title,
+		"description": prInfo.Description,
+		"fromRef":     bitbucketServerRef{ID: "refs/heads/" + prInfo.NewBranch},
+		"toRef":       bitbucketServerRef{ID: "refs/heads/" + prInfo.TargetBranch},
+	}
+
+	var pr bitbucketServerPullRequest
+	if err := p.client.post(ctx, p.repoPath(repoUrl)+"/pull-requests", req, &pr); err != nil {
+		return nil, fmt.Errorf("error creating pull request %s: %w", prInfo.Title, err)
+	}
+
+	return newBitbucketServerPullRequest(&pr), nil
+}
+
+// GetOpenPullRequest returns the open pull request of a branch, or nil if there is none.
+func (p bitbucketServerGitProvider) GetOpenPullRequest(ctx context.Context, repoUrl RepoURL, branch string) (gitprovider.PullRequest, error) {
+	var page struct {
+		Values []bitbucketServerPullRequest `json:"values"`
+	}
+
+	query := url.Values{
+		"state":     {"OPEN"},
+		"direction": {"OUTGOING"},
+		"at":        {"refs/heads/" + branch},
+	}
+
+	if err := p.client.get(ctx, p.repoPath(repoUrl)+"/pull-requests", query, &page); err != nil {
+		return nil, fmt.Errorf("error listing pull requests: %w", err)
+	}
+
+	for i := range page.Values {
+		if page.Values[i].FromRef.ID == "refs/heads/"+branch {
+			return newBitbucketServerPullRequest(&page.Values[i]), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// GetClosedPullRequest returns the latest merged or declined pull request of a branch, or nil if there is none.
+func (p bitbucketServerGitProvider) GetClosedPullRequest(ctx context.Context, repoUrl RepoURL, branch string) (gitprovider.PullRequest, error) {
+	var page struct {
+		Values []bitbucketServerPullRequest `json:"values"`
+	}
+
+	// pull requests are listed newest first
+	query := url.Values{
+		"state":     {"ALL"},
+		"direction": {"OUTGOING"},
+		"at":        {"refs/heads/" + branch},
+	}
+
+	if err := p.client.get(ctx, p.repoPath(repoUrl)+"/pull-requests", query, &page); err != nil {
+		return nil, fmt.Errorf("error listing pull requests: %w", err)
+	}
+
+	for i := range page.Values {
+		if page.Values[i].FromRef.ID == "refs/heads/"+branch && page.Values[i].State != "OPEN" {
+			return newBitbucketServerPullRequest(&page.Values[i]), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// DeleteBranch deletes a branch with the branch utils API, the core API can't delete branches.
+func (p bitbucketServerGitProvider) DeleteBranch(ctx context.Context, repoUrl RepoURL, branch string) error {
+	path := fmt.Sprintf("/rest/branch-utils/1.0/projects/%s/repos/%s/branches", url.PathEscape(repoUrl.Owner()), url.PathEscape(repoUrl.RepositoryName()))
+	req := map[string]interface{}{
+		"name":   "refs/heads/" + branch,
+		"dryRun": false,
+	}
+
+	if err := p.client.do(ctx, http.MethodDelete, path, nil, req, nil); err != nil && !errors.Is(err, gitprovider.ErrNotFound) {
+		return fmt.Errorf("error deleting branch %s: %w", branch, err)
+	}
+
+	return nil
+}
+
+// CommitFiles commits the files to an existing branch.
+func (p bitbucketServerGitProvider) CommitFiles(ctx context.Context, repoUrl RepoURL, branch, commitMessage string, files []gitprovider.CommitFile) error {
+	commits, err := p.GetCommits(ctx, repoUrl, branch, 1, 0)
+	if err != nil {
+		return fmt.Errorf("error getting commits: %w", err)
+	}
+
+	if len(commits) == 0 {
+		return fmt.Errorf("no commits on the branch: %s", branch)
+	}
+
+	if err := p.commitFiles(ctx, repoUrl, branch, commits[0].Get().Sha, commitMessage, files); err != nil {
+		return fmt.Errorf("error creating commit %s: %w", branch, err)
+	}
+
+	return nil
+}
+
+// commitFiles commits each file on its own, as the API only allows editing a single file at a time.
+// Files without content can't be deleted through the API.
+func (p bitbucketServerGitProvider) commitFiles(ctx context.Context, repoUrl RepoURL, branch, parent, message string, files []gitprovider.CommitFile) error {
+	for _, file := range files {
+		if file.Content == nil {
+			return fmt.Errorf("deleting %s is not supported by Bitbucket Server", *file.Path)
+		}
+
+		exists, err := p.fileExists(ctx, repoUrl, *file.Path, branch)
+		if err != nil {
+			return err
+		}
+
+		body := &bytes.Buffer{}
+		form := multipart.NewWriter(body)
+
+		fields := [][2]string{
+			{"branch", branch},
+			{"message", message},
+			{"content", *file.Content},
+		}
+		if exists {
+			fields = append(fields, [2]string{"sourceCommitId", parent})
+		}
+
+		for _, f := range fields {
+			if err := form.WriteField(f[0], f[1]); err != nil {
+				return err
+			}
+		}
+
+		if err := form.Close(); err != nil {
+			return err
+		}
+
+		b, err := p.client.send(ctx, http.MethodPut, p.repoPath(repoUrl)+"/browse/"+escapePath(*file.Path), nil, form.FormDataContentType(), body)
+		if err != nil {
+			return err
+		}
+
+		var c bitbucketServerCommit
+		if err := decodeJSON(b, &c); err != nil {
+			return err
+		}
+
+		parent = c.ID
+	}
+
+	return nil
+}
+
+func (p bitbucketServerGitProvider) fileExists(ctx context.Context, repoUrl RepoURL, path, branch string) (bool, error) {
+	err := p.client.get(ctx, p.repoPath(repoUrl)+"/browse/"+escapePath(path), url.Values{"at": {branch}, "type": {"true"}}, nil)
+	if err != nil {
+		if errors.Is(err, gitprovider.ErrNotFound) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("error checking whether %s exists: %w", path, err)
+	}
+
+	return true, nil
+}
+
+func (p bitbucketServerGitProvider) GetCommits(ctx context.Context, repoUrl RepoURL, targetBranch string, pageSize int, pageToken int) ([]gitprovider.Commit, error) {
+	var page struct {
+		Values []bitbucketServerCommit `json:"values"`
+	}
+
+	query := url.Values{
+		"until": {targetBranch},
+		"limit": {strconv.Itoa(pageSize)},
+		"start": {strconv.Itoa(pageSize * pageToken)},
+	}
+
+	if err := p.client.get(ctx, p.repoPath(repoUrl)+"/commits", query, &page); err != nil {
+		return nil, fmt.Errorf("error getting commits: %s", err)
+	}
+
+	commits := []gitprovider.Commit{}
+
+	for i := range page.Values {
+		c := page.Values[i]
+
+		commits = append(commits, commit{
+			info: gitprovider.CommitInfo{
+				Sha:       c.ID,
+				Author:    c.Author.Name,
+				Message:   c.Message,
+				CreatedAt: time.Unix(0, c.AuthorTimestamp*int64(time.Millisecond)).UTC(),
+				URL:       fmt.Sprintf("https://%s/%s/commits/%s", p.domain, p.repoWebPath(repoUrl), c.ID),
+			},
+			apiObj: &c,
+		})
+	}
+
+	return commits, nil
+}
+
+func (p bitbucketServerGitProvider) GetProviderDomain() string {
+	return p.domain
+}
+
+// GetRepoDirFiles returns the files found in the subdirectory of a repository.
+// Like for the other providers, it does not get the files of nested directories.
+func (p bitbucketServerGitProvider) GetRepoDirFiles(ctx context.Context, repoUrl RepoURL, dirPath, targetBranch string) ([]*gitprovider.CommitFile, error) {
+	var page struct {
+		Values []string `json:"values"`
+	}
+
+	query := url.Values{"at": {targetBranch}, "limit": {strconv.Itoa(bitbucketServerFilesLimit)}}
+	if err := p.client.get(ctx, p.repoPath(repoUrl)+"/files/"+escapePath(dirPath), query, &page); err != nil {
+		return nil, err
+	}
+
+	files := []*gitprovider.CommitFile{}
+
+	for _, name := range page.Values {
+		// the API lists the files of the nested directories too
+		if strings.Contains(name, "/") {
+			continue
+		}
+
+		path := strings.Trim(dirPath, "/") + "/" + name
+
+		content, err := p.client.send(ctx, http.MethodGet, p.repoPath(repoUrl)+"/raw/"+escapePath(path), url.Values{"at": {targetBranch}}, "", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		c := string(content)
+		files = append(files, &gitprovider.CommitFile{
+			Path:    &path,
+			Content: &c,
+		})
+	}
+
+	return files, nil
+}
+
+// GetPullRequestStatus returns the state, build statuses and reviews of a pull request.
+func (p bitbucketServerGitProvider) GetPullRequestStatus(ctx context.Context, repoUrl RepoURL, pullRequestNumber int) (PullRequestStatus, error) {
+	var pr bitbucketServerPullRequest
+	if err := p.client.get(ctx, fmt.Sprintf("%s/pull-requests/%d", p.repoPath(repoUrl), pullRequestNumber), nil, &pr); err != nil {
+		return PullRequestStatus{}, fmt.Errorf("error getting pull request %d: %w", pullRequestNumber, err)
+	}
+
+	status := PullRequestStatus{
+		Number:  pr.ID,
+		WebURL:  newBitbucketServerPullRequest(&pr).info.WebURL,
+		State:   PullRequestOpen,
+		HeadSha: pr.FromRef.LatestCommit,
+		Checks:  ChecksNone,
+	}
+
+	switch pr.State {
+	case "MERGED":
+		status.State = PullRequestMerged
+		status.MergeCommitSha = pr.Properties.MergeCommit.ID
+	case "DECLINED":
+		status.State = PullRequestClosed
+	}
+
+	for _, reviewer := range pr.Reviewers {
+		switch reviewer.Status {
+		case "APPROVED":
+			status.Approvals++
+		case "NEEDS_WORK":
+			status.ChangesRequested = true
+		}
+	}
+
+	// build statuses have their own REST API
+	var stats bitbucketServerBuildStats
+	if err := p.client.get(ctx, "/rest/build-status/1.0/commits/stats/"+url.PathEscape(pr.FromRef.LatestCommit), nil, &stats); err != nil {
+		return PullRequestStatus{}, fmt.Errorf("error getting the build statuses of commit %s: %w", pr.FromRef.LatestCommit, err)
+	}
+
+	switch {
+	case stats.Failed > 0 || stats.Cancelled > 0:
+		status.Checks = ChecksFailed
+	case stats.InProgress > 0:
+		status.Checks = ChecksPending
+	case stats.Successful > 0:
+		status.Checks = ChecksPassed
+	}
+
+	return status, nil
+}
+
+// MergePullRequest merges a pull request given the repository's URL and the PR's number with a merge method and a commit message.
+func (p bitbucketServerGitProvider) MergePullRequest(ctx context.Context, repoUrl RepoURL, pullRequestNumber int, mergeMethod gitprovider.MergeMethod, commitMesage string) error {
+	prPath := fmt.Sprintf("%s/pull-requests/%d", p.repoPath(repoUrl), pullRequestNumber)
+
+	// merging requires the current version of the pull request
+	var pr bitbucketServerPullRequest
+	if err := p.client.get(ctx, prPath, nil, &pr); err != nil {
+		return err
+	}
+
+	query := url.Values{"version": {strconv.Itoa(pr.Version)}}
+	req := map[string]string{"message": commitMesage}
+
+	// the merge strategy of the repository is used when none is given
+	switch mergeMethod {
+	case gitprovider.MergeMethodMerge:
+		req["strategyId"] = "no-ff"
+	case gitprovider.MergeMethodSquash:
+		req["strategyId"] = "squash"
+	case MergeMethodRebase:
+		req["strategyId"] = "rebase-no-ff"
+	}
+
+	return p.client.do(ctx, http.MethodPost, prPath+"/merge", query, req, nil)
+}
+
+// GetAuthenticatedUser returns the name and email of the user the provider is authenticated as. The REST API
+// has no endpoint for the current user, the whoami servlet gives its slug.
+func (p bitbucketServerGitProvider) GetAuthenticatedUser(ctx context.Context) (git.Author, error) {
+	slug, err := p.client.send(ctx, http.MethodGet, "/plugins/servlet/applinks/whoami", nil, "", nil)
+	if err != nil {
+		return git.Author{}, fmt.Errorf("error getting the authenticated user: %w", err)
+	}
+
+	if len(bytes.TrimSpace(slug)) == 0 {
+		return git.Author{}, errors.New("error getting the authenticated user: the token is anonymous")
+	}
+
+	var user bitbucketServerUser
+	if err := p.client.get(ctx, "/rest/api/1.0/users/"+url.PathEscape(string(bytes.TrimSpace(slug))), nil, &user); err != nil {
+		return git.Author{}, fmt.Errorf("error getting the authenticated user: %w", err)
+	}
+
+	author := git.Author{Name: user.DisplayName, Email: user.EmailAddress}
+	if author.Name == "" {
+		author.Name = user.Name
+	}
+
+	return author, nil
+}
+
+func (p bitbucketServerGitProvider) getRepo(ctx context.Context, repoUrl RepoURL) (*bitbucketServerRepository, error) {
+	var repo bitbucketServerRepository
+	if err := p.client.get(ctx, p.repoPath(repoUrl), nil, &repo); err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
+
+func (p bitbucketServerGitProvider) repoPath(repoUrl RepoURL) string {
+	return fmt.Sprintf("/rest/api/1.0/projects/%s/repos/%s", url.PathEscape(repoUrl.Owner()), url.PathEscape(repoUrl.RepositoryName()))
+}
+
+func (p bitbucketServerGitProvider) keysPath(repoUrl RepoURL) string {
+	return fmt.Sprintf("/rest/keys/1.0/projects/%s/repos/%s/ssh", url.PathEscape(repoUrl.Owner()), url.PathEscape(repoUrl.RepositoryName()))
+}
+
+// repoWebPath returns the path of the repository in the web interface, where user repositories live under /users.
+func (p bitbucketServerGitProvider) repoWebPath(repoUrl RepoURL) string {
+	if strings.HasPrefix(repoUrl.Owner(), "~") {
+		return fmt.Sprintf("users/%s/repos/%s", strings.TrimPrefix(repoUrl.Owner(), "~"), repoUrl.RepositoryName())
+	}
+
+	return fmt.Sprintf("projects/%s/repos/%s", repoUrl.Owner(), repoUrl.RepositoryName())
+}
+
+func newBitbucketServerPullRequest(pr *bitbucketServerPullRequest) pullRequest {
+	info := gitprovider.PullRequestInfo{
+		Merged: pr.State == "MERGED",
+		Number: pr.ID,
+	}
+
+	if len(pr.Links.Self) > 0 {
+		info.WebURL = pr.Links.Self[0].Href
+	}
+
+	return pullRequest{info: info, apiObj: pr}
+}
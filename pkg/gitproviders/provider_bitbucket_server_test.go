@@ -0,0 +1,218 @@
+package gitproviders
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/weaveworks/weave-gitops/pkg/git"
+)
+
+var _ = Describe("Bitbucket Server Provider", func() {
+	var (
+		ctx      context.Context
+		provider GitProvider
+		replay   *replayer
+		repoUrl  RepoURL
+	)
+
+	newRepoURL := func(uri string) RepoURL {
+		u, err := NewRepoURL(uri)
+		Expect(err).NotTo(HaveOccurred())
+
+		return u
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		viper.Set("git-host-types", "bitbucket.example.com=bitbucket-server")
+
+		p, err := New(Config{
+			Provider: GitProviderBitbucketServer,
+			Hostname: "bitbucket.example.com:7999",
+			Token:    "token",
+		}, "proj", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		replay = newReplayer("cache/bitbucket_server.yaml")
+		bbs := p.(bitbucketServerGitProvider)
+		bbs.client.http = &http.Client{Transport: replay}
+		provider = bbs
+
+		repoUrl = newRepoURL("ssh://git@bitbucket.example.com:7999/proj/config-repo.git")
+	})
+
+	AfterEach(func() {
+		viper.Set("git-host-types", "")
+	})
+
+	It("fails without a token", func() {
+		_, err := New(Config{Provider: GitProviderBitbucketServer, Hostname: "bitbucket.example.com"}, "proj", nil)
+		Expect(err).To(MatchError("no git provider token present"))
+	})
+
+	It("uses the hostname without the SSH port as domain", func() {
+		Expect(provider.GetProviderDomain()).To(Equal("bitbucket.example.com"))
+	})
+
+	It("checks whether the repository exists", func() {
+		exists, err := provider.RepositoryExists(ctx, repoUrl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeTrue())
+
+		exists, err = provider.RepositoryExists(ctx, newRepoURL("ssh://git@bitbucket.example.com:7999/proj/missing.git"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeFalse())
+	})
+
+	It("gets the default branch and visibility of the repository", func() {
+		branch, err := provider.GetDefaultBranch(ctx, repoUrl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(branch).To(Equal("main"))
+
+		visibility, err := provider.GetRepoVisibility(ctx, repoUrl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*visibility).To(Equal(gitprovider.RepositoryVisibilityPrivate))
+	})
+
+	It("finds and uploads the deploy key", func() {
+		exists, err := provider.DeployKeyExists(ctx, repoUrl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeTrue())
+
+		Expect(provider.UploadDeployKey(ctx, repoUrl, []byte("ssh-ed25519 AAAA\n"))).To(Succeed())
+		Expect(replay.bodies["POST https://bitbucket.example.com/rest/keys/1.0/projects/proj/repos/config-repo/ssh"]).To(ConsistOf(
+			`{"key":{"text":"ssh-ed25519 AAAA","label":"wego-deploy-key"},"permission":"REPO_WRITE"}`,
+		))
+	})
+
+	It("lists and deletes the deploy keys uploaded by gitops", func() {
+		keys, err := provider.ListDeployKeys(ctx, repoUrl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(HaveLen(2))
+		Expect(keys[0].ID).To(Equal("12"))
+		Expect(keys[1].Name).To(Equal("wego-deploy-key-20220801-101500"))
+		Expect(keys[1].CreatedAt.IsZero()).To(BeTrue())
+
+		Expect(provider.DeleteDeployKey(ctx, repoUrl, "12")).To(Succeed())
+	})
+
+	It("gets the commits of a branch", func() {
+		commits, err := provider.GetCommits(ctx, repoUrl, "main", 1, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Get().Sha).To(Equal("6fb1d9a0a4b6d2d9cb8c5bd5c0e5a1bd8ad3e9f0"))
+		Expect(commits[0].Get().Author).To(Equal("jdoe"))
+		Expect(commits[0].Get().Message).To(Equal("Add podinfo"))
+		Expect(commits[0].Get().CreatedAt.Unix()).To(Equal(int64(1657031136)))
+		Expect(commits[0].Get().URL).To(Equal("https://bitbucket.example.com/projects/proj/repos/config-repo/commits/6fb1d9a0a4b6d2d9cb8c5bd5c0e5a1bd8ad3e9f0"))
+	})
+
+	It("gets the open pull request of a branch", func() {
+		pr, err := provider.GetOpenPullRequest(ctx, repoUrl, "gitops-add-profile")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pr.Get().Number).To(Equal(7))
+
+		pr, err = provider.GetOpenPullRequest(ctx, repoUrl, "gitops-delete-profile")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pr).To(BeNil())
+	})
+
+	It("commits the files on top of an existing branch", func() {
+		profiles := ".weave-gitops/clusters/prod/system/profiles.yaml"
+		content := "kind: HelmRelease\n"
+
+		Expect(provider.CommitFiles(ctx, repoUrl, "gitops-add-profile", "Update profile manifests", []gitprovider.CommitFile{
+			{Path: &profiles, Content: &content},
+		})).To(Succeed())
+
+		updated := replay.bodies["PUT https://bitbucket.example.com/rest/api/1.0/projects/proj/repos/config-repo/browse/"+profiles]
+		Expect(updated).To(HaveLen(1))
+		Expect(updated[0]).To(ContainSubstring("a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0"))
+		Expect(updated[0]).To(ContainSubstring("Update profile manifests"))
+	})
+
+	It("creates a pull request committing each file on the new branch", func() {
+		profiles := ".weave-gitops/clusters/prod/system/profiles.yaml"
+		kustomization := ".weave-gitops/clusters/prod/system/kustomization.yaml"
+		content := "kind: HelmRelease\n"
+
+		pr, err := provider.CreatePullRequest(ctx, repoUrl, PullRequestInfo{
+			Title:         "GitOps add podinfo",
+			CommitMessage: "Add profile manifests",
+			TargetBranch:  "main",
+			NewBranch:     "gitops-add-profile",
+			Files: []gitprovider.CommitFile{
+				{Path: &profiles, Content: &content},
+				{Path: &kustomization, Content: &content},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pr.Get().Number).To(Equal(7))
+		Expect(pr.Get().WebURL).To(Equal("https://bitbucket.example.com/projects/PROJ/repos/config-repo/pull-requests/7"))
+
+		Expect(replay.bodies["POST https://bitbucket.example.com/rest/api/1.0/projects/proj/repos/config-repo/branches"]).To(ConsistOf(
+			`{"name":"gitops-add-profile","startPoint":"6fb1d9a0a4b6d2d9cb8c5bd5c0e5a1bd8ad3e9f0"}`,
+		))
+
+		// the existing file is updated on top of the branch, the new one on top of the previous commit
+		updated := replay.bodies["PUT https://bitbucket.example.com/rest/api/1.0/projects/proj/repos/config-repo/browse/"+profiles]
+		Expect(updated).To(HaveLen(1))
+		Expect(updated[0]).To(ContainSubstring("6fb1d9a0a4b6d2d9cb8c5bd5c0e5a1bd8ad3e9f0"))
+
+		created := replay.bodies["PUT https://bitbucket.example.com/rest/api/1.0/projects/proj/repos/config-repo/browse/"+kustomization]
+		Expect(created).To(HaveLen(1))
+		Expect(created[0]).NotTo(ContainSubstring("sourceCommitId"))
+	})
+
+	It("refuses to delete files", func() {
+		path := ".weave-gitops/clusters/prod/system/profiles.yaml"
+
+		_, err := provider.CreatePullRequest(ctx, repoUrl, PullRequestInfo{
+			TargetBranch: "main",
+			NewBranch:    "gitops-add-profile",
+			Files:        []gitprovider.CommitFile{{Path: &path}},
+		})
+		Expect(err).To(MatchError(ContainSubstring("deleting .weave-gitops/clusters/prod/system/profiles.yaml is not supported by Bitbucket Server")))
+	})
+
+	It("merges a pull request at its current version", func() {
+		Expect(provider.MergePullRequest(ctx, repoUrl, 7, gitprovider.MergeMethodSquash, "Merge profile")).To(Succeed())
+		Expect(replay.bodies["POST https://bitbucket.example.com/rest/api/1.0/projects/proj/repos/config-repo/pull-requests/7/merge?version=2"]).To(ConsistOf(
+			`{"message":"Merge profile","strategyId":"squash"}`,
+		))
+	})
+
+	It("gets the reviews and build statuses of a pull request", func() {
+		status, err := provider.GetPullRequestStatus(ctx, repoUrl, 7)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(Equal(PullRequestStatus{
+			Number:    7,
+			WebURL:    "https://bitbucket.example.com/projects/PROJ/repos/config-repo/pull-requests/7",
+			State:     PullRequestOpen,
+			HeadSha:   "9c1e5b2a7d3f4e6a8b0c1d2e3f4a5b6c7d8e9f01",
+			Checks:    ChecksPending,
+			Approvals: 1,
+		}))
+	})
+
+	It("gets the files of a directory", func() {
+		files, err := provider.GetRepoDirFiles(ctx, repoUrl, ".weave-gitops/clusters/prod/system", "main")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(2))
+		Expect(*files[0].Path).To(Equal(".weave-gitops/clusters/prod/system/profiles.yaml"))
+		Expect(*files[0].Content).To(Equal("kind: HelmRelease\n"))
+		Expect(*files[1].Path).To(Equal(".weave-gitops/clusters/prod/system/wego-system.yaml"))
+		Expect(*files[1].Content).To(Equal("kind: Kustomization\n"))
+	})
+
+	It("gets the authenticated user", func() {
+		user, err := provider.GetAuthenticatedUser(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user).To(Equal(git.Author{Name: "Jane Doe", Email: "jdoe@example.com"}))
+	})
+})
@@ -0,0 +1,373 @@
+package gitproviders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/git/wrapper"
+)
+
+const defaultGitBranch = "main"
+
+// ErrNoProviderAPI is returned for the operations that need the API of a hosting service.
+var ErrNoProviderAPI = errors.New("the git provider has no API")
+
+// GitOptions configures the provider of plain git servers.
+type GitOptions struct {
+	// DefaultBranch is the branch changes are based on, main if empty.
+	DefaultBranch string
+
+	// CompareURL is the URL printed for the pushed branches, {branch} and {base} are replaced
+	// with the names of the new and target branches.
+	CompareURL string
+
+	// DirectCommit commits the changes to the target branch instead of pushing a new branch.
+	DirectCommit bool
+
+	// ClientOptions configure the git client, e.g. the author and signing key of the commits.
+	ClientOptions []git.Option
+}
+
+// gitGitProvider works with nothing but a git remote: files are read from a clone and pull requests
+// are branches pushed to the remote, or commits on the target branch when DirectCommit is set.
+type gitGitProvider struct {
+	domain string
+	opts   GitOptions
+	newGit func() git.Git
+	remote func(repoUrl RepoURL) string
+}
+
+var _ GitProvider = gitGitProvider{}
+
+func newGitGitProvider(config Config) GitProvider {
+	return gitGitProvider{
+		domain: apiHostname(config.Hostname),
+		opts:   config.Git,
+		newGit: func() git.Git {
+			// relies on the credentials of the user, e.g. the SSH agent
+			return git.New(nil, wrapper.NewGoGit(), config.Git.ClientOptions...)
+		},
+		remote: func(repoUrl RepoURL) string {
+			return repoUrl.String()
+		},
+	}
+}
+
+func (p gitGitProvider) RepositoryExists(ctx context.Context, repoUrl RepoURL) (bool, error) {
+	if err := p.newGit().ValidateAccess(ctx, p.remote(repoUrl), p.defaultBranch()); err != nil {
+		if errors.Is(err, transport.ErrRepositoryNotFound) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("could not get verify repository exists  %w", err)
+	}
+
+	return true, nil
+}
+
+// DeployKeyExists always returns false, as the deploy keys of plain git servers can't be looked up.
+func (p gitGitProvider) DeployKeyExists(ctx context.Context, repoUrl RepoURL) (bool, error) {
+	return false, nil
+}
+
+func (p gitGitProvider) UploadDeployKey(ctx context.Context, repoUrl RepoURL, deployKey []byte) error {
+	return fmt.Errorf("%w to upload deploy keys, add the key %s to %s manually", ErrNoProviderAPI, strings.TrimSpace(string(deployKey)), repoUrl)
+}
+
+func (p gitGitProvider) UploadNamedDeployKey(ctx context.Context, repoUrl RepoURL, name string, deployKey []byte) error {
+	return p.UploadDeployKey(ctx, repoUrl, deployKey)
+}
+
+func (p gitGitProvider) ListDeployKeys(ctx context.Context, repoUrl RepoURL) ([]DeployKey, error) {
+	return nil, fmt.Errorf("%w to list deploy keys", ErrNoProviderAPI)
+}
+
+func (p gitGitProvider) DeleteDeployKey(ctx context.Context, repoUrl RepoURL, id string) error {
+	return fmt.Errorf("%w to delete deploy keys, remove the key %s from %s manually", ErrNoProviderAPI, id, repoUrl)
+}
+
+func (p gitGitProvider) GetDefaultBranch(ctx context.Context, repoUrl RepoURL) (string, error) {
+	return p.defaultBranch(), nil
+}
+
+// GetRepoVisibility returns private, as the visibility of repositories on plain git servers is unknown.
+func (p gitGitProvider) GetRepoVisibility(ctx context.Context, repoUrl RepoURL) (*gitprovider.RepositoryVisibility, error) {
+	visibility := gitprovider.RepositoryVisibilityPrivate
+
+	return &visibility, nil
+}
+
+func (p gitGitProvider) CreatePullRequest(ctx context.Context, repoUrl RepoURL, prInfo PullRequestInfo) (gitprovider.PullRequest, error) {
+	if prInfo.TargetBranch == "" {
+		prInfo.TargetBranch = p.defaultBranch()
+	}
+
+	if !prInfo.SkipAddingFilesOnCreation {
+		newBranch := prInfo.NewBranch
+		if p.opts.DirectCommit {
+			newBranch = ""
+		}
+
+		if err := p.pushFiles(ctx, repoUrl, prInfo.TargetBranch, newBranch, prInfo.CommitMessage, prInfo.Files); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.pullRequest(repoUrl, prInfo), nil
+}
+
+func (p gitGitProvider) pullRequest(repoUrl RepoURL, prInfo PullRequestInfo) gitprovider.PullRequest {
+	info := gitprovider.PullRequestInfo{Merged: p.opts.DirectCommit}
+
+	if !p.opts.DirectCommit {
+		info.WebURL = p.compareURL(repoUrl, prInfo)
+	}
+
+	return pullRequest{info: info, apiObj: &prInfo}
+}
+
+// GetOpenPullRequest returns the pushed branch as a pull request if it exists on the remote, as plain
+// git servers have no pull requests. There never is one when the changes are committed directly.
+func (p gitGitProvider) GetOpenPullRequest(ctx context.Context, repoUrl RepoURL, branch string) (gitprovider.PullRequest, error) {
+	if p.opts.DirectCommit {
+		return nil, nil
+	}
+
+	commits, err := p.GetCommits(ctx, repoUrl, branch, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(commits) == 0 {
+		return nil, nil
+	}
+
+	return p.pullRequest(repoUrl, PullRequestInfo{NewBranch: branch, TargetBranch: p.defaultBranch()}), nil
+}
+
+// GetClosedPullRequest returns nil, plain git servers have no pull requests.
+func (p gitGitProvider) GetClosedPullRequest(ctx context.Context, repoUrl RepoURL, branch string) (gitprovider.PullRequest, error) {
+	return nil, nil
+}
+
+// DeleteBranch does nothing, GetOpenPullRequest reports every pushed branch as open so the changes are
+// committed to existing branches.
+func (p gitGitProvider) DeleteBranch(ctx context.Context, repoUrl RepoURL, branch string) error {
+	return nil
+}
+
+// CommitFiles commits the files to an existing branch and pushes it.
+func (p gitGitProvider) CommitFiles(ctx context.Context, repoUrl RepoURL, branch, commitMessage string, files []gitprovider.CommitFile) error {
+	return p.pushFiles(ctx, repoUrl, branch, "", commitMessage, files)
+}
+
+// pushFiles commits the files on the branch, or on a new branch created from it, and pushes the commit.
+func (p gitGitProvider) pushFiles(ctx context.Context, repoUrl RepoURL, branch, newBranch, message string, files []gitprovider.CommitFile) error {
+	client, dir, err := p.clone(ctx, repoUrl, branch)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if newBranch != "" {
+		if err := client.Checkout(newBranch); err != nil {
+			return fmt.Errorf("error creating branch %s: %w", newBranch, err)
+		}
+
+		branch = newBranch
+	}
+
+	for _, file := range files {
+		if file.Content == nil {
+			if err := client.Remove(*file.Path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("error removing %s: %w", *file.Path, err)
+			}
+
+			continue
+		}
+
+		if err := client.Write(*file.Path, []byte(*file.Content)); err != nil {
+			return fmt.Errorf("error writing %s: %w", *file.Path, err)
+		}
+	}
+
+	if _, err := client.Commit(git.Commit{Message: message}); err != nil {
+		return fmt.Errorf("error creating commit %s: %w", branch, err)
+	}
+
+	if err := client.Push(ctx); err != nil {
+		return fmt.Errorf("error pushing %s: %w", branch, err)
+	}
+
+	return nil
+}
+
+// compareURL returns the URL to open a pull request for the pushed branch, or a reference to the
+// branch when no compare URL is configured.
+func (p gitGitProvider) compareURL(repoUrl RepoURL, prInfo PullRequestInfo) string {
+	if p.opts.CompareURL == "" {
+		return fmt.Sprintf("%s (branch %s)", repoUrl, prInfo.NewBranch)
+	}
+
+	return strings.NewReplacer("{branch}", prInfo.NewBranch, "{base}", prInfo.TargetBranch).Replace(p.opts.CompareURL)
+}
+
+func (p gitGitProvider) GetCommits(ctx context.Context, repoUrl RepoURL, targetBranch string, pageSize int, pageToken int) ([]gitprovider.Commit, error) {
+	client, dir, err := p.clone(ctx, repoUrl, targetBranch)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	repo, err := client.Open(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %w", repoUrl, err)
+	}
+
+	log, err := repo.Log(&gogit.LogOptions{})
+	if err != nil {
+		// the repository has no commits yet
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return []gitprovider.Commit{}, nil
+		}
+
+		return nil, fmt.Errorf("error getting commits: %s", err)
+	}
+	defer log.Close()
+
+	commits := []gitprovider.Commit{}
+	skip := pageSize * pageToken
+
+	err = log.ForEach(func(c *object.Commit) error {
+		if skip > 0 {
+			skip--
+			return nil
+		}
+
+		if len(commits) == pageSize {
+			return storer.ErrStop
+		}
+
+		commits = append(commits, commit{
+			info: gitprovider.CommitInfo{
+				Sha:       c.Hash.String(),
+				TreeSha:   c.TreeHash.String(),
+				Author:    c.Author.Name,
+				Message:   c.Message,
+				CreatedAt: c.Author.When,
+			},
+			apiObj: c,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting commits: %s", err)
+	}
+
+	return commits, nil
+}
+
+func (p gitGitProvider) GetProviderDomain() string {
+	return p.domain
+}
+
+// GetRepoDirFiles returns the files found in the subdirectory of a repository.
+// Like for the other providers, it does not get the files of nested directories.
+func (p gitGitProvider) GetRepoDirFiles(ctx context.Context, repoUrl RepoURL, dirPath, targetBranch string) ([]*gitprovider.CommitFile, error) {
+	client, dir, err := p.clone(ctx, repoUrl, targetBranch)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	entries, err := ioutil.ReadDir(filepath.Join(dir, dirPath))
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", dirPath, err)
+	}
+
+	files := []*gitprovider.CommitFile{}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dirPath, entry.Name())
+
+		content, err := client.Read(path)
+		if err != nil {
+			return nil, err
+		}
+
+		c := string(content)
+		files = append(files, &gitprovider.CommitFile{
+			Path:    &path,
+			Content: &c,
+		})
+	}
+
+	return files, nil
+}
+
+// GetPullRequestStatus returns a merged pull request when the changes were committed to the target branch,
+// plain git servers have no checks or reviews otherwise.
+func (p gitGitProvider) GetPullRequestStatus(ctx context.Context, repoUrl RepoURL, pullRequestNumber int) (PullRequestStatus, error) {
+	if p.opts.DirectCommit {
+		return PullRequestStatus{Number: pullRequestNumber, State: PullRequestMerged, Checks: ChecksPassed}, nil
+	}
+
+	return PullRequestStatus{}, fmt.Errorf("%w to get the status of pull requests, check the pushed branch manually", ErrNoProviderAPI)
+}
+
+// MergePullRequest succeeds when the changes were committed to the target branch, plain git servers
+// have no pull requests to merge otherwise.
+func (p gitGitProvider) MergePullRequest(ctx context.Context, repoUrl RepoURL, pullRequestNumber int, mergeMethod gitprovider.MergeMethod, commitMesage string) error {
+	if p.opts.DirectCommit {
+		return nil
+	}
+
+	return fmt.Errorf("%w to merge pull requests, merge the pushed branch manually", ErrNoProviderAPI)
+}
+
+// GetAuthenticatedUser fails as git remotes don't tell who the user is, the author of the commits is
+// configured on the git client instead.
+func (p gitGitProvider) GetAuthenticatedUser(ctx context.Context) (git.Author, error) {
+	return git.Author{}, fmt.Errorf("%w to get the authenticated user", ErrNoProviderAPI)
+}
+
+func (p gitGitProvider) clone(ctx context.Context, repoUrl RepoURL, branch string) (git.Git, string, error) {
+	dir, err := ioutil.TempDir("", "git-provider-")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed creating temp. directory to clone repo: %w", err)
+	}
+
+	client := p.newGit()
+
+	if _, err := client.Clone(ctx, dir, p.remote(repoUrl), branch); err != nil {
+		os.RemoveAll(dir)
+		return nil, "", fmt.Errorf("failed cloning repo: %s: %w", repoUrl, err)
+	}
+
+	return client, dir, nil
+}
+
+func (p gitGitProvider) defaultBranch() string {
+	if p.opts.DefaultBranch != "" {
+		return p.opts.DefaultBranch
+	}
+
+	return defaultGitBranch
+}
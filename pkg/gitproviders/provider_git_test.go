@@ -0,0 +1,244 @@
+package gitproviders
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+)
+
+var _ = Describe("Git Provider", func() {
+	var (
+		ctx      context.Context
+		provider gitGitProvider
+		remote   string
+		repoUrl  RepoURL
+		opts     GitOptions
+	)
+
+	systemPath := ".weave-gitops/clusters/prod/system"
+
+	// readRemote returns the content of a file on a branch of the remote repository.
+	readRemote := func(branch, path string) string {
+		repo, err := gogit.PlainOpen(remote)
+		Expect(err).NotTo(HaveOccurred())
+
+		ref, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+		Expect(err).NotTo(HaveOccurred())
+
+		c, err := repo.CommitObject(ref.Hash())
+		Expect(err).NotTo(HaveOccurred())
+
+		f, err := c.File(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		content, err := f.Contents()
+		Expect(err).NotTo(HaveOccurred())
+
+		return content
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		opts = GitOptions{}
+
+		dir, err := ioutil.TempDir("", "git-provider-test-")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+
+		// seed a bare remote with a commit on main
+		remote = filepath.Join(dir, "remote.git")
+		_, err = gogit.PlainInit(remote, true)
+		Expect(err).NotTo(HaveOccurred())
+
+		seed, err := gogit.PlainInit(filepath.Join(dir, "seed"), false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(seed.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("main")))).To(Succeed())
+
+		Expect(os.MkdirAll(filepath.Join(dir, "seed", systemPath, "nested"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "seed", systemPath, "profiles.yaml"), []byte("kind: HelmRelease\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "seed", systemPath, "nested", "app.yaml"), []byte("kind: Kustomization\n"), 0644)).To(Succeed())
+
+		wt, err := seed.Worktree()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wt.AddGlob(".")).To(Succeed())
+		_, err = wt.Commit("Initial commit", &gogit.CommitOptions{Author: &object.Signature{Name: "jdoe", Email: "jdoe@example.com", When: time.Now()}})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = seed.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remote}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(seed.Push(&gogit.PushOptions{})).To(Succeed())
+
+		viper.Set("git-host-types", "git.example.com=git")
+		DeferCleanup(viper.Set, "git-host-types", "")
+
+		repoUrl, err = NewRepoURL("ssh://git@git.example.com/owner/config-repo.git")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		p, err := New(Config{Provider: GitProviderGit, Hostname: "git.example.com", Git: opts}, "owner", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		provider = p.(gitGitProvider)
+		provider.remote = func(RepoURL) string { return remote }
+	})
+
+	It("checks whether the repository exists", func() {
+		exists, err := provider.RepositoryExists(ctx, repoUrl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeTrue())
+	})
+
+	It("can't upload deploy keys", func() {
+		exists, err := provider.DeployKeyExists(ctx, repoUrl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeFalse())
+
+		err = provider.UploadDeployKey(ctx, repoUrl, []byte("ssh-ed25519 AAAA\n"))
+		Expect(err).To(MatchError(ErrNoProviderAPI))
+		Expect(err).To(MatchError(ContainSubstring("add the key ssh-ed25519 AAAA to ssh://git@git.example.com/owner/config-repo.git manually")))
+	})
+
+	It("can't get the authenticated user", func() {
+		_, err := provider.GetAuthenticatedUser(ctx)
+		Expect(err).To(MatchError(ErrNoProviderAPI))
+	})
+
+	It("gets the commits of a branch", func() {
+		commits, err := provider.GetCommits(ctx, repoUrl, "main", 10, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Get().Author).To(Equal("jdoe"))
+		Expect(commits[0].Get().Message).To(Equal("Initial commit"))
+
+		commits, err = provider.GetCommits(ctx, repoUrl, "main", 10, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commits).To(BeEmpty())
+	})
+
+	It("reads the files of a directory from a clone", func() {
+		files, err := provider.GetRepoDirFiles(ctx, repoUrl, systemPath, "main")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+		Expect(*files[0].Path).To(Equal(systemPath + "/profiles.yaml"))
+		Expect(*files[0].Content).To(Equal("kind: HelmRelease\n"))
+	})
+
+	Context("pull requests", func() {
+		var prInfo PullRequestInfo
+
+		BeforeEach(func() {
+			path := systemPath + "/profiles.yaml"
+			content := "kind: HelmRelease\nmetadata:\n  name: podinfo\n"
+
+			prInfo = PullRequestInfo{
+				Title:         "GitOps add podinfo",
+				CommitMessage: "Add profile manifests",
+				NewBranch:     "gitops-add-profile",
+				Files:         []gitprovider.CommitFile{{Path: &path, Content: &content}},
+			}
+		})
+
+		When("a compare URL is configured", func() {
+			BeforeEach(func() {
+				opts.CompareURL = "https://git.example.com/owner/config-repo/compare/{base}...{branch}"
+			})
+
+			It("pushes a branch and returns its compare URL", func() {
+				pr, err := provider.CreatePullRequest(ctx, repoUrl, prInfo)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pr.Get().WebURL).To(Equal("https://git.example.com/owner/config-repo/compare/main...gitops-add-profile"))
+				Expect(pr.Get().Merged).To(BeFalse())
+
+				Expect(readRemote("gitops-add-profile", systemPath+"/profiles.yaml")).To(Equal(*prInfo.Files[0].Content))
+				Expect(readRemote("main", systemPath+"/profiles.yaml")).To(Equal("kind: HelmRelease\n"))
+
+				Expect(provider.MergePullRequest(ctx, repoUrl, 0, gitprovider.MergeMethodMerge, "merge")).To(MatchError(ErrNoProviderAPI))
+
+				_, err = provider.GetPullRequestStatus(ctx, repoUrl, 0)
+				Expect(err).To(MatchError(ErrNoProviderAPI))
+			})
+		})
+
+		It("finds the pushed branch and commits to it", func() {
+			pr, err := provider.GetOpenPullRequest(ctx, repoUrl, "gitops-add-profile")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pr).To(BeNil())
+
+			_, err = provider.CreatePullRequest(ctx, repoUrl, prInfo)
+			Expect(err).NotTo(HaveOccurred())
+
+			pr, err = provider.GetOpenPullRequest(ctx, repoUrl, "gitops-add-profile")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pr.Get().WebURL).To(Equal("ssh://git@git.example.com/owner/config-repo.git (branch gitops-add-profile)"))
+
+			path := systemPath + "/profiles.yaml"
+			content := "kind: HelmRelease\nmetadata:\n  name: podinfo-v2\n"
+
+			Expect(provider.CommitFiles(ctx, repoUrl, "gitops-add-profile", "Update profile manifests", []gitprovider.CommitFile{
+				{Path: &path, Content: &content},
+			})).To(Succeed())
+			Expect(readRemote("gitops-add-profile", path)).To(Equal(content))
+			Expect(readRemote("main", path)).To(Equal("kind: HelmRelease\n"))
+		})
+
+		It("names the pushed branch without a compare URL", func() {
+			pr, err := provider.CreatePullRequest(ctx, repoUrl, prInfo)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pr.Get().WebURL).To(Equal("ssh://git@git.example.com/owner/config-repo.git (branch gitops-add-profile)"))
+		})
+
+		When("committing directly", func() {
+			BeforeEach(func() {
+				opts.DirectCommit = true
+			})
+
+			It("commits the changes to the target branch", func() {
+				pr, err := provider.CreatePullRequest(ctx, repoUrl, prInfo)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pr.Get().Merged).To(BeTrue())
+
+				Expect(readRemote("main", systemPath+"/profiles.yaml")).To(Equal(*prInfo.Files[0].Content))
+				Expect(provider.MergePullRequest(ctx, repoUrl, 0, gitprovider.MergeMethodMerge, "merge")).To(Succeed())
+
+				status, err := provider.GetPullRequestStatus(ctx, repoUrl, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(status.State).To(Equal(PullRequestMerged))
+			})
+		})
+
+		When("the git client is configured", func() {
+			BeforeEach(func() {
+				opts.ClientOptions = []git.Option{git.WithAuthor(git.Author{Name: "Jane Doe", Email: "jdoe@example.com"})}
+			})
+
+			It("commits with the options of the client", func() {
+				_, err := provider.CreatePullRequest(ctx, repoUrl, prInfo)
+				Expect(err).NotTo(HaveOccurred())
+
+				repo, err := gogit.PlainOpen(remote)
+				Expect(err).NotTo(HaveOccurred())
+
+				ref, err := repo.Reference(plumbing.NewBranchReferenceName("gitops-add-profile"), true)
+				Expect(err).NotTo(HaveOccurred())
+
+				c, err := repo.CommitObject(ref.Hash())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(c.Author.Name).To(Equal("Jane Doe"))
+				Expect(c.Author.Email).To(Equal("jdoe@example.com"))
+			})
+		})
+	})
+})
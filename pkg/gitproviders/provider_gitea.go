@@ -0,0 +1,566 @@
+package gitproviders
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+
+	"github.com/weaveworks/weave-gitops/pkg/git"
+)
+
+// giteaGitProvider talks to the REST API of Gitea, where user and organization repositories share the same endpoints.
+type giteaGitProvider struct {
+	domain string
+	client restClient
+}
+
+var _ GitProvider = giteaGitProvider{}
+
+type giteaRepository struct {
+	DefaultBranch string `json:"default_branch"`
+	Private       bool   `json:"private"`
+	Internal      bool   `json:"internal"`
+}
+
+type giteaCommit struct {
+	Sha     string    `json:"sha"`
+	HTMLURL string    `json:"html_url"`
+	Created time.Time `json:"created"`
+	Commit  struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name string `json:"name"`
+		} `json:"author"`
+		Tree struct {
+			Sha string `json:"sha"`
+		} `json:"tree"`
+	} `json:"commit"`
+}
+
+type giteaPullRequest struct {
+	Number         int    `json:"number"`
+	HTMLURL        string `json:"html_url"`
+	State          string `json:"state"`
+	Merged         bool   `json:"merged"`
+	MergeCommitSha string `json:"merge_commit_sha"`
+	Head           struct {
+		Ref string `json:"ref"`
+		Sha string `json:"sha"`
+	} `json:"head"`
+}
+
+type giteaCombinedStatus struct {
+	State      string `json:"state"`
+	TotalCount int    `json:"total_count"`
+}
+
+type giteaReview struct {
+	State     string `json:"state"`
+	Dismissed bool   `json:"dismissed"`
+	Stale     bool   `json:"stale"`
+	User      struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+type giteaDeployKey struct {
+	ID        int        `json:"id,omitempty"`
+	Title     string     `json:"title"`
+	Key       string     `json:"key"`
+	ReadOnly  bool       `json:"read_only"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+type giteaContent struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+	Sha  string `json:"sha"`
+}
+
+type giteaUser struct {
+	Login    string `json:"login"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+type giteaFileChange struct {
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	Content   string `json:"content,omitempty"`
+	Sha       string `json:"sha,omitempty"`
+}
+
+func newGiteaGitProvider(config Config) (GitProvider, error) {
+	client, err := newRestClient(config, "token", "/api/v1")
+	if err != nil {
+		return nil, err
+	}
+
+	return giteaGitProvider{
+		domain: apiHostname(config.Hostname),
+		client: client,
+	}, nil
+}
+
+func (p giteaGitProvider) RepositoryExists(ctx context.Context, repoUrl RepoURL) (bool, error) {
+	if _, err := p.getRepo(ctx, repoUrl); err != nil {
+		if errors.Is(err, gitprovider.ErrNotFound) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("could not get verify repository exists  %w", err)
+	}
+
+	return true, nil
+}
+
+func (p giteaGitProvider) DeployKeyExists(ctx context.Context, repoUrl RepoURL) (bool, error) {
+	var keys []giteaDeployKey
+	if err := p.client.get(ctx, p.repoPath(repoUrl)+"/keys", nil, &keys); err != nil {
+		return false, fmt.Errorf("error getting deploy key %s: %s", DeployKeyName, err)
+	}
+
+	for _, k := range keys {
+		if IsManagedDeployKey(k.Title) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (p giteaGitProvider) UploadDeployKey(ctx context.Context, repoUrl RepoURL, deployKey []byte) error {
+	return p.UploadNamedDeployKey(ctx, repoUrl, DeployKeyName, deployKey)
+}
+
+func (p giteaGitProvider) UploadNamedDeployKey(ctx context.Context, repoUrl RepoURL, name string, deployKey []byte) error {
+	key := giteaDeployKey{
+		Title:    name,
+		Key:      strings.TrimSpace(string(deployKey)),
+		ReadOnly: false,
+	}
+
+	if err := p.client.post(ctx, p.repoPath(repoUrl)+"/keys", key, nil); err != nil {
+		if errors.Is(err, gitprovider.ErrNotFound) {
+			return ErrRepositoryNoPermissionsOrDoesNotExist
+		}
+
+		return fmt.Errorf("error uploading deploy key %s", err)
+	}
+
+	return nil
+}
+
+func (p giteaGitProvider) ListDeployKeys(ctx context.Context, repoUrl RepoURL) ([]DeployKey, error) {
+	var keys []giteaDeployKey
+	if err := p.client.get(ctx, p.repoPath(repoUrl)+"/keys", nil, &keys); err != nil {
+		return nil, fmt.Errorf("error listing deploy keys: %w", err)
+	}
+
+	deployKeys := []DeployKey{}
+
+	for _, k := range keys {
+		if !IsManagedDeployKey(k.Title) {
+			continue
+		}
+
+		key := DeployKey{
+			ID:       strconv.Itoa(k.ID),
+			Name:     k.Title,
+			Key:      []byte(k.Key),
+			ReadOnly: k.ReadOnly,
+		}
+
+		if k.CreatedAt != nil {
+			key.CreatedAt = *k.CreatedAt
+		}
+
+		deployKeys = append(deployKeys, key)
+	}
+
+	return deployKeys, nil
+}
+
+func (p giteaGitProvider) DeleteDeployKey(ctx context.Context, repoUrl RepoURL, id string) error {
+	if err := p.client.delete(ctx, p.repoPath(repoUrl)+"/keys/"+url.PathEscape(id)); err != nil {
+		return fmt.Errorf("error deleting deploy key %s: %w", id, err)
+	}
+
+	return nil
+}
+
+func (p giteaGitProvider) GetDefaultBranch(ctx context.Context, repoUrl RepoURL) (string, error) {
+	repo, err := p.getRepo(ctx, repoUrl)
+	if err != nil {
+		return "main", err
+	}
+
+	return repo.DefaultBranch, nil
+}
+
+func (p giteaGitProvider) GetRepoVisibility(ctx context.Context, repoUrl RepoURL) (*gitprovider.RepositoryVisibility, error) {
+	repo, err := p.getRepo(ctx, repoUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	visibility := gitprovider.RepositoryVisibilityPublic
+	if repo.Private {
+		visibility = gitprovider.RepositoryVisibilityPrivate
+	} else if repo.Internal {
+		visibility = gitprovider.RepositoryVisibilityInternal
+	}
+
+	return &visibility, nil
+}
+
+func (p giteaGitProvider) CreatePullRequest(ctx context.Context, repoUrl RepoURL, prInfo PullRequestInfo) (gitprovider.PullRequest, error) {
+	if prInfo.TargetBranch == "" {
+		branch, err := p.GetDefaultBranch(ctx, repoUrl)
+		if err != nil {
+			return nil, fmt.Errorf("error getting default branch: %w", err)
+		}
+
+		prInfo.TargetBranch = branch
+	}
+
+	if !prInfo.SkipAddingFilesOnCreation {
+		if err := p.commitFiles(ctx, repoUrl, prInfo.TargetBranch, prInfo.NewBranch, prInfo.CommitMessage, prInfo.Files); err != nil {
+			return nil, fmt.Errorf("error creating commit %s: %w", prInfo.NewBranch, err)
+		}
+	}
+
+	req := map[string]string{
+		"title": prInfo.Title,
+		"body":  prInfo.Description,
+		"head":  prInfo.NewBranch,
+		"base":  prInfo.TargetBranch,
+	}
+
+	var pr giteaPullRequest
+	if err := p.client.post(ctx, p.repoPath(repoUrl)+"/pulls", req, &pr); err != nil {
+		return nil, fmt.Errorf("error creating pull request %s: %w", prInfo.Title, err)
+	}
+
+	return newGiteaPullRequest(&pr), nil
+}
+
+func newGiteaPullRequest(pr *giteaPullRequest) gitprovider.PullRequest {
+	return pullRequest{
+		info: gitprovider.PullRequestInfo{
+			Merged: pr.Merged,
+			Number: pr.Number,
+			WebURL: pr.HTMLURL,
+		},
+		apiObj: pr,
+	}
+}
+
+// GetOpenPullRequest returns the open pull request of a branch, or nil if there is none.
+func (p giteaGitProvider) GetOpenPullRequest(ctx context.Context, repoUrl RepoURL, branch string) (gitprovider.PullRequest, error) {
+	query := url.Values{
+		"state": {"open"},
+		"limit": {"50"},
+	}
+
+	var prs []giteaPullRequest
+	if err := p.client.get(ctx, p.repoPath(repoUrl)+"/pulls", query, &prs); err != nil {
+		return nil, fmt.Errorf("error listing pull requests: %w", err)
+	}
+
+	for i := range prs {
+		if prs[i].Head.Ref == branch {
+			return newGiteaPullRequest(&prs[i]), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// GetClosedPullRequest returns the latest merged or closed pull request of a branch, or nil if there is none.
+func (p giteaGitProvider) GetClosedPullRequest(ctx context.Context, repoUrl RepoURL, branch string) (gitprovider.PullRequest, error) {
+	query := url.Values{
+		"state": {"closed"},
+		"sort":  {"recentupdate"},
+		"limit": {"50"},
+	}
+
+	var prs []giteaPullRequest
+	if err := p.client.get(ctx, p.repoPath(repoUrl)+"/pulls", query, &prs); err != nil {
+		return nil, fmt.Errorf("error listing pull requests: %w", err)
+	}
+
+	for i := range prs {
+		if prs[i].Head.Ref == branch {
+			return newGiteaPullRequest(&prs[i]), nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (p giteaGitProvider) DeleteBranch(ctx context.Context, repoUrl RepoURL, branch string) error {
+	if err := p.client.delete(ctx, p.repoPath(repoUrl)+"/branches/"+url.PathEscape(branch)); err != nil && !errors.Is(err, gitprovider.ErrNotFound) {
+		return fmt.Errorf("error deleting branch %s: %w", branch, err)
+	}
+
+	return nil
+}
+
+// CommitFiles commits the files to an existing branch.
+func (p giteaGitProvider) CommitFiles(ctx context.Context, repoUrl RepoURL, branch, commitMessage string, files []gitprovider.CommitFile) error {
+	if err := p.commitFiles(ctx, repoUrl, branch, "", commitMessage, files); err != nil {
+		return fmt.Errorf("error creating commit %s: %w", branch, err)
+	}
+
+	return nil
+}
+
+// commitFiles creates a single commit changing all the files on the branch, or on a new branch created from it.
+// Files without content are deleted.
+func (p giteaGitProvider) commitFiles(ctx context.Context, repoUrl RepoURL, branch, newBranch, message string, files []gitprovider.CommitFile) error {
+	changes := []giteaFileChange{}
+
+	for _, file := range files {
+		sha, err := p.fileSha(ctx, repoUrl, *file.Path, branch)
+		if err != nil {
+			return err
+		}
+
+		change := giteaFileChange{Path: *file.Path, Sha: sha}
+
+		switch {
+		case file.Content == nil:
+			change.Operation = "delete"
+		case sha == "":
+			change.Operation = "create"
+		default:
+			change.Operation = "update"
+		}
+
+		if file.Content != nil {
+			change.Content = base64.StdEncoding.EncodeToString([]byte(*file.Content))
+		}
+
+		changes = append(changes, change)
+	}
+
+	req := map[string]interface{}{
+		"branch":  branch,
+		"message": message,
+		"files":   changes,
+	}
+
+	if newBranch != "" {
+		req["new_branch"] = newBranch
+	}
+
+	return p.client.post(ctx, p.repoPath(repoUrl)+"/contents", req, nil)
+}
+
+// fileSha returns the blob sha of a file, required to update or delete it, or an empty string if it doesn't exist.
+func (p giteaGitProvider) fileSha(ctx context.Context, repoUrl RepoURL, path, branch string) (string, error) {
+	var content giteaContent
+	if err := p.client.get(ctx, p.repoPath(repoUrl)+"/contents/"+escapePath(path), url.Values{"ref": {branch}}, &content); err != nil {
+		if errors.Is(err, gitprovider.ErrNotFound) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("error getting %s: %w", path, err)
+	}
+
+	return content.Sha, nil
+}
+
+func (p giteaGitProvider) GetCommits(ctx context.Context, repoUrl RepoURL, targetBranch string, pageSize int, pageToken int) ([]gitprovider.Commit, error) {
+	query := url.Values{
+		"sha":   {targetBranch},
+		"limit": {strconv.Itoa(pageSize)},
+		"page":  {strconv.Itoa(pageToken + 1)},
+	}
+
+	var page []giteaCommit
+	if err := p.client.get(ctx, p.repoPath(repoUrl)+"/commits", query, &page); err != nil {
+		if isEmptyRepoError(err) {
+			return []gitprovider.Commit{}, nil
+		}
+
+		return nil, fmt.Errorf("error getting commits: %s", err)
+	}
+
+	commits := []gitprovider.Commit{}
+
+	for i := range page {
+		c := page[i]
+
+		commits = append(commits, commit{
+			info: gitprovider.CommitInfo{
+				Sha:       c.Sha,
+				TreeSha:   c.Commit.Tree.Sha,
+				Author:    c.Commit.Author.Name,
+				Message:   c.Commit.Message,
+				CreatedAt: c.Created,
+				URL:       c.HTMLURL,
+			},
+			apiObj: &c,
+		})
+	}
+
+	return commits, nil
+}
+
+func (p giteaGitProvider) GetProviderDomain() string {
+	return p.domain
+}
+
+// GetRepoDirFiles returns the files found in the subdirectory of a repository.
+// Like for the other providers, it does not get the files of nested directories.
+func (p giteaGitProvider) GetRepoDirFiles(ctx context.Context, repoUrl RepoURL, dirPath, targetBranch string) ([]*gitprovider.CommitFile, error) {
+	ref := url.Values{"ref": {targetBranch}}
+
+	var entries []giteaContent
+	if err := p.client.get(ctx, p.repoPath(repoUrl)+"/contents/"+escapePath(dirPath), ref, &entries); err != nil {
+		return nil, err
+	}
+
+	files := []*gitprovider.CommitFile{}
+
+	for _, entry := range entries {
+		if entry.Type != "file" {
+			continue
+		}
+
+		content, err := p.client.send(ctx, http.MethodGet, p.repoPath(repoUrl)+"/raw/"+escapePath(entry.Path), ref, "", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		path := entry.Path
+		c := string(content)
+		files = append(files, &gitprovider.CommitFile{
+			Path:    &path,
+			Content: &c,
+		})
+	}
+
+	return files, nil
+}
+
+// GetPullRequestStatus returns the state, checks and reviews of a pull request.
+func (p giteaGitProvider) GetPullRequestStatus(ctx context.Context, repoUrl RepoURL, pullRequestNumber int) (PullRequestStatus, error) {
+	prPath := fmt.Sprintf("%s/pulls/%d", p.repoPath(repoUrl), pullRequestNumber)
+
+	var pr giteaPullRequest
+	if err := p.client.get(ctx, prPath, nil, &pr); err != nil {
+		return PullRequestStatus{}, fmt.Errorf("error getting pull request %d: %w", pullRequestNumber, err)
+	}
+
+	status := PullRequestStatus{
+		Number:  pr.Number,
+		WebURL:  pr.HTMLURL,
+		State:   PullRequestOpen,
+		HeadSha: pr.Head.Sha,
+		Checks:  ChecksNone,
+	}
+
+	switch {
+	case pr.Merged:
+		status.State = PullRequestMerged
+		status.MergeCommitSha = pr.MergeCommitSha
+	case pr.State == "closed":
+		status.State = PullRequestClosed
+	}
+
+	var combined giteaCombinedStatus
+	if err := p.client.get(ctx, fmt.Sprintf("%s/commits/%s/status", p.repoPath(repoUrl), pr.Head.Sha), nil, &combined); err != nil {
+		return PullRequestStatus{}, fmt.Errorf("error getting the statuses of commit %s: %w", pr.Head.Sha, err)
+	}
+
+	if combined.TotalCount > 0 {
+		switch combined.State {
+		case "success", "warning":
+			status.Checks = ChecksPassed
+		case "pending":
+			status.Checks = ChecksPending
+		default:
+			status.Checks = ChecksFailed
+		}
+	}
+
+	var reviews []giteaReview
+	if err := p.client.get(ctx, prPath+"/reviews", nil, &reviews); err != nil {
+		return PullRequestStatus{}, fmt.Errorf("error getting the reviews of pull request %d: %w", pullRequestNumber, err)
+	}
+
+	// only the latest approval or change request of each reviewer counts
+	latest := map[string]string{}
+
+	for _, review := range reviews {
+		if review.Dismissed || review.Stale || (review.State != "APPROVED" && review.State != "REQUEST_CHANGES") {
+			continue
+		}
+
+		latest[review.User.Login] = review.State
+	}
+
+	for _, state := range latest {
+		if state == "APPROVED" {
+			status.Approvals++
+		} else {
+			status.ChangesRequested = true
+		}
+	}
+
+	return status, nil
+}
+
+// MergePullRequest merges a pull request given the repository's URL and the PR's number with a merge method and a commit message.
+func (p giteaGitProvider) MergePullRequest(ctx context.Context, repoUrl RepoURL, pullRequestNumber int, mergeMethod gitprovider.MergeMethod, commitMesage string) error {
+	if mergeMethod == "" {
+		mergeMethod = gitprovider.MergeMethodMerge
+	}
+
+	// Gitea names the merge methods like go-git-providers does
+	req := map[string]string{
+		"Do":                string(mergeMethod),
+		"MergeMessageField": commitMesage,
+	}
+
+	return p.client.post(ctx, fmt.Sprintf("%s/pulls/%d/merge", p.repoPath(repoUrl), pullRequestNumber), req, nil)
+}
+
+func (p giteaGitProvider) getRepo(ctx context.Context, repoUrl RepoURL) (*giteaRepository, error) {
+	var repo giteaRepository
+	if err := p.client.get(ctx, p.repoPath(repoUrl), nil, &repo); err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
+
+// GetAuthenticatedUser returns the name and email of the user the provider is authenticated as.
+func (p giteaGitProvider) GetAuthenticatedUser(ctx context.Context) (git.Author, error) {
+	var user giteaUser
+	if err := p.client.get(ctx, "/user", nil, &user); err != nil {
+		return git.Author{}, fmt.Errorf("error getting the authenticated user: %w", err)
+	}
+
+	author := git.Author{Name: user.FullName, Email: user.Email}
+	if author.Name == "" {
+		author.Name = user.Login
+	}
+
+	return author, nil
+}
+
+func (p giteaGitProvider) repoPath(repoUrl RepoURL) string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(repoUrl.Owner()), url.PathEscape(repoUrl.RepositoryName()))
+}
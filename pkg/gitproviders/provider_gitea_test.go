@@ -0,0 +1,216 @@
+package gitproviders
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/weaveworks/weave-gitops/pkg/git"
+)
+
+var _ = Describe("Gitea Provider", func() {
+	var (
+		ctx      context.Context
+		provider GitProvider
+		replay   *replayer
+		repoUrl  RepoURL
+	)
+
+	newRepoURL := func(uri string) RepoURL {
+		u, err := NewRepoURL(uri)
+		Expect(err).NotTo(HaveOccurred())
+
+		return u
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		viper.Set("git-host-types", "gitea.example.com=gitea")
+
+		p, err := New(Config{
+			Provider: GitProviderGitea,
+			Hostname: "gitea.example.com",
+			Token:    "token",
+		}, "owner", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		replay = newReplayer("cache/gitea.yaml")
+		gitea := p.(giteaGitProvider)
+		gitea.client.http = &http.Client{Transport: replay}
+		provider = gitea
+
+		repoUrl = newRepoURL("git@gitea.example.com:owner/config-repo.git")
+	})
+
+	AfterEach(func() {
+		viper.Set("git-host-types", "")
+	})
+
+	It("checks whether the repository exists", func() {
+		exists, err := provider.RepositoryExists(ctx, repoUrl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeTrue())
+
+		exists, err = provider.RepositoryExists(ctx, newRepoURL("git@gitea.example.com:owner/missing.git"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeFalse())
+	})
+
+	It("gets the default branch and visibility of the repository", func() {
+		branch, err := provider.GetDefaultBranch(ctx, repoUrl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(branch).To(Equal("main"))
+
+		visibility, err := provider.GetRepoVisibility(ctx, repoUrl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*visibility).To(Equal(gitprovider.RepositoryVisibilityPrivate))
+	})
+
+	It("finds and uploads the deploy key", func() {
+		exists, err := provider.DeployKeyExists(ctx, repoUrl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeTrue())
+
+		Expect(provider.UploadDeployKey(ctx, repoUrl, []byte("ssh-ed25519 AAAA\n"))).To(Succeed())
+		Expect(replay.bodies["POST https://gitea.example.com/api/v1/repos/owner/config-repo/keys"]).To(ConsistOf(
+			`{"title":"wego-deploy-key","key":"ssh-ed25519 AAAA","read_only":false}`,
+		))
+	})
+
+	It("lists and deletes the deploy keys uploaded by gitops", func() {
+		keys, err := provider.ListDeployKeys(ctx, repoUrl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(HaveLen(1))
+		Expect(keys[0].ID).To(Equal("2"))
+		Expect(keys[0].Name).To(Equal(DeployKeyName))
+		Expect(keys[0].CreatedAt).To(Equal(time.Date(2022, 7, 5, 14, 25, 36, 0, time.UTC)))
+
+		Expect(provider.DeleteDeployKey(ctx, repoUrl, "2")).To(Succeed())
+	})
+
+	It("gets the commits of a branch", func() {
+		commits, err := provider.GetCommits(ctx, repoUrl, "main", 1, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Get().Sha).To(Equal("6fb1d9a0a4b6d2d9cb8c5bd5c0e5a1bd8ad3e9f0"))
+		Expect(commits[0].Get().TreeSha).To(Equal("0b9e8a2c3d4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b"))
+		Expect(commits[0].Get().Author).To(Equal("jdoe"))
+		Expect(commits[0].Get().URL).To(Equal("https://gitea.example.com/owner/config-repo/commit/6fb1d9a0a4b6d2d9cb8c5bd5c0e5a1bd8ad3e9f0"))
+	})
+
+	It("returns no commits for an empty repository", func() {
+		commits, err := provider.GetCommits(ctx, newRepoURL("git@gitea.example.com:owner/empty-repo.git"), "main", 10, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commits).To(BeEmpty())
+	})
+
+	It("creates a pull request with a single commit on the new branch", func() {
+		profiles := ".weave-gitops/clusters/prod/system/profiles.yaml"
+		kustomization := ".weave-gitops/clusters/prod/system/kustomization.yaml"
+		wegoSystem := ".weave-gitops/clusters/prod/system/wego-system.yaml"
+		content := "kind: HelmRelease\n"
+
+		pr, err := provider.CreatePullRequest(ctx, repoUrl, PullRequestInfo{
+			Title:         "GitOps add podinfo",
+			Description:   "Add podinfo",
+			CommitMessage: "Add profile manifests",
+			TargetBranch:  "main",
+			NewBranch:     "gitops-add-profile",
+			Files: []gitprovider.CommitFile{
+				{Path: &profiles, Content: &content},
+				{Path: &kustomization, Content: &content},
+				{Path: &wegoSystem},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pr.Get().Number).To(Equal(3))
+		Expect(pr.Get().WebURL).To(Equal("https://gitea.example.com/owner/config-repo/pulls/3"))
+
+		bodies := replay.bodies["POST https://gitea.example.com/api/v1/repos/owner/config-repo/contents"]
+		Expect(bodies).To(HaveLen(1))
+
+		var changes struct {
+			Branch    string            `json:"branch"`
+			NewBranch string            `json:"new_branch"`
+			Message   string            `json:"message"`
+			Files     []giteaFileChange `json:"files"`
+		}
+		Expect(json.Unmarshal([]byte(bodies[0]), &changes)).To(Succeed())
+		Expect(changes.Branch).To(Equal("main"))
+		Expect(changes.NewBranch).To(Equal("gitops-add-profile"))
+		Expect(changes.Message).To(Equal("Add profile manifests"))
+		Expect(changes.Files).To(Equal([]giteaFileChange{
+			{Operation: "update", Path: profiles, Content: "a2luZDogSGVsbVJlbGVhc2UK", Sha: "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391"},
+			{Operation: "create", Path: kustomization, Content: "a2luZDogSGVsbVJlbGVhc2UK"},
+			{Operation: "delete", Path: wegoSystem, Sha: "5716ca5987cbf97d6bb54920bea6adde242d87e6"},
+		}))
+
+		Expect(replay.bodies["POST https://gitea.example.com/api/v1/repos/owner/config-repo/pulls"]).To(ConsistOf(
+			`{"base":"main","body":"Add podinfo","head":"gitops-add-profile","title":"GitOps add podinfo"}`,
+		))
+	})
+
+	It("gets the open pull request of a branch", func() {
+		pr, err := provider.GetOpenPullRequest(ctx, repoUrl, "gitops-add-profile")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pr.Get().Number).To(Equal(3))
+
+		pr, err = provider.GetOpenPullRequest(ctx, repoUrl, "gitops-delete-profile")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pr).To(BeNil())
+	})
+
+	It("commits the files to an existing branch", func() {
+		profiles := ".weave-gitops/clusters/prod/system/profiles.yaml"
+		content := "kind: HelmRelease\n"
+
+		Expect(provider.CommitFiles(ctx, repoUrl, "gitops-add-profile", "Update profile manifests", []gitprovider.CommitFile{
+			{Path: &profiles, Content: &content},
+		})).To(Succeed())
+
+		Expect(replay.bodies["POST https://gitea.example.com/api/v1/repos/owner/config-repo/contents"]).To(ConsistOf(
+			`{"branch":"gitops-add-profile","files":[{"operation":"update","path":".weave-gitops/clusters/prod/system/profiles.yaml","content":"a2luZDogSGVsbVJlbGVhc2UK","sha":"0a1b2c3d4e5f60718293a4b5c6d7e8f901234567"}],"message":"Update profile manifests"}`,
+		))
+	})
+
+	It("merges a pull request", func() {
+		Expect(provider.MergePullRequest(ctx, repoUrl, 3, MergeMethodRebase, "Merge profile")).To(Succeed())
+		Expect(replay.bodies["POST https://gitea.example.com/api/v1/repos/owner/config-repo/pulls/3/merge"]).To(ConsistOf(
+			`{"Do":"rebase","MergeMessageField":"Merge profile"}`,
+		))
+	})
+
+	It("gets the statuses and latest reviews of a pull request", func() {
+		status, err := provider.GetPullRequestStatus(ctx, repoUrl, 3)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(Equal(PullRequestStatus{
+			Number:           3,
+			WebURL:           "https://gitea.example.com/owner/config-repo/pulls/3",
+			State:            PullRequestOpen,
+			HeadSha:          "4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f70",
+			Checks:           ChecksFailed,
+			Approvals:        1,
+			ChangesRequested: true,
+		}))
+	})
+
+	It("gets the files of a directory", func() {
+		files, err := provider.GetRepoDirFiles(ctx, repoUrl, ".weave-gitops/clusters/prod/system", "main")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+		Expect(*files[0].Path).To(Equal(".weave-gitops/clusters/prod/system/profiles.yaml"))
+		Expect(*files[0].Content).To(Equal("kind: HelmRelease\n"))
+	})
+
+	It("gets the authenticated user, named after their login without a full name", func() {
+		user, err := provider.GetAuthenticatedUser(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user).To(Equal(git.Author{Name: "owner", Email: "owner@example.com"}))
+	})
+})
@@ -6,6 +6,8 @@ import (
 	"fmt"
 
 	"github.com/fluxcd/go-git-providers/gitprovider"
+
+	"github.com/weaveworks/weave-gitops/pkg/git"
 )
 
 type orgGitProvider struct {
@@ -41,13 +43,17 @@ func (p orgGitProvider) DeployKeyExists(ctx context.Context, repoUrl RepoURL) (b
 }
 
 func (p orgGitProvider) UploadDeployKey(ctx context.Context, repoUrl RepoURL, deployKey []byte) error {
+	return p.UploadNamedDeployKey(ctx, repoUrl, DeployKeyName, deployKey)
+}
+
+func (p orgGitProvider) UploadNamedDeployKey(ctx context.Context, repoUrl RepoURL, name string, deployKey []byte) error {
 	orgRepo, err := p.getOrgRepo(ctx, repoUrl)
 	if err != nil {
 		return fmt.Errorf("error getting org repo reference for owner %s, repo %s, %w", repoUrl.Owner(), repoUrl.RepositoryName(), err)
 	}
 
 	deployKeyInfo := gitprovider.DeployKeyInfo{
-		Name:     DeployKeyName,
+		Name:     name,
 		Key:      deployKey,
 		ReadOnly: gitprovider.BoolVar(false),
 	}
@@ -55,6 +61,24 @@ func (p orgGitProvider) UploadDeployKey(ctx context.Context, repoUrl RepoURL, de
 	return uploadDeployKey(ctx, orgRepo, deployKeyInfo)
 }
 
+func (p orgGitProvider) ListDeployKeys(ctx context.Context, repoUrl RepoURL) ([]DeployKey, error) {
+	orgRepo, err := p.getOrgRepo(ctx, repoUrl)
+	if err != nil {
+		return nil, fmt.Errorf("error getting org repo reference for owner %s, repo %s, %w", repoUrl.Owner(), repoUrl.RepositoryName(), err)
+	}
+
+	return listDeployKeys(ctx, orgRepo)
+}
+
+func (p orgGitProvider) DeleteDeployKey(ctx context.Context, repoUrl RepoURL, id string) error {
+	orgRepo, err := p.getOrgRepo(ctx, repoUrl)
+	if err != nil {
+		return fmt.Errorf("error getting org repo reference for owner %s, repo %s, %w", repoUrl.Owner(), repoUrl.RepositoryName(), err)
+	}
+
+	return deleteDeployKey(ctx, orgRepo, id)
+}
+
 func (p orgGitProvider) GetDefaultBranch(ctx context.Context, repoUrl RepoURL) (string, error) {
 	repoInfoRef, err := p.getRepoInfoFromUrl(ctx, repoUrl)
 	if err != nil {
@@ -113,6 +137,35 @@ func (p orgGitProvider) CreatePullRequest(ctx context.Context, repoUrl RepoURL,
 	return createPullRequest(ctx, orgRepo, prInfo)
 }
 
+// GetOpenPullRequest returns the open pull request of a branch, or nil if there is none.
+func (p orgGitProvider) GetOpenPullRequest(ctx context.Context, repoUrl RepoURL, branch string) (gitprovider.PullRequest, error) {
+	orgRepo, err := p.getOrgRepo(ctx, repoUrl)
+	if err != nil {
+		return nil, fmt.Errorf("error getting org repo for owner %s, repo %s, %w", repoUrl.Owner(), repoUrl.RepositoryName(), err)
+	}
+
+	return getOpenPullRequest(ctx, orgRepo, branch)
+}
+
+// GetClosedPullRequest returns the latest merged or closed pull request of a branch, or nil if there is none.
+func (p orgGitProvider) GetClosedPullRequest(ctx context.Context, repoUrl RepoURL, branch string) (gitprovider.PullRequest, error) {
+	return getClosedPullRequest(ctx, p.provider, repoUrl, branch)
+}
+
+func (p orgGitProvider) DeleteBranch(ctx context.Context, repoUrl RepoURL, branch string) error {
+	return deleteBranch(ctx, p.provider, repoUrl, branch)
+}
+
+// CommitFiles commits the files to an existing branch.
+func (p orgGitProvider) CommitFiles(ctx context.Context, repoUrl RepoURL, branch, commitMessage string, files []gitprovider.CommitFile) error {
+	orgRepo, err := p.getOrgRepo(ctx, repoUrl)
+	if err != nil {
+		return fmt.Errorf("error getting org repo for owner %s, repo %s, %w", repoUrl.Owner(), repoUrl.RepositoryName(), err)
+	}
+
+	return commitFiles(ctx, orgRepo, branch, commitMessage, files)
+}
+
 func (p orgGitProvider) GetCommits(ctx context.Context, repoUrl RepoURL, targetBranch string, pageSize int, pageToken int) ([]gitprovider.Commit, error) {
 	orgRepo, err := p.getOrgRepo(ctx, repoUrl)
 	if err != nil {
@@ -142,12 +195,22 @@ func (p orgGitProvider) GetRepoDirFiles(ctx context.Context, repoUrl RepoURL, di
 	return files, nil
 }
 
-// MergePullRequest merges a pull request given the repository's URL and the PR's number with a commit message.
-func (p orgGitProvider) MergePullRequest(ctx context.Context, repoUrl RepoURL, pullRequestNumber int, commitMesage string) error {
+// GetPullRequestStatus returns the state, checks and reviews of a pull request.
+func (p orgGitProvider) GetPullRequestStatus(ctx context.Context, repoUrl RepoURL, pullRequestNumber int) (PullRequestStatus, error) {
+	return getPullRequestStatus(ctx, p.provider, repoUrl, pullRequestNumber)
+}
+
+// MergePullRequest merges a pull request given the repository's URL and the PR's number with a merge method and a commit message.
+func (p orgGitProvider) MergePullRequest(ctx context.Context, repoUrl RepoURL, pullRequestNumber int, mergeMethod gitprovider.MergeMethod, commitMesage string) error {
 	repo, err := p.getOrgRepo(ctx, repoUrl)
 	if err != nil {
 		return err
 	}
 
-	return repo.PullRequests().Merge(ctx, pullRequestNumber, gitprovider.MergeMethodMerge, commitMesage)
+	return mergePullRequest(ctx, p.provider, repo, repoUrl, pullRequestNumber, mergeMethod, commitMesage)
+}
+
+// GetAuthenticatedUser returns the name and email of the user the provider is authenticated as.
+func (p orgGitProvider) GetAuthenticatedUser(ctx context.Context) (git.Author, error) {
+	return getAuthenticatedUser(ctx, p.provider, p.domain)
 }
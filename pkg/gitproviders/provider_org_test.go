@@ -2,12 +2,18 @@ package gitproviders
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 
 	"github.com/fluxcd/go-git-providers/gitprovider"
+	"github.com/google/go-github/v41/github"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/git"
 	"github.com/weaveworks/weave-gitops/pkg/vendorfakes/fakegitprovider"
 )
 
@@ -342,10 +348,56 @@ var _ = Describe("Org Provider", func() {
 		})
 	})
 
+	Describe("GetOpenPullRequest", func() {
+		newPullRequest := func(state, branch string) *fakegitprovider.PullRequest {
+			pr := &fakegitprovider.PullRequest{}
+			pr.APIObjectReturns(&github.PullRequest{State: github.String(state), Head: &github.PullRequestBranch{Ref: github.String(branch)}})
+
+			return pr
+		}
+
+		It("returns the open pull request of the branch", func() {
+			open := newPullRequest("open", "gitops-add-profile")
+			pullRequestsClient.ListReturns([]gitprovider.PullRequest{
+				newPullRequest("closed", "gitops-add-profile"),
+				newPullRequest("open", "gitops-upgrade"),
+				open,
+			}, nil)
+
+			pr, err := orgProvider.GetOpenPullRequest(context.TODO(), repoUrl, "gitops-add-profile")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pr).To(BeIdenticalTo(open))
+		})
+
+		It("returns nil when the branch has no open pull request", func() {
+			pullRequestsClient.ListReturns([]gitprovider.PullRequest{newPullRequest("closed", "gitops-add-profile")}, nil)
+
+			pr, err := orgProvider.GetOpenPullRequest(context.TODO(), repoUrl, "gitops-add-profile")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pr).To(BeNil())
+		})
+	})
+
+	Describe("CommitFiles", func() {
+		It("commits the files to the branch", func() {
+			path := "clusters/prod/profiles.yaml"
+			content := "kind: HelmRelease\n"
+			files := []gitprovider.CommitFile{{Path: &path, Content: &content}}
+
+			Expect(orgProvider.CommitFiles(context.TODO(), repoUrl, "gitops-add-profile", "message", files)).To(Succeed())
+			Expect(commitClient.CreateCallCount()).To(Equal(1))
+
+			_, branch, message, committed := commitClient.CreateArgsForCall(0)
+			Expect(branch).To(Equal("gitops-add-profile"))
+			Expect(message).To(Equal("message"))
+			Expect(committed).To(Equal(files))
+		})
+	})
+
 	Describe("MergePullRequest", func() {
 		It("merges a given pull request", func() {
 			pullRequestsClient.MergeReturns(nil)
-			err := orgProvider.MergePullRequest(context.TODO(), repoUrl, 1, "message")
+			err := orgProvider.MergePullRequest(context.TODO(), repoUrl, 1, gitprovider.MergeMethodMerge, "message")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(pullRequestsClient.MergeCallCount()).To(Equal(1))
 			_, prNumber, mergeMethod, message := pullRequestsClient.MergeArgsForCall(0)
@@ -357,10 +409,142 @@ var _ = Describe("Org Provider", func() {
 		When("merge the PR fails", func() {
 			It("returns an error", func() {
 				pullRequestsClient.MergeReturns(fmt.Errorf("err"))
-				err := orgProvider.MergePullRequest(context.TODO(), repoUrl, 1, "message")
+				err := orgProvider.MergePullRequest(context.TODO(), repoUrl, 1, gitprovider.MergeMethodMerge, "message")
 				Expect(err).To(MatchError("err"))
 				Expect(pullRequestsClient.MergeCallCount()).To(Equal(1))
 			})
 		})
+
+		It("rebases a pull request with the GitHub API", func() {
+			var body map[string]string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodPut))
+				Expect(r.URL.Path).To(Equal("/repos/owner/repo-name/pulls/1/merge"))
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				fmt.Fprint(w, `{"merged": true}`)
+			}))
+			DeferCleanup(server.Close)
+
+			client := github.NewClient(nil)
+			client.BaseURL, _ = url.Parse(server.URL + "/")
+			gitProviderClient.RawReturns(client)
+
+			Expect(orgProvider.MergePullRequest(context.TODO(), repoUrl, 1, MergeMethodRebase, "message")).To(Succeed())
+			Expect(body).To(Equal(map[string]string{"commit_message": "message", "merge_method": "rebase"}))
+			Expect(pullRequestsClient.MergeCallCount()).To(BeZero())
+		})
+	})
+
+	Describe("GetPullRequestStatus", func() {
+		var responses map[string]string
+
+		BeforeEach(func() {
+			responses = map[string]string{
+				"/repos/owner/repo-name/pulls/1":                                         `{"number": 1, "state": "open", "merged": false, "html_url": "https://github.com/owner/repo-name/pull/1", "head": {"sha": "abc123"}, "base": {"ref": "main"}}`,
+				"/repos/owner/repo-name/commits/abc123/status":                           `{"state": "success", "total_count": 1, "statuses": [{"context": "ci/lint", "state": "success"}]}`,
+				"/repos/owner/repo-name/commits/abc123/check-runs":                       `{"total_count": 2, "check_runs": [{"name": "test", "status": "completed", "conclusion": "success"}, {"name": "e2e", "status": "completed", "conclusion": "skipped"}]}`,
+				"/repos/owner/repo-name/branches/main/protection/required_status_checks": `{"contexts": ["ci/lint", "test"]}`,
+				"/repos/owner/repo-name/pulls/1/reviews":                                 `[{"user": {"login": "jdoe"}, "state": "CHANGES_REQUESTED"}, {"user": {"login": "jdoe"}, "state": "APPROVED"}, {"user": {"login": "rroe"}, "state": "COMMENTED"}]`,
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response, ok := responses[r.URL.Path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					fmt.Fprint(w, `{"message": "Not Found"}`)
+
+					return
+				}
+
+				fmt.Fprint(w, response)
+			}))
+			DeferCleanup(server.Close)
+
+			client := github.NewClient(nil)
+			client.BaseURL, _ = url.Parse(server.URL + "/")
+			gitProviderClient.RawReturns(client)
+		})
+
+		It("returns the checks and latest reviews of the head commit", func() {
+			status, err := orgProvider.GetPullRequestStatus(context.TODO(), repoUrl, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(PullRequestStatus{
+				Number:    1,
+				WebURL:    "https://github.com/owner/repo-name/pull/1",
+				State:     PullRequestOpen,
+				HeadSha:   "abc123",
+				Checks:    ChecksPassed,
+				Approvals: 1,
+			}))
+		})
+
+		It("waits for the check runs in progress", func() {
+			responses["/repos/owner/repo-name/commits/abc123/status"] = `{"state": "pending", "total_count": 0}`
+			responses["/repos/owner/repo-name/commits/abc123/check-runs"] = `{"total_count": 1, "check_runs": [{"status": "in_progress"}]}`
+
+			status, err := orgProvider.GetPullRequestStatus(context.TODO(), repoUrl, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Checks).To(Equal(ChecksPending))
+		})
+
+		It("reports no checks before any was reported", func() {
+			responses["/repos/owner/repo-name/commits/abc123/status"] = `{"state": "pending", "total_count": 0}`
+			responses["/repos/owner/repo-name/commits/abc123/check-runs"] = `{"total_count": 0, "check_runs": []}`
+			delete(responses, "/repos/owner/repo-name/branches/main/protection/required_status_checks")
+
+			status, err := orgProvider.GetPullRequestStatus(context.TODO(), repoUrl, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Checks).To(Equal(ChecksNone))
+		})
+
+		It("waits for the required checks that weren't reported yet", func() {
+			responses["/repos/owner/repo-name/branches/main/protection/required_status_checks"] = `{"contexts": ["ci/lint", "test", "ci/build"]}`
+
+			status, err := orgProvider.GetPullRequestStatus(context.TODO(), repoUrl, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Checks).To(Equal(ChecksPending))
+		})
+
+		It("returns the merge commit of merged pull requests", func() {
+			responses["/repos/owner/repo-name/pulls/1"] = `{"number": 1, "state": "closed", "merged": true, "merge_commit_sha": "def456", "head": {"sha": "abc123"}}`
+
+			status, err := orgProvider.GetPullRequestStatus(context.TODO(), repoUrl, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.State).To(Equal(PullRequestMerged))
+			Expect(status.MergeCommitSha).To(Equal("def456"))
+		})
+	})
+
+	Describe("GetAuthenticatedUser", func() {
+		var user string
+
+		BeforeEach(func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Path).To(Equal("/user"))
+				fmt.Fprint(w, user)
+			}))
+			DeferCleanup(server.Close)
+
+			client := github.NewClient(nil)
+			client.BaseURL, _ = url.Parse(server.URL + "/")
+			gitProviderClient.RawReturns(client)
+		})
+
+		It("returns the name and email of the user", func() {
+			user = `{"login": "jdoe", "id": 42, "name": "Jane Doe", "email": "jdoe@example.com"}`
+
+			author, err := orgProvider.GetAuthenticatedUser(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(author).To(Equal(git.Author{Name: "Jane Doe", Email: "jdoe@example.com"}))
+		})
+
+		It("falls back on the login and noreply address of the user", func() {
+			user = `{"login": "jdoe", "id": 42}`
+
+			author, err := orgProvider.GetAuthenticatedUser(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(author).To(Equal(git.Author{Name: "jdoe", Email: "42+jdoe@users.noreply.github.com"}))
+		})
 	})
 })
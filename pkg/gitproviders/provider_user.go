@@ -6,6 +6,8 @@ import (
 	"fmt"
 
 	"github.com/fluxcd/go-git-providers/gitprovider"
+
+	"github.com/weaveworks/weave-gitops/pkg/git"
 )
 
 type userGitProvider struct {
@@ -41,13 +43,17 @@ func (p userGitProvider) DeployKeyExists(ctx context.Context, repoUrl RepoURL) (
 }
 
 func (p userGitProvider) UploadDeployKey(ctx context.Context, repoUrl RepoURL, deployKey []byte) error {
+	return p.UploadNamedDeployKey(ctx, repoUrl, DeployKeyName, deployKey)
+}
+
+func (p userGitProvider) UploadNamedDeployKey(ctx context.Context, repoUrl RepoURL, name string, deployKey []byte) error {
 	userRepo, err := p.getUserRepo(ctx, repoUrl)
 	if err != nil {
 		return fmt.Errorf("error getting user repo reference for owner %s, repo %s, %w", repoUrl.Owner(), repoUrl.RepositoryName(), err)
 	}
 
 	deployKeyInfo := gitprovider.DeployKeyInfo{
-		Name:     DeployKeyName,
+		Name:     name,
 		Key:      deployKey,
 		ReadOnly: gitprovider.BoolVar(false),
 	}
@@ -55,6 +61,24 @@ func (p userGitProvider) UploadDeployKey(ctx context.Context, repoUrl RepoURL, d
 	return uploadDeployKey(ctx, userRepo, deployKeyInfo)
 }
 
+func (p userGitProvider) ListDeployKeys(ctx context.Context, repoUrl RepoURL) ([]DeployKey, error) {
+	userRepo, err := p.getUserRepo(ctx, repoUrl)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user repo reference for owner %s, repo %s, %w", repoUrl.Owner(), repoUrl.RepositoryName(), err)
+	}
+
+	return listDeployKeys(ctx, userRepo)
+}
+
+func (p userGitProvider) DeleteDeployKey(ctx context.Context, repoUrl RepoURL, id string) error {
+	userRepo, err := p.getUserRepo(ctx, repoUrl)
+	if err != nil {
+		return fmt.Errorf("error getting user repo reference for owner %s, repo %s, %w", repoUrl.Owner(), repoUrl.RepositoryName(), err)
+	}
+
+	return deleteDeployKey(ctx, userRepo, id)
+}
+
 func (p userGitProvider) GetDefaultBranch(ctx context.Context, repoUrl RepoURL) (string, error) {
 	repoInfoRef, err := p.getRepoInfoFromUrl(ctx, repoUrl)
 	if err != nil {
@@ -102,6 +126,35 @@ func (p userGitProvider) CreatePullRequest(ctx context.Context, repoUrl RepoURL,
 	return createPullRequest(ctx, userRepo, prInfo)
 }
 
+// GetOpenPullRequest returns the open pull request of a branch, or nil if there is none.
+func (p userGitProvider) GetOpenPullRequest(ctx context.Context, repoUrl RepoURL, branch string) (gitprovider.PullRequest, error) {
+	userRepo, err := p.getUserRepo(ctx, repoUrl)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user repo for owner %s, repo %s, %w", repoUrl.Owner(), repoUrl.RepositoryName(), err)
+	}
+
+	return getOpenPullRequest(ctx, userRepo, branch)
+}
+
+// GetClosedPullRequest returns the latest merged or closed pull request of a branch, or nil if there is none.
+func (p userGitProvider) GetClosedPullRequest(ctx context.Context, repoUrl RepoURL, branch string) (gitprovider.PullRequest, error) {
+	return getClosedPullRequest(ctx, p.provider, repoUrl, branch)
+}
+
+func (p userGitProvider) DeleteBranch(ctx context.Context, repoUrl RepoURL, branch string) error {
+	return deleteBranch(ctx, p.provider, repoUrl, branch)
+}
+
+// CommitFiles commits the files to an existing branch.
+func (p userGitProvider) CommitFiles(ctx context.Context, repoUrl RepoURL, branch, commitMessage string, files []gitprovider.CommitFile) error {
+	userRepo, err := p.getUserRepo(ctx, repoUrl)
+	if err != nil {
+		return fmt.Errorf("error getting user repo for owner %s, repo %s, %w", repoUrl.Owner(), repoUrl.RepositoryName(), err)
+	}
+
+	return commitFiles(ctx, userRepo, branch, commitMessage, files)
+}
+
 func (p userGitProvider) GetCommits(ctx context.Context, repoUrl RepoURL, targetBranch string, pageSize int, pageToken int) ([]gitprovider.Commit, error) {
 	userRepo, err := p.getUserRepo(ctx, repoUrl)
 	if err != nil {
@@ -131,12 +184,22 @@ func (p userGitProvider) GetRepoDirFiles(ctx context.Context, repoUrl RepoURL, d
 	return files, nil
 }
 
-// MergePullRequest merges a pull request given the repository's URL and the PR's number with a commit message.
-func (p userGitProvider) MergePullRequest(ctx context.Context, repoUrl RepoURL, pullRequestNumber int, commitMesage string) error {
+// GetPullRequestStatus returns the state, checks and reviews of a pull request.
+func (p userGitProvider) GetPullRequestStatus(ctx context.Context, repoUrl RepoURL, pullRequestNumber int) (PullRequestStatus, error) {
+	return getPullRequestStatus(ctx, p.provider, repoUrl, pullRequestNumber)
+}
+
+// MergePullRequest merges a pull request given the repository's URL and the PR's number with a merge method and a commit message.
+func (p userGitProvider) MergePullRequest(ctx context.Context, repoUrl RepoURL, pullRequestNumber int, mergeMethod gitprovider.MergeMethod, commitMesage string) error {
 	repo, err := p.getUserRepo(ctx, repoUrl)
 	if err != nil {
 		return err
 	}
 
-	return repo.PullRequests().Merge(ctx, pullRequestNumber, gitprovider.MergeMethodMerge, commitMesage)
+	return mergePullRequest(ctx, p.provider, repo, repoUrl, pullRequestNumber, mergeMethod, commitMesage)
+}
+
+// GetAuthenticatedUser returns the name and email of the user the provider is authenticated as.
+func (p userGitProvider) GetAuthenticatedUser(ctx context.Context) (git.Author, error) {
+	return getAuthenticatedUser(ctx, p.provider, p.domain)
 }
@@ -4,13 +4,49 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
 
 	"github.com/fluxcd/go-git-providers/gitprovider"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/git"
 	"github.com/weaveworks/weave-gitops/pkg/vendorfakes/fakegitprovider"
+	"github.com/google/go-github/v41/github"
+	"github.com/xanzy/go-gitlab"
 )
 
+// stubDeployKey is a deploy key of GitHub, recording whether it was deleted.
+type stubDeployKey struct {
+	gitprovider.DeployKey
+	key     *github.Key
+	deleted bool
+}
+
+func newStubDeployKey(id int64, name string, created time.Time) *stubDeployKey {
+	return &stubDeployKey{key: &github.Key{
+		ID:        github.Int64(id),
+		Title:     github.String(name),
+		Key:       github.String(fmt.Sprintf("ssh-ed25519 AAAA%d", id)),
+		ReadOnly:  github.Bool(false),
+		CreatedAt: &github.Timestamp{Time: created},
+	}}
+}
+
+func (k *stubDeployKey) Get() gitprovider.DeployKeyInfo {
+	return gitprovider.DeployKeyInfo{Name: k.key.GetTitle(), Key: []byte(k.key.GetKey()), ReadOnly: k.key.ReadOnly}
+}
+
+func (k *stubDeployKey) APIObject() interface{} {
+	return k.key
+}
+
+func (k *stubDeployKey) Delete(_ context.Context) error {
+	k.deleted = true
+	return nil
+}
+
 var _ = Describe("User Provider", func() {
 	var (
 		userProvider GitProvider
@@ -149,6 +185,52 @@ var _ = Describe("User Provider", func() {
 		})
 	})
 
+	Describe("ListDeployKeys", func() {
+		var deployKeyClient *fakegitprovider.DeployKeyClient
+
+		BeforeEach(func() {
+			deployKeyClient = &fakegitprovider.DeployKeyClient{}
+			userRepo.DeployKeysReturns(deployKeyClient)
+		})
+
+		It("returns the keys uploaded by gitops", func() {
+			created := time.Date(2022, 7, 5, 14, 25, 36, 0, time.UTC)
+			deployKeyClient.ListReturns([]gitprovider.DeployKey{
+				newStubDeployKey(1, DeployKeyName, created),
+				newStubDeployKey(2, "ci", created),
+				newStubDeployKey(3, RotatedDeployKeyName(created), created),
+			}, nil)
+
+			keys, err := userProvider.ListDeployKeys(ctx, repoUrl)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keys).To(Equal([]DeployKey{
+				{ID: "1", Name: DeployKeyName, Key: []byte("ssh-ed25519 AAAA1"), CreatedAt: created},
+				{ID: "3", Name: "wego-deploy-key-20220705-142536", Key: []byte("ssh-ed25519 AAAA3"), CreatedAt: created},
+			}))
+		})
+
+		It("deletes a key by its id", func() {
+			first := newStubDeployKey(1, DeployKeyName, time.Now())
+			second := newStubDeployKey(2, RotatedDeployKeyName(time.Now()), time.Now())
+			deployKeyClient.ListReturns([]gitprovider.DeployKey{first, second}, nil)
+
+			Expect(userProvider.DeleteDeployKey(ctx, repoUrl, "2")).To(Succeed())
+			Expect(first.deleted).To(BeFalse())
+			Expect(second.deleted).To(BeTrue())
+
+			Expect(userProvider.DeleteDeployKey(ctx, repoUrl, "3")).To(MatchError(gitprovider.ErrNotFound))
+		})
+
+		It("finds the rotated keys", func() {
+			deployKeyClient.GetReturns(nil, gitprovider.ErrNotFound)
+			deployKeyClient.ListReturns([]gitprovider.DeployKey{newStubDeployKey(3, RotatedDeployKeyName(time.Now()), time.Now())}, nil)
+
+			exists, err := userProvider.DeployKeyExists(ctx, repoUrl)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(exists).To(BeTrue())
+		})
+	})
+
 	Describe("GetDefaultBranch", func() {
 		It("returns error when can't get branch", func() {
 			userRepoClient.GetReturns(nil, gitprovider.ErrNotFound)
@@ -337,10 +419,28 @@ var _ = Describe("User Provider", func() {
 		})
 	})
 
+	Describe("GetOpenPullRequest", func() {
+		It("returns the opened merge request of the branch", func() {
+			merged := &fakegitprovider.PullRequest{}
+			merged.APIObjectReturns(&gitlab.MergeRequest{State: "merged", SourceBranch: "gitops-add-profile"})
+			opened := &fakegitprovider.PullRequest{}
+			opened.APIObjectReturns(&gitlab.MergeRequest{State: "opened", SourceBranch: "gitops-add-profile"})
+			pullRequestsClient.ListReturns([]gitprovider.PullRequest{merged, opened}, nil)
+
+			pr, err := userProvider.GetOpenPullRequest(context.TODO(), repoUrl, "gitops-add-profile")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pr).To(BeIdenticalTo(opened))
+
+			pr, err = userProvider.GetOpenPullRequest(context.TODO(), repoUrl, "gitops-upgrade")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pr).To(BeNil())
+		})
+	})
+
 	Describe("MergePullRequest", func() {
 		It("merges a given pull request", func() {
 			pullRequestsClient.MergeReturns(nil)
-			err := userProvider.MergePullRequest(context.TODO(), repoUrl, 1, "message")
+			err := userProvider.MergePullRequest(context.TODO(), repoUrl, 1, gitprovider.MergeMethodMerge, "message")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(pullRequestsClient.MergeCallCount()).To(Equal(1))
 			_, prNumber, mergeMethod, message := pullRequestsClient.MergeArgsForCall(0)
@@ -352,10 +452,77 @@ var _ = Describe("User Provider", func() {
 		When("merge the PR fails", func() {
 			It("returns an error", func() {
 				pullRequestsClient.MergeReturns(fmt.Errorf("err"))
-				err := userProvider.MergePullRequest(context.TODO(), repoUrl, 1, "message")
+				err := userProvider.MergePullRequest(context.TODO(), repoUrl, 1, gitprovider.MergeMethodMerge, "message")
 				Expect(err).To(MatchError("err"))
 				Expect(pullRequestsClient.MergeCallCount()).To(Equal(1))
 			})
 		})
+
+		It("can't rebase merge requests", func() {
+			gitProviderClient.ProviderIDReturns("gitlab")
+
+			err := userProvider.MergePullRequest(context.TODO(), repoUrl, 1, MergeMethodRebase, "message")
+			Expect(err).To(MatchError(ContainSubstring("the rebase merge method isn't supported by gitlab")))
+			Expect(pullRequestsClient.MergeCallCount()).To(BeZero())
+		})
+	})
+
+	Describe("GetPullRequestStatus", func() {
+		It("returns the pipeline status and approvals of a merge request", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v4/projects/owner/repo-name/merge_requests/1":
+					fmt.Fprint(w, `{"iid": 1, "state": "merged", "sha": "abc123", "squash_commit_sha": "def456", "web_url": "https://gitlab.com/owner/repo-name/-/merge_requests/1", "head_pipeline": {"status": "success"}}`)
+				case "/api/v4/projects/owner/repo-name/merge_requests/1/approvals":
+					fmt.Fprint(w, `{"approved_by": [{"user": {"username": "jdoe"}}]}`)
+				}
+			}))
+			DeferCleanup(server.Close)
+
+			client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL))
+			Expect(err).NotTo(HaveOccurred())
+			gitProviderClient.RawReturns(client)
+
+			status, err := userProvider.GetPullRequestStatus(context.TODO(), repoUrl, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(PullRequestStatus{
+				Number:         1,
+				WebURL:         "https://gitlab.com/owner/repo-name/-/merge_requests/1",
+				State:          PullRequestMerged,
+				HeadSha:        "abc123",
+				MergeCommitSha: "def456",
+				Checks:         ChecksPassed,
+				Approvals:      1,
+			}))
+		})
+	})
+
+	Describe("GetAuthenticatedUser", func() {
+		It("returns the name and email of the GitLab user", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// the client also requests the API root to configure its rate limiter
+				if r.URL.Path != "/api/v4/user" {
+					return
+				}
+
+				fmt.Fprint(w, `{"id": 42, "username": "jdoe", "name": "", "email": "", "public_email": "jdoe@example.com"}`)
+			}))
+			DeferCleanup(server.Close)
+
+			client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL))
+			Expect(err).NotTo(HaveOccurred())
+			gitProviderClient.RawReturns(client)
+
+			author, err := userProvider.GetAuthenticatedUser(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(author).To(Equal(git.Author{Name: "jdoe", Email: "jdoe@example.com"}))
+		})
+
+		It("fails for other providers", func() {
+			gitProviderClient.ProviderIDReturns("stash")
+
+			_, err := userProvider.GetAuthenticatedUser(context.TODO())
+			Expect(err).To(MatchError(`unsupported git provider "stash"`))
+		})
 	})
 })
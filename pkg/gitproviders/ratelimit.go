@@ -0,0 +1,246 @@
+package gitproviders
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// rateLimitRetries is how many times a rate limited request is sent again.
+	rateLimitRetries = 3
+	// lowQuotaRatio is the share of the quota under which it is reported as low.
+	lowQuotaRatio = 0.1
+)
+
+// RateLimit is the quota of API requests of a token, as last reported by a provider.
+type RateLimit struct {
+	Host      string
+	Limit     int
+	Remaining int
+	// Reset is when the quota is renewed.
+	Reset time.Time
+}
+
+// RateLimiter reads the rate limit headers of the provider APIs, waits for the quota to be renewed
+// when it ran out, and sends the rate limited requests again. GitHub and GitLab headers are supported,
+// as well as the Retry-After header of any provider.
+type RateLimiter struct {
+	maxWait time.Duration
+	onLow   func(RateLimit)
+	now     func() time.Time
+	sleep   func(ctx context.Context, d time.Duration) error
+
+	mu sync.Mutex
+	// quotas are the rate limits by host and credentials
+	quotas map[string]RateLimit
+	// warned are the quotas reported as low, by the time they are renewed
+	warned map[string]time.Time
+}
+
+// NewRateLimiter returns a RateLimiter waiting up to maxWait for the quota to be renewed, requests
+// failing with the rate limit error of the provider otherwise. onLow, if not nil, is called once per
+// period when less than a tenth of the quota remains.
+func NewRateLimiter(maxWait time.Duration, onLow func(RateLimit)) *RateLimiter {
+	return &RateLimiter{
+		maxWait: maxWait,
+		onLow:   onLow,
+		now:     time.Now,
+		sleep:   sleep,
+		quotas:  map[string]RateLimit{},
+		warned:  map[string]time.Time{},
+	}
+}
+
+// Transport returns a RoundTripper sending the requests with in, http.DefaultTransport when nil. Its
+// signature is the one of the transport hooks of go-git-providers.
+func (l *RateLimiter) Transport(in http.RoundTripper) http.RoundTripper {
+	if in == nil {
+		in = http.DefaultTransport
+	}
+
+	return rateLimitTransport{limiter: l, next: in}
+}
+
+type rateLimitTransport struct {
+	limiter *RateLimiter
+	next    http.RoundTripper
+}
+
+func (t rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	l := t.limiter
+	key := quotaKey(req)
+
+	for attempt := 0; ; attempt++ {
+		// the retries already waited for the quota
+		if wait := l.waitForQuota(key); wait > 0 && attempt == 0 {
+			if err := l.sleep(req.Context(), wait); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := t.next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		l.update(key, req.URL.Host, resp.Header)
+
+		wait, limited := l.backoff(resp)
+		if !limited || attempt == rateLimitRetries || wait > l.maxWait || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+
+		if err := l.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// waitForQuota returns how long to wait before sending a request, when the quota ran out and is
+// renewed within the max wait.
+func (l *RateLimiter) waitForQuota(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	quota, ok := l.quotas[key]
+	if !ok || quota.Remaining > 0 {
+		return 0
+	}
+
+	wait := quota.Reset.Sub(l.now())
+	if wait <= 0 || wait > l.maxWait {
+		return 0
+	}
+
+	return wait
+}
+
+// backoff returns how long to wait before sending a rate limited request again.
+func (l *RateLimiter) backoff(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	// secondary rate limits of GitHub, and the other providers
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	quota, ok := parseRateLimit(resp.Header)
+	if !ok || quota.Remaining > 0 {
+		// forbidden for other reasons
+		return 0, false
+	}
+
+	wait := quota.Reset.Sub(l.now())
+	if wait < 0 {
+		wait = 0
+	}
+
+	// the reset is rounded to the second
+	return wait + time.Second, true
+}
+
+func (l *RateLimiter) update(key, host string, header http.Header) {
+	quota, ok := parseRateLimit(header)
+	if !ok {
+		return
+	}
+
+	quota.Host = host
+
+	l.mu.Lock()
+
+	l.evict()
+
+	l.quotas[key] = quota
+
+	low := l.onLow != nil && float64(quota.Remaining) < float64(quota.Limit)*lowQuotaRatio && !l.warned[key].Equal(quota.Reset)
+	if low {
+		l.warned[key] = quota.Reset
+	}
+
+	l.mu.Unlock()
+
+	if low {
+		l.onLow(quota)
+	}
+}
+
+// evict drops the quotas renewed since, which would otherwise be kept for every token ever seen.
+func (l *RateLimiter) evict() {
+	now := l.now()
+
+	for key, quota := range l.quotas {
+		if !now.Before(quota.Reset) {
+			delete(l.quotas, key)
+		}
+	}
+
+	for key, reset := range l.warned {
+		if !now.Before(reset) {
+			delete(l.warned, key)
+		}
+	}
+}
+
+// parseRateLimit reads the X-RateLimit-* headers of GitHub, or the RateLimit-* ones of GitLab.
+func parseRateLimit(header http.Header) (RateLimit, bool) {
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		limit, err := strconv.Atoi(header.Get(prefix + "Limit"))
+		if err != nil {
+			continue
+		}
+
+		remaining, err := strconv.Atoi(header.Get(prefix + "Remaining"))
+		if err != nil {
+			continue
+		}
+
+		reset, err := strconv.ParseInt(header.Get(prefix+"Reset"), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		return RateLimit{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}, true
+	}
+
+	return RateLimit{}, false
+}
+
+// quotaKey identifies the quota of a request, which is per host and credentials.
+func quotaKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.Host + "\n" + req.Header.Get("Authorization")))
+	return string(sum[:])
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,153 @@
+package gitproviders
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateLimiter", func() {
+	var (
+		limiter   *RateLimiter
+		client    *http.Client
+		server    *httptest.Server
+		responses []func(w http.ResponseWriter)
+		bodies    []string
+		waits     []time.Duration
+		low       []RateLimit
+		now       time.Time
+	)
+
+	quota := func(remaining int, reset time.Time) func(w http.ResponseWriter) {
+		return func(w http.ResponseWriter) {
+			w.Header().Set("X-RateLimit-Limit", "5000")
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+
+			if remaining == 0 {
+				w.WriteHeader(http.StatusForbidden)
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		responses = nil
+		bodies = nil
+		waits = nil
+		low = nil
+		now = time.Unix(1700000000, 0)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+
+			respond := responses[0]
+			if len(responses) > 1 {
+				responses = responses[1:]
+			}
+
+			respond(w)
+		}))
+		DeferCleanup(server.Close)
+
+		limiter = NewRateLimiter(time.Minute, func(quota RateLimit) {
+			low = append(low, quota)
+		})
+		limiter.now = func() time.Time { return now }
+		limiter.sleep = func(_ context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		}
+
+		client = &http.Client{Transport: limiter.Transport(nil)}
+	})
+
+	It("sends the rate limited requests again after Retry-After", func() {
+		responses = []func(w http.ResponseWriter){
+			func(w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "3")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			func(w http.ResponseWriter) {},
+		}
+
+		res, err := client.Post(server.URL, "application/json", strings.NewReader(`{"title":"update"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		Expect(waits).To(Equal([]time.Duration{3 * time.Second}))
+		Expect(bodies).To(Equal([]string{`{"title":"update"}`, `{"title":"update"}`}))
+	})
+
+	It("waits for the quota to be renewed", func() {
+		responses = []func(w http.ResponseWriter){quota(0, now.Add(30*time.Second)), quota(4999, now.Add(time.Hour))}
+
+		res, err := client.Get(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		Expect(waits).To(Equal([]time.Duration{31 * time.Second}))
+
+		Expect(limiter.quotas).To(HaveLen(1))
+
+		for _, rateLimit := range limiter.quotas {
+			Expect(rateLimit.Limit).To(Equal(5000))
+			Expect(rateLimit.Remaining).To(Equal(4999))
+			Expect(rateLimit.Host).To(Equal(strings.TrimPrefix(server.URL, "http://")))
+		}
+	})
+
+	It("fails when the quota is renewed after the max wait", func() {
+		responses = []func(w http.ResponseWriter){quota(0, now.Add(time.Hour))}
+
+		res, err := client.Get(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusForbidden))
+		Expect(waits).To(BeEmpty())
+	})
+
+	It("doesn't send the forbidden requests again", func() {
+		responses = []func(w http.ResponseWriter){func(w http.ResponseWriter) { w.WriteHeader(http.StatusForbidden) }}
+
+		res, err := client.Get(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusForbidden))
+		Expect(bodies).To(HaveLen(1))
+	})
+
+	It("reports a low quota once per period", func() {
+		responses = []func(w http.ResponseWriter){quota(400, now.Add(time.Hour))}
+
+		for i := 0; i < 2; i++ {
+			_, err := client.Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(low).To(HaveLen(1))
+		Expect(low[0].Remaining).To(Equal(400))
+	})
+	It("drops the quotas renewed since", func() {
+		responses = []func(w http.ResponseWriter){quota(400, now.Add(time.Hour))}
+
+		for _, token := range []string{"token1", "token2"} {
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			_, err = client.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+
+			now = now.Add(2 * time.Hour)
+			responses = []func(w http.ResponseWriter){quota(400, now.Add(time.Hour))}
+		}
+
+		Expect(low).To(HaveLen(2))
+		Expect(limiter.quotas).To(HaveLen(1))
+		Expect(limiter.warned).To(HaveLen(1))
+	})
+})
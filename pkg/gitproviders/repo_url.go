@@ -11,6 +11,7 @@ import (
 	"github.com/weaveworks/weave-gitops/pkg/utils"
 )
 
+// RepositoryURLProtocol is the protocol git uses to reach a repository.
 type RepositoryURLProtocol string
 
 const RepositoryURLProtocolHTTPS RepositoryURLProtocol = "https"
@@ -36,19 +37,25 @@ func NewRepoURL(uri string) (RepoURL, error) {
 		return RepoURL{}, fmt.Errorf("could not normalize repo URL %s: %w", uri, err)
 	}
 
+	protocol := RepositoryURLProtocolSSH
+	if strings.HasPrefix(normalized, "https://") {
+		protocol = RepositoryURLProtocolHTTPS
+	}
+
 	u, err := url.Parse(normalized)
 	if err != nil {
 		return RepoURL{}, fmt.Errorf("could not create normalized repo URL %s: %w", uri, err)
 	}
 
-	owner, err := getOwnerFromUrl(*u, providerName)
-	if err != nil {
-		return RepoURL{}, fmt.Errorf("could not get owner name from URL %s: %w", uri, err)
+	// Bitbucket Server clones over HTTPS below /scm, which isn't part of the SSH URLs
+	ownerURL := *u
+	if providerName == GitProviderBitbucketServer {
+		ownerURL.Path = strings.TrimPrefix(ownerURL.Path, "/scm")
 	}
 
-	protocol := RepositoryURLProtocolSSH
-	if u.Scheme == "https" {
-		protocol = RepositoryURLProtocolHTTPS
+	owner, err := getOwnerFromUrl(ownerURL, providerName)
+	if err != nil {
+		return RepoURL{}, fmt.Errorf("could not get owner name from URL %s: %w", uri, err)
 	}
 
 	return RepoURL{
@@ -85,6 +92,22 @@ func (n RepoURL) Protocol() RepositoryURLProtocol {
 	return n.protocol
 }
 
+// Canonical returns the repository as host/owner/name, without the protocol, port and .git suffix,
+// so that the SSH and HTTPS URLs of a repository can be compared.
+func (n RepoURL) Canonical() string {
+	path := strings.TrimSuffix(n.url.Path, ".git")
+	if n.provider == GitProviderBitbucketServer {
+		path = strings.TrimPrefix(path, "/scm")
+	}
+
+	return strings.ToLower(n.url.Hostname()) + path
+}
+
+// Equal returns true when both URLs point to the same repository, whatever their protocol.
+func (n RepoURL) Equal(other RepoURL) bool {
+	return n.Canonical() == other.Canonical()
+}
+
 func getOwnerFromUrl(url url.URL, providerName GitProviderName) (string, error) {
 	url.Path = strings.TrimPrefix(url.Path, "/")
 
@@ -114,11 +137,24 @@ func detectGitProviderFromUrl(raw string, gitHostTypes map[string]string) (GitPr
 		return "", fmt.Errorf("could not parse git repo url %q: %w", raw, err)
 	}
 
-	// defaults for github and gitlab
-	gitHostTypes[github.DefaultDomain] = string(GitProviderGitHub)
-	gitHostTypes[gitlab.DefaultDomain] = string(GitProviderGitLab)
+	// defaults for github and gitlab, the map is not modified as the server shares it between requests
+	hostTypes := map[string]string{
+		github.DefaultDomain: string(GitProviderGitHub),
+		gitlab.DefaultDomain: string(GitProviderGitLab),
+	}
+
+	for host, provider := range gitHostTypes {
+		hostTypes[host] = provider
+	}
+
+	gitHostTypes = hostTypes
 
 	provider := gitHostTypes[u.Host]
+	if provider == "" {
+		// SSH URLs often use a custom port, e.g. 7999 for Bitbucket Server
+		provider = gitHostTypes[u.Hostname()]
+	}
+
 	if provider == "" {
 		return "", fmt.Errorf("no git providers found for %q", raw)
 	}
@@ -139,8 +175,9 @@ func parseGitURL(raw string) (*url.URL, error) {
 }
 
 // normalizeRepoURLString accepts a url like git@github.com:someuser/podinfo.git and converts it into
-// a string like ssh://git@github.com/someuser/podinfo.git. This helps standardize the different
-// user inputs that might be provided.
+// a string like ssh://git@github.com/someuser/podinfo.git. HTTP(S) urls lose their credentials and are
+// upgraded to HTTPS, e.g. https://github.com/someuser/podinfo.git, as the tokens of the git providers are
+// sent along when cloning them. This helps standardize the different user inputs that might be provided.
 func normalizeRepoURLString(url string) (string, error) {
 	// https://github.com/weaveworks/weave-gitops/issues/878
 	// A trailing slash causes problems when naming secrets.
@@ -155,6 +192,10 @@ func normalizeRepoURLString(url string) (string, error) {
 		return "", fmt.Errorf("could not parse git repo url while normalizing %q: %w", url, err)
 	}
 
+	if u.Scheme == "https" || u.Scheme == "http" {
+		return fmt.Sprintf("https://%s%s", u.Host, u.Path), nil
+	}
+
 	return fmt.Sprintf("ssh://git@%s%s", u.Host, u.Path), nil
 }
 
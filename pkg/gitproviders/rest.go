@@ -0,0 +1,219 @@
+package gitproviders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+)
+
+// restClient is a minimal JSON client for the REST APIs of the providers that go-git-providers doesn't support.
+type restClient struct {
+	baseURL       string
+	authorization string
+	http          *http.Client
+}
+
+// restError is returned when the provider API answers with a non successful status code.
+type restError struct {
+	method     string
+	url        string
+	statusCode int
+	message    string
+}
+
+func (e restError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.method, e.url, e.statusCode, e.message)
+}
+
+// Is makes restError match gitprovider.ErrNotFound when the resource could not be found, like the
+// errors returned by go-git-providers.
+func (e restError) Is(target error) bool {
+	return target == gitprovider.ErrNotFound && e.statusCode == http.StatusNotFound
+}
+
+func newRestClient(config Config, authorization, apiPath string) (restClient, error) {
+	if config.Token == "" {
+		return restClient{}, fmt.Errorf("no git provider token present")
+	}
+
+	if config.Hostname == "" {
+		return restClient{}, fmt.Errorf("no hostname present for git provider '%s'", config.Provider)
+	}
+
+	return restClient{
+		baseURL:       "https://" + apiHostname(config.Hostname) + apiPath,
+		authorization: authorization + " " + config.Token,
+		http:          config.httpClient(),
+	}, nil
+}
+
+// apiHostname drops the port from the hostname of a repository URL, as it usually is the SSH port, whereas
+// the API is served over HTTPS.
+func apiHostname(hostname string) string {
+	if host, _, err := net.SplitHostPort(hostname); err == nil {
+		return host
+	}
+
+	return hostname
+}
+
+// get decodes the JSON response of a GET request into out.
+func (c restClient) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// post sends in as JSON and decodes the JSON response into out, if not nil.
+func (c restClient) post(ctx context.Context, path string, in, out interface{}) error {
+	return c.do(ctx, http.MethodPost, path, nil, in, out)
+}
+
+// delete sends a DELETE request, ignoring the response body.
+func (c restClient) delete(ctx context.Context, path string) error {
+	return c.do(ctx, http.MethodDelete, path, nil, nil, nil)
+}
+
+func (c restClient) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body io.Reader
+
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+
+		body = bytes.NewReader(b)
+	}
+
+	b, err := c.send(ctx, method, path, query, "application/json", body)
+	if err != nil {
+		return err
+	}
+
+	if out == nil || len(b) == 0 {
+		return nil
+	}
+
+	return decodeJSON(b, out)
+}
+
+func decodeJSON(b []byte, out interface{}) error {
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// send performs the request and returns the raw response body.
+func (c restClient) send(ctx context.Context, method, path string, query url.Values, contentType string, body io.Reader) ([]byte, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", c.authorization)
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, restError{
+			method:     method,
+			url:        c.baseURL + path,
+			statusCode: res.StatusCode,
+			message:    errorMessage(b),
+		}
+	}
+
+	return b, nil
+}
+
+// errorMessage extracts the message of an API error, Gitea returns {"message": "..."} and Bitbucket Server
+// {"errors": [{"message": "..."}]}.
+func errorMessage(body []byte) string {
+	var apiErr struct {
+		Message string `json:"message"`
+		Errors  []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := json.Unmarshal(body, &apiErr); err != nil {
+		return strings.TrimSpace(string(body))
+	}
+
+	messages := []string{}
+	if apiErr.Message != "" {
+		messages = append(messages, apiErr.Message)
+	}
+
+	for _, e := range apiErr.Errors {
+		messages = append(messages, e.Message)
+	}
+
+	return strings.Join(messages, ", ")
+}
+
+// escapePath escapes each segment of a file path so it can be part of an API path.
+func escapePath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// pullRequest and commit implement the go-git-providers resources for the providers without a client there.
+type pullRequest struct {
+	info   gitprovider.PullRequestInfo
+	apiObj interface{}
+}
+
+func (pr pullRequest) Get() gitprovider.PullRequestInfo {
+	return pr.info
+}
+
+func (pr pullRequest) APIObject() interface{} {
+	return pr.apiObj
+}
+
+type commit struct {
+	info   gitprovider.CommitInfo
+	apiObj interface{}
+}
+
+func (c commit) Get() gitprovider.CommitInfo {
+	return c.info
+}
+
+func (c commit) APIObject() interface{} {
+	return c.apiObj
+}
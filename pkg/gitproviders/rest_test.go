@@ -0,0 +1,99 @@
+package gitproviders
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/yaml"
+)
+
+// cassette holds HTTP interactions recorded in the go-vcr format of the files in ./cache.
+type cassette struct {
+	Interactions []struct {
+		Request struct {
+			Method string `json:"method"`
+			URL    string `json:"url"`
+		} `json:"request"`
+		Response struct {
+			Body string `json:"body"`
+			Code int    `json:"code"`
+		} `json:"response"`
+	} `json:"interactions"`
+}
+
+// replayer is an http.RoundTripper replaying the interactions of a cassette, matched on the method
+// and URL of the requests in order, the last match being repeated. It keeps the bodies of the requests it received.
+type replayer struct {
+	cassette cassette
+	used     map[int]bool
+	bodies   map[string][]string
+}
+
+func newReplayer(path string) *replayer {
+	b, err := ioutil.ReadFile(path)
+	Expect(err).NotTo(HaveOccurred())
+
+	r := &replayer{used: map[int]bool{}, bodies: map[string][]string{}}
+	Expect(yaml.Unmarshal(b, &r.cassette)).To(Succeed())
+
+	return r
+}
+
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := req.Method + " " + req.URL.String()
+
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		r.bodies[key] = append(r.bodies[key], string(b))
+	}
+
+	match := -1
+
+	for i, interaction := range r.cassette.Interactions {
+		if interaction.Request.Method+" "+interaction.Request.URL != key {
+			continue
+		}
+
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+
+	if match < 0 {
+		return nil, fmt.Errorf("no recorded interaction for %s", key)
+	}
+
+	r.used[match] = true
+	response := r.cassette.Interactions[match].Response
+
+	return &http.Response{
+		StatusCode: response.Code,
+		Body:       ioutil.NopCloser(strings.NewReader(response.Body)),
+		Header:     http.Header{},
+		Request:    req,
+	}, nil
+}
+
+var _ = Describe("restError", func() {
+	It("matches gitprovider.ErrNotFound on 404", func() {
+		err := fmt.Errorf("wrapped: %w", restError{statusCode: http.StatusNotFound})
+		Expect(err).To(MatchError(gitprovider.ErrNotFound))
+		Expect(restError{statusCode: http.StatusForbidden}).NotTo(MatchError(gitprovider.ErrNotFound))
+	})
+
+	It("extracts the message of the API errors", func() {
+		Expect(errorMessage([]byte(`{"message": "Git Repository is empty."}`))).To(Equal("Git Repository is empty."))
+		Expect(errorMessage([]byte(`{"errors": [{"message": "first"}, {"message": "second"}]}`))).To(Equal("first, second"))
+		Expect(errorMessage([]byte("Bad Gateway\n"))).To(Equal("Bad Gateway"))
+	})
+})
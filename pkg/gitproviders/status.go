@@ -0,0 +1,121 @@
+package gitproviders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+)
+
+// MergeMethodRebase rebases the commits of a pull request on the target branch. go-git-providers
+// only knows about merge commits and squashing.
+const MergeMethodRebase = gitprovider.MergeMethod("rebase")
+
+// ErrChecksFailed is returned when waiting on a pull request that can't be merged as it is.
+var ErrChecksFailed = errors.New("the pull request can't be merged")
+
+type PullRequestState string
+
+const (
+	PullRequestOpen   PullRequestState = "open"
+	PullRequestMerged PullRequestState = "merged"
+	PullRequestClosed PullRequestState = "closed"
+)
+
+// CheckState sums up the statuses and check runs reported on the head commit of a pull request.
+type CheckState string
+
+const (
+	ChecksPending CheckState = "pending"
+	ChecksPassed  CheckState = "passed"
+	ChecksFailed  CheckState = "failed"
+	// ChecksNone tells that no check was reported on the head commit, either because the repository has
+	// none or because they haven't started yet.
+	ChecksNone CheckState = "none"
+)
+
+// PullRequestStatus is the state of a pull request along with its checks and reviews.
+type PullRequestStatus struct {
+	Number int
+	WebURL string
+	State  PullRequestState
+	// HeadSha is the commit the checks ran on.
+	HeadSha string
+	// MergeCommitSha is the commit the pull request was merged as, once merged.
+	MergeCommitSha string
+	// Checks is ChecksNone as long as no check was reported.
+	Checks           CheckState
+	Approvals        int
+	ChangesRequested bool
+}
+
+// ParseMergeMethod returns the merge method of its name: merge, squash or rebase.
+func ParseMergeMethod(name string) (gitprovider.MergeMethod, error) {
+	switch method := gitprovider.MergeMethod(name); method {
+	case gitprovider.MergeMethodMerge, gitprovider.MergeMethodSquash, MergeMethodRebase:
+		return method, nil
+	default:
+		return "", fmt.Errorf("unknown merge method %q, must be one of merge, squash or rebase", name)
+	}
+}
+
+// WaitForChecks polls a pull request until its checks completed, and returns its status once they passed.
+// It fails when a check failed, a reviewer requested changes or the pull request was closed. As checks may
+// take a while to be reported, a pull request without any passes once gracePeriod elapsed.
+func WaitForChecks(ctx context.Context, provider GitProvider, repoUrl RepoURL, pullRequestNumber int, interval, gracePeriod time.Duration) (PullRequestStatus, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	deadline := time.Now().Add(gracePeriod)
+
+	for {
+		status, err := provider.GetPullRequestStatus(ctx, repoUrl, pullRequestNumber)
+		if err != nil {
+			return PullRequestStatus{}, fmt.Errorf("failed to get the status of pull request %d: %w", pullRequestNumber, err)
+		}
+
+		switch {
+		case status.State == PullRequestClosed:
+			return status, fmt.Errorf("%w: pull request %d was closed", ErrChecksFailed, pullRequestNumber)
+		case status.State == PullRequestMerged:
+			return status, nil
+		case status.ChangesRequested:
+			return status, fmt.Errorf("%w: changes were requested on pull request %d", ErrChecksFailed, pullRequestNumber)
+		case status.Checks == ChecksFailed:
+			return status, fmt.Errorf("%w: checks failed on commit %s of pull request %d", ErrChecksFailed, shortSha(status.HeadSha), pullRequestNumber)
+		case status.Checks == ChecksPassed:
+			return status, nil
+		case status.Checks == ChecksNone && !time.Now().Before(deadline):
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, fmt.Errorf("checks of pull request %d still pending: %w", pullRequestNumber, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// combineChecks returns the state of a set of checks: failed when any failed, pending when any is still
+// running, none without checks and passed otherwise.
+func combineChecks(states ...CheckState) CheckState {
+	if len(states) == 0 {
+		return ChecksNone
+	}
+
+	combined := ChecksPassed
+
+	for _, state := range states {
+		switch state {
+		case ChecksFailed:
+			return ChecksFailed
+		case ChecksPending:
+			combined = ChecksPending
+		}
+	}
+
+	return combined
+}
@@ -0,0 +1,114 @@
+package gitproviders
+
+import (
+	"context"
+	"time"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// statusProvider returns a status of a list on each call, the last one once they were all returned.
+type statusProvider struct {
+	GitProvider
+	statuses []PullRequestStatus
+	calls    int
+}
+
+func (p *statusProvider) GetPullRequestStatus(_ context.Context, _ RepoURL, _ int) (PullRequestStatus, error) {
+	status := p.statuses[p.calls]
+	if p.calls < len(p.statuses)-1 {
+		p.calls++
+	}
+
+	return status, nil
+}
+
+var _ = Describe("ParseMergeMethod", func() {
+	It("parses the merge methods", func() {
+		for name, method := range map[string]gitprovider.MergeMethod{
+			"merge":  gitprovider.MergeMethodMerge,
+			"squash": gitprovider.MergeMethodSquash,
+			"rebase": MergeMethodRebase,
+		} {
+			Expect(ParseMergeMethod(name)).To(Equal(method))
+		}
+	})
+
+	It("fails for unknown methods", func() {
+		_, err := ParseMergeMethod("fast-forward")
+		Expect(err).To(MatchError(`unknown merge method "fast-forward", must be one of merge, squash or rebase`))
+	})
+})
+
+var _ = Describe("WaitForChecks", func() {
+	var provider *statusProvider
+
+	open := func(checks CheckState) PullRequestStatus {
+		return PullRequestStatus{Number: 3, State: PullRequestOpen, HeadSha: "4d5e6f708192a3b4", Checks: checks}
+	}
+
+	BeforeEach(func() {
+		provider = &statusProvider{}
+	})
+
+	It("polls until the checks passed", func() {
+		provider.statuses = []PullRequestStatus{open(ChecksPending), open(ChecksPending), open(ChecksPassed)}
+
+		status, err := WaitForChecks(context.Background(), provider, RepoURL{}, 3, time.Millisecond, time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Checks).To(Equal(ChecksPassed))
+		Expect(provider.calls).To(Equal(2))
+	})
+
+	It("keeps polling while no check was reported yet", func() {
+		provider.statuses = []PullRequestStatus{open(ChecksNone), open(ChecksPending), open(ChecksFailed)}
+
+		_, err := WaitForChecks(context.Background(), provider, RepoURL{}, 3, time.Millisecond, time.Hour)
+		Expect(err).To(MatchError(ErrChecksFailed))
+		Expect(provider.calls).To(Equal(2))
+	})
+
+	It("passes without checks once the grace period elapsed", func() {
+		provider.statuses = []PullRequestStatus{open(ChecksNone)}
+
+		status, err := WaitForChecks(context.Background(), provider, RepoURL{}, 3, time.Millisecond, 20*time.Millisecond)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Checks).To(Equal(ChecksNone))
+	})
+
+	It("fails when a check failed", func() {
+		provider.statuses = []PullRequestStatus{open(ChecksPending), open(ChecksFailed)}
+
+		_, err := WaitForChecks(context.Background(), provider, RepoURL{}, 3, time.Millisecond, time.Hour)
+		Expect(err).To(MatchError(ErrChecksFailed))
+		Expect(err).To(MatchError(ContainSubstring("checks failed on commit 4d5e6f7 of pull request 3")))
+	})
+
+	It("fails when changes were requested", func() {
+		status := open(ChecksPassed)
+		status.ChangesRequested = true
+		provider.statuses = []PullRequestStatus{status}
+
+		_, err := WaitForChecks(context.Background(), provider, RepoURL{}, 3, time.Millisecond, time.Hour)
+		Expect(err).To(MatchError(ContainSubstring("changes were requested on pull request 3")))
+	})
+
+	It("fails when the pull request was closed", func() {
+		provider.statuses = []PullRequestStatus{{Number: 3, State: PullRequestClosed}}
+
+		_, err := WaitForChecks(context.Background(), provider, RepoURL{}, 3, time.Millisecond, time.Hour)
+		Expect(err).To(MatchError(ContainSubstring("pull request 3 was closed")))
+	})
+
+	It("gives up when the context is done", func() {
+		provider.statuses = []PullRequestStatus{open(ChecksPending)}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := WaitForChecks(ctx, provider, RepoURL{}, 3, time.Millisecond, time.Hour)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+})
@@ -1,13 +1,16 @@
 package helm
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"sort"
+	"strings"
 
 	sourcev1beta1 "github.com/fluxcd/source-controller/api/v1beta1"
 	"helm.sh/helm/v3/pkg/action"
@@ -29,6 +32,9 @@ import (
 type HelmRepoManager interface {
 	ListCharts(ctx context.Context, hr *sourcev1beta1.HelmRepository, pred ChartPredicate) ([]*pb.Profile, error)
 	GetValuesFile(ctx context.Context, helmRepo *sourcev1beta1.HelmRepository, c *ChartReference, filename string) ([]byte, error)
+	// GetChartFiles returns the files of a chart with the given names, downloading the chart once. Files the chart
+	// does not have are left out.
+	GetChartFiles(ctx context.Context, helmRepo *sourcev1beta1.HelmRepository, c *ChartReference, filenames ...string) (map[string][]byte, error)
 }
 
 // ProfileAnnotation is the annotation that Helm charts must have to indicate
@@ -40,6 +46,10 @@ const ProfileAnnotation = "weave.works/profile"
 // lower layers have successfully installed and started.
 const LayerAnnotation = "weave.works/layer"
 
+// DependenciesAnnotation lists, separated by commas, the names of the profiles
+// that must be installed before the profile in the chart.
+const DependenciesAnnotation = "weave.works/dependencies"
+
 // NewRepoManager creates and returns a new RepoManager.
 func NewRepoManager(kc client.Client, cacheDir string) *RepoManager {
 	return &RepoManager{
@@ -56,7 +66,10 @@ func NewRepoManager(kc client.Client, cacheDir string) *RepoManager {
 // RepoManager implements HelmRepoManager interface using the Helm library packages.
 type RepoManager struct {
 	client.Client
-	CacheDir    string
+	CacheDir string
+	// HTTPClient is used to talk to OCI registries. Defaults to
+	// http.DefaultClient.
+	HTTPClient  *http.Client
 	envSettings *cli.EnvSettings
 }
 
@@ -84,7 +97,7 @@ var Profiles = func(v *repo.ChartVersion) bool {
 
 // ListCharts filters charts using the provided predicate.
 func (h *RepoManager) ListCharts(ctx context.Context, hr *sourcev1beta1.HelmRepository, pred ChartPredicate) ([]*pb.Profile, error) {
-	chartRepo, err := fetchIndexFile(hr.Status.URL)
+	entries, err := h.chartEntries(ctx, hr)
 	if err != nil {
 		return nil, fmt.Errorf("fetching profiles from HelmRepository %s/%s: %w",
 			hr.GetName(), hr.GetNamespace(), err)
@@ -92,7 +105,7 @@ func (h *RepoManager) ListCharts(ctx context.Context, hr *sourcev1beta1.HelmRepo
 
 	ps := make(map[string]*pb.Profile)
 
-	for name, versions := range chartRepo.Entries {
+	for name, versions := range entries {
 		for _, v := range versions {
 			if pred(v) {
 				// if already added, update the versions array
@@ -111,7 +124,8 @@ func (h *RepoManager) ListCharts(ctx context.Context, hr *sourcev1beta1.HelmRepo
 							Name:      hr.Name,
 							Namespace: hr.Namespace,
 						},
-						Layer: getLayer(v.Annotations),
+						Layer:        getLayer(v.Annotations),
+						Dependencies: getDependencies(v.Annotations),
 					}
 					for _, m := range v.Maintainers {
 						p.Maintainers = append(p.Maintainers, &pb.Maintainer{
@@ -137,24 +151,93 @@ func (h *RepoManager) ListCharts(ctx context.Context, hr *sourcev1beta1.HelmRepo
 	return profiles, nil
 }
 
+// chartEntries returns the versions of every chart in the repository, from
+// its index file or, for OCI repositories, from the registry.
+func (h *RepoManager) chartEntries(ctx context.Context, hr *sourcev1beta1.HelmRepository) (map[string]repo.ChartVersions, error) {
+	if IsOCIRepository(hr) {
+		registry, err := h.ociRegistryForRepository(ctx, hr)
+		if err != nil {
+			return nil, err
+		}
+
+		return registry.chartVersions(ctx, hr)
+	}
+
+	chartRepo, err := fetchIndexFile(hr.Status.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return chartRepo.Entries, nil
+}
+
 // GetValuesFile fetches the value file from a chart.
 func (h *RepoManager) GetValuesFile(ctx context.Context, helmRepo *sourcev1beta1.HelmRepository, c *ChartReference, filename string) ([]byte, error) {
-	if err := h.updateCache(ctx, helmRepo); err != nil {
-		return nil, fmt.Errorf("updating cache: %w", err)
+	files, err := h.GetChartFiles(ctx, helmRepo, c, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := files[filename]
+	if !ok {
+		return nil, fmt.Errorf("failed to find file: %s", filename)
+	}
+
+	return data, nil
+}
+
+// GetChartFiles returns the files of a chart with the given names. Files the
+// chart does not have are left out.
+func (h *RepoManager) GetChartFiles(ctx context.Context, helmRepo *sourcev1beta1.HelmRepository, c *ChartReference, filenames ...string) (map[string][]byte, error) {
+	var (
+		chart *chart.Chart
+		err   error
+	)
+
+	if IsOCIRepository(helmRepo) {
+		chart, err = h.loadOCIChart(ctx, helmRepo, c)
+	} else {
+		if err := h.updateCache(ctx, helmRepo); err != nil {
+			return nil, fmt.Errorf("updating cache: %w", err)
+		}
+
+		chart, err = h.loadChart(ctx, helmRepo, c)
 	}
 
-	chart, err := h.loadChart(ctx, helmRepo, c)
 	if err != nil {
-		return nil, fmt.Errorf("loading %s from chart: %w", filename, err)
+		return nil, fmt.Errorf("loading %s from chart: %w", strings.Join(filenames, ", "), err)
 	}
 
+	files := make(map[string][]byte)
+
 	for _, v := range chart.Raw {
-		if v.Name == filename {
-			return v.Data, nil
+		for _, name := range filenames {
+			if v.Name == name {
+				files[name] = v.Data
+			}
 		}
 	}
 
-	return nil, fmt.Errorf("failed to find file: %s", filename)
+	return files, nil
+}
+
+func (h *RepoManager) loadOCIChart(ctx context.Context, helmRepo *sourcev1beta1.HelmRepository, c *ChartReference) (*chart.Chart, error) {
+	registry, err := h.ociRegistryForRepository(ctx, helmRepo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure registry client: %w", err)
+	}
+
+	archive, err := registry.chartArchive(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	chart, err := loader.LoadArchive(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart %q: %w", c.Chart, err)
+	}
+
+	return chart, nil
 }
 
 func (h *RepoManager) updateCache(ctx context.Context, helmRepo *sourcev1beta1.HelmRepository) error {
@@ -294,6 +377,18 @@ func getLayer(annotations map[string]string) string {
 	return annotations[LayerAnnotation]
 }
 
+func getDependencies(annotations map[string]string) []string {
+	var deps []string
+
+	for _, d := range strings.Split(annotations[DependenciesAnnotation], ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			deps = append(deps, d)
+		}
+	}
+
+	return deps
+}
+
 func hasAnnotation(cm *chart.Metadata, name string) bool {
 	for k := range cm.Annotations {
 		if k == name {
@@ -147,6 +147,17 @@ var _ = Describe("RepoManager", func() {
 			Expect(string(values)).To(Equal("favoriteDrink: coffee\n"))
 		})
 
+		It("returns the files the chart has", func() {
+			testServer := httptest.NewServer(makeServeMux())
+			helmRepo := makeTestHelmRepository(testServer.URL)
+			chartReference := &helm.ChartReference{Chart: "demo-profile", Version: "0.0.1"}
+			repoManager := helm.NewRepoManager(makeTestClient(), tempDir)
+
+			files, err := repoManager.GetChartFiles(context.TODO(), helmRepo, chartReference, "values.yaml", helm.ValuesSchemaFileName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(Equal(map[string][]byte{"values.yaml": []byte("favoriteDrink: coffee\n")}))
+		})
+
 		When("the chart version doesn't exist", func() {
 			It("errors", func() {
 				testServer := httptest.NewServer(makeServeMux())
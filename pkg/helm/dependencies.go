@@ -0,0 +1,370 @@
+package helm
+
+import (
+	"fmt"
+	"strings"
+
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
+	"github.com/fluxcd/pkg/runtime/dependency"
+
+	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
+)
+
+// AppliedLayerLabel is set on the HelmReleases of profiles to the layer of the
+// profile.
+const AppliedLayerLabel = "weave.works/applied-layer"
+
+// ResolveDependencies works out the install order of the HelmReleases of a
+// cluster's profiles. A release depends on the releases of the profiles named
+// in its profile's dependencies annotation, and on the releases in the
+// nearest lower layer, layers being ordered by compareLayers. The missing
+// dependencies are added to the spec.dependsOn of each release; entries that
+// are already there are kept.
+//
+// The releases are returned sorted so that each one comes after the releases
+// it depends on. Releases whose chart is not one of the given profiles are
+// left as they are, apart from their position. It fails if a dependency is
+// not installed, if a profile depends on a profile in a higher layer, or if
+// the dependencies form a cycle.
+func ResolveDependencies(releases []*helmv2beta1.HelmRelease, profiles []*pb.Profile) ([]*helmv2beta1.HelmRelease, error) {
+	g := newDependencyGraph(releases)
+
+	for i, r := range releases {
+		p := findReleaseProfile(r, profiles)
+		if p == nil {
+			continue
+		}
+
+		if p.Layer != "" {
+			if r.Labels == nil {
+				r.Labels = map[string]string{}
+			}
+
+			r.Labels[AppliedLayerLabel] = p.Layer
+		}
+
+		deps, err := g.profileDependencies(i, p, profiles)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, d := range deps {
+			g.addDependency(i, d)
+		}
+	}
+
+	return g.sort()
+}
+
+// Dependents returns the releases whose spec.dependsOn refers to r.
+func Dependents(releases []*helmv2beta1.HelmRelease, r *helmv2beta1.HelmRelease) []*helmv2beta1.HelmRelease {
+	var dependents []*helmv2beta1.HelmRelease
+
+	for _, other := range releases {
+		for _, d := range other.Spec.DependsOn {
+			if dependencyKey(other, d) == r.Namespace+"/"+r.Name {
+				dependents = append(dependents, other)
+				break
+			}
+		}
+	}
+
+	return dependents
+}
+
+type dependencyGraph struct {
+	releases []*helmv2beta1.HelmRelease
+	index    map[string]int
+	// edges holds, for each release, the releases it depends on.
+	edges [][]int
+}
+
+func newDependencyGraph(releases []*helmv2beta1.HelmRelease) *dependencyGraph {
+	g := &dependencyGraph{
+		releases: releases,
+		index:    map[string]int{},
+		edges:    make([][]int, len(releases)),
+	}
+
+	for i, r := range releases {
+		g.index[r.Namespace+"/"+r.Name] = i
+	}
+
+	// Dependencies wired by hand take part in the ordering too. Those on
+	// objects that are not in the list are ignored.
+	for i, r := range releases {
+		for _, d := range r.Spec.DependsOn {
+			if j, ok := g.index[dependencyKey(r, d)]; ok {
+				g.edges[i] = append(g.edges[i], j)
+			}
+		}
+	}
+
+	return g
+}
+
+// profileDependencies returns the releases that the release at index i, which
+// installs profile p, depends on.
+func (g *dependencyGraph) profileDependencies(i int, p *pb.Profile, profiles []*pb.Profile) ([]int, error) {
+	r := g.releases[i]
+
+	var deps []int
+
+	for _, name := range p.Dependencies {
+		j, err := g.findProfileRelease(r, name)
+		if err != nil {
+			return nil, err
+		}
+
+		dp := findReleaseProfile(g.releases[j], profiles)
+		if dp != nil && p.Layer != "" && dp.Layer != "" && compareLayers(dp.Layer, p.Layer) > 0 {
+			return nil, fmt.Errorf("profile '%s' in layer %q depends on profile '%s' in the higher layer %q", p.Name, p.Layer, name, dp.Layer)
+		}
+
+		deps = append(deps, j)
+	}
+
+	if p.Layer == "" {
+		return deps, nil
+	}
+
+	// Find the nearest layer below the one of the profile.
+	below := make(map[int]string)
+
+	var nearest string
+
+	for j, other := range g.releases {
+		op := findReleaseProfile(other, profiles)
+		if j == i || op == nil || op.Layer == "" || compareLayers(op.Layer, p.Layer) >= 0 {
+			continue
+		}
+
+		below[j] = op.Layer
+
+		if nearest == "" || compareLayers(op.Layer, nearest) > 0 {
+			nearest = op.Layer
+		}
+	}
+
+	for j := range g.releases {
+		if l, ok := below[j]; ok && l == nearest {
+			deps = append(deps, j)
+		}
+	}
+
+	return deps, nil
+}
+
+// findProfileRelease returns the release of the profile named name, preferring
+// the one in the namespace of r.
+func (g *dependencyGraph) findProfileRelease(r *helmv2beta1.HelmRelease, name string) (int, error) {
+	var candidates []int
+
+	for j, other := range g.releases {
+		if other.Spec.Chart.Spec.Chart != name {
+			continue
+		}
+
+		if other.Namespace == r.Namespace {
+			return j, nil
+		}
+
+		candidates = append(candidates, j)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return 0, fmt.Errorf("profile '%s' depends on profile '%s', which is not installed", r.Spec.Chart.Spec.Chart, name)
+	case 1:
+		return candidates[0], nil
+	}
+
+	var namespaces []string
+	for _, j := range candidates {
+		namespaces = append(namespaces, g.releases[j].Namespace)
+	}
+
+	return 0, fmt.Errorf("profile '%s' depends on profile '%s', which is installed in several namespaces: %s",
+		r.Spec.Chart.Spec.Chart, name, strings.Join(namespaces, ", "))
+}
+
+// addDependency records that the release at index i depends on the one at
+// index j, adding it to its spec.dependsOn unless it is already there.
+func (g *dependencyGraph) addDependency(i, j int) {
+	for _, e := range g.edges[i] {
+		if e == j {
+			return
+		}
+	}
+
+	g.edges[i] = append(g.edges[i], j)
+
+	r, dep := g.releases[i], g.releases[j]
+	ref := dependency.CrossNamespaceDependencyReference{Name: dep.Name}
+
+	if dep.Namespace != r.Namespace {
+		ref.Namespace = dep.Namespace
+	}
+
+	r.Spec.DependsOn = append(r.Spec.DependsOn, ref)
+}
+
+// sort returns the releases in install order, keeping the original order
+// where there is a choice.
+func (g *dependencyGraph) sort() ([]*helmv2beta1.HelmRelease, error) {
+	done := make([]bool, len(g.releases))
+	sorted := make([]*helmv2beta1.HelmRelease, 0, len(g.releases))
+
+	for len(sorted) < len(g.releases) {
+		next := -1
+
+		for i := range g.releases {
+			if !done[i] && g.ready(i, done) {
+				next = i
+				break
+			}
+		}
+
+		if next == -1 {
+			return nil, g.cycleError(done)
+		}
+
+		done[next] = true
+		sorted = append(sorted, g.releases[next])
+	}
+
+	return sorted, nil
+}
+
+func (g *dependencyGraph) ready(i int, done []bool) bool {
+	for _, j := range g.edges[i] {
+		if !done[j] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cycleError describes a cycle among the releases that could not be sorted.
+func (g *dependencyGraph) cycleError(done []bool) error {
+	// Every release left depends on another release left, so following the
+	// dependencies from any of them ends up going round a cycle.
+	i := 0
+	for done[i] {
+		i++
+	}
+
+	seen := map[int]int{}
+
+	var path []int
+
+	for {
+		if start, ok := seen[i]; ok {
+			path = append(path[start:], i)
+			break
+		}
+
+		seen[i] = len(path)
+		path = append(path, i)
+
+		for _, j := range g.edges[i] {
+			if !done[j] {
+				i = j
+				break
+			}
+		}
+	}
+
+	names := make([]string, 0, len(path))
+	for _, j := range path {
+		names = append(names, g.releases[j].Name)
+	}
+
+	return fmt.Errorf("dependency cycle between HelmReleases: %s", strings.Join(names, " -> "))
+}
+
+// findReleaseProfile returns the profile installed by a release, preferring
+// the one from the HelmRepository the release refers to.
+func findReleaseProfile(r *helmv2beta1.HelmRelease, profiles []*pb.Profile) *pb.Profile {
+	var found *pb.Profile
+
+	for _, p := range profiles {
+		if p.Name != r.Spec.Chart.Spec.Chart {
+			continue
+		}
+
+		ref := r.Spec.Chart.Spec.SourceRef
+		if p.GetHelmRepository().GetName() == ref.Name && p.GetHelmRepository().GetNamespace() == ref.Namespace {
+			return p
+		}
+
+		if found == nil {
+			found = p
+		}
+	}
+
+	return found
+}
+
+// compareLayers orders layers like strings.Compare, except that runs of digits
+// are compared as numbers, so that layer-2 comes before layer-10.
+func compareLayers(a, b string) int {
+	for a != "" && b != "" {
+		da, db := digitPrefix(a), digitPrefix(b)
+
+		if da == "" || db == "" {
+			if a[0] != b[0] {
+				return strings.Compare(a[:1], b[:1])
+			}
+
+			a, b = a[1:], b[1:]
+
+			continue
+		}
+
+		// leading zeros don't change the number
+		na, nb := strings.TrimLeft(da, "0"), strings.TrimLeft(db, "0")
+		if len(na) != len(nb) {
+			return compareInts(len(na), len(nb))
+		}
+
+		if c := strings.Compare(na, nb); c != 0 {
+			return c
+		}
+
+		a, b = a[len(da):], b[len(db):]
+	}
+
+	return compareInts(len(a), len(b))
+}
+
+// digitPrefix returns the digits at the start of s.
+func digitPrefix(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+
+	return s[:i]
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func dependencyKey(r *helmv2beta1.HelmRelease, d dependency.CrossNamespaceDependencyReference) string {
+	ns := d.Namespace
+	if ns == "" {
+		ns = r.Namespace
+	}
+
+	return ns + "/" + d.Name
+}
@@ -0,0 +1,128 @@
+package helm_test
+
+import (
+	"github.com/fluxcd/pkg/runtime/dependency"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
+
+	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
+	"github.com/weaveworks/weave-gitops/pkg/helm"
+)
+
+var _ = Describe("ResolveDependencies", func() {
+	var profiles []*pb.Profile
+
+	release := func(name, ns string) *helmv2beta1.HelmRelease {
+		return helm.MakeHelmRelease(name, "0.0.1", "prod", ns, types.NamespacedName{Name: "profiles", Namespace: "flux-system"})
+	}
+
+	profile := func(name, layer string, deps ...string) *pb.Profile {
+		return &pb.Profile{
+			Name:           name,
+			Layer:          layer,
+			Dependencies:   deps,
+			HelmRepository: &pb.HelmRepository{Name: "profiles", Namespace: "flux-system"},
+		}
+	}
+
+	names := func(releases []*helmv2beta1.HelmRelease) []string {
+		var n []string
+		for _, r := range releases {
+			n = append(n, r.Name)
+		}
+
+		return n
+	}
+
+	BeforeEach(func() {
+		profiles = []*pb.Profile{
+			profile("observability", "layer-0"),
+			profile("ingress", "layer-1"),
+			profile("policies", "layer-1"),
+			profile("podinfo", "layer-2", "policies"),
+			profile("dashboards", "", "observability"),
+		}
+	})
+
+	It("makes each release depend on the releases in the layer below", func() {
+		releases, err := helm.ResolveDependencies([]*helmv2beta1.HelmRelease{
+			release("podinfo", "apps"),
+			release("ingress", "system"),
+			release("policies", "system"),
+			release("observability", "system"),
+		}, profiles)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(releases)).To(Equal([]string{"prod-observability", "prod-ingress", "prod-policies", "prod-podinfo"}))
+
+		Expect(releases[0].Spec.DependsOn).To(BeEmpty())
+		Expect(releases[0].Labels).To(HaveKeyWithValue(helm.AppliedLayerLabel, "layer-0"))
+		Expect(releases[1].Spec.DependsOn).To(Equal([]dependency.CrossNamespaceDependencyReference{{Name: "prod-observability"}}))
+		Expect(releases[3].Spec.DependsOn).To(Equal([]dependency.CrossNamespaceDependencyReference{
+			{Name: "prod-policies", Namespace: "system"},
+			{Name: "prod-ingress", Namespace: "system"},
+		}))
+	})
+
+	It("compares the numbers in layers numerically", func() {
+		profiles = append(profiles, profile("backups", "layer-10"))
+
+		releases, err := helm.ResolveDependencies([]*helmv2beta1.HelmRelease{
+			release("backups", "system"),
+			release("podinfo", "apps"),
+			release("policies", "system"),
+		}, profiles)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(releases)).To(Equal([]string{"prod-policies", "prod-podinfo", "prod-backups"}))
+		Expect(releases[2].Spec.DependsOn).To(Equal([]dependency.CrossNamespaceDependencyReference{{Name: "prod-podinfo", Namespace: "apps"}}))
+	})
+
+	It("orders releases by their explicit dependencies and keeps the existing ones", func() {
+		custom := release("custom", "system")
+		dashboards := release("dashboards", "system")
+		dashboards.Spec.DependsOn = []dependency.CrossNamespaceDependencyReference{{Name: "prod-custom"}, {Name: "prod-observability"}}
+
+		releases, err := helm.ResolveDependencies([]*helmv2beta1.HelmRelease{dashboards, release("observability", "system"), custom}, profiles)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(releases)).To(Equal([]string{"prod-observability", "prod-custom", "prod-dashboards"}))
+		Expect(releases[2].Spec.DependsOn).To(HaveLen(2))
+	})
+
+	It("fails when a dependency is not installed", func() {
+		_, err := helm.ResolveDependencies([]*helmv2beta1.HelmRelease{release("dashboards", "system")}, profiles)
+		Expect(err).To(MatchError("profile 'dashboards' depends on profile 'observability', which is not installed"))
+	})
+
+	It("fails when a profile depends on a profile in a higher layer", func() {
+		profiles = append(profiles, profile("broken", "layer-0", "ingress"))
+
+		_, err := helm.ResolveDependencies([]*helmv2beta1.HelmRelease{release("ingress", "system"), release("broken", "system")}, profiles)
+		Expect(err).To(MatchError(`profile 'broken' in layer "layer-0" depends on profile 'ingress' in the higher layer "layer-1"`))
+	})
+
+	It("fails when the dependencies form a cycle", func() {
+		profiles = append(profiles, profile("a", "", "b"), profile("b", "", "c"), profile("c", "", "a"))
+
+		_, err := helm.ResolveDependencies([]*helmv2beta1.HelmRelease{release("a", "system"), release("b", "system"), release("c", "system")}, profiles)
+		Expect(err).To(MatchError("dependency cycle between HelmReleases: prod-a -> prod-b -> prod-c -> prod-a"))
+	})
+})
+
+var _ = Describe("Dependents", func() {
+	It("returns the releases depending on a release", func() {
+		repo := types.NamespacedName{Name: "profiles", Namespace: "flux-system"}
+		observability := helm.MakeHelmRelease("observability", "0.0.1", "prod", "system", repo)
+		dashboards := helm.MakeHelmRelease("dashboards", "0.0.1", "prod", "system", repo)
+		dashboards.Spec.DependsOn = []dependency.CrossNamespaceDependencyReference{{Name: "prod-observability"}}
+		podinfo := helm.MakeHelmRelease("podinfo", "0.0.1", "prod", "apps", repo)
+		podinfo.Spec.DependsOn = []dependency.CrossNamespaceDependencyReference{{Name: "prod-observability", Namespace: "system"}}
+		other := helm.MakeHelmRelease("other", "0.0.1", "prod", "apps", repo)
+		other.Spec.DependsOn = []dependency.CrossNamespaceDependencyReference{{Name: "prod-observability"}}
+
+		releases := []*helmv2beta1.HelmRelease{observability, dashboards, podinfo, other}
+		Expect(helm.Dependents(releases, observability)).To(Equal([]*helmv2beta1.HelmRelease{dashboards, podinfo}))
+		Expect(helm.Dependents(releases, podinfo)).To(BeEmpty())
+	})
+})
@@ -11,6 +11,22 @@ import (
 )
 
 type FakeHelmRepoManager struct {
+	GetChartFilesStub        func(context.Context, *v1beta1.HelmRepository, *helm.ChartReference, ...string) (map[string][]byte, error)
+	getChartFilesMutex       sync.RWMutex
+	getChartFilesArgsForCall []struct {
+		arg1 context.Context
+		arg2 *v1beta1.HelmRepository
+		arg3 *helm.ChartReference
+		arg4 []string
+	}
+	getChartFilesReturns struct {
+		result1 map[string][]byte
+		result2 error
+	}
+	getChartFilesReturnsOnCall map[int]struct {
+		result1 map[string][]byte
+		result2 error
+	}
 	GetValuesFileStub        func(context.Context, *v1beta1.HelmRepository, *helm.ChartReference, string) ([]byte, error)
 	getValuesFileMutex       sync.RWMutex
 	getValuesFileArgsForCall []struct {
@@ -46,6 +62,73 @@ type FakeHelmRepoManager struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeHelmRepoManager) GetChartFiles(arg1 context.Context, arg2 *v1beta1.HelmRepository, arg3 *helm.ChartReference, arg4 ...string) (map[string][]byte, error) {
+	fake.getChartFilesMutex.Lock()
+	ret, specificReturn := fake.getChartFilesReturnsOnCall[len(fake.getChartFilesArgsForCall)]
+	fake.getChartFilesArgsForCall = append(fake.getChartFilesArgsForCall, struct {
+		arg1 context.Context
+		arg2 *v1beta1.HelmRepository
+		arg3 *helm.ChartReference
+		arg4 []string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.GetChartFilesStub
+	fakeReturns := fake.getChartFilesReturns
+	fake.recordInvocation("GetChartFiles", []interface{}{arg1, arg2, arg3, arg4})
+	fake.getChartFilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeHelmRepoManager) GetChartFilesCallCount() int {
+	fake.getChartFilesMutex.RLock()
+	defer fake.getChartFilesMutex.RUnlock()
+	return len(fake.getChartFilesArgsForCall)
+}
+
+func (fake *FakeHelmRepoManager) GetChartFilesCalls(stub func(context.Context, *v1beta1.HelmRepository, *helm.ChartReference, ...string) (map[string][]byte, error)) {
+	fake.getChartFilesMutex.Lock()
+	defer fake.getChartFilesMutex.Unlock()
+	fake.GetChartFilesStub = stub
+}
+
+func (fake *FakeHelmRepoManager) GetChartFilesArgsForCall(i int) (context.Context, *v1beta1.HelmRepository, *helm.ChartReference, []string) {
+	fake.getChartFilesMutex.RLock()
+	defer fake.getChartFilesMutex.RUnlock()
+	argsForCall := fake.getChartFilesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeHelmRepoManager) GetChartFilesReturns(result1 map[string][]byte, result2 error) {
+	fake.getChartFilesMutex.Lock()
+	defer fake.getChartFilesMutex.Unlock()
+	fake.GetChartFilesStub = nil
+	fake.getChartFilesReturns = struct {
+		result1 map[string][]byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHelmRepoManager) GetChartFilesReturnsOnCall(i int, result1 map[string][]byte, result2 error) {
+	fake.getChartFilesMutex.Lock()
+	defer fake.getChartFilesMutex.Unlock()
+	fake.GetChartFilesStub = nil
+	if fake.getChartFilesReturnsOnCall == nil {
+		fake.getChartFilesReturnsOnCall = make(map[int]struct {
+			result1 map[string][]byte
+			result2 error
+		})
+	}
+	fake.getChartFilesReturnsOnCall[i] = struct {
+		result1 map[string][]byte
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeHelmRepoManager) GetValuesFile(arg1 context.Context, arg2 *v1beta1.HelmRepository, arg3 *helm.ChartReference, arg4 string) ([]byte, error) {
 	fake.getValuesFileMutex.Lock()
 	ret, specificReturn := fake.getValuesFileReturnsOnCall[len(fake.getValuesFileArgsForCall)]
@@ -182,6 +265,8 @@ func (fake *FakeHelmRepoManager) ListChartsReturnsOnCall(i int, result1 []*profi
 func (fake *FakeHelmRepoManager) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.getChartFilesMutex.RLock()
+	defer fake.getChartFilesMutex.RUnlock()
 	fake.getValuesFileMutex.RLock()
 	defer fake.getValuesFileMutex.RUnlock()
 	fake.listChartsMutex.RLock()
@@ -0,0 +1,511 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	sourcev1beta1 "github.com/fluxcd/source-controller/api/v1beta1"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/repo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// OCIScheme is the URL scheme of HelmRepositories backed by an OCI
+	// registry.
+	OCIScheme = "oci"
+
+	// OCIChartsAnnotation lists, comma separated, the charts of an OCI
+	// HelmRepository to scan for profiles. It is needed for registries that
+	// do not implement the catalog API, which is used otherwise.
+	OCIChartsAnnotation = "weave.works/profile-charts"
+
+	ociManifestMediaType  = "application/vnd.oci.image.manifest.v1+json"
+	helmConfigMediaType   = "application/vnd.cncf.helm.config.v1+json"
+	helmChartMediaType    = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	legacyChartMediaType  = "application/tar+gzip"
+	ociCatalogPageSize    = 1000
+	maxOCIBlobSizeInBytes = 32 << 20
+)
+
+// IsOCIRepository returns true when the HelmRepository URL points at an OCI
+// registry, e.g. oci://ghcr.io/weaveworks/charts.
+func IsOCIRepository(hr *sourcev1beta1.HelmRepository) bool {
+	return strings.HasPrefix(hr.Spec.URL, OCIScheme+"://")
+}
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type ociManifest struct {
+	Config      ociDescriptor     `json:"config"`
+	Layers      []ociDescriptor   `json:"layers"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ociRegistry is a minimal client of the OCI distribution API, enough to
+// discover the charts pushed to a repository by `helm push`.
+type ociRegistry struct {
+	httpClient *http.Client
+	host       string
+	// prefix is the path of the HelmRepository in the registry, charts are
+	// stored as prefix/<chart name>.
+	prefix   string
+	username string
+	password string
+	// tokens caches bearer tokens by scope.
+	tokens map[string]string
+}
+
+func (h *RepoManager) ociRegistryForRepository(ctx context.Context, hr *sourcev1beta1.HelmRepository) (*ociRegistry, error) {
+	u, err := url.Parse(hr.Spec.URL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing URL %q: %w", hr.Spec.URL, err)
+	}
+
+	r := &ociRegistry{
+		httpClient: h.HTTPClient,
+		host:       u.Host,
+		prefix:     strings.Trim(u.Path, "/"),
+		tokens:     map[string]string{},
+	}
+
+	if r.httpClient == nil {
+		r.httpClient = http.DefaultClient
+	}
+
+	if hr.Spec.SecretRef != nil {
+		r.username, r.password, err = ociCredsForRepository(ctx, h.Client, hr, u.Host)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// chartVersions returns the versions of every chart in the repository, with
+// the metadata read from the chart manifests.
+func (r *ociRegistry) chartVersions(ctx context.Context, hr *sourcev1beta1.HelmRepository) (map[string]repo.ChartVersions, error) {
+	names, err := r.chartNames(ctx, hr)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := map[string]repo.ChartVersions{}
+
+	for _, name := range names {
+		tags, err := r.tags(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tag := range tags {
+			// Helm replaces the "+" of build metadata, which is not allowed
+			// in tags.
+			version := strings.ReplaceAll(tag, "_", "+")
+			if _, err := semver.NewVersion(version); err != nil {
+				continue
+			}
+
+			manifest, err := r.manifest(ctx, name, tag)
+			if err != nil {
+				return nil, err
+			}
+
+			if manifest.Config.MediaType != helmConfigMediaType {
+				continue
+			}
+
+			config, err := r.blob(ctx, name, manifest.Config)
+			if err != nil {
+				return nil, err
+			}
+
+			metadata := &chart.Metadata{}
+			if err := json.Unmarshal(config, metadata); err != nil {
+				return nil, fmt.Errorf("error decoding metadata of chart %s:%s: %w", name, tag, err)
+			}
+
+			// The tag is what the chart is pulled by.
+			metadata.Version = version
+
+			// Annotations can also be set on the manifest when pushing, e.g.
+			// with `oras push --annotation`.
+			for k, v := range manifest.Annotations {
+				if _, ok := metadata.Annotations[k]; !ok {
+					if metadata.Annotations == nil {
+						metadata.Annotations = map[string]string{}
+					}
+
+					metadata.Annotations[k] = v
+				}
+			}
+
+			entries[name] = append(entries[name], &repo.ChartVersion{Metadata: metadata})
+		}
+	}
+
+	return entries, nil
+}
+
+// chartArchive downloads the packaged chart of the given version.
+func (r *ociRegistry) chartArchive(ctx context.Context, c *ChartReference) ([]byte, error) {
+	manifest, err := r.manifest(ctx, c.Chart, strings.ReplaceAll(c.Version, "+", "_"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == helmChartMediaType || layer.MediaType == legacyChartMediaType {
+			return r.blob(ctx, c.Chart, layer)
+		}
+	}
+
+	return nil, fmt.Errorf("chart %q version %q has no chart content layer", c.Chart, c.Version)
+}
+
+func (r *ociRegistry) chartNames(ctx context.Context, hr *sourcev1beta1.HelmRepository) ([]string, error) {
+	if charts := hr.GetAnnotations()[OCIChartsAnnotation]; charts != "" {
+		var names []string
+
+		for _, name := range strings.Split(charts, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+
+		return names, nil
+	}
+
+	var names []string
+
+	next := fmt.Sprintf("/v2/_catalog?n=%d", ociCatalogPageSize)
+
+	for next != "" {
+		var catalog struct {
+			Repositories []string `json:"repositories"`
+		}
+
+		var err error
+
+		next, err = r.getJSON(ctx, next, "registry:catalog:*", "", &catalog)
+		if err != nil {
+			return nil, fmt.Errorf("listing charts, set the %s annotation if the registry has no catalog: %w", OCIChartsAnnotation, err)
+		}
+
+		for _, repository := range catalog.Repositories {
+			name := repository
+
+			if r.prefix != "" {
+				if !strings.HasPrefix(repository, r.prefix+"/") {
+					continue
+				}
+
+				name = strings.TrimPrefix(repository, r.prefix+"/")
+			}
+
+			if !strings.Contains(name, "/") {
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (r *ociRegistry) tags(ctx context.Context, chartName string) ([]string, error) {
+	var tags []string
+
+	next := fmt.Sprintf("/v2/%s/tags/list", r.repository(chartName))
+
+	for next != "" {
+		var list struct {
+			Tags []string `json:"tags"`
+		}
+
+		var err error
+
+		next, err = r.getJSON(ctx, next, r.pullScope(chartName), "", &list)
+		if err != nil {
+			return nil, fmt.Errorf("listing tags of chart %q: %w", chartName, err)
+		}
+
+		tags = append(tags, list.Tags...)
+	}
+
+	return tags, nil
+}
+
+func (r *ociRegistry) manifest(ctx context.Context, chartName, reference string) (*ociManifest, error) {
+	manifest := &ociManifest{}
+
+	path := fmt.Sprintf("/v2/%s/manifests/%s", r.repository(chartName), reference)
+	if _, err := r.getJSON(ctx, path, r.pullScope(chartName), ociManifestMediaType, manifest); err != nil {
+		return nil, fmt.Errorf("fetching manifest of chart %s:%s: %w", chartName, reference, err)
+	}
+
+	return manifest, nil
+}
+
+func (r *ociRegistry) blob(ctx context.Context, chartName string, desc ociDescriptor) ([]byte, error) {
+	if desc.Size > maxOCIBlobSizeInBytes {
+		return nil, fmt.Errorf("blob %s of chart %q is too large: %d bytes", desc.Digest, chartName, desc.Size)
+	}
+
+	res, err := r.get(ctx, fmt.Sprintf("/v2/%s/blobs/%s", r.repository(chartName), desc.Digest), r.pullScope(chartName), "")
+	if err != nil {
+		return nil, fmt.Errorf("fetching blob %s of chart %q: %w", desc.Digest, chartName, err)
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(res.Body, maxOCIBlobSizeInBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading blob %s of chart %q: %w", desc.Digest, chartName, err)
+	}
+
+	sum := sha256.Sum256(data)
+	if digest := "sha256:" + hex.EncodeToString(sum[:]); strings.HasPrefix(desc.Digest, "sha256:") && digest != desc.Digest {
+		return nil, fmt.Errorf("blob of chart %q does not match digest %s", chartName, desc.Digest)
+	}
+
+	return data, nil
+}
+
+func (r *ociRegistry) repository(chartName string) string {
+	if r.prefix == "" {
+		return chartName
+	}
+
+	return r.prefix + "/" + chartName
+}
+
+func (r *ociRegistry) pullScope(chartName string) string {
+	return "repository:" + r.repository(chartName) + ":pull"
+}
+
+// getJSON decodes the response to a GET request for path into v, and returns
+// the path of the next page, if any.
+func (r *ociRegistry) getJSON(ctx context.Context, path, scope, accept string, v interface{}) (string, error) {
+	res, err := r.get(ctx, path, scope, accept)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return "", fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return nextPage(res.Header.Get("Link")), nil
+}
+
+func (r *ociRegistry) get(ctx context.Context, path, scope, accept string) (*http.Response, error) {
+	res, err := r.do(ctx, path, scope, accept)
+	if err != nil {
+		return nil, err
+	}
+
+	// Registries with token authentication tell where to get a token in
+	// their first response.
+	if res.StatusCode == http.StatusUnauthorized {
+		challenge := res.Header.Get("WWW-Authenticate")
+		res.Body.Close()
+
+		if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+			return nil, fmt.Errorf("unauthorized: %s", path)
+		}
+
+		if err := r.fetchToken(ctx, challenge, scope); err != nil {
+			return nil, err
+		}
+
+		res, err = r.do(ctx, path, scope, accept)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		res.Body.Close()
+
+		return nil, fmt.Errorf("unexpected status %s: %s", res.Status, bytes.TrimSpace(body))
+	}
+
+	return res, nil
+}
+
+func (r *ociRegistry) do(ctx context.Context, path, scope, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+r.host+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	switch {
+	case r.tokens[scope] != "":
+		req.Header.Set("Authorization", "Bearer "+r.tokens[scope])
+	case r.username != "" || r.password != "":
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	return r.httpClient.Do(req)
+}
+
+// fetchToken gets a bearer token for scope from the authorization server
+// named in a WWW-Authenticate challenge.
+func (r *ociRegistry) fetchToken(ctx context.Context, challenge, scope string) error {
+	params := parseChallenge(challenge[len("bearer "):])
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return fmt.Errorf("invalid authentication challenge %q", challenge)
+	}
+
+	q := realm.Query()
+	if params["service"] != "" {
+		q.Set("service", params["service"])
+	}
+
+	q.Set("scope", scope)
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	if r.username != "" || r.password != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	res, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetching registry token: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching registry token: unexpected status %s", res.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return fmt.Errorf("error decoding registry token: %w", err)
+	}
+
+	r.tokens[scope] = token.Token
+	if r.tokens[scope] == "" {
+		r.tokens[scope] = token.AccessToken
+	}
+
+	return nil
+}
+
+// parseChallenge parses the comma separated key="value" parameters of a
+// WWW-Authenticate header.
+func parseChallenge(s string) map[string]string {
+	params := map[string]string{}
+
+	for _, part := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) == 2 {
+			params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+
+	return params
+}
+
+// nextPage returns the path of a Link: <path>; rel="next" header.
+func nextPage(link string) string {
+	if !strings.Contains(link, `rel="next"`) {
+		return ""
+	}
+
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start == -1 || end < start {
+		return ""
+	}
+
+	u, err := url.Parse(link[start+1 : end])
+	if err != nil {
+		return ""
+	}
+
+	return u.RequestURI()
+}
+
+// ociCredsForRepository reads the registry credentials from the Secret of the
+// HelmRepository, either a docker config for host or username and password.
+func ociCredsForRepository(ctx context.Context, kc client.Client, hr *sourcev1beta1.HelmRepository, host string) (string, string, error) {
+	var secret corev1.Secret
+	if err := kc.Get(ctx, types.NamespacedName{Name: hr.Spec.SecretRef.Name, Namespace: hr.Namespace}, &secret); err != nil {
+		return "", "", fmt.Errorf("repository authentication: %w", err)
+	}
+
+	dockerConfig, ok := secret.Data[corev1.DockerConfigJsonKey]
+	if !ok {
+		return string(secret.Data["username"]), string(secret.Data["password"]), nil
+	}
+
+	var config struct {
+		Auths map[string]struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			Auth     string `json:"auth"`
+		} `json:"auths"`
+	}
+
+	if err := json.Unmarshal(dockerConfig, &config); err != nil {
+		return "", "", fmt.Errorf("repository authentication: invalid %s: %w", corev1.DockerConfigJsonKey, err)
+	}
+
+	auth, ok := config.Auths[host]
+	if !ok {
+		return "", "", fmt.Errorf("repository authentication: no credentials for %s in Secret %s", host, secret.Name)
+	}
+
+	if auth.Auth != "" && auth.Username == "" {
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("repository authentication: invalid auth for %s: %w", host, err)
+		}
+
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return "", "", fmt.Errorf("repository authentication: invalid auth for %s", host)
+		}
+
+		return parts[0], parts[1], nil
+	}
+
+	return auth.Username, auth.Password, nil
+}
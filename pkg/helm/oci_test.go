@@ -0,0 +1,207 @@
+package helm_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	fluxmeta "github.com/fluxcd/pkg/apis/meta"
+	sourcev1beta1 "github.com/fluxcd/source-controller/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
+	"github.com/weaveworks/weave-gitops/pkg/helm"
+)
+
+var _ = Describe("RepoManager with an OCI HelmRepository", func() {
+	var (
+		registry    *fakeRegistry
+		server      *httptest.Server
+		repoManager *helm.RepoManager
+	)
+
+	BeforeEach(func() {
+		registry = newFakeRegistry("charts/demo-profile", "testdata/charts/demo-profile-0.1.0.tgz", "0.0.1", "0.0.2_build.1", "latest")
+		server = httptest.NewTLSServer(registry)
+		registry.realm = server.URL + "/token"
+
+		repoManager = helm.NewRepoManager(makeTestClient(), "")
+		repoManager.HTTPClient = server.Client()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("lists the profiles and their versions", func() {
+		profiles, err := repoManager.ListCharts(context.TODO(), makeOCIHelmRepository(server), helm.Profiles)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(profiles).To(HaveLen(1))
+		Expect(profiles[0].Name).To(Equal("demo-profile"))
+		Expect(profiles[0].AvailableVersions).To(Equal([]string{"0.0.1", "0.0.2+build.1"}))
+		Expect(profiles[0].HelmRepository).To(Equal(&pb.HelmRepository{Name: "testing", Namespace: "test-ns"}))
+	})
+
+	It("returns the values file for a chart", func() {
+		values, err := repoManager.GetValuesFile(context.TODO(), makeOCIHelmRepository(server), &helm.ChartReference{Chart: "demo-profile", Version: "0.0.2+build.1"}, "values.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(values)).To(Equal("favoriteDrink: coffee\n"))
+	})
+
+	It("lists the charts named in the annotation when the registry has no catalog", func() {
+		registry.noCatalog = true
+
+		_, err := repoManager.ListCharts(context.TODO(), makeOCIHelmRepository(server), helm.Profiles)
+		Expect(err).To(MatchError(ContainSubstring("set the weave.works/profile-charts annotation")))
+
+		profiles, err := repoManager.ListCharts(context.TODO(), makeOCIHelmRepository(server, func(hr *sourcev1beta1.HelmRepository) {
+			hr.Annotations = map[string]string{helm.OCIChartsAnnotation: "demo-profile"}
+		}), helm.Profiles)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(profiles).To(HaveLen(1))
+	})
+
+	It("authenticates with the credentials of the Secret", func() {
+		registry.username, registry.password = "user", "pass"
+
+		_, err := repoManager.ListCharts(context.TODO(), makeOCIHelmRepository(server), helm.Profiles)
+		Expect(err).To(MatchError(ContainSubstring("fetching registry token: unexpected status 401")))
+
+		host := strings.TrimPrefix(server.URL, "https://")
+		repoManager.Client = makeTestClient(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry-credentials", Namespace: "test-ns"},
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				corev1.DockerConfigJsonKey: []byte(fmt.Sprintf(`{"auths":{%q:{"username":"user","password":"pass"}}}`, host)),
+			},
+		})
+
+		profiles, err := repoManager.ListCharts(context.TODO(), makeOCIHelmRepository(server, func(hr *sourcev1beta1.HelmRepository) {
+			hr.Spec.SecretRef = &fluxmeta.LocalObjectReference{Name: "registry-credentials"}
+		}), helm.Profiles)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(profiles).To(HaveLen(1))
+	})
+})
+
+func makeOCIHelmRepository(server *httptest.Server, opts ...func(*sourcev1beta1.HelmRepository)) *sourcev1beta1.HelmRepository {
+	hr := makeTestHelmRepository("")
+	hr.Spec.URL = strings.Replace(server.URL, "https://", "oci://", 1) + "/charts"
+	hr.Status = sourcev1beta1.HelmRepositoryStatus{}
+
+	for _, o := range opts {
+		o(hr)
+	}
+
+	return hr
+}
+
+// fakeRegistry serves a single chart pushed under several tags, behind token
+// authentication.
+type fakeRegistry struct {
+	repository string
+	tags       []string
+	manifest   []byte
+	blobs      map[string][]byte
+
+	realm              string
+	username, password string
+	noCatalog          bool
+}
+
+func newFakeRegistry(repository, chartPath string, tags ...string) *fakeRegistry {
+	archive, err := ioutil.ReadFile(chartPath)
+	Expect(err).NotTo(HaveOccurred())
+
+	ch, err := loader.LoadFile(chartPath)
+	Expect(err).NotTo(HaveOccurred())
+
+	ch.Metadata.Annotations = map[string]string{helm.ProfileAnnotation: "demo-profile"}
+
+	config, err := json.Marshal(ch.Metadata)
+	Expect(err).NotTo(HaveOccurred())
+
+	r := &fakeRegistry{repository: repository, tags: tags, blobs: map[string][]byte{}}
+
+	descriptor := func(mediaType string, data []byte) map[string]interface{} {
+		sum := sha256.Sum256(data)
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+		r.blobs[digest] = data
+
+		return map[string]interface{}{"mediaType": mediaType, "digest": digest, "size": len(data)}
+	}
+
+	r.manifest, err = json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"config":        descriptor("application/vnd.cncf.helm.config.v1+json", config),
+		"layers":        []interface{}{descriptor("application/vnd.cncf.helm.chart.content.v1.tar+gzip", archive)},
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	return r
+}
+
+func (r *fakeRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		if u, p, _ := req.BasicAuth(); u != r.username || p != r.password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"token":"` + req.URL.Query().Get("scope") + `"}`))
+
+		return
+	}
+
+	scope := "repository:" + r.repository + ":pull"
+	if req.URL.Path == "/v2/_catalog" {
+		scope = "registry:catalog:*"
+	}
+
+	if req.Header.Get("Authorization") != "Bearer "+scope {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm=%q,service="registry",scope=%q`, r.realm, scope))
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	prefix := "/v2/" + r.repository
+
+	switch {
+	case req.URL.Path == "/v2/_catalog" && !r.noCatalog:
+		_ = json.NewEncoder(w).Encode(map[string][]string{"repositories": {"other/chart", r.repository}})
+	case req.URL.Path == prefix+"/tags/list":
+		// Serve the tags in two pages.
+		if req.URL.Query().Get("last") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/tags/list?last=%s>; rel="next"`, prefix, r.tags[0]))
+			_ = json.NewEncoder(w).Encode(map[string][]string{"tags": r.tags[:1]})
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string][]string{"tags": r.tags[1:]})
+	case strings.HasPrefix(req.URL.Path, prefix+"/manifests/"):
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		_, _ = w.Write(r.manifest)
+	case strings.HasPrefix(req.URL.Path, prefix+"/blobs/"):
+		blob, ok := r.blobs[strings.TrimPrefix(req.URL.Path, prefix+"/blobs/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write(blob)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
@@ -0,0 +1,136 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
+	sourcev1beta1 "github.com/fluxcd/source-controller/api/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// UpgradePolicy tells which upgrades of an installed profile are reported.
+type UpgradePolicy string
+
+const (
+	// UpgradePolicyPatch only reports versions with the same major and minor
+	// versions as the installed one.
+	UpgradePolicyPatch UpgradePolicy = "patch"
+	// UpgradePolicyMinor reports versions with the same major version as the
+	// installed one.
+	UpgradePolicyMinor UpgradePolicy = "minor"
+	// UpgradePolicyMajor reports every newer version.
+	UpgradePolicyMajor UpgradePolicy = "major"
+)
+
+// UpgradePolicyAnnotation can be set on a HelmRelease to override the upgrade
+// policy used for it.
+const UpgradePolicyAnnotation = "weave.works/upgrade-policy"
+
+// ParseUpgradePolicy returns the UpgradePolicy named s. An empty s is
+// UpgradePolicyMajor.
+func ParseUpgradePolicy(s string) (UpgradePolicy, error) {
+	switch UpgradePolicy(s) {
+	case "":
+		return UpgradePolicyMajor, nil
+	case UpgradePolicyPatch, UpgradePolicyMinor, UpgradePolicyMajor:
+		return UpgradePolicy(s), nil
+	default:
+		return "", fmt.Errorf("invalid upgrade policy %q, must be one of %s, %s or %s", s, UpgradePolicyPatch, UpgradePolicyMinor, UpgradePolicyMajor)
+	}
+}
+
+// allows reports whether p allows upgrading from installed to v.
+func (p UpgradePolicy) allows(installed, v *semver.Version) bool {
+	switch p {
+	case UpgradePolicyPatch:
+		return v.Major() == installed.Major() && v.Minor() == installed.Minor()
+	case UpgradePolicyMinor:
+		return v.Major() == installed.Major()
+	default:
+		return true
+	}
+}
+
+// UpgradeType returns the smallest UpgradePolicy allowing the upgrade from
+// installed to version.
+func UpgradeType(installed, version string) (UpgradePolicy, error) {
+	from, err := semver.NewVersion(installed)
+	if err != nil {
+		return "", fmt.Errorf("invalid installed version %q: %w", installed, err)
+	}
+
+	to, err := semver.NewVersion(version)
+	if err != nil {
+		return "", fmt.Errorf("invalid version %q: %w", version, err)
+	}
+
+	for _, p := range []UpgradePolicy{UpgradePolicyPatch, UpgradePolicyMinor} {
+		if p.allows(from, to) {
+			return p, nil
+		}
+	}
+
+	return UpgradePolicyMajor, nil
+}
+
+// AvailableUpgrades returns the versions newer than installed that policy
+// allows, latest first. Pre-releases and versions that are not semantic
+// versions are left out.
+func AvailableUpgrades(installed string, versions []string, policy UpgradePolicy) ([]string, error) {
+	from, err := semver.NewVersion(installed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid installed version %q: %w", installed, err)
+	}
+
+	var upgrades semver.Collection
+
+	for _, v := range versions {
+		to, err := semver.NewVersion(v)
+		if err != nil || to.Prerelease() != "" {
+			continue
+		}
+
+		if to.GreaterThan(from) && policy.allows(from, to) {
+			upgrades = append(upgrades, to)
+		}
+	}
+
+	sort.Sort(sort.Reverse(upgrades))
+
+	result := make([]string, 0, len(upgrades))
+	for _, v := range upgrades {
+		result = append(result, v.Original())
+	}
+
+	return result, nil
+}
+
+// InstalledVersion returns the chart version of a HelmRelease: the last
+// applied revision, or spec.chart.spec.version when no revision was applied
+// yet.
+func InstalledVersion(hr *helmv2beta1.HelmRelease) string {
+	if hr.Status.LastAppliedRevision != "" {
+		return hr.Status.LastAppliedRevision
+	}
+
+	return hr.Spec.Chart.Spec.Version
+}
+
+// ReleaseHelmRepository returns the HelmRepository the chart of a HelmRelease
+// comes from. It returns false when the chart comes from another kind of
+// source.
+func ReleaseHelmRepository(hr *helmv2beta1.HelmRelease) (types.NamespacedName, bool) {
+	ref := hr.Spec.Chart.Spec.SourceRef
+	if ref.Kind != sourcev1beta1.HelmRepositoryKind {
+		return types.NamespacedName{}, false
+	}
+
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = hr.Namespace
+	}
+
+	return types.NamespacedName{Namespace: namespace, Name: ref.Name}, true
+}
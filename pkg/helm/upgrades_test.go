@@ -0,0 +1,73 @@
+package helm_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/weaveworks/weave-gitops/pkg/helm"
+)
+
+var _ = Describe("AvailableUpgrades", func() {
+	versions := []string{"1.2.3", "1.2.5", "1.2.4", "1.3.0", "1.4.0-rc.1", "2.0.0", "not-semver", "1.0.0"}
+
+	DescribeTable("lists the upgrades the policy allows, latest first",
+		func(policy helm.UpgradePolicy, expected []string) {
+			upgrades, err := helm.AvailableUpgrades("1.2.3", versions, policy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(upgrades).To(Equal(expected))
+		},
+		Entry("patch", helm.UpgradePolicyPatch, []string{"1.2.5", "1.2.4"}),
+		Entry("minor", helm.UpgradePolicyMinor, []string{"1.3.0", "1.2.5", "1.2.4"}),
+		Entry("major", helm.UpgradePolicyMajor, []string{"2.0.0", "1.3.0", "1.2.5", "1.2.4"}),
+	)
+
+	It("fails when the installed version is not a semantic version", func() {
+		_, err := helm.AvailableUpgrades("latest", versions, helm.UpgradePolicyMajor)
+		Expect(err).To(MatchError(ContainSubstring(`invalid installed version "latest"`)))
+	})
+})
+
+var _ = Describe("UpgradeType", func() {
+	DescribeTable("returns the smallest policy allowing the upgrade",
+		func(version string, expected helm.UpgradePolicy) {
+			Expect(helm.UpgradeType("1.2.3", version)).To(Equal(expected))
+		},
+		Entry("patch", "1.2.4", helm.UpgradePolicyPatch),
+		Entry("minor", "1.3.0", helm.UpgradePolicyMinor),
+		Entry("major", "2.0.0", helm.UpgradePolicyMajor),
+	)
+})
+
+var _ = Describe("ParseUpgradePolicy", func() {
+	It("defaults to major", func() {
+		Expect(helm.ParseUpgradePolicy("")).To(Equal(helm.UpgradePolicyMajor))
+	})
+
+	It("rejects unknown policies", func() {
+		_, err := helm.ParseUpgradePolicy("weekly")
+		Expect(err).To(MatchError(`invalid upgrade policy "weekly", must be one of patch, minor or major`))
+	})
+})
+
+var _ = Describe("InstalledVersion and ReleaseHelmRepository", func() {
+	It("prefers the applied revision and defaults the repository namespace", func() {
+		hr := helm.MakeHelmRelease("podinfo", "6.0.0", "prod", "apps", types.NamespacedName{Name: "profiles", Namespace: "flux-system"})
+		Expect(helm.InstalledVersion(hr)).To(Equal("6.0.0"))
+
+		hr.Status.LastAppliedRevision = "6.0.1"
+		Expect(helm.InstalledVersion(hr)).To(Equal("6.0.1"))
+
+		repo, ok := helm.ReleaseHelmRepository(hr)
+		Expect(ok).To(BeTrue())
+		Expect(repo).To(Equal(types.NamespacedName{Name: "profiles", Namespace: "flux-system"}))
+
+		hr.Spec.Chart.Spec.SourceRef.Namespace = ""
+		repo, _ = helm.ReleaseHelmRepository(hr)
+		Expect(repo).To(Equal(types.NamespacedName{Name: "profiles", Namespace: "apps"}))
+
+		hr.Spec.Chart.Spec.SourceRef.Kind = "GitRepository"
+		_, ok = helm.ReleaseHelmRepository(hr)
+		Expect(ok).To(BeFalse())
+	})
+})
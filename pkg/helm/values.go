@@ -0,0 +1,162 @@
+package helm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
+	"github.com/xeipuuv/gojsonschema"
+	"sigs.k8s.io/yaml"
+)
+
+// ValuesSchemaFileName is the name of the JSON schema that charts can ship
+// to describe their values.
+const ValuesSchemaFileName = "values.schema.json"
+
+// ValuesError describes a value that does not match the values schema of a
+// chart.
+type ValuesError struct {
+	// Path is the JSON path of the value, e.g. $.image.tag.
+	Path    string
+	Message string
+}
+
+// ValuesValidationError is returned when values do not match the values
+// schema of a chart.
+type ValuesValidationError struct {
+	Errors []ValuesError
+}
+
+func (e *ValuesValidationError) Error() string {
+	var sb strings.Builder
+
+	sb.WriteString("values do not match the schema:")
+
+	for _, v := range e.Errors {
+		sb.WriteString(fmt.Sprintf("\n- %s: %s", v.Path, v.Message))
+	}
+
+	return sb.String()
+}
+
+// ValidateValues checks values against the JSON schema of a chart. As Helm
+// does when installing it, the values are merged on top of defaults, the
+// values.yaml of the chart, so that the values required by the schema can be
+// left to the chart. It returns a *ValuesValidationError listing every invalid
+// value when they do not match. An empty schema accepts any values.
+func ValidateValues(schema, defaults []byte, values map[string]interface{}) error {
+	if len(schema) == 0 {
+		return nil
+	}
+
+	base := map[string]interface{}{}
+	if err := yaml.Unmarshal(defaults, &base); err != nil {
+		return fmt.Errorf("failed to parse the default values of the chart: %w", err)
+	}
+
+	// an empty values.yaml unmarshals to nil
+	if base == nil {
+		base = map[string]interface{}{}
+	}
+
+	values = MergeValues(base, values)
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewGoLoader(values))
+	if err != nil {
+		return fmt.Errorf("failed to validate values against the schema: %w", err)
+	}
+
+	if result.Valid() {
+		return nil
+	}
+
+	verr := &ValuesValidationError{}
+
+	for _, re := range result.Errors() {
+		verr.Errors = append(verr.Errors, ValuesError{
+			Path:    jsonPath(re.Field()),
+			Message: re.Description(),
+		})
+	}
+
+	return verr
+}
+
+var arrayIndex = regexp.MustCompile(`\.(\d+)(\.|$)`)
+
+// jsonPath turns the field names of gojsonschema, e.g. "(root)" or
+// "ingress.hosts.0.name", into JSON paths.
+func jsonPath(field string) string {
+	if field == gojsonschema.STRING_CONTEXT_ROOT {
+		return "$"
+	}
+
+	path := "." + field
+
+	// The separator after an index is shared with the next one, so indexes
+	// that follow each other need a second pass.
+	for arrayIndex.MatchString(path) {
+		path = arrayIndex.ReplaceAllString(path, "[$1]$2")
+	}
+
+	return "$" + path
+}
+
+// MergeValues returns the values of base overridden by those of override.
+// Maps are merged key by key, any other value of override replaces the one in
+// base. Neither base nor override are changed.
+func MergeValues(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base))
+
+	for k, v := range base {
+		merged[k] = v
+	}
+
+	for k, v := range override {
+		if vm, ok := v.(map[string]interface{}); ok {
+			if bm, ok := merged[k].(map[string]interface{}); ok {
+				merged[k] = MergeValues(bm, vm)
+				continue
+			}
+		}
+
+		merged[k] = v
+	}
+
+	return merged
+}
+
+// ParseValuesReference parses a reference to the values of a HelmRelease in
+// a ConfigMap or a Secret, written as <kind>/<name>[:<key>], e.g.
+// ConfigMap/podinfo-values or Secret/podinfo-values:prod.yaml. The key
+// defaults to values.yaml.
+func ParseValuesReference(s string) (helmv2beta1.ValuesReference, error) {
+	ref := helmv2beta1.ValuesReference{}
+
+	kind, name := "", s
+	if i := strings.Index(s, "/"); i >= 0 {
+		kind, name = s[:i], s[i+1:]
+	}
+
+	if i := strings.Index(name, ":"); i >= 0 {
+		name, ref.ValuesKey = name[:i], name[i+1:]
+	}
+
+	switch strings.ToLower(kind) {
+	case "configmap":
+		ref.Kind = "ConfigMap"
+	case "secret":
+		ref.Kind = "Secret"
+	default:
+		return ref, fmt.Errorf("invalid values reference %q, must be ConfigMap/<name>[:<key>] or Secret/<name>[:<key>]", s)
+	}
+
+	if name == "" {
+		return ref, fmt.Errorf("invalid values reference %q, the name is empty", s)
+	}
+
+	ref.Name = name
+
+	return ref, nil
+}
@@ -0,0 +1,119 @@
+package helm_test
+
+import (
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/weaveworks/weave-gitops/pkg/helm"
+)
+
+var _ = Describe("ValidateValues", func() {
+	schema := []byte(`{
+  "type": "object",
+  "required": ["image"],
+  "properties": {
+    "replicaCount": {"type": "integer", "minimum": 1},
+    "image": {
+      "type": "object",
+      "properties": {"tag": {"type": "string"}}
+    },
+    "ingress": {
+      "type": "object",
+      "properties": {
+        "hosts": {
+          "type": "array",
+          "items": {"type": "object", "properties": {"name": {"type": "string"}}}
+        }
+      }
+    }
+  }
+}`)
+
+	It("accepts values matching the schema", func() {
+		Expect(helm.ValidateValues(schema, nil, map[string]interface{}{
+			"replicaCount": 2,
+			"image":        map[string]interface{}{"tag": "v1"},
+		})).To(Succeed())
+	})
+
+	It("validates the values on top of the defaults of the chart", func() {
+		defaults := []byte("replicaCount: 1\nimage:\n  tag: v1\n")
+
+		Expect(helm.ValidateValues(schema, defaults, map[string]interface{}{"replicaCount": 2})).To(Succeed())
+
+		err := helm.ValidateValues(schema, defaults, map[string]interface{}{
+			"image": map[string]interface{}{"tag": 2},
+		})
+		Expect(err).To(MatchError(ContainSubstring("$.image.tag: Invalid type. Expected: string, given: integer")))
+	})
+
+	It("fails when the defaults of the chart cannot be parsed", func() {
+		err := helm.ValidateValues(schema, []byte("- not a map"), nil)
+		Expect(err).To(MatchError(ContainSubstring("failed to parse the default values of the chart")))
+	})
+
+	It("accepts any values when there is no schema", func() {
+		Expect(helm.ValidateValues(nil, nil, map[string]interface{}{"replicaCount": "two"})).To(Succeed())
+	})
+
+	It("reports every invalid value by its JSON path", func() {
+		err := helm.ValidateValues(schema, nil, map[string]interface{}{
+			"replicaCount": 0,
+			"ingress": map[string]interface{}{
+				"hosts": []interface{}{map[string]interface{}{"name": 1}},
+			},
+		})
+
+		var verr *helm.ValuesValidationError
+		Expect(err).To(BeAssignableToTypeOf(verr))
+		verr = err.(*helm.ValuesValidationError)
+		Expect(verr.Errors).To(ConsistOf(
+			helm.ValuesError{Path: "$", Message: "image is required"},
+			helm.ValuesError{Path: "$.replicaCount", Message: "Must be greater than or equal to 1"},
+			helm.ValuesError{Path: "$.ingress.hosts[0].name", Message: "Invalid type. Expected: string, given: integer"},
+		))
+		Expect(err.Error()).To(HavePrefix("values do not match the schema:\n- "))
+	})
+})
+
+var _ = Describe("MergeValues", func() {
+	It("merges maps and replaces the other values", func() {
+		base := map[string]interface{}{
+			"replicaCount": 1,
+			"image":        map[string]interface{}{"repository": "podinfo", "tag": "6.0.0"},
+			"hosts":        []interface{}{"a"},
+		}
+		override := map[string]interface{}{
+			"image": map[string]interface{}{"tag": "6.0.1"},
+			"hosts": []interface{}{"b"},
+		}
+
+		Expect(helm.MergeValues(base, override)).To(Equal(map[string]interface{}{
+			"replicaCount": 1,
+			"image":        map[string]interface{}{"repository": "podinfo", "tag": "6.0.1"},
+			"hosts":        []interface{}{"b"},
+		}))
+		Expect(base["image"]).To(HaveKeyWithValue("tag", "6.0.0"))
+	})
+})
+
+var _ = Describe("ParseValuesReference", func() {
+	DescribeTable("parses references to ConfigMaps and Secrets",
+		func(s string, expected helmv2beta1.ValuesReference) {
+			Expect(helm.ParseValuesReference(s)).To(Equal(expected))
+		},
+		Entry("ConfigMap", "ConfigMap/podinfo-values", helmv2beta1.ValuesReference{Kind: "ConfigMap", Name: "podinfo-values"}),
+		Entry("Secret with a key", "secret/podinfo-values:prod.yaml", helmv2beta1.ValuesReference{Kind: "Secret", Name: "podinfo-values", ValuesKey: "prod.yaml"}),
+	)
+
+	DescribeTable("rejects invalid references",
+		func(s, msg string) {
+			_, err := helm.ParseValuesReference(s)
+			Expect(err).To(MatchError(ContainSubstring(msg)))
+		},
+		Entry("no kind", "podinfo-values", "must be ConfigMap/<name>[:<key>] or Secret/<name>[:<key>]"),
+		Entry("unknown kind", "Pod/podinfo", "must be ConfigMap/<name>[:<key>] or Secret/<name>[:<key>]"),
+		Entry("no name", "ConfigMap/:values.yaml", "the name is empty"),
+	)
+})
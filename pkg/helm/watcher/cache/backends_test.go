@@ -0,0 +1,205 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
+)
+
+// backends returns a new Cache of every backend, so that they can be checked
+// to behave the same.
+func backends(t *testing.T) map[string]Cache {
+	fsCache, err := NewCache(t.TempDir())
+	assert.NoError(t, err)
+
+	return map[string]Cache{
+		BackendFilesystem: fsCache,
+		BackendMemory:     NewMemoryCache(),
+		BackendConfigMap:  NewConfigMapCache(newFakeClient(), "flux-system"),
+	}
+}
+
+func newFakeClient() client.Client {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	return fake.NewClientBuilder().WithScheme(scheme).Build()
+}
+
+// testProfile copies the fields of p the tests check, as the backends may
+// change the internal state of the messages they are given, which the other
+// tests compare.
+func testProfile(p *pb.Profile) *pb.Profile {
+	return &pb.Profile{Name: p.Name, AvailableVersions: p.AvailableVersions}
+}
+
+func TestBackendsRoundTrip(t *testing.T) {
+	for name, c := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			data := Data{
+				Profiles: []*pb.Profile{testProfile(profile1), testProfile(profile2)},
+				Values:   ValueMap{profile1.Name: values1, profile2.Name: values2},
+				Schemas:  ValueMap{profile1.Name: {"0.0.3": []byte(`{"type":"object"}`)}},
+			}
+
+			_, err := c.ListProfiles(ctx, helmNamespace, helmName)
+			assert.ErrorIs(t, err, os.ErrNotExist)
+
+			versions, err := c.ListAvailableVersionsForProfile(ctx, helmNamespace, helmName, profile1.Name)
+			assert.NoError(t, err)
+			assert.Empty(t, versions)
+
+			assert.NoError(t, c.Put(ctx, helmNamespace, helmName, data))
+
+			profiles, err := c.ListProfiles(ctx, helmNamespace, helmName)
+			assert.NoError(t, err)
+			assert.Len(t, profiles, 2)
+			assert.Equal(t, profile1.AvailableVersions, profiles[0].AvailableVersions)
+
+			versions, err = c.ListAvailableVersionsForProfile(ctx, helmNamespace, helmName, profile2.Name)
+			assert.NoError(t, err)
+			assert.Equal(t, profile2.AvailableVersions, versions)
+
+			_, err = c.ListAvailableVersionsForProfile(ctx, helmNamespace, helmName, "nope")
+			assert.EqualError(t, err, "profile with name nope not found in cached profiles")
+
+			values, err := c.GetProfileValues(ctx, helmNamespace, helmName, profile1.Name, "0.0.2")
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("values-2"), values)
+
+			_, err = c.GetProfileValues(ctx, helmNamespace, helmName, profile1.Name, "0.0.9")
+			assert.Error(t, err)
+
+			schema, err := c.GetProfileValuesSchema(ctx, helmNamespace, helmName, profile1.Name, "0.0.3")
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"type":"object"}`, string(schema))
+
+			schema, err = c.GetProfileValuesSchema(ctx, helmNamespace, helmName, profile1.Name, "0.0.2")
+			assert.NoError(t, err)
+			assert.Empty(t, schema)
+
+			cached, err := c.Get(ctx, helmNamespace, helmName)
+			assert.NoError(t, err)
+			assert.Len(t, cached.Profiles, 2)
+			assert.Equal(t, data.Values, cached.Values)
+			assert.Equal(t, data.Schemas, cached.Schemas)
+
+			// A rescan drops the profiles, versions and schemas the repository
+			// no longer has.
+			assert.NoError(t, c.Put(ctx, helmNamespace, helmName, Data{
+				Profiles: []*pb.Profile{testProfile(profile1)},
+				Values:   ValueMap{profile1.Name: {"0.0.3": []byte("values-3")}},
+			}))
+
+			_, err = c.GetProfileValues(ctx, helmNamespace, helmName, profile2.Name, "0.0.5")
+			assert.Error(t, err)
+
+			_, err = c.GetProfileValues(ctx, helmNamespace, helmName, profile1.Name, "0.0.2")
+			assert.Error(t, err)
+
+			schema, err = c.GetProfileValuesSchema(ctx, helmNamespace, helmName, profile1.Name, "0.0.3")
+			assert.NoError(t, err)
+			assert.Empty(t, schema)
+
+			assert.NoError(t, c.Delete(ctx, helmNamespace, helmName))
+
+			_, err = c.ListProfiles(ctx, helmNamespace, helmName)
+			assert.ErrorIs(t, err, os.ErrNotExist)
+
+			_, err = c.Get(ctx, helmNamespace, helmName)
+			assert.ErrorIs(t, err, os.ErrNotExist)
+		})
+	}
+}
+
+func TestBackendsConcurrentAccess(t *testing.T) {
+	for name, c := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			var wg sync.WaitGroup
+
+			for i := 0; i < 5; i++ {
+				repo := fmt.Sprintf("repo-%d", i)
+
+				wg.Add(2)
+
+				go func() {
+					defer wg.Done()
+
+					assert.NoError(t, c.Put(ctx, helmNamespace, repo, Data{
+						Profiles: []*pb.Profile{testProfile(profile1)},
+						Values:   ValueMap{profile1.Name: values1},
+					}))
+				}()
+
+				go func() {
+					defer wg.Done()
+
+					_, err := c.ListAvailableVersionsForProfile(ctx, helmNamespace, repo, profile1.Name)
+					if err != nil {
+						assert.EqualError(t, err, "profile with name test-profiles-1 not found in cached profiles")
+					}
+				}()
+			}
+
+			wg.Wait()
+
+			for i := 0; i < 5; i++ {
+				values, err := c.GetProfileValues(ctx, helmNamespace, fmt.Sprintf("repo-%d", i), profile1.Name, "0.0.3")
+				assert.NoError(t, err)
+				assert.Equal(t, []byte("values-3"), values)
+			}
+		})
+	}
+}
+
+func TestMemoryCacheReturnsCopies(t *testing.T) {
+	c := NewMemoryCache()
+	ctx := context.Background()
+
+	assert.NoError(t, c.Put(ctx, helmNamespace, helmName, Data{Profiles: []*pb.Profile{testProfile(profile1)}}))
+
+	profiles, err := c.ListProfiles(ctx, helmNamespace, helmName)
+	assert.NoError(t, err)
+
+	profiles[0].Name = "changed"
+
+	profiles, err = c.ListProfiles(ctx, helmNamespace, helmName)
+	assert.NoError(t, err)
+	assert.Equal(t, profile1.Name, profiles[0].Name)
+}
+
+func TestConfigMapCacheSharedBetweenReplicas(t *testing.T) {
+	kubeClient := newFakeClient()
+	ctx := context.Background()
+
+	assert.NoError(t, NewConfigMapCache(kubeClient, "flux-system").Put(ctx, helmNamespace, helmName, Data{
+		Profiles: []*pb.Profile{testProfile(profile1)},
+		Values:   ValueMap{profile1.Name: values1},
+	}))
+
+	values, err := NewConfigMapCache(kubeClient, "flux-system").GetProfileValues(ctx, helmNamespace, helmName, profile1.Name, "0.0.2")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("values-2"), values)
+
+	list := &corev1.ConfigMapList{}
+	assert.NoError(t, kubeClient.List(ctx, list, client.MatchingLabels{ConfigMapRepositoryLabel: hashName(helmNamespace, helmName)}))
+	assert.Len(t, list.Items, 2)
+}
+
+func TestNewBackendUnknown(t *testing.T) {
+	_, err := NewBackend("redis", "", nil, "")
+	assert.EqualError(t, err, `unknown profile cache backend "redis", must be one of filesystem, memory, configmap`)
+}
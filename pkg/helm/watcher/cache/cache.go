@@ -6,11 +6,13 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/ghodss/yaml"
 	"github.com/go-logr/logr"
 	"github.com/gofrs/flock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
 )
@@ -22,6 +24,7 @@ const (
 	lockFilename    = "cache.lock"
 	profileFilename = "profiles.yaml"
 	valuesFilename  = "values.yaml"
+	schemaFilename  = "values.schema.json"
 	lockTimeout     = 1 * time.Minute
 )
 
@@ -32,26 +35,72 @@ type profileVersion = string
 // ValueMap contains easy access for a profile name and version based values file.
 type ValueMap map[profileName]map[profileVersion][]byte
 
+// Set stores data for a profile name and version.
+func (m ValueMap) Set(name, version string, data []byte) {
+	if _, ok := m[name]; !ok {
+		m[name] = make(map[profileVersion][]byte)
+	}
+
+	m[name][version] = data
+}
+
 // Cache defines an interface to work with the profile data cacher.
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
 //counterfeiter:generate . Cache
 type Cache interface {
+	// Put replaces the data of a HelmRepository. Values of profiles and versions missing from value are removed.
 	Put(ctx context.Context, helmRepoNamespace, helmRepoName string, value Data) error
+	// Get returns all the data of a HelmRepository. The error wraps os.ErrNotExist when it has not been cached.
+	Get(ctx context.Context, helmRepoNamespace, helmRepoName string) (Data, error)
 	Delete(ctx context.Context, helmRepoNamespace, helmRepoName string) error
 	// ListProfiles specifically retrieve profiles data only to avoid traversing the values structure for no reason.
 	ListProfiles(ctx context.Context, helmRepoNamespace, helmRepoName string) ([]*pb.Profile, error)
 	// GetProfileValues will try and find a specific values file for the given profileName and profileVersion. Returns an
 	// error if said version is not found.
 	GetProfileValues(ctx context.Context, helmRepoNamespace, helmRepoName, profileName, profileVersion string) ([]byte, error)
+	// GetProfileValuesSchema returns the values schema of the given profileName and profileVersion. It returns nil
+	// when the version has no schema, and an error if said version is not found.
+	GetProfileValuesSchema(ctx context.Context, helmRepoNamespace, helmRepoName, profileName, profileVersion string) ([]byte, error)
 	// ListAvailableVersionsForProfile returns all stored available versions for a profile.
 	ListAvailableVersionsForProfile(ctx context.Context, helmRepoNamespace, helmRepoName, profileName string) ([]string, error)
 }
 
 // Data is explicit data for a specific profile including values.
-// Saved as `profiles.yaml` and `profileName/version/values.yaml`.
+// Saved as `profiles.yaml`, `profileName/version/values.yaml` and
+// `profileName/version/values.schema.json`.
 type Data struct {
 	Profiles []*pb.Profile `yaml:"profiles"`
 	Values   ValueMap
+	// Schemas holds the values schemas of the versions that have one.
+	Schemas ValueMap
+}
+
+const (
+	// BackendFilesystem keeps profile data in files, see ProfileCache.
+	BackendFilesystem = "filesystem"
+	// BackendMemory keeps profile data in memory, see MemoryCache.
+	BackendMemory = "memory"
+	// BackendConfigMap keeps profile data in ConfigMaps shared by every
+	// replica, see ConfigMapCache.
+	BackendConfigMap = "configmap"
+)
+
+// Backends lists the supported cache backends.
+var Backends = []string{BackendFilesystem, BackendMemory, BackendConfigMap}
+
+// NewBackend returns the Cache of the given backend. cacheLocation is only used
+// by the filesystem backend, kubeClient and namespace by the configmap one.
+func NewBackend(backend, cacheLocation string, kubeClient client.Client, namespace string) (Cache, error) {
+	switch backend {
+	case BackendFilesystem, "":
+		return NewCache(cacheLocation)
+	case BackendMemory:
+		return NewMemoryCache(), nil
+	case BackendConfigMap:
+		return NewConfigMapCache(kubeClient, namespace), nil
+	default:
+		return nil, fmt.Errorf("unknown profile cache backend %q, must be one of %s", backend, strings.Join(Backends, ", "))
+	}
 }
 
 // ProfileCache is used to cache profiles data from scanner helm repositories.
@@ -72,7 +121,7 @@ func NewCache(cacheLocation string) (*ProfileCache, error) {
 	}, nil
 }
 
-// Put adds a new entry or updates an existing entry in the cache for the helmRepository.
+// Put adds a new entry or replaces an existing entry in the cache for the helmRepository.
 func (c *ProfileCache) Put(ctx context.Context, helmRepoNamespace, helmRepoName string, value Data) error {
 	logger := logr.FromContextOrDiscard(ctx)
 	logger.Info("starting put operation")
@@ -107,9 +156,29 @@ func (c *ProfileCache) Put(ctx context.Context, helmRepoNamespace, helmRepoName
 				if err := os.WriteFile(filepath.Join(versionFolder, valuesFilename), values, 0700); err != nil {
 					return fmt.Errorf("failed to write out values for version %s: %w", version, err)
 				}
+
+				schemaFile := filepath.Join(versionFolder, schemaFilename)
+
+				schema, ok := value.Schemas[profName][version]
+				if !ok {
+					// A previous version of the chart may have had a schema.
+					if err := os.Remove(schemaFile); err != nil && !os.IsNotExist(err) {
+						return fmt.Errorf("failed to remove values schema for version %s: %w", version, err)
+					}
+
+					continue
+				}
+
+				if err := os.WriteFile(schemaFile, schema, 0700); err != nil {
+					return fmt.Errorf("failed to write out values schema for version %s: %w", version, err)
+				}
 			}
 		}
 
+		if err := pruneValues(cacheLocation, value.Values); err != nil {
+			return err
+		}
+
 		logger.Info("finished put operation")
 
 		return nil
@@ -118,6 +187,95 @@ func (c *ProfileCache) Put(ctx context.Context, helmRepoNamespace, helmRepoName
 	return c.tryWithLock(ctx, putOperation)
 }
 
+// pruneValues removes the folders of the profiles and versions that are not in values.
+func pruneValues(cacheLocation string, values ValueMap) error {
+	profileDirs, err := os.ReadDir(cacheLocation)
+	if err != nil {
+		return fmt.Errorf("failed to read cache location: %w", err)
+	}
+
+	for _, profileDir := range profileDirs {
+		if !profileDir.IsDir() {
+			continue
+		}
+
+		profileFolder := filepath.Join(cacheLocation, profileDir.Name())
+
+		versions, ok := values[profileDir.Name()]
+		if !ok {
+			if err := os.RemoveAll(profileFolder); err != nil {
+				return fmt.Errorf("failed to remove values for profile %s: %w", profileDir.Name(), err)
+			}
+
+			continue
+		}
+
+		versionDirs, err := os.ReadDir(profileFolder)
+		if err != nil {
+			return fmt.Errorf("failed to read values folder for profile %s: %w", profileDir.Name(), err)
+		}
+
+		for _, versionDir := range versionDirs {
+			if _, ok := versions[versionDir.Name()]; ok {
+				continue
+			}
+
+			if err := os.RemoveAll(filepath.Join(profileFolder, versionDir.Name())); err != nil {
+				return fmt.Errorf("failed to remove values for version %s of profile %s: %w", versionDir.Name(), profileDir.Name(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Get reads the profiles data of a HelmRepository along with the values and values schemas of every version.
+func (c *ProfileCache) Get(ctx context.Context, helmRepoNamespace, helmRepoName string) (Data, error) {
+	result := Data{Values: make(ValueMap), Schemas: make(ValueMap)}
+
+	getOperation := func() error {
+		if err := c.getProfilesFromFile(helmRepoNamespace, helmRepoName, &result.Profiles); err != nil {
+			return fmt.Errorf("failed to read profiles data for helm repo (%s/%s): %w", helmRepoNamespace, helmRepoName, err)
+		}
+
+		for _, p := range result.Profiles {
+			for _, version := range p.AvailableVersions {
+				versionFolder := filepath.Join(c.cacheLocation, helmRepoNamespace, helmRepoName, p.Name, version)
+
+				values, err := os.ReadFile(filepath.Join(versionFolder, valuesFilename))
+				if os.IsNotExist(err) {
+					continue
+				}
+
+				if err != nil {
+					return fmt.Errorf("failed to read values file: %w", err)
+				}
+
+				result.Values.Set(p.Name, version, values)
+
+				schema, err := os.ReadFile(filepath.Join(versionFolder, schemaFilename))
+				if os.IsNotExist(err) {
+					continue
+				}
+
+				if err != nil {
+					return fmt.Errorf("failed to read values schema file: %w", err)
+				}
+
+				result.Schemas.Set(p.Name, version, schema)
+			}
+		}
+
+		return nil
+	}
+
+	if err := c.tryWithLock(ctx, getOperation); err != nil {
+		return Data{}, err
+	}
+
+	return result, nil
+}
+
 // Delete clears the cache folder for a specific HelmRepository. It will only clear the innermost
 // folder so others in the same namespace may retain their values.
 func (c *ProfileCache) Delete(ctx context.Context, helmRepoNamespace, helmRepoName string) error {
@@ -213,6 +371,41 @@ func (c *ProfileCache) GetProfileValues(ctx context.Context, helmRepoNamespace,
 	return result, nil
 }
 
+// GetProfileValuesSchema returns the content of the cached values schema file. It returns nil if the version has
+// values but no schema, and errors if the version is not cached.
+func (c *ProfileCache) GetProfileValuesSchema(ctx context.Context, helmRepoNamespace, helmRepoName, profileName, profileVersion string) ([]byte, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+	logger.Info("retrieving cached profile values schema")
+
+	var result []byte
+
+	getSchemaOperation := func() error {
+		versionFolder := filepath.Join(c.cacheLocation, helmRepoNamespace, helmRepoName, profileName, profileVersion)
+
+		schema, err := os.ReadFile(filepath.Join(versionFolder, schemaFilename))
+		if err == nil {
+			result = schema
+			return nil
+		}
+
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read values schema file: %w", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(versionFolder, valuesFilename)); err != nil {
+			return fmt.Errorf("failed to read values file: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.tryWithLock(ctx, getSchemaOperation); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // getProfilesFromFile returns profiles loaded from a file.
 func (c *ProfileCache) getProfilesFromFile(helmRepoNamespace, helmRepoName string, profiles *[]*pb.Profile) error {
 	content, err := os.ReadFile(filepath.Join(c.cacheLocation, helmRepoNamespace, helmRepoName, profileFilename))
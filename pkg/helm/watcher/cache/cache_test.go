@@ -114,6 +114,39 @@ func TestGetProfileValuesNonexistent(t *testing.T) {
 	assert.EqualError(t, err, fmt.Sprintf("failed to read values file: open %s/test-namespace/test-name/test-profiles-1/999/values.yaml: no such file or directory", dir))
 }
 
+func TestCacheGetProfileValuesSchema(t *testing.T) {
+	profileCache, _ := setupCache(t)
+	data := Data{
+		Profiles: []*pb.Profile{profile1, profile2},
+		Values: ValueMap{
+			profile1.Name: values1,
+		},
+		Schemas: ValueMap{
+			profile1.Name: {"0.0.2": []byte(`{"type":"object"}`)},
+		},
+	}
+	assert.NoError(t, profileCache.Put(context.Background(), helmNamespace, helmName, data), "put call from cache should have worked")
+
+	schema, err := profileCache.GetProfileValuesSchema(context.Background(), helmNamespace, helmName, profile1.Name, "0.0.2")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"type":"object"}`), schema)
+
+	schema, err = profileCache.GetProfileValuesSchema(context.Background(), helmNamespace, helmName, profile1.Name, "0.0.3")
+	assert.NoError(t, err)
+	assert.Nil(t, schema)
+
+	_, err = profileCache.GetProfileValuesSchema(context.Background(), helmNamespace, helmName, profile1.Name, "999")
+	assert.Error(t, err)
+
+	// A new version of the chart without a schema removes the cached one.
+	data.Schemas = nil
+	assert.NoError(t, profileCache.Put(context.Background(), helmNamespace, helmName, data), "put call from cache should have worked")
+
+	schema, err = profileCache.GetProfileValuesSchema(context.Background(), helmNamespace, helmName, profile1.Name, "0.0.2")
+	assert.NoError(t, err)
+	assert.Nil(t, schema)
+}
+
 // Note that error case is missing. It's actually difficult to make RemoveAll fail. It
 // could fail if we mess up the permission on a file, but that would leave us with a file we can't
 // clear up and neither modify.
@@ -23,6 +23,21 @@ type FakeCache struct {
 	deleteReturnsOnCall map[int]struct {
 		result1 error
 	}
+	GetStub        func(context.Context, string, string) (cache.Data, error)
+	getMutex       sync.RWMutex
+	getArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	getReturns struct {
+		result1 cache.Data
+		result2 error
+	}
+	getReturnsOnCall map[int]struct {
+		result1 cache.Data
+		result2 error
+	}
 	GetProfileValuesStub        func(context.Context, string, string, string, string) ([]byte, error)
 	getProfileValuesMutex       sync.RWMutex
 	getProfileValuesArgsForCall []struct {
@@ -40,6 +55,23 @@ type FakeCache struct {
 		result1 []byte
 		result2 error
 	}
+	GetProfileValuesSchemaStub        func(context.Context, string, string, string, string) ([]byte, error)
+	getProfileValuesSchemaMutex       sync.RWMutex
+	getProfileValuesSchemaArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 string
+	}
+	getProfileValuesSchemaReturns struct {
+		result1 []byte
+		result2 error
+	}
+	getProfileValuesSchemaReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
 	ListAvailableVersionsForProfileStub        func(context.Context, string, string, string) ([]string, error)
 	listAvailableVersionsForProfileMutex       sync.RWMutex
 	listAvailableVersionsForProfileArgsForCall []struct {
@@ -152,6 +184,72 @@ func (fake *FakeCache) DeleteReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeCache) Get(arg1 context.Context, arg2 string, arg3 string) (cache.Data, error) {
+	fake.getMutex.Lock()
+	ret, specificReturn := fake.getReturnsOnCall[len(fake.getArgsForCall)]
+	fake.getArgsForCall = append(fake.getArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.GetStub
+	fakeReturns := fake.getReturns
+	fake.recordInvocation("Get", []interface{}{arg1, arg2, arg3})
+	fake.getMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCache) GetCallCount() int {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return len(fake.getArgsForCall)
+}
+
+func (fake *FakeCache) GetCalls(stub func(context.Context, string, string) (cache.Data, error)) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = stub
+}
+
+func (fake *FakeCache) GetArgsForCall(i int) (context.Context, string, string) {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	argsForCall := fake.getArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeCache) GetReturns(result1 cache.Data, result2 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	fake.getReturns = struct {
+		result1 cache.Data
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCache) GetReturnsOnCall(i int, result1 cache.Data, result2 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	if fake.getReturnsOnCall == nil {
+		fake.getReturnsOnCall = make(map[int]struct {
+			result1 cache.Data
+			result2 error
+		})
+	}
+	fake.getReturnsOnCall[i] = struct {
+		result1 cache.Data
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeCache) GetProfileValues(arg1 context.Context, arg2 string, arg3 string, arg4 string, arg5 string) ([]byte, error) {
 	fake.getProfileValuesMutex.Lock()
 	ret, specificReturn := fake.getProfileValuesReturnsOnCall[len(fake.getProfileValuesArgsForCall)]
@@ -220,6 +318,74 @@ func (fake *FakeCache) GetProfileValuesReturnsOnCall(i int, result1 []byte, resu
 	}{result1, result2}
 }
 
+func (fake *FakeCache) GetProfileValuesSchema(arg1 context.Context, arg2 string, arg3 string, arg4 string, arg5 string) ([]byte, error) {
+	fake.getProfileValuesSchemaMutex.Lock()
+	ret, specificReturn := fake.getProfileValuesSchemaReturnsOnCall[len(fake.getProfileValuesSchemaArgsForCall)]
+	fake.getProfileValuesSchemaArgsForCall = append(fake.getProfileValuesSchemaArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 string
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.GetProfileValuesSchemaStub
+	fakeReturns := fake.getProfileValuesSchemaReturns
+	fake.recordInvocation("GetProfileValuesSchema", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.getProfileValuesSchemaMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCache) GetProfileValuesSchemaCallCount() int {
+	fake.getProfileValuesSchemaMutex.RLock()
+	defer fake.getProfileValuesSchemaMutex.RUnlock()
+	return len(fake.getProfileValuesSchemaArgsForCall)
+}
+
+func (fake *FakeCache) GetProfileValuesSchemaCalls(stub func(context.Context, string, string, string, string) ([]byte, error)) {
+	fake.getProfileValuesSchemaMutex.Lock()
+	defer fake.getProfileValuesSchemaMutex.Unlock()
+	fake.GetProfileValuesSchemaStub = stub
+}
+
+func (fake *FakeCache) GetProfileValuesSchemaArgsForCall(i int) (context.Context, string, string, string, string) {
+	fake.getProfileValuesSchemaMutex.RLock()
+	defer fake.getProfileValuesSchemaMutex.RUnlock()
+	argsForCall := fake.getProfileValuesSchemaArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeCache) GetProfileValuesSchemaReturns(result1 []byte, result2 error) {
+	fake.getProfileValuesSchemaMutex.Lock()
+	defer fake.getProfileValuesSchemaMutex.Unlock()
+	fake.GetProfileValuesSchemaStub = nil
+	fake.getProfileValuesSchemaReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCache) GetProfileValuesSchemaReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.getProfileValuesSchemaMutex.Lock()
+	defer fake.getProfileValuesSchemaMutex.Unlock()
+	fake.GetProfileValuesSchemaStub = nil
+	if fake.getProfileValuesSchemaReturnsOnCall == nil {
+		fake.getProfileValuesSchemaReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.getProfileValuesSchemaReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeCache) ListAvailableVersionsForProfile(arg1 context.Context, arg2 string, arg3 string, arg4 string) ([]string, error) {
 	fake.listAvailableVersionsForProfileMutex.Lock()
 	ret, specificReturn := fake.listAvailableVersionsForProfileReturnsOnCall[len(fake.listAvailableVersionsForProfileArgsForCall)]
@@ -422,8 +588,12 @@ func (fake *FakeCache) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
 	fake.getProfileValuesMutex.RLock()
 	defer fake.getProfileValuesMutex.RUnlock()
+	fake.getProfileValuesSchemaMutex.RLock()
+	defer fake.getProfileValuesSchemaMutex.RUnlock()
 	fake.listAvailableVersionsForProfileMutex.RLock()
 	defer fake.listAvailableVersionsForProfileMutex.RUnlock()
 	fake.listProfilesMutex.RLock()
@@ -0,0 +1,343 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
+)
+
+const (
+	// ConfigMapRepositoryLabel is set on the ConfigMaps of a ConfigMapCache to
+	// a hash of the HelmRepository they hold the data of.
+	ConfigMapRepositoryLabel = "weave.works/profiles-cache-repository"
+	// ConfigMapKindLabel tells the ConfigMap listing the profiles of a
+	// HelmRepository from those holding the values of a profile.
+	ConfigMapKindLabel = "weave.works/profiles-cache"
+	// ConfigMapSourceAnnotation names the HelmRepository, or profile, whose
+	// data a ConfigMap holds.
+	ConfigMapSourceAnnotation = "weave.works/profiles-cache-source"
+
+	configMapPrefix    = "profiles-cache-"
+	profilesKind       = "profiles"
+	valuesKind         = "values"
+	profilesDataKey    = "profiles.json.gz"
+	valuesDataKey      = "values.json.gz"
+	managedByLabel     = "app.kubernetes.io/managed-by"
+	managedByLabelName = "weave-gitops"
+)
+
+// ConfigMapCache keeps profile data in ConfigMaps, so that several replicas of
+// the server share it. Each HelmRepository has a ConfigMap listing its
+// profiles, and one ConfigMap per profile holding the values and values
+// schemas of every version, compressed. Writes use optimistic concurrency, the
+// last replica to scan a HelmRepository wins. A profile whose compressed
+// values exceed the 1MiB limit of ConfigMaps cannot be cached.
+type ConfigMapCache struct {
+	client    client.Client
+	namespace string
+}
+
+var _ Cache = &ConfigMapCache{}
+
+// NewConfigMapCache returns a ConfigMapCache keeping its ConfigMaps in
+// namespace.
+func NewConfigMapCache(kubeClient client.Client, namespace string) *ConfigMapCache {
+	return &ConfigMapCache{client: kubeClient, namespace: namespace}
+}
+
+// cachedVersion is the data of a profile version stored in ConfigMaps.
+type cachedVersion struct {
+	Values []byte `json:"values"`
+	Schema []byte `json:"schema,omitempty"`
+}
+
+// Put writes the data of a HelmRepository, then removes the ConfigMaps of the
+// profiles it no longer has.
+func (c *ConfigMapCache) Put(ctx context.Context, helmRepoNamespace, helmRepoName string, value Data) error {
+	logger := logr.FromContextOrDiscard(ctx)
+	logger.Info("starting put operation")
+
+	repoHash := hashName(helmRepoNamespace, helmRepoName)
+	keep := map[string]bool{}
+
+	for _, p := range value.Profiles {
+		versions := map[string]cachedVersion{}
+		for version, values := range value.Values[p.Name] {
+			versions[version] = cachedVersion{Values: values, Schema: value.Schemas[p.Name][version]}
+		}
+
+		data, err := compressJSON(versions)
+		if err != nil {
+			return fmt.Errorf("failed to encode values of profile %s: %w", p.Name, err)
+		}
+
+		name := c.valuesConfigMapName(helmRepoNamespace, helmRepoName, p.Name)
+		keep[name] = true
+
+		if err := c.apply(ctx, c.configMap(name, repoHash, valuesKind, helmRepoNamespace+"/"+helmRepoName+"/"+p.Name, valuesDataKey, data)); err != nil {
+			return fmt.Errorf("failed to write values of profile %s: %w", p.Name, err)
+		}
+	}
+
+	data, err := compressJSON(value.Profiles)
+	if err != nil {
+		return fmt.Errorf("failed to encode profile data: %w", err)
+	}
+
+	name := configMapPrefix + repoHash
+	if err := c.apply(ctx, c.configMap(name, repoHash, profilesKind, helmRepoNamespace+"/"+helmRepoName, profilesDataKey, data)); err != nil {
+		return fmt.Errorf("failed to write profile data: %w", err)
+	}
+
+	list := &corev1.ConfigMapList{}
+	if err := c.client.List(ctx, list, client.InNamespace(c.namespace), client.MatchingLabels{
+		ConfigMapRepositoryLabel: repoHash,
+		ConfigMapKindLabel:       valuesKind,
+	}); err != nil {
+		return fmt.Errorf("failed to list cached values: %w", err)
+	}
+
+	for i := range list.Items {
+		if keep[list.Items[i].Name] {
+			continue
+		}
+
+		if err := c.client.Delete(ctx, &list.Items[i]); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to remove cached values of %s: %w", list.Items[i].Annotations[ConfigMapSourceAnnotation], err)
+		}
+	}
+
+	logger.Info("finished put operation")
+
+	return nil
+}
+
+// Delete removes the ConfigMaps of a HelmRepository.
+func (c *ConfigMapCache) Delete(ctx context.Context, helmRepoNamespace, helmRepoName string) error {
+	if err := c.client.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace(c.namespace), client.MatchingLabels{
+		ConfigMapRepositoryLabel: hashName(helmRepoNamespace, helmRepoName),
+	}); err != nil {
+		return fmt.Errorf("failed to clean up cache for helm repo %s/%s: %w", helmRepoNamespace, helmRepoName, err)
+	}
+
+	return nil
+}
+
+// ListProfiles returns the profiles of a HelmRepository. The error wraps
+// os.ErrNotExist when the HelmRepository has not been cached.
+func (c *ConfigMapCache) ListProfiles(ctx context.Context, helmRepoNamespace, helmRepoName string) ([]*pb.Profile, error) {
+	var profiles []*pb.Profile
+
+	if err := c.read(ctx, configMapPrefix+hashName(helmRepoNamespace, helmRepoName), profilesDataKey, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to read profiles data for helm repo (%s/%s): %w", helmRepoNamespace, helmRepoName, err)
+	}
+
+	return profiles, nil
+}
+
+// Get returns the profiles of a HelmRepository along with the values and
+// values schemas of every version.
+func (c *ConfigMapCache) Get(ctx context.Context, helmRepoNamespace, helmRepoName string) (Data, error) {
+	profiles, err := c.ListProfiles(ctx, helmRepoNamespace, helmRepoName)
+	if err != nil {
+		return Data{}, err
+	}
+
+	result := Data{Profiles: profiles, Values: make(ValueMap), Schemas: make(ValueMap)}
+
+	for _, p := range profiles {
+		var versions map[string]cachedVersion
+
+		if err := c.read(ctx, c.valuesConfigMapName(helmRepoNamespace, helmRepoName, p.Name), valuesDataKey, &versions); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return Data{}, fmt.Errorf("failed to read values of profile %s: %w", p.Name, err)
+		}
+
+		for version, v := range versions {
+			result.Values.Set(p.Name, version, v.Values)
+
+			if v.Schema != nil {
+				result.Schemas.Set(p.Name, version, v.Schema)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ListAvailableVersionsForProfile returns all stored available versions for a profile.
+func (c *ConfigMapCache) ListAvailableVersionsForProfile(ctx context.Context, helmRepoNamespace, helmRepoName, profileName string) ([]string, error) {
+	var profiles []*pb.Profile
+
+	if err := c.read(ctx, configMapPrefix+hashName(helmRepoNamespace, helmRepoName), profilesDataKey, &profiles); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read profiles data for helm repo: %w", err)
+	}
+
+	return availableVersions(profiles, profileName)
+}
+
+// GetProfileValues returns the values file of a profile version.
+func (c *ConfigMapCache) GetProfileValues(ctx context.Context, helmRepoNamespace, helmRepoName, profileName, profileVersion string) ([]byte, error) {
+	v, err := c.getVersion(ctx, helmRepoNamespace, helmRepoName, profileName, profileVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Values, nil
+}
+
+// GetProfileValuesSchema returns the values schema of a profile version, or
+// nil if the version has values but no schema.
+func (c *ConfigMapCache) GetProfileValuesSchema(ctx context.Context, helmRepoNamespace, helmRepoName, profileName, profileVersion string) ([]byte, error) {
+	v, err := c.getVersion(ctx, helmRepoNamespace, helmRepoName, profileName, profileVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Schema, nil
+}
+
+func (c *ConfigMapCache) getVersion(ctx context.Context, helmRepoNamespace, helmRepoName, profileName, profileVersion string) (cachedVersion, error) {
+	var versions map[string]cachedVersion
+
+	if err := c.read(ctx, c.valuesConfigMapName(helmRepoNamespace, helmRepoName, profileName), valuesDataKey, &versions); err != nil {
+		return cachedVersion{}, fmt.Errorf("failed to read values file: %w", err)
+	}
+
+	v, ok := versions[profileVersion]
+	if !ok {
+		return cachedVersion{}, fmt.Errorf("failed to read values file: no values for %s version %s: %w", profileName, profileVersion, os.ErrNotExist)
+	}
+
+	return v, nil
+}
+
+// read decodes the data under key of a ConfigMap into out. The error wraps
+// os.ErrNotExist when the ConfigMap does not exist.
+func (c *ConfigMapCache) read(ctx context.Context, name, key string, out interface{}) error {
+	cm := &corev1.ConfigMap{}
+	if err := c.client.Get(ctx, client.ObjectKey{Namespace: c.namespace, Name: name}, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("ConfigMap %s/%s: %w", c.namespace, name, os.ErrNotExist)
+		}
+
+		return err
+	}
+
+	return decompressJSON(cm.BinaryData[key], out)
+}
+
+// apply creates or replaces a ConfigMap, retrying when another replica
+// changed it in the meantime.
+func (c *ConfigMapCache) apply(ctx context.Context, cm *corev1.ConfigMap) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing := &corev1.ConfigMap{}
+
+		err := c.client.Get(ctx, client.ObjectKeyFromObject(cm), existing)
+		if apierrors.IsNotFound(err) {
+			err = c.client.Create(ctx, cm.DeepCopy())
+			if apierrors.IsAlreadyExists(err) {
+				return apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, cm.Name, err)
+			}
+
+			return err
+		}
+
+		if err != nil {
+			return err
+		}
+
+		existing.Labels = cm.Labels
+		existing.Annotations = cm.Annotations
+		existing.Data = nil
+		existing.BinaryData = cm.BinaryData
+
+		return c.client.Update(ctx, existing)
+	})
+}
+
+func (c *ConfigMapCache) configMap(name, repoHash, kind, source, key string, data []byte) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: c.namespace,
+			Labels: map[string]string{
+				managedByLabel:           managedByLabelName,
+				ConfigMapRepositoryLabel: repoHash,
+				ConfigMapKindLabel:       kind,
+			},
+			Annotations: map[string]string{ConfigMapSourceAnnotation: source},
+		},
+		BinaryData: map[string][]byte{key: data},
+	}
+}
+
+func (c *ConfigMapCache) valuesConfigMapName(helmRepoNamespace, helmRepoName, profileName string) string {
+	return configMapPrefix + hashName(helmRepoNamespace, helmRepoName, profileName)
+}
+
+// hashName returns a short hash of parts that can be used in object names and
+// label values, whatever the length of parts.
+func hashName(parts ...string) string {
+	h := sha256.New()
+
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:20]
+}
+
+func compressJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(v); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decompressJSON(data []byte, out interface{}) error {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decompress cached data: %w", err)
+	}
+
+	raw, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return fmt.Errorf("failed to decompress cached data: %w", err)
+	}
+
+	return json.Unmarshal(raw, out)
+}
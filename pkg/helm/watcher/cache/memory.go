@@ -0,0 +1,214 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
+)
+
+// MemoryCache keeps profile data in memory. Each HelmRepository has its own
+// lock, so scanning one repository does not hold up reads of the others.
+type MemoryCache struct {
+	mu    sync.Mutex
+	repos map[string]*memoryEntry
+}
+
+type memoryEntry struct {
+	mu   sync.RWMutex
+	data Data
+}
+
+var _ Cache = &MemoryCache{}
+
+// NewMemoryCache returns an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{repos: map[string]*memoryEntry{}}
+}
+
+// Put replaces the data of the helmRepository.
+func (c *MemoryCache) Put(ctx context.Context, helmRepoNamespace, helmRepoName string, value Data) error {
+	key := helmRepoNamespace + "/" + helmRepoName
+
+	c.mu.Lock()
+	entry, ok := c.repos[key]
+
+	if !ok {
+		entry = &memoryEntry{}
+		c.repos[key] = entry
+	}
+	c.mu.Unlock()
+
+	data := Data{
+		Profiles: cloneProfiles(value.Profiles),
+		Values:   cloneValueMap(value.Values),
+		Schemas:  cloneValueMap(value.Schemas),
+	}
+
+	entry.mu.Lock()
+	entry.data = data
+	entry.mu.Unlock()
+
+	return nil
+}
+
+// Get returns a copy of the data of a HelmRepository.
+func (c *MemoryCache) Get(ctx context.Context, helmRepoNamespace, helmRepoName string) (Data, error) {
+	var result Data
+
+	err := c.read(helmRepoNamespace, helmRepoName, func(data *Data) error {
+		result = Data{
+			Profiles: cloneProfiles(data.Profiles),
+			Values:   cloneValueMap(data.Values),
+			Schemas:  cloneValueMap(data.Schemas),
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Data{}, fmt.Errorf("failed to read profiles data for helm repo (%s/%s): %w", helmRepoNamespace, helmRepoName, err)
+	}
+
+	return result, nil
+}
+
+// Delete forgets the data of a HelmRepository.
+func (c *MemoryCache) Delete(ctx context.Context, helmRepoNamespace, helmRepoName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.repos, helmRepoNamespace+"/"+helmRepoName)
+
+	return nil
+}
+
+// ListProfiles returns the profiles of a HelmRepository. The error wraps
+// os.ErrNotExist when the HelmRepository has not been cached.
+func (c *MemoryCache) ListProfiles(ctx context.Context, helmRepoNamespace, helmRepoName string) ([]*pb.Profile, error) {
+	var result []*pb.Profile
+
+	err := c.read(helmRepoNamespace, helmRepoName, func(data *Data) error {
+		result = cloneProfiles(data.Profiles)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles data for helm repo (%s/%s): %w", helmRepoNamespace, helmRepoName, err)
+	}
+
+	return result, nil
+}
+
+// ListAvailableVersionsForProfile returns all stored available versions for a profile.
+func (c *MemoryCache) ListAvailableVersionsForProfile(ctx context.Context, helmRepoNamespace, helmRepoName, profileName string) ([]string, error) {
+	var result []string
+
+	err := c.read(helmRepoNamespace, helmRepoName, func(data *Data) error {
+		versions, err := availableVersions(data.Profiles, profileName)
+		result = versions
+
+		return err
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return result, err
+}
+
+// GetProfileValues returns the values file of a profile version.
+func (c *MemoryCache) GetProfileValues(ctx context.Context, helmRepoNamespace, helmRepoName, profileName, profileVersion string) ([]byte, error) {
+	var result []byte
+
+	err := c.read(helmRepoNamespace, helmRepoName, func(data *Data) error {
+		values, ok := data.Values[profileName][profileVersion]
+		if !ok {
+			return fmt.Errorf("failed to read values file: no values for %s version %s: %w", profileName, profileVersion, os.ErrNotExist)
+		}
+
+		result = append([]byte(nil), values...)
+
+		return nil
+	})
+
+	return result, err
+}
+
+// GetProfileValuesSchema returns the values schema of a profile version, or
+// nil if the version has values but no schema.
+func (c *MemoryCache) GetProfileValuesSchema(ctx context.Context, helmRepoNamespace, helmRepoName, profileName, profileVersion string) ([]byte, error) {
+	var result []byte
+
+	err := c.read(helmRepoNamespace, helmRepoName, func(data *Data) error {
+		if _, ok := data.Values[profileName][profileVersion]; !ok {
+			return fmt.Errorf("failed to read values file: no values for %s version %s: %w", profileName, profileVersion, os.ErrNotExist)
+		}
+
+		if schema, ok := data.Schemas[profileName][profileVersion]; ok {
+			result = append([]byte(nil), schema...)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// read runs f with the data of a HelmRepository, holding its read lock.
+func (c *MemoryCache) read(helmRepoNamespace, helmRepoName string, f func(*Data) error) error {
+	c.mu.Lock()
+	entry, ok := c.repos[helmRepoNamespace+"/"+helmRepoName]
+	c.mu.Unlock()
+
+	if !ok {
+		return os.ErrNotExist
+	}
+
+	entry.mu.RLock()
+	defer entry.mu.RUnlock()
+
+	return f(&entry.data)
+}
+
+// availableVersions returns the versions of a profile listed in profiles.
+func availableVersions(profiles []*pb.Profile, profileName string) ([]string, error) {
+	for _, p := range profiles {
+		if p.Name == profileName {
+			return append([]string(nil), p.AvailableVersions...), nil
+		}
+	}
+
+	return nil, fmt.Errorf("profile with name %s not found in cached profiles", profileName)
+}
+
+// cloneProfiles deep copies profiles, so callers can modify them without
+// changing the cached data.
+func cloneProfiles(profiles []*pb.Profile) []*pb.Profile {
+	if profiles == nil {
+		return nil
+	}
+
+	result := make([]*pb.Profile, 0, len(profiles))
+	for _, p := range profiles {
+		result = append(result, proto.Clone(p).(*pb.Profile))
+	}
+
+	return result
+}
+
+func cloneValueMap(values ValueMap) ValueMap {
+	result := make(ValueMap, len(values))
+
+	for name, versions := range values {
+		result[name] = make(map[profileVersion][]byte, len(versions))
+
+		for version, data := range versions {
+			result[name][version] = append([]byte(nil), data...)
+		}
+	}
+
+	return result
+}
@@ -2,9 +2,15 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
 	"sort"
+	"sync"
 
 	"github.com/Masterminds/semver/v3"
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 	"github.com/go-logr/logr"
 	"github.com/helm/helm/pkg/chartutil"
@@ -16,13 +22,19 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
+	"github.com/weaveworks/weave-gitops/core/clustersmngr"
 	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
 	"github.com/weaveworks/weave-gitops/pkg/helm"
 	"github.com/weaveworks/weave-gitops/pkg/helm/watcher/cache"
+	"github.com/weaveworks/weave-gitops/pkg/server/auth"
 )
 
 const (
 	watcherFinalizer = "finalizers.helm.watcher"
+
+	// DefaultConcurrency is the number of charts fetched at the same time
+	// when HelmWatcherReconciler.Concurrency is not set.
+	DefaultConcurrency = 4
 )
 
 // EventRecorder defines an external event recorder's function for creating events for the notification controller.
@@ -39,6 +51,24 @@ type HelmWatcherReconciler struct {
 	RepoManager           helm.HelmRepoManager
 	ExternalEventRecorder eventRecorder
 	Scheme                *runtime.Scheme
+	// Concurrency bounds the number of charts fetched at the same time.
+	Concurrency int
+	// UpgradePolicy tells which upgrades of installed profiles are notified,
+	// unless a HelmRelease overrides it with helm.UpgradePolicyAnnotation.
+	// Defaults to helm.UpgradePolicyMajor.
+	UpgradePolicy helm.UpgradePolicy
+	// ClustersFetcher lists the clusters whose HelmReleases are notified of
+	// upgrades. Only the cluster of the watcher is checked when it is nil.
+	ClustersFetcher clustersmngr.ClusterFetcher
+
+	// clustersMu guards the clients of the clusters last fetched, which are
+	// reused until the clusters change.
+	clustersMu  sync.Mutex
+	clusters    []clustersmngr.Cluster
+	clientsPool clustersmngr.ClientsPool
+	// newClientsPool creates the clients pools, with
+	// clustersmngr.NewClustersClientsPool when nil.
+	newClientsPool func() clustersmngr.ClientsPool
 }
 
 // +kubebuilder:rbac:groups=helm.watcher,resources=helmrepositories,verbs=get;list;watch;create;update;patch;delete
@@ -72,7 +102,11 @@ func (r *HelmWatcherReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return r.reconcileDelete(ctx, repository)
 	}
 
-	if repository.Status.Artifact == nil {
+	// OCI repositories have no index artifact, so they are rescanned at
+	// their interval instead of when the artifact changes.
+	isOCI := helm.IsOCIRepository(&repository)
+
+	if repository.Status.Artifact == nil && !isOCI {
 		return ctrl.Result{}, nil
 	}
 
@@ -85,7 +119,20 @@ func (r *HelmWatcherReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
-	values := make(cache.ValueMap)
+	// Chart versions are immutable, so only the versions that are not cached
+	// yet are fetched. Versions missing from the index are dropped by Put.
+	cached, err := r.Cache.Get(ctx, repository.Namespace, repository.Name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Error(err, "failed to read cached data, fetching every version")
+	}
+
+	data := cache.Data{
+		Profiles: charts,
+		Values:   make(cache.ValueMap),
+		Schemas:  make(cache.ValueMap),
+	}
+
+	var missing []*helm.ChartReference
 
 	for _, chart := range charts {
 		if v, err := r.checkForNewVersion(ctx, chart); err != nil {
@@ -96,39 +143,100 @@ func (r *HelmWatcherReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 
 		for _, v := range chart.AvailableVersions {
-			valueBytes, err := r.RepoManager.GetValuesFile(context.Background(), &repository, &helm.ChartReference{
-				Chart:   chart.Name,
-				Version: v,
-			}, chartutil.ValuesfileName)
-
-			if err != nil {
-				log.Error(err, "failed to get values for chart and version, skipping...", "chart", chart.Name, "version", v)
-				// log error and skip version
+			values, ok := cached.Values[chart.Name][v]
+			if !ok {
+				missing = append(missing, &helm.ChartReference{Chart: chart.Name, Version: v})
 				continue
 			}
 
-			if _, ok := values[chart.Name]; !ok {
-				values[chart.Name] = make(map[string][]byte)
-			}
+			data.Values.Set(chart.Name, v, values)
 
-			values[chart.Name][v] = valueBytes
+			if schema, ok := cached.Schemas[chart.Name][v]; ok {
+				data.Schemas.Set(chart.Name, v, schema)
+			}
 		}
 	}
 
-	data := cache.Data{
-		Profiles: charts,
-		Values:   values,
-	}
+	failed := r.fetchValues(ctx, log, &repository, missing, &data)
+
+	r.notifyUpgrades(ctx, log, &repository, charts, cached)
 
 	if err := r.Cache.Put(logr.NewContext(ctx, log), repository.Namespace, repository.Name, data); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	log.Info("cached data from repository", "url", repository.Status.URL, "name", repository.Name, "number of profiles", len(charts))
+	log.Info("cached data from repository", "url", repository.Status.URL, "name", repository.Name, "number of profiles", len(charts),
+		"fetched versions", len(missing)-failed, "failed versions", failed)
+
+	// The versions that failed are fetched again at the next interval, as
+	// the artifact may not change before then.
+	if isOCI || failed > 0 {
+		return ctrl.Result{RequeueAfter: repository.Spec.Interval.Duration}, nil
+	}
 
 	return ctrl.Result{}, nil
 }
 
+// fetchValues downloads the values files and values schemas of refs, at most
+// r.Concurrency charts at a time, and adds them to data. A version that cannot
+// be fetched is logged and left out, without holding up the others. It
+// returns the number of versions that failed.
+func (r *HelmWatcherReconciler) fetchValues(ctx context.Context, log logr.Logger, repository *sourcev1.HelmRepository, refs []*helm.ChartReference, data *cache.Data) int {
+	concurrency := r.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		failed int
+	)
+
+	sem := make(chan struct{}, concurrency)
+
+	for _, ref := range refs {
+		ref := ref
+
+		wg.Add(1)
+
+		sem <- struct{}{}
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			files, err := r.RepoManager.GetChartFiles(ctx, repository, ref, chartutil.ValuesfileName, helm.ValuesSchemaFileName)
+			if err == nil && files[chartutil.ValuesfileName] == nil {
+				err = fmt.Errorf("failed to find file: %s", chartutil.ValuesfileName)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				log.Error(err, "failed to get values for chart and version, skipping...", "chart", ref.Chart, "version", ref.Version)
+
+				failed++
+
+				return
+			}
+
+			data.Values.Set(ref.Chart, ref.Version, files[chartutil.ValuesfileName])
+
+			if schema, ok := files[helm.ValuesSchemaFileName]; ok {
+				data.Schemas.Set(ref.Chart, ref.Version, schema)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return failed
+}
+
 func (r *HelmWatcherReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&sourcev1.HelmRepository{}).
@@ -173,7 +281,7 @@ func (r *HelmWatcherReconciler) sendEvent(log logr.Logger, hr *sourcev1.HelmRepo
 	}
 
 	var meta map[string]string
-	if hr.Status.Artifact.Revision != "" {
+	if hr.Status.Artifact != nil && hr.Status.Artifact.Revision != "" {
 		meta = map[string]string{"revision": hr.Status.Artifact.Revision}
 	}
 
@@ -183,6 +291,202 @@ func (r *HelmWatcherReconciler) sendEvent(log logr.Logger, hr *sourcev1.HelmRepo
 	}
 }
 
+// notifyUpgrades sends an event for each HelmRelease installed from the
+// repository when its upgrade policy allows a version that was just added to
+// the repository. The HelmReleases of every cluster are checked, those of
+// leaf clusters referencing a HelmRepository of the same namespace and name.
+// Nothing is sent on the first scan of a repository.
+func (r *HelmWatcherReconciler) notifyUpgrades(ctx context.Context, log logr.Logger, repository *sourcev1.HelmRepository, charts []*pb.Profile, cached cache.Data) {
+	if r.ExternalEventRecorder == nil || len(cached.Profiles) == 0 {
+		return
+	}
+
+	known := map[string]map[string]bool{}
+
+	for _, p := range cached.Profiles {
+		known[p.Name] = map[string]bool{}
+		for _, v := range p.AvailableVersions {
+			known[p.Name][v] = true
+		}
+	}
+
+	profiles := map[string]*pb.Profile{}
+	for _, chart := range charts {
+		profiles[chart.Name] = chart
+	}
+
+	releases, err := r.listHelmReleases(ctx)
+	if err != nil {
+		// the releases of the clusters that could be listed are still notified
+		log.Error(err, "failed to list HelmReleases")
+	}
+
+	for i := range releases {
+		hr := &releases[i].HelmRelease
+
+		repo, ok := helm.ReleaseHelmRepository(hr)
+		if !ok || repo != client.ObjectKeyFromObject(repository) {
+			continue
+		}
+
+		chart, ok := profiles[hr.Spec.Chart.Spec.Chart]
+		if !ok || known[chart.Name] == nil {
+			continue
+		}
+
+		policy := r.UpgradePolicy
+		if policy == "" {
+			policy = helm.UpgradePolicyMajor
+		}
+
+		if annotation, ok := hr.Annotations[helm.UpgradePolicyAnnotation]; ok {
+			p, err := helm.ParseUpgradePolicy(annotation)
+			if err != nil {
+				log.Error(err, "ignoring the upgrade policy of HelmRelease", "release", client.ObjectKeyFromObject(hr))
+			} else {
+				policy = p
+			}
+		}
+
+		installed := helm.InstalledVersion(hr)
+
+		upgrades, err := helm.AvailableUpgrades(installed, chart.AvailableVersions, policy)
+		if err != nil {
+			log.Error(err, "checking for upgrades failed", "release", client.ObjectKeyFromObject(hr))
+			continue
+		}
+
+		if len(upgrades) == 0 || known[chart.Name][upgrades[0]] {
+			continue
+		}
+
+		log.Info("sending notification event for upgrade", "cluster", releases[i].Cluster, "release", client.ObjectKeyFromObject(hr), "version", upgrades[0])
+		r.sendUpgradeEvent(log, releases[i].Cluster, hr, chart.Name, installed, upgrades[0])
+	}
+}
+
+// clusterHelmRelease is a HelmRelease and the name of its cluster.
+type clusterHelmRelease struct {
+	Cluster     string
+	HelmRelease helmv2beta1.HelmRelease
+}
+
+// listHelmReleases lists the HelmReleases of the clusters returned by the
+// ClustersFetcher, or of the cluster of the watcher when there is none. The
+// releases of the clusters that could be listed are returned with the error.
+func (r *HelmWatcherReconciler) listHelmReleases(ctx context.Context) ([]clusterHelmRelease, error) {
+	if r.ClustersFetcher == nil {
+		list := &helmv2beta1.HelmReleaseList{}
+		if err := r.List(ctx, list); err != nil {
+			return nil, fmt.Errorf("failed to list HelmReleases: %w", err)
+		}
+
+		return clusterHelmReleases(clustersmngr.DefaultCluster, list), nil
+	}
+
+	clusters, err := r.ClustersFetcher.Fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch clusters: %w", err)
+	}
+
+	pool, err := r.clustersClientsPool(clusters)
+	if err != nil {
+		return nil, err
+	}
+
+	return listClusterHelmReleases(ctx, clustersmngr.NewClient(pool))
+}
+
+// clustersClientsPool returns the clients of clusters. Creating them queries
+// the API of every cluster, so the clients are reused until the clusters, or
+// their credentials, change.
+func (r *HelmWatcherReconciler) clustersClientsPool(clusters []clustersmngr.Cluster) (clustersmngr.ClientsPool, error) {
+	r.clustersMu.Lock()
+	defer r.clustersMu.Unlock()
+
+	if r.clientsPool != nil && reflect.DeepEqual(r.clusters, clusters) {
+		return r.clientsPool, nil
+	}
+
+	newPool := clustersmngr.NewClustersClientsPool
+	if r.newClientsPool != nil {
+		newPool = r.newClientsPool
+	}
+
+	pool := newPool()
+
+	for _, cluster := range clusters {
+		// an empty principal uses the credentials of the cluster instead of impersonating a user
+		if err := pool.Add(&auth.UserPrincipal{}, cluster); err != nil {
+			return nil, fmt.Errorf("failed to create a client for cluster %s: %w", cluster.Name, err)
+		}
+	}
+
+	r.clusters, r.clientsPool = clusters, pool
+
+	return pool, nil
+}
+
+func listClusterHelmReleases(ctx context.Context, clustersClient clustersmngr.Client) ([]clusterHelmRelease, error) {
+	clist := clustersmngr.NewClusteredList(func() client.ObjectList {
+		return &helmv2beta1.HelmReleaseList{}
+	})
+
+	listErr := clustersClient.ClusteredList(ctx, clist)
+
+	var releases []clusterHelmRelease
+
+	for cluster, l := range clist.Lists() {
+		if list, ok := l.(*helmv2beta1.HelmReleaseList); ok {
+			releases = append(releases, clusterHelmReleases(cluster, list)...)
+		}
+	}
+
+	// keep the notifications in a stable order
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].Cluster < releases[j].Cluster
+	})
+
+	return releases, listErr
+}
+
+func clusterHelmReleases(cluster string, list *helmv2beta1.HelmReleaseList) []clusterHelmRelease {
+	releases := make([]clusterHelmRelease, 0, len(list.Items))
+	for _, hr := range list.Items {
+		releases = append(releases, clusterHelmRelease{Cluster: cluster, HelmRelease: hr})
+	}
+
+	return releases
+}
+
+// sendUpgradeEvent emits an event about the upgrade of a HelmRelease and
+// forwards it to the notification controller.
+func (r *HelmWatcherReconciler) sendUpgradeEvent(log logr.Logger, cluster string, hr *helmv2beta1.HelmRelease, profileName, installed, version string) {
+	objRef, err := reference.GetReference(r.Scheme, hr)
+	if err != nil {
+		log.Error(err, "unable to get reference")
+		return
+	}
+
+	upgradeType, err := helm.UpgradeType(installed, version)
+	if err != nil {
+		log.Error(err, "unable to get upgrade type")
+		return
+	}
+
+	meta := map[string]string{
+		"cluster":          cluster,
+		"profile":          profileName,
+		"installedVersion": installed,
+		"availableVersion": version,
+		"upgradeType":      string(upgradeType),
+	}
+
+	if err := r.ExternalEventRecorder.EventInfof(*objRef, meta, "info", "Upgrade available for profile %s in cluster %s from version %s to %s", profileName, cluster, installed, version); err != nil {
+		log.Error(err, "unable to send event")
+	}
+}
+
 // checkForNewVersion uses existing data to determine if there are newer versions in the incoming data
 // compared to what's already stored in the cache. It returns the LATEST version which is greater than
 // the last version that was stored.
@@ -3,25 +3,31 @@ package controller
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/weaveworks/weave-gitops/core/clustersmngr"
 	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
 	"github.com/weaveworks/weave-gitops/pkg/helm"
 	"github.com/weaveworks/weave-gitops/pkg/helm/helmfakes"
 	"github.com/weaveworks/weave-gitops/pkg/helm/watcher/cache"
 	"github.com/weaveworks/weave-gitops/pkg/helm/watcher/cache/cachefakes"
 	"github.com/weaveworks/weave-gitops/pkg/helm/watcher/controller/controllerfakes"
+	"github.com/weaveworks/weave-gitops/pkg/server/auth"
 )
 
 var (
@@ -63,9 +69,11 @@ var (
 
 func TestReconcile(t *testing.T) {
 	reconciler, fakeCache, fakeRepoManager, _ := setupReconcileAndFakes(repo1)
-	fakeRepoManager.GetValuesFileReturnsOnCall(0, []byte("value1"), nil)
-	fakeRepoManager.GetValuesFileReturnsOnCall(1, []byte("value2"), nil)
-	fakeRepoManager.GetValuesFileReturnsOnCall(2, []byte("value3"), nil)
+	fakeRepoManager.GetChartFilesStub = chartFiles(map[string]map[string][]byte{
+		profile1.Name + "/0.0.1": {"values.yaml": []byte("value1")},
+		profile1.Name + "/0.0.2": {"values.yaml": []byte("value2"), "values.schema.json": []byte("schema2")},
+		profile2.Name + "/0.0.4": {"values.yaml": []byte("value3")},
+	})
 
 	_, err := reconciler.Reconcile(context.Background(), ctrl.Request{
 		NamespacedName: types.NamespacedName{
@@ -86,35 +94,120 @@ func TestReconcile(t *testing.T) {
 				"0.0.4": []byte("value3"),
 			},
 		},
+		Schemas: map[string]map[string][]byte{
+			profile1.Name: {
+				"0.0.2": []byte("schema2"),
+			},
+		},
 	}
 	_, namespace, name, cacheData := fakeCache.PutArgsForCall(0)
 	assert.Equal(t, "test-namespace", namespace)
 	assert.Equal(t, "test-name", name)
 	assert.Equal(t, expectedData, cacheData)
 
-	_, helmRepo, ref, filename := fakeRepoManager.GetValuesFileArgsForCall(0)
-	assert.Equal(t, repo1.Status.Artifact, helmRepo.Status.Artifact)
-	assert.Equal(t, &helm.ChartReference{
-		Chart:   profile1.Name,
-		Version: "0.0.1",
-	}, ref)
-	assert.Equal(t, "values.yaml", filename)
-
-	_, helmRepo, ref, filename = fakeRepoManager.GetValuesFileArgsForCall(1)
-	assert.Equal(t, repo1.Status.Artifact, helmRepo.Status.Artifact)
-	assert.Equal(t, &helm.ChartReference{
-		Chart:   profile1.Name,
-		Version: "0.0.2",
-	}, ref)
-	assert.Equal(t, "values.yaml", filename)
-
-	_, helmRepo, ref, filename = fakeRepoManager.GetValuesFileArgsForCall(2)
-	assert.Equal(t, repo1.Status.Artifact, helmRepo.Status.Artifact)
-	assert.Equal(t, &helm.ChartReference{
-		Chart:   profile2.Name,
-		Version: "0.0.4",
-	}, ref)
-	assert.Equal(t, "values.yaml", filename)
+	assert.ElementsMatch(t, []*helm.ChartReference{
+		{Chart: profile1.Name, Version: "0.0.1"},
+		{Chart: profile1.Name, Version: "0.0.2"},
+		{Chart: profile2.Name, Version: "0.0.4"},
+	}, fetchedCharts(fakeRepoManager))
+
+	for i := 0; i < fakeRepoManager.GetChartFilesCallCount(); i++ {
+		_, helmRepo, _, filenames := fakeRepoManager.GetChartFilesArgsForCall(i)
+		assert.Equal(t, repo1.Status.Artifact, helmRepo.Status.Artifact)
+		assert.Equal(t, []string{"values.yaml", "values.schema.json"}, filenames)
+	}
+}
+
+func TestReconcileOnlyFetchesNewVersions(t *testing.T) {
+	reconciler, fakeCache, fakeRepoManager, _ := setupReconcileAndFakes(repo1)
+	fakeCache.GetReturns(cache.Data{
+		Profiles: []*pb.Profile{profile1},
+		Values: cache.ValueMap{
+			profile1.Name: {"0.0.1": []byte("value1"), "0.0.2": []byte("value2")},
+			// 0.0.0 is no longer in the index.
+			profile2.Name: {"0.0.0": []byte("value0")},
+		},
+		Schemas: cache.ValueMap{profile1.Name: {"0.0.2": []byte("schema2")}},
+	}, nil)
+	fakeRepoManager.GetChartFilesReturns(map[string][]byte{"values.yaml": []byte("value4")}, nil)
+
+	result, err := reconciler.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: "test-namespace",
+			Name:      "test-name",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+
+	assert.Equal(t, []*helm.ChartReference{{Chart: profile2.Name, Version: "0.0.4"}}, fetchedCharts(fakeRepoManager))
+
+	_, _, _, cacheData := fakeCache.PutArgsForCall(0)
+	assert.Equal(t, cache.Data{
+		Profiles: []*pb.Profile{profile1, profile2},
+		Values: cache.ValueMap{
+			profile1.Name: {"0.0.1": []byte("value1"), "0.0.2": []byte("value2")},
+			profile2.Name: {"0.0.4": []byte("value4")},
+		},
+		Schemas: cache.ValueMap{profile1.Name: {"0.0.2": []byte("schema2")}},
+	}, cacheData)
+}
+
+func TestReconcileFetchesEveryVersionWhenTheCacheCannotBeRead(t *testing.T) {
+	reconciler, fakeCache, fakeRepoManager, _ := setupReconcileAndFakes(repo1)
+	fakeCache.GetReturns(cache.Data{}, errors.New("nope"))
+
+	_, err := reconciler.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: "test-namespace",
+			Name:      "test-name",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, fakeRepoManager.GetChartFilesCallCount())
+	assert.Equal(t, 1, fakeCache.PutCallCount())
+}
+
+func TestReconcileBoundsConcurrentFetches(t *testing.T) {
+	profile := &pb.Profile{Name: "many-versions"}
+	for i := 0; i < 20; i++ {
+		profile.AvailableVersions = append(profile.AvailableVersions, fmt.Sprintf("1.0.%d", i))
+	}
+
+	reconciler, fakeCache, fakeRepoManager, _ := setupReconcileAndFakes(repo1)
+	reconciler.Concurrency = 3
+	fakeRepoManager.ListChartsReturns([]*pb.Profile{profile}, nil)
+
+	var running, maxRunning int32
+
+	fakeRepoManager.GetChartFilesStub = func(context.Context, *sourcev1.HelmRepository, *helm.ChartReference, ...string) (map[string][]byte, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+
+		time.Sleep(time.Millisecond)
+
+		return map[string][]byte{"values.yaml": []byte("value")}, nil
+	}
+
+	_, err := reconciler.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: "test-namespace",
+			Name:      "test-name",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 20, fakeRepoManager.GetChartFilesCallCount())
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(3))
+
+	_, _, _, cacheData := fakeCache.PutArgsForCall(0)
+	assert.Len(t, cacheData.Values[profile.Name], 20)
 }
 
 func TestReconcileDelete(t *testing.T) {
@@ -191,11 +284,13 @@ func TestReconcileGetChartFails(t *testing.T) {
 	})
 	assert.EqualError(t, err, "nope")
 }
-func TestReconcileGetValuesFileFailsItWillContinue(t *testing.T) {
-	reconciler, fakeCache, fakeRepoManager, _ := setupReconcileAndFakes(repo1)
-	fakeRepoManager.GetValuesFileReturns(nil, errors.New("this will be skipped"))
+func TestReconcileGetChartFilesFailsItWillContinue(t *testing.T) {
+	repo := repo1.DeepCopy()
+	repo.Spec.Interval = metav1.Duration{Duration: 10 * time.Minute}
+	reconciler, fakeCache, fakeRepoManager, _ := setupReconcileAndFakes(repo)
+	fakeRepoManager.GetChartFilesReturns(nil, errors.New("this will be skipped"))
 
-	_, err := reconciler.Reconcile(context.Background(), ctrl.Request{
+	result, err := reconciler.Reconcile(context.Background(), ctrl.Request{
 		NamespacedName: types.NamespacedName{
 			Namespace: "test-namespace",
 			Name:      "test-name",
@@ -206,11 +301,34 @@ func TestReconcileGetValuesFileFailsItWillContinue(t *testing.T) {
 	expectedData := cache.Data{
 		Profiles: []*pb.Profile{profile1, profile2},
 		Values:   map[string]map[string][]byte{},
+		Schemas:  map[string]map[string][]byte{},
 	}
 	_, namespace, name, cacheData := fakeCache.PutArgsForCall(0)
 	assert.Equal(t, "test-namespace", namespace)
 	assert.Equal(t, "test-name", name)
 	assert.Equal(t, expectedData, cacheData)
+	assert.Equal(t, 10*time.Minute, result.RequeueAfter)
+}
+
+func TestReconcileGetChartFilesFailsForOneChart(t *testing.T) {
+	reconciler, fakeCache, fakeRepoManager, _ := setupReconcileAndFakes(repo1)
+	fakeRepoManager.GetChartFilesStub = chartFiles(map[string]map[string][]byte{
+		profile1.Name + "/0.0.1": {"values.yaml": []byte("value1")},
+		profile1.Name + "/0.0.2": {"values.yaml": []byte("value2")},
+	})
+
+	_, err := reconciler.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: "test-namespace",
+			Name:      "test-name",
+		},
+	})
+	assert.NoError(t, err)
+
+	_, _, _, cacheData := fakeCache.PutArgsForCall(0)
+	assert.Equal(t, cache.ValueMap{
+		profile1.Name: {"0.0.1": []byte("value1"), "0.0.2": []byte("value2")},
+	}, cacheData.Values)
 }
 
 func TestReconcileIgnoreReposWithoutArtifact(t *testing.T) {
@@ -222,7 +340,7 @@ func TestReconcileIgnoreReposWithoutArtifact(t *testing.T) {
 	}
 	reconciler, fakeCache, fakeRepoManager, _ := setupReconcileAndFakes(repo)
 
-	fakeRepoManager.GetValuesFileReturns(nil, errors.New("this will be skipped"))
+	fakeRepoManager.GetChartFilesReturns(nil, errors.New("this will be skipped"))
 
 	_, err := reconciler.Reconcile(context.Background(), ctrl.Request{
 		NamespacedName: types.NamespacedName{
@@ -233,10 +351,36 @@ func TestReconcileIgnoreReposWithoutArtifact(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Zero(t, fakeRepoManager.ListChartsCallCount())
-	assert.Zero(t, fakeRepoManager.GetValuesFileCallCount())
+	assert.Zero(t, fakeRepoManager.GetChartFilesCallCount())
 	assert.Zero(t, fakeCache.PutCallCount())
 }
 
+func TestReconcileOCIRepositoryIsRequeued(t *testing.T) {
+	repo := &sourcev1.HelmRepository{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-name",
+			Namespace: "test-namespace",
+		},
+		Spec: sourcev1.HelmRepositorySpec{
+			URL:      "oci://ghcr.io/weaveworks/charts",
+			Interval: metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+	reconciler, fakeCache, fakeRepoManager, _ := setupReconcileAndFakes(repo)
+
+	result, err := reconciler.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: "test-namespace",
+			Name:      "test-name",
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, result.RequeueAfter)
+	assert.Equal(t, 1, fakeRepoManager.ListChartsCallCount())
+	assert.Equal(t, 1, fakeCache.PutCallCount())
+}
+
 func TestReconcileUpdateReturnsError(t *testing.T) {
 	reconciler, fakeCache, _, _ := setupReconcileAndFakes(repo1)
 	fakeCache.PutReturns(errors.New("nope"))
@@ -327,6 +471,154 @@ func TestNotifyForGreaterVersionEventSenderFailureIsIgnored(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestNotifyUpgradesOfInstalledReleases(t *testing.T) {
+	podinfo := &pb.Profile{Name: "podinfo", AvailableVersions: []string{"1.0.0", "1.0.1", "1.1.0"}}
+	repoRef := types.NamespacedName{Name: repo1.Name, Namespace: repo1.Namespace}
+
+	defaultPolicy := helm.MakeHelmRelease("podinfo", "1.0.0", "prod", "apps", repoRef)
+	patchPolicy := helm.MakeHelmRelease("podinfo", "1.0.0", "staging", "apps", repoRef)
+	patchPolicy.Annotations = map[string]string{helm.UpgradePolicyAnnotation: "patch"}
+	upToDate := helm.MakeHelmRelease("podinfo", "1.0.0", "dev", "apps", repoRef)
+	upToDate.Status.LastAppliedRevision = "1.1.0"
+	otherRepo := helm.MakeHelmRelease("podinfo", "1.0.0", "prod", "other", types.NamespacedName{Name: "other", Namespace: "flux-system"})
+
+	reconciler, fakeCache, fakeRepoManager, fakeEventRecorder := setupReconcileAndFakes(repo1, defaultPolicy, patchPolicy, upToDate, otherRepo)
+	fakeRepoManager.ListChartsReturns([]*pb.Profile{podinfo}, nil)
+	fakeCache.GetReturns(cache.Data{
+		Profiles: []*pb.Profile{{Name: "podinfo", AvailableVersions: []string{"1.0.0"}}},
+	}, nil)
+
+	_, err := reconciler.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: repoRef,
+	})
+	assert.NoError(t, err)
+
+	events := map[string]map[string]string{}
+
+	for i := 0; i < fakeEventRecorder.EventInfofCallCount(); i++ {
+		ref, meta, _, message, args := fakeEventRecorder.EventInfofArgsForCall(i)
+		if ref.Kind != helmv2beta1.HelmReleaseKind {
+			continue
+		}
+
+		assert.Equal(t, "Upgrade available for profile %s in cluster %s from version %s to %s", message)
+		assert.Equal(t, []interface{}{"podinfo", meta["cluster"], meta["installedVersion"], meta["availableVersion"]}, args)
+
+		events[ref.Namespace+"/"+ref.Name] = meta
+	}
+
+	assert.Equal(t, map[string]map[string]string{
+		"apps/prod-podinfo": {
+			"cluster":          clustersmngr.DefaultCluster,
+			"profile":          "podinfo",
+			"installedVersion": "1.0.0",
+			"availableVersion": "1.1.0",
+			"upgradeType":      "minor",
+		},
+		"apps/staging-podinfo": {
+			"cluster":          clustersmngr.DefaultCluster,
+			"profile":          "podinfo",
+			"installedVersion": "1.0.0",
+			"availableVersion": "1.0.1",
+			"upgradeType":      "patch",
+		},
+	}, events)
+}
+
+func TestDoNotNotifyUpgradesAlreadyCached(t *testing.T) {
+	podinfo := &pb.Profile{Name: "podinfo", AvailableVersions: []string{"1.0.0", "1.0.1", "1.1.0"}}
+	repoRef := types.NamespacedName{Name: repo1.Name, Namespace: repo1.Namespace}
+
+	reconciler, fakeCache, fakeRepoManager, fakeEventRecorder := setupReconcileAndFakes(repo1, helm.MakeHelmRelease("podinfo", "1.0.0", "prod", "apps", repoRef))
+	fakeRepoManager.ListChartsReturns([]*pb.Profile{podinfo}, nil)
+	fakeCache.GetReturns(cache.Data{
+		Profiles: []*pb.Profile{{Name: "podinfo", AvailableVersions: []string{"1.0.0", "1.1.0"}}},
+	}, nil)
+
+	_, err := reconciler.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: repoRef,
+	})
+	assert.NoError(t, err)
+	assert.Zero(t, fakeEventRecorder.EventInfofCallCount())
+}
+
+func TestListHelmReleasesOfEveryCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(helmv2beta1.AddToScheme(scheme))
+
+	repoRef := types.NamespacedName{Name: repo1.Name, Namespace: repo1.Namespace}
+	management := fake.NewClientBuilder().WithScheme(scheme).WithObjects(helm.MakeHelmRelease("podinfo", "1.0.0", "prod", "apps", repoRef)).Build()
+	leaf := fake.NewClientBuilder().WithScheme(scheme).WithObjects(helm.MakeHelmRelease("podinfo", "1.0.1", "prod", "apps", repoRef)).Build()
+
+	releases, err := listClusterHelmReleases(context.Background(), clustersmngr.NewClient(clientsPool{
+		"management": clusterClient{Client: management},
+		"leaf":       clusterClient{Client: leaf},
+	}))
+	assert.NoError(t, err)
+
+	assert.Len(t, releases, 2)
+	assert.Equal(t, "leaf", releases[0].Cluster)
+	assert.Equal(t, "1.0.1", helm.InstalledVersion(&releases[0].HelmRelease))
+	assert.Equal(t, "management", releases[1].Cluster)
+	assert.Equal(t, "1.0.0", helm.InstalledVersion(&releases[1].HelmRelease))
+}
+
+func TestClustersClientsPoolIsReusedUntilTheClustersChange(t *testing.T) {
+	created := 0
+	reconciler := &HelmWatcherReconciler{
+		newClientsPool: func() clustersmngr.ClientsPool {
+			created++
+			return clientsPool{}
+		},
+	}
+
+	leaf := func(token string) []clustersmngr.Cluster {
+		return []clustersmngr.Cluster{{Name: "leaf", Server: "https://leaf.example.com:6443", BearerToken: token}}
+	}
+
+	pool, err := reconciler.clustersClientsPool(leaf("token"))
+	assert.NoError(t, err)
+	assert.Contains(t, pool.Clients(), "leaf")
+
+	reused, err := reconciler.clustersClientsPool(leaf("token"))
+	assert.NoError(t, err)
+	assert.Equal(t, pool, reused)
+	assert.Equal(t, 1, created)
+
+	_, err = reconciler.clustersClientsPool(leaf("rotated"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, created)
+}
+
+// clientsPool serves fake clients by cluster name.
+type clientsPool map[string]clustersmngr.ClusterClient
+
+func (p clientsPool) Add(user *auth.UserPrincipal, cluster clustersmngr.Cluster) error {
+	p[cluster.Name] = clusterClient{}
+
+	return nil
+}
+
+func (p clientsPool) Clients() map[string]clustersmngr.ClusterClient {
+	return p
+}
+
+func (p clientsPool) Client(cluster string) (clustersmngr.ClusterClient, error) {
+	if c, ok := p[cluster]; ok {
+		return c, nil
+	}
+
+	return nil, clustersmngr.ClusterNotFoundError{Cluster: cluster}
+}
+
+type clusterClient struct {
+	client.Client
+}
+
+func (c clusterClient) RestConfig() *rest.Config {
+	return &rest.Config{}
+}
+
 type mockClient struct {
 	client.Client
 	getErr    error
@@ -369,7 +661,7 @@ func TestReconcileKubernetesGetFails(t *testing.T) {
 	})
 	assert.EqualError(t, err, "nope")
 	assert.Zero(t, fakeRepoManager.ListChartsCallCount())
-	assert.Zero(t, fakeRepoManager.GetValuesFileCallCount())
+	assert.Zero(t, fakeRepoManager.GetChartFilesCallCount())
 	assert.Zero(t, fakeCache.PutCallCount())
 }
 
@@ -437,9 +729,34 @@ func TestReconcilePatchFails(t *testing.T) {
 	assert.EqualError(t, err, "nope")
 }
 
+// chartFiles returns a GetChartFiles stub serving the files of charts, keyed by
+// chart/version, and failing for the others.
+func chartFiles(charts map[string]map[string][]byte) func(context.Context, *sourcev1.HelmRepository, *helm.ChartReference, ...string) (map[string][]byte, error) {
+	return func(_ context.Context, _ *sourcev1.HelmRepository, ref *helm.ChartReference, _ ...string) (map[string][]byte, error) {
+		files, ok := charts[ref.Chart+"/"+ref.Version]
+		if !ok {
+			return nil, fmt.Errorf("chart %s version %s not found", ref.Chart, ref.Version)
+		}
+
+		return files, nil
+	}
+}
+
+func fetchedCharts(fakeRepoManager *helmfakes.FakeHelmRepoManager) []*helm.ChartReference {
+	var refs []*helm.ChartReference
+
+	for i := 0; i < fakeRepoManager.GetChartFilesCallCount(); i++ {
+		_, _, ref, _ := fakeRepoManager.GetChartFilesArgsForCall(i)
+		refs = append(refs, ref)
+	}
+
+	return refs
+}
+
 func setupReconcileAndFakes(objects ...client.Object) (*HelmWatcherReconciler, *cachefakes.FakeCache, *helmfakes.FakeHelmRepoManager, *controllerfakes.FakeEventRecorder) {
 	scheme := runtime.NewScheme()
 	utilruntime.Must(sourcev1.AddToScheme(scheme))
+	utilruntime.Must(helmv2beta1.AddToScheme(scheme))
 
 	fakeCache := &cachefakes.FakeCache{}
 	fakeRepoManager := &helmfakes.FakeHelmRepoManager{}
@@ -447,12 +764,13 @@ func setupReconcileAndFakes(objects ...client.Object) (*HelmWatcherReconciler, *
 	fakeEventRecorder := &controllerfakes.FakeEventRecorder{}
 
 	fakeRepoManager.ListChartsReturns([]*pb.Profile{profile1, profile2}, nil)
-	fakeRepoManager.GetValuesFileReturns([]byte("value"), nil)
+	fakeRepoManager.GetChartFilesReturns(map[string][]byte{"values.yaml": []byte("value")}, nil)
 
 	return &HelmWatcherReconciler{
 		Client:                fakeClient.Build(),
 		Cache:                 fakeCache,
 		RepoManager:           fakeRepoManager,
 		ExternalEventRecorder: fakeEventRecorder,
+		Scheme:                scheme,
 	}, fakeCache, fakeRepoManager, fakeEventRecorder
 }
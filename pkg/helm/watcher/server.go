@@ -3,6 +3,7 @@ package watcher
 import (
 	"io/ioutil"
 
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
 	"github.com/fluxcd/pkg/runtime/events"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 	"github.com/go-logr/logr"
@@ -13,6 +14,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
+	"github.com/weaveworks/weave-gitops/core/clustersmngr"
 	"github.com/weaveworks/weave-gitops/pkg/helm"
 
 	//+kubebuilder:scaffold:imports
@@ -20,7 +22,11 @@ import (
 	"github.com/weaveworks/weave-gitops/pkg/helm/watcher/controller"
 )
 
-const controllerName = "helm-watcher"
+const (
+	controllerName = "helm-watcher"
+	// leaderElectionID is the name of the lock held by the replica scanning the HelmRepositories.
+	leaderElectionID = "helm-watcher.weave.works"
+)
 
 var (
 	scheme = runtime.NewScheme()
@@ -33,6 +39,15 @@ type Options struct {
 	HealthzBindAddress            string
 	NotificationControllerAddress string
 	WatcherPort                   int
+	// UpgradePolicy tells which upgrades of installed profiles are notified.
+	UpgradePolicy helm.UpgradePolicy
+	// LeaderElection makes a single replica scan the HelmRepositories, for caches shared between
+	// replicas. The lock is kept in LeaderElectionNamespace.
+	LeaderElection          bool
+	LeaderElectionNamespace string
+	// ClustersFetcher lists the clusters whose HelmReleases are notified of upgrades. Only the
+	// cluster of the watcher is checked when it is nil.
+	ClustersFetcher clustersmngr.ClusterFetcher
 }
 
 type Watcher struct {
@@ -42,6 +57,10 @@ type Watcher struct {
 	healthzBindAddress  string
 	watcherPort         int
 	notificationAddress string
+	upgradePolicy       helm.UpgradePolicy
+	leaderElection      bool
+	leaderElectionNS    string
+	clustersFetcher     clustersmngr.ClusterFetcher
 }
 
 func NewWatcher(opts Options) (*Watcher, error) {
@@ -58,6 +77,10 @@ func NewWatcher(opts Options) (*Watcher, error) {
 		return nil, err
 	}
 
+	if err := helmv2beta1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+
 	return &Watcher{
 		cache:               opts.Cache,
 		repoManager:         helm.NewRepoManager(opts.KubeClient, tempDir),
@@ -65,6 +88,10 @@ func NewWatcher(opts Options) (*Watcher, error) {
 		metricsBindAddress:  opts.MetricsBindAddress,
 		notificationAddress: opts.NotificationControllerAddress,
 		watcherPort:         opts.WatcherPort,
+		upgradePolicy:       opts.UpgradePolicy,
+		leaderElection:      opts.LeaderElection,
+		leaderElectionNS:    opts.LeaderElectionNamespace,
+		clustersFetcher:     opts.ClustersFetcher,
 	}, nil
 }
 
@@ -77,6 +104,10 @@ func (w *Watcher) StartWatcher(log logr.Logger) error {
 		HealthProbeBindAddress: w.healthzBindAddress,
 		Port:                   w.watcherPort,
 		Logger:                 ctrl.Log,
+		// the other replicas read the profiles the leader stores in the shared cache
+		LeaderElection:          w.leaderElection,
+		LeaderElectionID:        leaderElectionID,
+		LeaderElectionNamespace: w.leaderElectionNS,
 	})
 	if err != nil {
 		ctrl.Log.Error(err, "unable to create manager")
@@ -109,6 +140,8 @@ func (w *Watcher) StartWatcher(log logr.Logger) error {
 		RepoManager:           w.repoManager,
 		Scheme:                scheme,
 		ExternalEventRecorder: eventRecorder,
+		UpgradePolicy:         w.upgradePolicy,
+		ClustersFetcher:       w.clustersFetcher,
 	}).SetupWithManager(mgr); err != nil {
 		ctrl.Log.Error(err, "unable to create controller", "controller", "HelmWatcherReconciler")
 		return err
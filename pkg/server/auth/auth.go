@@ -89,9 +89,19 @@ func WithAPIAuth(next http.Handler, srv *AuthServer, publicRoutes []string) http
 		multi = append(multi, headerAuth, cookieAuth)
 	}
 
+	// Machine clients can authenticate with a client certificate alone.
+	multi = append(multi, NewClientCertPrincipalGetter(srv.Log))
+
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
 		if IsPublicRoute(r.URL, publicRoutes) {
+			// Public routes can still tailor their response to a signed in
+			// user, e.g. feature flags targeted at some groups.
+			if principal, err := multi.Principal(r); err == nil && principal != nil {
+				r = r.Clone(WithPrincipal(r.Context(), principal))
+			}
+
 			next.ServeHTTP(rw, r)
+
 			return
 		}
 
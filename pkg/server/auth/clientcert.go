@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-logr/logr"
+)
+
+// ClientCertPrincipalGetter maps a verified TLS client certificate to a
+// principal the same way the Kubernetes API server does: the subject common
+// name is the user and the subject organisations are its groups.
+type ClientCertPrincipalGetter struct {
+	log logr.Logger
+}
+
+func NewClientCertPrincipalGetter(log logr.Logger) PrincipalGetter {
+	return &ClientCertPrincipalGetter{
+		log: log,
+	}
+}
+
+func (pg *ClientCertPrincipalGetter) Principal(r *http.Request) (*UserPrincipal, error) {
+	// Only certificates verified against the client CA bundle are chained,
+	// a certificate that was merely presented is ignored.
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, nil
+	}
+
+	pg.log.Info("attempt to read principal from client certificate")
+
+	subject := r.TLS.VerifiedChains[0][0].Subject
+	if subject.CommonName == "" {
+		return nil, errors.New("client certificate has no common name")
+	}
+
+	groups := append([]string{}, subject.Organization...)
+
+	return &UserPrincipal{ID: subject.CommonName, Groups: groups}, nil
+}
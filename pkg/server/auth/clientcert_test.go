@@ -0,0 +1,174 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/server/auth"
+)
+
+func TestClientCertPrincipalGetter(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "ci-bot", Organization: []string{"deployers", "readers"}}}
+
+	authTests := []struct {
+		name    string
+		state   *tls.ConnectionState
+		want    *auth.UserPrincipal
+		wantErr bool
+	}{
+		{"verified certificate", &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}, &auth.UserPrincipal{ID: "ci-bot", Groups: []string{"deployers", "readers"}}, false},
+		{"unverified certificate", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}, nil, false},
+		{"no TLS", nil, nil, false},
+		{"no common name", &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}, nil, true},
+	}
+
+	for _, tt := range authTests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			req.TLS = tt.state
+
+			principal, err := auth.NewClientCertPrincipalGetter(logr.Discard()).Principal(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, principal); diff != "" {
+				t.Fatalf("failed to get principal:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithAPIAuthAcceptsClientCertificates(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	caKey, caCert := makeCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "client-ca"},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+
+	clientKey, clientCert := makeCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "ci-bot", Organization: []string{"deployers"}},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, caKey)
+
+	tokenSignerVerifier, err := auth.NewHMACTokenSignerVerifier(5 * time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	authCfg, err := auth.NewAuthServerConfig(logr.Discard(), auth.OIDCConfig{}, nil, tokenSignerVerifier)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	srv, err := auth.NewAuthServer(context.Background(), authCfg)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var principal *auth.UserPrincipal
+
+	s := httptest.NewUnstartedServer(auth.WithAPIAuth(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		principal = auth.Principal(r.Context())
+	}), srv, nil))
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+
+	s.TLS = &tls.Config{ClientCAs: pool, ClientAuth: tls.VerifyClientCertIfGiven}
+	s.StartTLS()
+
+	t.Cleanup(s.Close)
+
+	// Without a certificate the request is not authenticated.
+	res, err := s.Client().Get(s.URL)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res).To(HaveHTTPStatus(http.StatusUnauthorized))
+
+	client := s.Client()
+	client.Transport.(*http.Transport).TLSClientConfig.Certificates = []tls.Certificate{{
+		Certificate: [][]byte{clientCert.Raw},
+		PrivateKey:  clientKey,
+	}}
+
+	res, err = client.Get(s.URL)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res).To(HaveHTTPStatus(http.StatusOK))
+	g.Expect(principal).To(Equal(&auth.UserPrincipal{ID: "ci-bot", Groups: []string{"deployers"}}))
+}
+
+// makeCert creates a certificate from template, signed by parent or
+// self-signed when parent is nil.
+func makeCert(t *testing.T, template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*ecdsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key, cert
+}
+
+func TestWithAPIAuthSetsPrincipalOnPublicRoutes(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tokenSignerVerifier, err := auth.NewHMACTokenSignerVerifier(5 * time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	authCfg, err := auth.NewAuthServerConfig(logr.Discard(), auth.OIDCConfig{}, nil, tokenSignerVerifier)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	srv, err := auth.NewAuthServer(context.Background(), authCfg)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var principal *auth.UserPrincipal
+
+	handler := auth.WithAPIAuth(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		principal = auth.Principal(r.Context())
+	}), srv, []string{"/v1/featureflags"})
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/v1/featureflags", nil)
+	handler.ServeHTTP(res, req)
+
+	g.Expect(res).To(HaveHTTPStatus(http.StatusOK))
+	g.Expect(principal).To(BeNil())
+
+	res = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "https://example.com/v1/featureflags", nil)
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "ci-bot"}}}}}
+	handler.ServeHTTP(res, req)
+
+	g.Expect(res).To(HaveHTTPStatus(http.StatusOK))
+	g.Expect(principal).To(Equal(&auth.UserPrincipal{ID: "ci-bot", Groups: []string{}}))
+}
@@ -0,0 +1,175 @@
+// Package config loads the gitops-server configuration file. The file is a
+// versioned YAML document that covers every option of the server, so it can
+// be shipped as a ConfigMap rather than a long list of flags.
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// APIVersion is the only supported version of the file format.
+	APIVersion = "gitops.weave.works/v1alpha1"
+	// Kind identifies a gitops-server configuration file.
+	Kind = "GitopsServerConfig"
+)
+
+// Config is the content of a configuration file. Zero values mean "not set",
+// in which case the command line flag (or its default) is used.
+type Config struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	Server   Server   `json:"server,omitempty"`
+	TLS      TLS      `json:"tls,omitempty"`
+	Auth     Auth     `json:"auth,omitempty"`
+	Profiles Profiles `json:"profiles,omitempty"`
+	// RateLimits limits the API requests callers can make.
+	RateLimits RateLimits `json:"rateLimits,omitempty"`
+
+	// Clusters lists the leaf clusters the server connects to, next to the
+	// cluster it is running in.
+	Clusters []Cluster `json:"clusters,omitempty"`
+	// AccessRules is the minimum set of permissions a user needs in a
+	// namespace for it to be listed. Defaults to nsaccess.DefautltWegoAppRules.
+	AccessRules []rbacv1.PolicyRule `json:"accessRules,omitempty"`
+	// FeatureFlags are returned to the UI by GetFeatureFlags, unless the
+	// feature flags ConfigMap sets them too.
+	FeatureFlags map[string]string `json:"featureFlags,omitempty"`
+	// FeatureFlagsConfigMap names the ConfigMap in the flux-system namespace
+	// holding targeted feature flags.
+	FeatureFlagsConfigMap string `json:"featureFlagsConfigMap,omitempty"`
+}
+
+// Server holds the listener and logging options.
+type Server struct {
+	Host                          string `json:"host,omitempty"`
+	Port                          string `json:"port,omitempty"`
+	Path                          string `json:"path,omitempty"`
+	LogLevel                      string `json:"logLevel,omitempty"`
+	NotificationControllerAddress string `json:"notificationControllerAddress,omitempty"`
+}
+
+// TLS holds the certificate used to serve the UI and API.
+type TLS struct {
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+	// ClientCAFile is the CA bundle used to verify client certificates.
+	ClientCAFile string `json:"clientCAFile,omitempty"`
+	Insecure     bool   `json:"insecure,omitempty"`
+	MTLS         bool   `json:"mtls,omitempty"`
+}
+
+// Auth configures authentication of the API.
+type Auth struct {
+	// Enabled has the same effect as WEAVE_GITOPS_AUTH_ENABLED=true.
+	Enabled bool `json:"enabled,omitempty"`
+	OIDC    OIDC `json:"oidc,omitempty"`
+}
+
+// OIDC configures the OpenID Connect issuer. The oidc-auth Secret, when
+// present, still takes precedence.
+type OIDC struct {
+	IssuerURL     string          `json:"issuerURL,omitempty"`
+	ClientID      string          `json:"clientID,omitempty"`
+	ClientSecret  string          `json:"clientSecret,omitempty"`
+	RedirectURL   string          `json:"redirectURL,omitempty"`
+	TokenDuration metav1.Duration `json:"tokenDuration,omitempty"`
+}
+
+// Profiles configures where profiles are discovered and cached.
+type Profiles struct {
+	HelmRepository HelmRepository `json:"helmRepository,omitempty"`
+	// HelmRepositories lists several HelmRepositories to serve profiles from,
+	// as "namespace/name", or "*" for every HelmRepository the user can read.
+	// It takes precedence over HelmRepository.
+	HelmRepositories []string `json:"helmRepositories,omitempty"`
+	CacheLocation    string   `json:"cacheLocation,omitempty"`
+	// CacheBackend is one of "filesystem", "memory" or "configmap".
+	CacheBackend string `json:"cacheBackend,omitempty"`
+	// CacheNamespace holds the ConfigMaps of the "configmap" backend.
+	CacheNamespace string         `json:"cacheNamespace,omitempty"`
+	Watcher        ProfileWatcher `json:"watcher,omitempty"`
+}
+
+// HelmRepository references the HelmRepository scanned for profiles.
+type HelmRepository struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ProfileWatcher configures the controller that keeps the profile cache up to
+// date.
+type ProfileWatcher struct {
+	Port               int    `json:"port,omitempty"`
+	MetricsBindAddress string `json:"metricsBindAddress,omitempty"`
+	HealthzBindAddress string `json:"healthzBindAddress,omitempty"`
+	// UpgradePolicy is one of "patch", "minor" or "major".
+	UpgradePolicy string `json:"upgradePolicy,omitempty"`
+}
+
+// RateLimits configures the token buckets API requests are counted in.
+type RateLimits struct {
+	// Global is shared by all callers.
+	Global RateLimit `json:"global,omitempty"`
+	// PerPrincipal applies to each user, or to each client address for
+	// unauthenticated requests.
+	PerPrincipal RateLimit `json:"perPrincipal,omitempty"`
+	// FanOut applies to each user for the routes listing resources in every
+	// cluster.
+	FanOut RateLimit `json:"fanOut,omitempty"`
+	// FanOutRoutes replaces the default list of fan-out routes.
+	FanOutRoutes []string `json:"fanOutRoutes,omitempty"`
+}
+
+// RateLimit allows bursts of Requests requests, refilled every Interval.
+// Interval defaults to one second.
+type RateLimit struct {
+	Requests uint64          `json:"requests,omitempty"`
+	Interval metav1.Duration `json:"interval,omitempty"`
+}
+
+// Cluster is a leaf cluster. SecretRef names a Secret in the flux-system
+// namespace holding a service account "token" and, optionally, the cluster
+// "ca.crt".
+type Cluster struct {
+	Name      string `json:"name"`
+	Server    string `json:"server"`
+	SecretRef string `json:"secretRef"`
+}
+
+// Load reads and validates the configuration file at path.
+func Load(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Parse decodes and validates a configuration file. Unknown fields are
+// rejected so that typos do not go unnoticed.
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,267 @@
+package config_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/server/config"
+)
+
+const validConfig = `
+apiVersion: gitops.weave.works/v1alpha1
+kind: GitopsServerConfig
+server:
+  port: "9002"
+  logLevel: debug
+auth:
+  enabled: true
+  oidc:
+    issuerURL: https://dex.example.com
+    clientID: weave-gitops
+    clientSecret: secret
+    redirectURL: https://gitops.example.com/oauth2/callback
+    tokenDuration: 30m
+profiles:
+  helmRepository:
+    name: weaveworks-charts
+    namespace: flux-system
+clusters:
+  - name: leaf
+    server: https://leaf.example.com:6443
+    secretRef: leaf-credentials
+accessRules:
+  - apiGroups: [""]
+    resources: ["pods"]
+    verbs: ["get", "list"]
+rateLimits:
+  perPrincipal:
+    requests: 20
+  fanOut:
+    requests: 30
+    interval: 1m
+featureFlags:
+  WEAVE_GITOPS_FEATURE_CLUSTER: "true"
+`
+
+func TestParse(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cfg, err := config.Parse([]byte(validConfig))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.Server.Port).To(Equal("9002"))
+	g.Expect(cfg.Auth.OIDC.TokenDuration.Duration).To(Equal(30 * time.Minute))
+	g.Expect(cfg.Clusters).To(HaveLen(1))
+	g.Expect(cfg.AccessRules[0].Verbs).To(ConsistOf("get", "list"))
+	g.Expect(cfg.RateLimits.FanOut.Interval.Duration).To(Equal(time.Minute))
+	g.Expect(cfg.FeatureFlags).To(HaveKeyWithValue("WEAVE_GITOPS_FEATURE_CLUSTER", "true"))
+}
+
+func TestParseRejectsUnknownFields(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	_, err := config.Parse([]byte(`
+apiVersion: gitops.weave.works/v1alpha1
+kind: GitopsServerConfig
+server:
+  prot: "9002"
+`))
+	g.Expect(err).To(MatchError(ContainSubstring(`unknown field "prot"`)))
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		errors []string
+	}{
+		{
+			name:   "missing version",
+			config: "kind: GitopsServerConfig",
+			errors: []string{"apiVersion: Required value"},
+		},
+		{
+			name: "unsupported version",
+			config: `
+apiVersion: gitops.weave.works/v2
+kind: GitopsServerConfig`,
+			errors: []string{`apiVersion: Unsupported value: "gitops.weave.works/v2"`},
+		},
+		{
+			name: "invalid server",
+			config: `
+apiVersion: gitops.weave.works/v1alpha1
+kind: GitopsServerConfig
+server:
+  port: "http"
+  logLevel: verbose`,
+			errors: []string{
+				`server.port: Invalid value: "http": must be a number between 1 and 65535`,
+				`server.logLevel: Unsupported value: "verbose"`,
+			},
+		},
+		{
+			name: "invalid tls",
+			config: `
+apiVersion: gitops.weave.works/v1alpha1
+kind: GitopsServerConfig
+tls:
+  certFile: /does/not/exist.crt
+  insecure: true
+  mtls: true`,
+			errors: []string{
+				"tls: Invalid value: \"\": certFile and keyFile must be set together",
+				"tls.mtls: Invalid value: true: cannot be used together with insecure",
+				`tls.certFile: Invalid value: "/does/not/exist.crt"`,
+			},
+		},
+		{
+			name: "partial oidc",
+			config: `
+apiVersion: gitops.weave.works/v1alpha1
+kind: GitopsServerConfig
+auth:
+  oidc:
+    issuerURL: dex`,
+			errors: []string{
+				"auth.oidc.clientID: Required value",
+				`auth.oidc.issuerURL: Invalid value: "dex": must be an absolute URL`,
+			},
+		},
+		{
+			name: "invalid clusters",
+			config: `
+apiVersion: gitops.weave.works/v1alpha1
+kind: GitopsServerConfig
+clusters:
+  - name: Default
+    server: https://a
+    secretRef: a
+  - name: leaf
+    server: https://b
+  - name: leaf
+    server: https://c
+    secretRef: c`,
+			errors: []string{
+				`clusters[0].name: Invalid value: "Default": is reserved`,
+				"clusters[1].secretRef: Required value",
+				`clusters[2].name: Duplicate value: "leaf"`,
+			},
+		},
+		{
+			name: "invalid access rules",
+			config: `
+apiVersion: gitops.weave.works/v1alpha1
+kind: GitopsServerConfig
+accessRules:
+  - resources: ["pods"]`,
+			errors: []string{"accessRules[0].verbs: Required value"},
+		},
+		{
+			name: "invalid helm repositories",
+			config: `
+apiVersion: gitops.weave.works/v1alpha1
+kind: GitopsServerConfig
+profiles:
+  helmRepositories: ["*", "flux-system/charts", "charts"]`,
+			errors: []string{`profiles.helmRepositories[2]: Invalid value: "charts"`},
+		},
+		{
+			name: "invalid profile cache backend",
+			config: `
+apiVersion: gitops.weave.works/v1alpha1
+kind: GitopsServerConfig
+profiles:
+  cacheBackend: redis`,
+			errors: []string{`profiles.cacheBackend: Unsupported value: "redis"`},
+		},
+		{
+			name: "invalid profile upgrade policy",
+			config: `
+apiVersion: gitops.weave.works/v1alpha1
+kind: GitopsServerConfig
+profiles:
+  watcher:
+    upgradePolicy: weekly`,
+			errors: []string{`profiles.watcher.upgradePolicy: Unsupported value: "weekly"`},
+		},
+		{
+			name: "invalid rate limits",
+			config: `
+apiVersion: gitops.weave.works/v1alpha1
+kind: GitopsServerConfig
+rateLimits:
+  global:
+    requests: 100
+    interval: -1s
+  fanOutRoutes: ["kustomizations"]`,
+			errors: []string{
+				`rateLimits.global.interval: Invalid value: "-1s": cannot be negative`,
+				`rateLimits.fanOutRoutes[0]: Invalid value: "kustomizations"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			_, err := config.Parse([]byte(tt.config))
+			g.Expect(err).To(HaveOccurred())
+
+			for _, msg := range tt.errors {
+				g.Expect(err.Error()).To(ContainSubstring(msg))
+			}
+		})
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dir, err := ioutil.TempDir("", "gitops-server-config")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.yaml")
+	g.Expect(ioutil.WriteFile(path, []byte(validConfig), 0600)).To(Succeed())
+
+	w, err := config.NewWatcher(logr.Discard(), path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	changes := make(chan *config.Config, 10)
+
+	w.OnChange(func(old, new *config.Config) {
+		changes <- new
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		_ = w.Start(ctx)
+	}()
+
+	// Give the watcher a moment to register the directory.
+	time.Sleep(100 * time.Millisecond)
+
+	updated := validConfig + "  WEAVE_GITOPS_FEATURE_TENANCY: \"true\"\n"
+	g.Expect(ioutil.WriteFile(path, []byte(updated), 0600)).To(Succeed())
+
+	var cfg *config.Config
+	g.Eventually(changes, 5*time.Second).Should(Receive(&cfg))
+	g.Expect(cfg.FeatureFlags).To(HaveKeyWithValue("WEAVE_GITOPS_FEATURE_TENANCY", "true"))
+	g.Expect(w.Config()).To(Equal(cfg))
+
+	// An invalid file is ignored and the previous configuration is kept.
+	g.Expect(ioutil.WriteFile(path, []byte("kind: Nope\n"), 0600)).To(Succeed())
+	g.Expect(w.Reload()).To(MatchError(ContainSubstring("invalid config file")))
+	g.Expect(w.Config()).To(Equal(cfg))
+	g.Consistently(changes, 200*time.Millisecond).ShouldNot(Receive())
+}
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/weaveworks/weave-gitops/core/clustersmngr"
+	"go.uber.org/zap/zapcore"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Validate checks the whole configuration and reports every problem found,
+// each prefixed with the path of the offending field.
+func (c *Config) Validate() error {
+	var errs field.ErrorList
+
+	switch c.APIVersion {
+	case APIVersion:
+	case "":
+		errs = append(errs, field.Required(field.NewPath("apiVersion"), ""))
+	default:
+		errs = append(errs, field.NotSupported(field.NewPath("apiVersion"), c.APIVersion, []string{APIVersion}))
+	}
+
+	if c.Kind != Kind {
+		errs = append(errs, field.NotSupported(field.NewPath("kind"), c.Kind, []string{Kind}))
+	}
+
+	errs = append(errs, c.Server.validate(field.NewPath("server"))...)
+	errs = append(errs, c.TLS.validate(field.NewPath("tls"))...)
+	errs = append(errs, c.Auth.OIDC.validate(field.NewPath("auth", "oidc"))...)
+	errs = append(errs, c.Profiles.validate(field.NewPath("profiles"))...)
+	errs = append(errs, c.RateLimits.validate(field.NewPath("rateLimits"))...)
+	errs = append(errs, validateClusters(c.Clusters, field.NewPath("clusters"))...)
+
+	rulesPath := field.NewPath("accessRules")
+
+	for i, rule := range c.AccessRules {
+		if len(rule.Verbs) == 0 {
+			errs = append(errs, field.Required(rulesPath.Index(i).Child("verbs"), ""))
+		}
+
+		if len(rule.Resources) == 0 {
+			errs = append(errs, field.Required(rulesPath.Index(i).Child("resources"), ""))
+		}
+	}
+
+	for name := range c.FeatureFlags {
+		if name == "" {
+			errs = append(errs, field.Invalid(field.NewPath("featureFlags"), name, "flag names cannot be empty"))
+		}
+	}
+
+	if c.FeatureFlagsConfigMap != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(c.FeatureFlagsConfigMap) {
+			errs = append(errs, field.Invalid(field.NewPath("featureFlagsConfigMap"), c.FeatureFlagsConfigMap, msg))
+		}
+	}
+
+	return errs.ToAggregate()
+}
+
+func (s Server) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if s.Port != "" {
+		if port, err := strconv.Atoi(s.Port); err != nil || validation.IsValidPortNum(port) != nil {
+			errs = append(errs, field.Invalid(path.Child("port"), s.Port, "must be a number between 1 and 65535"))
+		}
+	}
+
+	if s.LogLevel != "" {
+		if _, err := zapcore.ParseLevel(s.LogLevel); err != nil {
+			errs = append(errs, field.NotSupported(path.Child("logLevel"), s.LogLevel, []string{"debug", "info", "warn", "error"}))
+		}
+	}
+
+	if s.NotificationControllerAddress != "" {
+		errs = append(errs, validateURL(path.Child("notificationControllerAddress"), s.NotificationControllerAddress)...)
+	}
+
+	return errs
+}
+
+func (t TLS) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		errs = append(errs, field.Invalid(path, "", "certFile and keyFile must be set together"))
+	}
+
+	if t.Insecure && t.MTLS {
+		errs = append(errs, field.Invalid(path.Child("mtls"), t.MTLS, "cannot be used together with insecure"))
+	}
+
+	errs = append(errs, validateFile(path.Child("certFile"), t.CertFile)...)
+	errs = append(errs, validateFile(path.Child("keyFile"), t.KeyFile)...)
+	errs = append(errs, validateFile(path.Child("clientCAFile"), t.ClientCAFile)...)
+
+	return errs
+}
+
+func (o OIDC) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if o.IssuerURL == "" && o.ClientID == "" && o.ClientSecret == "" && o.RedirectURL == "" {
+		return nil
+	}
+
+	required := map[string]string{
+		"issuerURL":    o.IssuerURL,
+		"clientID":     o.ClientID,
+		"clientSecret": o.ClientSecret,
+		"redirectURL":  o.RedirectURL,
+	}
+
+	for _, name := range []string{"issuerURL", "clientID", "clientSecret", "redirectURL"} {
+		if required[name] == "" {
+			errs = append(errs, field.Required(path.Child(name), "all OIDC settings must be set when any of them is"))
+		}
+	}
+
+	if o.IssuerURL != "" {
+		errs = append(errs, validateURL(path.Child("issuerURL"), o.IssuerURL)...)
+	}
+
+	if o.RedirectURL != "" {
+		errs = append(errs, validateURL(path.Child("redirectURL"), o.RedirectURL)...)
+	}
+
+	if o.TokenDuration.Duration < 0 {
+		errs = append(errs, field.Invalid(path.Child("tokenDuration"), o.TokenDuration.String(), "cannot be negative"))
+	}
+
+	return errs
+}
+
+func (p Profiles) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	repoPath := path.Child("helmRepository")
+
+	if p.HelmRepository.Name != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(p.HelmRepository.Name) {
+			errs = append(errs, field.Invalid(repoPath.Child("name"), p.HelmRepository.Name, msg))
+		}
+	}
+
+	if p.HelmRepository.Namespace != "" {
+		for _, msg := range validation.IsDNS1123Label(p.HelmRepository.Namespace) {
+			errs = append(errs, field.Invalid(repoPath.Child("namespace"), p.HelmRepository.Namespace, msg))
+		}
+	}
+
+	for i, ref := range p.HelmRepositories {
+		if ref == "*" {
+			continue
+		}
+
+		parts := strings.Split(ref, "/")
+		if len(parts) != 2 || len(validation.IsDNS1123Label(parts[0])) > 0 || len(validation.IsDNS1123Subdomain(parts[1])) > 0 {
+			errs = append(errs, field.Invalid(path.Child("helmRepositories").Index(i), ref, `must be "*" or namespace/name`))
+		}
+	}
+
+	switch p.CacheBackend {
+	case "", "filesystem", "memory", "configmap":
+	default:
+		errs = append(errs, field.NotSupported(path.Child("cacheBackend"), p.CacheBackend, []string{"filesystem", "memory", "configmap"}))
+	}
+
+	if p.CacheNamespace != "" {
+		for _, msg := range validation.IsDNS1123Label(p.CacheNamespace) {
+			errs = append(errs, field.Invalid(path.Child("cacheNamespace"), p.CacheNamespace, msg))
+		}
+	}
+
+	switch p.Watcher.UpgradePolicy {
+	case "", "patch", "minor", "major":
+	default:
+		errs = append(errs, field.NotSupported(path.Child("watcher", "upgradePolicy"), p.Watcher.UpgradePolicy, []string{"patch", "minor", "major"}))
+	}
+
+	if p.Watcher.Port != 0 {
+		for _, msg := range validation.IsValidPortNum(p.Watcher.Port) {
+			errs = append(errs, field.Invalid(path.Child("watcher", "port"), p.Watcher.Port, msg))
+		}
+	}
+
+	return errs
+}
+
+func (r RateLimits) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	limits := map[string]RateLimit{
+		"global":       r.Global,
+		"perPrincipal": r.PerPrincipal,
+		"fanOut":       r.FanOut,
+	}
+
+	for _, name := range []string{"global", "perPrincipal", "fanOut"} {
+		if limits[name].Interval.Duration < 0 {
+			errs = append(errs, field.Invalid(path.Child(name, "interval"), limits[name].Interval.Duration.String(), "cannot be negative"))
+		}
+	}
+
+	for i, route := range r.FanOutRoutes {
+		if !strings.HasPrefix(route, "/v1/") {
+			errs = append(errs, field.Invalid(path.Child("fanOutRoutes").Index(i), route, "must be an API path starting with /v1/"))
+		}
+	}
+
+	return errs
+}
+
+func validateClusters(clusters []Cluster, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	seen := map[string]bool{}
+
+	for i, c := range clusters {
+		p := path.Index(i)
+
+		switch {
+		case c.Name == "":
+			errs = append(errs, field.Required(p.Child("name"), ""))
+		case c.Name == clustersmngr.DefaultCluster:
+			errs = append(errs, field.Invalid(p.Child("name"), c.Name, "is reserved for the cluster the server runs in"))
+		case seen[c.Name]:
+			errs = append(errs, field.Duplicate(p.Child("name"), c.Name))
+		}
+
+		seen[c.Name] = true
+
+		if c.Server == "" {
+			errs = append(errs, field.Required(p.Child("server"), ""))
+		} else {
+			errs = append(errs, validateURL(p.Child("server"), c.Server)...)
+		}
+
+		if c.SecretRef == "" {
+			errs = append(errs, field.Required(p.Child("secretRef"), "the Secret holding the cluster credentials"))
+		}
+	}
+
+	return errs
+}
+
+func validateURL(path *field.Path, value string) field.ErrorList {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return field.ErrorList{field.Invalid(path, value, "must be an absolute URL")}
+	}
+
+	return nil
+}
+
+func validateFile(path *field.Path, name string) field.ErrorList {
+	if name == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(name); err != nil {
+		return field.ErrorList{field.Invalid(path, name, err.Error())}
+	}
+
+	return nil
+}
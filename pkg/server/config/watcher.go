@@ -0,0 +1,154 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+)
+
+// ChangeHandler is called with the previous and the new configuration after
+// the file has been reloaded successfully.
+type ChangeHandler func(old, new *Config)
+
+// Watcher keeps track of a configuration file and reloads it when it changes
+// on disk. An invalid file is logged and ignored, the last valid
+// configuration stays in effect.
+type Watcher struct {
+	path string
+	log  logr.Logger
+
+	mu       sync.RWMutex
+	current  *Config
+	raw      []byte
+	handlers []ChangeHandler
+}
+
+// NewWatcher loads the configuration file at path. It fails if the file is
+// missing or invalid.
+func NewWatcher(log logr.Logger, path string) (*Watcher, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	cfg, err := Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return &Watcher{
+		path:    path,
+		log:     log.WithName("config-watcher"),
+		current: cfg,
+		raw:     raw,
+	}, nil
+}
+
+// Config returns the configuration currently in effect.
+func (w *Watcher) Config() *Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.current
+}
+
+// OnChange registers a handler called after every successful reload.
+func (w *Watcher) OnChange(h ChangeHandler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.handlers = append(w.handlers, h)
+}
+
+// Reload reads the file again and, if its content changed and is valid,
+// makes it the current configuration and notifies the handlers.
+func (w *Watcher) Reload() error {
+	raw, err := ioutil.ReadFile(w.path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	w.mu.Lock()
+
+	if bytes.Equal(raw, w.raw) {
+		w.mu.Unlock()
+		return nil
+	}
+
+	cfg, err := Parse(raw)
+	if err != nil {
+		w.mu.Unlock()
+		return fmt.Errorf("invalid config file %s: %w", w.path, err)
+	}
+
+	old := w.current
+	w.current = cfg
+	w.raw = raw
+	handlers := append([]ChangeHandler{}, w.handlers...)
+
+	w.mu.Unlock()
+
+	for _, h := range handlers {
+		h(old, cfg)
+	}
+
+	return nil
+}
+
+// Start watches the directory of the file until ctx is done. The directory
+// is watched rather than the file itself so that the atomic symlink swaps
+// used for mounted ConfigMaps are noticed too.
+func (w *Watcher) Start(ctx context.Context) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer fsw.Close()
+
+	if err := fsw.Add(filepath.Dir(w.path)); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", w.path, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+
+			if !w.affects(event) {
+				continue
+			}
+
+			if err := w.Reload(); err != nil {
+				w.log.Error(err, "ignoring config file change, the previous configuration stays in effect")
+			}
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+
+			w.log.Error(err, "error watching config file")
+		}
+	}
+}
+
+func (w *Watcher) affects(event fsnotify.Event) bool {
+	if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+		return false
+	}
+
+	name := filepath.Base(event.Name)
+
+	// Kubernetes updates mounted volumes by swapping the ..data symlink.
+	return name == filepath.Base(w.path) || strings.HasPrefix(name, "..")
+}
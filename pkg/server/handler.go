@@ -14,6 +14,7 @@ import (
 	pbprofiles "github.com/weaveworks/weave-gitops/pkg/api/profiles"
 	"github.com/weaveworks/weave-gitops/pkg/server/auth"
 	"github.com/weaveworks/weave-gitops/pkg/server/middleware"
+	"github.com/weaveworks/weave-gitops/pkg/server/ratelimit"
 )
 
 const (
@@ -36,6 +37,12 @@ type Config struct {
 	ProfilesConfig   ProfilesConfig
 	CoreServerConfig core.CoreServerConfig
 	AuthServer       *auth.AuthServer
+	// ClustersFetcher lists the clusters users can access. Defaults to the
+	// cluster the server is running in.
+	ClustersFetcher clustersmngr.ClusterFetcher
+	// RateLimiter limits the API requests of each principal. Requests are not
+	// limited when it is nil.
+	RateLimiter *ratelimit.Limiter
 }
 
 func NewHandlers(ctx context.Context, log logr.Logger, cfg *Config) (http.Handler, error) {
@@ -43,12 +50,26 @@ func NewHandlers(ctx context.Context, log logr.Logger, cfg *Config) (http.Handle
 	httpHandler := middleware.WithLogging(log, mux)
 
 	if AuthEnabled() {
-		clustersFetcher, err := clustersmngr.NewSingleClusterFetcher(cfg.CoreServerConfig.RestCfg)
-		if err != nil {
-			return nil, fmt.Errorf("failed fetching clusters: %w", err)
+		clustersFetcher := cfg.ClustersFetcher
+		if clustersFetcher == nil {
+			fetcher, err := clustersmngr.NewSingleClusterFetcher(cfg.CoreServerConfig.RestCfg)
+			if err != nil {
+				return nil, fmt.Errorf("failed fetching clusters: %w", err)
+			}
+
+			clustersFetcher = fetcher
 		}
 
 		httpHandler = clustersmngr.WithClustersClient(clustersFetcher, httpHandler)
+	}
+
+	if cfg.RateLimiter != nil {
+		// Requests are limited once the principal is known, before clients to
+		// every cluster are created for them.
+		httpHandler = cfg.RateLimiter.Handler(httpHandler)
+	}
+
+	if AuthEnabled() {
 		httpHandler = auth.WithAPIAuth(httpHandler, cfg.AuthServer, PublicRoutes)
 	}
 
@@ -29,3 +29,8 @@ func (c *gitProviderClient) GetProvider(repoUrl gitproviders.RepoURL, getAccount
 
 	return provider, nil
 }
+
+// GetToken returns the auth token of the client
+func (c *gitProviderClient) GetToken(repoUrl gitproviders.RepoURL) (string, error) {
+	return c.token, nil
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"errors"
+
 	"github.com/fluxcd/go-git-providers/gitprovider"
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 
@@ -38,7 +39,6 @@ var _ = Describe("Get git provider", func() {
 		provider, err := client.GetProvider(repoUrl, fakeAccountGetterSuccess)
 
 		Expect(err).To(BeNil())
-		expectedProvider, _ := gitproviders.New(gitproviders.Config{Provider: repoUrl.Provider(), Token: fakeToken}, repoUrl.Owner(), fakeAccountGetterSuccess)
-		Expect(provider).To(Equal(expectedProvider))
+		Expect(provider.GetProviderDomain()).To(Equal("github.com"))
 	})
 })
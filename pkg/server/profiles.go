@@ -7,19 +7,30 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"sort"
 	"strings"
 
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
 	sourcev1beta1 "github.com/fluxcd/source-controller/api/v1beta1"
 	"github.com/go-logr/logr"
 	grpcruntime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/weaveworks/weave-gitops/core/clustersmngr"
+	"github.com/weaveworks/weave-gitops/core/logger"
 	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
+	"github.com/weaveworks/weave-gitops/pkg/helm"
 	"github.com/weaveworks/weave-gitops/pkg/helm/watcher/cache"
 	"github.com/weaveworks/weave-gitops/pkg/kube"
+	serverauth "github.com/weaveworks/weave-gitops/pkg/server/auth"
 	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -27,9 +38,14 @@ const (
 	JsonType        = "application/json"
 )
 
+// AllHelmRepositories can be given in place of a HelmRepository to serve the
+// profiles of every HelmRepository the user can read.
+const AllHelmRepositories = "*"
+
 type ProfilesConfig struct {
 	helmRepoNamespace string
 	helmRepoName      string
+	helmRepositories  []string
 	helmCache         cache.Cache
 	clusterConfig     kube.ClusterConfig
 }
@@ -43,14 +59,30 @@ func NewProfilesConfig(clusterConfig kube.ClusterConfig, helmCache cache.Cache,
 	}
 }
 
+// WithHelmRepositories serves the profiles of several HelmRepositories
+// instead of the one given to NewProfilesConfig. Repositories are given as
+// "namespace/name", or AllHelmRepositories.
+func (c ProfilesConfig) WithHelmRepositories(repositories []string) ProfilesConfig {
+	c.helmRepositories = repositories
+
+	return c
+}
+
 type ProfilesServer struct {
 	pb.UnimplementedProfilesServer
 
 	Log               logr.Logger
 	HelmRepoName      string
 	HelmRepoNamespace string
-	HelmCache         cache.Cache
-	ClientGetter      kube.ClientGetter
+	// HelmRepositories lists the HelmRepositories whose profiles are served,
+	// as "namespace/name" or AllHelmRepositories. Only the HelmRepository
+	// named by HelmRepoName and HelmRepoNamespace is served when it is empty.
+	HelmRepositories []string
+	HelmCache        cache.Cache
+	ClientGetter     kube.ClientGetter
+	// ClusterName is the name of the cluster HelmReleases are listed from
+	// when the request has no clusters client.
+	ClusterName string
 }
 
 func NewProfilesServer(log logr.Logger, config ProfilesConfig) pb.ProfilesServer {
@@ -61,8 +93,10 @@ func NewProfilesServer(log logr.Logger, config ProfilesConfig) pb.ProfilesServer
 		Log:               log.WithName("profiles-server"),
 		HelmRepoNamespace: config.helmRepoNamespace,
 		HelmRepoName:      config.helmRepoName,
+		HelmRepositories:  config.helmRepositories,
 		HelmCache:         config.helmCache,
 		ClientGetter:      clientGetter,
+		ClusterName:       config.clusterConfig.ClusterName,
 	}
 }
 
@@ -72,6 +106,10 @@ func (s *ProfilesServer) GetProfiles(ctx context.Context, msg *pb.GetProfilesReq
 		return nil, fmt.Errorf("failed to get a Kubernetes client: %w", err)
 	}
 
+	if len(s.HelmRepositories) > 0 {
+		return s.getProfilesFromRepositories(ctx, kubeClient, msg)
+	}
+
 	helmRepo := &sourcev1beta1.HelmRepository{}
 	err = kubeClient.Get(ctx, client.ObjectKey{
 		Name:      s.HelmRepoName,
@@ -109,12 +147,203 @@ func (s *ProfilesServer) GetProfiles(ctx context.Context, msg *pb.GetProfilesReq
 	}, nil
 }
 
+// getProfilesFromRepositories aggregates the profiles of every configured
+// HelmRepository the user can read. HelmRepositories pointing at the same
+// chart repository URL only contribute their profiles once.
+func (s *ProfilesServer) getProfilesFromRepositories(ctx context.Context, kubeClient client.Client, msg *pb.GetProfilesRequest) (*pb.GetProfilesResponse, error) {
+	helmRepos, err := s.listHelmRepositories(ctx, kubeClient, msg.HelmRepositoryNamespace, msg.HelmRepositoryName)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := []*pb.Profile{}
+	seen := map[string]bool{}
+
+	for _, helmRepo := range helmRepos {
+		log := s.Log.WithValues("repository", client.ObjectKeyFromObject(&helmRepo))
+
+		ps, err := s.HelmCache.ListProfiles(logr.NewContext(ctx, log), helmRepo.Namespace, helmRepo.Name)
+		if err != nil {
+			// The watcher has not scanned this HelmRepository yet.
+			if errors.Is(err, os.ErrNotExist) {
+				log.V(logger.LogLevelDebug).Info("HelmRepository not cached yet")
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to scan HelmRepository %q/%q for charts: %w", helmRepo.Namespace, helmRepo.Name, err)
+		}
+
+		for _, p := range ps {
+			key := helmRepo.Spec.URL + "/" + p.Name
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+
+			// Profiles cached before they were labelled with their repository.
+			if p.HelmRepository == nil {
+				p.HelmRepository = &pb.HelmRepository{Name: helmRepo.Name, Namespace: helmRepo.Namespace}
+			}
+
+			profiles = append(profiles, p)
+		}
+	}
+
+	return &pb.GetProfilesResponse{
+		Profiles: profiles,
+	}, nil
+}
+
+// listHelmRepositories returns the configured HelmRepositories the user can
+// read, optionally filtered by namespace and name, sorted by namespace and
+// name.
+func (s *ProfilesServer) listHelmRepositories(ctx context.Context, kubeClient client.Client, namespace, name string) ([]sourcev1beta1.HelmRepository, error) {
+	var result []sourcev1beta1.HelmRepository
+
+	matches := func(key types.NamespacedName) bool {
+		return (namespace == "" || key.Namespace == namespace) && (name == "" || key.Name == name)
+	}
+
+	for _, ref := range s.HelmRepositories {
+		if ref == AllHelmRepositories {
+			items, err := s.listAllHelmRepositories(ctx, kubeClient, namespace)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, hr := range items {
+				if matches(client.ObjectKeyFromObject(&hr)) {
+					result = append(result, hr)
+				}
+			}
+
+			continue
+		}
+
+		key, err := parseHelmRepositoryRef(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		if !matches(key) {
+			continue
+		}
+
+		hr := sourcev1beta1.HelmRepository{}
+		if err := kubeClient.Get(ctx, key, &hr); err != nil {
+			if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+				s.Log.V(logger.LogLevelDebug).Info("skipping HelmRepository", "repository", key, "reason", err.Error())
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to get HelmRepository %q/%q: %w", key.Namespace, key.Name, err)
+		}
+
+		result = append(result, hr)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+
+		return result[i].Name < result[j].Name
+	})
+
+	// The same HelmRepository may be listed both explicitly and through
+	// AllHelmRepositories.
+	deduped := result[:0]
+
+	for i, hr := range result {
+		if i > 0 && hr.Namespace == result[i-1].Namespace && hr.Name == result[i-1].Name {
+			continue
+		}
+
+		deduped = append(deduped, hr)
+	}
+
+	return deduped, nil
+}
+
+// listAllHelmRepositories lists the HelmRepositories of a namespace, or of
+// every namespace when empty. Users who can't list them cluster-wide get the
+// ones of the namespaces they can list them in.
+func (s *ProfilesServer) listAllHelmRepositories(ctx context.Context, kubeClient client.Client, namespace string) ([]sourcev1beta1.HelmRepository, error) {
+	list := &sourcev1beta1.HelmRepositoryList{}
+
+	var opts []client.ListOption
+	if namespace != "" {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+
+	err := kubeClient.List(ctx, list, opts...)
+	if err == nil {
+		return list.Items, nil
+	}
+
+	if !apierrors.IsForbidden(err) {
+		return nil, fmt.Errorf("failed to list HelmRepositories: %w", err)
+	}
+
+	if namespace != "" {
+		s.Log.V(logger.LogLevelDebug).Info("skipping HelmRepositories", "namespace", namespace, "reason", err.Error())
+		return nil, nil
+	}
+
+	// The user may not be allowed to list the namespaces, the server is.
+	serverClient, err := s.ClientGetter.Client(serverauth.WithPrincipal(ctx, nil))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a Kubernetes client: %w", err)
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := serverClient.List(ctx, namespaces); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	var result []sourcev1beta1.HelmRepository
+
+	for _, ns := range namespaces.Items {
+		list := &sourcev1beta1.HelmRepositoryList{}
+		if err := kubeClient.List(ctx, list, client.InNamespace(ns.Name)); err != nil {
+			if apierrors.IsForbidden(err) {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to list HelmRepositories in namespace %q: %w", ns.Name, err)
+		}
+
+		result = append(result, list.Items...)
+	}
+
+	return result, nil
+}
+
+func parseHelmRepositoryRef(ref string) (types.NamespacedName, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return types.NamespacedName{}, fmt.Errorf("invalid HelmRepository %q, expected namespace/name", ref)
+	}
+
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+}
+
 func (s *ProfilesServer) GetProfileValues(ctx context.Context, msg *pb.GetProfileValuesRequest) (*httpbody.HttpBody, error) {
 	kubeClient, err := s.ClientGetter.Client(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get a Kubernetes client: %w", err)
 	}
 
+	if len(s.HelmRepositories) > 0 {
+		data, err := s.getProfileValuesFromRepositories(ctx, kubeClient, msg)
+		if err != nil {
+			return nil, err
+		}
+
+		return valuesResponse(ctx, data)
+	}
+
 	helmRepo := &sourcev1beta1.HelmRepository{}
 	err = kubeClient.Get(ctx, client.ObjectKey{
 		Name:      s.HelmRepoName,
@@ -148,6 +377,39 @@ func (s *ProfilesServer) GetProfileValues(ctx context.Context, msg *pb.GetProfil
 		return nil, fmt.Errorf("failed to retrieve values file from Helm chart '%s' (%s): %w", msg.ProfileName, msg.ProfileVersion, err)
 	}
 
+	return valuesResponse(ctx, data)
+}
+
+// getProfileValuesFromRepositories returns the values of the first
+// HelmRepository, in namespace and name order, with the requested profile
+// version. Requests can name the HelmRepository to pick a specific one.
+func (s *ProfilesServer) getProfileValuesFromRepositories(ctx context.Context, kubeClient client.Client, msg *pb.GetProfileValuesRequest) ([]byte, error) {
+	helmRepos, err := s.listHelmRepositories(ctx, kubeClient, msg.HelmRepositoryNamespace, msg.HelmRepositoryName)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+
+	for _, helmRepo := range helmRepos {
+		log := s.Log.WithValues("repository", client.ObjectKeyFromObject(&helmRepo))
+
+		data, err := s.HelmCache.GetProfileValues(logr.NewContext(ctx, log), helmRepo.Namespace, helmRepo.Name, msg.ProfileName, msg.ProfileVersion)
+		if err == nil {
+			return data, nil
+		}
+
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("no HelmRepository found")
+	}
+
+	return nil, fmt.Errorf("failed to retrieve values file from Helm chart '%s' (%s): %w", msg.ProfileName, msg.ProfileVersion, lastErr)
+}
+
+func valuesResponse(ctx context.Context, data []byte) (*httpbody.HttpBody, error) {
 	var acceptHeader string
 
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
@@ -175,3 +437,285 @@ func (s *ProfilesServer) GetProfileValues(ctx context.Context, msg *pb.GetProfil
 		Data:        res,
 	}, nil
 }
+
+func (s *ProfilesServer) GetProfileValuesSchema(ctx context.Context, msg *pb.GetProfileValuesSchemaRequest) (*pb.GetProfileValuesSchemaResponse, error) {
+	schema, err := s.getProfileValuesSchema(ctx, msg.ProfileName, msg.ProfileVersion, msg.HelmRepositoryNamespace, msg.HelmRepositoryName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetProfileValuesSchemaResponse{Schema: string(schema)}, nil
+}
+
+// ValidateProfileValues checks values, on top of the default values of the
+// profile, against its values schema. Values that do not match are reported in
+// the response rather than as an error.
+func (s *ProfilesServer) ValidateProfileValues(ctx context.Context, msg *pb.ValidateProfileValuesRequest) (*pb.ValidateProfileValuesResponse, error) {
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(msg.Values), &values); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to parse values: %s", err)
+	}
+
+	schema, err := s.getProfileValuesSchema(ctx, msg.ProfileName, msg.ProfileVersion, msg.HelmRepositoryNamespace, msg.HelmRepositoryName)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults, err := s.readProfileFile(ctx, "values file", msg.ProfileName, msg.ProfileVersion, msg.HelmRepositoryNamespace, msg.HelmRepositoryName, s.HelmCache.GetProfileValues)
+	if err != nil {
+		return nil, err
+	}
+
+	err = helm.ValidateValues(schema, defaults, values)
+
+	var verr *helm.ValuesValidationError
+	if errors.As(err, &verr) {
+		res := &pb.ValidateProfileValuesResponse{}
+		for _, e := range verr.Errors {
+			res.Errors = append(res.Errors, &pb.ValuesError{Path: e.Path, Message: e.Message})
+		}
+
+		return res, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ValidateProfileValuesResponse{Valid: true}, nil
+}
+
+// getProfileValuesSchema returns the values schema of a profile version, from
+// the first HelmRepository that has the version. It returns nil when the
+// version has no schema.
+func (s *ProfilesServer) getProfileValuesSchema(ctx context.Context, profileName, profileVersion, helmRepoNamespace, helmRepoName string) ([]byte, error) {
+	return s.readProfileFile(ctx, "values schema", profileName, profileVersion, helmRepoNamespace, helmRepoName, s.HelmCache.GetProfileValuesSchema)
+}
+
+// readProfileFile reads a file of a profile version from the cache with read,
+// from the first HelmRepository that has the version.
+func (s *ProfilesServer) readProfileFile(ctx context.Context, file, profileName, profileVersion, helmRepoNamespace, helmRepoName string,
+	read func(ctx context.Context, helmRepoNamespace, helmRepoName, profileName, profileVersion string) ([]byte, error)) ([]byte, error) {
+	kubeClient, err := s.ClientGetter.Client(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a Kubernetes client: %w", err)
+	}
+
+	var helmRepos []sourcev1beta1.HelmRepository
+
+	if len(s.HelmRepositories) > 0 {
+		helmRepos, err = s.listHelmRepositories(ctx, kubeClient, helmRepoNamespace, helmRepoName)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		helmRepo := sourcev1beta1.HelmRepository{}
+		if err := kubeClient.Get(ctx, client.ObjectKey{Name: s.HelmRepoName, Namespace: s.HelmRepoNamespace}, &helmRepo); err != nil {
+			return nil, fmt.Errorf("failed to get HelmRepository %q/%q: %w", s.HelmRepoNamespace, s.HelmRepoName, err)
+		}
+
+		helmRepos = append(helmRepos, helmRepo)
+	}
+
+	lastErr := errors.New("no HelmRepository found")
+
+	for _, helmRepo := range helmRepos {
+		log := s.Log.WithValues("repository", client.ObjectKeyFromObject(&helmRepo))
+
+		data, err := read(logr.NewContext(ctx, log), helmRepo.Namespace, helmRepo.Name, profileName, profileVersion)
+		if err == nil {
+			return data, nil
+		}
+
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("failed to retrieve %s from Helm chart '%s' (%s): %w", file, profileName, profileVersion, lastErr)
+}
+
+// ListProfileUpgrades lists the HelmReleases the user can read in every
+// cluster that run an older version of a profile served by this server.
+func (s *ProfilesServer) ListProfileUpgrades(ctx context.Context, msg *pb.ListProfileUpgradesRequest) (*pb.ListProfileUpgradesResponse, error) {
+	policy, err := helm.ParseUpgradePolicy(msg.Policy)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	kubeClient, err := s.ClientGetter.Client(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a Kubernetes client: %w", err)
+	}
+
+	served, err := s.servedHelmRepositories(ctx, kubeClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []client.ListOption
+	if msg.Namespace != "" {
+		opts = append(opts, client.InNamespace(msg.Namespace))
+	}
+
+	releases, err := s.listHelmReleases(ctx, kubeClient, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := map[types.NamespacedName]map[string]*pb.Profile{}
+	upgrades := []*pb.ProfileUpgrade{}
+
+	for i := range releases {
+		hr := &releases[i].helmRelease
+
+		repo, ok := helm.ReleaseHelmRepository(hr)
+		if !ok || !served[repo] {
+			continue
+		}
+
+		repoProfiles, ok := profiles[repo]
+		if !ok {
+			repoProfiles, err = s.cachedProfiles(ctx, repo)
+			if err != nil {
+				return nil, err
+			}
+
+			profiles[repo] = repoProfiles
+		}
+
+		profile, ok := repoProfiles[hr.Spec.Chart.Spec.Chart]
+		if !ok {
+			continue
+		}
+
+		releasePolicy := policy
+
+		if annotation, ok := hr.Annotations[helm.UpgradePolicyAnnotation]; ok {
+			if p, err := helm.ParseUpgradePolicy(annotation); err == nil {
+				releasePolicy = p
+			}
+		}
+
+		installed := helm.InstalledVersion(hr)
+
+		versions, err := helm.AvailableUpgrades(installed, profile.AvailableVersions, releasePolicy)
+		if err != nil {
+			s.Log.V(logger.LogLevelDebug).Info("skipping HelmRelease", "release", client.ObjectKeyFromObject(hr), "reason", err.Error())
+			continue
+		}
+
+		if len(versions) == 0 {
+			continue
+		}
+
+		upgradeType, err := helm.UpgradeType(installed, versions[0])
+		if err != nil {
+			return nil, err
+		}
+
+		upgrades = append(upgrades, &pb.ProfileUpgrade{
+			ClusterName:          releases[i].cluster,
+			HelmReleaseName:      hr.Name,
+			HelmReleaseNamespace: hr.Namespace,
+			ProfileName:          profile.Name,
+			HelmRepository:       &pb.HelmRepository{Name: repo.Name, Namespace: repo.Namespace},
+			InstalledVersion:     installed,
+			LatestVersion:        versions[0],
+			UpgradeType:          string(upgradeType),
+			AvailableVersions:    versions,
+		})
+	}
+
+	return &pb.ListProfileUpgradesResponse{
+		Upgrades: upgrades,
+	}, nil
+}
+
+// clusterHelmRelease is a HelmRelease and the name of its cluster.
+type clusterHelmRelease struct {
+	cluster     string
+	helmRelease helmv2beta1.HelmRelease
+}
+
+// listHelmReleases lists the HelmReleases the user can read in every cluster
+// of the clusters client of the request, or in the cluster of the server with
+// kubeClient when the request has none.
+func (s *ProfilesServer) listHelmReleases(ctx context.Context, kubeClient client.Client, opts ...client.ListOption) ([]clusterHelmRelease, error) {
+	var releases []clusterHelmRelease
+
+	clustersClient := clustersmngr.ClientFromCtx(ctx)
+	if clustersClient == nil {
+		list := &helmv2beta1.HelmReleaseList{}
+		if err := kubeClient.List(ctx, list, opts...); err != nil {
+			return nil, fmt.Errorf("failed to list HelmReleases: %w", err)
+		}
+
+		for _, hr := range list.Items {
+			releases = append(releases, clusterHelmRelease{cluster: s.ClusterName, helmRelease: hr})
+		}
+
+		return releases, nil
+	}
+
+	clist := clustersmngr.NewClusteredList(func() client.ObjectList {
+		return &helmv2beta1.HelmReleaseList{}
+	})
+
+	if err := clustersClient.ClusteredList(ctx, clist, opts...); err != nil {
+		return nil, err
+	}
+
+	for cluster, l := range clist.Lists() {
+		list, ok := l.(*helmv2beta1.HelmReleaseList)
+		if !ok {
+			continue
+		}
+
+		for _, hr := range list.Items {
+			releases = append(releases, clusterHelmRelease{cluster: cluster, helmRelease: hr})
+		}
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].cluster < releases[j].cluster
+	})
+
+	return releases, nil
+}
+
+// servedHelmRepositories returns the HelmRepositories whose profiles are
+// served to the user.
+func (s *ProfilesServer) servedHelmRepositories(ctx context.Context, kubeClient client.Client) (map[types.NamespacedName]bool, error) {
+	if len(s.HelmRepositories) == 0 {
+		return map[types.NamespacedName]bool{{Namespace: s.HelmRepoNamespace, Name: s.HelmRepoName}: true}, nil
+	}
+
+	helmRepos, err := s.listHelmRepositories(ctx, kubeClient, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	served := map[types.NamespacedName]bool{}
+	for i := range helmRepos {
+		served[client.ObjectKeyFromObject(&helmRepos[i])] = true
+	}
+
+	return served, nil
+}
+
+// cachedProfiles returns the cached profiles of a HelmRepository by name,
+// none when it has not been scanned yet.
+func (s *ProfilesServer) cachedProfiles(ctx context.Context, repo types.NamespacedName) (map[string]*pb.Profile, error) {
+	log := s.Log.WithValues("repository", repo)
+
+	ps, err := s.HelmCache.ListProfiles(logr.NewContext(ctx, log), repo.Namespace, repo.Name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to scan HelmRepository %q/%q for charts: %w", repo.Namespace, repo.Name, err)
+	}
+
+	result := map[string]*pb.Profile{}
+	for _, p := range ps {
+		result[p.Name] = p
+	}
+
+	return result, nil
+}
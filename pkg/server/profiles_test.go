@@ -4,24 +4,35 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
 	sourcev1beta1 "github.com/fluxcd/source-controller/api/v1beta1"
 	grpcruntime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/weaveworks/weave-gitops/core/clustersmngr"
 	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
+	"github.com/weaveworks/weave-gitops/pkg/helm"
 	"github.com/weaveworks/weave-gitops/pkg/helm/watcher/cache/cachefakes"
 	"github.com/weaveworks/weave-gitops/pkg/kube/kubefakes"
 	"github.com/weaveworks/weave-gitops/pkg/server"
+	serverauth "github.com/weaveworks/weave-gitops/pkg/server/auth"
 	"github.com/weaveworks/weave-gitops/pkg/testutils"
 )
 
@@ -38,7 +49,9 @@ var _ = Describe("ProfilesServer", func() {
 	BeforeEach(func() {
 		scheme := runtime.NewScheme()
 		schemeBuilder := runtime.SchemeBuilder{
+			corev1.AddToScheme,
 			sourcev1beta1.AddToScheme,
+			helmv2beta1.AddToScheme,
 		}
 		Expect(schemeBuilder.AddToScheme(scheme)).To(Succeed())
 
@@ -181,4 +194,310 @@ var _ = Describe("ProfilesServer", func() {
 			})
 		})
 	})
+
+	Describe("GetProfileValuesSchema", func() {
+		BeforeEach(func() {
+			Expect(kubeClient.Create(context.TODO(), helmRepo)).To(Succeed())
+		})
+
+		It("returns the values schema of the profile version", func() {
+			fakeCache.GetProfileValuesSchemaReturns([]byte(`{"type":"object"}`), nil)
+			resp, err := s.GetProfileValuesSchema(context.TODO(), &pb.GetProfileValuesSchemaRequest{
+				ProfileName:    profileName,
+				ProfileVersion: profileVersion,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Schema).To(Equal(`{"type":"object"}`))
+
+			_, namespace, name, profile, version := fakeCache.GetProfileValuesSchemaArgsForCall(0)
+			Expect([]string{namespace, name, profile, version}).To(Equal([]string{"default", "helmrepo", profileName, profileVersion}))
+		})
+
+		It("errors when the profile version is not cached", func() {
+			fakeCache.GetProfileValuesSchemaReturns(nil, fmt.Errorf("err"))
+			_, err := s.GetProfileValuesSchema(context.TODO(), &pb.GetProfileValuesSchemaRequest{
+				ProfileName:    profileName,
+				ProfileVersion: profileVersion,
+			})
+			Expect(err).To(MatchError("failed to retrieve values schema from Helm chart 'observability' (latest): err"))
+		})
+	})
+
+	Describe("ValidateProfileValues", func() {
+		BeforeEach(func() {
+			Expect(kubeClient.Create(context.TODO(), helmRepo)).To(Succeed())
+			fakeCache.GetProfileValuesSchemaReturns([]byte(`{"type":"object","properties":{"replicaCount":{"type":"integer"}}}`), nil)
+		})
+
+		It("accepts values matching the schema", func() {
+			resp, err := s.ValidateProfileValues(context.TODO(), &pb.ValidateProfileValuesRequest{
+				ProfileName:    profileName,
+				ProfileVersion: profileVersion,
+				Values:         "replicaCount: 2\n",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Valid).To(BeTrue())
+		})
+
+		It("leaves the values required by the schema to the defaults of the profile", func() {
+			fakeCache.GetProfileValuesSchemaReturns([]byte(`{"type":"object","required":["replicaCount","image"],"properties":{"replicaCount":{"type":"integer"}}}`), nil)
+			fakeCache.GetProfileValuesReturns([]byte("replicaCount: 1\nimage: podinfo\n"), nil)
+
+			resp, err := s.ValidateProfileValues(context.TODO(), &pb.ValidateProfileValuesRequest{
+				ProfileName:    profileName,
+				ProfileVersion: profileVersion,
+				Values:         "replicaCount: 2\n",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Valid).To(BeTrue())
+		})
+
+		It("reports the values that do not match the schema", func() {
+			resp, err := s.ValidateProfileValues(context.TODO(), &pb.ValidateProfileValuesRequest{
+				ProfileName:    profileName,
+				ProfileVersion: profileVersion,
+				Values:         `{"replicaCount": "two"}`,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Valid).To(BeFalse())
+			Expect(resp.Errors).To(HaveLen(1))
+			Expect(resp.Errors[0].Path).To(Equal("$.replicaCount"))
+			Expect(resp.Errors[0].Message).To(Equal("Invalid type. Expected: integer, given: string"))
+		})
+
+		It("rejects values that cannot be parsed", func() {
+			_, err := s.ValidateProfileValues(context.TODO(), &pb.ValidateProfileValuesRequest{Values: "- not a map"})
+			Expect(err).To(MatchError(ContainSubstring("failed to parse values")))
+		})
+	})
+
+	Describe("ListProfileUpgrades", func() {
+		var repoRef types.NamespacedName
+
+		BeforeEach(func() {
+			repoRef = types.NamespacedName{Name: "helmrepo", Namespace: "default"}
+			s.ClusterName = "management"
+			fakeCache.ListProfilesReturns([]*pb.Profile{
+				{Name: "podinfo", AvailableVersions: []string{"6.0.0", "6.0.1", "6.1.0", "7.0.0"}},
+			}, nil)
+
+			patchOnly := helm.MakeHelmRelease("podinfo", "6.0.0", "staging", "apps", repoRef)
+			patchOnly.Annotations = map[string]string{helm.UpgradePolicyAnnotation: "patch"}
+
+			for _, hr := range []*helmv2beta1.HelmRelease{
+				helm.MakeHelmRelease("podinfo", "6.0.0", "prod", "apps", repoRef),
+				patchOnly,
+				helm.MakeHelmRelease("podinfo", "7.0.0", "dev", "apps", repoRef),
+				helm.MakeHelmRelease("podinfo", "6.0.0", "prod", "other", types.NamespacedName{Name: "other", Namespace: "default"}),
+				helm.MakeHelmRelease("unknown", "1.0.0", "prod", "apps", repoRef),
+			} {
+				Expect(kubeClient.Create(context.TODO(), hr)).To(Succeed())
+			}
+		})
+
+		It("lists the releases of served profiles that can be upgraded", func() {
+			resp, err := s.ListProfileUpgrades(context.TODO(), &pb.ListProfileUpgradesRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Upgrades).To(HaveLen(2))
+
+			Expect(resp.Upgrades[0].HelmReleaseName).To(Equal("prod-podinfo"))
+			Expect(resp.Upgrades[0].ClusterName).To(Equal("management"))
+			Expect(resp.Upgrades[0].InstalledVersion).To(Equal("6.0.0"))
+			Expect(resp.Upgrades[0].LatestVersion).To(Equal("7.0.0"))
+			Expect(resp.Upgrades[0].UpgradeType).To(Equal("major"))
+			Expect(resp.Upgrades[0].AvailableVersions).To(Equal([]string{"7.0.0", "6.1.0", "6.0.1"}))
+			Expect(resp.Upgrades[0].HelmRepository).To(Equal(&pb.HelmRepository{Name: "helmrepo", Namespace: "default"}))
+
+			Expect(resp.Upgrades[1].HelmReleaseName).To(Equal("staging-podinfo"))
+			Expect(resp.Upgrades[1].LatestVersion).To(Equal("6.0.1"))
+			Expect(resp.Upgrades[1].UpgradeType).To(Equal("patch"))
+		})
+
+		It("applies the requested policy", func() {
+			resp, err := s.ListProfileUpgrades(context.TODO(), &pb.ListProfileUpgradesRequest{Namespace: "apps", Policy: "minor"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Upgrades).To(HaveLen(2))
+			Expect(resp.Upgrades[0].LatestVersion).To(Equal("6.1.0"))
+			Expect(resp.Upgrades[0].UpgradeType).To(Equal("minor"))
+		})
+
+		It("lists the releases of every cluster of the clusters client", func() {
+			leafClient := fake.NewClientBuilder().WithScheme(kubeClient.Scheme()).Build()
+			Expect(leafClient.Create(context.TODO(), helm.MakeHelmRelease("podinfo", "6.1.0", "prod", "apps", repoRef))).To(Succeed())
+
+			clustersClient := clustersmngr.NewClient(clientsPool{
+				clustersmngr.DefaultCluster: clusterClient{Client: kubeClient},
+				"leaf":                      clusterClient{Client: leafClient},
+			})
+			ctx := context.WithValue(context.TODO(), clustersmngr.ClustersClientCtxKey, clustersClient)
+
+			resp, err := s.ListProfileUpgrades(ctx, &pb.ListProfileUpgradesRequest{Namespace: "apps"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Upgrades).To(HaveLen(3))
+
+			Expect(resp.Upgrades[0].ClusterName).To(Equal(clustersmngr.DefaultCluster))
+			Expect(resp.Upgrades[0].HelmReleaseName).To(Equal("prod-podinfo"))
+			Expect(resp.Upgrades[1].ClusterName).To(Equal(clustersmngr.DefaultCluster))
+			Expect(resp.Upgrades[1].HelmReleaseName).To(Equal("staging-podinfo"))
+			Expect(resp.Upgrades[2].ClusterName).To(Equal("leaf"))
+			Expect(resp.Upgrades[2].HelmReleaseName).To(Equal("prod-podinfo"))
+			Expect(resp.Upgrades[2].InstalledVersion).To(Equal("6.1.0"))
+		})
+
+		It("rejects unknown policies", func() {
+			_, err := s.ListProfileUpgrades(context.TODO(), &pb.ListProfileUpgradesRequest{Policy: "weekly"})
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		})
+	})
+
+	Describe("with several HelmRepositories", func() {
+		var otherRepo, mirrorRepo *sourcev1beta1.HelmRepository
+
+		BeforeEach(func() {
+			otherRepo = helmRepo.DeepCopy()
+			otherRepo.Name = "other"
+			otherRepo.Namespace = "team"
+			otherRepo.Spec.URL = "example.com/other-charts"
+
+			// A copy of helmrepo in another namespace, serving the same charts.
+			mirrorRepo = helmRepo.DeepCopy()
+			mirrorRepo.Namespace = "mirror"
+
+			for _, hr := range []*sourcev1beta1.HelmRepository{helmRepo, otherRepo, mirrorRepo} {
+				Expect(kubeClient.Create(context.TODO(), hr)).To(Succeed())
+			}
+
+			fakeCache.ListProfilesStub = func(_ context.Context, namespace, name string) ([]*pb.Profile, error) {
+				return []*pb.Profile{
+					{Name: profileName},
+					{Name: name + "-only", HelmRepository: &pb.HelmRepository{Name: name, Namespace: namespace}},
+				}, nil
+			}
+		})
+
+		It("aggregates and de-duplicates the profiles of every HelmRepository", func() {
+			s.HelmRepositories = []string{server.AllHelmRepositories}
+
+			resp, err := s.GetProfiles(context.TODO(), &pb.GetProfilesRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Profiles).To(Equal([]*pb.Profile{
+				{Name: profileName, HelmRepository: &pb.HelmRepository{Name: "helmrepo", Namespace: "default"}},
+				{Name: "helmrepo-only", HelmRepository: &pb.HelmRepository{Name: "helmrepo", Namespace: "default"}},
+				{Name: profileName, HelmRepository: &pb.HelmRepository{Name: "other", Namespace: "team"}},
+				{Name: "other-only", HelmRepository: &pb.HelmRepository{Name: "other", Namespace: "team"}},
+			}))
+		})
+
+		It("serves the HelmRepositories of the namespaces the user can read", func() {
+			for _, ns := range []string{"default", "team", "mirror"} {
+				Expect(kubeClient.Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})).To(Succeed())
+			}
+
+			s.HelmRepositories = []string{server.AllHelmRepositories}
+			s.ClientGetter = kubefakes.NewFakeClientGetter(namespaceScopedClient{Client: kubeClient, namespace: "team"})
+
+			resp, err := s.GetProfiles(context.TODO(), &pb.GetProfilesRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Profiles).To(Equal([]*pb.Profile{
+				{Name: profileName, HelmRepository: &pb.HelmRepository{Name: "other", Namespace: "team"}},
+				{Name: "other-only", HelmRepository: &pb.HelmRepository{Name: "other", Namespace: "team"}},
+			}))
+		})
+
+		It("only serves the allow-listed HelmRepositories and skips missing ones", func() {
+			s.HelmRepositories = []string{"team/other", "team/missing", "team/other"}
+
+			resp, err := s.GetProfiles(context.TODO(), &pb.GetProfilesRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Profiles).To(HaveLen(2))
+			Expect(fakeCache.ListProfilesCallCount()).To(Equal(1))
+		})
+
+		It("filters by HelmRepository", func() {
+			s.HelmRepositories = []string{server.AllHelmRepositories}
+
+			resp, err := s.GetProfiles(context.TODO(), &pb.GetProfilesRequest{HelmRepositoryNamespace: "team"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Profiles).To(HaveLen(2))
+			Expect(resp.Profiles[0].HelmRepository.Name).To(Equal("other"))
+		})
+
+		It("rejects invalid HelmRepository references", func() {
+			s.HelmRepositories = []string{"other"}
+
+			_, err := s.GetProfiles(context.TODO(), &pb.GetProfilesRequest{})
+			Expect(err).To(MatchError(`invalid HelmRepository "other", expected namespace/name`))
+		})
+
+		It("returns the values of the requested HelmRepository", func() {
+			s.HelmRepositories = []string{server.AllHelmRepositories}
+			fakeCache.GetProfileValuesStub = func(_ context.Context, namespace, name, _, _ string) ([]byte, error) {
+				return []byte(namespace + "/" + name), nil
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept", server.OctetStreamType))
+
+			resp, err := s.GetProfileValues(ctx, &pb.GetProfileValuesRequest{
+				ProfileName:             profileName,
+				ProfileVersion:          profileVersion,
+				HelmRepositoryName:      "other",
+				HelmRepositoryNamespace: "team",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(resp.Data)).To(Equal("team/other"))
+
+			resp, err = s.GetProfileValues(ctx, &pb.GetProfileValuesRequest{
+				ProfileName:    profileName,
+				ProfileVersion: profileVersion,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(resp.Data)).To(Equal("default/helmrepo"))
+		})
+	})
 })
+
+// namespaceScopedClient forbids listing HelmRepositories outside of namespace,
+// like the client of a user only bound to a Role there.
+type namespaceScopedClient struct {
+	client.Client
+	namespace string
+}
+
+func (c namespaceScopedClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if _, ok := list.(*sourcev1beta1.HelmRepositoryList); ok {
+		listOpts := &client.ListOptions{}
+		listOpts.ApplyOptions(opts)
+
+		if listOpts.Namespace != c.namespace {
+			return apierrors.NewForbidden(sourcev1beta1.GroupVersion.WithResource("helmrepositories").GroupResource(), "", errors.New("forbidden"))
+		}
+	}
+
+	return c.Client.List(ctx, list, opts...)
+}
+
+// clientsPool serves fake clients by cluster name.
+type clientsPool map[string]clustersmngr.ClusterClient
+
+func (p clientsPool) Add(user *serverauth.UserPrincipal, cluster clustersmngr.Cluster) error {
+	return errors.New("not implemented")
+}
+
+func (p clientsPool) Clients() map[string]clustersmngr.ClusterClient {
+	return p
+}
+
+func (p clientsPool) Client(cluster string) (clustersmngr.ClusterClient, error) {
+	if c, ok := p[cluster]; ok {
+		return c, nil
+	}
+
+	return nil, clustersmngr.ClusterNotFoundError{Cluster: cluster}
+}
+
+type clusterClient struct {
+	client.Client
+}
+
+func (c clusterClient) RestConfig() *rest.Config {
+	return &rest.Config{}
+}
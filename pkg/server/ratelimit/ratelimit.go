@@ -0,0 +1,253 @@
+// Package ratelimit protects the API from callers sending more requests than
+// the server, or the clusters it fans out to, can handle. Requests are
+// counted in token buckets: one shared by everybody, one per principal and a
+// tighter one per principal for the routes that query every cluster.
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sethvargo/go-limiter"
+	"github.com/sethvargo/go-limiter/memorystore"
+	"github.com/weaveworks/weave-gitops/pkg/server/auth"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// ScopeGlobal labels requests rejected by the bucket shared by everybody.
+	ScopeGlobal = "global"
+	// ScopePrincipal labels requests rejected by the caller's own bucket.
+	ScopePrincipal = "principal"
+	// ScopeFanOut labels requests rejected by the caller's fan-out bucket.
+	ScopeFanOut = "fanout"
+)
+
+// DefaultFanOutRoutes are the API routes that list resources in every
+// cluster the caller can access.
+var DefaultFanOutRoutes = []string{
+	"/v1/kustomizations",
+	"/v1/helmreleases",
+	"/v1/gitrepositories",
+	"/v1/helmcharts",
+	"/v1/helmrepositories",
+	"/v1/buckets",
+	"/v1/flux_runtime_objects",
+	"/v1/reconciled_objects",
+	"/v1/child_objects",
+	"/v1/namespaces",
+	"/v1/events",
+	"/v1/profiles/upgrades",
+}
+
+// Limit allows bursts of Requests requests, refilled every Interval. A zero
+// Requests disables the limit.
+type Limit struct {
+	Requests uint64
+	Interval time.Duration
+}
+
+func (l Limit) enabled() bool {
+	return l.Requests > 0
+}
+
+// Config holds the limits applied to API requests.
+type Config struct {
+	// Global is shared by all callers.
+	Global Limit
+	// PerPrincipal applies to each user, or to each client address when the
+	// request is not authenticated.
+	PerPrincipal Limit
+	// FanOut applies to each principal for FanOutRoutes, on top of
+	// PerPrincipal.
+	FanOut Limit
+	// FanOutRoutes are matched exactly against the request path.
+	FanOutRoutes []string
+}
+
+// Limiter is an http middleware enforcing a Config. The configuration can be
+// replaced while serving requests.
+type Limiter struct {
+	log      logr.Logger
+	rejected *prometheus.CounterVec
+
+	mu     sync.RWMutex
+	stores map[string]limiter.Store
+	fanOut map[string]bool
+}
+
+// New creates a Limiter. The rejection counter is registered with reg, unless
+// it is nil.
+func New(log logr.Logger, cfg Config, reg prometheus.Registerer) (*Limiter, error) {
+	l := &Limiter{
+		log: log,
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gitops_server_rate_limited_requests_total",
+			Help: "Number of API requests rejected because a rate limit was exceeded.",
+		}, []string{"scope"}),
+	}
+
+	if reg != nil {
+		if err := reg.Register(l.rejected); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := l.SetConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// SetConfig replaces the limits. Requests counted so far are forgotten.
+func (l *Limiter) SetConfig(cfg Config) error {
+	stores := map[string]limiter.Store{}
+
+	limits := map[string]Limit{
+		ScopeGlobal:    cfg.Global,
+		ScopePrincipal: cfg.PerPrincipal,
+		ScopeFanOut:    cfg.FanOut,
+	}
+
+	for scope, limit := range limits {
+		if !limit.enabled() {
+			continue
+		}
+
+		store, err := memorystore.New(&memorystore.Config{
+			Tokens:   limit.Requests,
+			Interval: limit.Interval,
+		})
+		if err != nil {
+			closeStores(stores)
+			return err
+		}
+
+		stores[scope] = store
+	}
+
+	fanOut := map[string]bool{}
+	for _, route := range cfg.FanOutRoutes {
+		fanOut[route] = true
+	}
+
+	l.mu.Lock()
+	old := l.stores
+	l.stores = stores
+	l.fanOut = fanOut
+	l.mu.Unlock()
+
+	closeStores(old)
+
+	return nil
+}
+
+// Close stops the background sweeping of the buckets.
+func (l *Limiter) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	closeStores(l.stores)
+	l.stores = nil
+}
+
+// Handler rejects requests over the limits with 429 Too Many Requests and a
+// Retry-After header. It must be wrapped by the authentication middleware so
+// the principal is known.
+func (l *Limiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.mu.RLock()
+		stores := l.stores
+		fanOut := l.fanOut[r.URL.Path]
+		l.mu.RUnlock()
+
+		key := principalKey(r)
+
+		// The caller's own buckets are checked first, so that a caller who is
+		// over their limit does not use up the global bucket.
+		checks := []struct {
+			scope string
+			key   string
+		}{
+			{ScopePrincipal, key},
+			{ScopeFanOut, key},
+			{ScopeGlobal, ScopeGlobal},
+		}
+
+		for _, check := range checks {
+			store, ok := stores[check.scope]
+			if !ok || (check.scope == ScopeFanOut && !fanOut) {
+				continue
+			}
+
+			limit, _, reset, ok, err := store.Take(r.Context(), check.key)
+			if err != nil {
+				// The store only fails once closed, which happens when the
+				// configuration is replaced. Let the request through.
+				l.log.V(1).Info("rate limit check failed", "error", err.Error())
+				continue
+			}
+
+			if !ok {
+				l.rejected.WithLabelValues(check.scope).Inc()
+				l.log.V(1).Info("rate limit exceeded", "scope", check.scope, "key", check.key, "uri", r.RequestURI)
+				reject(w, limit, reset)
+
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func principalKey(r *http.Request) string {
+	if p := auth.Principal(r.Context()); p != nil && p.ID != "" {
+		return "user:" + p.ID
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+type errorResponse struct {
+	Code    codes.Code `json:"code"`
+	Message string     `json:"message"`
+}
+
+func reject(w http.ResponseWriter, limit, reset uint64) {
+	retryAfter := int64(math.Ceil(time.Until(time.Unix(0, int64(reset))).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+	w.Header().Set("X-RateLimit-Limit", strconv.FormatUint(limit, 10))
+	w.Header().Set("X-RateLimit-Remaining", "0")
+	w.WriteHeader(http.StatusTooManyRequests)
+
+	_ = json.NewEncoder(w).Encode(errorResponse{
+		Code:    codes.ResourceExhausted,
+		Message: "rate limit exceeded, retry in " + strconv.FormatInt(retryAfter, 10) + "s",
+	})
+}
+
+func closeStores(stores map[string]limiter.Store) {
+	for _, store := range stores {
+		_ = store.Close(context.Background())
+	}
+}
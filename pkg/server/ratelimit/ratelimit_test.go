@@ -0,0 +1,121 @@
+package ratelimit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/weaveworks/weave-gitops/pkg/server/auth"
+	"github.com/weaveworks/weave-gitops/pkg/server/ratelimit"
+)
+
+func newLimiter(t *testing.T, cfg ratelimit.Config) (http.Handler, *prometheus.Registry) {
+	t.Helper()
+
+	reg := prometheus.NewRegistry()
+
+	l, err := ratelimit.New(logr.Discard(), cfg, reg)
+	if err != nil {
+		t.Fatalf("failed creating limiter: %v", err)
+	}
+
+	t.Cleanup(l.Close)
+
+	return l.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})), reg
+}
+
+func request(h http.Handler, path, user, addr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "https://example.com"+path, nil)
+	req.RemoteAddr = addr
+
+	if user != "" {
+		req = req.WithContext(auth.WithPrincipal(context.Background(), &auth.UserPrincipal{ID: user}))
+	}
+
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+
+	return res
+}
+
+func TestPerPrincipalLimit(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	h, reg := newLimiter(t, ratelimit.Config{
+		PerPrincipal: ratelimit.Limit{Requests: 2, Interval: time.Minute},
+	})
+
+	g.Expect(request(h, "/v1/featureflags", "alice", "10.0.0.1:1234")).To(HaveHTTPStatus(http.StatusOK))
+	g.Expect(request(h, "/v1/featureflags", "alice", "10.0.0.2:1234")).To(HaveHTTPStatus(http.StatusOK))
+
+	res := request(h, "/v1/featureflags", "alice", "10.0.0.1:1234")
+	g.Expect(res).To(HaveHTTPStatus(http.StatusTooManyRequests))
+	g.Expect(res.Body.String()).To(MatchJSON(`{"code":8,"message":"rate limit exceeded, retry in ` + res.Header().Get("Retry-After") + `s"}`))
+
+	retryAfter, err := strconv.Atoi(res.Header().Get("Retry-After"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(retryAfter).To(BeNumerically("~", 60, 1))
+
+	// Other users, and unauthenticated clients, have their own buckets.
+	g.Expect(request(h, "/v1/featureflags", "bob", "10.0.0.1:1234")).To(HaveHTTPStatus(http.StatusOK))
+	g.Expect(request(h, "/v1/featureflags", "", "10.0.0.1:1234")).To(HaveHTTPStatus(http.StatusOK))
+	g.Expect(request(h, "/v1/featureflags", "", "10.0.0.1:5678")).To(HaveHTTPStatus(http.StatusOK))
+	g.Expect(request(h, "/v1/featureflags", "", "10.0.0.1:9012")).To(HaveHTTPStatus(http.StatusTooManyRequests))
+
+	g.Expect(testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP gitops_server_rate_limited_requests_total Number of API requests rejected because a rate limit was exceeded.
+# TYPE gitops_server_rate_limited_requests_total counter
+gitops_server_rate_limited_requests_total{scope="principal"} 2
+`))).To(Succeed())
+}
+
+func TestFanOutLimit(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	h, _ := newLimiter(t, ratelimit.Config{
+		PerPrincipal: ratelimit.Limit{Requests: 10, Interval: time.Minute},
+		FanOut:       ratelimit.Limit{Requests: 1, Interval: time.Minute},
+		FanOutRoutes: ratelimit.DefaultFanOutRoutes,
+	})
+
+	g.Expect(request(h, "/v1/kustomizations", "alice", "")).To(HaveHTTPStatus(http.StatusOK))
+	g.Expect(request(h, "/v1/helmreleases", "alice", "")).To(HaveHTTPStatus(http.StatusTooManyRequests))
+
+	// Routes reading a single object are not fan-out routes.
+	g.Expect(request(h, "/v1/kustomizations/flux-system", "alice", "")).To(HaveHTTPStatus(http.StatusOK))
+	g.Expect(request(h, "/v1/kustomizations", "bob", "")).To(HaveHTTPStatus(http.StatusOK))
+	g.Expect(request(h, "/v1/profiles/upgrades", "bob", "")).To(HaveHTTPStatus(http.StatusTooManyRequests))
+}
+
+func TestGlobalLimit(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	l, err := ratelimit.New(logr.Discard(), ratelimit.Config{
+		PerPrincipal: ratelimit.Limit{Requests: 1, Interval: time.Minute},
+		Global:       ratelimit.Limit{Requests: 2, Interval: time.Minute},
+	}, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	defer l.Close()
+
+	h := l.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	g.Expect(request(h, "/v1/namespaces", "alice", "")).To(HaveHTTPStatus(http.StatusOK))
+	// Rejected by alice's own bucket, which leaves the global one alone.
+	g.Expect(request(h, "/v1/namespaces", "alice", "")).To(HaveHTTPStatus(http.StatusTooManyRequests))
+	g.Expect(request(h, "/v1/namespaces", "bob", "")).To(HaveHTTPStatus(http.StatusOK))
+	g.Expect(request(h, "/v1/namespaces", "carol", "")).To(HaveHTTPStatus(http.StatusTooManyRequests))
+
+	// Replacing the configuration resets the buckets.
+	g.Expect(l.SetConfig(ratelimit.Config{})).To(Succeed())
+	g.Expect(request(h, "/v1/namespaces", "carol", "")).To(HaveHTTPStatus(http.StatusOK))
+	g.Expect(request(h, "/v1/namespaces", "carol", "")).To(HaveHTTPStatus(http.StatusOK))
+}
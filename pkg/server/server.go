@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 
 	"github.com/weaveworks/weave-gitops/api/v1alpha1"
 	pb "github.com/weaveworks/weave-gitops/pkg/api/applications"
+	"github.com/weaveworks/weave-gitops/pkg/featureflags"
 	"github.com/weaveworks/weave-gitops/pkg/flux"
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 	"github.com/weaveworks/weave-gitops/pkg/kube"
@@ -36,11 +38,21 @@ import (
 const DefaultHost = "0.0.0.0"
 const DefaultPort = "9001"
 
+// validTokenTTL is how long a token validated by a provider is considered valid without asking it again.
+const validTokenTTL = time.Minute
+
+// providerRateLimitMaxWait is how long a request waits for the quota of a provider API to be renewed,
+// short enough for the requests of the UI.
+const providerRateLimitMaxWait = 10 * time.Second
+
 var (
 	ErrEmptyAccessToken = errors.New("access token is empty")
 	ErrBadProvider      = errors.New("wrong provider name")
 )
 
+// hostPattern matches a hostname, optionally followed by a port.
+var hostPattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?)*(:[0-9]{1,5})?$`)
+
 type applicationServer struct {
 	pb.UnimplementedApplicationsServer
 
@@ -51,6 +63,12 @@ type applicationServer struct {
 	glAuthClient auth.GitlabAuthClient
 	clientGetter kube.ClientGetter
 	kubeGetter   kube.KubeGetter
+	featureFlags featureflags.Checker
+	tokenCache   *auth.TokenCache
+	// providerClient sends the requests validating tokens with the APIs of the self-hosted providers
+	providerClient *http.Client
+	// gitHostTypes are the self-hosted providers tokens can be validated against, by host
+	gitHostTypes map[string]string
 }
 
 // An ApplicationsConfig allows for the customization of an ApplicationsServer.
@@ -62,6 +80,11 @@ type ApplicationsConfig struct {
 	GithubAuthClient auth.GithubAuthClient
 	GitlabAuthClient auth.GitlabAuthClient
 	ClusterConfig    kube.ClusterConfig
+	// ProviderHTTPClient sends the requests to the git provider APIs, http.DefaultClient if nil.
+	ProviderHTTPClient *http.Client
+	// GitHostTypes are the git providers by host, like the --git-host-types flag. Tokens of self-hosted
+	// providers are only validated against these hosts.
+	GitHostTypes map[string]string
 }
 
 // NewApplicationsServer creates a grpc Applications server
@@ -79,6 +102,11 @@ func NewApplicationsServer(cfg *ApplicationsConfig, setters ...ApplicationsOptio
 		setter(args)
 	}
 
+	providerClient := cfg.ProviderHTTPClient
+	if providerClient == nil {
+		providerClient = http.DefaultClient
+	}
+
 	return &applicationServer{
 		jwtClient:    cfg.JwtClient,
 		log:          cfg.Logger,
@@ -87,6 +115,11 @@ func NewApplicationsServer(cfg *ApplicationsConfig, setters ...ApplicationsOptio
 		glAuthClient: cfg.GitlabAuthClient,
 		clientGetter: args.ClientGetter,
 		kubeGetter:   args.KubeGetter,
+		featureFlags: args.FeatureFlags,
+		tokenCache:   auth.NewTokenCache(validTokenTTL),
+
+		providerClient: providerClient,
+		gitHostTypes:   cfg.GitHostTypes,
 	}
 }
 
@@ -110,16 +143,23 @@ func DefaultApplicationsConfig(log logr.Logger) (*ApplicationsConfig, error) {
 
 	fluxClient := flux.New(&runner.CLIRunner{})
 
+	// the quotas of the tokens are shared by all the requests validating them
+	rateLimiter := gitproviders.NewRateLimiter(providerRateLimitMaxWait, func(quota gitproviders.RateLimit) {
+		log.Info("git provider API quota is running low", "host", quota.Host, "remaining", quota.Remaining, "limit", quota.Limit, "reset", quota.Reset)
+	})
+	providerClient := &http.Client{Transport: rateLimiter.Transport(nil)}
+
 	return &ApplicationsConfig{
 		Logger:           log.WithName("app-server"),
 		Factory:          services.NewFactory(fluxClient, internal.NewApiLogger(log.WithName("services"))),
 		JwtClient:        jwtClient,
-		GithubAuthClient: auth.NewGithubAuthClient(http.DefaultClient),
-		GitlabAuthClient: auth.NewGitlabAuthClient(http.DefaultClient),
+		GithubAuthClient: auth.NewGithubAuthClient(providerClient),
+		GitlabAuthClient: auth.NewGitlabAuthClient(providerClient),
 		ClusterConfig: kube.ClusterConfig{
 			DefaultConfig: rest,
 			ClusterName:   clusterName,
 		},
+		ProviderHTTPClient: providerClient,
 	}, nil
 }
 
@@ -214,11 +254,13 @@ func (s *applicationServer) ValidateProviderToken(ctx context.Context, msg *pb.V
 		return nil, grpcStatus.Error(codes.Unauthenticated, err.Error())
 	}
 
-	v, err := findValidator(msg.Provider, s)
+	v, err := findValidator(msg, s)
 	if err != nil {
 		return nil, grpcStatus.Error(codes.InvalidArgument, err.Error())
 	}
 
+	v = s.tokenCache.Wrap(msg.Provider.String(), msg.Host, v)
+
 	if err := v.ValidateToken(ctx, token.AccessToken); err != nil {
 		return nil, grpcStatus.Error(codes.InvalidArgument, err.Error())
 	}
@@ -231,6 +273,13 @@ func (s *applicationServer) ValidateProviderToken(ctx context.Context, msg *pb.V
 func (s *applicationServer) GetFeatureFlags(ctx context.Context, msg *pb.GetFeatureFlagsRequest) (*pb.GetFeatureFlagsResponse, error) {
 	flags := make(map[string]string)
 
+	// The route is public, the principal is only set for signed in users.
+	if s.featureFlags != nil {
+		for name, value := range s.featureFlags.Flags(serverauth.Principal(ctx)) {
+			flags[name] = value
+		}
+	}
+
 	flags["WEAVE_GITOPS_AUTH_ENABLED"] = os.Getenv("WEAVE_GITOPS_AUTH_ENABLED")
 
 	cl, err := s.clientGetter.Client(ctx)
@@ -280,18 +329,47 @@ func toProtoProvider(p gitproviders.GitProviderName) pb.GitProvider {
 		return pb.GitProvider_GitHub
 	case gitproviders.GitProviderGitLab:
 		return pb.GitProvider_GitLab
+	case gitproviders.GitProviderBitbucketServer:
+		return pb.GitProvider_BitbucketServer
+	case gitproviders.GitProviderGitea:
+		return pb.GitProvider_Gitea
 	}
 
 	return pb.GitProvider_Unknown
 }
 
-func findValidator(provider pb.GitProvider, s *applicationServer) (auth.ProviderTokenValidator, error) {
-	switch provider {
+func findValidator(msg *pb.ValidateProviderTokenRequest, s *applicationServer) (auth.ProviderTokenValidator, error) {
+	switch msg.Provider {
 	case pb.GitProvider_GitHub:
 		return s.ghAuthClient, nil
 	case pb.GitProvider_GitLab:
 		return s.glAuthClient, nil
+	case pb.GitProvider_BitbucketServer, pb.GitProvider_Gitea:
+		// self-hosted providers have no default host to validate the token against
+		if msg.Host == "" {
+			return nil, fmt.Errorf("host is required to validate tokens for git provider %s", msg.Provider)
+		}
+
+		if !hostPattern.MatchString(msg.Host) {
+			return nil, fmt.Errorf("invalid host %q, expected a hostname with an optional port", msg.Host)
+		}
+
+		// the token is sent to the host by the server, which mustn't reach arbitrary hosts
+		provider := gitproviders.GitProviderGitea
+		if msg.Provider == pb.GitProvider_BitbucketServer {
+			provider = gitproviders.GitProviderBitbucketServer
+		}
+
+		if gitproviders.GitProviderName(s.gitHostTypes[msg.Host]) != provider {
+			return nil, fmt.Errorf("host %s is not configured as a %s provider in --git-host-types", msg.Host, provider)
+		}
+
+		if msg.Provider == pb.GitProvider_BitbucketServer {
+			return auth.NewBitbucketServerAuthClient(s.providerClient, msg.Host), nil
+		}
+
+		return auth.NewGiteaAuthClient(s.providerClient, msg.Host), nil
 	}
 
-	return nil, fmt.Errorf("unknown git provider %s", provider)
+	return nil, fmt.Errorf("unknown git provider %s", msg.Provider)
 }
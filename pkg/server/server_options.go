@@ -1,12 +1,16 @@
 package server
 
-import "github.com/weaveworks/weave-gitops/pkg/kube"
+import (
+	"github.com/weaveworks/weave-gitops/pkg/featureflags"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+)
 
 // ApplicationsOptions includes all the options that can be set for an
 // ApplicationsServer.
 type ApplicationsOptions struct {
 	ClientGetter kube.ClientGetter
 	KubeGetter   kube.KubeGetter
+	FeatureFlags featureflags.Checker
 }
 
 // ApplicationsOption defines the signature of a function that can be used
@@ -26,3 +30,11 @@ func WithKubeGetter(kubeGetter kube.KubeGetter) ApplicationsOption {
 		args.KubeGetter = kubeGetter
 	}
 }
+
+// WithFeatureFlags allows for setting the feature flags returned to callers
+// next to the built-in ones.
+func WithFeatureFlags(flags featureflags.Checker) ApplicationsOption {
+	return func(args *ApplicationsOptions) {
+		args.FeatureFlags = flags
+	}
+}
@@ -20,11 +20,14 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/stretchr/testify/assert"
+	"github.com/weaveworks/weave-gitops/api/v1alpha1"
 	pb "github.com/weaveworks/weave-gitops/pkg/api/applications"
+	"github.com/weaveworks/weave-gitops/pkg/featureflags"
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 	"github.com/weaveworks/weave-gitops/pkg/kube"
 	"github.com/weaveworks/weave-gitops/pkg/kube/kubefakes"
 	"github.com/weaveworks/weave-gitops/pkg/server"
+	serverauth "github.com/weaveworks/weave-gitops/pkg/server/auth"
 	"github.com/weaveworks/weave-gitops/pkg/server/middleware"
 	"github.com/weaveworks/weave-gitops/pkg/services/auth"
 	"github.com/weaveworks/weave-gitops/pkg/services/auth/authfakes"
@@ -280,6 +283,26 @@ var _ = Describe("ApplicationsServer", func() {
 		Entry("bad github token", pb.GitProvider_GitHub, contextWithAuth(context.Background()), errors.New("this token is bad"), codes.InvalidArgument, false),
 		Entry("good github token", pb.GitProvider_GitHub, contextWithAuth(context.Background()), nil, codes.OK, true),
 		Entry("no gitops jwt", pb.GitProvider_GitHub, context.Background(), errors.New("unauth error"), codes.Unauthenticated, false),
+		Entry("gitea token without host", pb.GitProvider_Gitea, contextWithAuth(context.Background()), errors.New("missing host"), codes.InvalidArgument, false),
+		Entry("bitbucket server token without host", pb.GitProvider_BitbucketServer, contextWithAuth(context.Background()), errors.New("missing host"), codes.InvalidArgument, false),
+	)
+
+	DescribeTable("ValidateProviderToken of self-hosted providers", func(provider pb.GitProvider, host, expectedErr string) {
+		_, err := appsClient.ValidateProviderToken(contextWithAuth(context.Background()), &pb.ValidateProviderTokenRequest{
+			Provider: provider,
+			Host:     host,
+		})
+		Expect(err).To(HaveOccurred())
+
+		s, ok := status.FromError(err)
+		Expect(ok).To(BeTrue(), "could not get status from error")
+		Expect(s.Code()).To(Equal(codes.InvalidArgument))
+		Expect(s.Message()).To(ContainSubstring(expectedErr))
+	},
+		Entry("host not in git host types", pb.GitProvider_Gitea, "internal.example.com", "host internal.example.com is not configured as a gitea provider"),
+		Entry("host of another provider", pb.GitProvider_BitbucketServer, "git.example.com", "host git.example.com is not configured as a bitbucket-server provider"),
+		Entry("host with a path", pb.GitProvider_Gitea, "git.example.com/admin?", `invalid host "git.example.com/admin?"`),
+		Entry("host with credentials", pb.GitProvider_Gitea, "user@git.example.com", `invalid host "user@git.example.com"`),
 	)
 
 	Describe("middleware", func() {
@@ -469,6 +492,7 @@ func TestGetFeatureFlags(t *testing.T) {
 		envSet   func()
 		envUnset func()
 		state    []client.Object
+		flags    featureflags.Checker
 		result   map[string]string
 	}{
 		{
@@ -556,6 +580,28 @@ func TestGetFeatureFlags(t *testing.T) {
 				"OIDC_AUTH":                 "false",
 			},
 		},
+		{
+			name:     "Extra flags set",
+			envSet:   func() {},
+			envUnset: func() {},
+			state:    []client.Object{},
+			flags: func() featureflags.Checker {
+				store := featureflags.NewStore()
+				store.SetDefaults(map[string]string{"WEAVE_GITOPS_FEATURE_X": "true", "OIDC_AUTH": "true"})
+				store.SetFlags(featureflags.Set{
+					"WEAVE_GITOPS_FEATURE_Y": {Value: "false", Targets: []featureflags.Target{{Groups: []string{"team"}, Value: "true"}}},
+				})
+
+				return store
+			}(),
+			result: map[string]string{
+				"WEAVE_GITOPS_AUTH_ENABLED": "",
+				"WEAVE_GITOPS_FEATURE_X":    "true",
+				"WEAVE_GITOPS_FEATURE_Y":    "false",
+				"CLUSTER_USER_AUTH":         "false",
+				"OIDC_AUTH":                 "false",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -569,7 +615,7 @@ func TestGetFeatureFlags(t *testing.T) {
 
 			k8s := fake.NewClientBuilder().WithScheme(kube.CreateScheme()).WithObjects(tt.state...).Build()
 			fakeClientGetter := kubefakes.NewFakeClientGetter(k8s)
-			appSrv := server.NewApplicationsServer(&cfg, server.WithClientGetter(fakeClientGetter))
+			appSrv := server.NewApplicationsServer(&cfg, server.WithClientGetter(fakeClientGetter), server.WithFeatureFlags(tt.flags))
 			err = pb.RegisterApplicationsHandlerServer(context.Background(), mux, appSrv)
 
 			httpHandler := middleware.WithLogging(log, mux)
@@ -594,3 +640,62 @@ func TestGetFeatureFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestGetFeatureFlagsTargetsSignedInUsers(t *testing.T) {
+	log, _ := testutils.MakeFakeLogr()
+	mux := runtime.NewServeMux(middleware.WithGrpcErrorLogging(log))
+
+	store := featureflags.NewStore()
+	store.SetFlags(featureflags.Set{
+		"WEAVE_GITOPS_FEATURE_TENANCY": {Value: "false", Targets: []featureflags.Target{{Users: []string{v1alpha1.DefaultClaimsSubject}, Value: "true"}}},
+	})
+
+	k8s := fake.NewClientBuilder().WithScheme(kube.CreateScheme()).Build()
+	appSrv := server.NewApplicationsServer(&server.ApplicationsConfig{Logger: logr.Discard()},
+		server.WithClientGetter(kubefakes.NewFakeClientGetter(k8s)), server.WithFeatureFlags(store))
+	assert.NoError(t, pb.RegisterApplicationsHandlerServer(context.Background(), mux, appSrv))
+
+	tsv, err := serverauth.NewHMACTokenSignerVerifier(5 * time.Minute)
+	assert.NoError(t, err)
+
+	authCfg, err := serverauth.NewAuthServerConfig(logr.Discard(), serverauth.OIDCConfig{}, k8s, tsv)
+	assert.NoError(t, err)
+
+	authServer, err := serverauth.NewAuthServer(context.Background(), authCfg)
+	assert.NoError(t, err)
+
+	// The same middleware as the gitops-server, the route is public but
+	// signed in users get their own flags.
+	ts := httptest.NewServer(serverauth.WithAPIAuth(middleware.WithLogging(log, mux), authServer, server.PublicRoutes))
+	defer ts.Close()
+
+	getFlags := func(cookie *http.Cookie) map[string]string {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/v1/featureflags", nil)
+		assert.NoError(t, err)
+
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var data struct {
+			Flags map[string]string
+		}
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&data))
+
+		return data.Flags
+	}
+
+	assert.Equal(t, "false", getFlags(nil)["WEAVE_GITOPS_FEATURE_TENANCY"])
+
+	token, err := tsv.Sign()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "true", getFlags(&http.Cookie{Name: serverauth.IDTokenCookieName, Value: token})["WEAVE_GITOPS_FEATURE_TENANCY"])
+}
@@ -110,6 +110,7 @@ var _ = BeforeEach(func() {
 		GithubAuthClient: ghAuthClient,
 		GitlabAuthClient: glAuthClient,
 		ClusterConfig:    kube.ClusterConfig{},
+		GitHostTypes:     map[string]string{"git.example.com": "gitea"},
 	}
 	apps = server.NewApplicationsServer(&cfg,
 		server.WithClientGetter(fakeClientGetter),
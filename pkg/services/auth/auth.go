@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/weaveworks/weave-gitops/pkg/logger"
 	"github.com/weaveworks/weave-gitops/pkg/models"
@@ -45,9 +46,12 @@ func (sn SecretName) NamespacedName() types.NamespacedName {
 }
 
 type AuthService interface {
-	CreateGitClient(ctx context.Context, repoUrl gitproviders.RepoURL, namespace string, dryRun bool) (git.Git, error)
+	CreateGitClient(ctx context.Context, repoUrl gitproviders.RepoURL, namespace string, dryRun bool, opts ...git.Option) (git.Git, error)
 	GetGitProvider() gitproviders.GitProvider
 	SetupDeployKey(ctx context.Context, namespace string, repo gitproviders.RepoURL) (*ssh.PublicKeys, error)
+	ListDeployKeys(ctx context.Context, namespace string, repo gitproviders.RepoURL) ([]DeployKeyStatus, error)
+	RotateDeployKey(ctx context.Context, namespace string, repo gitproviders.RepoURL, maxAge time.Duration) (bool, error)
+	DeleteOrphanedDeployKeys(ctx context.Context, namespace string, repo gitproviders.RepoURL, opts DeleteDeployKeysOptions) ([]gitproviders.DeployKey, error)
 }
 
 type authSvc struct {
@@ -57,15 +61,18 @@ type authSvc struct {
 	// That interface wasn't providing any valuable abstraction for this service.
 	k8sClient   client.Client
 	gitProvider gitproviders.GitProvider
+	// validateAccess checks that a deploy key grants access to a repository
+	validateAccess func(ctx context.Context, deployKey *ssh.PublicKeys, url, branch string) error
 }
 
 // NewAuthService constructs an auth service for doing git operations with an authenticated client.
 func NewAuthService(fluxClient flux.Flux, k8sClient client.Client, provider gitproviders.GitProvider, log logger.Logger) (AuthService, error) {
 	return &authSvc{
-		log:         log,
-		fluxClient:  fluxClient,
-		k8sClient:   k8sClient,
-		gitProvider: provider,
+		log:            log,
+		fluxClient:     fluxClient,
+		k8sClient:      k8sClient,
+		gitProvider:    provider,
+		validateAccess: validateGitAccess,
 	}, nil
 }
 
@@ -76,10 +83,10 @@ func (a *authSvc) GetGitProvider() gitproviders.GitProvider {
 
 // CreateGitClient creates a git.Git client instrumented with existing or generated deploy keys.
 // This ensures that git operations are done with stored deploy keys instead of a user's local ssh-agent or equivalent.
-func (a *authSvc) CreateGitClient(ctx context.Context, repoUrl gitproviders.RepoURL, namespace string, dryRun bool) (git.Git, error) {
+func (a *authSvc) CreateGitClient(ctx context.Context, repoUrl gitproviders.RepoURL, namespace string, dryRun bool, opts ...git.Option) (git.Git, error) {
 	if dryRun {
 		d, _ := makePublicKey([]byte(""))
-		return git.New(d, wrapper.NewGoGit()), nil
+		return git.New(d, wrapper.NewGoGit(), opts...), nil
 	}
 
 	pubKey, keyErr := a.SetupDeployKey(ctx, namespace, repoUrl)
@@ -90,11 +97,11 @@ func (a *authSvc) CreateGitClient(ctx context.Context, repoUrl gitproviders.Repo
 	if pubKey == nil {
 		// Don't return git.New(pubkey, wrapper.NewGoGit()), nil here. It will fail
 		// "nil" of type *ssh.PublicKeys does not behave correctly
-		return git.New(nil, wrapper.NewGoGit()), nil
+		return git.New(nil, wrapper.NewGoGit(), opts...), nil
 	}
 
 	// Set the git client to use the existing deploy key.
-	return git.New(pubKey, wrapper.NewGoGit()), nil
+	return git.New(pubKey, wrapper.NewGoGit(), opts...), nil
 }
 
 // SetupDeployKey creates a git.Git client instrumented with existing or generated deploy keys.
@@ -145,6 +152,7 @@ func (a *authSvc) provisionDeployKey(ctx context.Context, name SecretName, repo
 	}
 
 	publicKeyBytes := extractPublicKey(secret)
+	annotateDeployKey(secret)
 
 	if err := a.gitProvider.UploadDeployKey(ctx, repo, publicKeyBytes); err != nil {
 		return nil, fmt.Errorf("error uploading deploy key: %w", err)
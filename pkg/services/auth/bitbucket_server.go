@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type bitbucketServerAuth struct {
+	http *http.Client
+	host string
+}
+
+// NewBitbucketServerAuthClient returns a validator for the HTTP access tokens of the Bitbucket Server instance running on host.
+func NewBitbucketServerAuthClient(client *http.Client, host string) ProviderTokenValidator {
+	return bitbucketServerAuth{http: client, host: host}
+}
+
+// ValidateToken checks that the token authenticates a user. Anonymous requests succeed when public access
+// is enabled, only the authenticated ones get the X-AUSERNAME header.
+func (b bitbucketServerAuth) ValidateToken(ctx context.Context, token string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://%s/rest/api/1.0/users?limit=1", b.host), nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	res, err := b.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("invalid token: %s", res.Status)
+	}
+
+	if res.Header.Get("X-AUSERNAME") == "" {
+		return fmt.Errorf("invalid token: the request was not authenticated")
+	}
+
+	return nil
+}
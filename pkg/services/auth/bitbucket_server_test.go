@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/vendorfakes/fakehttp"
+)
+
+var _ = Describe("BitbucketServerAuthClient", func() {
+	Describe("ValidateToken", func() {
+		It("returns an error when a 401 is returned", func() {
+			rt := fakehttp.RoundTripper{}
+			rt.RoundTripReturns(&http.Response{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized", Body: ioutil.NopCloser(strings.NewReader(""))}, nil)
+			c := NewBitbucketServerAuthClient(&http.Client{Transport: &rt}, "bitbucket.example.com")
+
+			Expect(c.ValidateToken(context.Background(), "sometoken")).To(MatchError("invalid token: 401 Unauthorized"))
+		})
+		It("returns an error when the request is anonymous", func() {
+			rt := fakehttp.RoundTripper{}
+			rt.RoundTripReturns(&http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader("{}"))}, nil)
+			c := NewBitbucketServerAuthClient(&http.Client{Transport: &rt}, "bitbucket.example.com")
+
+			Expect(c.ValidateToken(context.Background(), "sometoken")).To(MatchError("invalid token: the request was not authenticated"))
+		})
+		It("does not return an error when a token is valid", func() {
+			rt := fakehttp.RoundTripper{}
+			rt.RoundTripReturns(&http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Ausername": {"jdoe"}}, Body: ioutil.NopCloser(strings.NewReader("{}"))}, nil)
+			c := NewBitbucketServerAuthClient(&http.Client{Transport: &rt}, "bitbucket.example.com")
+
+			Expect(c.ValidateToken(context.Background(), "sometoken")).To(Succeed())
+
+			req := rt.RoundTripArgsForCall(0)
+			Expect(req.URL.String()).To(Equal("https://bitbucket.example.com/rest/api/1.0/users?limit=1"))
+			Expect(req.Header.Get("Authorization")).To(Equal("Bearer sometoken"))
+		})
+	})
+})
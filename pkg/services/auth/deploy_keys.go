@@ -0,0 +1,341 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/git/wrapper"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	"github.com/weaveworks/weave-gitops/pkg/models"
+	"github.com/weaveworks/weave-gitops/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// DeployKeyCreatedAnnotation records when the deploy key of a Secret was generated.
+	DeployKeyCreatedAnnotation = "weave.works/deploy-key-created"
+
+	// ReplacedDeployKeysAnnotation records the comma separated fingerprints of the deploy keys replaced by
+	// rotating the key of a Secret, that may still be uploaded to the repository.
+	ReplacedDeployKeysAnnotation = "weave.works/replaced-deploy-keys"
+
+	// deployKeyPropagationTimeout is how long a new deploy key may take to grant access to the repository.
+	deployKeyPropagationTimeout = 30 * time.Second
+)
+
+// ErrNoDeployKeySecret is returned when deleting the orphaned deploy keys of a repository whose Secret
+// used by Flux doesn't exist, as it may be in another namespace or on another cluster.
+var ErrNoDeployKeySecret = errors.New("the Secret of the deploy key used by Flux was not found")
+
+// DeleteDeployKeysOptions tell which orphaned deploy keys are deleted.
+type DeleteDeployKeysOptions struct {
+	// IDs are the keys deleted besides the ones replaced by rotating the key of the Secret used by Flux,
+	// e.g. the keys of a deleted cluster.
+	IDs []string
+	// DryRun returns the keys that would be deleted, without deleting them.
+	DryRun bool
+}
+
+// DeployKeyStatus is a deploy key uploaded by gitops, and whether Flux uses it.
+type DeployKeyStatus struct {
+	gitproviders.DeployKey
+	// InUse is true for the key of the Secret used by Flux.
+	InUse bool
+	// Replaced is true for the keys replaced by rotating the key of the Secret used by Flux.
+	Replaced bool
+}
+
+// ListDeployKeys returns the deploy keys gitops uploaded to the repository.
+func (a *authSvc) ListDeployKeys(ctx context.Context, namespace string, repo gitproviders.RepoURL) ([]DeployKeyStatus, error) {
+	secret, err := a.currentDeployKeySecret(ctx, namespace, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, replaced := currentPublicKey(secret), replacedDeployKeys(secret)
+
+	keys, err := a.gitProvider.ListDeployKeys(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []DeployKeyStatus{}
+	for _, k := range keys {
+		statuses = append(statuses, DeployKeyStatus{
+			DeployKey: k,
+			InUse:     gitproviders.SameDeployKey(k.Key, publicKey),
+			Replaced:  replaced[gitproviders.DeployKeyFingerprint(k.Key)],
+		})
+	}
+
+	return statuses, nil
+}
+
+// RotateDeployKey replaces the deploy key of the repository when it is older than maxAge, or whatever
+// its age when maxAge is 0. The new key is uploaded and checked to grant access to the repository
+// before the Secret used by Flux is updated, then the old key is deleted. The Secret records the
+// fingerprint of the old key, so that DeleteOrphanedDeployKeys deletes it if it's left behind. It
+// returns whether the key was rotated.
+func (a *authSvc) RotateDeployKey(ctx context.Context, namespace string, repo gitproviders.RepoURL, maxAge time.Duration) (bool, error) {
+	secretName := SecretName{
+		Name:      models.CreateRepoSecretName(repo),
+		Namespace: namespace,
+	}
+
+	secret, err := a.retrieveDeployKey(ctx, secretName)
+	if err != nil {
+		return false, fmt.Errorf("error retrieving deploy key: %w", err)
+	}
+
+	if maxAge > 0 && time.Since(deployKeyCreated(secret)) < maxAge {
+		return false, nil
+	}
+
+	oldPublicKey := extractPublicKey(secret)
+
+	oldKeys, err := a.gitProvider.ListDeployKeys(ctx, repo)
+	if err != nil {
+		return false, err
+	}
+
+	deployKey, newSecret, err := a.generateDeployKey(secretName, repo)
+	if err != nil {
+		return false, fmt.Errorf("error generating deploy key: %w", err)
+	}
+
+	publicKey := extractPublicKey(newSecret)
+
+	if err := a.gitProvider.UploadNamedDeployKey(ctx, repo, gitproviders.RotatedDeployKeyName(time.Now()), publicKey); err != nil {
+		return false, fmt.Errorf("error uploading deploy key: %w", err)
+	}
+
+	if err := a.verifyDeployKey(ctx, repo, deployKey); err != nil {
+		return false, a.revertDeployKey(ctx, repo, publicKey, fmt.Errorf("error verifying the new deploy key: %w", err))
+	}
+
+	// Flux reads the keys from the data of the Secret, flux generates them as string data.
+	secret.Data = map[string][]byte{}
+	for k, v := range newSecret.Data {
+		secret.Data[k] = v
+	}
+
+	for k, v := range newSecret.StringData {
+		secret.Data[k] = []byte(v)
+	}
+
+	secret.StringData = nil
+	annotateDeployKey(secret)
+	recordReplacedDeployKey(secret, oldPublicKey, oldKeys)
+
+	if err := a.k8sClient.Update(ctx, secret); err != nil {
+		return false, a.revertDeployKey(ctx, repo, publicKey, fmt.Errorf("could not update secret: %w", err))
+	}
+
+	for _, k := range oldKeys {
+		if !gitproviders.SameDeployKey(k.Key, oldPublicKey) {
+			continue
+		}
+
+		if err := a.gitProvider.DeleteDeployKey(ctx, repo, k.ID); err != nil {
+			return true, fmt.Errorf("the deploy key was rotated, but the old key %s could not be deleted: %w", k.Name, err)
+		}
+	}
+
+	a.log.Successf("Deploy key of %s rotated", repo)
+
+	return true, nil
+}
+
+// DeleteOrphanedDeployKeys deletes the deploy keys replaced by rotating the key of the Secret used by Flux,
+// and those of opts.IDs, and returns them. The keys of the other clusters using the repository are only
+// deleted when their IDs are given, and the key used by Flux never is. When the Secret used by Flux doesn't
+// exist, ErrNoDeployKeySecret is returned unless IDs are given.
+func (a *authSvc) DeleteOrphanedDeployKeys(ctx context.Context, namespace string, repo gitproviders.RepoURL, opts DeleteDeployKeysOptions) ([]gitproviders.DeployKey, error) {
+	secret, err := a.currentDeployKeySecret(ctx, namespace, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if secret == nil && len(opts.IDs) == 0 {
+		return nil, fmt.Errorf("%w in namespace %s, the keys it replaced are unknown", ErrNoDeployKeySecret, namespace)
+	}
+
+	publicKey, replaced := currentPublicKey(secret), replacedDeployKeys(secret)
+
+	keys, err := a.gitProvider.ListDeployKeys(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	requested := map[string]bool{}
+	for _, id := range opts.IDs {
+		requested[id] = true
+	}
+
+	found := map[string]bool{}
+
+	for _, k := range keys {
+		if requested[k.ID] && gitproviders.SameDeployKey(k.Key, publicKey) {
+			return nil, fmt.Errorf("deploy key %s (%s) is used by Flux", k.Name, k.ID)
+		}
+
+		found[k.ID] = true
+	}
+
+	for _, id := range opts.IDs {
+		if !found[id] {
+			return nil, fmt.Errorf("deploy key %s not found in %s", id, repo)
+		}
+	}
+
+	deleted := []gitproviders.DeployKey{}
+
+	for _, k := range keys {
+		if gitproviders.SameDeployKey(k.Key, publicKey) {
+			continue
+		}
+
+		if !requested[k.ID] && !replaced[gitproviders.DeployKeyFingerprint(k.Key)] {
+			continue
+		}
+
+		if opts.DryRun {
+			deleted = append(deleted, k)
+			continue
+		}
+
+		if err := a.gitProvider.DeleteDeployKey(ctx, repo, k.ID); err != nil {
+			return deleted, err
+		}
+
+		deleted = append(deleted, k)
+	}
+
+	return deleted, nil
+}
+
+// currentDeployKeySecret returns the Secret used by Flux, nil if there is none.
+func (a *authSvc) currentDeployKeySecret(ctx context.Context, namespace string, repo gitproviders.RepoURL) (*corev1.Secret, error) {
+	secret, err := a.retrieveDeployKey(ctx, SecretName{
+		Name:      models.CreateRepoSecretName(repo),
+		Namespace: namespace,
+	})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error retrieving deploy key: %w", err)
+	}
+
+	return secret, nil
+}
+
+// verifyDeployKey checks that the key grants access to the default branch of the repository.
+func (a *authSvc) verifyDeployKey(ctx context.Context, repo gitproviders.RepoURL, deployKey *ssh.PublicKeys) error {
+	branch, err := a.gitProvider.GetDefaultBranch(ctx, repo)
+	if err != nil {
+		return fmt.Errorf("error getting default branch: %w", err)
+	}
+
+	return a.validateAccess(ctx, deployKey, repo.String(), branch)
+}
+
+// revertDeployKey deletes a new deploy key that couldn't be put in use, and returns err.
+func (a *authSvc) revertDeployKey(ctx context.Context, repo gitproviders.RepoURL, publicKey []byte, err error) error {
+	keys, listErr := a.gitProvider.ListDeployKeys(ctx, repo)
+	if listErr != nil {
+		a.log.Warningf("The new deploy key could not be deleted: %v", listErr)
+		return err
+	}
+
+	for _, k := range keys {
+		if !gitproviders.SameDeployKey(k.Key, publicKey) {
+			continue
+		}
+
+		if deleteErr := a.gitProvider.DeleteDeployKey(ctx, repo, k.ID); deleteErr != nil {
+			a.log.Warningf("The new deploy key %s could not be deleted: %v", k.Name, deleteErr)
+		}
+	}
+
+	return err
+}
+
+// validateGitAccess checks that the repository can be cloned with a deploy key, waiting for it to
+// grant access as the providers take a few seconds to accept new keys. It returns the last error.
+func validateGitAccess(ctx context.Context, deployKey *ssh.PublicKeys, url, branch string) error {
+	var err error
+
+	if waitErr := utils.WaitUntil(ioutil.Discard, time.Second, deployKeyPropagationTimeout, func() error {
+		err = git.New(deployKey, wrapper.NewGoGit()).ValidateAccess(ctx, url, branch)
+		return err
+	}); waitErr != nil {
+		return err
+	}
+
+	return nil
+}
+
+// annotateDeployKey records that the key of the Secret was generated now.
+func annotateDeployKey(secret *corev1.Secret) {
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+
+	secret.Annotations[DeployKeyCreatedAnnotation] = time.Now().UTC().Format(time.RFC3339)
+}
+
+// currentPublicKey returns the public key of the Secret used by Flux, nil if there is none.
+func currentPublicKey(secret *corev1.Secret) []byte {
+	if secret == nil {
+		return nil
+	}
+
+	return extractPublicKey(secret)
+}
+
+// recordReplacedDeployKey adds the fingerprint of the key replaced by a rotation to the Secret. The
+// fingerprints of the keys no longer uploaded to the repository are dropped.
+func recordReplacedDeployKey(secret *corev1.Secret, replaced []byte, uploaded []gitproviders.DeployKey) {
+	previous := replacedDeployKeys(secret)
+	fingerprints := []string{gitproviders.DeployKeyFingerprint(replaced)}
+
+	for _, k := range uploaded {
+		if fingerprint := gitproviders.DeployKeyFingerprint(k.Key); previous[fingerprint] {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+	}
+
+	secret.Annotations[ReplacedDeployKeysAnnotation] = strings.Join(fingerprints, ",")
+}
+
+// replacedDeployKeys returns the fingerprints of the keys replaced by rotating the key of the Secret.
+func replacedDeployKeys(secret *corev1.Secret) map[string]bool {
+	fingerprints := map[string]bool{}
+
+	if secret == nil || secret.Annotations[ReplacedDeployKeysAnnotation] == "" {
+		return fingerprints
+	}
+
+	for _, fingerprint := range strings.Split(secret.Annotations[ReplacedDeployKeysAnnotation], ",") {
+		fingerprints[fingerprint] = true
+	}
+
+	return fingerprints
+}
+
+// deployKeyCreated returns when the key of the Secret was generated, which is when the Secret was
+// created unless it was rotated.
+func deployKeyCreated(secret *corev1.Secret) time.Time {
+	if created, err := time.Parse(time.RFC3339, secret.Annotations[DeployKeyCreatedAnnotation]); err == nil {
+		return created
+	}
+
+	return secret.CreationTimestamp.Time
+}
@@ -0,0 +1,301 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	gossh "golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/yaml"
+
+	"github.com/weaveworks/weave-gitops/pkg/flux/fluxfakes"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders/gitprovidersfakes"
+	"github.com/weaveworks/weave-gitops/pkg/logger/loggerfakes"
+	"github.com/weaveworks/weave-gitops/pkg/models"
+)
+
+// newKeyPairSecret returns a Secret like the ones generated by flux.
+func newKeyPairSecret(name, namespace string) *corev1.Secret {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+
+	publicKey, err := gossh.NewPublicKey(&key.PublicKey)
+	Expect(err).NotTo(HaveOccurred())
+
+	return &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		StringData: map[string]string{
+			"identity":     string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+			"identity.pub": string(gossh.MarshalAuthorizedKey(publicKey)),
+			"known_hosts":  "github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl",
+		},
+	}
+}
+
+var _ = Describe("Deploy keys", func() {
+	var (
+		ctx        context.Context
+		namespace  string
+		repoUrl    gitproviders.RepoURL
+		secretName SecretName
+		keys       []gitproviders.DeployKey
+		gp         *gitprovidersfakes.FakeGitProvider
+		validated  []*ssh.PublicKeys
+		as         *authSvc
+	)
+
+	// uploaded adds a key to the repository and returns its public key.
+	uploaded := func(name string, secret *corev1.Secret) []byte {
+		publicKey := extractPublicKey(secret)
+		keys = append(keys, gitproviders.DeployKey{ID: fmt.Sprint(len(keys) + 1), Name: name, Key: publicKey})
+
+		return publicKey
+	}
+
+	// stored creates the Secret used by Flux, which replaced the keys of replaced.
+	stored := func(secret *corev1.Secret, created time.Time, replaced ...[]byte) {
+		secret.Annotations = map[string]string{DeployKeyCreatedAnnotation: created.UTC().Format(time.RFC3339)}
+
+		if len(replaced) > 0 {
+			fingerprints := []string{}
+			for _, k := range replaced {
+				fingerprints = append(fingerprints, gitproviders.DeployKeyFingerprint(k))
+			}
+
+			secret.Annotations[ReplacedDeployKeysAnnotation] = strings.Join(fingerprints, ",")
+		}
+
+		Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespace = "deploy-keys-" + utilrand.String(5)
+
+		var err error
+		repoUrl, err = gitproviders.NewRepoURL("ssh://git@github.com/my-org/my-repo.git")
+		Expect(err).NotTo(HaveOccurred())
+
+		secretName = SecretName{Name: models.CreateRepoSecretName(repoUrl), Namespace: namespace}
+
+		keys = nil
+		gp = &gitprovidersfakes.FakeGitProvider{}
+		gp.GetDefaultBranchReturns("main", nil)
+		gp.ListDeployKeysStub = func(context.Context, gitproviders.RepoURL) ([]gitproviders.DeployKey, error) {
+			return append([]gitproviders.DeployKey{}, keys...), nil
+		}
+		gp.UploadNamedDeployKeyStub = func(_ context.Context, _ gitproviders.RepoURL, name string, key []byte) error {
+			keys = append(keys, gitproviders.DeployKey{ID: fmt.Sprint(len(keys) + 1), Name: name, Key: key})
+			return nil
+		}
+		gp.DeleteDeployKeyStub = func(_ context.Context, _ gitproviders.RepoURL, id string) error {
+			for i, k := range keys {
+				if k.ID == id {
+					keys = append(keys[:i], keys[i+1:]...)
+					return nil
+				}
+			}
+
+			return errors.New("not found")
+		}
+
+		fluxClient := &fluxfakes.FakeFlux{}
+		fluxClient.CreateSecretGitStub = func(name string, _ gitproviders.RepoURL, namespace string) ([]byte, error) {
+			return yaml.Marshal(newKeyPairSecret(name, namespace))
+		}
+
+		validated = nil
+		as = &authSvc{
+			log:         &loggerfakes.FakeLogger{},
+			fluxClient:  fluxClient,
+			k8sClient:   k8sClient,
+			gitProvider: gp,
+			validateAccess: func(_ context.Context, deployKey *ssh.PublicKeys, url, branch string) error {
+				Expect(url).To(Equal(repoUrl.String()))
+				Expect(branch).To(Equal("main"))
+				validated = append(validated, deployKey)
+
+				return nil
+			},
+		}
+	})
+
+	It("lists the keys uploaded by gitops and the one used by Flux", func() {
+		secret := newKeyPairSecret(secretName.Name.String(), namespace)
+		uploaded(gitproviders.DeployKeyName, newKeyPairSecret("old", namespace))
+		uploaded(gitproviders.RotatedDeployKeyName(time.Now()), secret)
+		stored(secret, time.Now())
+
+		statuses, err := as.ListDeployKeys(ctx, namespace, repoUrl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statuses).To(HaveLen(2))
+		Expect(statuses[0].InUse).To(BeFalse())
+		Expect(statuses[1].InUse).To(BeTrue())
+	})
+
+	It("rotates the key, updating the Secret before deleting the old key", func() {
+		secret := newKeyPairSecret(secretName.Name.String(), namespace)
+		oldPublicKey := uploaded(gitproviders.DeployKeyName, secret)
+		stored(secret, time.Now().Add(-91*24*time.Hour))
+
+		rotated, err := as.RotateDeployKey(ctx, namespace, repoUrl, 90*24*time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rotated).To(BeTrue())
+
+		updated := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, secretName.NamespacedName(), updated)).To(Succeed())
+		Expect(gitproviders.SameDeployKey(updated.Data["identity.pub"], oldPublicKey)).To(BeFalse())
+		Expect(time.Since(deployKeyCreated(updated))).To(BeNumerically("<", time.Minute))
+		Expect(updated.Annotations).To(HaveKeyWithValue(ReplacedDeployKeysAnnotation, gitproviders.DeployKeyFingerprint(oldPublicKey)))
+
+		Expect(keys).To(HaveLen(1))
+		Expect(keys[0].Name).To(HavePrefix(gitproviders.DeployKeyName + "-"))
+		Expect(gitproviders.SameDeployKey(keys[0].Key, updated.Data["identity.pub"])).To(BeTrue())
+
+		Expect(validated).To(HaveLen(1))
+		Expect(validated[0].Signer.PublicKey().Marshal()).To(Equal(mustParsePublicKey(keys[0].Key).Marshal()))
+	})
+
+	It("doesn't rotate keys younger than the max age", func() {
+		secret := newKeyPairSecret(secretName.Name.String(), namespace)
+		uploaded(gitproviders.DeployKeyName, secret)
+		stored(secret, time.Now().Add(-30*24*time.Hour))
+
+		rotated, err := as.RotateDeployKey(ctx, namespace, repoUrl, 90*24*time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rotated).To(BeFalse())
+		Expect(gp.UploadNamedDeployKeyCallCount()).To(Equal(0))
+	})
+
+	It("deletes the new key and keeps the Secret when the new key doesn't grant access", func() {
+		secret := newKeyPairSecret(secretName.Name.String(), namespace)
+		oldPublicKey := uploaded(gitproviders.DeployKeyName, secret)
+		stored(secret, time.Now())
+
+		as.validateAccess = func(context.Context, *ssh.PublicKeys, string, string) error {
+			return errors.New("permission denied")
+		}
+
+		_, err := as.RotateDeployKey(ctx, namespace, repoUrl, 0)
+		Expect(err).To(MatchError(ContainSubstring("permission denied")))
+
+		Expect(keys).To(HaveLen(1))
+		Expect(keys[0].Key).To(Equal(oldPublicKey))
+
+		current := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, secretName.NamespacedName(), current)).To(Succeed())
+		Expect(gitproviders.SameDeployKey(extractPublicKey(current), oldPublicKey)).To(BeTrue())
+	})
+
+	It("fails to rotate when there is no Secret", func() {
+		_, err := as.RotateDeployKey(ctx, namespace, repoUrl, 0)
+		Expect(err).To(MatchError(ContainSubstring("error retrieving deploy key")))
+	})
+
+	It("deletes the keys replaced by rotations, keeping those of the other clusters", func() {
+		secret := newKeyPairSecret(secretName.Name.String(), namespace)
+		oldPublicKey := uploaded(gitproviders.DeployKeyName, newKeyPairSecret("old", namespace))
+		uploaded(gitproviders.DeployKeyName, newKeyPairSecret("other-cluster", namespace))
+		publicKey := uploaded(gitproviders.RotatedDeployKeyName(time.Now()), secret)
+		stored(secret, time.Now(), oldPublicKey)
+
+		deleted, err := as.DeleteOrphanedDeployKeys(ctx, namespace, repoUrl, DeleteDeployKeysOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(HaveLen(1))
+		Expect(deleted[0].Key).To(Equal(oldPublicKey))
+
+		Expect(keys).To(HaveLen(2))
+		Expect(keys[0].ID).To(Equal("2"))
+		Expect(keys[1].Key).To(Equal(publicKey))
+	})
+
+	It("deletes the old key a rotation failed to delete", func() {
+		secret := newKeyPairSecret(secretName.Name.String(), namespace)
+		oldPublicKey := uploaded(gitproviders.DeployKeyName, secret)
+		stored(secret, time.Now())
+
+		deleteDeployKey := gp.DeleteDeployKeyStub
+		gp.DeleteDeployKeyReturns(errors.New("service unavailable"))
+
+		rotated, err := as.RotateDeployKey(ctx, namespace, repoUrl, 0)
+		Expect(err).To(MatchError(ContainSubstring("the old key %s could not be deleted", gitproviders.DeployKeyName)))
+		Expect(rotated).To(BeTrue())
+		Expect(keys).To(HaveLen(2))
+
+		gp.DeleteDeployKeyStub = deleteDeployKey
+
+		deleted, err := as.DeleteOrphanedDeployKeys(ctx, namespace, repoUrl, DeleteDeployKeysOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(HaveLen(1))
+		Expect(deleted[0].Key).To(Equal(oldPublicKey))
+		Expect(keys).To(HaveLen(1))
+	})
+
+	It("only lists the keys it would delete in dry run", func() {
+		secret := newKeyPairSecret(secretName.Name.String(), namespace)
+		oldPublicKey := uploaded(gitproviders.DeployKeyName, newKeyPairSecret("old", namespace))
+		uploaded(gitproviders.RotatedDeployKeyName(time.Now()), secret)
+		stored(secret, time.Now(), oldPublicKey)
+
+		deleted, err := as.DeleteOrphanedDeployKeys(ctx, namespace, repoUrl, DeleteDeployKeysOptions{DryRun: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(HaveLen(1))
+		Expect(deleted[0].Name).To(Equal(gitproviders.DeployKeyName))
+		Expect(keys).To(HaveLen(2))
+		Expect(gp.DeleteDeployKeyCallCount()).To(Equal(0))
+	})
+
+	It("refuses to delete the keys when there is no Secret", func() {
+		uploaded(gitproviders.DeployKeyName, newKeyPairSecret("old", namespace))
+
+		_, err := as.DeleteOrphanedDeployKeys(ctx, namespace, repoUrl, DeleteDeployKeysOptions{})
+		Expect(err).To(MatchError(ErrNoDeployKeySecret))
+		Expect(keys).To(HaveLen(1))
+	})
+
+	It("deletes the keys given by their IDs", func() {
+		uploaded(gitproviders.DeployKeyName, newKeyPairSecret("other-cluster", namespace))
+		uploaded(gitproviders.DeployKeyName, newKeyPairSecret("deleted-cluster", namespace))
+
+		deleted, err := as.DeleteOrphanedDeployKeys(ctx, namespace, repoUrl, DeleteDeployKeysOptions{IDs: []string{"2"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(HaveLen(1))
+		Expect(deleted[0].ID).To(Equal("2"))
+		Expect(keys).To(HaveLen(1))
+		Expect(keys[0].ID).To(Equal("1"))
+	})
+
+	It("refuses to delete the key used by Flux or unknown keys", func() {
+		secret := newKeyPairSecret(secretName.Name.String(), namespace)
+		uploaded(gitproviders.DeployKeyName, secret)
+		stored(secret, time.Now())
+
+		_, err := as.DeleteOrphanedDeployKeys(ctx, namespace, repoUrl, DeleteDeployKeysOptions{IDs: []string{"1"}})
+		Expect(err).To(MatchError(ContainSubstring("is used by Flux")))
+
+		_, err = as.DeleteOrphanedDeployKeys(ctx, namespace, repoUrl, DeleteDeployKeysOptions{IDs: []string{"7"}})
+		Expect(err).To(MatchError(ContainSubstring("deploy key 7 not found")))
+		Expect(keys).To(HaveLen(1))
+	})
+})
+
+func mustParsePublicKey(b []byte) gossh.PublicKey {
+	key, _, _, _, err := gossh.ParseAuthorizedKey(b)
+	Expect(err).NotTo(HaveOccurred())
+
+	return key
+}
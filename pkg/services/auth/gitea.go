@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type giteaAuth struct {
+	http *http.Client
+	host string
+}
+
+// NewGiteaAuthClient returns a validator for the tokens of the Gitea instance running on host.
+func NewGiteaAuthClient(client *http.Client, host string) ProviderTokenValidator {
+	return giteaAuth{http: client, host: host}
+}
+
+func (g giteaAuth) ValidateToken(ctx context.Context, token string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://%s/api/v1/user", g.host), nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+
+	res, err := g.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("invalid token: %s", res.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/vendorfakes/fakehttp"
+)
+
+var _ = Describe("GiteaAuthClient", func() {
+	Describe("ValidateToken", func() {
+		It("returns an error when a 401 is returned", func() {
+			rt := fakehttp.RoundTripper{}
+			rt.RoundTripReturns(&http.Response{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized", Body: ioutil.NopCloser(strings.NewReader(""))}, nil)
+			c := NewGiteaAuthClient(&http.Client{Transport: &rt}, "gitea.example.com")
+
+			Expect(c.ValidateToken(context.Background(), "sometoken")).To(MatchError("invalid token: 401 Unauthorized"))
+		})
+		It("does not return an error when a token is valid", func() {
+			rt := fakehttp.RoundTripper{}
+			rt.RoundTripReturns(&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("{}"))}, nil)
+			c := NewGiteaAuthClient(&http.Client{Transport: &rt}, "gitea.example.com")
+
+			Expect(c.ValidateToken(context.Background(), "sometoken")).To(Succeed())
+
+			req := rt.RoundTripArgsForCall(0)
+			Expect(req.URL.String()).To(Equal("https://gitea.example.com/api/v1/user"))
+			Expect(req.Header.Get("Authorization")).To(Equal("token sometoken"))
+		})
+	})
+})
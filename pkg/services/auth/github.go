@@ -58,6 +58,25 @@ func (g ghAuth) ValidateToken(ctx context.Context, token string) error {
 
 	_, err = doRequest(req, g.http)
 
+	var gerr GitHubError
+	if errors.As(err, &gerr) && gerr.StatusCode == http.StatusForbidden {
+		// the installation tokens of GitHub Apps have no user, check the installation instead
+		return g.validateInstallationToken(ctx, token)
+	}
+
+	return err
+}
+
+func (g ghAuth) validateInstallationToken(ctx context.Context, token string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.github.com/installation/repositories?per_page=1", nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+
+	_, err = doRequest(req, g.http)
+
 	return err
 }
 
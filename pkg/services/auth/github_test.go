@@ -2,7 +2,9 @@ package auth
 
 import (
 	"context"
+	"io/ioutil"
 	"net/http"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -33,4 +35,16 @@ var _ = Describe("ValidateToken", func() {
 
 		Expect(gh.ValidateToken(context.Background(), "sometoken")).NotTo(HaveOccurred())
 	})
+	It("validates the installation tokens of GitHub Apps", func() {
+		rt := &fakehttp.RoundTripper{}
+		gh := NewGithubAuthClient(&http.Client{Transport: rt})
+		rt.RoundTripReturnsOnCall(0, &http.Response{
+			StatusCode: http.StatusForbidden,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Resource not accessible by integration"}`)),
+		}, nil)
+		rt.RoundTripReturnsOnCall(1, &http.Response{StatusCode: http.StatusOK}, nil)
+
+		Expect(gh.ValidateToken(context.Background(), "ghs_token")).NotTo(HaveOccurred())
+		Expect(rt.RoundTripArgsForCall(1).URL.String()).To(Equal("https://api.github.com/installation/repositories?per_page=1"))
+	})
 })
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// TokenCache remembers the tokens validated by the providers, so that the UI checking its token on
+// each page doesn't use up the rate limits of the provider APIs.
+type TokenCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu sync.Mutex
+	// valid are the expiry times of the validations, by provider, host and hashed token
+	valid map[string]time.Time
+}
+
+// NewTokenCache returns a TokenCache keeping the successful validations for ttl. Failed ones
+// aren't cached.
+func NewTokenCache(ttl time.Duration) *TokenCache {
+	return &TokenCache{
+		ttl:   ttl,
+		now:   time.Now,
+		valid: map[string]time.Time{},
+	}
+}
+
+// Wrap returns a validator calling v for the tokens that weren't validated for the provider
+// on this host yet.
+func (c *TokenCache) Wrap(provider, host string, v ProviderTokenValidator) ProviderTokenValidator {
+	return cachedTokenValidator{cache: c, provider: provider, host: host, next: v}
+}
+
+type cachedTokenValidator struct {
+	cache    *TokenCache
+	provider string
+	host     string
+	next     ProviderTokenValidator
+}
+
+func (v cachedTokenValidator) ValidateToken(ctx context.Context, token string) error {
+	// the tokens aren't kept in memory
+	sum := sha256.Sum256([]byte(v.provider + "\n" + v.host + "\n" + token))
+	key := string(sum[:])
+
+	c := v.cache
+
+	c.mu.Lock()
+	expires, ok := c.valid[key]
+	c.mu.Unlock()
+
+	if ok && c.now().Before(expires) {
+		return nil
+	}
+
+	if err := v.next.ValidateToken(ctx, token); err != nil {
+		return err
+	}
+
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, e := range c.valid {
+		if !now.Before(e) {
+			delete(c.valid, k)
+		}
+	}
+
+	c.valid[key] = now.Add(c.ttl)
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/vendorfakes/fakehttp"
+)
+
+var _ = Describe("TokenCache", func() {
+	var (
+		rt    *fakehttp.RoundTripper
+		cache *TokenCache
+		now   time.Time
+	)
+
+	validator := func(host string) ProviderTokenValidator {
+		return cache.Wrap("gitea", host, NewGiteaAuthClient(&http.Client{Transport: rt}, host))
+	}
+
+	BeforeEach(func() {
+		rt = &fakehttp.RoundTripper{}
+		rt.RoundTripStub = func(*http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("{}"))}, nil
+		}
+
+		now = time.Now()
+		cache = NewTokenCache(time.Minute)
+		cache.now = func() time.Time { return now }
+	})
+
+	It("validates a token once per host until it expires", func() {
+		Expect(validator("gitea.example.com").ValidateToken(context.Background(), "sometoken")).To(Succeed())
+		Expect(validator("gitea.example.com").ValidateToken(context.Background(), "sometoken")).To(Succeed())
+		Expect(rt.RoundTripCallCount()).To(Equal(1))
+
+		Expect(validator("gitea.example.org").ValidateToken(context.Background(), "sometoken")).To(Succeed())
+		Expect(validator("gitea.example.com").ValidateToken(context.Background(), "othertoken")).To(Succeed())
+		Expect(rt.RoundTripCallCount()).To(Equal(3))
+
+		now = now.Add(2 * time.Minute)
+
+		Expect(validator("gitea.example.com").ValidateToken(context.Background(), "sometoken")).To(Succeed())
+		Expect(rt.RoundTripCallCount()).To(Equal(4))
+	})
+
+	It("doesn't cache invalid tokens", func() {
+		rt.RoundTripStub = nil
+		rt.RoundTripReturns(&http.Response{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized", Body: ioutil.NopCloser(strings.NewReader(""))}, nil)
+
+		Expect(validator("gitea.example.com").ValidateToken(context.Background(), "sometoken")).To(HaveOccurred())
+		Expect(validator("gitea.example.com").ValidateToken(context.Background(), "sometoken")).To(HaveOccurred())
+		Expect(rt.RoundTripCallCount()).To(Equal(2))
+	})
+})
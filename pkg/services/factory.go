@@ -2,10 +2,12 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/weaveworks/weave-gitops/pkg/flux"
 	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/git/wrapper"
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 	"github.com/weaveworks/weave-gitops/pkg/kube"
 	"github.com/weaveworks/weave-gitops/pkg/logger"
@@ -18,6 +20,7 @@ import (
 // Factory provides helpers for generating various WeGO service objects at runtime.
 type Factory interface {
 	GetGitClients(ctx context.Context, kubeClient kube.Kube, gpClient gitproviders.Client, params GitConfigParams) (git.Git, gitproviders.GitProvider, error)
+	GetAuthService(kubeClient kube.Kube, gpClient gitproviders.Client, params GitConfigParams) (auth.AuthService, error)
 }
 
 type GitConfigParams struct {
@@ -26,6 +29,9 @@ type GitConfigParams struct {
 	Namespace        string
 	IsHelmRepository bool
 	DryRun           bool
+	// GitOptions configure the git client, they take precedence over the authenticated user of
+	// the git provider, which is the default author of the commits.
+	GitOptions []git.Option
 }
 
 type defaultFactory struct {
@@ -46,12 +52,20 @@ func (f *defaultFactory) GetGitClients(ctx context.Context, kubeClient kube.Kube
 		return nil, nil, fmt.Errorf("error normalizing config url: %w", err)
 	}
 
+	if configNormalizedUrl.Provider() == gitproviders.GitProviderGit && !params.DryRun {
+		return f.getPlainGitClients(ctx, configNormalizedUrl, gpClient, params)
+	}
+
+	if configNormalizedUrl.Protocol() == gitproviders.RepositoryURLProtocolHTTPS && !params.DryRun {
+		return f.getHTTPSGitClients(ctx, configNormalizedUrl, gpClient, params)
+	}
+
 	authSvc, err := f.getAuthService(kubeClient, configNormalizedUrl, gpClient, params.DryRun)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error getting auth service: %w", err)
 	}
 
-	client, err := authSvc.CreateGitClient(ctx, configNormalizedUrl, params.Namespace, params.DryRun)
+	client, err := authSvc.CreateGitClient(ctx, configNormalizedUrl, params.Namespace, params.DryRun, f.gitOptions(ctx, authSvc.GetGitProvider(), params)...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -59,6 +73,35 @@ func (f *defaultFactory) GetGitClients(ctx context.Context, kubeClient kube.Kube
 	return client, authSvc.GetGitProvider(), nil
 }
 
+// GetAuthService returns the service managing the deploy keys of the config repository.
+func (f *defaultFactory) GetAuthService(kubeClient kube.Kube, gpClient gitproviders.Client, params GitConfigParams) (auth.AuthService, error) {
+	configNormalizedUrl, err := gitproviders.NewRepoURL(params.ConfigRepo)
+	if err != nil {
+		return nil, fmt.Errorf("error normalizing config url: %w", err)
+	}
+
+	return f.getAuthService(kubeClient, configNormalizedUrl, gpClient, params.DryRun)
+}
+
+// gitOptions returns the options of the git client, which commits as the authenticated user of the
+// git provider unless the options of params configure another author.
+func (f *defaultFactory) gitOptions(ctx context.Context, gitProvider gitproviders.GitProvider, params GitConfigParams) []git.Option {
+	if params.DryRun {
+		return params.GitOptions
+	}
+
+	user, err := gitProvider.GetAuthenticatedUser(ctx)
+	if err != nil {
+		if !errors.Is(err, gitproviders.ErrNoProviderAPI) {
+			f.log.Warningf("Committing as %s, the git provider user could not be found: %v", git.DefaultAuthor.Name, err)
+		}
+
+		return params.GitOptions
+	}
+
+	return append([]git.Option{git.WithAuthor(user)}, params.GitOptions...)
+}
+
 func (f *defaultFactory) getAuthService(kubeClient kube.Kube, normalizedUrl gitproviders.RepoURL, gpClient gitproviders.Client, dryRun bool) (auth.AuthService, error) {
 	var (
 		gitProvider gitproviders.GitProvider
@@ -77,3 +120,32 @@ func (f *defaultFactory) getAuthService(kubeClient kube.Kube, normalizedUrl gitp
 
 	return auth.NewAuthService(f.fluxClient, kubeClient.Raw(), gitProvider, f.log)
 }
+
+// getPlainGitClients returns the clients of git remotes without a hosting API. Deploy keys can't be
+// uploaded there, the remote is accessed with the credentials of the user, e.g. the SSH agent.
+func (f *defaultFactory) getPlainGitClients(ctx context.Context, normalizedUrl gitproviders.RepoURL, gpClient gitproviders.Client, params GitConfigParams) (git.Git, gitproviders.GitProvider, error) {
+	gitProvider, err := gpClient.GetProvider(normalizedUrl, gitproviders.GetAccountType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating git provider client: %w", err)
+	}
+
+	return git.New(nil, wrapper.NewGoGit(), f.gitOptions(ctx, gitProvider, params)...), gitProvider, nil
+}
+
+// getHTTPSGitClients returns the clients of HTTPS remotes, which are accessed with the token of the
+// git provider instead of deploy keys.
+func (f *defaultFactory) getHTTPSGitClients(ctx context.Context, normalizedUrl gitproviders.RepoURL, gpClient gitproviders.Client, params GitConfigParams) (git.Git, gitproviders.GitProvider, error) {
+	token, err := gpClient.GetToken(normalizedUrl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error obtaining git provider token: %w", err)
+	}
+
+	gitProvider, err := gpClient.GetProvider(normalizedUrl, gitproviders.GetAccountType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating git provider client: %w", err)
+	}
+
+	auth := git.NewHTTPSAuth(gitproviders.HTTPSUsername(normalizedUrl.Provider()), token)
+
+	return git.New(auth, wrapper.NewGoGit(), f.gitOptions(ctx, gitProvider, params)...), gitProvider, nil
+}
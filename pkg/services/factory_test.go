@@ -2,10 +2,16 @@ package services
 
 import (
 	"context"
+	"errors"
+	"io/ioutil"
+	"os"
 
+	gogit "github.com/go-git/go-git/v5"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
 	"github.com/weaveworks/weave-gitops/pkg/flux/fluxfakes"
+	"github.com/weaveworks/weave-gitops/pkg/git"
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders/gitprovidersfakes"
 	"github.com/weaveworks/weave-gitops/pkg/kube/kubefakes"
 	"github.com/weaveworks/weave-gitops/pkg/logger/loggerfakes"
@@ -48,5 +54,111 @@ var _ = Describe("Services factory", func() {
 			Expect(gitProvider).To(BeNil())
 			Expect(err.Error()).To(MatchRegexp("error normalizing config url*."))
 		})
+
+		It("skips deploy keys for plain git remotes", func() {
+			viper.Set("git-host-types", "git.example.com=git")
+			defer viper.Set("git-host-types", "")
+
+			fakeProvider := &gitprovidersfakes.FakeGitProvider{}
+			fakeClient.GetProviderReturns(fakeProvider, nil)
+
+			gitClient, gitProvider, err := factory.GetGitClients(ctx, fakeKube, fakeClient, GitConfigParams{
+				ConfigRepo: "ssh://git@git.example.com/owner/config-repo.git",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gitClient).NotTo(BeNil())
+			Expect(gitProvider).To(Equal(fakeProvider))
+			Expect(fakeKube.RawCallCount()).To(Equal(0))
+		})
+
+		It("uses the provider token for HTTPS remotes", func() {
+			fakeProvider := &gitprovidersfakes.FakeGitProvider{}
+			fakeClient.GetProviderReturns(fakeProvider, nil)
+			fakeClient.GetTokenReturns("token", nil)
+
+			gitClient, gitProvider, err := factory.GetGitClients(ctx, fakeKube, fakeClient, GitConfigParams{
+				ConfigRepo: "https://github.com/owner/config-repo",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gitClient).NotTo(BeNil())
+			Expect(gitProvider).To(Equal(fakeProvider))
+			Expect(fakeClient.GetTokenArgsForCall(0).String()).To(Equal("https://github.com/owner/config-repo.git"))
+			Expect(fakeKube.RawCallCount()).To(Equal(0))
+		})
+
+		It("fails for HTTPS remotes without a token", func() {
+			fakeClient.GetTokenReturns("", errors.New("no token"))
+
+			_, _, err := factory.GetGitClients(ctx, fakeKube, fakeClient, GitConfigParams{
+				ConfigRepo: "https://github.com/owner/config-repo",
+			})
+			Expect(err).To(MatchError("error obtaining git provider token: no token"))
+		})
+
+		Describe("commit identity", func() {
+			var fakeProvider *gitprovidersfakes.FakeGitProvider
+
+			// headAuthor commits with the git client in a new repository and returns the author of the commit.
+			headAuthor := func(gitClient git.Git) string {
+				dir, err := ioutil.TempDir("", "factory-test-")
+				Expect(err).NotTo(HaveOccurred())
+				DeferCleanup(os.RemoveAll, dir)
+
+				_, err = gitClient.Init(dir, "https://github.com/owner/config-repo.git", "main")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(gitClient.Write("test.txt", []byte("testing"))).To(Succeed())
+				_, err = gitClient.Commit(git.Commit{Message: "test commit"})
+				Expect(err).NotTo(HaveOccurred())
+
+				repo, err := gogit.PlainOpen(dir)
+				Expect(err).NotTo(HaveOccurred())
+
+				head, err := repo.Head()
+				Expect(err).NotTo(HaveOccurred())
+
+				commit, err := repo.CommitObject(head.Hash())
+				Expect(err).NotTo(HaveOccurred())
+
+				return commit.Author.Name + " <" + commit.Author.Email + ">"
+			}
+
+			BeforeEach(func() {
+				fakeProvider = &gitprovidersfakes.FakeGitProvider{}
+				fakeClient.GetProviderReturns(fakeProvider, nil)
+				fakeClient.GetTokenReturns("token", nil)
+			})
+
+			It("commits as the authenticated user of the git provider", func() {
+				fakeProvider.GetAuthenticatedUserReturns(git.Author{Name: "Jane Doe", Email: "jdoe@example.com"}, nil)
+
+				gitClient, _, err := factory.GetGitClients(ctx, fakeKube, fakeClient, GitConfigParams{
+					ConfigRepo: "https://github.com/owner/config-repo",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(headAuthor(gitClient)).To(Equal("Jane Doe <jdoe@example.com>"))
+			})
+
+			It("prefers the author of the git options", func() {
+				fakeProvider.GetAuthenticatedUserReturns(git.Author{Name: "Jane Doe", Email: "jdoe@example.com"}, nil)
+
+				gitClient, _, err := factory.GetGitClients(ctx, fakeKube, fakeClient, GitConfigParams{
+					ConfigRepo: "https://github.com/owner/config-repo",
+					GitOptions: []git.Option{git.WithAuthor(git.Author{Name: "bot", Email: "bot@example.com"})},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(headAuthor(gitClient)).To(Equal("bot <bot@example.com>"))
+			})
+
+			It("warns and commits as the default author without a provider user", func() {
+				fakeProvider.GetAuthenticatedUserReturns(git.Author{}, errors.New("forbidden"))
+
+				gitClient, _, err := factory.GetGitClients(ctx, fakeKube, fakeClient, GitConfigParams{
+					ConfigRepo: "https://github.com/owner/config-repo",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(headAuthor(gitClient)).To(Equal("Weave Gitops <weave-gitops@weave.works>"))
+				Expect(fakeLog.WarningfCallCount()).To(Equal(1))
+			})
+		})
 	})
 })
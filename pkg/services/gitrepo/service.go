@@ -14,10 +14,8 @@ import (
 func CommitAndPush(ctx context.Context, client git.Git, commitMsg string, logger logger.Logger, filters ...func(string) bool) error {
 	logger.Actionf("Committing and pushing gitops updates for application")
 
-	_, err := client.Commit(git.Commit{
-		Author:  git.Author{Name: "Weave Gitops", Email: "weave-gitops@weave.works"},
-		Message: commitMsg,
-	}, filters...)
+	// the author is the one configured on the client
+	_, err := client.Commit(git.Commit{Message: commitMsg}, filters...)
 	if err != nil && err != git.ErrNoStagedFiles {
 		return fmt.Errorf("failed to update the repository: %w", err)
 	}
@@ -37,14 +35,14 @@ func CommitAndPush(ctx context.Context, client git.Git, commitMsg string, logger
 
 // CloneRepo uses the git client to clone the reop from the URL and branch.  It clones into a temp
 // directory and returns a function to use by the caller for cleanup.  The temp directory is
-// also returned.
-func CloneRepo(ctx context.Context, client git.Git, url gitproviders.RepoURL, branch string) (func(), string, error) {
+// also returned.  When sparsePaths are given, only the files under them are checked out.
+func CloneRepo(ctx context.Context, client git.Git, url gitproviders.RepoURL, branch string, sparsePaths ...string) (func(), string, error) {
 	repoDir, err := ioutil.TempDir("", "user-repo-")
 	if err != nil {
 		return nil, "", fmt.Errorf("failed creating temp. directory to clone repo: %w", err)
 	}
 
-	_, err = client.Clone(ctx, repoDir, url.String(), branch)
+	_, err = client.Clone(ctx, repoDir, url.String(), branch, sparsePaths...)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed cloning user repo: %s: %w", url, err)
 	}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
+	"github.com/weaveworks/weave-gitops/pkg/flux"
 	"github.com/weaveworks/weave-gitops/pkg/git"
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 	"github.com/weaveworks/weave-gitops/pkg/helm"
@@ -12,7 +15,6 @@ import (
 
 	"github.com/fluxcd/go-git-providers/gitprovider"
 	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
-	"github.com/google/uuid"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -33,12 +35,12 @@ func (s *ProfilesSvc) Add(ctx context.Context, gitProvider gitproviders.GitProvi
 		return fmt.Errorf("repository %q could not be found", configRepoURL)
 	}
 
-	defaultBranch, err := gitProvider.GetDefaultBranch(ctx, configRepoURL)
+	changeset, err := gitproviders.NewChangeset(ctx, gitProvider, configRepoURL, opts.HeadBranch)
 	if err != nil {
-		return fmt.Errorf("failed to get default branch: %w", err)
+		return err
 	}
 
-	helmRepo, version, err := s.discoverHelmRepository(ctx, GetOptions{
+	helmRepo, version, availableProfiles, err := s.discoverHelmRepository(ctx, GetOptions{
 		Name:      opts.Name,
 		Version:   opts.Version,
 		Cluster:   opts.Cluster,
@@ -51,36 +53,26 @@ func (s *ProfilesSvc) Add(ctx context.Context, gitProvider gitproviders.GitProvi
 
 	opts.Version = version
 
-	files, err := gitProvider.GetRepoDirFiles(ctx, configRepoURL, git.GetSystemPath(opts.Cluster), defaultBranch)
-	if err != nil {
-		return fmt.Errorf("failed to get files in '%s' for config repository %q: %s", git.GetSystemPath(opts.Cluster), configRepoURL, err)
+	if err := s.validateValues(ctx, opts, helmRepo, opts.Values); err != nil {
+		return err
 	}
 
-	fileContent := getGitCommitFileContent(files, git.GetProfilesPath(opts.Cluster, models.WegoProfilesPath))
-
-	content, err := addHelmRelease(helmRepo, fileContent, opts.Name, opts.Version, opts.Cluster, opts.Namespace)
+	files, err := changeset.ReadDir(ctx, git.GetSystemPath(opts.Cluster))
 	if err != nil {
-		return fmt.Errorf("failed to add HelmRelease for profile '%s' to %s: %w", opts.Name, models.WegoProfilesPath, err)
+		return err
 	}
 
-	path := git.GetProfilesPath(opts.Cluster, models.WegoProfilesPath)
-	pr, err := gitProvider.CreatePullRequest(ctx, configRepoURL, prInfo(opts, "add", defaultBranch, gitprovider.CommitFile{
-		Path:    &path,
-		Content: &content,
-	}))
+	fileContent := getGitCommitFileContent(files, git.GetProfilesPath(opts.Cluster, models.WegoProfilesPath))
 
+	content, err := addHelmRelease(helmRepo, fileContent, opts, availableProfiles)
 	if err != nil {
-		return fmt.Errorf("failed to create pull request: %s", err)
+		return fmt.Errorf("failed to add HelmRelease for profile '%s' to %s: %w", opts.Name, models.WegoProfilesPath, err)
 	}
 
-	s.Logger.Actionf("created Pull Request: %s", pr.Get().WebURL)
-
-	if opts.AutoMerge {
-		s.Logger.Actionf("auto-merge=true; merging PR number %v", pr.Get().Number)
+	changeset.Write(git.GetProfilesPath(opts.Cluster, models.WegoProfilesPath), content)
 
-		if err := gitProvider.MergePullRequest(ctx, configRepoURL, pr.Get().Number, AddCommitMessage); err != nil {
-			return fmt.Errorf("error auto-merging PR: %w", err)
-		}
+	if err := s.submit(ctx, gitProvider, configRepoURL, changeset, prInfo(opts, "add"), opts.mergeOptions(AddCommitMessage)); err != nil {
+		return err
 	}
 
 	s.printAddSummary(opts)
@@ -88,7 +80,9 @@ func (s *ProfilesSvc) Add(ctx context.Context, gitProvider gitproviders.GitProvi
 	return nil
 }
 
-func prInfo(opts Options, action, defaultBranch string, commitFile gitprovider.CommitFile) gitproviders.PullRequestInfo {
+// prInfo returns the pull request of an action on a profile. Its branch only depends on the profile, so that
+// running the same command again updates the pull request instead of opening another one.
+func prInfo(opts Options, action string) gitproviders.PullRequestInfo {
 	title := fmt.Sprintf("GitOps %s %s", action, opts.Name)
 
 	if opts.Title != "" {
@@ -105,24 +99,136 @@ func prInfo(opts Options, action, defaultBranch string, commitFile gitprovider.C
 		commitMessage = opts.Message
 	}
 
-	headBranch := defaultBranch
-	if opts.HeadBranch != "" {
-		headBranch = opts.HeadBranch
+	info := gitproviders.PullRequestInfo{
+		Title:           title,
+		Description:     description,
+		CommitMessage:   commitMessage,
+		NewBranch:       fmt.Sprintf("gitops-%s-%s-%s-%s", action, opts.Namespace, opts.Cluster, opts.Name),
+		GeneratedBranch: true,
 	}
 
-	newBranch := uuid.New().String()
 	if opts.BaseBranch != "" {
-		newBranch = opts.BaseBranch
+		info.NewBranch = opts.BaseBranch
+		info.GeneratedBranch = false
+	}
+
+	return info
+}
+
+// defaultPollInterval is how often pull requests and clusters are checked when waiting for them.
+const defaultPollInterval = 10 * time.Second
+
+// defaultChecksGracePeriod is how long the checks of a pull request may take to be reported.
+const defaultChecksGracePeriod = 2 * time.Minute
+
+// mergeOptions tell what happens to a pull request once submitted.
+type mergeOptions struct {
+	autoMerge    bool
+	wait         bool
+	method       gitprovider.MergeMethod
+	message      string
+	pollInterval time.Duration
+	// checksGracePeriod is how long the checks of the pull request may take to be reported.
+	checksGracePeriod time.Duration
+}
+
+// submit opens the pull request of the changeset, or updates the one a previous run of the same command left
+// open, and merges it when autoMerge is set, or once its checks passed when waiting.
+func (s *ProfilesSvc) submit(ctx context.Context, gitProvider gitproviders.GitProvider, configRepoURL gitproviders.RepoURL, changeset *gitproviders.Changeset, info gitproviders.PullRequestInfo, merge mergeOptions) error {
+	pr, created, err := changeset.Submit(ctx, info)
+	if err != nil {
+		return fmt.Errorf("failed to create pull request: %w", err)
+	}
+
+	if created {
+		s.Logger.Actionf("created Pull Request: %s", pr.Get().WebURL)
+	} else {
+		s.Logger.Actionf("updated Pull Request: %s", pr.Get().WebURL)
+	}
+
+	if merge.wait {
+		return s.mergeOnGreen(ctx, gitProvider, configRepoURL, changeset, pr, merge)
+	}
+
+	if merge.autoMerge {
+		s.Logger.Actionf("auto-merge=true; merging PR number %v", pr.Get().Number)
+
+		if err := gitProvider.MergePullRequest(ctx, configRepoURL, pr.Get().Number, merge.method, merge.message); err != nil {
+			return fmt.Errorf("error auto-merging PR: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// mergeOnGreen waits for the checks of a pull request to pass, merges it and waits for Flux to apply the merged
+// commit on the cluster.
+func (s *ProfilesSvc) mergeOnGreen(ctx context.Context, gitProvider gitproviders.GitProvider, configRepoURL gitproviders.RepoURL, changeset *gitproviders.Changeset, pr gitprovider.PullRequest, merge mergeOptions) error {
+	interval := merge.pollInterval
+	if interval == 0 {
+		interval = defaultPollInterval
+	}
+
+	gracePeriod := merge.checksGracePeriod
+	if gracePeriod == 0 {
+		gracePeriod = defaultChecksGracePeriod
 	}
 
-	return gitproviders.PullRequestInfo{
-		Title:         title,
-		Description:   description,
-		CommitMessage: commitMessage,
-		TargetBranch:  headBranch,
-		NewBranch:     newBranch,
-		Files:         []gitprovider.CommitFile{commitFile},
+	number := pr.Get().Number
+
+	var sha string
+
+	if pr.Get().Merged {
+		// the changes were committed to the base branch directly
+		commits, err := gitProvider.GetCommits(ctx, configRepoURL, changeset.BaseBranch(), 1, 0)
+		if err != nil {
+			return fmt.Errorf("failed to get the head of %s: %w", changeset.BaseBranch(), err)
+		}
+
+		if len(commits) > 0 {
+			sha = commits[0].Get().Sha
+		}
+	} else {
+		s.Logger.Waitingf("waiting for the checks of Pull Request %d to pass", number)
+
+		status, err := gitproviders.WaitForChecks(ctx, gitProvider, configRepoURL, number, interval, gracePeriod)
+		if err != nil {
+			return fmt.Errorf("failed to wait for Pull Request %d: %w", number, err)
+		}
+
+		// someone may have merged it in the meantime
+		if status.State != gitproviders.PullRequestMerged {
+			s.Logger.Actionf("checks passed; merging PR number %v", number)
+
+			if err := gitProvider.MergePullRequest(ctx, configRepoURL, number, merge.method, merge.message); err != nil {
+				return fmt.Errorf("error merging PR: %w", err)
+			}
+
+			if status, err = gitProvider.GetPullRequestStatus(ctx, configRepoURL, number); err != nil {
+				return fmt.Errorf("failed to get the status of Pull Request %d: %w", number, err)
+			}
+		}
+
+		sha = status.MergeCommitSha
+		s.Logger.Successf("merged Pull Request %d as commit %s", number, sha)
 	}
+
+	if s.KubeClient == nil || sha == "" {
+		return nil
+	}
+
+	s.Logger.Waitingf("waiting for Flux to apply commit %s", sha)
+
+	reconciliations, err := flux.WaitForReconciliation(ctx, s.KubeClient, configRepoURL, changeset.BaseBranch(), sha, interval)
+	if err != nil {
+		return fmt.Errorf("failed to wait for the reconciliation of commit %s: %w", sha, err)
+	}
+
+	for _, r := range reconciliations {
+		s.Logger.Successf("Kustomization %s applied revision %s", r.Kustomization, r.Revision)
+	}
+
+	return nil
 }
 
 func (s *ProfilesSvc) printAddSummary(opts Options) {
@@ -133,19 +239,30 @@ func (s *ProfilesSvc) printAddSummary(opts Options) {
 	s.Logger.Println("Namespace: %s\n", opts.Namespace)
 }
 
-func addHelmRelease(helmRepo types.NamespacedName, fileContent, name, version, cluster, ns string) (string, error) {
+func addHelmRelease(helmRepo types.NamespacedName, fileContent string, opts Options, availableProfiles []*pb.Profile) (string, error) {
 	existingReleases, err := helm.SplitHelmReleaseYAML([]byte(fileContent))
 	if err != nil {
 		return "", fmt.Errorf("error splitting into YAML: %w", err)
 	}
 
-	newRelease := helm.MakeHelmRelease(name, version, cluster, ns, helmRepo)
+	newRelease := helm.MakeHelmRelease(opts.Name, opts.Version, opts.Cluster, opts.Namespace, helmRepo)
+
+	if releaseIsInNamespace(existingReleases, newRelease.Name, opts.Namespace) {
+		return "", fmt.Errorf("found another HelmRelease for profile '%s' in namespace %s", opts.Name, opts.Namespace)
+	}
 
-	if releaseIsInNamespace(existingReleases, newRelease.Name, ns) {
-		return "", fmt.Errorf("found another HelmRelease for profile '%s' in namespace %s", name, ns)
+	if err := setReleaseValues(newRelease, opts.Values); err != nil {
+		return "", err
+	}
+
+	addValuesReferences(newRelease, opts.ValuesFrom)
+
+	releases, err := helm.ResolveDependencies(append(existingReleases, newRelease), availableProfiles)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve profile dependencies: %w", err)
 	}
 
-	return helm.AppendHelmReleaseToString(fileContent, newRelease)
+	return helm.MarshalHelmReleases(releases)
 }
 
 func releaseIsInNamespace(existingReleases []*helmv2beta1.HelmRelease, name, ns string) bool {
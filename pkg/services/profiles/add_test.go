@@ -2,9 +2,12 @@ package profiles_test
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"strings"
 
 	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders/gitprovidersfakes"
 	"github.com/weaveworks/weave-gitops/pkg/helm"
 	"github.com/weaveworks/weave-gitops/pkg/logger/loggerfakes"
@@ -13,6 +16,7 @@ import (
 	"github.com/weaveworks/weave-gitops/pkg/vendorfakes/fakegitprovider"
 
 	"github.com/fluxcd/go-git-providers/gitprovider"
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/types"
@@ -77,10 +81,52 @@ var _ = Describe("Add", func() {
 					Expect(prInfo.Description).To(Equal("Add manifest for podinfo profile"))
 					Expect(prInfo.CommitMessage).To(Equal("Add profile manifests"))
 					Expect(prInfo.TargetBranch).To(Equal("main"))
+					Expect(prInfo.NewBranch).To(Equal("gitops-add-weave-system-prod-podinfo"))
 					Expect(prInfo.Files).To(HaveLen(1))
 					Expect(*prInfo.Files[0].Path).To(Equal(".weave-gitops/clusters/prod/system/profiles.yaml"))
 				})
 
+				When("a PR adding the profile is open", func() {
+					BeforeEach(func() {
+						fakePR.GetReturns(gitprovider.PullRequestInfo{WebURL: "url"})
+						gitProviders.GetOpenPullRequestReturns(fakePR, nil)
+					})
+
+					It("commits the HelmRelease to the branch of the PR", func() {
+						Expect(profilesSvc.Add(context.TODO(), gitProviders, addOptions)).Should(Succeed())
+						Expect(gitProviders.CreatePullRequestCallCount()).To(Equal(0))
+
+						_, _, branch := gitProviders.GetOpenPullRequestArgsForCall(0)
+						Expect(branch).To(Equal("gitops-add-weave-system-prod-podinfo"))
+
+						Expect(gitProviders.CommitFilesCallCount()).To(Equal(1))
+						_, _, branch, message, files := gitProviders.CommitFilesArgsForCall(0)
+						Expect(branch).To(Equal("gitops-add-weave-system-prod-podinfo"))
+						Expect(message).To(Equal("Add profile manifests"))
+						Expect(files).To(HaveLen(1))
+						Expect(*files[0].Path).To(Equal(".weave-gitops/clusters/prod/system/profiles.yaml"))
+
+						msg, _ := fakeLogger.ActionfArgsForCall(fakeLogger.ActionfCallCount() - 1)
+						Expect(msg).To(Equal("updated Pull Request: %s"))
+					})
+				})
+
+				When("profiles.yaml changed on the base branch since it was read", func() {
+					It("fails with a conflict", func() {
+						gitProviders.GetCommitsReturnsOnCall(0, []gitprovider.Commit{newCommit("1111111111")}, nil)
+						gitProviders.GetCommitsReturnsOnCall(1, []gitprovider.Commit{newCommit("2222222222")}, nil)
+						gitProviders.GetRepoDirFilesReturnsOnCall(1, []*gitprovider.CommitFile{{
+							Path:    gitprovider.StringVar(".weave-gitops/clusters/prod/system/profiles.yaml"),
+							Content: gitprovider.StringVar("changed"),
+						}}, nil)
+
+						err := profilesSvc.Add(context.TODO(), gitProviders, addOptions)
+						Expect(err).To(MatchError(gitproviders.ErrChangesetConflict))
+						Expect(err).To(MatchError(ContainSubstring(".weave-gitops/clusters/prod/system/profiles.yaml changed on main since commit 1111111")))
+						Expect(gitProviders.CreatePullRequestCallCount()).To(Equal(0))
+					})
+				})
+
 				When("PR settings are configured", func() {
 					It("opens a PR with the configuration", func() {
 						addOptions = profiles.Options{
@@ -200,6 +246,104 @@ var _ = Describe("Add", func() {
 			})
 		})
 
+		When("values are given", func() {
+			BeforeEach(func() {
+				gitProviders.RepositoryExistsReturns(true, nil)
+				gitProviders.GetDefaultBranchReturns("main", nil)
+				clientSet.AddProxyReactor("services", func(action testing.Action) (handled bool, ret restclient.ResponseWrapper, err error) {
+					path := action.(testing.ProxyGetAction).GetPath()
+
+					switch {
+					case strings.HasSuffix(path, "/values/schema"):
+						return true, newFakeResponseWrapper(`{"schema": "{\"required\": [\"image\"], \"properties\": {\"replicaCount\": {\"type\": \"integer\"}, \"image\": {\"type\": \"string\"}}}"}`), nil
+					case strings.HasSuffix(path, "/values"):
+						values := base64.StdEncoding.EncodeToString([]byte("replicaCount: 1\nimage: podinfo:6.0.1\n"))
+						return true, newFakeResponseWrapper(fmt.Sprintf(`{"values": %q}`, values)), nil
+					}
+
+					return true, newFakeResponseWrapper(getProfilesResp), nil
+				})
+				fakePR.GetReturns(gitprovider.PullRequestInfo{WebURL: "url"})
+				gitProviders.CreatePullRequestReturns(fakePR, nil)
+			})
+
+			It("writes the values to the HelmRelease", func() {
+				addOptions.Values = map[string]interface{}{"replicaCount": 2}
+				Expect(profilesSvc.Add(context.TODO(), gitProviders, addOptions)).Should(Succeed())
+
+				_, _, prInfo := gitProviders.CreatePullRequestArgsForCall(0)
+				releases, err := helm.SplitHelmReleaseYAML([]byte(*prInfo.Files[0].Content))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(releases[0].Spec.Values.Raw)).To(Equal(`{"replicaCount":2}`))
+			})
+
+			It("writes the values references to the HelmRelease", func() {
+				addOptions.ValuesFrom = []helmv2beta1.ValuesReference{{Kind: "ConfigMap", Name: "podinfo-values"}}
+				Expect(profilesSvc.Add(context.TODO(), gitProviders, addOptions)).Should(Succeed())
+
+				_, _, prInfo := gitProviders.CreatePullRequestArgsForCall(0)
+				releases, err := helm.SplitHelmReleaseYAML([]byte(*prInfo.Files[0].Content))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(releases[0].Spec.Values).To(BeNil())
+				Expect(releases[0].Spec.ValuesFrom).To(Equal([]helmv2beta1.ValuesReference{{Kind: "ConfigMap", Name: "podinfo-values"}}))
+			})
+
+			It("leaves the values required by the schema to the defaults of the profile", func() {
+				addOptions.Values = map[string]interface{}{"replicaCount": 2}
+				Expect(profilesSvc.Add(context.TODO(), gitProviders, addOptions)).Should(Succeed())
+				Expect(gitProviders.CreatePullRequestCallCount()).To(Equal(1))
+			})
+
+			It("fails before opening a PR when the values do not match the schema", func() {
+				addOptions.Values = map[string]interface{}{"replicaCount": "two"}
+				err := profilesSvc.Add(context.TODO(), gitProviders, addOptions)
+				Expect(err).To(MatchError("invalid values for profile 'podinfo' version 6.0.1: values do not match the schema:\n- $.replicaCount: Invalid type. Expected: integer, given: string"))
+				Expect(gitProviders.CreatePullRequestCallCount()).To(BeZero())
+			})
+		})
+
+		When("the profile is in a layer above an installed profile", func() {
+			BeforeEach(func() {
+				gitProviders.RepositoryExistsReturns(true, nil)
+				gitProviders.GetDefaultBranchReturns("main", nil)
+
+				existingRelease := helm.MakeHelmRelease(
+					"observability", "0.1.0", "prod", "weave-system",
+					types.NamespacedName{Name: "podinfo", Namespace: "weave-system"},
+				)
+				r, _ := yaml.Marshal(existingRelease)
+				content := string(r)
+				path := git.GetProfilesPath("prod", models.WegoProfilesPath)
+				gitProviders.GetRepoDirFilesReturns([]*gitprovider.CommitFile{{
+					Path:    &path,
+					Content: &content,
+				}}, nil)
+				clientSet.AddProxyReactor("services", func(action testing.Action) (handled bool, ret restclient.ResponseWrapper, err error) {
+					return true, newFakeResponseWrapper(layeredProfilesResp), nil
+				})
+			})
+
+			It("makes the new HelmRelease depend on the profiles of the layer below", func() {
+				fakePR.GetReturns(gitprovider.PullRequestInfo{WebURL: "url"})
+				gitProviders.CreatePullRequestReturns(fakePR, nil)
+				Expect(profilesSvc.Add(context.TODO(), gitProviders, addOptions)).Should(Succeed())
+
+				_, _, prInfo := gitProviders.CreatePullRequestArgsForCall(0)
+				releases, err := helm.SplitHelmReleaseYAML([]byte(*prInfo.Files[0].Content))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(releases).To(HaveLen(2))
+				Expect(releases[1].Name).To(Equal("prod-podinfo"))
+				Expect(releases[1].Spec.DependsOn).To(HaveLen(1))
+				Expect(releases[1].Spec.DependsOn[0].Name).To(Equal("prod-observability"))
+			})
+
+			It("fails when a dependency of the profile is not installed", func() {
+				addOptions.Name = "dashboards"
+				err := profilesSvc.Add(context.TODO(), gitProviders, addOptions)
+				Expect(err).To(MatchError("failed to add HelmRelease for profile 'dashboards' to profiles.yaml: failed to resolve profile dependencies: profile 'dashboards' depends on profile 'grafana', which is not installed"))
+			})
+		})
+
 		Context("it fails to discover the HelmRepository name and namespace", func() {
 			It("fails if it's unable to list available profiles from the cluster", func() {
 				gitProviders.RepositoryExistsReturns(true, nil)
@@ -245,6 +389,29 @@ var _ = Describe("Add", func() {
 	})
 })
 
+const layeredProfilesResp = `{
+  "profiles": [
+    {
+      "name": "observability",
+      "helmRepository": {"name": "podinfo", "namespace": "weave-system"},
+      "availableVersions": ["0.1.0"],
+      "layer": "layer-0"
+    },
+    {
+      "name": "podinfo",
+      "helmRepository": {"name": "podinfo", "namespace": "weave-system"},
+      "availableVersions": ["6.0.0", "6.0.1"],
+      "layer": "layer-1"
+    },
+    {
+      "name": "dashboards",
+      "helmRepository": {"name": "podinfo", "namespace": "weave-system"},
+      "availableVersions": ["1.0.0"],
+      "dependencies": ["grafana"]
+    }
+  ]
+}`
+
 func makeTestFiles() []*gitprovider.CommitFile {
 	path0 := ".weave-gitops/clusters/prod/system/wego-system.yaml"
 	content0 := "machine1 yaml content"
@@ -272,3 +439,10 @@ func makeTestFiles() []*gitprovider.CommitFile {
 
 	return commitFiles
 }
+
+func newCommit(sha string) gitprovider.Commit {
+	c := &fakegitprovider.Commit{}
+	c.GetReturns(gitprovider.CommitInfo{Sha: sha})
+
+	return c
+}
@@ -0,0 +1,107 @@
+package profiles
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	"github.com/weaveworks/weave-gitops/pkg/helm"
+	"github.com/weaveworks/weave-gitops/pkg/models"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
+)
+
+const DeleteCommitMessage = "Delete profile manifests"
+
+// Delete uninstalls a profile by removing its HelmRelease from the profile manifest in the config repo, provided that no other
+// installed profile depends on it.
+func (s *ProfilesSvc) Delete(ctx context.Context, gitProvider gitproviders.GitProvider, opts Options) error {
+	configRepoURL, err := gitproviders.NewRepoURL(opts.ConfigRepo)
+	if err != nil {
+		return fmt.Errorf("failed to parse url: %w", err)
+	}
+
+	repoExists, err := gitProvider.RepositoryExists(ctx, configRepoURL)
+	if err != nil {
+		return fmt.Errorf("failed to check whether repository exists: %w", err)
+	} else if !repoExists {
+		return fmt.Errorf("repository %q could not be found", configRepoURL)
+	}
+
+	changeset, err := gitproviders.NewChangeset(ctx, gitProvider, configRepoURL, opts.HeadBranch)
+	if err != nil {
+		return err
+	}
+
+	files, err := changeset.ReadDir(ctx, git.GetSystemPath(opts.Cluster))
+	if err != nil {
+		return err
+	}
+
+	content, err := deleteHelmRelease(files, opts.Name, opts.Cluster, opts.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed to delete HelmRelease for profile '%s' from %s: %w", opts.Name, models.WegoProfilesPath, err)
+	}
+
+	changeset.Write(git.GetProfilesPath(opts.Cluster, models.WegoProfilesPath), content)
+
+	if err := s.submit(ctx, gitProvider, configRepoURL, changeset, prInfo(opts, "delete"), opts.mergeOptions(DeleteCommitMessage)); err != nil {
+		return err
+	}
+
+	s.printDeleteSummary(opts)
+
+	return nil
+}
+
+func (s *ProfilesSvc) printDeleteSummary(opts Options) {
+	s.Logger.Println("Deleting profile:\n")
+	s.Logger.Println("Name: %s", opts.Name)
+	s.Logger.Println("Cluster: %s", opts.Cluster)
+	s.Logger.Println("Namespace: %s\n", opts.Namespace)
+}
+
+// deleteHelmRelease returns the content of the profiles manifest without the HelmRelease of the profile.
+func deleteHelmRelease(files []*gitprovider.CommitFile, name, cluster, ns string) (string, error) {
+	fileContent := getGitCommitFileContent(files, git.GetProfilesPath(cluster, models.WegoProfilesPath))
+	if fileContent == "" {
+		return "", fmt.Errorf("failed to find installed profiles in '%s'", git.GetProfilesPath(cluster, models.WegoProfilesPath))
+	}
+
+	existingReleases, err := helm.SplitHelmReleaseYAML([]byte(fileContent))
+	if err != nil {
+		return "", fmt.Errorf("error splitting into YAML: %w", err)
+	}
+
+	var (
+		deleted  *helmv2beta1.HelmRelease
+		releases []*helmv2beta1.HelmRelease
+	)
+
+	for _, r := range existingReleases {
+		if r.Name == cluster+"-"+name && r.Namespace == ns {
+			deleted = r
+			continue
+		}
+
+		releases = append(releases, r)
+	}
+
+	if deleted == nil {
+		return "", fmt.Errorf("failed to find HelmRelease '%s' in namespace '%s'", cluster+"-"+name, ns)
+	}
+
+	if dependents := helm.Dependents(releases, deleted); len(dependents) > 0 {
+		var names []string
+		for _, d := range dependents {
+			names = append(names, d.Namespace+"/"+d.Name)
+		}
+
+		return "", fmt.Errorf("HelmRelease '%s' is a dependency of %s", deleted.Name, strings.Join(names, ", "))
+	}
+
+	return helm.MarshalHelmReleases(releases)
+}
@@ -0,0 +1,220 @@
+package profiles_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev2 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/dependency"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders/gitprovidersfakes"
+	"github.com/weaveworks/weave-gitops/pkg/helm"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/logger/loggerfakes"
+	"github.com/weaveworks/weave-gitops/pkg/models"
+	"github.com/weaveworks/weave-gitops/pkg/services/profiles"
+	"github.com/weaveworks/weave-gitops/pkg/vendorfakes/fakegitprovider"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	ctrlclientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Delete Profile(s)", func() {
+	var (
+		gitProviders  *gitprovidersfakes.FakeGitProvider
+		profilesSvc   *profiles.ProfilesSvc
+		fakeLogger    *loggerfakes.FakeLogger
+		fakePR        *fakegitprovider.PullRequest
+		deleteOptions profiles.Options
+	)
+
+	release := func(name string) *helmv2beta1.HelmRelease {
+		return helm.MakeHelmRelease(name, "6.0.0", "prod", "weave-system", types.NamespacedName{Name: "helm-repo-name", Namespace: "helm-repo-namespace"})
+	}
+
+	installed := func(releases ...*helmv2beta1.HelmRelease) {
+		content, err := helm.MarshalHelmReleases(releases)
+		Expect(err).NotTo(HaveOccurred())
+
+		path := git.GetProfilesPath("prod", models.WegoProfilesPath)
+		gitProviders.GetRepoDirFilesReturns([]*gitprovider.CommitFile{{
+			Path:    &path,
+			Content: &content,
+		}}, nil)
+	}
+
+	BeforeEach(func() {
+		gitProviders = &gitprovidersfakes.FakeGitProvider{}
+		fakeLogger = &loggerfakes.FakeLogger{}
+		fakePR = &fakegitprovider.PullRequest{}
+		profilesSvc = profiles.NewService(fake.NewSimpleClientset(), fakeLogger)
+
+		deleteOptions = profiles.Options{
+			ConfigRepo: "ssh://git@github.com/owner/config-repo.git",
+			Name:       "podinfo",
+			Cluster:    "prod",
+			Namespace:  "weave-system",
+		}
+
+		gitProviders.RepositoryExistsReturns(true, nil)
+		gitProviders.GetDefaultBranchReturns("main", nil)
+		fakePR.GetReturns(gitprovider.PullRequestInfo{WebURL: "url", Number: 42})
+		gitProviders.CreatePullRequestReturns(fakePR, nil)
+	})
+
+	It("opens a PR removing the HelmRelease of the profile", func() {
+		installed(release("observability"), release("podinfo"))
+
+		Expect(profilesSvc.Delete(context.TODO(), gitProviders, deleteOptions)).To(Succeed())
+		Expect(gitProviders.CreatePullRequestCallCount()).To(Equal(1))
+
+		_, repoURL, prInfo := gitProviders.CreatePullRequestArgsForCall(0)
+		Expect(repoURL.String()).To(Equal("ssh://git@github.com/owner/config-repo.git"))
+		Expect(prInfo.Title).To(Equal("GitOps delete podinfo"))
+		Expect(prInfo.Description).To(Equal("Delete manifest for podinfo profile"))
+		Expect(prInfo.CommitMessage).To(Equal("Delete profile manifests"))
+		Expect(prInfo.TargetBranch).To(Equal("main"))
+		Expect(prInfo.NewBranch).To(Equal("gitops-delete-weave-system-prod-podinfo"))
+		Expect(*prInfo.Files[0].Path).To(Equal(".weave-gitops/clusters/prod/system/profiles.yaml"))
+
+		releases, err := helm.SplitHelmReleaseYAML([]byte(*prInfo.Files[0].Content))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(releases).To(HaveLen(1))
+		Expect(releases[0].Name).To(Equal("prod-observability"))
+		Expect(gitProviders.MergePullRequestCallCount()).To(BeZero())
+	})
+
+	It("leaves an empty manifest when the last profile is deleted", func() {
+		installed(release("podinfo"))
+
+		Expect(profilesSvc.Delete(context.TODO(), gitProviders, deleteOptions)).To(Succeed())
+
+		_, _, prInfo := gitProviders.CreatePullRequestArgsForCall(0)
+		Expect(*prInfo.Files[0].Content).To(BeEmpty())
+	})
+
+	It("merges the PR when auto-merge is enabled", func() {
+		installed(release("podinfo"))
+		deleteOptions.AutoMerge = true
+
+		Expect(profilesSvc.Delete(context.TODO(), gitProviders, deleteOptions)).To(Succeed())
+		Expect(gitProviders.MergePullRequestCallCount()).To(Equal(1))
+
+		_, _, number, method, message := gitProviders.MergePullRequestArgsForCall(0)
+		Expect(number).To(Equal(42))
+		Expect(method).To(BeEmpty())
+		Expect(message).To(Equal(profiles.DeleteCommitMessage))
+	})
+
+	It("returns an error when the PR fails to be merged", func() {
+		installed(release("podinfo"))
+		deleteOptions.AutoMerge = true
+		gitProviders.MergePullRequestReturns(fmt.Errorf("err"))
+
+		err := profilesSvc.Delete(context.TODO(), gitProviders, deleteOptions)
+		Expect(err).To(MatchError("error auto-merging PR: err"))
+	})
+
+	When("waiting for the PR", func() {
+		const sha = "4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f70"
+
+		status := func(state gitproviders.PullRequestState, checks gitproviders.CheckState) gitproviders.PullRequestStatus {
+			return gitproviders.PullRequestStatus{Number: 42, State: state, HeadSha: "abc123", Checks: checks}
+		}
+
+		BeforeEach(func() {
+			installed(release("podinfo"))
+			deleteOptions.Wait = true
+			deleteOptions.MergeMethod = gitprovider.MergeMethodSquash
+			deleteOptions.PollInterval = time.Millisecond
+
+			source := &sourcev1.GitRepository{
+				ObjectMeta: metav1.ObjectMeta{Name: "config-repo", Namespace: "flux-system"},
+				Spec: sourcev1.GitRepositorySpec{
+					URL:       "ssh://git@github.com/owner/config-repo.git",
+					Reference: &sourcev1.GitRepositoryRef{Branch: "main"},
+				},
+				Status: sourcev1.GitRepositoryStatus{Artifact: &sourcev1.Artifact{Revision: "main/" + sha}},
+			}
+			kustomization := &kustomizev2.Kustomization{
+				ObjectMeta: metav1.ObjectMeta{Name: "apps", Namespace: "flux-system"},
+				Spec: kustomizev2.KustomizationSpec{
+					SourceRef: kustomizev2.CrossNamespaceSourceReference{Kind: sourcev1.GitRepositoryKind, Name: "config-repo"},
+				},
+				Status: kustomizev2.KustomizationStatus{
+					LastAppliedRevision: "main/" + sha,
+					Conditions:          []metav1.Condition{{Type: meta.ReadyCondition, Status: metav1.ConditionTrue}},
+				},
+			}
+			profilesSvc.KubeClient = ctrlclientfake.NewClientBuilder().WithScheme(kube.CreateScheme()).WithObjects(source, kustomization).Build()
+		})
+
+		It("merges the PR once its checks passed and reports the reconciliation of the merged commit", func() {
+			merged := status(gitproviders.PullRequestMerged, gitproviders.ChecksPassed)
+			merged.MergeCommitSha = sha
+
+			gitProviders.GetPullRequestStatusReturnsOnCall(0, status(gitproviders.PullRequestOpen, gitproviders.ChecksPending), nil)
+			gitProviders.GetPullRequestStatusReturnsOnCall(1, status(gitproviders.PullRequestOpen, gitproviders.ChecksPassed), nil)
+			gitProviders.GetPullRequestStatusReturnsOnCall(2, merged, nil)
+
+			Expect(profilesSvc.Delete(context.TODO(), gitProviders, deleteOptions)).To(Succeed())
+			Expect(gitProviders.MergePullRequestCallCount()).To(Equal(1))
+
+			_, _, number, method, _ := gitProviders.MergePullRequestArgsForCall(0)
+			Expect(number).To(Equal(42))
+			Expect(method).To(Equal(gitprovider.MergeMethodSquash))
+
+			msg, args := fakeLogger.SuccessfArgsForCall(fakeLogger.SuccessfCallCount() - 1)
+			Expect(fmt.Sprintf(msg, args...)).To(Equal("Kustomization flux-system/apps applied revision main/" + sha))
+		})
+
+		It("doesn't merge the PR when its checks failed", func() {
+			gitProviders.GetPullRequestStatusReturns(status(gitproviders.PullRequestOpen, gitproviders.ChecksFailed), nil)
+
+			err := profilesSvc.Delete(context.TODO(), gitProviders, deleteOptions)
+			Expect(err).To(MatchError(gitproviders.ErrChecksFailed))
+			Expect(gitProviders.MergePullRequestCallCount()).To(BeZero())
+		})
+	})
+
+	It("refuses to delete a profile other profiles depend on", func() {
+		dashboards := release("dashboards")
+		dashboards.Spec.DependsOn = []dependency.CrossNamespaceDependencyReference{{Name: "prod-podinfo"}}
+		installed(release("podinfo"), dashboards)
+
+		err := profilesSvc.Delete(context.TODO(), gitProviders, deleteOptions)
+		Expect(err).To(MatchError("failed to delete HelmRelease for profile 'podinfo' from profiles.yaml: HelmRelease 'prod-podinfo' is a dependency of weave-system/prod-dashboards"))
+		Expect(gitProviders.CreatePullRequestCallCount()).To(BeZero())
+	})
+
+	It("fails when the profile is not installed", func() {
+		installed(release("observability"))
+
+		err := profilesSvc.Delete(context.TODO(), gitProviders, deleteOptions)
+		Expect(err).To(MatchError("failed to delete HelmRelease for profile 'podinfo' from profiles.yaml: failed to find HelmRelease 'prod-podinfo' in namespace 'weave-system'"))
+	})
+
+	It("fails when the file containing the HelmReleases is empty", func() {
+		gitProviders.GetRepoDirFilesReturns(makeTestFiles(), nil)
+
+		err := profilesSvc.Delete(context.TODO(), gitProviders, deleteOptions)
+		Expect(err).To(MatchError(ContainSubstring("failed to find installed profiles in '.weave-gitops/clusters/prod/system/profiles.yaml'")))
+	})
+
+	It("fails if the config repo does not exist", func() {
+		gitProviders.RepositoryExistsReturns(false, nil)
+
+		err := profilesSvc.Delete(context.TODO(), gitProviders, deleteOptions)
+		Expect(err).To(MatchError("repository \"ssh://git@github.com/owner/config-repo.git\" could not be found"))
+	})
+})
@@ -37,7 +37,7 @@ func (s *ProfilesSvc) Get(ctx context.Context, opts GetOptions) error {
 }
 
 func doKubeGetRequest(ctx context.Context, namespace, serviceName, servicePort, path string, clientset kubernetes.Interface) (*pb.GetProfilesResponse, error) {
-	resp, err := kubernetesDoRequest(ctx, namespace, wegoServiceName, "https", servicePort, getProfilesPath, clientset)
+	resp, err := kubernetesDoRequest(ctx, namespace, wegoServiceName, "https", servicePort, getProfilesPath, nil, clientset)
 	if err != nil {
 		return nil, err
 	}
@@ -54,16 +54,30 @@ func doKubeGetRequest(ctx context.Context, namespace, serviceName, servicePort,
 
 // GetProfile returns a single available profile.
 func (s *ProfilesSvc) GetProfile(ctx context.Context, opts GetOptions) (*pb.Profile, string, error) {
+	profiles, err := s.getAvailableProfiles(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return findProfile(profiles, opts)
+}
+
+func (s *ProfilesSvc) getAvailableProfiles(ctx context.Context, opts GetOptions) ([]*pb.Profile, error) {
 	s.Logger.Actionf("getting available profiles in %s/%s", opts.Cluster, opts.Namespace)
 
 	profilesList, err := doKubeGetRequest(ctx, opts.Namespace, wegoServiceName, opts.Port, getProfilesPath, s.ClientSet)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
+	return profilesList.Profiles, nil
+}
+
+// findProfile returns the profile named in opts and the version to install.
+func findProfile(profiles []*pb.Profile, opts GetOptions) (*pb.Profile, string, error) {
 	var version string
 
-	for _, p := range profilesList.Profiles {
+	for _, p := range profiles {
 		if p.Name == opts.Name {
 			if len(p.AvailableVersions) == 0 {
 				return nil, "", fmt.Errorf("no version found for profile '%s' in %s/%s", p.Name, opts.Cluster, opts.Namespace)
@@ -118,13 +132,13 @@ func printProfiles(profiles *pb.GetProfilesResponse, w io.Writer) {
 	}
 }
 
-func kubernetesDoRequest(ctx context.Context, namespace, serviceName, scheme, servicePort, path string, clientset kubernetes.Interface) ([]byte, error) {
+func kubernetesDoRequest(ctx context.Context, namespace, serviceName, scheme, servicePort, path string, params map[string]string, clientset kubernetes.Interface) ([]byte, error) {
 	u, err := url.Parse(path)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := clientset.CoreV1().Services(namespace).ProxyGet(scheme, serviceName, servicePort, u.String(), nil).DoRaw(ctx)
+	data, err := clientset.CoreV1().Services(namespace).ProxyGet(scheme, serviceName, servicePort, u.String(), params).DoRaw(ctx)
 	if err != nil {
 		if se, ok := err.(*errors.StatusError); ok {
 			return nil, fmt.Errorf("failed to make GET request to service %s/%s path %q status code: %d", namespace, serviceName, path, int(se.Status().Code))
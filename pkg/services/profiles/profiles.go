@@ -3,13 +3,17 @@ package profiles
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/fluxcd/go-git-providers/gitprovider"
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
+	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 	"github.com/weaveworks/weave-gitops/pkg/logger"
 
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -27,6 +31,10 @@ type ProfilesService interface {
 	Get(ctx context.Context, opts GetOptions) error
 	// Update updates a profile
 	Update(ctx context.Context, gitProvider gitproviders.GitProvider, opts Options) error
+	// Delete uninstalls a profile
+	Delete(ctx context.Context, gitProvider gitproviders.GitProvider, opts Options) error
+	// Upgrade opens pull requests upgrading the installed profiles
+	Upgrade(ctx context.Context, gitProvider gitproviders.GitProvider, opts UpgradeOptions) error
 }
 
 type Options struct {
@@ -43,11 +51,44 @@ type Options struct {
 	Message      string
 	Title        string
 	Description  string
+	// Values are written to the spec.values of the HelmRelease, once checked
+	// against the values schema of the profile. When updating, they are merged
+	// into the values of the installed profile.
+	Values map[string]interface{}
+	// ValuesFrom are added to the spec.valuesFrom of the HelmRelease.
+	ValuesFrom []helmv2beta1.ValuesReference
+	// MergeMethod is how the pull request is merged, with a merge commit
+	// when empty.
+	MergeMethod gitprovider.MergeMethod
+	// Wait waits for the checks of the pull request to pass before merging
+	// it, then for Flux to apply the merged commit.
+	Wait bool
+	// PollInterval is how often the pull request and the cluster are checked
+	// while waiting.
+	PollInterval time.Duration
+	// ChecksGracePeriod is how long the checks of the pull request may take
+	// to be reported while waiting, before it is merged without checks.
+	ChecksGracePeriod time.Duration
+}
+
+func (opts Options) mergeOptions(message string) mergeOptions {
+	return mergeOptions{
+		autoMerge:         opts.AutoMerge,
+		wait:              opts.Wait,
+		method:            opts.MergeMethod,
+		message:           message,
+		pollInterval:      opts.PollInterval,
+		checksGracePeriod: opts.ChecksGracePeriod,
+	}
 }
 
 type ProfilesSvc struct {
 	ClientSet kubernetes.Interface
 	Logger    logger.Logger
+	// KubeClient reads the Flux objects of the cluster to report the
+	// reconciliation of the merged pull requests when waiting for them,
+	// which is skipped when nil.
+	KubeClient client.Client
 }
 
 func NewService(clientSet kubernetes.Interface, log logger.Logger) *ProfilesSvc {
@@ -57,16 +98,24 @@ func NewService(clientSet kubernetes.Interface, log logger.Logger) *ProfilesSvc
 	}
 }
 
-func (s *ProfilesSvc) discoverHelmRepository(ctx context.Context, opts GetOptions) (types.NamespacedName, string, error) {
-	availableProfile, version, err := s.GetProfile(ctx, opts)
+// discoverHelmRepository returns the HelmRepository and version of the profile
+// to install, along with every available profile so that the dependencies of
+// the installed profiles can be resolved.
+func (s *ProfilesSvc) discoverHelmRepository(ctx context.Context, opts GetOptions) (types.NamespacedName, string, []*pb.Profile, error) {
+	profiles, err := s.getAvailableProfiles(ctx, opts)
+	if err != nil {
+		return types.NamespacedName{}, "", nil, fmt.Errorf("failed to get profiles from cluster: %w", err)
+	}
+
+	availableProfile, version, err := findProfile(profiles, opts)
 	if err != nil {
-		return types.NamespacedName{}, "", fmt.Errorf("failed to get profiles from cluster: %w", err)
+		return types.NamespacedName{}, "", nil, fmt.Errorf("failed to get profiles from cluster: %w", err)
 	}
 
 	return types.NamespacedName{
 		Name:      availableProfile.HelmRepository.Name,
 		Namespace: availableProfile.HelmRepository.Namespace,
-	}, version, nil
+	}, version, profiles, nil
 }
 
 func getGitCommitFileContent(files []*gitprovider.CommitFile, filePath string) string {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
 	"github.com/weaveworks/weave-gitops/pkg/git"
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 	"github.com/weaveworks/weave-gitops/pkg/helm"
@@ -29,12 +30,12 @@ func (s *ProfilesSvc) Update(ctx context.Context, gitProvider gitproviders.GitPr
 		return fmt.Errorf("repository %q could not be found", configRepoURL)
 	}
 
-	defaultBranch, err := gitProvider.GetDefaultBranch(ctx, configRepoURL)
+	changeset, err := gitproviders.NewChangeset(ctx, gitProvider, configRepoURL, opts.HeadBranch)
 	if err != nil {
-		return fmt.Errorf("failed to get default branch: %w", err)
+		return err
 	}
 
-	_, version, err := s.discoverHelmRepository(ctx, GetOptions{
+	helmRepo, version, availableProfiles, err := s.discoverHelmRepository(ctx, GetOptions{
 		Name:      opts.Name,
 		Version:   opts.Version,
 		Cluster:   opts.Cluster,
@@ -47,34 +48,30 @@ func (s *ProfilesSvc) Update(ctx context.Context, gitProvider gitproviders.GitPr
 
 	opts.Version = version
 
-	files, err := gitProvider.GetRepoDirFiles(ctx, configRepoURL, git.GetSystemPath(opts.Cluster), defaultBranch)
+	files, err := changeset.ReadDir(ctx, git.GetSystemPath(opts.Cluster))
 	if err != nil {
-		return fmt.Errorf("failed to get files in '%s' of config repository %q: %s", git.GetSystemPath(opts.Cluster), configRepoURL, err)
+		return err
 	}
 
-	content, err := updateHelmRelease(files, opts.Name, opts.Version, opts.Cluster, opts.Namespace)
+	content, release, err := updateHelmRelease(files, opts, availableProfiles)
 	if err != nil {
 		return fmt.Errorf("failed to update HelmRelease for profile '%s' in %s: %w", opts.Name, models.WegoProfilesPath, err)
 	}
 
-	path := git.GetProfilesPath(opts.Cluster, models.WegoProfilesPath)
-
-	pr, err := gitProvider.CreatePullRequest(ctx, configRepoURL, prInfo(opts, "update", defaultBranch, gitprovider.CommitFile{
-		Path:    &path,
-		Content: &content,
-	}))
+	// The values of the installed profile, along with the new ones, must be valid for the new version.
+	values, err := releaseValues(release)
 	if err != nil {
-		return fmt.Errorf("failed to create pull request: %s", err)
+		return err
 	}
 
-	s.Logger.Actionf("created Pull Request: %s", pr.Get().WebURL)
+	if err := s.validateValues(ctx, opts, helmRepo, values); err != nil {
+		return err
+	}
 
-	if opts.AutoMerge {
-		s.Logger.Actionf("auto-merge=true; merging PR number %v", pr.Get().Number)
+	changeset.Write(git.GetProfilesPath(opts.Cluster, models.WegoProfilesPath), content)
 
-		if err := gitProvider.MergePullRequest(ctx, configRepoURL, pr.Get().Number, AddCommitMessage); err != nil {
-			return fmt.Errorf("error auto-merging PR: %w", err)
-		}
+	if err := s.submit(ctx, gitProvider, configRepoURL, changeset, prInfo(opts, "update"), opts.mergeOptions(AddCommitMessage)); err != nil {
+		return err
 	}
 
 	s.printUpdateSummary(opts)
@@ -90,23 +87,59 @@ func (s *ProfilesSvc) printUpdateSummary(opts Options) {
 	s.Logger.Println("Namespace: %s\n", opts.Namespace)
 }
 
-func updateHelmRelease(files []*gitprovider.CommitFile, name, version, cluster, ns string) (string, error) {
+// updateHelmRelease returns the new content of the profiles manifest, and the updated HelmRelease. The values and values
+// references of the installed profile are kept, the ones in opts being merged into them.
+func updateHelmRelease(files []*gitprovider.CommitFile, opts Options, availableProfiles []*pb.Profile) (string, *helmv2beta1.HelmRelease, error) {
+	name, version, cluster, ns := opts.Name, opts.Version, opts.Cluster, opts.Namespace
+
 	fileContent := getGitCommitFileContent(files, git.GetProfilesPath(cluster, models.WegoProfilesPath))
 	if fileContent == "" {
-		return "", fmt.Errorf("failed to find installed profiles in '%s'", git.GetProfilesPath(cluster, models.WegoProfilesPath))
+		return "", nil, fmt.Errorf("failed to find installed profiles in '%s'", git.GetProfilesPath(cluster, models.WegoProfilesPath))
 	}
 
 	existingReleases, err := helm.SplitHelmReleaseYAML([]byte(fileContent))
 	if err != nil {
-		return "", fmt.Errorf("error splitting into YAML: %w", err)
+		return "", nil, fmt.Errorf("error splitting into YAML: %w", err)
 	}
 
 	updatedReleases, err := patchRelease(existingReleases, cluster+"-"+name, ns, version)
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	var updated *helmv2beta1.HelmRelease
+
+	for _, r := range updatedReleases {
+		if r.Name == cluster+"-"+name && r.Namespace == ns {
+			updated = r
+		}
+	}
+
+	if len(opts.Values) > 0 {
+		values, err := releaseValues(updated)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if err := setReleaseValues(updated, helm.MergeValues(values, opts.Values)); err != nil {
+			return "", nil, err
+		}
+	}
+
+	addValuesReferences(updated, opts.ValuesFrom)
+
+	// The new version may come with other dependencies.
+	updatedReleases, err = helm.ResolveDependencies(updatedReleases, availableProfiles)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve profile dependencies: %w", err)
+	}
+
+	content, err := helm.MarshalHelmReleases(updatedReleases)
+	if err != nil {
+		return "", nil, err
 	}
 
-	return helm.MarshalHelmReleases(updatedReleases)
+	return content, updated, nil
 }
 
 func patchRelease(existingReleases []*helmv2beta1.HelmRelease, name, ns, version string) ([]*helmv2beta1.HelmRelease, error) {
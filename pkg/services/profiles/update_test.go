@@ -3,8 +3,10 @@ package profiles_test
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/fluxcd/go-git-providers/gitprovider"
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
 	"github.com/weaveworks/weave-gitops/pkg/git"
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders/gitprovidersfakes"
 	"github.com/weaveworks/weave-gitops/pkg/helm"
@@ -16,6 +18,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/fake"
 	restclient "k8s.io/client-go/rest"
@@ -100,10 +103,71 @@ var _ = Describe("Update Profile(s)", func() {
 							Expect(prInfo.Description).To(Equal("Update manifest for podinfo profile"))
 							Expect(prInfo.CommitMessage).To(Equal("Update profile manifests"))
 							Expect(prInfo.TargetBranch).To(Equal("main"))
+							Expect(prInfo.NewBranch).To(Equal("gitops-update-weave-system-prod-podinfo"))
 							Expect(prInfo.Files).To(HaveLen(1))
 							Expect(*prInfo.Files[0].Path).To(Equal(".weave-gitops/clusters/prod/system/profiles.yaml"))
 						})
 
+						When("the installed profile has values", func() {
+							BeforeEach(func() {
+								existingRelease := helm.MakeHelmRelease(
+									"podinfo", "6.0.0", "prod", "weave-system",
+									types.NamespacedName{Name: "helm-repo-name", Namespace: "helm-repo-namespace"},
+								)
+								existingRelease.Spec.Values = &apiextensionsv1.JSON{Raw: []byte(`{"image":{"repository":"podinfo","tag":"6.0.0"},"replicaCount":2}`)}
+								existingRelease.Spec.ValuesFrom = []helmv2beta1.ValuesReference{{Kind: "ConfigMap", Name: "podinfo-values"}}
+								r, _ := yaml.Marshal(existingRelease)
+								content := string(r)
+								path := git.GetProfilesPath("prod", models.WegoProfilesPath)
+								gitProviders.GetRepoDirFilesReturns([]*gitprovider.CommitFile{{
+									Path:    &path,
+									Content: &content,
+								}}, nil)
+								fakePR.GetReturns(gitprovider.PullRequestInfo{WebURL: "url"})
+								gitProviders.CreatePullRequestReturns(fakePR, nil)
+								clientSet.PrependProxyReactor("services", func(action testing.Action) (handled bool, ret restclient.ResponseWrapper, err error) {
+									if strings.HasSuffix(action.(testing.ProxyGetAction).GetPath(), "/values/schema") {
+										return true, newFakeResponseWrapper(`{"schema": ""}`), nil
+									}
+
+									return false, nil, nil
+								})
+							})
+
+							updatedRelease := func() *helmv2beta1.HelmRelease {
+								_, _, prInfo := gitProviders.CreatePullRequestArgsForCall(0)
+								releases, err := helm.SplitHelmReleaseYAML([]byte(*prInfo.Files[0].Content))
+								Expect(err).NotTo(HaveOccurred())
+
+								return releases[0]
+							}
+
+							It("keeps them", func() {
+								Expect(profilesSvc.Update(context.TODO(), gitProviders, updateOptions)).To(Succeed())
+
+								release := updatedRelease()
+								Expect(release.Spec.Chart.Spec.Version).To(Equal("6.0.1"))
+								Expect(string(release.Spec.Values.Raw)).To(Equal(`{"image":{"repository":"podinfo","tag":"6.0.0"},"replicaCount":2}`))
+								Expect(release.Spec.ValuesFrom).To(Equal([]helmv2beta1.ValuesReference{{Kind: "ConfigMap", Name: "podinfo-values"}}))
+							})
+
+							It("merges the new values and references into them", func() {
+								updateOptions.Values = map[string]interface{}{"image": map[string]interface{}{"tag": "6.0.1"}}
+								updateOptions.ValuesFrom = []helmv2beta1.ValuesReference{
+									{Kind: "ConfigMap", Name: "podinfo-values", ValuesKey: "values.yaml"},
+									{Kind: "Secret", Name: "podinfo-credentials"},
+								}
+								Expect(profilesSvc.Update(context.TODO(), gitProviders, updateOptions)).To(Succeed())
+
+								release := updatedRelease()
+								Expect(string(release.Spec.Values.Raw)).To(Equal(`{"image":{"repository":"podinfo","tag":"6.0.1"},"replicaCount":2}`))
+								Expect(release.Spec.ValuesFrom).To(Equal([]helmv2beta1.ValuesReference{
+									{Kind: "ConfigMap", Name: "podinfo-values"},
+									{Kind: "Secret", Name: "podinfo-credentials"},
+								}))
+							})
+						})
+
 						When("PR settings are configured", func() {
 							It("opens a PR with the configuration", func() {
 								updateOptions = profiles.Options{
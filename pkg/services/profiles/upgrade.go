@@ -0,0 +1,391 @@
+package profiles
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	"github.com/weaveworks/weave-gitops/pkg/helm"
+	"github.com/weaveworks/weave-gitops/pkg/models"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const UpgradeCommitMessage = "Upgrade profile manifests"
+
+// UpgradeOptions tells which installed profiles Upgrade looks at, and how the
+// pull requests upgrading them are opened.
+type UpgradeOptions struct {
+	Cluster      string
+	ConfigRepo   string
+	ProfilesPort string
+	Namespace    string
+	// Constraint is a semantic version constraint, e.g. "~6.0", the new
+	// versions must satisfy. Any newer version is picked when empty.
+	Constraint string
+	// Batch opens a single pull request upgrading every profile instead of
+	// one pull request per profile.
+	Batch     bool
+	AutoMerge bool
+	// BaseBranch is the branch the pull requests are opened against, the
+	// default branch of the config repository when empty.
+	BaseBranch string
+	// MergeMethod is how the pull requests are merged, with a merge commit
+	// when empty.
+	MergeMethod gitprovider.MergeMethod
+	// Wait waits for the checks of the pull request to pass before merging
+	// it, then for Flux to apply the merged commit. As every upgrade edits
+	// the same manifest, it implies Batch.
+	Wait bool
+	// PollInterval is how often the pull request and the cluster are checked
+	// while waiting.
+	PollInterval time.Duration
+	// ChecksGracePeriod is how long the checks of the pull request may take
+	// to be reported while waiting, before it is merged without checks.
+	ChecksGracePeriod time.Duration
+}
+
+func (opts UpgradeOptions) mergeOptions(message string) mergeOptions {
+	return mergeOptions{
+		autoMerge:         opts.AutoMerge,
+		wait:              opts.Wait,
+		method:            opts.MergeMethod,
+		message:           message,
+		pollInterval:      opts.PollInterval,
+		checksGracePeriod: opts.ChecksGracePeriod,
+	}
+}
+
+// profileUpgrade is an installed profile a newer version is available for.
+type profileUpgrade struct {
+	release    types.NamespacedName
+	profile    string
+	from       string
+	to         string
+	valuesDiff string
+	compared   bool
+}
+
+// Upgrade opens pull requests upgrading the profiles installed in the config
+// repository to the latest version allowed by the constraint and by the upgrade
+// policy annotation of their HelmRelease. Profiles whose values are not valid
+// for the new version are left out.
+func (s *ProfilesSvc) Upgrade(ctx context.Context, gitProvider gitproviders.GitProvider, opts UpgradeOptions) error {
+	return s.upgrade(ctx, gitProvider, opts, map[string]bool{})
+}
+
+// WatchUpgrades runs Upgrade every interval until ctx is done. A pull request
+// is only opened once for each upgrade, and failures are logged rather than
+// stopping the loop.
+func (s *ProfilesSvc) WatchUpgrades(ctx context.Context, gitProvider gitproviders.GitProvider, opts UpgradeOptions, interval time.Duration) error {
+	opened := map[string]bool{}
+
+	for {
+		if err := s.upgrade(ctx, gitProvider, opts, opened); err != nil {
+			s.Logger.Failuref("failed to upgrade profiles: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// upgrade opens the pull requests of Upgrade, skipping the branches in opened
+// and adding the branches of the pull requests it opens.
+func (s *ProfilesSvc) upgrade(ctx context.Context, gitProvider gitproviders.GitProvider, opts UpgradeOptions, opened map[string]bool) error {
+	var constraint *semver.Constraints
+
+	if opts.Constraint != "" {
+		c, err := semver.NewConstraint(opts.Constraint)
+		if err != nil {
+			return fmt.Errorf("invalid version constraint %q: %w", opts.Constraint, err)
+		}
+
+		constraint = c
+	}
+
+	configRepoURL, err := gitproviders.NewRepoURL(opts.ConfigRepo)
+	if err != nil {
+		return fmt.Errorf("failed to parse url: %w", err)
+	}
+
+	repoExists, err := gitProvider.RepositoryExists(ctx, configRepoURL)
+	if err != nil {
+		return fmt.Errorf("failed to check whether repository exists: %w", err)
+	} else if !repoExists {
+		return fmt.Errorf("repository %q could not be found", configRepoURL)
+	}
+
+	changeset, err := gitproviders.NewChangeset(ctx, gitProvider, configRepoURL, opts.BaseBranch)
+	if err != nil {
+		return err
+	}
+
+	availableProfiles, err := s.getAvailableProfiles(ctx, GetOptions{
+		Cluster:   opts.Cluster,
+		Namespace: opts.Namespace,
+		Port:      opts.ProfilesPort,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get profiles from cluster: %w", err)
+	}
+
+	files, err := changeset.ReadDir(ctx, git.GetSystemPath(opts.Cluster))
+	if err != nil {
+		return err
+	}
+
+	path := git.GetProfilesPath(opts.Cluster, models.WegoProfilesPath)
+
+	fileContent := getGitCommitFileContent(files, path)
+	if fileContent == "" {
+		return fmt.Errorf("failed to find installed profiles in '%s'", path)
+	}
+
+	releases, err := helm.SplitHelmReleaseYAML([]byte(fileContent))
+	if err != nil {
+		return fmt.Errorf("error splitting into YAML: %w", err)
+	}
+
+	upgrades := s.findUpgrades(ctx, opts, constraint, releases, availableProfiles)
+	if len(upgrades) == 0 {
+		s.Logger.Successf("all profiles installed in cluster %s are up to date", opts.Cluster)
+		return nil
+	}
+
+	// the pull requests of the other profiles would revert a merged upgrade
+	batches := [][]profileUpgrade{upgrades}
+	if !opts.Batch && !opts.Wait {
+		batches = nil
+		for _, u := range upgrades {
+			batches = append(batches, []profileUpgrade{u})
+		}
+	}
+
+	for _, batch := range batches {
+		info := upgradePRInfo(opts.Cluster, batch)
+		if opened[info.NewBranch] {
+			continue
+		}
+
+		content, err := upgradeHelmReleases(fileContent, batch, availableProfiles)
+		if err != nil {
+			return err
+		}
+
+		// every batch edits the same manifest, replacing the edit of the previous one
+		changeset.Write(path, content)
+
+		if err := s.submit(ctx, gitProvider, configRepoURL, changeset, info, opts.mergeOptions(UpgradeCommitMessage)); err != nil {
+			return err
+		}
+
+		opened[info.NewBranch] = true
+	}
+
+	return nil
+}
+
+// findUpgrades returns the upgrades of the installed profiles, ordered like
+// the HelmReleases. Profiles that cannot be upgraded are logged and left out.
+func (s *ProfilesSvc) findUpgrades(ctx context.Context, opts UpgradeOptions, constraint *semver.Constraints, releases []*helmv2beta1.HelmRelease, availableProfiles []*pb.Profile) []profileUpgrade {
+	var upgrades []profileUpgrade
+
+	for _, r := range releases {
+		helmRepo, ok := helm.ReleaseHelmRepository(r)
+		if !ok {
+			continue
+		}
+
+		name := r.Spec.Chart.Spec.Chart
+		profile := findInstalledProfile(availableProfiles, name, helmRepo)
+
+		if profile == nil {
+			continue
+		}
+
+		policy, err := helm.ParseUpgradePolicy(r.Annotations[helm.UpgradePolicyAnnotation])
+		if err != nil {
+			s.Logger.Warningf("skipping profile %s of HelmRelease %s/%s: %v", name, r.Namespace, r.Name, err)
+			continue
+		}
+
+		installed := helm.InstalledVersion(r)
+
+		versions, err := helm.AvailableUpgrades(installed, profile.AvailableVersions, policy)
+		if err != nil {
+			s.Logger.Warningf("skipping profile %s of HelmRelease %s/%s: %v", name, r.Namespace, r.Name, err)
+			continue
+		}
+
+		version := latestAllowed(versions, constraint)
+		if version == "" {
+			continue
+		}
+
+		values, err := releaseValues(r)
+		if err == nil {
+			err = s.validateValues(ctx, Options{
+				Name:         name,
+				Version:      version,
+				Namespace:    opts.Namespace,
+				ProfilesPort: opts.ProfilesPort,
+			}, helmRepo, values)
+		}
+
+		if err != nil {
+			s.Logger.Warningf("skipping upgrade of profile %s from version %s to %s: %v", name, installed, version, err)
+			continue
+		}
+
+		diff, err := s.valuesDiff(ctx, opts, helmRepo, name, installed, version)
+		if err != nil {
+			s.Logger.Warningf("failed to compare the values of profile %s versions %s and %s: %v", name, installed, version, err)
+		}
+
+		upgrades = append(upgrades, profileUpgrade{
+			release:    types.NamespacedName{Namespace: r.Namespace, Name: r.Name},
+			profile:    name,
+			from:       installed,
+			to:         version,
+			valuesDiff: diff,
+			compared:   err == nil,
+		})
+	}
+
+	return upgrades
+}
+
+func findInstalledProfile(profiles []*pb.Profile, name string, helmRepo types.NamespacedName) *pb.Profile {
+	for _, p := range profiles {
+		if p.Name == name && p.GetHelmRepository().GetName() == helmRepo.Name && p.GetHelmRepository().GetNamespace() == helmRepo.Namespace {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// latestAllowed returns the first of versions, sorted latest first, that
+// satisfies constraint, or an empty string when none does.
+func latestAllowed(versions []string, constraint *semver.Constraints) string {
+	for _, v := range versions {
+		if constraint == nil {
+			return v
+		}
+
+		if sv, err := semver.NewVersion(v); err == nil && constraint.Check(sv) {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// valuesDiff returns the unified diff between the default values of two
+// versions of a profile.
+func (s *ProfilesSvc) valuesDiff(ctx context.Context, opts UpgradeOptions, helmRepo types.NamespacedName, name, from, to string) (string, error) {
+	oldValues, err := s.getValues(ctx, opts.Namespace, opts.ProfilesPort, helmRepo, name, from)
+	if err != nil {
+		return "", err
+	}
+
+	newValues, err := s.getValues(ctx, opts.Namespace, opts.ProfilesPort, helmRepo, name, to)
+	if err != nil {
+		return "", err
+	}
+
+	// SplitLines ends every line with a newline, the last one included.
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(strings.TrimSuffix(oldValues, "\n")),
+		B:        difflib.SplitLines(strings.TrimSuffix(newValues, "\n")),
+		FromFile: fmt.Sprintf("values.yaml (%s)", from),
+		ToFile:   fmt.Sprintf("values.yaml (%s)", to),
+		Context:  3,
+	})
+}
+
+// upgradeHelmReleases returns the content of the profiles manifest with the
+// HelmReleases of upgrades set to their new version.
+func upgradeHelmReleases(fileContent string, upgrades []profileUpgrade, availableProfiles []*pb.Profile) (string, error) {
+	releases, err := helm.SplitHelmReleaseYAML([]byte(fileContent))
+	if err != nil {
+		return "", fmt.Errorf("error splitting into YAML: %w", err)
+	}
+
+	for _, u := range upgrades {
+		releases, err = patchRelease(releases, u.release.Name, u.release.Namespace, u.to)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// The new versions may come with other dependencies.
+	releases, err = helm.ResolveDependencies(releases, availableProfiles)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve profile dependencies: %w", err)
+	}
+
+	return helm.MarshalHelmReleases(releases)
+}
+
+// upgradePRInfo returns the pull request upgrading profiles. Its branch only
+// depends on the upgrades, so that the same upgrade is not opened twice.
+func upgradePRInfo(cluster string, upgrades []profileUpgrade) gitproviders.PullRequestInfo {
+	var (
+		title       string
+		branch      string
+		description strings.Builder
+	)
+
+	if len(upgrades) == 1 {
+		u := upgrades[0]
+		title = fmt.Sprintf("GitOps upgrade %s to %s", u.profile, u.to)
+		branch = fmt.Sprintf("gitops-upgrade-%s-%s-%s", u.release.Namespace, u.release.Name, u.to)
+	} else {
+		names := make([]string, 0, len(upgrades))
+		for _, u := range upgrades {
+			names = append(names, fmt.Sprintf("%s@%s", u.release, u.to))
+		}
+
+		sort.Strings(names)
+
+		title = fmt.Sprintf("GitOps upgrade %d profiles in cluster %s", len(upgrades), cluster)
+		sum := sha256.Sum256([]byte(strings.Join(names, ",")))
+		branch = fmt.Sprintf("gitops-upgrade-%s-%x", cluster, sum[:6])
+	}
+
+	for _, u := range upgrades {
+		fmt.Fprintf(&description, "Upgrade profile %s of HelmRelease %s in cluster %s from version %s to %s.\n\n", u.profile, u.release, cluster, u.from, u.to)
+
+		switch {
+		case !u.compared:
+			description.WriteString("The default values of both versions could not be compared.\n\n")
+		case u.valuesDiff == "":
+			description.WriteString("The default values did not change.\n\n")
+		default:
+			fmt.Fprintf(&description, "Changes to the default values:\n\n```diff\n%s```\n\n", u.valuesDiff)
+		}
+	}
+
+	return gitproviders.PullRequestInfo{
+		Title:           title,
+		Description:     strings.TrimSpace(description.String()),
+		CommitMessage:   UpgradeCommitMessage,
+		NewBranch:       branch,
+		GeneratedBranch: true,
+	}
+}
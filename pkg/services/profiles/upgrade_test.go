@@ -0,0 +1,234 @@
+package profiles_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders/gitprovidersfakes"
+	"github.com/weaveworks/weave-gitops/pkg/helm"
+	"github.com/weaveworks/weave-gitops/pkg/logger/loggerfakes"
+	"github.com/weaveworks/weave-gitops/pkg/models"
+	"github.com/weaveworks/weave-gitops/pkg/services/profiles"
+	"github.com/weaveworks/weave-gitops/pkg/vendorfakes/fakegitprovider"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/testing"
+)
+
+const upgradeProfilesResp = `{
+  "profiles": [
+    {
+      "name": "podinfo",
+      "helmRepository": {"name": "charts", "namespace": "weave-system"},
+      "availableVersions": ["6.0.0", "6.0.1", "6.1.0", "7.0.0"]
+    },
+    {
+      "name": "nginx",
+      "helmRepository": {"name": "charts", "namespace": "weave-system"},
+      "availableVersions": ["1.0.0", "1.1.0"]
+    }
+  ]
+}
+`
+
+var _ = Describe("Upgrade Profiles", func() {
+	var (
+		gitProviders *gitprovidersfakes.FakeGitProvider
+		profilesSvc  *profiles.ProfilesSvc
+		clientSet    *fake.Clientset
+		fakeLogger   *loggerfakes.FakeLogger
+		fakePR       *fakegitprovider.PullRequest
+		opts         profiles.UpgradeOptions
+		schema       string
+	)
+
+	release := func(name, version string) *helmv2beta1.HelmRelease {
+		return helm.MakeHelmRelease(name, version, "prod", "weave-system", types.NamespacedName{Name: "charts", Namespace: "weave-system"})
+	}
+
+	installed := func(releases ...*helmv2beta1.HelmRelease) {
+		content, err := helm.MarshalHelmReleases(releases)
+		Expect(err).NotTo(HaveOccurred())
+
+		path := git.GetProfilesPath("prod", models.WegoProfilesPath)
+		gitProviders.GetRepoDirFilesReturns([]*gitprovider.CommitFile{{
+			Path:    &path,
+			Content: &content,
+		}}, nil)
+	}
+
+	BeforeEach(func() {
+		gitProviders = &gitprovidersfakes.FakeGitProvider{}
+		clientSet = fake.NewSimpleClientset()
+		fakeLogger = &loggerfakes.FakeLogger{}
+		fakePR = &fakegitprovider.PullRequest{}
+		profilesSvc = profiles.NewService(clientSet, fakeLogger)
+		schema = ""
+
+		opts = profiles.UpgradeOptions{
+			ConfigRepo: "ssh://git@github.com/owner/config-repo.git",
+			Cluster:    "prod",
+			Namespace:  "weave-system",
+		}
+
+		gitProviders.RepositoryExistsReturns(true, nil)
+		gitProviders.GetDefaultBranchReturns("main", nil)
+		fakePR.GetReturns(gitprovider.PullRequestInfo{WebURL: "url", Number: 42})
+		gitProviders.CreatePullRequestReturns(fakePR, nil)
+
+		clientSet.AddProxyReactor("services", func(action testing.Action) (handled bool, ret restclient.ResponseWrapper, err error) {
+			path := action.(testing.ProxyGetAction).GetPath()
+
+			switch {
+			case strings.HasSuffix(path, "/values/schema"):
+				return true, newFakeResponseWrapper(fmt.Sprintf(`{"schema": %q}`, schema)), nil
+			case strings.HasSuffix(path, "/values"):
+				version := strings.Split(path, "/")[4]
+				values := fmt.Sprintf("replicaCount: 1\nimage: podinfo:%s\n", version)
+
+				return true, newFakeResponseWrapper(fmt.Sprintf(`{"values": %q}`, base64.StdEncoding.EncodeToString([]byte(values)))), nil
+			}
+
+			return true, newFakeResponseWrapper(upgradeProfilesResp), nil
+		})
+	})
+
+	It("opens a PR for each profile with a newer version", func() {
+		installed(release("podinfo", "6.0.0"), release("nginx", "1.0.0"))
+
+		Expect(profilesSvc.Upgrade(context.TODO(), gitProviders, opts)).To(Succeed())
+		Expect(gitProviders.CreatePullRequestCallCount()).To(Equal(2))
+
+		_, repoURL, prInfo := gitProviders.CreatePullRequestArgsForCall(0)
+		Expect(repoURL.String()).To(Equal("ssh://git@github.com/owner/config-repo.git"))
+		Expect(prInfo.Title).To(Equal("GitOps upgrade podinfo to 7.0.0"))
+		Expect(prInfo.TargetBranch).To(Equal("main"))
+		Expect(prInfo.NewBranch).To(Equal("gitops-upgrade-weave-system-prod-podinfo-7.0.0"))
+		Expect(prInfo.CommitMessage).To(Equal(profiles.UpgradeCommitMessage))
+		Expect(prInfo.Description).To(ContainSubstring("Upgrade profile podinfo of HelmRelease weave-system/prod-podinfo in cluster prod from version 6.0.0 to 7.0.0."))
+		Expect(prInfo.Description).To(ContainSubstring("```diff\n--- values.yaml (6.0.0)\n+++ values.yaml (7.0.0)\n@@ -1,2 +1,2 @@\n replicaCount: 1\n-image: podinfo:6.0.0\n+image: podinfo:7.0.0\n```"))
+		Expect(*prInfo.Files[0].Path).To(Equal(".weave-gitops/clusters/prod/system/profiles.yaml"))
+		Expect(chartVersions(*prInfo.Files[0].Content)).To(Equal(map[string]string{"podinfo": "7.0.0", "nginx": "1.0.0"}))
+
+		_, _, prInfo = gitProviders.CreatePullRequestArgsForCall(1)
+		Expect(prInfo.Title).To(Equal("GitOps upgrade nginx to 1.1.0"))
+		Expect(chartVersions(*prInfo.Files[0].Content)).To(Equal(map[string]string{"podinfo": "6.0.0", "nginx": "1.1.0"}))
+	})
+
+	It("opens a single PR when batching", func() {
+		installed(release("podinfo", "6.0.0"), release("nginx", "1.0.0"))
+		opts.Batch = true
+		opts.AutoMerge = true
+		opts.MergeMethod = gitprovider.MergeMethodSquash
+
+		Expect(profilesSvc.Upgrade(context.TODO(), gitProviders, opts)).To(Succeed())
+		Expect(gitProviders.CreatePullRequestCallCount()).To(Equal(1))
+
+		_, _, prInfo := gitProviders.CreatePullRequestArgsForCall(0)
+		Expect(prInfo.Title).To(Equal("GitOps upgrade 2 profiles in cluster prod"))
+		Expect(prInfo.NewBranch).To(HavePrefix("gitops-upgrade-prod-"))
+		Expect(prInfo.Description).To(ContainSubstring("from version 6.0.0 to 7.0.0"))
+		Expect(prInfo.Description).To(ContainSubstring("from version 1.0.0 to 1.1.0"))
+		Expect(chartVersions(*prInfo.Files[0].Content)).To(Equal(map[string]string{"podinfo": "7.0.0", "nginx": "1.1.0"}))
+
+		Expect(gitProviders.MergePullRequestCallCount()).To(Equal(1))
+		_, _, number, method, message := gitProviders.MergePullRequestArgsForCall(0)
+		Expect(number).To(Equal(42))
+		Expect(method).To(Equal(gitprovider.MergeMethodSquash))
+		Expect(message).To(Equal(profiles.UpgradeCommitMessage))
+	})
+
+	It("opens a single PR when waiting for it", func() {
+		installed(release("podinfo", "6.0.0"), release("nginx", "1.0.0"))
+		opts.Wait = true
+		opts.PollInterval = time.Millisecond
+		gitProviders.GetPullRequestStatusReturns(gitproviders.PullRequestStatus{Number: 42, State: gitproviders.PullRequestMerged, MergeCommitSha: "abc123"}, nil)
+
+		Expect(profilesSvc.Upgrade(context.TODO(), gitProviders, opts)).To(Succeed())
+		Expect(gitProviders.CreatePullRequestCallCount()).To(Equal(1))
+
+		_, _, prInfo := gitProviders.CreatePullRequestArgsForCall(0)
+		Expect(chartVersions(*prInfo.Files[0].Content)).To(Equal(map[string]string{"podinfo": "7.0.0", "nginx": "1.1.0"}))
+	})
+
+	It("picks the latest version satisfying the constraint", func() {
+		installed(release("podinfo", "6.0.0"))
+		opts.Constraint = "~6.0"
+
+		Expect(profilesSvc.Upgrade(context.TODO(), gitProviders, opts)).To(Succeed())
+		Expect(gitProviders.CreatePullRequestCallCount()).To(Equal(1))
+
+		_, _, prInfo := gitProviders.CreatePullRequestArgsForCall(0)
+		Expect(prInfo.Title).To(Equal("GitOps upgrade podinfo to 6.0.1"))
+	})
+
+	It("does not open a PR when the profiles are up to date", func() {
+		installed(release("podinfo", "7.0.0"), release("nginx", "1.1.0"))
+
+		Expect(profilesSvc.Upgrade(context.TODO(), gitProviders, opts)).To(Succeed())
+		Expect(gitProviders.CreatePullRequestCallCount()).To(BeZero())
+	})
+
+	It("skips the profiles whose values are not valid for the new version", func() {
+		schema = `{"properties": {"replicaCount": {"type": "integer"}}}`
+
+		hr := release("podinfo", "6.0.0")
+		hr.Spec.Values = &apiextensionsv1.JSON{Raw: []byte(`{"replicaCount":"two"}`)}
+		installed(hr)
+
+		Expect(profilesSvc.Upgrade(context.TODO(), gitProviders, opts)).To(Succeed())
+		Expect(gitProviders.CreatePullRequestCallCount()).To(BeZero())
+		Expect(fakeLogger.WarningfCallCount()).To(Equal(1))
+	})
+
+	It("fails when the constraint is not valid", func() {
+		opts.Constraint = "&%*/v"
+
+		err := profilesSvc.Upgrade(context.TODO(), gitProviders, opts)
+		Expect(err).To(MatchError(ContainSubstring(`invalid version constraint "&%*/v"`)))
+		Expect(gitProviders.RepositoryExistsCallCount()).To(BeZero())
+	})
+
+	It("only opens a PR once when watching for upgrades", func() {
+		installed(release("podinfo", "6.0.0"))
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		gitProviders.RepositoryExistsStub = func(context.Context, gitproviders.RepoURL) (bool, error) {
+			if gitProviders.RepositoryExistsCallCount() == 3 {
+				cancel()
+			}
+
+			return true, nil
+		}
+
+		Expect(profilesSvc.WatchUpgrades(ctx, gitProviders, opts, time.Millisecond)).To(Succeed())
+		Expect(gitProviders.RepositoryExistsCallCount()).To(Equal(3))
+		Expect(gitProviders.CreatePullRequestCallCount()).To(Equal(1))
+	})
+})
+
+func chartVersions(content string) map[string]string {
+	releases, err := helm.SplitHelmReleaseYAML([]byte(content))
+	Expect(err).NotTo(HaveOccurred())
+
+	versions := map[string]string{}
+	for _, r := range releases {
+		versions[r.Spec.Chart.Spec.Chart] = r.Spec.Chart.Spec.Version
+	}
+
+	return versions
+}
@@ -0,0 +1,131 @@
+package profiles
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	helmv2beta1 "github.com/fluxcd/helm-controller/api/v2beta1"
+	"github.com/gogo/protobuf/jsonpb"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	pb "github.com/weaveworks/weave-gitops/pkg/api/profiles"
+	"github.com/weaveworks/weave-gitops/pkg/helm"
+)
+
+// validateValues checks values, on top of the default values of a profile
+// version, against its values schema, as served by the profiles API, so that
+// invalid values are reported before a pull request is opened.
+func (s *ProfilesSvc) validateValues(ctx context.Context, opts Options, helmRepo types.NamespacedName, values map[string]interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	path := fmt.Sprintf("%s/%s/%s/values/schema", getProfilesPath, opts.Name, opts.Version)
+
+	resp, err := kubernetesDoRequest(ctx, opts.Namespace, wegoServiceName, "https", opts.ProfilesPort, path, map[string]string{
+		"helmRepositoryName":      helmRepo.Name,
+		"helmRepositoryNamespace": helmRepo.Namespace,
+	}, s.ClientSet)
+	if err != nil {
+		return fmt.Errorf("failed to get values schema: %w", err)
+	}
+
+	schema := &pb.GetProfileValuesSchemaResponse{}
+	if err := jsonpb.UnmarshalString(string(resp), schema); err != nil {
+		return fmt.Errorf("failed to unmarshal values schema response: %w", err)
+	}
+
+	if schema.Schema == "" {
+		return nil
+	}
+
+	defaults, err := s.getValues(ctx, opts.Namespace, opts.ProfilesPort, helmRepo, opts.Name, opts.Version)
+	if err != nil {
+		return err
+	}
+
+	if err := helm.ValidateValues([]byte(schema.Schema), []byte(defaults), values); err != nil {
+		return fmt.Errorf("invalid values for profile '%s' version %s: %w", opts.Name, opts.Version, err)
+	}
+
+	return nil
+}
+
+// getValues returns the default values of a profile version, as served by the
+// profiles API.
+func (s *ProfilesSvc) getValues(ctx context.Context, namespace, profilesPort string, helmRepo types.NamespacedName, name, version string) (string, error) {
+	path := fmt.Sprintf("%s/%s/%s/values", getProfilesPath, name, version)
+
+	resp, err := kubernetesDoRequest(ctx, namespace, wegoServiceName, "https", profilesPort, path, map[string]string{
+		"helmRepositoryName":      helmRepo.Name,
+		"helmRepositoryNamespace": helmRepo.Namespace,
+	}, s.ClientSet)
+	if err != nil {
+		return "", fmt.Errorf("failed to get values: %w", err)
+	}
+
+	values := &pb.GetProfileValuesResponse{}
+	if err := jsonpb.UnmarshalString(string(resp), values); err != nil {
+		return "", fmt.Errorf("failed to unmarshal values response: %w", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(values.Values)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode values: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// setReleaseValues writes values to the spec.values of a HelmRelease.
+func setReleaseValues(r *helmv2beta1.HelmRelease, values map[string]interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to marshal values: %w", err)
+	}
+
+	r.Spec.Values = &apiextensionsv1.JSON{Raw: raw}
+
+	return nil
+}
+
+// addValuesReferences appends to the spec.valuesFrom of a HelmRelease the
+// references it does not have yet.
+func addValuesReferences(r *helmv2beta1.HelmRelease, refs []helmv2beta1.ValuesReference) {
+	for _, ref := range refs {
+		if !hasValuesReference(r.Spec.ValuesFrom, ref) {
+			r.Spec.ValuesFrom = append(r.Spec.ValuesFrom, ref)
+		}
+	}
+}
+
+func hasValuesReference(refs []helmv2beta1.ValuesReference, ref helmv2beta1.ValuesReference) bool {
+	for _, r := range refs {
+		if r.Kind == ref.Kind && r.Name == ref.Name && r.GetValuesKey() == ref.GetValuesKey() && r.TargetPath == ref.TargetPath {
+			return true
+		}
+	}
+
+	return false
+}
+
+// releaseValues returns the spec.values of a HelmRelease.
+func releaseValues(r *helmv2beta1.HelmRelease) (map[string]interface{}, error) {
+	if r.Spec.Values == nil || len(r.Spec.Values.Raw) == 0 {
+		return nil, nil
+	}
+
+	values := map[string]interface{}{}
+	if err := json.Unmarshal(r.Spec.Values.Raw, &values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal values of HelmRelease '%s': %w", r.Name, err)
+	}
+
+	return values, nil
+}
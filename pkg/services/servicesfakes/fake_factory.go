@@ -9,9 +9,25 @@ import (
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 	"github.com/weaveworks/weave-gitops/pkg/kube"
 	"github.com/weaveworks/weave-gitops/pkg/services"
+	"github.com/weaveworks/weave-gitops/pkg/services/auth"
 )
 
 type FakeFactory struct {
+	GetAuthServiceStub        func(kube.Kube, gitproviders.Client, services.GitConfigParams) (auth.AuthService, error)
+	getAuthServiceMutex       sync.RWMutex
+	getAuthServiceArgsForCall []struct {
+		arg1 kube.Kube
+		arg2 gitproviders.Client
+		arg3 services.GitConfigParams
+	}
+	getAuthServiceReturns struct {
+		result1 auth.AuthService
+		result2 error
+	}
+	getAuthServiceReturnsOnCall map[int]struct {
+		result1 auth.AuthService
+		result2 error
+	}
 	GetGitClientsStub        func(context.Context, kube.Kube, gitproviders.Client, services.GitConfigParams) (git.Git, gitproviders.GitProvider, error)
 	getGitClientsMutex       sync.RWMutex
 	getGitClientsArgsForCall []struct {
@@ -34,6 +50,72 @@ type FakeFactory struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeFactory) GetAuthService(arg1 kube.Kube, arg2 gitproviders.Client, arg3 services.GitConfigParams) (auth.AuthService, error) {
+	fake.getAuthServiceMutex.Lock()
+	ret, specificReturn := fake.getAuthServiceReturnsOnCall[len(fake.getAuthServiceArgsForCall)]
+	fake.getAuthServiceArgsForCall = append(fake.getAuthServiceArgsForCall, struct {
+		arg1 kube.Kube
+		arg2 gitproviders.Client
+		arg3 services.GitConfigParams
+	}{arg1, arg2, arg3})
+	stub := fake.GetAuthServiceStub
+	fakeReturns := fake.getAuthServiceReturns
+	fake.recordInvocation("GetAuthService", []interface{}{arg1, arg2, arg3})
+	fake.getAuthServiceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeFactory) GetAuthServiceCallCount() int {
+	fake.getAuthServiceMutex.RLock()
+	defer fake.getAuthServiceMutex.RUnlock()
+	return len(fake.getAuthServiceArgsForCall)
+}
+
+func (fake *FakeFactory) GetAuthServiceCalls(stub func(kube.Kube, gitproviders.Client, services.GitConfigParams) (auth.AuthService, error)) {
+	fake.getAuthServiceMutex.Lock()
+	defer fake.getAuthServiceMutex.Unlock()
+	fake.GetAuthServiceStub = stub
+}
+
+func (fake *FakeFactory) GetAuthServiceArgsForCall(i int) (kube.Kube, gitproviders.Client, services.GitConfigParams) {
+	fake.getAuthServiceMutex.RLock()
+	defer fake.getAuthServiceMutex.RUnlock()
+	argsForCall := fake.getAuthServiceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeFactory) GetAuthServiceReturns(result1 auth.AuthService, result2 error) {
+	fake.getAuthServiceMutex.Lock()
+	defer fake.getAuthServiceMutex.Unlock()
+	fake.GetAuthServiceStub = nil
+	fake.getAuthServiceReturns = struct {
+		result1 auth.AuthService
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFactory) GetAuthServiceReturnsOnCall(i int, result1 auth.AuthService, result2 error) {
+	fake.getAuthServiceMutex.Lock()
+	defer fake.getAuthServiceMutex.Unlock()
+	fake.GetAuthServiceStub = nil
+	if fake.getAuthServiceReturnsOnCall == nil {
+		fake.getAuthServiceReturnsOnCall = make(map[int]struct {
+			result1 auth.AuthService
+			result2 error
+		})
+	}
+	fake.getAuthServiceReturnsOnCall[i] = struct {
+		result1 auth.AuthService
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeFactory) GetGitClients(arg1 context.Context, arg2 kube.Kube, arg3 gitproviders.Client, arg4 services.GitConfigParams) (git.Git, gitproviders.GitProvider, error) {
 	fake.getGitClientsMutex.Lock()
 	ret, specificReturn := fake.getGitClientsReturnsOnCall[len(fake.getGitClientsArgsForCall)]
@@ -107,6 +189,8 @@ func (fake *FakeFactory) GetGitClientsReturnsOnCall(i int, result1 git.Git, resu
 func (fake *FakeFactory) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.getAuthServiceMutex.RLock()
+	defer fake.getAuthServiceMutex.RUnlock()
 	fake.getGitClientsMutex.RLock()
 	defer fake.getGitClientsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
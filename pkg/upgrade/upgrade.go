@@ -105,7 +105,13 @@ func upgrade(ctx context.Context, uv UpgradeValues, kube kube.Kube, gitClient gi
 		}
 	}
 
-	remover, repoDir, err := gitrepo.CloneRepo(ctx, gitClient, normalizedURL, configBranch)
+	// only the files of the cluster are needed, absolute paths are on the local disk
+	sparsePaths := []string{}
+	if uv.ClusterPath != "" && !filepath.IsAbs(uv.ClusterPath) {
+		sparsePaths = append(sparsePaths, uv.ClusterPath)
+	}
+
+	remover, repoDir, err := gitrepo.CloneRepo(ctx, gitClient, normalizedURL, configBranch, sparsePaths...)
 	if err != nil {
 		return fmt.Errorf("failed to clone configuration repo: %w", err)
 	}
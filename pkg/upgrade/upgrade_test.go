@@ -78,6 +78,7 @@ func TestUpgrade(t *testing.T) {
 	// Run upgrade!
 	err := upgrade(context.TODO(), UpgradeValues{
 		ConfigRepo:    "https://github.com/test/example.git",
+		ClusterPath:   "clusters/management",
 		HeadBranch:    "upgrade-to-wge",
 		BaseBranch:    "main",
 		CommitMessage: "Upgrade to wge",
@@ -85,6 +86,10 @@ func TestUpgrade(t *testing.T) {
 	}, k, gitClient, kubeClient, gitProvider, logger, &output)
 
 	assert.NoError(t, err)
+
+	_, _, _, branch, sparsePaths := gitClient.CloneArgsForCall(0)
+	assert.Equal(t, "main", branch)
+	assert.Equal(t, []string{"clusters/management"}, sparsePaths)
 }
 
 func TestGetGitAuthFromDeployKey(t *testing.T) {
@@ -10,6 +10,8 @@ export enum GitProvider {
   Unknown = "Unknown",
   GitHub = "GitHub",
   GitLab = "GitLab",
+  BitbucketServer = "BitbucketServer",
+  Gitea = "Gitea",
 }
 
 export type AuthenticateRequest = {
@@ -69,6 +71,7 @@ export type AuthorizeGitlabResponse = {
 
 export type ValidateProviderTokenRequest = {
   provider?: GitProvider
+  host?: string
 }
 
 export type ValidateProviderTokenResponse = {
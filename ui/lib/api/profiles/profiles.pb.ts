@@ -30,9 +30,12 @@ export type Profile = {
   helmRepository?: HelmRepository
   availableVersions?: string[]
   layer?: string
+  dependencies?: string[]
 }
 
 export type GetProfilesRequest = {
+  helmRepositoryName?: string
+  helmRepositoryNamespace?: string
 }
 
 export type GetProfilesResponse = {
@@ -42,6 +45,8 @@ export type GetProfilesResponse = {
 export type GetProfileValuesRequest = {
   profileName?: string
   profileVersion?: string
+  helmRepositoryName?: string
+  helmRepositoryNamespace?: string
 }
 
 export type GetProfileValuesResponse = {
@@ -54,6 +59,56 @@ export type ProfileValues = {
   values?: string
 }
 
+export type GetProfileValuesSchemaRequest = {
+  profileName?: string
+  profileVersion?: string
+  helmRepositoryName?: string
+  helmRepositoryNamespace?: string
+}
+
+export type GetProfileValuesSchemaResponse = {
+  schema?: string
+}
+
+export type ValidateProfileValuesRequest = {
+  profileName?: string
+  profileVersion?: string
+  helmRepositoryName?: string
+  helmRepositoryNamespace?: string
+  values?: string
+}
+
+export type ValuesError = {
+  path?: string
+  message?: string
+}
+
+export type ValidateProfileValuesResponse = {
+  valid?: boolean
+  errors?: ValuesError[]
+}
+
+export type ListProfileUpgradesRequest = {
+  namespace?: string
+  policy?: string
+}
+
+export type ProfileUpgrade = {
+  clusterName?: string
+  helmReleaseName?: string
+  helmReleaseNamespace?: string
+  profileName?: string
+  helmRepository?: HelmRepository
+  installedVersion?: string
+  latestVersion?: string
+  upgradeType?: string
+  availableVersions?: string[]
+}
+
+export type ListProfileUpgradesResponse = {
+  upgrades?: ProfileUpgrade[]
+}
+
 export class Profiles {
   static GetProfiles(req: GetProfilesRequest, initReq?: fm.InitReq): Promise<GetProfilesResponse> {
     return fm.fetchReq<GetProfilesRequest, GetProfilesResponse>(`/v1/profiles?${fm.renderURLSearchParams(req, [])}`, {...initReq, method: "GET"})
@@ -61,4 +116,13 @@ export class Profiles {
   static GetProfileValues(req: GetProfileValuesRequest, initReq?: fm.InitReq): Promise<GoogleApiHttpbody.HttpBody> {
     return fm.fetchReq<GetProfileValuesRequest, GoogleApiHttpbody.HttpBody>(`/v1/profiles/${req["profileName"]}/${req["profileVersion"]}/values?${fm.renderURLSearchParams(req, ["profileName", "profileVersion"])}`, {...initReq, method: "GET"})
   }
+  static GetProfileValuesSchema(req: GetProfileValuesSchemaRequest, initReq?: fm.InitReq): Promise<GetProfileValuesSchemaResponse> {
+    return fm.fetchReq<GetProfileValuesSchemaRequest, GetProfileValuesSchemaResponse>(`/v1/profiles/${req["profileName"]}/${req["profileVersion"]}/values/schema?${fm.renderURLSearchParams(req, ["profileName", "profileVersion"])}`, {...initReq, method: "GET"})
+  }
+  static ValidateProfileValues(req: ValidateProfileValuesRequest, initReq?: fm.InitReq): Promise<ValidateProfileValuesResponse> {
+    return fm.fetchReq<ValidateProfileValuesRequest, ValidateProfileValuesResponse>(`/v1/profiles/${req["profileName"]}/${req["profileVersion"]}/values/validate`, {...initReq, method: "POST", body: JSON.stringify(req)})
+  }
+  static ListProfileUpgrades(req: ListProfileUpgradesRequest, initReq?: fm.InitReq): Promise<ListProfileUpgradesResponse> {
+    return fm.fetchReq<ListProfileUpgradesRequest, ListProfileUpgradesResponse>(`/v1/profiles/upgrades?${fm.renderURLSearchParams(req, [])}`, {...initReq, method: "GET"})
+  }
 }
\ No newline at end of file